@@ -0,0 +1,164 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package acl
+
+import "strings"
+
+// RuleMatch is the rule of a policy that applies to the access of a segment
+// of a resource.
+type RuleMatch struct {
+	// Type is the kind of rule, as written in policies: "key_prefix",
+	// "service", "operator"...
+	Type string
+
+	// Name is the name or prefix of the rule, empty for rules of resources
+	// without segments.
+	Name string
+
+	// Policy is the access level of the rule. For intentions it is the one
+	// derived from the service rule when the rule does not set it.
+	Policy string
+}
+
+// prefix returns whether the rule is a prefix rule.
+func (m RuleMatch) prefix() bool {
+	return strings.HasSuffix(m.Type, "_prefix")
+}
+
+// TakesPrecedenceOver returns whether m is the rule used to authorize a request
+// when both m and other apply to it, for example because they come from two
+// policies of the same token. Exact rules win over prefix rules and longer
+// prefixes over shorter ones, as in the compiled authorizer. Between rules of
+// the same name the most restrictive one wins, as when merging policies, and
+// mesh and peering rules win over the operator rule.
+func (m RuleMatch) TakesPrecedenceOver(other RuleMatch) bool {
+	// The operator rule only applies to mesh and peering when no policy
+	// has a mesh or peering rule.
+	if m.Type != other.Type && (m.Type == "operator" || other.Type == "operator") {
+		return other.Type == "operator"
+	}
+	if m.prefix() != other.prefix() {
+		return !m.prefix()
+	}
+	if len(m.Name) != len(other.Name) {
+		return len(m.Name) > len(other.Name)
+	}
+	return takesPrecedenceOver(m.Policy, other.Policy)
+}
+
+// MatchingRule returns the rule of the policy used to authorize an access to
+// the segment of the resource, the same way the authorizer compiled from the
+// policy alone would.
+func (p *Policy) MatchingRule(resource Resource, segment string) (RuleMatch, bool) {
+	var best RuleMatch
+	var found bool
+	consider := func(typ, name, policy string, prefix bool) {
+		if policy == "" {
+			return
+		}
+		if prefix && !strings.HasPrefix(segment, name) || !prefix && name != segment {
+			return
+		}
+		m := RuleMatch{Type: typ, Name: name, Policy: policy}
+		if !found || m.TakesPrecedenceOver(best) {
+			best, found = m, true
+		}
+	}
+	single := func(typ, policy string) (RuleMatch, bool) {
+		if policy == "" {
+			return RuleMatch{}, false
+		}
+		return RuleMatch{Type: typ, Policy: policy}, true
+	}
+
+	switch resource {
+	case ResourceACL:
+		return single("acl", p.ACL)
+	case ResourceKeyring:
+		return single("keyring", p.Keyring)
+	case ResourceOperator:
+		return single("operator", p.Operator)
+	case ResourceMesh:
+		if p.Mesh == "" {
+			return single("operator", p.Operator)
+		}
+		return single("mesh", p.Mesh)
+	case ResourcePeering:
+		if p.Peering == "" {
+			return single("operator", p.Operator)
+		}
+		return single("peering", p.Peering)
+	case ResourceAgent:
+		for _, r := range p.Agents {
+			consider("agent", r.Node, r.Policy, false)
+		}
+		for _, r := range p.AgentPrefixes {
+			consider("agent_prefix", r.Node, r.Policy, true)
+		}
+	case ResourceEvent:
+		for _, r := range p.Events {
+			consider("event", r.Event, r.Policy, false)
+		}
+		for _, r := range p.EventPrefixes {
+			consider("event_prefix", r.Event, r.Policy, true)
+		}
+	case ResourceKey:
+		for _, r := range p.Keys {
+			consider("key", r.Prefix, r.Policy, false)
+		}
+		for _, r := range p.KeyPrefixes {
+			consider("key_prefix", r.Prefix, r.Policy, true)
+		}
+	case ResourceNode:
+		for _, r := range p.Nodes {
+			consider("node", r.Name, r.Policy, false)
+		}
+		for _, r := range p.NodePrefixes {
+			consider("node_prefix", r.Name, r.Policy, true)
+		}
+	case ResourceQuery:
+		for _, r := range p.PreparedQueries {
+			consider("query", r.Prefix, r.Policy, false)
+		}
+		for _, r := range p.PreparedQueryPrefixes {
+			consider("query_prefix", r.Prefix, r.Policy, true)
+		}
+	case ResourceService:
+		for _, r := range p.Services {
+			consider("service", r.Name, r.Policy, false)
+		}
+		for _, r := range p.ServicePrefixes {
+			consider("service_prefix", r.Name, r.Policy, true)
+		}
+	case ResourceIntention:
+		for _, r := range p.Services {
+			consider("service", r.Name, intentionPolicy(r), false)
+		}
+		for _, r := range p.ServicePrefixes {
+			consider("service_prefix", r.Name, intentionPolicy(r), true)
+		}
+	case ResourceSession:
+		for _, r := range p.Sessions {
+			consider("session", r.Node, r.Policy, false)
+		}
+		for _, r := range p.SessionPrefixes {
+			consider("session_prefix", r.Node, r.Policy, true)
+		}
+	}
+	return best, found
+}
+
+// intentionPolicy returns the access level of intentions for the service
+// rule, see policyAuthorizer.loadRules.
+func intentionPolicy(r *ServiceRule) string {
+	if r.Intentions != "" {
+		return r.Intentions
+	}
+	switch r.Policy {
+	case PolicyRead, PolicyWrite:
+		return PolicyRead
+	default:
+		return PolicyDeny
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package acl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy_MatchingRule(t *testing.T) {
+	policy, err := NewPolicyFromSource(`
+key_prefix "" { policy = "read" }
+key_prefix "app/" { policy = "write" }
+key "app/secret" { policy = "deny" }
+service "web" { policy = "write" }
+service_prefix "" { policy = "read" intentions = "write" }
+operator = "read"
+mesh = "write"
+`, nil, nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		desc     string
+		resource Resource
+		segment  string
+		want     RuleMatch
+		found    bool
+	}{
+		{"exact rule", ResourceKey, "app/secret", RuleMatch{Type: "key", Name: "app/secret", Policy: PolicyDeny}, true},
+		{"longest prefix", ResourceKey, "app/config", RuleMatch{Type: "key_prefix", Name: "app/", Policy: PolicyWrite}, true},
+		{"empty prefix", ResourceKey, "other", RuleMatch{Type: "key_prefix", Name: "", Policy: PolicyRead}, true},
+		{"derived intentions", ResourceIntention, "web", RuleMatch{Type: "service", Name: "web", Policy: PolicyRead}, true},
+		{"explicit intentions", ResourceIntention, "api", RuleMatch{Type: "service_prefix", Name: "", Policy: PolicyWrite}, true},
+		{"mesh", ResourceMesh, "", RuleMatch{Type: "mesh", Policy: PolicyWrite}, true},
+		{"peering falls back to operator", ResourcePeering, "", RuleMatch{Type: "operator", Policy: PolicyRead}, true},
+		{"no rule", ResourceNode, "node1", RuleMatch{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, found := policy.MatchingRule(tt.resource, tt.segment)
+			require.Equal(t, tt.found, found)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRuleMatch_TakesPrecedenceOver(t *testing.T) {
+	exact := RuleMatch{Type: "key", Name: "foo", Policy: PolicyRead}
+	short := RuleMatch{Type: "key_prefix", Name: "f", Policy: PolicyWrite}
+	long := RuleMatch{Type: "key_prefix", Name: "fo", Policy: PolicyRead}
+	longDeny := RuleMatch{Type: "key_prefix", Name: "fo", Policy: PolicyDeny}
+
+	require.True(t, exact.TakesPrecedenceOver(long))
+	require.False(t, long.TakesPrecedenceOver(exact))
+	require.True(t, long.TakesPrecedenceOver(short))
+	require.True(t, longDeny.TakesPrecedenceOver(long))
+	require.False(t, long.TakesPrecedenceOver(longDeny))
+
+	operator := RuleMatch{Type: "operator", Policy: PolicyWrite}
+	mesh := RuleMatch{Type: "mesh", Policy: PolicyRead}
+	require.True(t, mesh.TakesPrecedenceOver(operator))
+	require.False(t, operator.TakesPrecedenceOver(mesh))
+}
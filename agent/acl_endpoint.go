@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/hernad/consul/acl"
 	"github.com/hernad/consul/agent/structs"
@@ -270,6 +271,191 @@ func (s *HTTPHandlers) ACLPolicyDelete(resp http.ResponseWriter, req *http.Reque
 	return true, nil
 }
 
+func (s *HTTPHandlers) ACLPolicyTemplateList(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if s.checkACLDisabled() {
+		return nil, aclDisabled
+	}
+
+	var args structs.ACLPolicyTemplateListRequest
+	if done := s.parse(resp, req, &args.Datacenter, &args.QueryOptions); done {
+		return nil, nil
+	}
+	if err := s.parseEntMeta(req, &args.EnterpriseMeta); err != nil {
+		return nil, err
+	}
+
+	if args.Datacenter == "" {
+		args.Datacenter = s.agent.config.Datacenter
+	}
+
+	var out structs.ACLPolicyTemplateListResponse
+	defer setMeta(resp, &out.QueryMeta)
+	if err := s.agent.RPC(req.Context(), "ACL.PolicyTemplateList", &args, &out); err != nil {
+		return nil, err
+	}
+
+	// make sure we return an array and not nil
+	if out.PolicyTemplates == nil {
+		out.PolicyTemplates = make(structs.ACLPolicyTemplates, 0)
+	}
+
+	return out.PolicyTemplates, nil
+}
+
+func (s *HTTPHandlers) ACLPolicyTemplateCRUD(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if s.checkACLDisabled() {
+		return nil, aclDisabled
+	}
+
+	var fn func(resp http.ResponseWriter, req *http.Request, templateID string) (interface{}, error)
+
+	switch req.Method {
+	case "GET":
+		fn = s.ACLPolicyTemplateReadByID
+
+	case "PUT":
+		fn = s.ACLPolicyTemplateWrite
+
+	case "DELETE":
+		fn = s.ACLPolicyTemplateDelete
+
+	default:
+		return nil, MethodNotAllowedError{req.Method, []string{"GET", "PUT", "DELETE"}}
+	}
+
+	templateID := strings.TrimPrefix(req.URL.Path, "/v1/acl/policy-template/")
+	if templateID == "" && req.Method != "PUT" {
+		return nil, HTTPError{StatusCode: http.StatusBadRequest, Reason: "Missing policy template ID"}
+	}
+
+	return fn(resp, req, templateID)
+}
+
+func (s *HTTPHandlers) ACLPolicyTemplateRead(resp http.ResponseWriter, req *http.Request, templateID, templateName string) (interface{}, error) {
+	// template name needs to be unescaped in case there were `/` characters
+	templateName, err := url.QueryUnescape(templateName)
+	if err != nil {
+		return nil, err
+	}
+
+	args := structs.ACLPolicyTemplateGetRequest{
+		Datacenter:         s.agent.config.Datacenter,
+		PolicyTemplateID:   templateID,
+		PolicyTemplateName: templateName,
+	}
+	if done := s.parse(resp, req, &args.Datacenter, &args.QueryOptions); done {
+		return nil, nil
+	}
+
+	if err := s.parseEntMeta(req, &args.EnterpriseMeta); err != nil {
+		return nil, err
+	}
+
+	if args.Datacenter == "" {
+		args.Datacenter = s.agent.config.Datacenter
+	}
+
+	var out structs.ACLPolicyTemplateResponse
+	defer setMeta(resp, &out.QueryMeta)
+	if err := s.agent.RPC(req.Context(), "ACL.PolicyTemplateRead", &args, &out); err != nil {
+		// should return permission denied error if missing permissions
+		return nil, err
+	}
+
+	if out.PolicyTemplate == nil {
+		// if no error was returned above, the template does not exist
+		resp.WriteHeader(http.StatusNotFound)
+		msg := acl.ACLResourceNotExistError("policy template", args.EnterpriseMeta)
+		return nil, HTTPError{StatusCode: http.StatusNotFound, Reason: msg.Error()}
+	}
+
+	return out.PolicyTemplate, nil
+}
+
+func (s *HTTPHandlers) ACLPolicyTemplateReadByName(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if s.checkACLDisabled() {
+		return nil, aclDisabled
+	}
+
+	templateName := strings.TrimPrefix(req.URL.Path, "/v1/acl/policy-template/name/")
+	if templateName == "" {
+		return nil, HTTPError{StatusCode: http.StatusBadRequest, Reason: "Missing policy template Name"}
+	}
+
+	return s.ACLPolicyTemplateRead(resp, req, "", templateName)
+}
+
+func (s *HTTPHandlers) ACLPolicyTemplateReadByID(resp http.ResponseWriter, req *http.Request, templateID string) (interface{}, error) {
+	return s.ACLPolicyTemplateRead(resp, req, templateID, "")
+}
+
+func (s *HTTPHandlers) ACLPolicyTemplateCreate(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if s.checkACLDisabled() {
+		return nil, aclDisabled
+	}
+
+	return s.aclPolicyTemplateWriteInternal(resp, req, "", true)
+}
+
+func (s *HTTPHandlers) ACLPolicyTemplateWrite(resp http.ResponseWriter, req *http.Request, templateID string) (interface{}, error) {
+	return s.aclPolicyTemplateWriteInternal(resp, req, templateID, false)
+}
+
+func (s *HTTPHandlers) aclPolicyTemplateWriteInternal(_resp http.ResponseWriter, req *http.Request, templateID string, create bool) (interface{}, error) {
+	args := structs.ACLPolicyTemplateSetRequest{
+		Datacenter: s.agent.config.Datacenter,
+	}
+	s.parseToken(req, &args.Token)
+	if err := s.parseEntMeta(req, &args.PolicyTemplate.EnterpriseMeta); err != nil {
+		return nil, err
+	}
+
+	if err := s.rewordUnknownEnterpriseFieldError(lib.DecodeJSON(req.Body, &args.PolicyTemplate)); err != nil {
+		return nil, HTTPError{StatusCode: http.StatusBadRequest, Reason: fmt.Sprintf("Policy template decoding failed: %v", err)}
+	}
+
+	if create {
+		if args.PolicyTemplate.ID != "" {
+			return nil, HTTPError{StatusCode: http.StatusBadRequest, Reason: "Cannot specify the ID when creating a new policy template"}
+		}
+	} else {
+		if args.PolicyTemplate.ID != "" && args.PolicyTemplate.ID != templateID {
+			return nil, HTTPError{StatusCode: http.StatusBadRequest, Reason: "Policy template ID in URL and payload do not match"}
+		} else if args.PolicyTemplate.ID == "" {
+			args.PolicyTemplate.ID = templateID
+		}
+	}
+
+	var out structs.ACLPolicyTemplate
+	if err := s.agent.RPC(req.Context(), "ACL.PolicyTemplateSet", args, &out); err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+func (s *HTTPHandlers) ACLPolicyTemplateDelete(resp http.ResponseWriter, req *http.Request, templateID string) (interface{}, error) {
+	args := structs.ACLPolicyTemplateDeleteRequest{
+		Datacenter:       s.agent.config.Datacenter,
+		PolicyTemplateID: templateID,
+	}
+	s.parseToken(req, &args.Token)
+	if err := s.parseEntMeta(req, &args.EnterpriseMeta); err != nil {
+		return nil, err
+	}
+
+	var ignored string
+	if err := s.agent.RPC(req.Context(), "ACL.PolicyTemplateDelete", args, &ignored); err != nil {
+		if strings.Contains(err.Error(), acl.ErrNotFound.Error()) {
+			resp.WriteHeader(http.StatusNotFound)
+			return nil, HTTPError{StatusCode: http.StatusNotFound, Reason: "Cannot find policy template to delete"}
+		}
+		return nil, err
+	}
+
+	return true, nil
+}
+
 func (s *HTTPHandlers) ACLTokenList(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
 	if s.checkACLDisabled() {
 		return nil, aclDisabled
@@ -297,6 +483,13 @@ func (s *HTTPHandlers) ACLTokenList(resp http.ResponseWriter, req *http.Request)
 	if err := parseACLAuthMethodEnterpriseMeta(req, &args.ACLAuthMethodEnterpriseMeta); err != nil {
 		return nil, err
 	}
+	if unusedFor := req.URL.Query().Get("unused-for"); unusedFor != "" {
+		dur, err := time.ParseDuration(unusedFor)
+		if err != nil {
+			return nil, HTTPError{StatusCode: http.StatusBadRequest, Reason: fmt.Sprintf("Invalid unused-for value %q: %v", unusedFor, err)}
+		}
+		args.UnusedFor = dur
+	}
 
 	var out structs.ACLTokenListResponse
 	defer setMeta(resp, &out.QueryMeta)
@@ -384,6 +577,32 @@ func (s *HTTPHandlers) ACLTokenCreate(resp http.ResponseWriter, req *http.Reques
 	return s.aclTokenSetInternal(req, "", true)
 }
 
+func (s *HTTPHandlers) ACLTokenCreateChild(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if s.checkACLDisabled() {
+		return nil, aclDisabled
+	}
+
+	args := structs.ACLTokenSetRequest{
+		Datacenter: s.agent.config.Datacenter,
+		Create:     true,
+	}
+	s.parseToken(req, &args.Token)
+	if err := s.parseEntMeta(req, &args.ACLToken.EnterpriseMeta); err != nil {
+		return nil, err
+	}
+
+	if err := s.rewordUnknownEnterpriseFieldError(lib.DecodeJSON(req.Body, &args.ACLToken)); err != nil {
+		return nil, HTTPError{StatusCode: http.StatusBadRequest, Reason: fmt.Sprintf("Token decoding failed: %v", err)}
+	}
+
+	var out structs.ACLToken
+	if err := s.agent.RPC(req.Context(), "ACL.TokenCreateChild", args, &out); err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
 func (s *HTTPHandlers) ACLTokenGet(resp http.ResponseWriter, req *http.Request, tokenAccessorID string) (interface{}, error) {
 	args := structs.ACLTokenGetRequest{
 		Datacenter:  s.agent.config.Datacenter,
@@ -1132,3 +1351,51 @@ func (s *HTTPHandlers) ACLAuthorize(resp http.ResponseWriter, req *http.Request)
 
 	return responses, nil
 }
+
+// ACLAuthorizeExplain returns the decisions of the authorizations in the
+// body along with the policy and rule that produced them. Unlike ACLAuthorize
+// it always goes through the servers, which hold the policies of the token.
+func (s *HTTPHandlers) ACLAuthorizeExplain(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	const maxRequests = 64
+
+	if s.checkACLDisabled() {
+		return nil, aclDisabled
+	}
+
+	args := structs.ACLAuthorizeExplainRequest{
+		Datacenter: s.agent.config.Datacenter,
+	}
+	s.parseToken(req, &args.Token)
+	s.parseDC(req, &args.Datacenter)
+	if err := s.parseEntMeta(req, &args.EnterpriseMeta); err != nil {
+		return nil, err
+	}
+
+	var body struct {
+		AccessorID string
+		Requests   []structs.ACLAuthorizationRequest
+	}
+	if err := decodeBody(req.Body, &body); err != nil {
+		return nil, HTTPError{StatusCode: http.StatusBadRequest, Reason: fmt.Sprintf("Failed to decode request body: %v", err)}
+	}
+	args.AccessorID = body.AccessorID
+	args.Requests = body.Requests
+
+	if len(args.Requests) > maxRequests {
+		return nil, HTTPError{StatusCode: http.StatusBadRequest, Reason: fmt.Sprintf("Refusing to process more than %d authorizations at once", maxRequests)}
+	}
+
+	if len(args.Requests) == 0 {
+		return make([]structs.ACLAuthorizationExplanation, 0), nil
+	}
+
+	var out []structs.ACLAuthorizationExplanation
+	if err := s.agent.RPC(req.Context(), "ACL.AuthorizeExplain", &args, &out); err != nil {
+		return nil, err
+	}
+
+	if out == nil {
+		out = make([]structs.ACLAuthorizationExplanation, 0)
+	}
+	return out, nil
+}
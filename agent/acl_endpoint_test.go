@@ -61,6 +61,9 @@ func TestACL_Disabled_Response(t *testing.T) {
 		{"ACLPolicyList", a.srv.ACLPolicyList},
 		{"ACLPolicyCRUD", a.srv.ACLPolicyCRUD},
 		{"ACLPolicyCreate", a.srv.ACLPolicyCreate},
+		{"ACLPolicyTemplateList", a.srv.ACLPolicyTemplateList},
+		{"ACLPolicyTemplateCRUD", a.srv.ACLPolicyTemplateCRUD},
+		{"ACLPolicyTemplateCreate", a.srv.ACLPolicyTemplateCreate},
 		{"ACLTokenList", a.srv.ACLTokenList},
 		{"ACLTokenCreate", a.srv.ACLTokenCreate},
 		{"ACLTokenSelf", a.srv.ACLTokenSelf},
@@ -38,6 +38,7 @@ import (
 	"github.com/hernad/consul/acl"
 	"github.com/hernad/consul/acl/resolver"
 	"github.com/hernad/consul/agent/ae"
+	"github.com/hernad/consul/agent/audit"
 	"github.com/hernad/consul/agent/cache"
 	cachetype "github.com/hernad/consul/agent/cache-types"
 	"github.com/hernad/consul/agent/checks"
@@ -46,6 +47,7 @@ import (
 	rpcRate "github.com/hernad/consul/agent/consul/rate"
 	"github.com/hernad/consul/agent/consul/servercert"
 	"github.com/hernad/consul/agent/dns"
+	"github.com/hernad/consul/agent/exec"
 	external "github.com/hernad/consul/agent/grpc-external"
 	grpcDNS "github.com/hernad/consul/agent/grpc-external/services/dns"
 	middleware "github.com/hernad/consul/agent/grpc-middleware"
@@ -86,8 +88,9 @@ const (
 	proxyDir = "proxies"
 
 	// Path to save local agent checks
-	checksDir     = "checks"
-	checkStateDir = "checks/state"
+	checksDir       = "checks"
+	checkStateDir   = "checks/state"
+	checkHistoryDir = "checks/history"
 
 	// Default reasons for node/service maintenance mode
 	defaultNodeMaintReason = "Maintenance mode is enabled for this node, " +
@@ -287,6 +290,15 @@ type Agent struct {
 	// checkOSServices maps the check ID to an associated OS Service check
 	checkOSServices map[structs.CheckID]*checks.CheckOSService
 
+	// checkDNSs maps the check ID to an associated DNS check
+	checkDNSs map[structs.CheckID]*checks.CheckDNS
+
+	// checkTLSCerts maps the check ID to an associated TLS certificate check
+	checkTLSCerts map[structs.CheckID]*checks.CheckTLSCert
+
+	// checkComposites maps the check ID to an associated Composite check
+	checkComposites map[structs.CheckID]*checks.CheckComposite
+
 	// exposedPorts tracks listener ports for checks exposed through a proxy
 	exposedPorts map[string]int
 
@@ -454,6 +466,9 @@ func New(bd BaseDeps) (*Agent, error) {
 		checkDockers:    make(map[structs.CheckID]*checks.CheckDocker),
 		checkAliases:    make(map[structs.CheckID]*checks.CheckAlias),
 		checkOSServices: make(map[structs.CheckID]*checks.CheckOSService),
+		checkDNSs:       make(map[structs.CheckID]*checks.CheckDNS),
+		checkTLSCerts:   make(map[structs.CheckID]*checks.CheckTLSCert),
+		checkComposites: make(map[structs.CheckID]*checks.CheckComposite),
 		eventCh:         make(chan serf.UserEvent, 1024),
 		eventBuf:        make([]*UserEvent, 256),
 		joinLANNotifier: &systemd.Notifier{},
@@ -565,15 +580,20 @@ func (a *Agent) GetConfig() *config.RuntimeConfig {
 // LocalConfig takes a config.RuntimeConfig and maps the fields to a local.Config
 func LocalConfig(cfg *config.RuntimeConfig) local.Config {
 	lc := local.Config{
-		AdvertiseAddr:       cfg.AdvertiseAddrLAN.String(),
-		CheckUpdateInterval: cfg.CheckUpdateInterval,
-		Datacenter:          cfg.Datacenter,
-		DiscardCheckOutput:  cfg.DiscardCheckOutput,
-		NodeID:              cfg.NodeID,
-		NodeName:            cfg.NodeName,
-		NodeLocality:        cfg.StructLocality(),
-		Partition:           cfg.PartitionOrDefault(),
-		TaggedAddresses:     map[string]string{},
+		AdvertiseAddr:           cfg.AdvertiseAddrLAN.String(),
+		CheckUpdateInterval:     cfg.CheckUpdateInterval,
+		Datacenter:              cfg.Datacenter,
+		DiscardCheckOutput:      cfg.DiscardCheckOutput,
+		NodeID:                  cfg.NodeID,
+		NodeName:                cfg.NodeName,
+		NodeLocality:            cfg.StructLocality(),
+		Partition:               cfg.PartitionOrDefault(),
+		TaggedAddresses:         map[string]string{},
+		CheckHistorySize:        cfg.CheckHistorySize,
+		CheckFlapWindow:         cfg.CheckFlapWindow,
+		CheckFlapStartThreshold: cfg.CheckFlapStartThreshold,
+		CheckFlapStopThreshold:  cfg.CheckFlapStopThreshold,
+		CheckFlapStatus:         cfg.CheckFlapStatus,
 	}
 	for k, v := range cfg.TaggedAddresses {
 		lc.TaggedAddresses[k] = v
@@ -743,6 +763,7 @@ func (a *Agent) Start(ctx context.Context) error {
 	if err := a.loadChecks(c, nil); err != nil {
 		return err
 	}
+	a.loadCheckHistory()
 	if err := a.loadMetadata(c); err != nil {
 		return err
 	}
@@ -936,6 +957,9 @@ func (a *Agent) listenAndServeGRPC() error {
 		},
 		a,
 	)
+	a.xdsServer.PushCoalesceWindow = a.config.XDSPushCoalesceWindow
+	a.xdsServer.EndpointPushCoalesceWindow = a.config.XDSEndpointPushCoalesceWindow
+	a.xdsServer.MaxConcurrentPushes = a.config.XDSMaxConcurrentPushes
 	a.xdsServer.Register(a.externalGRPCServer)
 
 	// Attempt to spawn listeners
@@ -1650,6 +1674,12 @@ func (a *Agent) RPC(ctx context.Context, method string, args interface{}, reply
 
 	a.endpointsLock.RUnlock()
 
+	// Requests decoded from HTTP bodies may carry an ID set by the client,
+	// so it is always replaced.
+	if r, ok := args.(structs.AuditedRequest); ok {
+		r.SetAuditRequestID(audit.RequestIDFromContext(ctx))
+	}
+
 	defer func() {
 		a.writeAuditRPCEvent(method, "OperationComplete")
 	}()
@@ -1719,6 +1749,16 @@ func (a *Agent) ShutdownAgent() error {
 	for _, chk := range a.checkH2PINGs {
 		chk.Stop()
 	}
+	for _, chk := range a.checkDNSs {
+		chk.Stop()
+	}
+	for _, chk := range a.checkTLSCerts {
+		chk.Stop()
+	}
+	for _, chk := range a.checkComposites {
+		chk.Stop()
+	}
+	a.persistCheckHistory()
 
 	// Stop gRPC
 	if a.externalGRPCServer != nil {
@@ -1753,6 +1793,12 @@ func (a *Agent) ShutdownAgent() error {
 		}
 	}
 
+	// The HTTP servers may still be running, their events are dropped from
+	// now on.
+	if err := a.baseDeps.Auditor.Close(); err != nil {
+		a.logger.Warn("could not close the audit log", "error", err)
+	}
+
 	pidErr := a.deletePid()
 	if pidErr != nil {
 		a.logger.Warn("could not delete pid file", "error", pidErr)
@@ -2980,6 +3026,11 @@ func (a *Agent) addCheck(check *structs.HealthCheck, chkType *structs.CheckType,
 				Method:           chkType.Method,
 				Body:             chkType.Body,
 				DisableRedirects: chkType.DisableRedirects,
+				BodyRegex:        chkType.BodyRegex,
+				JSONPath:         chkType.JSONPath,
+				JSONPathValue:    chkType.JSONPathValue,
+				RequiredHeaders:  chkType.RequiredHeaders,
+				LatencyWarning:   chkType.LatencyWarning,
 				Interval:         chkType.Interval,
 				Timeout:          chkType.Timeout,
 				Logger:           a.logger,
@@ -3184,6 +3235,14 @@ func (a *Agent) addCheck(check *structs.HealthCheck, chkType *structs.CheckType,
 				)
 				chkType.Interval = checks.MinInterval
 			}
+			var sandbox *exec.SandboxConfig
+			if a.config.ScriptCheckSandboxEnabled {
+				cfg, err := scriptCheckSandboxConfig(a.config)
+				if err != nil {
+					return fmt.Errorf("failed to configure the sandbox of check %q: %w", cid.String(), err)
+				}
+				sandbox = cfg
+			}
 			monitor := &checks.CheckMonitor{
 				Notify:        a.State,
 				CheckID:       cid,
@@ -3194,6 +3253,7 @@ func (a *Agent) addCheck(check *structs.HealthCheck, chkType *structs.CheckType,
 				Logger:        a.logger,
 				OutputMaxSize: maxOutputSize,
 				StatusHandler: statusHandler,
+				Sandbox:       sandbox,
 			}
 			monitor.Start()
 			a.checkMonitors[cid] = monitor
@@ -3230,6 +3290,62 @@ func (a *Agent) addCheck(check *structs.HealthCheck, chkType *structs.CheckType,
 			h2ping.Start()
 			a.checkH2PINGs[cid] = h2ping
 
+		case chkType.IsDNS():
+			if existing, ok := a.checkDNSs[cid]; ok {
+				existing.Stop()
+				delete(a.checkDNSs, cid)
+			}
+			if chkType.Interval < checks.MinInterval {
+				a.logger.Warn("check has interval below minimum",
+					"check", cid.String(),
+					"minimum_interval", checks.MinInterval,
+				)
+				chkType.Interval = checks.MinInterval
+			}
+
+			dnsCheck := &checks.CheckDNS{
+				CheckID:       cid,
+				ServiceID:     sid,
+				DNS:           chkType.DNS,
+				DNSServer:     chkType.DNSServer,
+				DNSRecordType: chkType.DNSRecordType,
+				DNSExpect:     chkType.DNSExpect,
+				Interval:      chkType.Interval,
+				Timeout:       chkType.Timeout,
+				Logger:        a.logger,
+				StatusHandler: statusHandler,
+			}
+			dnsCheck.Start()
+			a.checkDNSs[cid] = dnsCheck
+
+		case chkType.IsTLSCert():
+			if existing, ok := a.checkTLSCerts[cid]; ok {
+				existing.Stop()
+				delete(a.checkTLSCerts, cid)
+			}
+			if chkType.Interval < checks.MinInterval {
+				a.logger.Warn("check has interval below minimum",
+					"check", cid.String(),
+					"minimum_interval", checks.MinInterval,
+				)
+				chkType.Interval = checks.MinInterval
+			}
+
+			tlsCert := &checks.CheckTLSCert{
+				CheckID:         cid,
+				ServiceID:       sid,
+				TLSCert:         chkType.TLSCert,
+				WarningDays:     chkType.TLSCertWarningDays,
+				CriticalDays:    chkType.TLSCertCriticalDays,
+				Interval:        chkType.Interval,
+				Timeout:         chkType.Timeout,
+				Logger:          a.logger,
+				TLSClientConfig: a.tlsConfigurator.OutgoingTLSConfigForCheck(chkType.TLSSkipVerify, chkType.TLSServerName),
+				StatusHandler:   statusHandler,
+			}
+			tlsCert.Start()
+			a.checkTLSCerts[cid] = tlsCert
+
 		case chkType.IsAlias():
 			if existing, ok := a.checkAliases[cid]; ok {
 				existing.Stop()
@@ -3262,6 +3378,50 @@ func (a *Agent) addCheck(check *structs.HealthCheck, chkType *structs.CheckType,
 			chkImpl.Start()
 			a.checkAliases[cid] = chkImpl
 
+		case chkType.IsComposite():
+			if existing, ok := a.checkComposites[cid]; ok {
+				existing.Stop()
+				delete(a.checkComposites, cid)
+			}
+
+			var rpcReq structs.NodeSpecificRequest
+			rpcReq.Datacenter = a.config.Datacenter
+			rpcReq.EnterpriseMeta = *a.AgentEnterpriseMeta()
+
+			// The token follows the same rules as for alias checks.
+			rpcReq.Token = a.tokens.UserToken()
+			if token != "" {
+				rpcReq.Token = token
+			}
+
+			combined := make([]structs.CheckID, 0, len(chkType.CompositeChecks))
+			for _, id := range chkType.CompositeChecks {
+				combined = append(combined, structs.NewCheckID(types.CheckID(id), &check.EnterpriseMeta))
+			}
+			members := make([]checks.CompositeMember, 0, len(chkType.CompositeMembers))
+			for _, m := range chkType.CompositeMembers {
+				members = append(members, checks.CompositeMember{
+					Node:    m.Node,
+					CheckID: structs.NewCheckID(types.CheckID(m.CheckID), &check.EnterpriseMeta),
+				})
+			}
+			chkImpl := &checks.CheckComposite{
+				Notify:         a.State,
+				RPC:            a.delegate,
+				RPCReq:         rpcReq,
+				CheckID:        cid,
+				Node:           chkType.CompositeNode,
+				Checks:         combined,
+				Members:        members,
+				Operator:       chkType.CompositeOperator,
+				Threshold:      chkType.CompositeThreshold,
+				Status:         chkType.CompositeStatus,
+				PartialStatus:  chkType.CompositePartialStatus,
+				EnterpriseMeta: check.EnterpriseMeta,
+			}
+			chkImpl.Start()
+			a.checkComposites[cid] = chkImpl
+
 		default:
 			return fmt.Errorf("Check type is not valid")
 		}
@@ -3410,6 +3570,18 @@ func (a *Agent) cancelCheckMonitors(checkID structs.CheckID) {
 		check.Stop()
 		delete(a.checkAliases, checkID)
 	}
+	if check, ok := a.checkDNSs[checkID]; ok {
+		check.Stop()
+		delete(a.checkDNSs, checkID)
+	}
+	if check, ok := a.checkTLSCerts[checkID]; ok {
+		check.Stop()
+		delete(a.checkTLSCerts, checkID)
+	}
+	if check, ok := a.checkComposites[checkID]; ok {
+		check.Stop()
+		delete(a.checkComposites, checkID)
+	}
 }
 
 // updateTTLCheck is used to update the status of a TTL check via the Agent API.
@@ -3541,6 +3713,81 @@ func (a *Agent) purgeCheckState(checkID structs.CheckID) error {
 	return err
 }
 
+// persistCheckHistory records the status transitions of all checks into the
+// data dir so that flap detection survives a graceful restart. The history is
+// only kept by this agent: it is lost when the agent doesn't shut down
+// cleanly and it doesn't follow a check registered on another agent.
+func (a *Agent) persistCheckHistory() {
+	if a.config.DataDir == "" || a.config.CheckHistorySize <= 0 {
+		return
+	}
+
+	dir := filepath.Join(a.config.DataDir, checkHistoryDir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		a.logger.Error("failed creating check history dir", "dir", dir, "error", err)
+		return
+	}
+	for cid, c := range a.State.AllCheckStates() {
+		if len(c.History) == 0 {
+			continue
+		}
+		p := persistedCheckHistory{
+			CheckID:        cid.ID,
+			History:        c.History,
+			Flapping:       c.Flapping,
+			EnterpriseMeta: cid.EnterpriseMeta,
+		}
+		buf, err := json.Marshal(p)
+		if err != nil {
+			a.logger.Error("failed encoding check history", "check", cid.String(), "error", err)
+			continue
+		}
+		path := filepath.Join(dir, cid.StringHashSHA256())
+		if err := file.WriteAtomic(path, buf); err != nil {
+			a.logger.Error("failed writing check history", "check", cid.String(), "error", err)
+		}
+	}
+}
+
+// loadCheckHistory restores the status transitions persisted by
+// persistCheckHistory into the loaded checks. The files are removed once
+// read, so a history is never restored twice.
+func (a *Agent) loadCheckHistory() {
+	if a.config.DataDir == "" {
+		return
+	}
+
+	dir := filepath.Join(a.config.DataDir, checkHistoryDir)
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			a.logger.Error("failed reading check history dir", "dir", dir, "error", err)
+		}
+		return
+	}
+	for _, fi := range files {
+		if fi.IsDir() {
+			continue
+		}
+		file := filepath.Join(dir, fi.Name())
+		buf, err := os.ReadFile(file)
+		if err == nil {
+			var p persistedCheckHistory
+			if err := json.Unmarshal(buf, &p); err != nil {
+				a.logger.Error("failed decoding check history", "file", file, "error", err)
+			} else {
+				cid := structs.NewCheckID(p.CheckID, &p.EnterpriseMeta)
+				a.State.RestoreCheckHistory(cid, p.History, p.Flapping)
+			}
+		} else {
+			a.logger.Error("failed reading check history", "file", file, "error", err)
+		}
+		if err := os.Remove(file); err != nil {
+			a.logger.Error("failed removing check history", "file", file, "error", err)
+		}
+	}
+}
+
 // Stats is used to get various debugging state from the sub-systems
 func (a *Agent) Stats() map[string]map[string]string {
 	stats := a.delegate.Stats()
@@ -892,6 +892,64 @@ func (s *HTTPHandlers) AgentCheckFail(resp http.ResponseWriter, req *http.Reques
 	return s.agentCheckUpdate(resp, req, checkID, api.HealthCritical, note)
 }
 
+// AgentCheckHistory returns the recent status transitions of a check and
+// whether it is flapping.
+func (s *HTTPHandlers) AgentCheckHistory(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	path := strings.TrimPrefix(req.URL.Path, "/v1/agent/check/")
+	id, ok := strings.CutSuffix(path, "/history")
+	if !ok {
+		return nil, HTTPError{StatusCode: http.StatusNotFound, Reason: fmt.Sprintf("Unknown agent check endpoint %q", req.URL.Path)}
+	}
+	if id == "" {
+		return nil, HTTPError{StatusCode: http.StatusBadRequest, Reason: "Missing check ID"}
+	}
+
+	var entMeta acl.EnterpriseMeta
+	if err := s.parseEntMetaNoWildcard(req, &entMeta); err != nil {
+		return nil, err
+	}
+
+	var token string
+	s.parseToken(req, &token)
+
+	s.defaultMetaPartitionToAgent(&entMeta)
+	var authzContext acl.AuthorizerContext
+	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, &entMeta, &authzContext)
+	if err != nil {
+		return nil, err
+	}
+
+	if !s.validateRequestPartition(resp, &entMeta) {
+		return nil, nil
+	}
+
+	cid := structs.NewCheckID(types.CheckID(id), &entMeta)
+	c := s.agent.State.CheckState(cid)
+	if c == nil {
+		return nil, HTTPError{StatusCode: http.StatusNotFound, Reason: fmt.Sprintf("Unknown check ID %q", cid.String())}
+	}
+
+	if c.Check.ServiceName != "" {
+		err = authz.ToAllowAuthorizer().ServiceReadAllowed(c.Check.ServiceName, &authzContext)
+	} else {
+		err = authz.ToAllowAuthorizer().NodeReadAllowed(s.agent.config.NodeName, &authzContext)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]api.AgentCheckTransition, 0, len(c.History))
+	for _, t := range c.History {
+		history = append(history, api.AgentCheckTransition{Status: t.Status, Output: t.Output, Time: t.Time})
+	}
+	return &api.AgentCheckHistory{
+		CheckID:  string(c.Check.CheckID),
+		Status:   c.Check.Status,
+		Flapping: c.Flapping,
+		History:  history,
+	}, nil
+}
+
 // checkUpdate is the payload for a PUT to AgentCheckUpdate.
 type checkUpdate struct {
 	// Status us one of the api.Health* states, "passing", "warning", or
@@ -1628,6 +1686,107 @@ func (s *HTTPHandlers) AgentConnectCALeafCert(resp http.ResponseWriter, req *htt
 	return reply, nil
 }
 
+// AgentConnectCAJWTSVID issues a JWT-SVID to the given service.
+//
+// PUT /v1/agent/connect/ca/jwt-svid/:service
+func (s *HTTPHandlers) AgentConnectCAJWTSVID(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	// Get the service name. Note that this is the name of the service,
+	// not the ID of the service instance.
+	serviceName := strings.TrimPrefix(req.URL.Path, "/v1/agent/connect/ca/jwt-svid/")
+	if serviceName == "" {
+		return nil, HTTPError{StatusCode: http.StatusBadRequest, Reason: "Missing service name"}
+	}
+
+	args := structs.CAJWTSVIDRequest{
+		Service: serviceName,
+	}
+	if err := s.parseEntMetaNoWildcard(req, &args.EnterpriseMeta); err != nil {
+		return nil, err
+	}
+	s.parseDC(req, &args.Datacenter)
+	s.parseToken(req, &args.Token)
+
+	var body struct {
+		Audience []string
+		TTL      string
+	}
+	if err := decodeBody(req.Body, &body); err != nil {
+		return nil, HTTPError{StatusCode: http.StatusBadRequest, Reason: fmt.Sprintf("Request decode failed: %v", err)}
+	}
+	if len(body.Audience) == 0 {
+		return nil, HTTPError{StatusCode: http.StatusBadRequest, Reason: "At least one audience is required"}
+	}
+	args.Audience = body.Audience
+	if body.TTL != "" {
+		ttl, err := time.ParseDuration(body.TTL)
+		if err != nil {
+			return nil, HTTPError{StatusCode: http.StatusBadRequest, Reason: fmt.Sprintf("Invalid TTL: %v", err)}
+		}
+		args.TTL = ttl
+	}
+
+	if !s.validateRequestPartition(resp, &args.EnterpriseMeta) {
+		return nil, nil
+	}
+
+	var reply structs.JWTSVID
+	if err := s.agent.RPC(req.Context(), "ConnectCA.SignJWTSVID", &args, &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+// AgentXDSDebug returns the state of the delta xDS stream of a proxy
+// registered with this agent: the resource versions Envoy has ACKed, the
+// pending and rejected resources and the last pushes.
+//
+// GET /v1/agent/xds/debug/:proxy_id
+func (s *HTTPHandlers) AgentXDSDebug(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	id := strings.TrimPrefix(req.URL.Path, "/v1/agent/xds/debug/")
+	if id == "" {
+		return nil, HTTPError{StatusCode: http.StatusBadRequest, Reason: "Missing proxy ID"}
+	}
+
+	var token string
+	s.parseToken(req, &token)
+
+	var entMeta acl.EnterpriseMeta
+	if err := s.parseEntMetaNoWildcard(req, &entMeta); err != nil {
+		return nil, err
+	}
+	s.defaultMetaPartitionToAgent(&entMeta)
+	if !s.validateRequestPartition(resp, &entMeta) {
+		return nil, nil
+	}
+
+	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Authorize using the agent's own enterprise meta, not the token.
+	var authzContext acl.AuthorizerContext
+	s.agent.AgentEnterpriseMeta().FillAuthzContext(&authzContext)
+	if err := authz.ToAllowAuthorizer().AgentReadAllowed(s.agent.config.NodeName, &authzContext); err != nil {
+		return nil, err
+	}
+
+	sid := structs.NewServiceID(id, &entMeta)
+	notFound := HTTPError{StatusCode: http.StatusNotFound, Reason: fmt.Sprintf("no xDS stream for proxy %s", sid.String())}
+	if s.agent.xdsServer == nil {
+		return nil, notFound
+	}
+
+	state, err := s.agent.xdsServer.DeltaStreamDebug(req.Context(), sid)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		return nil, notFound
+	}
+	return state, nil
+}
+
 // AgentConnectAuthorize
 //
 // POST /v1/agent/connect/authorize
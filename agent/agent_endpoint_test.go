@@ -32,6 +32,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/time/rate"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
 
 	"github.com/hernad/consul/acl"
 	"github.com/hernad/consul/acl/resolver"
@@ -3354,6 +3356,55 @@ func TestAgent_UpdateCheck_ACLDeny(t *testing.T) {
 	})
 }
 
+func TestAgent_CheckHistory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := NewTestAgent(t, TestACLConfig())
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	chk := &structs.HealthCheck{Name: "test", CheckID: "test", Status: api.HealthCritical}
+	chkType := &structs.CheckType{TTL: 15 * time.Second}
+	require.NoError(t, a.AddCheck(chk, chkType, false, "", ConfigSourceLocal))
+	require.NoError(t, a.updateTTLCheck(structs.NewCheckID("test", nil), api.HealthPassing, "hello"))
+
+	t.Run("no token", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/v1/agent/check/test/history", nil)
+		resp := httptest.NewRecorder()
+		a.srv.h.ServeHTTP(resp, req)
+		require.Equal(t, http.StatusForbidden, resp.Code)
+	})
+
+	t.Run("unknown check", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/v1/agent/check/nope/history", nil)
+		req.Header.Add("X-Consul-Token", "root")
+		resp := httptest.NewRecorder()
+		a.srv.h.ServeHTTP(resp, req)
+		require.Equal(t, http.StatusNotFound, resp.Code)
+	})
+
+	t.Run("root token", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/v1/agent/check/test/history", nil)
+		req.Header.Add("X-Consul-Token", "root")
+		resp := httptest.NewRecorder()
+		a.srv.h.ServeHTTP(resp, req)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		var out api.AgentCheckHistory
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+		require.Equal(t, "test", out.CheckID)
+		require.Equal(t, api.HealthPassing, out.Status)
+		require.False(t, out.Flapping)
+		require.Len(t, out.History, 2)
+		require.Equal(t, api.HealthCritical, out.History[0].Status)
+		require.Equal(t, api.HealthPassing, out.History[1].Status)
+		require.Equal(t, "hello", out.History[1].Output)
+	})
+}
+
 func TestAgent_RegisterService(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
@@ -7556,6 +7607,98 @@ func requireLeafValidUnderCA(t require.TestingT, issued *structs.IssuedCert, ca
 	require.NoError(t, err)
 }
 
+func TestAgentConnectCAJWTSVID(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	a := NewTestAgent(t, "")
+	defer a.Shutdown()
+	testrpc.WaitForActiveCARoot(t, a.RPC, "dc1", nil)
+
+	// Wait for the leader to create the signing key.
+	var keySet jose.JSONWebKeySet
+	retry.Run(t, func(r *retry.R) {
+		req, _ := http.NewRequest("GET", "/v1/connect/ca/jwks", nil)
+		recorder := httptest.NewRecorder()
+		_, err := a.srv.ConnectCAJWKS(recorder, req)
+		require.NoError(r, err)
+		require.NoError(r, json.NewDecoder(recorder.Body).Decode(&keySet))
+		require.Len(r, keySet.Keys, 1)
+	})
+
+	body := `{"Audience": ["api"], "TTL": "10m"}`
+	req, _ := http.NewRequest("PUT", "/v1/agent/connect/ca/jwt-svid/web", strings.NewReader(body))
+	obj, err := a.srv.AgentConnectCAJWTSVID(httptest.NewRecorder(), req)
+	require.NoError(t, err)
+	svid := obj.(structs.JWTSVID)
+	require.Contains(t, svid.SpiffeID, "/ns/default/dc/dc1/svc/web")
+	require.WithinDuration(t, time.Now().Add(10*time.Minute), svid.ExpiresAt, time.Minute)
+
+	// The token is verified with the key set served by the agent.
+	parsed, err := jwt.ParseSigned(svid.Token)
+	require.NoError(t, err)
+	keys := keySet.Key(parsed.Headers[0].KeyID)
+	require.Len(t, keys, 1)
+	var claims jwt.Claims
+	require.NoError(t, parsed.Claims(keys[0].Key, &claims))
+	require.Equal(t, svid.SpiffeID, claims.Subject)
+	require.Equal(t, jwt.Audience{"api"}, claims.Audience)
+
+	// Invalid requests.
+	for name, tc := range map[string]struct {
+		path, body, err string
+	}{
+		"missing service":  {"/v1/agent/connect/ca/jwt-svid/", body, "Missing service name"},
+		"missing audience": {"/v1/agent/connect/ca/jwt-svid/web", `{}`, "At least one audience is required"},
+		"invalid TTL":      {"/v1/agent/connect/ca/jwt-svid/web", `{"Audience": ["api"], "TTL": "soon"}`, "Invalid TTL"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			req, _ := http.NewRequest("PUT", tc.path, strings.NewReader(tc.body))
+			_, err := a.srv.AgentConnectCAJWTSVID(httptest.NewRecorder(), req)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestAgentXDSDebug(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := NewTestAgent(t, TestACLConfig())
+	defer a.Shutdown()
+
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+	t.Run("no token", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/v1/agent/xds/debug/web-proxy", nil)
+		resp := httptest.NewRecorder()
+		a.srv.h.ServeHTTP(resp, req)
+		require.Equal(t, http.StatusForbidden, resp.Code)
+	})
+
+	t.Run("missing proxy ID", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/v1/agent/xds/debug/", nil)
+		req.Header.Add("X-Consul-Token", "towel")
+		resp := httptest.NewRecorder()
+		a.srv.h.ServeHTTP(resp, req)
+		require.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("proxy not connected", func(t *testing.T) {
+		ro := createACLTokenWithAgentReadPolicy(t, a.srv)
+		req, _ := http.NewRequest("GET", "/v1/agent/xds/debug/web-proxy", nil)
+		req.Header.Add("X-Consul-Token", ro)
+		resp := httptest.NewRecorder()
+		a.srv.h.ServeHTTP(resp, req)
+		require.Equal(t, http.StatusNotFound, resp.Code)
+		require.Contains(t, resp.Body.String(), "no xDS stream for proxy web-proxy")
+	})
+}
+
 func TestAgentConnectAuthorize_badBody(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
@@ -7790,6 +7933,150 @@ func TestAgentConnectAuthorize_deny(t *testing.T) {
 	assert.Contains(t, obj.Reason, "Matched")
 }
 
+func TestAgentConnectAuthorize_revoked(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	a := NewTestAgent(t, "")
+	defer a.Shutdown()
+
+	testrpc.WaitForTestAgent(t, a.RPC, "dc1")
+
+	authorize := func(t require.TestingT, args *structs.ConnectAuthorizeRequest) *connectAuthorizeResp {
+		req, _ := http.NewRequest("POST", "/v1/agent/connect/authorize", jsonReader(args))
+		resp := httptest.NewRecorder()
+		a.srv.h.ServeHTTP(resp, req)
+		require.Equal(t, 200, resp.Code)
+
+		obj := &connectAuthorizeResp{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(obj))
+		return obj
+	}
+
+	web := &structs.ConnectAuthorizeRequest{
+		Target:           "db",
+		ClientCertURI:    connect.TestSpiffeIDService(t, "web").URI().String(),
+		ClientCertSerial: "01:02:03",
+	}
+	other := &structs.ConnectAuthorizeRequest{
+		Target:           "db",
+		ClientCertURI:    connect.TestSpiffeIDService(t, "api").URI().String(),
+		ClientCertSerial: "0a:0b:0c",
+	}
+	require.True(t, authorize(t, web).Authorized)
+	require.True(t, authorize(t, other).Authorized)
+
+	for _, rev := range []*structs.CARevocation{{Service: "web"}, {SerialNumber: "0A0B0C"}} {
+		args := &structs.CARevocationRequest{Datacenter: "dc1", Revocation: rev}
+		var reply structs.CARevocation
+		require.NoError(t, a.RPC(context.Background(), "ConnectCA.Revoke", args, &reply))
+	}
+
+	retry.Run(t, func(r *retry.R) {
+		obj := authorize(r, web)
+		require.False(r, obj.Authorized)
+		require.Contains(r, obj.Reason, `Service identity "web" has been revoked`)
+
+		obj = authorize(r, other)
+		require.False(r, obj.Authorized)
+		require.Contains(r, obj.Reason, "Client certificate 0a:0b:0c has been revoked")
+	})
+}
+
+func TestAgentConnectAuthorize_federatedTrustDomain(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	a := NewTestAgent(t, `
+		connect {
+			ca_config {
+				federated_trust_domains = [
+					{
+						name = "example.org"
+						spiffe_id_templates = ["/ns/{namespace}/sa/{service}"]
+					}
+				]
+			}
+		}
+	`)
+	defer a.Shutdown()
+
+	testrpc.WaitForTestAgent(t, a.RPC, "dc1")
+	target := "db"
+
+	// Allow the local web service and any local service, but deny web of
+	// the federated trust domain.
+	{
+		req := structs.ConfigEntryRequest{
+			Datacenter: "dc1",
+			Entry: &structs.ServiceIntentionsConfigEntry{
+				Kind: structs.ServiceIntentions,
+				Name: target,
+				Sources: []*structs.SourceIntention{
+					{
+						Name:   "web",
+						Action: structs.IntentionActionAllow,
+					},
+					{
+						Name:        "web",
+						TrustDomain: "example.org",
+						Action:      structs.IntentionActionDeny,
+					},
+					{
+						Name:   "*",
+						Action: structs.IntentionActionAllow,
+					},
+				},
+			},
+		}
+
+		var reply bool
+		require.NoError(t, a.RPC(context.Background(), "ConfigEntry.Apply", &req, &reply))
+	}
+
+	authorize := func(uri string) (int, *connectAuthorizeResp) {
+		args := &structs.ConnectAuthorizeRequest{
+			Target:        target,
+			ClientCertURI: uri,
+		}
+		req, _ := http.NewRequest("POST", "/v1/agent/connect/authorize", jsonReader(args))
+		resp := httptest.NewRecorder()
+		a.srv.h.ServeHTTP(resp, req)
+		if resp.Code != 200 {
+			return resp.Code, nil
+		}
+
+		obj := &connectAuthorizeResp{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(obj))
+		return resp.Code, obj
+	}
+
+	code, obj := authorize("spiffe://example.org/ns/default/sa/web")
+	require.Equal(t, 200, code)
+	require.False(t, obj.Authorized)
+	require.Contains(t, obj.Reason, "trust-domain(example.org)")
+
+	code, obj = authorize("spiffe://11111111-2222-3333-4444-555555555555.consul/ns/default/dc/dc1/svc/web")
+	require.Equal(t, 200, code)
+	require.True(t, obj.Authorized)
+	require.Contains(t, obj.Reason, "Matched")
+
+	// The wildcard intention only matches local services.
+	code, obj = authorize("spiffe://example.org/ns/default/sa/api")
+	require.Equal(t, 200, code)
+	require.Equal(t, "Default behavior configured by ACLs", obj.Reason)
+
+	// Paths that don't match the template are not service identities.
+	code, _ = authorize("spiffe://example.org/workload/web")
+	require.Equal(t, 400, code)
+}
+
 // Test when there is an intention allowing service with a different trust
 // domain. We allow this because migration between trust domains shouldn't cause
 // an outage even if we have stale info about current trusted domains. It's safe
@@ -20,6 +20,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"os/user"
 	"path"
 	"path/filepath"
 	"strconv"
@@ -53,6 +54,7 @@ import (
 	"github.com/hernad/consul/agent/hcp"
 	"github.com/hernad/consul/agent/hcp/scada"
 	"github.com/hernad/consul/agent/leafcert"
+	"github.com/hernad/consul/agent/local"
 	"github.com/hernad/consul/agent/structs"
 	"github.com/hernad/consul/agent/token"
 	"github.com/hernad/consul/api"
@@ -3862,6 +3864,45 @@ func TestAgent_purgeCheckState(t *testing.T) {
 	}
 }
 
+func TestAgent_persistCheckHistory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := NewTestAgent(t, "")
+	defer a.Shutdown()
+
+	cid := structs.NewCheckID("check1", nil)
+	require.NoError(t, a.State.AddCheck(&structs.HealthCheck{CheckID: "check1", Status: api.HealthCritical}, "", false))
+	a.State.UpdateCheck(cid, api.HealthPassing, "up")
+	a.State.UpdateCheck(cid, api.HealthCritical, "down")
+	history := a.State.CheckState(cid).History
+	require.Len(t, history, 3)
+
+	a.persistCheckHistory()
+	file := filepath.Join(a.Config.DataDir, checkHistoryDir, cid.StringHashSHA256())
+	require.FileExists(t, file)
+
+	// Simulate the fresh history of a restarted agent, which the persisted
+	// one replaces.
+	c := a.State.CheckState(cid)
+	c.History = []local.CheckTransition{{Status: api.HealthCritical, Time: time.Now()}}
+	a.State.SetCheckState(c)
+
+	a.loadCheckHistory()
+	restored := a.State.CheckState(cid).History
+	require.Len(t, restored, 3)
+	for i := range history {
+		require.Equal(t, history[i].Status, restored[i].Status)
+		require.Equal(t, history[i].Output, restored[i].Output)
+		require.True(t, history[i].Time.Equal(restored[i].Time))
+	}
+
+	// The persisted history is only restored once
+	require.NoFileExists(t, file)
+}
+
 func TestAgent_GetCoordinate(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
@@ -6378,3 +6419,25 @@ func assertDeepEqual(t *testing.T, x, y interface{}, opts ...cmp.Option) {
 		t.Fatalf("assertion failed: values are not equal\n--- expected\n+++ actual\n%v", diff)
 	}
 }
+
+func TestScriptCheckSandboxConfig(t *testing.T) {
+	t.Run("defaults to an unprivileged user", func(t *testing.T) {
+		sandbox, err := scriptCheckSandboxConfig(&config.RuntimeConfig{})
+		require.NoError(t, err)
+		require.NotZero(t, sandbox.UID)
+		require.NotZero(t, sandbox.GID)
+		if os.Getuid() != 0 && os.Getgid() != 0 {
+			require.Equal(t, os.Getuid(), sandbox.UID)
+			require.Equal(t, os.Getgid(), sandbox.GID)
+		}
+	})
+
+	t.Run("refuses root", func(t *testing.T) {
+		if _, err := user.LookupId("0"); err != nil {
+			t.Skip("no root user")
+		}
+		_, err := scriptCheckSandboxConfig(&config.RuntimeConfig{ScriptCheckSandboxUser: "0"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "cannot run as root")
+	})
+}
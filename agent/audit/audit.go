@@ -0,0 +1,260 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package audit records which token performed which request on the HTTP API
+// and the RPC endpoints of the agent.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hernad/consul/logging"
+)
+
+const (
+	// SourceHTTP is the source of the events of HTTP API requests.
+	SourceHTTP = "http"
+
+	// SourceRPC is the source of the events of RPC requests.
+	SourceRPC = "rpc"
+
+	// DecisionAllow is the decision of requests that were not denied by
+	// the ACL system, even if they failed for another reason.
+	DecisionAllow = "allow"
+
+	// DecisionDeny is the decision of requests denied by the ACL system.
+	DecisionDeny = "deny"
+
+	// SinkTypeFile writes the events to a rotating file.
+	SinkTypeFile = "file"
+
+	// SinkTypeStdout writes the events to the standard output of the agent.
+	SinkTypeStdout = "stdout"
+
+	// FormatJSON writes each event as a line of JSON.
+	FormatJSON = "json"
+)
+
+// Config is the configuration of the audit log.
+type Config struct {
+	// Enabled turns on the audit log.
+	Enabled bool
+
+	// RPCEnabled also records the RPC requests handled by the servers.
+	RPCEnabled bool
+
+	// Sinks are where the events are written, each with its own filters.
+	Sinks []SinkConfig
+}
+
+// SinkConfig is the configuration of an audit log sink.
+type SinkConfig struct {
+	Name   string
+	Type   string
+	Format string
+
+	// Path is the file of file sinks. It is rotated like the agent log
+	// file.
+	Path           string
+	RotateDuration time.Duration
+	RotateBytes    int
+	RotateMaxFiles int
+
+	// Endpoints limits the events to the requests whose URL path or RPC
+	// method starts with one of the prefixes. Empty means all requests.
+	Endpoints []string
+
+	// Decisions limits the events to the requests with one of the
+	// decisions. Empty means all decisions.
+	Decisions []string
+
+	// IncludeReads records the read requests too. By default only the
+	// write requests and the denied read requests are recorded.
+	IncludeReads bool
+}
+
+// Event is an entry of the audit log.
+type Event struct {
+	Time      time.Time
+	RequestID string
+	Source    string
+
+	// AccessorID is the accessor ID of the token of the request, empty when
+	// the token could not be resolved or ACLs are disabled.
+	AccessorID string
+
+	// Operation is the HTTP method for HTTP requests, and "read" or
+	// "write" for RPC requests.
+	Operation string
+
+	// Resource is the URL path for HTTP requests and the method for RPC
+	// requests.
+	Resource string
+
+	Decision   string
+	RemoteAddr string `json:",omitempty"`
+	Error      string `json:",omitempty"`
+
+	// ClientRequestID is the X-Request-Id header of HTTP requests. It is set
+	// by the client, or a proxy in front of the agent, and is not trusted
+	// as RequestID is.
+	ClientRequestID string `json:",omitempty"`
+}
+
+type requestIDKey struct{}
+
+// ContextWithRequestID returns a copy of ctx carrying the request ID of the
+// HTTP request, so the RPC requests made for it use the same ID.
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID set by ContextWithRequestID,
+// or an empty string.
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// Logger writes audit events to its sinks.
+type Logger struct {
+	rpcEnabled bool
+	sinks      []*sink
+}
+
+type sink struct {
+	config SinkConfig
+
+	lock   sync.Mutex
+	enc    *json.Encoder
+	closer io.Closer
+	closed bool
+}
+
+// New returns a Logger writing to the sinks of config, or nil if the audit
+// log is disabled.
+func New(config Config) (*Logger, error) {
+	if !config.Enabled {
+		return nil, nil
+	}
+	l := &Logger{rpcEnabled: config.RPCEnabled}
+	for _, c := range config.Sinks {
+		var w io.Writer
+		switch c.Type {
+		case SinkTypeFile:
+			file, err := logging.NewLogFile(c.Path, "audit.log", c.RotateDuration, c.RotateBytes, c.RotateMaxFiles)
+			if err != nil {
+				return nil, fmt.Errorf("failed to open audit sink %q: %w", c.Name, err)
+			}
+			w = file
+		case SinkTypeStdout:
+			w = os.Stdout
+		default:
+			l.Close()
+			return nil, fmt.Errorf("unsupported type %q for audit sink %q", c.Type, c.Name)
+		}
+		l.AddSink(c, w)
+	}
+	return l, nil
+}
+
+// Close closes the files of the sinks. The events logged afterwards are
+// dropped.
+func (l *Logger) Close() error {
+	if l == nil {
+		return nil
+	}
+	var errs error
+	for _, s := range l.sinks {
+		s.lock.Lock()
+		if !s.closed && s.closer != nil {
+			errs = errors.Join(errs, s.closer.Close())
+		}
+		s.closed = true
+		s.lock.Unlock()
+	}
+	return errs
+}
+
+// AddSink adds a sink writing to w, which is closed with the Logger if it
+// is an io.Closer other than the standard output. The type, path and
+// rotation settings of config are ignored.
+func (l *Logger) AddSink(config SinkConfig, w io.Writer) {
+	s := &sink{config: config, enc: json.NewEncoder(w)}
+	if c, ok := w.(io.Closer); ok && w != io.Writer(os.Stdout) {
+		s.closer = c
+	}
+	l.sinks = append(l.sinks, s)
+}
+
+// Wants returns whether a request with the given properties is recorded by
+// any sink. It is cheaper than building the event, which requires resolving
+// the token of the request.
+func (l *Logger) Wants(source, resource string, read bool, decision string) bool {
+	if l == nil || (source == SourceRPC && !l.rpcEnabled) {
+		return false
+	}
+	for _, s := range l.sinks {
+		if s.wants(resource, read, decision) {
+			return true
+		}
+	}
+	return false
+}
+
+// Log writes the event to the sinks that want it. read is whether the
+// request was a read. Errors are ignored so a broken audit log does not fail
+// the requests.
+func (l *Logger) Log(e Event, read bool) {
+	if l == nil {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	for _, s := range l.sinks {
+		if !s.wants(e.Resource, read, e.Decision) {
+			continue
+		}
+		s.lock.Lock()
+		if !s.closed {
+			_ = s.enc.Encode(e)
+		}
+		s.lock.Unlock()
+	}
+}
+
+func (s *sink) wants(resource string, read bool, decision string) bool {
+	if read && decision != DecisionDeny && !s.config.IncludeReads {
+		return false
+	}
+	if len(s.config.Decisions) > 0 && !contains(s.config.Decisions, decision) {
+		return false
+	}
+	if len(s.config.Endpoints) == 0 {
+		return true
+	}
+	for _, prefix := range s.config.Endpoints {
+		if strings.HasPrefix(resource, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
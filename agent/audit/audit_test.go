@@ -0,0 +1,109 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_Wants(t *testing.T) {
+	l := &Logger{}
+	l.AddSink(SinkConfig{Name: "acl", Endpoints: []string{"/v1/acl/", "ACL."}}, &bytes.Buffer{})
+	l.AddSink(SinkConfig{Name: "denied", Decisions: []string{DecisionDeny}, IncludeReads: true}, &bytes.Buffer{})
+
+	tests := []struct {
+		desc     string
+		source   string
+		resource string
+		read     bool
+		decision string
+		want     bool
+	}{
+		{"write matching endpoint", SourceHTTP, "/v1/acl/token", false, DecisionAllow, true},
+		{"write other endpoint", SourceHTTP, "/v1/kv/foo", false, DecisionAllow, false},
+		{"read matching endpoint", SourceHTTP, "/v1/acl/tokens", true, DecisionAllow, false},
+		{"denied read other endpoint", SourceHTTP, "/v1/kv/foo", true, DecisionDeny, true},
+		{"rpc disabled", SourceRPC, "ACL.TokenSet", false, DecisionDeny, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			require.Equal(t, tt.want, l.Wants(tt.source, tt.resource, tt.read, tt.decision))
+		})
+	}
+
+	var nilLogger *Logger
+	require.False(t, nilLogger.Wants(SourceHTTP, "/v1/kv/foo", false, DecisionAllow))
+	nilLogger.Log(Event{}, false)
+}
+
+func TestLogger_Log(t *testing.T) {
+	var kv, all bytes.Buffer
+	l := &Logger{rpcEnabled: true}
+	l.AddSink(SinkConfig{Name: "kv", Endpoints: []string{"KVS."}}, &kv)
+	l.AddSink(SinkConfig{Name: "all", IncludeReads: true}, &all)
+
+	l.Log(Event{Source: SourceRPC, AccessorID: "a1", Operation: "write", Resource: "KVS.Apply", Decision: DecisionAllow}, false)
+	l.Log(Event{Source: SourceRPC, AccessorID: "a2", Operation: "read", Resource: "KVS.Get", Decision: DecisionAllow}, true)
+
+	decode := func(buf *bytes.Buffer) []Event {
+		var events []Event
+		dec := json.NewDecoder(buf)
+		for dec.More() {
+			var e Event
+			require.NoError(t, dec.Decode(&e))
+			events = append(events, e)
+		}
+		return events
+	}
+
+	events := decode(&kv)
+	require.Len(t, events, 1)
+	require.Equal(t, "a1", events[0].AccessorID)
+	require.False(t, events[0].Time.IsZero())
+
+	events = decode(&all)
+	require.Len(t, events, 2)
+	require.Equal(t, "KVS.Get", events[1].Resource)
+}
+
+func TestNew(t *testing.T) {
+	l, err := New(Config{})
+	require.NoError(t, err)
+	require.Nil(t, l)
+
+	dir := t.TempDir()
+	l, err = New(Config{
+		Enabled: true,
+		Sinks: []SinkConfig{{
+			Name: "file",
+			Type: SinkTypeFile,
+			Path: filepath.Join(dir, "audit.json"),
+		}},
+	})
+	require.NoError(t, err)
+	l.Log(Event{Source: SourceHTTP, Resource: "/v1/kv/foo", Decision: DecisionDeny}, false)
+
+	files, err := filepath.Glob(filepath.Join(dir, "audit-*.json"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	data, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"Resource":"/v1/kv/foo"`)
+
+	// Events logged after Close are dropped.
+	require.NoError(t, l.Close())
+	l.Log(Event{Source: SourceHTTP, Resource: "/v1/kv/bar", Decision: DecisionDeny}, false)
+	data, err = os.ReadFile(files[0])
+	require.NoError(t, err)
+	require.NotContains(t, string(data), `"Resource":"/v1/kv/bar"`)
+
+	_, err = New(Config{Enabled: true, Sinks: []SinkConfig{{Name: "bad", Type: "socket"}}})
+	require.EqualError(t, err, `unsupported type "socket" for audit sink "bad"`)
+}
@@ -4,7 +4,15 @@
 package agent
 
 import (
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+
 	"github.com/hernad/consul/acl"
+	"github.com/hernad/consul/agent/config"
+	"github.com/hernad/consul/agent/exec"
+	"github.com/hernad/consul/agent/local"
 	"github.com/hernad/consul/agent/structs"
 	"github.com/hernad/consul/types"
 )
@@ -29,3 +37,56 @@ type persistedCheckState struct {
 	Expires int64
 	acl.EnterpriseMeta
 }
+
+// persistedCheckHistory is used to persist the status transitions of a check
+// across a graceful agent restart, so that flap detection keeps working.
+type persistedCheckHistory struct {
+	CheckID  types.CheckID
+	History  []local.CheckTransition
+	Flapping bool
+	acl.EnterpriseMeta
+}
+
+// defaultSandboxUser is the user sandboxed script checks run as when the agent
+// runs as root and no user is configured.
+const defaultSandboxUser = "nobody"
+
+// scriptCheckSandboxConfig returns the sandbox of script checks configured
+// in the script_check_sandbox stanza. Unless another user is configured, the
+// checks run as the agent user or as nobody when the agent runs as root. They
+// never run as root.
+func scriptCheckSandboxConfig(conf *config.RuntimeConfig) (*exec.SandboxConfig, error) {
+	sandbox := &exec.SandboxConfig{
+		UID:          os.Getuid(),
+		GID:          os.Getgid(),
+		CgroupParent: conf.ScriptCheckSandboxCgroupParent,
+		CPUMax:       conf.ScriptCheckSandboxCPUMax,
+		MemoryMax:    int64(conf.ScriptCheckSandboxMemoryMax),
+	}
+
+	name := conf.ScriptCheckSandboxUser
+	if name == "" {
+		if sandbox.UID != 0 && sandbox.GID != 0 {
+			return sandbox, nil
+		}
+		name = defaultSandboxUser
+	}
+
+	u, err := user.Lookup(name)
+	if err != nil {
+		u, err = user.LookupId(name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unknown user %q: %w", name, err)
+	}
+	if sandbox.UID, err = strconv.Atoi(u.Uid); err != nil {
+		return nil, fmt.Errorf("invalid uid %q for user %q", u.Uid, u.Username)
+	}
+	if sandbox.GID, err = strconv.Atoi(u.Gid); err != nil {
+		return nil, fmt.Errorf("invalid gid %q for user %q", u.Gid, u.Username)
+	}
+	if sandbox.UID == 0 || sandbox.GID == 0 {
+		return nil, fmt.Errorf("script checks cannot run as root in the sandbox, user %q has uid %d and gid %d", u.Username, sandbox.UID, sandbox.GID)
+	}
+	return sandbox, nil
+}
@@ -5,8 +5,10 @@ package checks
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"crypto/tls"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -14,6 +16,7 @@ import (
 	"net/http"
 	"os"
 	osexec "os/exec"
+	"regexp"
 	"strings"
 	"sync"
 	"syscall"
@@ -28,6 +31,7 @@ import (
 	"github.com/hernad/consul/agent/exec"
 	"github.com/hernad/consul/api"
 	"github.com/hernad/consul/lib"
+	"github.com/hernad/consul/lib/jsonpath"
 	"github.com/hashicorp/go-cleanhttp"
 )
 
@@ -42,6 +46,11 @@ const (
 	// from being captured
 	DefaultBufSize = 4 * 1024 // 4KB
 
+	// MaxHTTPAssertionBodySize is the maximum size of the
+	// body of an HTTP check response that BodyRegex and
+	// JSONPath are evaluated against.
+	MaxHTTPAssertionBodySize = 1024 * 1024 // 1MB
+
 	// UserAgent is the value of the User-Agent header
 	// for HTTP health checks.
 	UserAgent = "Consul Health Check"
@@ -79,6 +88,10 @@ type CheckMonitor struct {
 	OutputMaxSize int
 	StatusHandler *StatusHandler
 
+	// Sandbox, when set, runs the script isolated from the host, see
+	// exec.Sandbox.
+	Sandbox *exec.SandboxConfig
+
 	stop     bool
 	stopCh   chan struct{}
 	stopLock sync.Mutex
@@ -145,6 +158,30 @@ func (c *CheckMonitor) check() {
 	cmd.Stderr = output
 	exec.SetSysProcAttr(cmd)
 
+	killCommand := func() error {
+		return exec.KillCommandSubtree(cmd)
+	}
+	if c.Sandbox != nil {
+		sandbox, err := exec.Sandbox(cmd, string(c.CheckID.ID), *c.Sandbox)
+		if err != nil {
+			c.Logger.Error("Check failed to setup sandbox",
+				"check", c.CheckID.String(),
+				"error", err,
+			)
+			c.Notify.UpdateCheck(c.CheckID, api.HealthCritical, err.Error())
+			return
+		}
+		defer func() {
+			if err := sandbox.Cleanup(); err != nil {
+				c.Logger.Warn("Check failed to cleanup sandbox",
+					"check", c.CheckID.String(),
+					"error", err,
+				)
+			}
+		}()
+		killCommand = sandbox.Kill
+	}
+
 	truncateAndLogOutput := func() string {
 		outputStr := string(output.Bytes())
 		if output.TotalWritten() > output.Size() {
@@ -180,7 +217,7 @@ func (c *CheckMonitor) check() {
 	}
 	select {
 	case <-time.After(timeout):
-		if err := exec.KillCommandSubtree(cmd); err != nil {
+		if err := killCommand(); err != nil {
 			c.Logger.Warn("Check failed to kill after timeout",
 				"check", c.CheckID.String(),
 				"error", err,
@@ -336,6 +373,11 @@ func (c *CheckTTL) SetStatus(status, output string) string {
 // The check is warning if the response code is 429.
 // The check is critical if the response code is anything else
 // or if the request returns an error
+// A passing response can additionally be asserted on: the body can be
+// required to match BodyRegex or to contain JSONPath (with the value
+// JSONPathValue), and RequiredHeaders to be present. The check is critical
+// if an assertion fails, and warning if the response took longer than
+// LatencyWarning.
 // Supports failures_before_critical and success_before_passing.
 type CheckHTTP struct {
 	CheckID          structs.CheckID
@@ -351,8 +393,15 @@ type CheckHTTP struct {
 	OutputMaxSize    int
 	StatusHandler    *StatusHandler
 	DisableRedirects bool
+	BodyRegex        string
+	JSONPath         string
+	JSONPathValue    string
+	RequiredHeaders  map[string]string
+	LatencyWarning   time.Duration
 
 	httpClient *http.Client
+	bodyRegex  *regexp.Regexp
+	jsonPath   jsonpath.Path
 	stop       bool
 	stopCh     chan struct{}
 	stopLock   sync.Mutex
@@ -365,15 +414,20 @@ type CheckHTTP struct {
 
 func (c *CheckHTTP) CheckType() structs.CheckType {
 	return structs.CheckType{
-		CheckID:       c.CheckID.ID,
-		HTTP:          c.HTTP,
-		Method:        c.Method,
-		Body:          c.Body,
-		Header:        c.Header,
-		Interval:      c.Interval,
-		ProxyHTTP:     c.ProxyHTTP,
-		Timeout:       c.Timeout,
-		OutputMaxSize: c.OutputMaxSize,
+		CheckID:         c.CheckID.ID,
+		HTTP:            c.HTTP,
+		Method:          c.Method,
+		Body:            c.Body,
+		Header:          c.Header,
+		BodyRegex:       c.BodyRegex,
+		JSONPath:        c.JSONPath,
+		JSONPathValue:   c.JSONPathValue,
+		RequiredHeaders: c.RequiredHeaders,
+		LatencyWarning:  c.LatencyWarning,
+		Interval:        c.Interval,
+		ProxyHTTP:       c.ProxyHTTP,
+		Timeout:         c.Timeout,
+		OutputMaxSize:   c.OutputMaxSize,
 	}
 }
 
@@ -411,6 +465,29 @@ func (c *CheckHTTP) Start() {
 		}
 	}
 
+	if c.BodyRegex != "" && c.bodyRegex == nil {
+		re, err := regexp.Compile(c.BodyRegex)
+		if err != nil {
+			// The regexp has been validated when the check was registered.
+			c.Logger.Error("Check has an invalid body regex",
+				"check", c.CheckID.String(),
+				"error", err,
+			)
+		}
+		c.bodyRegex = re
+	}
+	if c.JSONPath != "" && c.jsonPath == nil {
+		path, err := jsonpath.Parse(c.JSONPath)
+		if err != nil {
+			// The path has been validated when the check was registered.
+			c.Logger.Error("Check has an invalid JSONPath",
+				"check", c.CheckID.String(),
+				"error", err,
+			)
+		}
+		c.jsonPath = path
+	}
+
 	c.stop = false
 	c.stopCh = make(chan struct{})
 	c.stopWg.Add(1)
@@ -484,6 +561,7 @@ func (c *CheckHTTP) check() {
 		req.Header.Set("Accept", "text/plain, text/*, */*")
 	}
 
+	start := time.Now()
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		c.StatusHandler.updateCheck(c.CheckID, api.HealthCritical, err.Error())
@@ -491,21 +569,37 @@ func (c *CheckHTTP) check() {
 	}
 	defer resp.Body.Close()
 
-	// Read the response into a circular buffer to limit the size
+	// Read the response into a circular buffer to limit the size. The body
+	// assertions need the beginning of the body rather than its end so it
+	// is kept separately, up to a limit, when they are configured.
 	output, _ := circbuf.NewBuffer(int64(c.OutputMaxSize))
-	if _, err := io.Copy(output, resp.Body); err != nil {
+	var body bytes.Buffer
+	bodyWriter := &limitedWriter{w: &body, n: MaxHTTPAssertionBodySize}
+	var src io.Reader = resp.Body
+	if c.BodyRegex != "" || c.JSONPath != "" {
+		src = io.TeeReader(resp.Body, bodyWriter)
+	}
+	if _, err := io.Copy(output, src); err != nil {
 		c.Logger.Warn("Check error while reading body",
 			"check", c.CheckID.String(),
 			"error", err,
 		)
 	}
+	latency := time.Since(start)
 
 	// Format the response body
 	result := fmt.Sprintf("HTTP %s %s: %s Output: %s", method, target, resp.Status, output.String())
 
 	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
-		// PASSING (2xx)
-		c.StatusHandler.updateCheck(c.CheckID, api.HealthPassing, result)
+		// PASSING (2xx), unless an assertion fails
+		if msg := c.assert(resp.Header, body.Bytes(), bodyWriter.truncated); msg != "" {
+			c.StatusHandler.updateCheck(c.CheckID, api.HealthCritical, msg+"; "+result)
+		} else if c.LatencyWarning > 0 && latency > c.LatencyWarning {
+			msg := fmt.Sprintf("response took %s, more than %s", latency.Round(time.Millisecond), c.LatencyWarning)
+			c.StatusHandler.updateCheck(c.CheckID, api.HealthWarning, msg+"; "+result)
+		} else {
+			c.StatusHandler.updateCheck(c.CheckID, api.HealthPassing, result)
+		}
 	} else if resp.StatusCode == 429 {
 		// WARNING
 		// 429 Too Many Requests (RFC 6585)
@@ -517,6 +611,99 @@ func (c *CheckHTTP) check() {
 	}
 }
 
+// assert returns why the response doesn't satisfy the assertions of the
+// check, or an empty string if it does. truncated is set when the body was
+// cut off at MaxHTTPAssertionBodySize.
+func (c *CheckHTTP) assert(header http.Header, body []byte, truncated bool) string {
+	for name, want := range c.RequiredHeaders {
+		values, ok := header[http.CanonicalHeaderKey(name)]
+		if !ok {
+			return fmt.Sprintf("response header %q is missing", name)
+		}
+		if want == "" {
+			continue
+		}
+		found := false
+		for _, v := range values {
+			if v == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Sprintf("response header %q is %q, expected %q", name, strings.Join(values, ", "), want)
+		}
+	}
+
+	if c.BodyRegex != "" {
+		if c.bodyRegex == nil {
+			return fmt.Sprintf("body regex %q is invalid", c.BodyRegex)
+		}
+		if !c.bodyRegex.Match(body) {
+			return fmt.Sprintf("response body does not match %q", c.BodyRegex)
+		}
+	}
+
+	if c.JSONPath != "" {
+		if c.jsonPath == nil {
+			return fmt.Sprintf("JSONPath %q is invalid", c.JSONPath)
+		}
+		var doc interface{}
+		if err := json.Unmarshal(body, &doc); err != nil {
+			if truncated {
+				return fmt.Sprintf("response body is larger than %d bytes, JSONPath can't be evaluated", MaxHTTPAssertionBodySize)
+			}
+			return fmt.Sprintf("response body is not valid JSON: %v", err)
+		}
+		v, ok := c.jsonPath.Eval(doc)
+		if !ok {
+			return fmt.Sprintf("JSONPath %q not found in response body", c.JSONPath)
+		}
+		if c.JSONPathValue != "" {
+			if got := jsonValueString(v); got != c.JSONPathValue {
+				return fmt.Sprintf("JSONPath %q is %q, expected %q", c.JSONPath, got, c.JSONPathValue)
+			}
+		}
+	}
+
+	return ""
+}
+
+// jsonValueString formats a value selected by a JSONPath to be compared with
+// the expected value of the check: strings are used as is and any other value
+// is encoded as JSON.
+func jsonValueString(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// limitedWriter writes to w until n bytes have been written and discards the
+// rest, recording that it did so in truncated.
+type limitedWriter struct {
+	w         io.Writer
+	n         int64
+	truncated bool
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	written := len(p)
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+		l.truncated = true
+	}
+	if len(p) > 0 {
+		n, err := l.w.Write(p)
+		l.n -= int64(n)
+		if err != nil {
+			return n, err
+		}
+	}
+	return written, nil
+}
+
 type CheckH2PING struct {
 	CheckID         structs.CheckID
 	ServiceID       structs.ServiceID
@@ -0,0 +1,159 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build linux
+// +build linux
+
+package checks
+
+import (
+	"os"
+	osexec "os/exec"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hernad/consul/agent/exec"
+	"github.com/hernad/consul/agent/mock"
+	"github.com/hernad/consul/agent/structs"
+	"github.com/hernad/consul/api"
+	"github.com/hernad/consul/sdk/testutil"
+	"github.com/hernad/consul/sdk/testutil/retry"
+)
+
+// testSandboxConfig returns a sandbox running as the user of the test, or as
+// nobody when the tests run as root.
+func testSandboxConfig() *exec.SandboxConfig {
+	if os.Getuid() == 0 || os.Getgid() == 0 {
+		return &exec.SandboxConfig{UID: 65534, GID: 65534}
+	}
+	return &exec.SandboxConfig{UID: os.Getuid(), GID: os.Getgid()}
+}
+
+func skipIfNoUserNamespaces(t *testing.T) {
+	t.Helper()
+	cmd := osexec.Command("true")
+	if _, err := exec.Sandbox(cmd, "probe", *testSandboxConfig()); err != nil {
+		t.Skipf("sandbox not supported: %v", err)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Skipf("user namespaces not supported: %v: %s", err, out)
+	}
+}
+
+func TestCheckMonitor_Sandbox(t *testing.T) {
+	skipIfNoUserNamespaces(t)
+
+	notif := mock.NewNotify()
+	logger := testutil.Logger(t)
+	statusHandler := NewStatusHandler(notif, logger, 0, 0, 0)
+
+	cid := structs.NewCheckID("foo", nil)
+	check := &CheckMonitor{
+		Notify:        notif,
+		CheckID:       cid,
+		ScriptArgs:    []string{"sh", "-c", "echo pid=$$ uid=$(id -u)"},
+		Interval:      25 * time.Millisecond,
+		OutputMaxSize: DefaultBufSize,
+		Logger:        logger,
+		StatusHandler: statusHandler,
+		Sandbox:       testSandboxConfig(),
+	}
+	check.Start()
+	defer check.Stop()
+
+	retry.Run(t, func(r *retry.R) {
+		if got, want := notif.State(cid), api.HealthPassing; got != want {
+			r.Fatalf("got state %q want %q", got, want)
+		}
+		// The script is the init process of its PID namespace and is root
+		// in its user namespace.
+		if got, want := strings.TrimSpace(notif.Output(cid)), "pid=1 uid=0"; got != want {
+			r.Fatalf("got output %q want %q", got, want)
+		}
+	})
+}
+
+func TestCheckMonitor_Sandbox_Mounts(t *testing.T) {
+	skipIfNoUserNamespaces(t)
+
+	f, err := os.CreateTemp("", "sandbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+
+	notif := mock.NewNotify()
+	logger := testutil.Logger(t)
+	statusHandler := NewStatusHandler(notif, logger, 0, 0, 0)
+
+	// /proc only shows the processes of the sandbox, /tmp is empty and the
+	// rest of the filesystem is read-only.
+	cid := structs.NewCheckID("foo", nil)
+	check := &CheckMonitor{
+		Notify:  notif,
+		CheckID: cid,
+		ScriptArgs: []string{"sh", "-c", `
+			echo init=$(cat /proc/1/comm)
+			test -e /proc/` + strconv.Itoa(os.Getpid()) + ` && echo host_proc=visible || echo host_proc=hidden
+			test -e ` + f.Name() + ` && echo host_tmp=visible || echo host_tmp=hidden
+			touch /etc/sandbox 2>/dev/null && echo etc=writable || echo etc=readonly
+		`},
+		Interval:      time.Hour,
+		OutputMaxSize: DefaultBufSize,
+		Logger:        logger,
+		StatusHandler: statusHandler,
+		Sandbox:       testSandboxConfig(),
+	}
+	check.check()
+
+	if got, want := notif.State(cid), api.HealthPassing; got != want {
+		t.Fatalf("got state %q want %q: %s", got, want, notif.Output(cid))
+	}
+	if got, want := strings.Join(strings.Fields(notif.Output(cid)), " "), "init=sh host_proc=hidden host_tmp=hidden etc=readonly"; got != want {
+		t.Fatalf("got output %q want %q", got, want)
+	}
+}
+
+func TestCheckMonitor_Sandbox_TimeoutKillsProcessTree(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+	skipIfNoUserNamespaces(t)
+
+	notif := mock.NewNotify()
+	logger := testutil.Logger(t)
+	statusHandler := NewStatusHandler(notif, logger, 0, 0, 0)
+
+	// The child leaves its process group, it would survive the kill of the
+	// process group outside of the sandbox.
+	cid := structs.NewCheckID("foo", nil)
+	check := &CheckMonitor{
+		Notify:        notif,
+		CheckID:       cid,
+		ScriptArgs:    []string{"sh", "-c", "setsid sleep 10 & sleep 10"},
+		Interval:      time.Hour,
+		Timeout:       100 * time.Millisecond,
+		OutputMaxSize: DefaultBufSize,
+		Logger:        logger,
+		StatusHandler: statusHandler,
+		Sandbox:       testSandboxConfig(),
+	}
+
+	start := time.Now()
+	check.check()
+
+	if got, want := notif.State(cid), api.HealthCritical; got != want {
+		t.Fatalf("got state %q want %q", got, want)
+	}
+	if !strings.HasPrefix(notif.Output(cid), "Timed out") {
+		t.Fatalf("unexpected output %q", notif.Output(cid))
+	}
+	// check waits for the process to exit, which would take 10s if the
+	// namespace wasn't killed.
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("check took %s", elapsed)
+	}
+}
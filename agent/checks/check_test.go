@@ -506,6 +506,135 @@ func TestCheckHTTP_DisableRedirects(t *testing.T) {
 	})
 }
 
+func TestCheckHTTP_Assertions(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(50 * time.Millisecond)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/large" {
+			fmt.Fprintf(w, `{"padding":%q,"status":"ok"}`, strings.Repeat("x", MaxHTTPAssertionBodySize))
+			return
+		}
+		w.Header().Set("X-Version", "1.2.3")
+		fmt.Fprint(w, `{"status":"ok","checks":[{"name":"db","healthy":true}],"app.version":3}`)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		desc   string
+		check  *CheckHTTP
+		path   string
+		status string
+		output string
+	}{
+		{
+			desc:   "body regex matches",
+			check:  &CheckHTTP{BodyRegex: `"status":\s*"ok"`},
+			status: api.HealthPassing,
+		},
+		{
+			desc:   "body regex does not match",
+			check:  &CheckHTTP{BodyRegex: `"status":\s*"degraded"`},
+			status: api.HealthCritical,
+			output: "response body does not match",
+		},
+		{
+			desc:   "json path exists",
+			check:  &CheckHTTP{JSONPath: "$.checks[0].name"},
+			status: api.HealthPassing,
+		},
+		{
+			desc:   "json path is missing",
+			check:  &CheckHTTP{JSONPath: "$.checks[1].name"},
+			status: api.HealthCritical,
+			output: `JSONPath "$.checks[1].name" not found`,
+		},
+		{
+			desc:   "json path value matches",
+			check:  &CheckHTTP{JSONPath: "$.checks[0].healthy", JSONPathValue: "true"},
+			status: api.HealthPassing,
+		},
+		{
+			desc:   "json path quoted key",
+			check:  &CheckHTTP{JSONPath: "$['app.version']", JSONPathValue: "3"},
+			status: api.HealthPassing,
+		},
+		{
+			desc:   "json path value does not match",
+			check:  &CheckHTTP{JSONPath: "$.status", JSONPathValue: "degraded"},
+			status: api.HealthCritical,
+			output: `JSONPath "$.status" is "ok", expected "degraded"`,
+		},
+		{
+			desc:   "json path in a truncated body",
+			check:  &CheckHTTP{JSONPath: "$.status"},
+			path:   "/large",
+			status: api.HealthCritical,
+			output: "response body is larger than 1048576 bytes",
+		},
+		{
+			desc:   "required headers",
+			check:  &CheckHTTP{RequiredHeaders: map[string]string{"x-version": "1.2.3", "Content-Type": ""}},
+			status: api.HealthPassing,
+		},
+		{
+			desc:   "required header is missing",
+			check:  &CheckHTTP{RequiredHeaders: map[string]string{"X-Missing": ""}},
+			status: api.HealthCritical,
+			output: `response header "X-Missing" is missing`,
+		},
+		{
+			desc:   "required header has another value",
+			check:  &CheckHTTP{RequiredHeaders: map[string]string{"X-Version": "2.0.0"}},
+			status: api.HealthCritical,
+			output: `response header "X-Version" is "1.2.3", expected "2.0.0"`,
+		},
+		{
+			desc:   "latency below threshold",
+			check:  &CheckHTTP{LatencyWarning: time.Minute},
+			status: api.HealthPassing,
+		},
+		{
+			desc:   "latency above threshold",
+			check:  &CheckHTTP{LatencyWarning: time.Millisecond},
+			path:   "/slow",
+			status: api.HealthWarning,
+			output: "more than 1ms",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			notif := mock.NewNotify()
+			logger := testutil.Logger(t)
+			statusHandler := NewStatusHandler(notif, logger, 0, 0, 0)
+			cid := structs.NewCheckID("foo", nil)
+
+			check := tt.check
+			check.CheckID = cid
+			check.HTTP = server.URL + tt.path
+			check.Interval = 10 * time.Millisecond
+			check.Logger = logger
+			check.StatusHandler = statusHandler
+			check.Start()
+			defer check.Stop()
+
+			retry.Run(t, func(r *retry.R) {
+				if got, want := notif.State(cid), tt.status; got != want {
+					r.Fatalf("got state %q want %q", got, want)
+				}
+				if output := notif.Output(cid); !strings.Contains(output, tt.output) {
+					r.Fatalf("output %q does not contain %q", output, tt.output)
+				}
+			})
+		})
+	}
+}
+
 func TestCheckHTTPTCP_BigTimeout(t *testing.T) {
 	testCases := []struct {
 		timeoutIn, intervalIn, timeoutWant time.Duration
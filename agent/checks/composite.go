@@ -0,0 +1,336 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package checks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hernad/consul/acl"
+	"github.com/hernad/consul/agent/structs"
+	"github.com/hernad/consul/api"
+)
+
+// CheckComposite is a check type that combines the health of other checks.
+// A combined check is failing when it is warning, critical or missing. The
+// composite check has the Status status when enough of the combined checks
+// are failing: all of them with the "and" operator, any of them with the "or"
+// operator, or at least Threshold of them. It has the PartialStatus status
+// when some of the combined checks are failing but not enough, and is passing
+// otherwise.
+type CheckComposite struct {
+	Node          string            // Node name of Checks. If empty, assumed to be this node.
+	Checks        []structs.CheckID // IDs of the checks to combine
+	Members       []CompositeMember // Checks to combine along with their node, in addition to Checks
+	Operator      string            // structs.CompositeOperatorAnd or structs.CompositeOperatorOr
+	Threshold     int               // Number of failing checks required, overrides Operator if set
+	Status        string            // Status of the check when enough checks are failing
+	PartialStatus string            // Status of the check when some checks are failing
+
+	CheckID structs.CheckID             // ID of this check
+	RPC     RPC                         // Used to query remote server if necessary
+	RPCReq  structs.NodeSpecificRequest // Base request
+	Notify  CompositeNotifier           // For updating the check state
+
+	stop     bool
+	stopCh   chan struct{}
+	stopLock sync.Mutex
+	stopWg   sync.WaitGroup
+
+	// remote holds the last result of the query of each node the combined
+	// checks are on, keyed by the lowercased node name.
+	remote     map[string]compositeNodeChecks
+	remoteLock sync.Mutex
+
+	acl.EnterpriseMeta
+}
+
+// CompositeMember is a check combined by a composite check along with the
+// node it is registered on.
+type CompositeMember struct {
+	Node    string // If empty, assumed to be this node.
+	CheckID structs.CheckID
+}
+
+// compositeNodeChecks is the result of the query of the checks of a node.
+type compositeNodeChecks struct {
+	checks []*structs.HealthCheck
+	err    error
+}
+
+// CompositeNotifier is a CheckNotifier specifically for the Composite check.
+// This requires additional methods that are satisfied by the agent local
+// state.
+type CompositeNotifier interface {
+	CheckNotifier
+
+	AddCompositeCheck(structs.CheckID, []structs.CheckID, chan<- struct{}) error
+	RemoveCompositeCheck(structs.CheckID)
+	Checks(*acl.EnterpriseMeta) map[structs.CheckID]*structs.HealthCheck
+}
+
+// Start is used to start the check, runs until Stop() is called.
+func (c *CheckComposite) Start() {
+	c.stopLock.Lock()
+	defer c.stopLock.Unlock()
+	c.stop = false
+	c.stopCh = make(chan struct{})
+	c.stopWg.Add(1)
+	go c.run(c.stopCh)
+}
+
+// Stop is used to stop the check.
+func (c *CheckComposite) Stop() {
+	c.stopLock.Lock()
+	if !c.stop {
+		c.stop = true
+		close(c.stopCh)
+	}
+	c.stopLock.Unlock()
+
+	// Wait until the associated goroutine is complete so that a new check
+	// can't race with the old one, see CheckAlias.Stop.
+	c.stopWg.Wait()
+}
+
+// members returns all the combined checks: Checks on Node followed by
+// Members.
+func (c *CheckComposite) members() []CompositeMember {
+	members := make([]CompositeMember, 0, len(c.Checks)+len(c.Members))
+	for _, id := range c.Checks {
+		members = append(members, CompositeMember{Node: c.Node, CheckID: id})
+	}
+	return append(members, c.Members...)
+}
+
+// run is invoked in a goroutine until Stop() is called. The checks of this
+// node are watched in the local state and the checks of every other node
+// with a blocking query.
+func (c *CheckComposite) run(stopCh chan struct{}) {
+	defer c.stopWg.Done()
+
+	var local []structs.CheckID
+	remote := make(map[string]string)
+	for _, m := range c.members() {
+		if m.Node == "" {
+			local = append(local, m.CheckID)
+		} else {
+			remote[strings.ToLower(m.Node)] = m.Node
+		}
+	}
+
+	// Buffered as 1 so that we do not lose any queued updates, see
+	// CheckAlias.runLocal.
+	notifyCh := make(chan struct{}, 1)
+	if len(local) > 0 {
+		if err := c.Notify.AddCompositeCheck(c.CheckID, local, notifyCh); err != nil {
+			c.Notify.UpdateCheck(c.CheckID, api.HealthCritical, err.Error())
+			return
+		}
+		defer c.Notify.RemoveCompositeCheck(c.CheckID)
+	}
+
+	c.remoteLock.Lock()
+	c.remote = make(map[string]compositeNodeChecks, len(remote))
+	c.remoteLock.Unlock()
+
+	var wg sync.WaitGroup
+	for _, node := range remote {
+		wg.Add(1)
+		go func(node string) {
+			defer wg.Done()
+			c.runQuery(node, notifyCh, stopCh)
+		}(node)
+	}
+	defer wg.Wait()
+
+	// Re-evaluate the checks periodically in case we miss an edge triggered
+	// event.
+	const maxDurationBetweenUpdates = 1 * time.Minute
+
+	for {
+		var localChecks []*structs.HealthCheck
+		if len(local) > 0 {
+			for _, chk := range c.Notify.Checks(c.WithWildcardNamespace()) {
+				localChecks = append(localChecks, chk)
+			}
+		}
+
+		c.remoteLock.Lock()
+		ready := len(c.remote) == len(remote)
+		var remoteChecks []*structs.HealthCheck
+		var remoteErr error
+		for _, node := range remote {
+			result := c.remote[strings.ToLower(node)]
+			if result.err != nil && remoteErr == nil {
+				remoteErr = fmt.Errorf("Failure checking combined checks on node %s: %s", node, result.err)
+			}
+			remoteChecks = append(remoteChecks, result.checks...)
+		}
+		c.remoteLock.Unlock()
+
+		// Wait for the first result of every node so that their checks are
+		// not reported as missing in the meantime.
+		switch {
+		case remoteErr != nil:
+			c.Notify.UpdateCheck(c.CheckID, api.HealthCritical, remoteErr.Error())
+		case ready:
+			c.processChecks(localChecks, remoteChecks)
+		}
+
+		select {
+		case <-time.After(maxDurationBetweenUpdates):
+		case <-notifyCh:
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// runQuery watches the checks of the given node with a blocking query, stores
+// them in c.remote and notifies notifyCh when they change.
+func (c *CheckComposite) runQuery(node string, notifyCh chan<- struct{}, stopCh chan struct{}) {
+	args := c.RPCReq
+	args.Node = node
+	args.AllowStale = true
+	args.MaxQueryTime = 1 * time.Minute
+	args.EnterpriseMeta = c.EnterpriseMeta
+	// We are late at maximum of 15s compared to leader
+	args.MaxStaleDuration = 15 * time.Second
+
+	update := func(result compositeNodeChecks) {
+		c.remoteLock.Lock()
+		c.remote[strings.ToLower(node)] = result
+		c.remoteLock.Unlock()
+
+		select {
+		case notifyCh <- struct{}{}:
+		default:
+		}
+	}
+
+	var attempt uint
+	for {
+		// Check if we're stopped. We fallthrough and block otherwise,
+		// which has a maximum time set above so we'll always check for
+		// stop within a reasonable amount of time.
+		select {
+		case <-stopCh:
+			return
+		default:
+		}
+
+		// Backoff if we have to
+		if attempt > checkAliasBackoffMin {
+			shift := attempt - checkAliasBackoffMin
+			if shift > 31 {
+				shift = 31 // so we don't overflow to 0
+			}
+			waitTime := (1 << shift) * time.Second
+			if waitTime > checkAliasBackoffMaxWait {
+				waitTime = checkAliasBackoffMaxWait
+			}
+			time.Sleep(waitTime)
+		}
+
+		var out structs.IndexedHealthChecks
+		if err := c.RPC.RPC(context.Background(), "Health.NodeChecks", &args, &out); err != nil {
+			attempt++
+			if attempt > 1 {
+				update(compositeNodeChecks{err: err})
+			}
+
+			continue
+		}
+
+		attempt = 0 // Reset the attempts so we don't backoff the next
+
+		// Set our index for the next request, always blocking on subsequent
+		// requests to avoid hot loops.
+		args.MinQueryIndex = out.Index
+		if args.MinQueryIndex < 1 {
+			args.MinQueryIndex = 1
+		}
+
+		var checks []*structs.HealthCheck
+		for _, chk := range out.HealthChecks {
+			if strings.EqualFold(chk.Node, node) {
+				checks = append(checks, chk)
+			}
+		}
+		update(compositeNodeChecks{checks: checks})
+	}
+}
+
+// processChecks updates the composite check from the current state of the
+// checks it combines. local are the checks of this node, from the local
+// state, and remote the checks of the other nodes, from the servers.
+func (c *CheckComposite) processChecks(local, remote []*structs.HealthCheck) {
+	type key struct {
+		node string
+		id   structs.CheckID
+	}
+	byKey := make(map[key]*structs.HealthCheck, len(local)+len(remote))
+	for _, chk := range local {
+		byKey[key{id: chk.CompoundCheckID()}] = chk
+	}
+	for _, chk := range remote {
+		byKey[key{node: strings.ToLower(chk.Node), id: chk.CompoundCheckID()}] = chk
+	}
+
+	members := c.members()
+	var failing []string
+	for _, m := range members {
+		name := fmt.Sprintf("%q", m.CheckID.ID)
+		if m.Node != "" {
+			name += fmt.Sprintf(" on node %q", m.Node)
+		}
+
+		chk, ok := byKey[key{node: strings.ToLower(m.Node), id: m.CheckID}]
+		switch {
+		case !ok:
+			failing = append(failing, name+" is missing")
+		case chk.Status != api.HealthPassing:
+			// The output of the check is not included since composite
+			// checks combining each other would never settle.
+			failing = append(failing, fmt.Sprintf("%s is %s", name, chk.Status))
+		}
+	}
+
+	status := api.HealthPassing
+	switch {
+	case len(failing) >= c.required():
+		status = c.Status
+		if status == "" {
+			status = api.HealthCritical
+		}
+	case len(failing) > 0:
+		status = c.PartialStatus
+		if status == "" {
+			status = api.HealthPassing
+		}
+	}
+
+	msg := fmt.Sprintf("%d of %d combined checks failing, %d required.", len(failing), len(members), c.required())
+	if len(failing) > 0 {
+		msg += " " + strings.Join(failing, "; ")
+	}
+	c.Notify.UpdateCheck(c.CheckID, status, msg)
+}
+
+// required returns the number of failing checks for the check to have the
+// Status status.
+func (c *CheckComposite) required() int {
+	switch {
+	case c.Threshold > 0:
+		return c.Threshold
+	case c.Operator == structs.CompositeOperatorAnd:
+		return len(c.Checks) + len(c.Members)
+	default:
+		return 1
+	}
+}
@@ -0,0 +1,282 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package checks
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hernad/consul/acl"
+	"github.com/hernad/consul/agent/mock"
+	"github.com/hernad/consul/agent/structs"
+	"github.com/hernad/consul/api"
+	"github.com/hernad/consul/sdk/testutil/retry"
+	"github.com/hernad/consul/types"
+)
+
+func TestCheckComposite_processChecks(t *testing.T) {
+	t.Parallel()
+
+	db := structs.NewCheckID("db", nil)
+	cache := structs.NewCheckID("cache", nil)
+	queue := structs.NewCheckID("queue", nil)
+
+	checks := func(db, cache string) []*structs.HealthCheck {
+		return []*structs.HealthCheck{
+			{CheckID: "db", Status: db},
+			{CheckID: "cache", Status: cache},
+			{CheckID: "other", Status: api.HealthCritical},
+		}
+	}
+
+	tests := []struct {
+		desc   string
+		check  *CheckComposite
+		checks []*structs.HealthCheck
+		status string
+		output string
+	}{
+		{
+			desc:   "or all passing",
+			check:  &CheckComposite{Checks: []structs.CheckID{db, cache}},
+			checks: checks(api.HealthPassing, api.HealthPassing),
+			status: api.HealthPassing,
+			output: "0 of 2 combined checks failing, 1 required.",
+		},
+		{
+			desc:   "or one failing",
+			check:  &CheckComposite{Checks: []structs.CheckID{db, cache}},
+			checks: checks(api.HealthPassing, api.HealthWarning),
+			status: api.HealthCritical,
+			output: `1 of 2 combined checks failing, 1 required. "cache" is warning`,
+		},
+		{
+			desc:   "and one failing",
+			check:  &CheckComposite{Checks: []structs.CheckID{db, cache}, Operator: structs.CompositeOperatorAnd},
+			checks: checks(api.HealthCritical, api.HealthPassing),
+			status: api.HealthPassing,
+		},
+		{
+			desc:   "and one failing with partial status",
+			check:  &CheckComposite{Checks: []structs.CheckID{db, cache}, Operator: structs.CompositeOperatorAnd, PartialStatus: api.HealthWarning},
+			checks: checks(api.HealthCritical, api.HealthPassing),
+			status: api.HealthWarning,
+		},
+		{
+			desc:   "and all failing",
+			check:  &CheckComposite{Checks: []structs.CheckID{db, cache}, Operator: structs.CompositeOperatorAnd},
+			checks: checks(api.HealthCritical, api.HealthCritical),
+			status: api.HealthCritical,
+		},
+		{
+			desc:   "threshold with missing check",
+			check:  &CheckComposite{Checks: []structs.CheckID{db, cache, queue}, Threshold: 2, Status: api.HealthWarning},
+			checks: checks(api.HealthPassing, api.HealthCritical),
+			status: api.HealthWarning,
+			output: `2 of 3 combined checks failing, 2 required. "cache" is critical; "queue" is missing`,
+		},
+		{
+			desc:   "threshold not reached",
+			check:  &CheckComposite{Checks: []structs.CheckID{db, cache, queue}, Threshold: 3},
+			checks: checks(api.HealthPassing, api.HealthCritical),
+			status: api.HealthPassing,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			notify := newMockCompositeNotify()
+			chkID := structs.NewCheckID(types.CheckID("foo"), nil)
+			chk := tt.check
+			chk.CheckID = chkID
+			chk.Notify = notify
+			chk.processChecks(tt.checks, nil)
+
+			if got, want := notify.State(chkID), tt.status; got != want {
+				t.Fatalf("got state %q want %q", got, want)
+			}
+			if got := notify.Output(chkID); !strings.HasPrefix(got, tt.output) {
+				t.Fatalf("got output %q want %q", got, tt.output)
+			}
+		})
+	}
+}
+
+func TestCheckComposite_local(t *testing.T) {
+	t.Parallel()
+
+	notify := newMockCompositeNotify()
+	notify.checks = map[structs.CheckID]*structs.HealthCheck{
+		structs.NewCheckID("db", nil):    {CheckID: "db", Status: api.HealthPassing},
+		structs.NewCheckID("cache", nil): {CheckID: "cache", Status: api.HealthPassing},
+	}
+	chkID := structs.NewCheckID(types.CheckID("foo"), nil)
+	chk := &CheckComposite{
+		CheckID:  chkID,
+		Notify:   notify,
+		Checks:   []structs.CheckID{structs.NewCheckID("db", nil), structs.NewCheckID("cache", nil)},
+		Operator: structs.CompositeOperatorAnd,
+	}
+	chk.Start()
+	defer chk.Stop()
+
+	retry.Run(t, func(r *retry.R) {
+		if got, want := notify.State(chkID), api.HealthPassing; got != want {
+			r.Fatalf("got state %q want %q", got, want)
+		}
+	})
+
+	notify.setStatus(structs.NewCheckID("db", nil), api.HealthCritical)
+	notify.setStatus(structs.NewCheckID("cache", nil), api.HealthCritical)
+
+	retry.Run(t, func(r *retry.R) {
+		if got, want := notify.State(chkID), api.HealthCritical; got != want {
+			r.Fatalf("got state %q want %q", got, want)
+		}
+	})
+}
+
+func TestCheckComposite_remote(t *testing.T) {
+	t.Parallel()
+
+	notify := newMockCompositeNotify()
+	chkID := structs.NewCheckID(types.CheckID("foo"), nil)
+	rpc := &mockRPC{}
+	chk := &CheckComposite{
+		Node:    "remote",
+		CheckID: chkID,
+		Notify:  notify,
+		RPC:     rpc,
+		Checks:  []structs.CheckID{structs.NewCheckID("db", nil)},
+	}
+
+	rpc.AddReply("Health.NodeChecks", structs.IndexedHealthChecks{
+		HealthChecks: []*structs.HealthCheck{
+			// Checks of other nodes are ignored
+			{Node: "other", CheckID: "db", Status: api.HealthPassing},
+			{Node: "remote", CheckID: "db", Status: api.HealthWarning},
+		},
+	})
+
+	chk.Start()
+	defer chk.Stop()
+
+	retry.Run(t, func(r *retry.R) {
+		if got, want := notify.State(chkID), api.HealthCritical; got != want {
+			r.Fatalf("got state %q want %q", got, want)
+		}
+	})
+
+	args := rpc.Args.Load().(*structs.NodeSpecificRequest)
+	if args.Node != "remote" {
+		t.Fatalf("bad node %q", args.Node)
+	}
+}
+
+func TestCheckComposite_multipleNodes(t *testing.T) {
+	t.Parallel()
+
+	notify := newMockCompositeNotify()
+	notify.checks = map[structs.CheckID]*structs.HealthCheck{
+		structs.NewCheckID("web", nil): {CheckID: "web", Status: api.HealthCritical},
+	}
+	chkID := structs.NewCheckID(types.CheckID("foo"), nil)
+	rpc := &mockRPC{}
+	chk := &CheckComposite{
+		CheckID: chkID,
+		Notify:  notify,
+		RPC:     rpc,
+		// Any of the replicas of web is enough.
+		Checks: []structs.CheckID{structs.NewCheckID("web", nil)},
+		Members: []CompositeMember{
+			{Node: "node-a", CheckID: structs.NewCheckID("web", nil)},
+			{Node: "node-b", CheckID: structs.NewCheckID("web", nil)},
+		},
+		Operator: structs.CompositeOperatorAnd,
+	}
+
+	rpc.AddReply("Health.NodeChecks", structs.IndexedHealthChecks{
+		HealthChecks: []*structs.HealthCheck{
+			{Node: "node-a", CheckID: "web", Status: api.HealthCritical},
+			{Node: "node-b", CheckID: "web", Status: api.HealthPassing},
+		},
+	})
+
+	chk.Start()
+	defer chk.Stop()
+
+	retry.Run(t, func(r *retry.R) {
+		if got, want := notify.State(chkID), api.HealthPassing; got != want {
+			r.Fatalf("got state %q want %q", got, want)
+		}
+		want := `2 of 3 combined checks failing, 3 required. "web" is critical; "web" on node "node-a" is critical`
+		if got := notify.Output(chkID); got != want {
+			r.Fatalf("got output %q want %q", got, want)
+		}
+	})
+
+	rpc.Replies["Health.NodeChecks"].Store(structs.IndexedHealthChecks{
+		HealthChecks: []*structs.HealthCheck{
+			{Node: "node-a", CheckID: "web", Status: api.HealthCritical},
+			{Node: "node-b", CheckID: "web", Status: api.HealthCritical},
+		},
+	})
+
+	retry.Run(t, func(r *retry.R) {
+		if got, want := notify.State(chkID), api.HealthCritical; got != want {
+			r.Fatalf("got state %q want %q", got, want)
+		}
+	})
+}
+
+type mockCompositeNotify struct {
+	*mock.Notify
+
+	lock     sync.Mutex
+	checks   map[structs.CheckID]*structs.HealthCheck
+	notifyCh chan<- struct{}
+}
+
+func newMockCompositeNotify() *mockCompositeNotify {
+	return &mockCompositeNotify{
+		Notify: mock.NewNotify(),
+	}
+}
+
+func (m *mockCompositeNotify) AddCompositeCheck(chkID structs.CheckID, checkIDs []structs.CheckID, ch chan<- struct{}) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.notifyCh = ch
+	return nil
+}
+
+func (m *mockCompositeNotify) RemoveCompositeCheck(chkID structs.CheckID) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.notifyCh = nil
+}
+
+func (m *mockCompositeNotify) Checks(*acl.EnterpriseMeta) map[structs.CheckID]*structs.HealthCheck {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	checks := make(map[structs.CheckID]*structs.HealthCheck, len(m.checks))
+	for id, chk := range m.checks {
+		checks[id] = chk.Clone()
+	}
+	return checks
+}
+
+func (m *mockCompositeNotify) setStatus(id structs.CheckID, status string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.checks[id].Status = status
+	if m.notifyCh != nil {
+		select {
+		case m.notifyCh <- struct{}{}:
+		default:
+		}
+	}
+}
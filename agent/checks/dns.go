@@ -0,0 +1,214 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package checks
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/miekg/dns"
+
+	"github.com/hernad/consul/agent/structs"
+	"github.com/hernad/consul/api"
+	"github.com/hernad/consul/lib"
+)
+
+// DefaultDNSRecordType is the record type queried by DNS checks when none is
+// configured.
+const DefaultDNSRecordType = "A"
+
+// CheckDNS is used to periodically resolve a name against a DNS server to
+// determine the health of a given check.
+// The check is passing if the server answers with at least one record of the
+// requested type and, when DNSExpect is set, if every expected value is found
+// in the answer. The value of a record is its data, e.g. the address of an A
+// record or the target of a CNAME, as presented in a zone file.
+// The check is critical if the query fails, the server returns an error or
+// an expected value is missing.
+// Supports failures_before_critical and success_before_passing.
+type CheckDNS struct {
+	CheckID       structs.CheckID
+	ServiceID     structs.ServiceID
+	DNS           string
+	DNSServer     string
+	DNSRecordType string
+	DNSExpect     []string
+	Interval      time.Duration
+	Timeout       time.Duration
+	Logger        hclog.Logger
+	StatusHandler *StatusHandler
+
+	client   *dns.Client
+	stop     bool
+	stopCh   chan struct{}
+	stopLock sync.Mutex
+	stopWg   sync.WaitGroup
+}
+
+func (c *CheckDNS) CheckType() structs.CheckType {
+	return structs.CheckType{
+		CheckID:       c.CheckID.ID,
+		DNS:           c.DNS,
+		DNSServer:     c.DNSServer,
+		DNSRecordType: c.DNSRecordType,
+		DNSExpect:     c.DNSExpect,
+		Interval:      c.Interval,
+		Timeout:       c.Timeout,
+	}
+}
+
+// Start is used to start a DNS check.
+// The check runs until stop is called
+func (c *CheckDNS) Start() {
+	c.stopLock.Lock()
+	defer c.stopLock.Unlock()
+
+	if c.client == nil {
+		c.client = &dns.Client{
+			Timeout: 10 * time.Second,
+		}
+		if c.Timeout > 0 {
+			c.client.Timeout = c.Timeout
+		}
+	}
+
+	c.stop = false
+	c.stopCh = make(chan struct{})
+	c.stopWg.Add(1)
+	go c.run()
+}
+
+// Stop is used to stop a DNS check.
+func (c *CheckDNS) Stop() {
+	c.stopLock.Lock()
+	defer c.stopLock.Unlock()
+	if !c.stop {
+		c.stop = true
+		close(c.stopCh)
+	}
+
+	// Wait for the c.run() goroutine to complete before returning.
+	c.stopWg.Wait()
+}
+
+// run is invoked by a goroutine to run until Stop() is called
+func (c *CheckDNS) run() {
+	defer c.stopWg.Done()
+	// Get the randomized initial pause time
+	initialPauseTime := lib.RandomStagger(c.Interval)
+	next := time.After(initialPauseTime)
+	for {
+		select {
+		case <-next:
+			c.check()
+			next = time.After(c.Interval)
+		case <-c.stopCh:
+			return
+		}
+	}
+}
+
+// check is invoked periodically to perform the DNS check
+func (c *CheckDNS) check() {
+	recordType := c.DNSRecordType
+	if recordType == "" {
+		recordType = DefaultDNSRecordType
+	}
+	qtype, ok := dns.StringToType[strings.ToUpper(recordType)]
+	if !ok {
+		c.StatusHandler.updateCheck(c.CheckID, api.HealthCritical, fmt.Sprintf("Invalid DNS record type %q", recordType))
+		return
+	}
+
+	server, err := c.server()
+	if err != nil {
+		c.StatusHandler.updateCheck(c.CheckID, api.HealthCritical, err.Error())
+		return
+	}
+
+	m := new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(c.DNS), qtype)
+	resp, _, err := c.client.Exchange(m, server)
+	if err == nil && resp.Truncated {
+		tcp := &dns.Client{Net: "tcp", Timeout: c.client.Timeout}
+		resp, _, err = tcp.Exchange(m, server)
+	}
+	if err != nil {
+		c.Logger.Warn("Check DNS query failed",
+			"check", c.CheckID.String(),
+			"error", err,
+		)
+		c.StatusHandler.updateCheck(c.CheckID, api.HealthCritical, err.Error())
+		return
+	}
+
+	prefix := fmt.Sprintf("DNS %s %s @%s:", recordType, c.DNS, server)
+	if resp.Rcode != dns.RcodeSuccess {
+		c.StatusHandler.updateCheck(c.CheckID, api.HealthCritical, fmt.Sprintf("%s %s", prefix, dns.RcodeToString[resp.Rcode]))
+		return
+	}
+
+	var values []string
+	for _, rr := range resp.Answer {
+		if rr.Header().Rrtype != qtype {
+			continue
+		}
+		values = append(values, dnsRecordValue(rr))
+	}
+	if len(values) == 0 {
+		c.StatusHandler.updateCheck(c.CheckID, api.HealthCritical, fmt.Sprintf("%s no %s record found", prefix, recordType))
+		return
+	}
+
+	result := fmt.Sprintf("%s %s", prefix, strings.Join(values, ", "))
+	for _, want := range c.DNSExpect {
+		if !dnsValuesContain(values, want) {
+			c.StatusHandler.updateCheck(c.CheckID, api.HealthCritical, fmt.Sprintf("%s expected %q not found in answer; %s", prefix, want, result))
+			return
+		}
+	}
+	c.StatusHandler.updateCheck(c.CheckID, api.HealthPassing, result)
+}
+
+// server returns the address of the server queried by the check. It defaults
+// to the first nameserver of the system resolver and to port 53.
+func (c *CheckDNS) server() (string, error) {
+	server := c.DNSServer
+	if server == "" {
+		conf, err := dns.ClientConfigFromFile("/etc/resolv.conf")
+		if err != nil || len(conf.Servers) == 0 {
+			return "", fmt.Errorf("no DNS server configured and none found in /etc/resolv.conf")
+		}
+		return net.JoinHostPort(conf.Servers[0], conf.Port), nil
+	}
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(strings.Trim(server, "[]"), "53")
+	}
+	return server, nil
+}
+
+// dnsRecordValue returns the data of a record, without its header. The
+// strings of TXT records are concatenated rather than quoted.
+func dnsRecordValue(rr dns.RR) string {
+	if txt, ok := rr.(*dns.TXT); ok {
+		return strings.Join(txt.Txt, "")
+	}
+	return strings.TrimPrefix(rr.String(), rr.Header().String())
+}
+
+// dnsValuesContain checks whether want is one of values. Names are compared
+// case insensitively and regardless of the trailing dot.
+func dnsValuesContain(values []string, want string) bool {
+	want = strings.TrimSuffix(want, ".")
+	for _, v := range values {
+		if strings.EqualFold(strings.TrimSuffix(v, "."), want) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package checks
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hernad/consul/agent/mock"
+	"github.com/hernad/consul/agent/structs"
+	"github.com/hernad/consul/api"
+	"github.com/hernad/consul/sdk/testutil"
+	"github.com/hernad/consul/sdk/testutil/retry"
+)
+
+func startDNSServer(t *testing.T) string {
+	t.Helper()
+
+	mux := dns.NewServeMux()
+	mux.HandleFunc("example.com.", func(w dns.ResponseWriter, req *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetReply(req)
+		q := req.Question[0]
+		hdr := dns.RR_Header{Name: q.Name, Rrtype: q.Qtype, Class: dns.ClassINET, Ttl: 30}
+		switch {
+		case q.Name == "missing.example.com.":
+			m.SetRcode(req, dns.RcodeNameError)
+		case q.Qtype == dns.TypeA:
+			m.Answer = append(m.Answer,
+				&dns.A{Hdr: hdr, A: net.ParseIP("10.0.0.1")},
+				&dns.A{Hdr: hdr, A: net.ParseIP("10.0.0.2")},
+			)
+		case q.Qtype == dns.TypeTXT:
+			m.Answer = append(m.Answer, &dns.TXT{Hdr: hdr, Txt: []string{"v=1 ", "ok"}})
+		case q.Qtype == dns.TypeCNAME:
+			m.Answer = append(m.Answer, &dns.CNAME{Hdr: hdr, Target: "Target.Example.com."})
+		}
+		w.WriteMsg(m)
+	})
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := &dns.Server{PacketConn: pc, Handler: mux}
+	go server.ActivateAndServe()
+	t.Cleanup(func() { server.Shutdown() })
+
+	return pc.LocalAddr().String()
+}
+
+func TestCheckDNS(t *testing.T) {
+	t.Parallel()
+
+	addr := startDNSServer(t)
+
+	tests := []struct {
+		desc       string
+		name       string
+		recordType string
+		expect     []string
+		status     string
+		output     string
+	}{
+		{
+			desc:   "default record type",
+			name:   "www.example.com",
+			status: api.HealthPassing,
+			output: "10.0.0.1, 10.0.0.2",
+		},
+		{
+			desc:   "expected addresses",
+			name:   "www.example.com",
+			expect: []string{"10.0.0.2", "10.0.0.1"},
+			status: api.HealthPassing,
+		},
+		{
+			desc:   "missing address",
+			name:   "www.example.com",
+			expect: []string{"10.0.0.1", "10.0.0.3"},
+			status: api.HealthCritical,
+			output: `expected "10.0.0.3" not found`,
+		},
+		{
+			desc:       "txt record",
+			name:       "www.example.com",
+			recordType: "txt",
+			expect:     []string{"v=1 ok"},
+			status:     api.HealthPassing,
+		},
+		{
+			desc:       "names are compared case insensitively",
+			name:       "www.example.com",
+			recordType: "CNAME",
+			expect:     []string{"target.example.com"},
+			status:     api.HealthPassing,
+		},
+		{
+			desc:       "no record of the type",
+			name:       "www.example.com",
+			recordType: "AAAA",
+			status:     api.HealthCritical,
+			output:     "no AAAA record found",
+		},
+		{
+			desc:   "name error",
+			name:   "missing.example.com",
+			status: api.HealthCritical,
+			output: "NXDOMAIN",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			notif := mock.NewNotify()
+			logger := testutil.Logger(t)
+			statusHandler := NewStatusHandler(notif, logger, 0, 0, 0)
+			cid := structs.NewCheckID("foo", nil)
+
+			check := &CheckDNS{
+				CheckID:       cid,
+				DNS:           tt.name,
+				DNSServer:     addr,
+				DNSRecordType: tt.recordType,
+				DNSExpect:     tt.expect,
+				Interval:      10 * time.Millisecond,
+				Logger:        logger,
+				StatusHandler: statusHandler,
+			}
+			check.Start()
+			defer check.Stop()
+
+			retry.Run(t, func(r *retry.R) {
+				if got, want := notif.State(cid), tt.status; got != want {
+					r.Fatalf("got state %q want %q", got, want)
+				}
+				if output := notif.Output(cid); !strings.Contains(output, tt.output) {
+					r.Fatalf("output %q does not contain %q", output, tt.output)
+				}
+			})
+		})
+	}
+}
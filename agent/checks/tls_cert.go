@@ -0,0 +1,157 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package checks
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+
+	"github.com/hernad/consul/agent/structs"
+	"github.com/hernad/consul/api"
+	"github.com/hernad/consul/lib"
+)
+
+// CheckTLSCert is used to periodically perform a TLS handshake with an
+// endpoint to monitor the expiry of its certificate.
+// The check is passing if the handshake succeeds and the leaf certificate
+// expires in more than WarningDays days.
+// The check is warning if the certificate expires in less than WarningDays
+// days.
+// The check is critical if the handshake fails, including when the
+// certificate can't be verified, or if it expires in less than CriticalDays
+// days.
+// Supports failures_before_critical and success_before_passing.
+type CheckTLSCert struct {
+	CheckID         structs.CheckID
+	ServiceID       structs.ServiceID
+	TLSCert         string
+	WarningDays     int
+	CriticalDays    int
+	Interval        time.Duration
+	Timeout         time.Duration
+	Logger          hclog.Logger
+	TLSClientConfig *tls.Config
+	StatusHandler   *StatusHandler
+
+	dialer   *tls.Dialer
+	stop     bool
+	stopCh   chan struct{}
+	stopLock sync.Mutex
+	stopWg   sync.WaitGroup
+
+	// now is used to compute the remaining validity of the certificate,
+	// it is only overridden in tests.
+	now func() time.Time
+}
+
+func (c *CheckTLSCert) CheckType() structs.CheckType {
+	return structs.CheckType{
+		CheckID:             c.CheckID.ID,
+		TLSCert:             c.TLSCert,
+		TLSCertWarningDays:  c.WarningDays,
+		TLSCertCriticalDays: c.CriticalDays,
+		Interval:            c.Interval,
+		Timeout:             c.Timeout,
+	}
+}
+
+// Start is used to start a TLS certificate check.
+// The check runs until stop is called
+func (c *CheckTLSCert) Start() {
+	c.stopLock.Lock()
+	defer c.stopLock.Unlock()
+
+	if c.dialer == nil {
+		c.dialer = &tls.Dialer{
+			NetDialer: &net.Dialer{Timeout: 10 * time.Second},
+			Config:    c.TLSClientConfig,
+		}
+		if c.Timeout > 0 {
+			c.dialer.NetDialer.Timeout = c.Timeout
+		}
+	}
+	c.WarningDays, c.CriticalDays = structs.TLSCertCheckDays(c.WarningDays, c.CriticalDays)
+	if c.now == nil {
+		c.now = time.Now
+	}
+
+	c.stop = false
+	c.stopCh = make(chan struct{})
+	c.stopWg.Add(1)
+	go c.run()
+}
+
+// Stop is used to stop a TLS certificate check.
+func (c *CheckTLSCert) Stop() {
+	c.stopLock.Lock()
+	defer c.stopLock.Unlock()
+	if !c.stop {
+		c.stop = true
+		close(c.stopCh)
+	}
+
+	// Wait for the c.run() goroutine to complete before returning.
+	c.stopWg.Wait()
+}
+
+// run is invoked by a goroutine to run until Stop() is called
+func (c *CheckTLSCert) run() {
+	defer c.stopWg.Done()
+	// Get the randomized initial pause time
+	initialPauseTime := lib.RandomStagger(c.Interval)
+	next := time.After(initialPauseTime)
+	for {
+		select {
+		case <-next:
+			c.check()
+			next = time.After(c.Interval)
+		case <-c.stopCh:
+			return
+		}
+	}
+}
+
+// check is invoked periodically to perform the TLS certificate check
+func (c *CheckTLSCert) check() {
+	conn, err := c.dialer.Dial("tcp", c.TLSCert)
+	if err != nil {
+		c.Logger.Warn("Check TLS handshake failed",
+			"check", c.CheckID.String(),
+			"error", err,
+		)
+		c.StatusHandler.updateCheck(c.CheckID, api.HealthCritical, fmt.Sprintf("TLS handshake with %s failed: %s", c.TLSCert, err))
+		return
+	}
+	defer conn.Close()
+
+	certs := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		c.StatusHandler.updateCheck(c.CheckID, api.HealthCritical, fmt.Sprintf("TLS endpoint %s did not present a certificate", c.TLSCert))
+		return
+	}
+	leaf := certs[0]
+
+	remaining := leaf.NotAfter.Sub(c.now())
+	days := int(remaining.Hours() / 24)
+	var result string
+	if remaining <= 0 {
+		result = fmt.Sprintf("TLS certificate %q of %s expired on %s", leaf.Subject.String(), c.TLSCert, leaf.NotAfter.UTC().Format(time.RFC3339))
+	} else {
+		result = fmt.Sprintf("TLS certificate %q of %s expires on %s (%d days)", leaf.Subject.String(), c.TLSCert, leaf.NotAfter.UTC().Format(time.RFC3339), days)
+	}
+
+	switch {
+	case remaining < time.Duration(c.CriticalDays)*24*time.Hour:
+		c.StatusHandler.updateCheck(c.CheckID, api.HealthCritical, result)
+	case remaining < time.Duration(c.WarningDays)*24*time.Hour:
+		c.StatusHandler.updateCheck(c.CheckID, api.HealthWarning, result)
+	default:
+		c.StatusHandler.updateCheck(c.CheckID, api.HealthPassing, result)
+	}
+}
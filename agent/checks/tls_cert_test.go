@@ -0,0 +1,101 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package checks
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hernad/consul/agent/mock"
+	"github.com/hernad/consul/agent/structs"
+	"github.com/hernad/consul/api"
+	"github.com/hernad/consul/sdk/testutil"
+	"github.com/hernad/consul/sdk/testutil/retry"
+)
+
+func TestCheckTLSCert(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	notAfter := server.Certificate().NotAfter
+
+	tests := []struct {
+		desc       string
+		skipVerify bool
+		now        time.Time
+		status     string
+		output     string
+	}{
+		{
+			desc:       "valid certificate",
+			skipVerify: true,
+			now:        notAfter.Add(-60 * 24 * time.Hour),
+			status:     api.HealthPassing,
+			output:     "(60 days)",
+		},
+		{
+			desc:       "certificate expires soon",
+			skipVerify: true,
+			now:        notAfter.Add(-20 * 24 * time.Hour),
+			status:     api.HealthWarning,
+			output:     "(20 days)",
+		},
+		{
+			desc:       "certificate expires very soon",
+			skipVerify: true,
+			now:        notAfter.Add(-2 * 24 * time.Hour),
+			status:     api.HealthCritical,
+			output:     "(2 days)",
+		},
+		{
+			desc:       "certificate expired",
+			skipVerify: true,
+			now:        notAfter.Add(time.Hour),
+			status:     api.HealthCritical,
+			output:     "expired on",
+		},
+		{
+			desc:   "untrusted certificate",
+			now:    time.Now(),
+			status: api.HealthCritical,
+			output: "TLS handshake with",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			notif := mock.NewNotify()
+			logger := testutil.Logger(t)
+			statusHandler := NewStatusHandler(notif, logger, 0, 0, 0)
+			cid := structs.NewCheckID("foo", nil)
+
+			check := &CheckTLSCert{
+				CheckID:         cid,
+				TLSCert:         server.Listener.Addr().String(),
+				Interval:        10 * time.Millisecond,
+				Logger:          logger,
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: tt.skipVerify},
+				StatusHandler:   statusHandler,
+				now:             func() time.Time { return tt.now },
+			}
+			check.Start()
+			defer check.Stop()
+
+			retry.Run(t, func(r *retry.R) {
+				if got, want := notif.State(cid), tt.status; got != want {
+					r.Fatalf("got state %q want %q", got, want)
+				}
+				if output := notif.Output(cid); !strings.Contains(output, tt.output) {
+					r.Fatalf("output %q does not contain %q", output, tt.output)
+				}
+			})
+		})
+	}
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/hashicorp/memberlist"
 	"golang.org/x/time/rate"
 
+	"github.com/hernad/consul/agent/audit"
 	"github.com/hernad/consul/agent/cache"
 	"github.com/hernad/consul/agent/checks"
 	"github.com/hernad/consul/agent/connect/ca"
@@ -39,6 +40,7 @@ import (
 	"github.com/hernad/consul/agent/rpc/middleware"
 	"github.com/hernad/consul/agent/structs"
 	"github.com/hernad/consul/agent/token"
+	"github.com/hernad/consul/api"
 	"github.com/hernad/consul/ipaddr"
 	"github.com/hernad/consul/lib"
 	"github.com/hernad/consul/lib/stringslice"
@@ -719,6 +721,8 @@ func (b *builder) build() (rt RuntimeConfig, err error) {
 			"private_key":           "PrivateKey",
 			"root_cert":             "RootCert",
 			"intermediate_cert_ttl": "IntermediateCertTTL",
+			"token_label":           "TokenLabel",
+			"key_label":             "KeyLabel",
 
 			// Vault CA config
 			"address":                    "Address",
@@ -745,6 +749,12 @@ func (b *builder) build() (rt RuntimeConfig, err error) {
 			"private_key_type":   "PrivateKeyType",
 			"private_key_bits":   "PrivateKeyBits",
 			"root_cert_ttl":      "RootCertTTL",
+
+			// Federated trust domains
+			"federated_trust_domains": "FederatedTrustDomains",
+			"spiffe_id_templates":     "SpiffeIDTemplates",
+			"bundle_endpoint_url":     "BundleEndpointURL",
+			"bundle_endpoint_ca_cert": "BundleEndpointCACert",
 		})
 	}
 
@@ -895,26 +905,29 @@ func (b *builder) build() (rt RuntimeConfig, err error) {
 		AutopilotUpgradeVersionTag:       stringVal(c.Autopilot.UpgradeVersionTag),
 
 		// DNS
-		DNSAddrs:              dnsAddrs,
-		DNSAllowStale:         boolVal(c.DNS.AllowStale),
-		DNSARecordLimit:       intVal(c.DNS.ARecordLimit),
-		DNSDisableCompression: boolVal(c.DNS.DisableCompression),
-		DNSDomain:             stringVal(c.DNSDomain),
-		DNSAltDomain:          altDomain,
-		DNSEnableTruncate:     boolVal(c.DNS.EnableTruncate),
-		DNSMaxStale:           b.durationVal("dns_config.max_stale", c.DNS.MaxStale),
-		DNSNodeTTL:            b.durationVal("dns_config.node_ttl", c.DNS.NodeTTL),
-		DNSOnlyPassing:        boolVal(c.DNS.OnlyPassing),
-		DNSPort:               dnsPort,
-		DNSRecursorStrategy:   b.dnsRecursorStrategyVal(stringVal(c.DNS.RecursorStrategy)),
-		DNSRecursorTimeout:    b.durationVal("recursor_timeout", c.DNS.RecursorTimeout),
-		DNSRecursors:          dnsRecursors,
-		DNSServiceTTL:         dnsServiceTTL,
-		DNSSOA:                soa,
-		DNSUDPAnswerLimit:     intVal(c.DNS.UDPAnswerLimit),
-		DNSNodeMetaTXT:        boolValWithDefault(c.DNS.NodeMetaTXT, true),
-		DNSUseCache:           boolVal(c.DNS.UseCache),
-		DNSCacheMaxAge:        b.durationVal("dns_config.cache_max_age", c.DNS.CacheMaxAge),
+		DNSAddrs:                 dnsAddrs,
+		DNSAllowStale:            boolVal(c.DNS.AllowStale),
+		DNSARecordLimit:          intVal(c.DNS.ARecordLimit),
+		DNSDisableCompression:    boolVal(c.DNS.DisableCompression),
+		DNSDomain:                stringVal(c.DNSDomain),
+		DNSAltDomain:             altDomain,
+		DNSEnableTruncate:        boolVal(c.DNS.EnableTruncate),
+		DNSMaxStale:              b.durationVal("dns_config.max_stale", c.DNS.MaxStale),
+		DNSNodeTTL:               b.durationVal("dns_config.node_ttl", c.DNS.NodeTTL),
+		DNSOnlyPassing:           boolVal(c.DNS.OnlyPassing),
+		DNSPort:                  dnsPort,
+		DNSRecursorStrategy:      b.dnsRecursorStrategyVal(stringVal(c.DNS.RecursorStrategy)),
+		DNSRecursorTimeout:       b.durationVal("recursor_timeout", c.DNS.RecursorTimeout),
+		DNSRecursors:             dnsRecursors,
+		DNSServiceTTL:            dnsServiceTTL,
+		DNSSOA:                   soa,
+		DNSUDPAnswerLimit:        intVal(c.DNS.UDPAnswerLimit),
+		DNSNodeMetaTXT:           boolValWithDefault(c.DNS.NodeMetaTXT, true),
+		DNSUseCache:              boolVal(c.DNS.UseCache),
+		DNSCacheMaxAge:           b.durationVal("dns_config.cache_max_age", c.DNS.CacheMaxAge),
+		DNSAllowZoneTransferFrom: b.cidrsVal("dns_config.allow_zone_transfer_from", c.DNS.AllowZoneTransferFrom),
+		DNSOrderByRTT:            boolVal(c.DNS.OrderByRTT),
+		DNSWeightedAnswers:       boolVal(c.DNS.WeightedAnswers),
 
 		// HTTP
 		HTTPPort:            httpPort,
@@ -976,6 +989,11 @@ func (b *builder) build() (rt RuntimeConfig, err error) {
 		AutoReloadConfig:                       boolVal(c.AutoReloadConfig),
 		CheckUpdateInterval:                    b.durationVal("check_update_interval", c.CheckUpdateInterval),
 		CheckOutputMaxSize:                     intValWithDefault(c.CheckOutputMaxSize, 4096),
+		CheckHistorySize:                       intValWithDefault(c.CheckHistorySize, 10),
+		CheckFlapWindow:                        b.durationVal("check_flap_detection.window", c.CheckFlapDetection.Window),
+		CheckFlapStartThreshold:                intValWithDefault(c.CheckFlapDetection.StartThreshold, 5),
+		CheckFlapStopThreshold:                 intValWithDefault(c.CheckFlapDetection.StopThreshold, 2),
+		CheckFlapStatus:                        stringVal(c.CheckFlapDetection.Status),
 		Checks:                                 checks,
 		ClientAddrs:                            clientAddrs,
 		ConfigEntryBootstrap:                   configEntries,
@@ -985,6 +1003,7 @@ func (b *builder) build() (rt RuntimeConfig, err error) {
 		AutoEncryptAllowTLS:                    autoEncryptAllowTLS,
 		AutoConfig:                             autoConfig,
 		Cloud:                                  b.cloudConfigVal(c),
+		Audit:                                  b.auditVal(c.Audit),
 		ConnectEnabled:                         connectEnabled,
 		ConnectCAProvider:                      connectCAProvider,
 		ConnectCAConfig:                        connectCAConfig,
@@ -1007,22 +1026,27 @@ func (b *builder) build() (rt RuntimeConfig, err error) {
 		DisableUpdateCheck:                     boolVal(c.DisableUpdateCheck),
 		DiscardCheckOutput:                     boolVal(c.DiscardCheckOutput),
 
-		DiscoveryMaxStale:          b.durationVal("discovery_max_stale", c.DiscoveryMaxStale),
-		EnableAgentTLSForChecks:    boolVal(c.EnableAgentTLSForChecks),
-		EnableCentralServiceConfig: boolVal(c.EnableCentralServiceConfig),
-		EnableDebug:                boolVal(c.EnableDebug),
-		EnableRemoteScriptChecks:   enableRemoteScriptChecks,
-		EnableLocalScriptChecks:    enableLocalScriptChecks,
-		EncryptKey:                 stringVal(c.EncryptKey),
-		GRPCAddrs:                  grpcAddrs,
-		GRPCPort:                   grpcPort,
-		GRPCTLSAddrs:               grpcTlsAddrs,
-		GRPCTLSPort:                grpcTlsPort,
-		HTTPMaxConnsPerClient:      intVal(c.Limits.HTTPMaxConnsPerClient),
-		HTTPSHandshakeTimeout:      b.durationVal("limits.https_handshake_timeout", c.Limits.HTTPSHandshakeTimeout),
-		KVMaxValueSize:             uint64Val(c.Limits.KVMaxValueSize),
-		LeaveDrainTime:             b.durationVal("performance.leave_drain_time", c.Performance.LeaveDrainTime),
-		LeaveOnTerm:                leaveOnTerm,
+		DiscoveryMaxStale:              b.durationVal("discovery_max_stale", c.DiscoveryMaxStale),
+		EnableAgentTLSForChecks:        boolVal(c.EnableAgentTLSForChecks),
+		EnableCentralServiceConfig:     boolVal(c.EnableCentralServiceConfig),
+		EnableDebug:                    boolVal(c.EnableDebug),
+		EnableRemoteScriptChecks:       enableRemoteScriptChecks,
+		EnableLocalScriptChecks:        enableLocalScriptChecks,
+		ScriptCheckSandboxEnabled:      boolVal(c.ScriptCheckSandbox.Enabled),
+		ScriptCheckSandboxUser:         stringVal(c.ScriptCheckSandbox.User),
+		ScriptCheckSandboxCgroupParent: stringVal(c.ScriptCheckSandbox.CgroupParent),
+		ScriptCheckSandboxCPUMax:       float64Val(c.ScriptCheckSandbox.CPUMax),
+		ScriptCheckSandboxMemoryMax:    intVal(c.ScriptCheckSandbox.MemoryMax),
+		EncryptKey:                     stringVal(c.EncryptKey),
+		GRPCAddrs:                      grpcAddrs,
+		GRPCPort:                       grpcPort,
+		GRPCTLSAddrs:                   grpcTlsAddrs,
+		GRPCTLSPort:                    grpcTlsPort,
+		HTTPMaxConnsPerClient:          intVal(c.Limits.HTTPMaxConnsPerClient),
+		HTTPSHandshakeTimeout:          b.durationVal("limits.https_handshake_timeout", c.Limits.HTTPSHandshakeTimeout),
+		KVMaxValueSize:                 uint64Val(c.Limits.KVMaxValueSize),
+		LeaveDrainTime:                 b.durationVal("performance.leave_drain_time", c.Performance.LeaveDrainTime),
+		LeaveOnTerm:                    leaveOnTerm,
 		StaticRuntimeConfig: StaticRuntimeConfig{
 			EncryptVerifyIncoming: boolVal(c.EncryptVerifyIncoming),
 			EncryptVerifyOutgoing: boolVal(c.EncryptVerifyOutgoing),
@@ -1103,6 +1127,9 @@ func (b *builder) build() (rt RuntimeConfig, err error) {
 		UnixSocketUser:                    stringVal(c.UnixSocket.User),
 		Watches:                           c.Watches,
 		XDSUpdateRateLimit:                limitVal(c.XDS.UpdateMaxPerSecond),
+		XDSPushCoalesceWindow:             b.durationVal("xds.push_coalesce_window", c.XDS.PushCoalesceWindow),
+		XDSEndpointPushCoalesceWindow:     b.durationVal("xds.endpoint_push_coalesce_window", c.XDS.EndpointPushCoalesceWindow),
+		XDSMaxConcurrentPushes:            intVal(c.XDS.MaxConcurrentPushes),
 		AutoReloadConfigCoalesceInterval:  1 * time.Second,
 		LocalProxyConfigResyncInterval:    30 * time.Second,
 	}
@@ -1330,6 +1357,49 @@ func (b *builder) validate(rt RuntimeConfig) error {
 	if rt.CheckOutputMaxSize < 1 {
 		return fmt.Errorf("check_output_max_size must be positive, to discard check output use the discard_check_output flag")
 	}
+	if rt.Audit.Enabled && len(rt.Audit.Sinks) == 0 {
+		return fmt.Errorf("audit.sink is required when the audit log is enabled")
+	}
+	for _, s := range rt.Audit.Sinks {
+		switch s.Type {
+		case audit.SinkTypeFile:
+			if s.Path == "" {
+				return fmt.Errorf("audit.sink[%s].path is required for file sinks", s.Name)
+			}
+		case audit.SinkTypeStdout:
+		default:
+			return fmt.Errorf("audit.sink[%s].type must be %q or %q", s.Name, audit.SinkTypeFile, audit.SinkTypeStdout)
+		}
+		if s.Format != audit.FormatJSON {
+			return fmt.Errorf("audit.sink[%s].format must be %q", s.Name, audit.FormatJSON)
+		}
+		for _, d := range s.Decisions {
+			if d != audit.DecisionAllow && d != audit.DecisionDeny {
+				return fmt.Errorf("audit.sink[%s].decisions must only contain %q or %q", s.Name, audit.DecisionAllow, audit.DecisionDeny)
+			}
+		}
+	}
+	if rt.CheckHistorySize < 0 {
+		return fmt.Errorf("check_history_size cannot be %d. Must be greater than or equal to zero", rt.CheckHistorySize)
+	}
+	if rt.CheckFlapWindow < 0 {
+		return fmt.Errorf("check_flap_detection.window cannot be %s. Must be greater than or equal to zero", rt.CheckFlapWindow)
+	}
+	if rt.CheckFlapWindow > 0 {
+		// The first of the check_history_size entries of the history is not
+		// a transition, so a threshold of check_history_size is never reached.
+		if rt.CheckFlapStartThreshold < 1 || rt.CheckFlapStartThreshold >= rt.CheckHistorySize {
+			return fmt.Errorf("check_flap_detection.start_threshold must be at least 1 and less than check_history_size (%d)", rt.CheckHistorySize)
+		}
+		if rt.CheckFlapStopThreshold < 0 || rt.CheckFlapStopThreshold >= rt.CheckFlapStartThreshold {
+			return fmt.Errorf("check_flap_detection.stop_threshold must be greater than or equal to zero and less than check_flap_detection.start_threshold")
+		}
+	}
+	switch rt.CheckFlapStatus {
+	case "", api.HealthPassing, api.HealthWarning, api.HealthCritical:
+	default:
+		return fmt.Errorf("check_flap_detection.status must be one of %q, %q or %q", api.HealthPassing, api.HealthWarning, api.HealthCritical)
+	}
 	if rt.AEInterval <= 0 {
 		return fmt.Errorf("ae_interval cannot be %s. Must be positive", rt.AEInterval)
 	}
@@ -1352,6 +1422,24 @@ func (b *builder) validate(rt RuntimeConfig) error {
 	if rt.DNSARecordLimit < 0 {
 		return fmt.Errorf("dns_config.a_record_limit cannot be %d. Must be greater than or equal to zero", rt.DNSARecordLimit)
 	}
+	if rt.ScriptCheckSandboxCPUMax < 0 {
+		return fmt.Errorf("script_check_sandbox.cpu_max cannot be %v. Must be greater than or equal to zero", rt.ScriptCheckSandboxCPUMax)
+	}
+	if rt.ScriptCheckSandboxMemoryMax < 0 {
+		return fmt.Errorf("script_check_sandbox.memory_max cannot be %d. Must be greater than or equal to zero", rt.ScriptCheckSandboxMemoryMax)
+	}
+	if rt.XDSPushCoalesceWindow < 0 {
+		return fmt.Errorf("xds.push_coalesce_window cannot be %s. Must be greater than or equal to zero", rt.XDSPushCoalesceWindow)
+	}
+	if rt.XDSEndpointPushCoalesceWindow < 0 {
+		return fmt.Errorf("xds.endpoint_push_coalesce_window cannot be %s. Must be greater than or equal to zero", rt.XDSEndpointPushCoalesceWindow)
+	}
+	if rt.XDSMaxConcurrentPushes < 0 {
+		return fmt.Errorf("xds.max_concurrent_pushes cannot be %d. Must be greater than or equal to zero", rt.XDSMaxConcurrentPushes)
+	}
+	if rt.ScriptCheckSandboxCgroupParent == "" && (rt.ScriptCheckSandboxCPUMax > 0 || rt.ScriptCheckSandboxMemoryMax > 0) {
+		return fmt.Errorf("script_check_sandbox.cgroup_parent is required to limit the CPU or memory of script checks")
+	}
 	if err := structs.ValidateNodeMetadata(rt.NodeMeta, false); err != nil {
 		return fmt.Errorf("node_meta invalid: %v", err)
 	}
@@ -1455,10 +1543,11 @@ func (b *builder) validate(rt RuntimeConfig) error {
 
 	// Validate the given Connect CA provider config
 	validCAProviders := map[string]bool{
-		"":                       true,
-		structs.ConsulCAProvider: true,
-		structs.VaultCAProvider:  true,
-		structs.AWSCAProvider:    true,
+		"":                         true,
+		structs.ConsulCAProvider:   true,
+		structs.VaultCAProvider:    true,
+		structs.AWSCAProvider:      true,
+		structs.ExternalCAProvider: true,
 	}
 	if _, ok := validCAProviders[rt.ConnectCAProvider]; !ok {
 		return fmt.Errorf("%s is not a valid CA provider", rt.ConnectCAProvider)
@@ -1476,6 +1565,10 @@ func (b *builder) validate(rt RuntimeConfig) error {
 			if _, err := ca.ParseAWSCAConfig(rt.ConnectCAConfig); err != nil {
 				return err
 			}
+		case structs.ExternalCAProvider:
+			if _, err := ca.ParseExternalCAConfig(rt.ConnectCAConfig); err != nil {
+				return err
+			}
 		}
 	}
 
@@ -1613,8 +1706,20 @@ func (b *builder) checkVal(v *CheckDefinition) *structs.CheckDefinition {
 		Method:                         stringVal(v.Method),
 		Body:                           stringVal(v.Body),
 		DisableRedirects:               boolVal(v.DisableRedirects),
+		BodyRegex:                      stringVal(v.BodyRegex),
+		JSONPath:                       stringVal(v.JSONPath),
+		JSONPathValue:                  stringVal(v.JSONPathValue),
+		RequiredHeaders:                v.RequiredHeaders,
+		LatencyWarning:                 b.durationVal(fmt.Sprintf("check[%s].latency_warning", id), v.LatencyWarning),
 		TCP:                            stringVal(v.TCP),
 		UDP:                            stringVal(v.UDP),
+		DNS:                            stringVal(v.DNS),
+		DNSServer:                      stringVal(v.DNSServer),
+		DNSRecordType:                  stringVal(v.DNSRecordType),
+		DNSExpect:                      v.DNSExpect,
+		TLSCert:                        stringVal(v.TLSCert),
+		TLSCertWarningDays:             intVal(v.TLSCertWarningDays),
+		TLSCertCriticalDays:            intVal(v.TLSCertCriticalDays),
 		Interval:                       b.durationVal(fmt.Sprintf("check[%s].interval", id), v.Interval),
 		DockerContainerID:              stringVal(v.DockerContainerID),
 		Shell:                          stringVal(v.Shell),
@@ -1624,6 +1729,13 @@ func (b *builder) checkVal(v *CheckDefinition) *structs.CheckDefinition {
 		TLSSkipVerify:                  boolVal(v.TLSSkipVerify),
 		AliasNode:                      stringVal(v.AliasNode),
 		AliasService:                   stringVal(v.AliasService),
+		CompositeChecks:                v.CompositeChecks,
+		CompositeNode:                  stringVal(v.CompositeNode),
+		CompositeMembers:               b.compositeMembersVal(v.CompositeMembers),
+		CompositeOperator:              stringVal(v.CompositeOperator),
+		CompositeThreshold:             intVal(v.CompositeThreshold),
+		CompositeStatus:                stringVal(v.CompositeStatus),
+		CompositePartialStatus:         stringVal(v.CompositePartialStatus),
 		Timeout:                        b.durationVal(fmt.Sprintf("check[%s].timeout", id), v.Timeout),
 		TTL:                            b.durationVal(fmt.Sprintf("check[%s].ttl", id), v.TTL),
 		SuccessBeforePassing:           intVal(v.SuccessBeforePassing),
@@ -1861,6 +1973,20 @@ func (b *builder) proxyModeVal(v *string) structs.ProxyMode {
 	return mode
 }
 
+func (b *builder) compositeMembersVal(v []CompositeMember) []structs.CompositeMember {
+	if len(v) == 0 {
+		return nil
+	}
+	members := make([]structs.CompositeMember, len(v))
+	for i, m := range v {
+		members[i] = structs.CompositeMember{
+			Node:    stringVal(m.Node),
+			CheckID: stringVal(m.CheckID),
+		}
+	}
+	return members
+}
+
 func (b *builder) pathsVal(v []ExposePath) []structs.ExposePath {
 	paths := make([]structs.ExposePath, len(v))
 	for i, p := range v {
@@ -2541,6 +2667,39 @@ func validateAutoConfigAuthorizer(rt RuntimeConfig) error {
 	return nil
 }
 
+func (b *builder) auditVal(v Audit) audit.Config {
+	names := make([]string, 0, len(v.Sinks))
+	for name := range v.Sinks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	sinks := make([]audit.SinkConfig, 0, len(names))
+	for _, name := range names {
+		s := v.Sinks[name]
+		if s.Mode != nil {
+			b.warn("audit.sink[%s].mode is not supported and will be ignored", name)
+		}
+		sinks = append(sinks, audit.SinkConfig{
+			Name:           name,
+			Type:           stringValWithDefault(s.Type, audit.SinkTypeFile),
+			Format:         stringValWithDefault(s.Format, audit.FormatJSON),
+			Path:           stringVal(s.Path),
+			RotateDuration: b.durationVal(fmt.Sprintf("audit.sink[%s].rotate_duration", name), s.RotateDuration),
+			RotateBytes:    intVal(s.RotateBytes),
+			RotateMaxFiles: intVal(s.RotateMaxFiles),
+			Endpoints:      s.Endpoints,
+			Decisions:      s.Decisions,
+			IncludeReads:   boolVal(s.IncludeReads),
+		})
+	}
+	return audit.Config{
+		Enabled:    boolVal(v.Enabled),
+		RPCEnabled: boolVal(v.RPCEnabled),
+		Sinks:      sinks,
+	}
+}
+
 func (b *builder) cloudConfigVal(v Config) hcpconfig.CloudConfig {
 	val := hcpconfig.CloudConfig{
 		ResourceID: os.Getenv("HCP_RESOURCE_ID"),
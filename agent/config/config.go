@@ -150,6 +150,8 @@ type Config struct {
 	BootstrapExpect                  *int                `mapstructure:"bootstrap_expect" json:"bootstrap_expect,omitempty"`
 	Cache                            Cache               `mapstructure:"cache" json:"-"`
 	Check                            *CheckDefinition    `mapstructure:"check" json:"-"` // needs to be a pointer to avoid partial merges
+	CheckFlapDetection               CheckFlapDetection  `mapstructure:"check_flap_detection" json:"-"`
+	CheckHistorySize                 *int                `mapstructure:"check_history_size" json:"check_history_size,omitempty"`
 	CheckOutputMaxSize               *int                `mapstructure:"check_output_max_size" json:"check_output_max_size,omitempty"`
 	CheckUpdateInterval              *string             `mapstructure:"check_update_interval" json:"check_update_interval,omitempty"`
 	Checks                           []CheckDefinition   `mapstructure:"checks" json:"-"`
@@ -230,6 +232,7 @@ type Config struct {
 	ServerMode                       *bool               `mapstructure:"server" json:"server,omitempty"`
 	ServerName                       *string             `mapstructure:"server_name" json:"server_name,omitempty"`
 	ServerRejoinAgeMax               *string             `mapstructure:"server_rejoin_age_max" json:"server_rejoin_age_max,omitempty"`
+	ScriptCheckSandbox               ScriptCheckSandbox  `mapstructure:"script_check_sandbox" json:"-"`
 	Service                          *ServiceDefinition  `mapstructure:"service" json:"-"`
 	Services                         []ServiceDefinition `mapstructure:"services" json:"-"`
 	SessionTTLMin                    *string             `mapstructure:"session_ttl_min" json:"session_ttl_min,omitempty"`
@@ -281,7 +284,6 @@ type Config struct {
 	VersionMetadata            *string    `mapstructure:"version_metadata" json:"-"`
 	BuildDate                  *time.Time `mapstructure:"build_date" json:"-"`
 
-	// Enterprise Only
 	Audit Audit `mapstructure:"audit" json:"-"`
 	// Enterprise Only
 	ReadReplica *bool `mapstructure:"read_replica" alias:"non_voting_server" json:"-"`
@@ -421,9 +423,21 @@ type CheckDefinition struct {
 	Method                         *string             `mapstructure:"method"`
 	Body                           *string             `mapstructure:"body"`
 	DisableRedirects               *bool               `mapstructure:"disable_redirects"`
+	BodyRegex                      *string             `mapstructure:"body_regex"`
+	JSONPath                       *string             `mapstructure:"json_path"`
+	JSONPathValue                  *string             `mapstructure:"json_path_value"`
+	RequiredHeaders                map[string]string   `mapstructure:"required_headers"`
+	LatencyWarning                 *string             `mapstructure:"latency_warning"`
 	OutputMaxSize                  *int                `mapstructure:"output_max_size"`
 	TCP                            *string             `mapstructure:"tcp"`
 	UDP                            *string             `mapstructure:"udp"`
+	DNS                            *string             `mapstructure:"dns"`
+	DNSServer                      *string             `mapstructure:"dns_server"`
+	DNSRecordType                  *string             `mapstructure:"dns_record_type"`
+	DNSExpect                      []string            `mapstructure:"dns_expect"`
+	TLSCert                        *string             `mapstructure:"tls_cert"`
+	TLSCertWarningDays             *int                `mapstructure:"tls_cert_warning_days"`
+	TLSCertCriticalDays            *int                `mapstructure:"tls_cert_critical_days"`
 	Interval                       *string             `mapstructure:"interval"`
 	DockerContainerID              *string             `mapstructure:"docker_container_id" alias:"dockercontainerid"`
 	Shell                          *string             `mapstructure:"shell"`
@@ -433,6 +447,13 @@ type CheckDefinition struct {
 	TLSSkipVerify                  *bool               `mapstructure:"tls_skip_verify" alias:"tlsskipverify"`
 	AliasNode                      *string             `mapstructure:"alias_node"`
 	AliasService                   *string             `mapstructure:"alias_service"`
+	CompositeChecks                []string            `mapstructure:"composite_checks"`
+	CompositeNode                  *string             `mapstructure:"composite_node"`
+	CompositeMembers               []CompositeMember   `mapstructure:"composite_members"`
+	CompositeOperator              *string             `mapstructure:"composite_operator"`
+	CompositeThreshold             *int                `mapstructure:"composite_threshold"`
+	CompositeStatus                *string             `mapstructure:"composite_status"`
+	CompositePartialStatus         *string             `mapstructure:"composite_partial_status"`
 	Timeout                        *string             `mapstructure:"timeout"`
 	TTL                            *string             `mapstructure:"ttl"`
 	H2PING                         *string             `mapstructure:"h2ping"`
@@ -446,6 +467,13 @@ type CheckDefinition struct {
 	EnterpriseMeta `mapstructure:",squash"`
 }
 
+// CompositeMember is a check combined by a composite check along with the
+// node it is registered on.
+type CompositeMember struct {
+	Node    *string `mapstructure:"node"`
+	CheckID *string `mapstructure:"check_id"`
+}
+
 // ServiceConnect is the connect block within a service registration
 type ServiceConnect struct {
 	// Native is true when this service can natively understand Connect.
@@ -643,21 +671,24 @@ type SOA struct {
 }
 
 type DNS struct {
-	AllowStale         *bool             `mapstructure:"allow_stale"`
-	ARecordLimit       *int              `mapstructure:"a_record_limit"`
-	DisableCompression *bool             `mapstructure:"disable_compression"`
-	EnableTruncate     *bool             `mapstructure:"enable_truncate"`
-	MaxStale           *string           `mapstructure:"max_stale"`
-	NodeTTL            *string           `mapstructure:"node_ttl"`
-	OnlyPassing        *bool             `mapstructure:"only_passing"`
-	RecursorStrategy   *string           `mapstructure:"recursor_strategy"`
-	RecursorTimeout    *string           `mapstructure:"recursor_timeout"`
-	ServiceTTL         map[string]string `mapstructure:"service_ttl"`
-	UDPAnswerLimit     *int              `mapstructure:"udp_answer_limit"`
-	NodeMetaTXT        *bool             `mapstructure:"enable_additional_node_meta_txt"`
-	SOA                *SOA              `mapstructure:"soa"`
-	UseCache           *bool             `mapstructure:"use_cache"`
-	CacheMaxAge        *string           `mapstructure:"cache_max_age"`
+	AllowStale            *bool             `mapstructure:"allow_stale"`
+	ARecordLimit          *int              `mapstructure:"a_record_limit"`
+	DisableCompression    *bool             `mapstructure:"disable_compression"`
+	EnableTruncate        *bool             `mapstructure:"enable_truncate"`
+	MaxStale              *string           `mapstructure:"max_stale"`
+	NodeTTL               *string           `mapstructure:"node_ttl"`
+	OnlyPassing           *bool             `mapstructure:"only_passing"`
+	RecursorStrategy      *string           `mapstructure:"recursor_strategy"`
+	RecursorTimeout       *string           `mapstructure:"recursor_timeout"`
+	ServiceTTL            map[string]string `mapstructure:"service_ttl"`
+	UDPAnswerLimit        *int              `mapstructure:"udp_answer_limit"`
+	NodeMetaTXT           *bool             `mapstructure:"enable_additional_node_meta_txt"`
+	SOA                   *SOA              `mapstructure:"soa"`
+	UseCache              *bool             `mapstructure:"use_cache"`
+	CacheMaxAge           *string           `mapstructure:"cache_max_age"`
+	AllowZoneTransferFrom []string          `mapstructure:"allow_zone_transfer_from"`
+	OrderByRTT            *bool             `mapstructure:"order_by_rtt"`
+	WeightedAnswers       *bool             `mapstructure:"weighted_answers"`
 
 	// Enterprise Only
 	PreferNamespace *bool `mapstructure:"prefer_namespace"`
@@ -823,6 +854,10 @@ type AuditSink struct {
 	RotateBytes       *int    `mapstructure:"rotate_bytes"`
 	RotateDuration    *string `mapstructure:"rotate_duration"`
 	RotateMaxFiles    *int    `mapstructure:"rotate_max_files"`
+
+	Endpoints    []string `mapstructure:"endpoints"`
+	Decisions    []string `mapstructure:"decisions"`
+	IncludeReads *bool    `mapstructure:"include_reads"`
 }
 
 type AutoConfigRaw struct {
@@ -937,7 +972,25 @@ type Peering struct {
 }
 
 type XDS struct {
-	UpdateMaxPerSecond *float64 `mapstructure:"update_max_per_second"`
+	UpdateMaxPerSecond         *float64 `mapstructure:"update_max_per_second"`
+	PushCoalesceWindow         *string  `mapstructure:"push_coalesce_window"`
+	EndpointPushCoalesceWindow *string  `mapstructure:"endpoint_push_coalesce_window"`
+	MaxConcurrentPushes        *int     `mapstructure:"max_concurrent_pushes"`
+}
+
+type ScriptCheckSandbox struct {
+	Enabled      *bool    `mapstructure:"enabled"`
+	User         *string  `mapstructure:"user"`
+	CgroupParent *string  `mapstructure:"cgroup_parent"`
+	CPUMax       *float64 `mapstructure:"cpu_max"`
+	MemoryMax    *int     `mapstructure:"memory_max"`
+}
+
+type CheckFlapDetection struct {
+	Window         *string `mapstructure:"window"`
+	StartThreshold *int    `mapstructure:"start_threshold"`
+	StopThreshold  *int    `mapstructure:"stop_threshold"`
+	Status         *string `mapstructure:"status"`
 }
 
 type RaftLogStoreRaw struct {
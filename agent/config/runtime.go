@@ -13,6 +13,7 @@ import (
 	"github.com/hashicorp/go-uuid"
 	"golang.org/x/time/rate"
 
+	"github.com/hernad/consul/agent/audit"
 	"github.com/hernad/consul/agent/cache"
 	"github.com/hernad/consul/agent/consul"
 	consulrate "github.com/hernad/consul/agent/consul/rate"
@@ -162,6 +163,12 @@ type RuntimeConfig struct {
 	// hcl: autopilot { upgrade_version_tag = string }
 	AutopilotUpgradeVersionTag string
 
+	// Audit configures the audit log, which records the accessor ID of the
+	// token and the ACL decision of the HTTP and RPC requests.
+	//
+	// hcl: audit { enabled = (true|false) rpc_enabled = (true|false) sink "name" { ... } }
+	Audit audit.Config
+
 	// Cloud contains configuration for agents to connect to HCP.
 	//
 	// hcl: cloud { ... }
@@ -296,6 +303,32 @@ type RuntimeConfig struct {
 	// hcl: dns_config { use_cache = (true|false) }
 	DNSUseCache bool
 
+	// DNSAllowZoneTransferFrom is the list of networks which are allowed to
+	// request a zone transfer (AXFR/IXFR) of the consul domain. Zone
+	// transfers are refused when the list is empty. After the first
+	// transfer the agent records the zone each time the catalog changes
+	// so IXFR can send differences, but changes committed in quick
+	// succession may share a single serial.
+	//
+	// hcl: dns_config { allow_zone_transfer_from = []string }
+	DNSAllowZoneTransferFrom []*net.IPNet
+
+	// DNSOrderByRTT sorts the instances returned by service lookups by their
+	// estimated round trip time from this agent, using the network
+	// coordinates, instead of shuffling them. The nearest instances are
+	// returned first.
+	//
+	// hcl: dns_config { order_by_rtt = (true|false) }
+	DNSOrderByRTT bool
+
+	// DNSWeightedAnswers orders the instances returned by service lookups
+	// according to their weights: instances with a higher weight are more
+	// likely to be returned first and instances with a weight of zero are
+	// omitted.
+	//
+	// hcl: dns_config { weighted_answers = (true|false) }
+	DNSWeightedAnswers bool
+
 	// DNSUseCache whether or not to use cache for dns queries
 	//
 	// hcl: dns_config { cache_max_age = "duration" }
@@ -416,6 +449,46 @@ type RuntimeConfig struct {
 	// hcl: check_update_interval = "duration"
 	CheckUpdateInterval time.Duration
 
+	// CheckHistorySize is the number of recent status transitions kept for
+	// each health check, see /v1/agent/check/<id>/history. The history is
+	// kept by the agent running the check: it is persisted in the data dir
+	// on a graceful shutdown, but it is lost when the agent doesn't shut
+	// down cleanly and it doesn't follow a check registered on another
+	// agent, so flap detection starts over in those cases.
+	//
+	// hcl: check_history_size = int
+	CheckHistorySize int
+
+	// CheckFlapWindow is the window in which the status transitions of a
+	// health check are counted to detect that it is flapping. Zero disables
+	// flap detection.
+	//
+	// hcl: check_flap_detection { window = "duration" }
+	CheckFlapWindow time.Duration
+
+	// CheckFlapStartThreshold is the number of status transitions within
+	// CheckFlapWindow after which a health check is flapping. It is lower
+	// than CheckHistorySize, which bounds the number of transitions kept.
+	//
+	// hcl: check_flap_detection { start_threshold = int }
+	CheckFlapStartThreshold int
+
+	// CheckFlapStopThreshold is the number of status transitions within
+	// CheckFlapWindow at or below which a flapping health check stops
+	// flapping. It is lower than CheckFlapStartThreshold so a check does
+	// not flap in and out of flapping.
+	//
+	// hcl: check_flap_detection { stop_threshold = int }
+	CheckFlapStopThreshold int
+
+	// CheckFlapStatus is the status reported for a flapping health check
+	// when it is better than the status of the check, for example
+	// "warning" to remove flapping instances from passing-only DNS answers.
+	// Empty leaves the status unchanged.
+	//
+	// hcl: check_flap_detection { status = string }
+	CheckFlapStatus string
+
 	// Maximum size for the output of a healtcheck
 	// hcl check_output_max_size int
 	// flag: -check_output_max_size int
@@ -669,6 +742,41 @@ type RuntimeConfig struct {
 	// flag: -enable-script-checks
 	EnableRemoteScriptChecks bool
 
+	// ScriptCheckSandboxEnabled runs script checks in their own user, mount
+	// and PID namespaces, with a read-only view of the host filesystem and
+	// their own /proc and /tmp, and kills every process of the check when it
+	// times out. Only supported on Linux.
+	//
+	// hcl: script_check_sandbox { enabled = (true|false) }
+	ScriptCheckSandboxEnabled bool
+
+	// ScriptCheckSandboxUser is the user sandboxed script checks run as. It
+	// cannot be root. Unless the agent runs as root it must be the user of
+	// the agent, which is the default. When the agent runs as root it
+	// defaults to nobody.
+	//
+	// hcl: script_check_sandbox { user = string }
+	ScriptCheckSandboxUser string
+
+	// ScriptCheckSandboxCgroupParent is the cgroup v2 directory under which a
+	// cgroup is created for each execution of a sandboxed script check. It
+	// is required to limit the CPU and memory of the checks.
+	//
+	// hcl: script_check_sandbox { cgroup_parent = string }
+	ScriptCheckSandboxCgroupParent string
+
+	// ScriptCheckSandboxCPUMax limits the CPU time of each sandboxed script
+	// check, in number of CPUs. Zero means no limit.
+	//
+	// hcl: script_check_sandbox { cpu_max = float64 }
+	ScriptCheckSandboxCPUMax float64
+
+	// ScriptCheckSandboxMemoryMax limits the memory of each sandboxed script
+	// check, in bytes. Zero means no limit.
+	//
+	// hcl: script_check_sandbox { memory_max = int }
+	ScriptCheckSandboxMemoryMax int
+
 	// EncryptKey contains the encryption key to use for the Serf communication.
 	//
 	// hcl: encrypt = string
@@ -1489,6 +1597,25 @@ type RuntimeConfig struct {
 	// hcl: xds { update_max_per_second = (float64|MaxFloat64) }
 	XDSUpdateRateLimit rate.Limit
 
+	// XDSPushCoalesceWindow is how long an xDS stream waits for more proxy
+	// config updates before pushing resources to Envoy, so that a burst of
+	// catalog changes results in a single push. Zero disables coalescing.
+	//
+	// hcl: xds { push_coalesce_window = "duration" }
+	XDSPushCoalesceWindow time.Duration
+
+	// XDSEndpointPushCoalesceWindow replaces XDSPushCoalesceWindow when the
+	// pending updates only change the endpoints of upstreams.
+	//
+	// hcl: xds { endpoint_push_coalesce_window = "duration" }
+	XDSEndpointPushCoalesceWindow time.Duration
+
+	// XDSMaxConcurrentPushes limits how many xDS streams generate and push
+	// resources at the same time. Zero means no limit.
+	//
+	// hcl: xds { max_concurrent_pushes = int }
+	XDSMaxConcurrentPushes int
+
 	// AutoReloadConfigCoalesceInterval Coalesce Interval for auto reload config
 	AutoReloadConfigCoalesceInterval time.Duration
 
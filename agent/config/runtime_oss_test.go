@@ -31,7 +31,6 @@ var enterpriseConfigKeyWarnings = []string{
 	enterpriseConfigKeyError{key: "dns_config.prefer_namespace"}.Error(),
 	enterpriseConfigKeyError{key: "acl.msp_disable_bootstrap"}.Error(),
 	enterpriseConfigKeyError{key: "acl.tokens.managed_service_provider"}.Error(),
-	enterpriseConfigKeyError{key: "audit"}.Error(),
 	enterpriseConfigKeyError{key: "reporting.license.enabled"}.Error(),
 }
 
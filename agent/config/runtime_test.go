@@ -26,6 +26,7 @@ import (
 	"golang.org/x/time/rate"
 
 	"github.com/hernad/consul/acl"
+	"github.com/hernad/consul/agent/audit"
 	"github.com/hernad/consul/agent/cache"
 	"github.com/hernad/consul/agent/checks"
 	"github.com/hernad/consul/agent/consul"
@@ -2087,6 +2088,78 @@ func TestLoad_IntegrationWithFlags(t *testing.T) {
 		hcl:         []string{`dns_config = { a_record_limit = -1 }`},
 		expectedErr: "dns_config.a_record_limit cannot be -1. Must be greater than or equal to zero",
 	})
+	run(t, testCase{
+		desc: "audit enabled without sink",
+		args: []string{
+			`-data-dir=` + dataDir,
+		},
+		json:        []string{`{ "audit": { "enabled": true } }`},
+		hcl:         []string{`audit = { enabled = true }`},
+		expectedErr: "audit.sink is required when the audit log is enabled",
+	})
+	run(t, testCase{
+		desc: "audit file sink without path",
+		args: []string{
+			`-data-dir=` + dataDir,
+		},
+		json:        []string{`{ "audit": { "enabled": true, "sink": { "main": { "type": "file" } } } }`},
+		hcl:         []string{`audit = { enabled = true sink "main" { type = "file" } }`},
+		expectedErr: "audit.sink[main].path is required for file sinks",
+	})
+	run(t, testCase{
+		desc: "check_flap_detection.stop_threshold not below start_threshold",
+		args: []string{
+			`-data-dir=` + dataDir,
+		},
+		json:        []string{`{ "check_flap_detection": { "window": "10m", "start_threshold": 4, "stop_threshold": 4 } }`},
+		hcl:         []string{`check_flap_detection = { window = "10m", start_threshold = 4, stop_threshold = 4 }`},
+		expectedErr: "check_flap_detection.stop_threshold must be greater than or equal to zero and less than check_flap_detection.start_threshold",
+	})
+	run(t, testCase{
+		desc: "check_flap_detection.start_threshold above check_history_size",
+		args: []string{
+			`-data-dir=` + dataDir,
+		},
+		json:        []string{`{ "check_history_size": 3, "check_flap_detection": { "window": "10m" } }`},
+		hcl:         []string{`check_history_size = 3 check_flap_detection = { window = "10m" }`},
+		expectedErr: "check_flap_detection.start_threshold must be at least 1 and less than check_history_size (3)",
+	})
+	run(t, testCase{
+		desc: "check_flap_detection.start_threshold equal to check_history_size",
+		args: []string{
+			`-data-dir=` + dataDir,
+		},
+		json:        []string{`{ "check_history_size": 5, "check_flap_detection": { "window": "10m", "start_threshold": 5 } }`},
+		hcl:         []string{`check_history_size = 5 check_flap_detection = { window = "10m", start_threshold = 5 }`},
+		expectedErr: "check_flap_detection.start_threshold must be at least 1 and less than check_history_size (5)",
+	})
+	run(t, testCase{
+		desc: "script_check_sandbox.memory_max invalid",
+		args: []string{
+			`-data-dir=` + dataDir,
+		},
+		json:        []string{`{ "script_check_sandbox": { "enabled": true, "cgroup_parent": "/sys/fs/cgroup/consul", "memory_max": -1 } }`},
+		hcl:         []string{`script_check_sandbox = { enabled = true, cgroup_parent = "/sys/fs/cgroup/consul", memory_max = -1 }`},
+		expectedErr: "script_check_sandbox.memory_max cannot be -1. Must be greater than or equal to zero",
+	})
+	run(t, testCase{
+		desc: "xds.max_concurrent_pushes invalid",
+		args: []string{
+			`-data-dir=` + dataDir,
+		},
+		json:        []string{`{ "xds": { "max_concurrent_pushes": -1 } }`},
+		hcl:         []string{`xds = { max_concurrent_pushes = -1 }`},
+		expectedErr: "xds.max_concurrent_pushes cannot be -1. Must be greater than or equal to zero",
+	})
+	run(t, testCase{
+		desc: "script_check_sandbox limits without cgroup_parent",
+		args: []string{
+			`-data-dir=` + dataDir,
+		},
+		json:        []string{`{ "script_check_sandbox": { "enabled": true, "cpu_max": 0.5 } }`},
+		hcl:         []string{`script_check_sandbox = { enabled = true, cpu_max = 0.5 }`},
+		expectedErr: "script_check_sandbox.cgroup_parent is required to limit the CPU or memory of script checks",
+	})
 	run(t, testCase{
 		desc: "performance.raft_multiplier < 0",
 		args: []string{
@@ -2587,7 +2660,7 @@ func TestLoad_IntegrationWithFlags(t *testing.T) {
 		hcl: []string{
 			`check = { name = "a", os_service = "foo" }`,
 		},
-		expectedErr: `Interval must be > 0 for Script, HTTP, H2PING, TCP, UDP or OSService checks`,
+		expectedErr: `Interval must be > 0 for Script, HTTP, H2PING, TCP, UDP, OSService, DNS or TLSCert checks`,
 	})
 	run(t, testCase{
 		desc: "os_service check",
@@ -2610,6 +2683,59 @@ func TestLoad_IntegrationWithFlags(t *testing.T) {
 			}
 			rt.DataDir = dataDir
 		}})
+	run(t, testCase{
+		desc: "composite check",
+		args: []string{
+			`-data-dir=` + dataDir,
+		},
+		json: []string{
+			`{ "check": { "name": "a", "composite_checks": ["db", "cache"], "composite_node": "foo", "composite_members": [{ "node": "bar", "check_id": "db" }], "composite_threshold": 2, "composite_status": "warning", "composite_partial_status": "passing" } }`,
+		},
+		hcl: []string{
+			`check = { name = "a", composite_checks = ["db", "cache"], composite_node = "foo", composite_members = [{ node = "bar", check_id = "db" }], composite_threshold = 2, composite_status = "warning", composite_partial_status = "passing" }`,
+		},
+		expected: func(rt *RuntimeConfig) {
+			rt.Checks = []*structs.CheckDefinition{
+				{
+					Name:                   "a",
+					CompositeChecks:        []string{"db", "cache"},
+					CompositeNode:          "foo",
+					CompositeMembers:       []structs.CompositeMember{{Node: "bar", CheckID: "db"}},
+					CompositeThreshold:     2,
+					CompositeStatus:        "warning",
+					CompositePartialStatus: "passing",
+					OutputMaxSize:          checks.DefaultBufSize,
+				},
+			}
+			rt.DataDir = dataDir
+		},
+	})
+	run(t, testCase{
+		desc: "composite check with an interval",
+		args: []string{
+			`-data-dir=` + dataDir,
+		},
+		json: []string{
+			`{ "check": { "name": "a", "composite_checks": ["db"], "http": "http://foo", "interval": "10s" } }`,
+		},
+		hcl: []string{
+			`check = { name = "a", composite_checks = ["db"], http = "http://foo", interval = "10s" }`,
+		},
+		expectedErr: `Composite checks can't be combined with another check type`,
+	})
+	run(t, testCase{
+		desc: "composite check with an invalid operator",
+		args: []string{
+			`-data-dir=` + dataDir,
+		},
+		json: []string{
+			`{ "check": { "name": "a", "composite_checks": ["db"], "composite_operator": "xor" } }`,
+		},
+		hcl: []string{
+			`check = { name = "a", composite_checks = ["db"], composite_operator = "xor" }`,
+		},
+		expectedErr: `CompositeOperator must be "and" or "or"`,
+	})
 	run(t, testCase{
 		desc: "multiple service files",
 		args: []string{
@@ -5022,7 +5148,7 @@ func TestLoad_IntegrationWithFlags(t *testing.T) {
 					}
 				}
 			}`},
-		expectedErr: `auto_config.authorization.static has invalid configuration: exactly one of 'JWTValidationPubKeys', 'JWKSURL', or 'OIDCDiscoveryURL' must be set for type "jwt"`,
+		expectedErr: `auto_config.authorization.static has invalid configuration: exactly one of 'JWTValidationPubKeys', 'JWKSURL', 'JWKS', 'JWKSFile', or 'OIDCDiscoveryURL' must be set for type "jwt"`,
 	})
 
 	run(t, testCase{
@@ -5064,7 +5190,7 @@ func TestLoad_IntegrationWithFlags(t *testing.T) {
 					}
 				}
 			}`},
-		expectedErr: `auto_config.authorization.static has invalid configuration: exactly one of 'JWTValidationPubKeys', 'JWKSURL', or 'OIDCDiscoveryURL' must be set for type "jwt"`,
+		expectedErr: `auto_config.authorization.static has invalid configuration: exactly one of 'JWTValidationPubKeys', 'JWKSURL', 'JWKS', 'JWKSFile', or 'OIDCDiscoveryURL' must be set for type "jwt"`,
 	})
 
 	run(t, testCase{
@@ -6232,6 +6358,18 @@ func TestLoad_FullConfig(t *testing.T) {
 				Method:                         "Dou0nGT5",
 				Body:                           "5PBQd2OT",
 				DisableRedirects:               true,
+				BodyRegex:                      "^ok(.*)$",
+				JSONPath:                       "$.xU3dBfUE[0]",
+				JSONPathValue:                  "Ctd6fJ1w",
+				RequiredHeaders:                map[string]string{"Gc4TUkOy": "nq7zUKX3"},
+				LatencyWarning:                 2218 * time.Second,
+				DNS:                            "fDK9ey0p.example.com",
+				DNSServer:                      "127.0.0.1:8600",
+				DNSRecordType:                  "SRV",
+				DNSExpect:                      []string{"hI5Nt2Lk", "oP1q8MfR"},
+				TLSCert:                        "cE7XhN0y:443",
+				TLSCertWarningDays:             21,
+				TLSCertCriticalDays:            3,
 				OutputMaxSize:                  checks.DefaultBufSize,
 				TCP:                            "JY6fTTcw",
 				H2PING:                         "rQ8eyCSF",
@@ -6246,8 +6384,13 @@ func TestLoad_FullConfig(t *testing.T) {
 				DeregisterCriticalServiceAfter: 13209 * time.Second,
 			},
 		},
-		CheckUpdateInterval: 16507 * time.Second,
-		ClientAddrs:         []*net.IPAddr{ipAddr("93.83.18.19")},
+		CheckUpdateInterval:     16507 * time.Second,
+		CheckHistorySize:        17,
+		CheckFlapWindow:         3482 * time.Second,
+		CheckFlapStartThreshold: 8,
+		CheckFlapStopThreshold:  3,
+		CheckFlapStatus:         "warning",
+		ClientAddrs:             []*net.IPAddr{ipAddr("93.83.18.19")},
 		ConfigEntryBootstrap: []structs.ConfigEntry{
 			&structs.ProxyConfigEntry{
 				Kind:           structs.ProxyDefaults,
@@ -6315,6 +6458,22 @@ func TestLoad_FullConfig(t *testing.T) {
 			"CSRMaxConcurrent":    float64(2),
 		},
 		ConnectMeshGatewayWANFederationEnabled: false,
+		Audit: audit.Config{
+			Enabled:    true,
+			RPCEnabled: true,
+			Sinks: []audit.SinkConfig{{
+				Name:           "Bk4xD2Tz",
+				Type:           "file",
+				Format:         "json",
+				Path:           "/var/log/consul/qD7sNb3R.json",
+				RotateBytes:    30917,
+				RotateDuration: 6813 * time.Second,
+				RotateMaxFiles: 12,
+				Endpoints:      []string{"/v1/acl/", "ACL."},
+				Decisions:      []string{"deny"},
+				IncludeReads:   true,
+			}},
+		},
 		Cloud: hcpconfig.CloudConfig{
 			ResourceID:   "N43DsscE",
 			ClientID:     "6WvsDZCP",
@@ -6345,6 +6504,9 @@ func TestLoad_FullConfig(t *testing.T) {
 		DNSNodeMetaTXT:                   true,
 		DNSUseCache:                      true,
 		DNSCacheMaxAge:                   5 * time.Minute,
+		DNSAllowZoneTransferFrom:         []*net.IPNet{cidr("10.0.0.0/8"), cidr("fd00::/8")},
+		DNSOrderByRTT:                    true,
+		DNSWeightedAnswers:               true,
 		DataDir:                          dataDir,
 		Datacenter:                       "rzo029wg",
 		DefaultQueryTime:                 16743 * time.Second,
@@ -6393,48 +6555,53 @@ func TestLoad_FullConfig(t *testing.T) {
 			EnableSyslog:   true,
 			SyslogFacility: "hHv79Uia",
 		},
-		MaxQueryTime:            18237 * time.Second,
-		NodeID:                  types.NodeID("AsUIlw99"),
-		NodeMeta:                map[string]string{"5mgGQMBk": "mJLtVMSG", "A7ynFMJB": "0Nx6RGab"},
-		NodeName:                "otlLxGaI",
-		ReadReplica:             true,
-		PeeringEnabled:          true,
-		PidFile:                 "43xN80Km",
-		PrimaryGateways:         []string{"aej8eeZo", "roh2KahS"},
-		PrimaryGatewaysInterval: 18866 * time.Second,
-		RPCAdvertiseAddr:        tcpAddr("17.99.29.16:3757"),
-		RPCBindAddr:             tcpAddr("16.99.34.17:3757"),
-		RPCHandshakeTimeout:     1932 * time.Millisecond,
-		RPCClientTimeout:        62 * time.Second,
-		RPCHoldTimeout:          15707 * time.Second,
-		RPCProtocol:             30793,
-		RPCRateLimit:            12029.43,
-		RPCMaxBurst:             44848,
-		RPCMaxConnsPerClient:    2954,
-		RaftProtocol:            3,
-		RaftSnapshotThreshold:   16384,
-		RaftSnapshotInterval:    30 * time.Second,
-		RaftTrailingLogs:        83749,
-		ReconnectTimeoutLAN:     23739 * time.Second,
-		ReconnectTimeoutWAN:     26694 * time.Second,
-		RequestLimitsMode:       consulrate.ModePermissive,
-		RequestLimitsReadRate:   99.0,
-		RequestLimitsWriteRate:  101.0,
-		RejoinAfterLeave:        true,
-		RetryJoinIntervalLAN:    8067 * time.Second,
-		RetryJoinIntervalWAN:    28866 * time.Second,
-		RetryJoinLAN:            []string{"pbsSFY7U", "l0qLtWij", "LR3hGDoG", "MwVpZ4Up"},
-		RetryJoinMaxAttemptsLAN: 913,
-		RetryJoinMaxAttemptsWAN: 23160,
-		RetryJoinWAN:            []string{"PFsR02Ye", "rJdQIhER", "EbFSc3nA", "kwXTh623"},
-		RPCConfig:               consul.RPCConfig{EnableStreaming: true},
-		SegmentLimit:            123,
-		SerfPortLAN:             8301,
-		SerfPortWAN:             8302,
-		ServerMode:              true,
-		ServerName:              "Oerr9n1G",
-		ServerRejoinAgeMax:      604800 * time.Second,
-		ServerPort:              3757,
+		MaxQueryTime:                   18237 * time.Second,
+		NodeID:                         types.NodeID("AsUIlw99"),
+		NodeMeta:                       map[string]string{"5mgGQMBk": "mJLtVMSG", "A7ynFMJB": "0Nx6RGab"},
+		NodeName:                       "otlLxGaI",
+		ReadReplica:                    true,
+		PeeringEnabled:                 true,
+		PidFile:                        "43xN80Km",
+		PrimaryGateways:                []string{"aej8eeZo", "roh2KahS"},
+		PrimaryGatewaysInterval:        18866 * time.Second,
+		RPCAdvertiseAddr:               tcpAddr("17.99.29.16:3757"),
+		RPCBindAddr:                    tcpAddr("16.99.34.17:3757"),
+		RPCHandshakeTimeout:            1932 * time.Millisecond,
+		RPCClientTimeout:               62 * time.Second,
+		RPCHoldTimeout:                 15707 * time.Second,
+		RPCProtocol:                    30793,
+		RPCRateLimit:                   12029.43,
+		RPCMaxBurst:                    44848,
+		RPCMaxConnsPerClient:           2954,
+		RaftProtocol:                   3,
+		RaftSnapshotThreshold:          16384,
+		RaftSnapshotInterval:           30 * time.Second,
+		RaftTrailingLogs:               83749,
+		ReconnectTimeoutLAN:            23739 * time.Second,
+		ReconnectTimeoutWAN:            26694 * time.Second,
+		RequestLimitsMode:              consulrate.ModePermissive,
+		RequestLimitsReadRate:          99.0,
+		RequestLimitsWriteRate:         101.0,
+		RejoinAfterLeave:               true,
+		RetryJoinIntervalLAN:           8067 * time.Second,
+		RetryJoinIntervalWAN:           28866 * time.Second,
+		RetryJoinLAN:                   []string{"pbsSFY7U", "l0qLtWij", "LR3hGDoG", "MwVpZ4Up"},
+		RetryJoinMaxAttemptsLAN:        913,
+		RetryJoinMaxAttemptsWAN:        23160,
+		RetryJoinWAN:                   []string{"PFsR02Ye", "rJdQIhER", "EbFSc3nA", "kwXTh623"},
+		RPCConfig:                      consul.RPCConfig{EnableStreaming: true},
+		ScriptCheckSandboxEnabled:      true,
+		ScriptCheckSandboxUser:         "R4MRhQZa",
+		ScriptCheckSandboxCgroupParent: "/sys/fs/cgroup/2SYSOiA1",
+		ScriptCheckSandboxCPUMax:       0.25,
+		ScriptCheckSandboxMemoryMax:    58226,
+		SegmentLimit:                   123,
+		SerfPortLAN:                    8301,
+		SerfPortWAN:                    8302,
+		ServerMode:                     true,
+		ServerName:                     "Oerr9n1G",
+		ServerRejoinAgeMax:             604800 * time.Second,
+		ServerPort:                     3757,
 		Services: []*structs.ServiceDefinition{
 			{
 				ID:      "wI1dzxS4",
@@ -6854,7 +7021,10 @@ func TestLoad_FullConfig(t *testing.T) {
 				"args":       []interface{}{"dltjDJ2a", "flEa7C2d"},
 			},
 		},
-		XDSUpdateRateLimit: 9526.2,
+		XDSUpdateRateLimit:            9526.2,
+		XDSPushCoalesceWindow:         2531 * time.Second,
+		XDSEndpointPushCoalesceWindow: 1853 * time.Second,
+		XDSMaxConcurrentPushes:        7347,
 		RaftLogStoreConfig: consul.RaftLogStoreConfig{
 			Backend:         consul.LogStoreBackendWAL,
 			DisableLogCache: true,
@@ -54,6 +54,18 @@ advertise_addr_wan = "78.63.37.19"
 advertise_reconnect_timeout = "0s"
 audit = {
     enabled = true
+    rpc_enabled = true
+    sink "Bk4xD2Tz" {
+        type = "file"
+        format = "json"
+        path = "/var/log/consul/qD7sNb3R.json"
+        rotate_bytes = 30917
+        rotate_duration = "6813s"
+        rotate_max_files = 12
+        endpoints = ["/v1/acl/", "ACL."]
+        decisions = ["deny"]
+        include_reads = true
+    }
 }
 auto_config = {
     enabled = false
@@ -115,6 +127,20 @@ check = {
     method = "Dou0nGT5"
     body = "5PBQd2OT"
     disable_redirects = true
+    body_regex = "^ok(.*)$"
+    json_path = "$.xU3dBfUE[0]"
+    json_path_value = "Ctd6fJ1w"
+    required_headers = {
+        Gc4TUkOy = "nq7zUKX3"
+    }
+    latency_warning = "2218s"
+    dns = "fDK9ey0p.example.com"
+    dns_server = "127.0.0.1:8600"
+    dns_record_type = "SRV"
+    dns_expect = ["hI5Nt2Lk", "oP1q8MfR"]
+    tls_cert = "cE7XhN0y:443"
+    tls_cert_warning_days = 21
+    tls_cert_critical_days = 3
     tcp = "JY6fTTcw"
     h2ping = "rQ8eyCSF"
     h2ping_use_tls = false
@@ -189,6 +215,13 @@ checks = [
     }
 ]
 check_update_interval = "16507s"
+check_history_size = 17
+check_flap_detection {
+    window = "3482s"
+    start_threshold = 8
+    stop_threshold = 3
+    status = "warning"
+}
 client_addr = "93.83.18.19"
 config_entries {
     # This is using the repeated block-to-array HCL magic
@@ -261,11 +294,13 @@ alt_domain = "1789hsd"
 dns_config {
     allow_stale = true
     a_record_limit = 29907
+    allow_zone_transfer_from = [ "10.0.0.0/8", "fd00::/8" ]
     disable_compression = true
     enable_truncate = true
     max_stale = "29685s"
     node_ttl = "7084s"
     only_passing = true
+    order_by_rtt = true
     recursor_timeout = "4427s"
     service_ttl = {
         "*" = "32030s"
@@ -273,6 +308,7 @@ dns_config {
     udp_answer_limit = 29909
     use_cache = true
     cache_max_age = "5m"
+    weighted_answers = true
     prefer_namespace = true
 }
 enable_acl_replication = true
@@ -392,6 +428,13 @@ retry_max_wan = 23160
 rpc {
     enable_streaming = true
 }
+script_check_sandbox {
+    enabled = true
+    user = "R4MRhQZa"
+    cgroup_parent = "/sys/fs/cgroup/2SYSOiA1"
+    cpu_max = 0.25
+    memory_max = 58226
+}
 segment_limit = 123
 serf_lan = "99.43.63.15"
 serf_wan = "67.88.33.19"
@@ -794,4 +837,7 @@ watches = [{
 }]
 xds {
   update_max_per_second = 9526.2
+  push_coalesce_window = "2531s"
+  endpoint_push_coalesce_window = "1853s"
+  max_concurrent_pushes = 7347
 }
@@ -106,6 +106,28 @@ func Test_ComputeResolvedServiceConfig(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "proxy with enable_http3",
+			args: args{
+				scReq: &structs.ServiceConfigRequest{
+					Name: "sid",
+				},
+				entries: &ResolvedServiceConfigSet{
+					ServiceDefaults: map[structs.ServiceID]*structs.ServiceConfigEntry{
+						sid: {
+							Protocol:    "http",
+							EnableHTTP3: true,
+						},
+					},
+				},
+			},
+			want: &structs.ServiceConfigResponse{
+				ProxyConfig: map[string]interface{}{
+					"protocol":     "http",
+					"enable_http3": true,
+				},
+			},
+		},
 		{
 			name: "proxy upstream mesh-gateway inherits proxy-defaults",
 			args: args{
@@ -14,13 +14,16 @@ import (
 //
 // The return value of `auth` is only valid if the second value `match` is true.
 // If `match` is false, then the intention doesn't match this target and any result should be ignored.
+//
+// targetTrustDomain is only set for sources of a federated trust domain, which
+// only match intentions that name that trust domain.
 func AuthorizeIntentionTarget(
-	target, targetNS, targetAP, targetPeer string,
+	target, targetNS, targetAP, targetPeer, targetTrustDomain string,
 	ixn *structs.Intention,
 	matchType structs.IntentionMatchType,
 ) (bool, bool) {
 
-	match := IntentionMatch(target, targetNS, targetAP, targetPeer, ixn, matchType)
+	match := IntentionMatch(target, targetNS, targetAP, targetPeer, targetTrustDomain, ixn, matchType)
 
 	if match {
 		return ixn.Action == structs.IntentionActionAllow, true
@@ -31,7 +34,7 @@ func AuthorizeIntentionTarget(
 
 // IntentionMatch determines whether the target is covered by the given intention.
 func IntentionMatch(
-	target, targetNS, targetAP, targetPeer string,
+	target, targetNS, targetAP, targetPeer, targetTrustDomain string,
 	ixn *structs.Intention,
 	matchType structs.IntentionMatchType,
 ) bool {
@@ -57,6 +60,10 @@ func IntentionMatch(
 			return false
 		}
 
+		if ixn.SourceTrustDomain != targetTrustDomain {
+			return false
+		}
+
 		if acl.PartitionOrDefault(ixn.SourcePartition) != acl.PartitionOrDefault(targetAP) {
 			return false
 		}
@@ -11,15 +11,16 @@ import (
 
 func TestAuthorizeIntentionTarget(t *testing.T) {
 	cases := []struct {
-		name       string
-		target     string
-		targetNS   string
-		targetAP   string
-		targetPeer string
-		ixn        *structs.Intention
-		matchType  structs.IntentionMatchType
-		auth       bool
-		match      bool
+		name              string
+		target            string
+		targetNS          string
+		targetAP          string
+		targetPeer        string
+		targetTrustDomain string
+		ixn               *structs.Intention
+		matchType         structs.IntentionMatchType
+		auth              bool
+		match             bool
 	}{
 		// Source match type
 		{
@@ -174,11 +175,54 @@ func TestAuthorizeIntentionTarget(t *testing.T) {
 			auth:      false,
 			match:     false,
 		},
+		{
+			name:              "trust domain match",
+			target:            "web",
+			targetNS:          structs.IntentionDefaultNamespace,
+			targetTrustDomain: "example.org",
+			ixn: &structs.Intention{
+				SourceName:        "web",
+				SourceNS:          structs.IntentionDefaultNamespace,
+				SourceTrustDomain: "example.org",
+				Action:            structs.IntentionActionAllow,
+			},
+			matchType: structs.IntentionMatchSource,
+			auth:      true,
+			match:     true,
+		},
+		{
+			name:              "no wildcard match for federated source",
+			target:            "web",
+			targetNS:          structs.IntentionDefaultNamespace,
+			targetTrustDomain: "example.org",
+			ixn: &structs.Intention{
+				SourceName: structs.WildcardSpecifier,
+				SourceNS:   structs.WildcardSpecifier,
+				Action:     structs.IntentionActionAllow,
+			},
+			matchType: structs.IntentionMatchSource,
+			auth:      false,
+			match:     false,
+		},
+		{
+			name:     "no trust domain match for local source",
+			target:   "web",
+			targetNS: structs.IntentionDefaultNamespace,
+			ixn: &structs.Intention{
+				SourceName:        "web",
+				SourceNS:          structs.IntentionDefaultNamespace,
+				SourceTrustDomain: "example.org",
+				Action:            structs.IntentionActionAllow,
+			},
+			matchType: structs.IntentionMatchSource,
+			auth:      false,
+			match:     false,
+		},
 	}
 
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			auth, match := AuthorizeIntentionTarget(tc.target, tc.targetNS, tc.targetAP, tc.targetPeer, tc.ixn, tc.matchType)
+			auth, match := AuthorizeIntentionTarget(tc.target, tc.targetNS, tc.targetAP, tc.targetPeer, tc.targetTrustDomain, tc.ixn, tc.matchType)
 			assert.Equal(t, tc.auth, auth)
 			assert.Equal(t, tc.match, match)
 		})
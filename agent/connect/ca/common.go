@@ -5,12 +5,47 @@ package ca
 
 import (
 	"bytes"
+	"crypto"
+	"crypto/rand"
 	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"fmt"
+	"math/big"
+	"net/url"
+	"time"
 
 	"github.com/hernad/consul/agent/connect"
+	"github.com/hernad/consul/agent/structs"
 )
 
+// validateFederatedTrustDomains checks the federated trust domains of the
+// common configuration, which all the providers accept.
+func validateFederatedTrustDomains(domains []structs.FederatedTrustDomain) error {
+	_, err := connect.NewSpiffeIDTemplates(domains)
+	if err != nil {
+		return fmt.Errorf("invalid FederatedTrustDomains: %w", err)
+	}
+
+	for _, d := range domains {
+		if d.BundleEndpointURL != "" {
+			u, err := url.Parse(d.BundleEndpointURL)
+			if err != nil || u.Scheme != "https" || u.Host == "" {
+				return fmt.Errorf("invalid FederatedTrustDomains: trust domain %q: BundleEndpointURL must be an https URL", d.Name)
+			}
+		}
+		if d.BundleEndpointCACert != "" {
+			if d.BundleEndpointURL == "" {
+				return fmt.Errorf("invalid FederatedTrustDomains: trust domain %q: BundleEndpointCACert requires a BundleEndpointURL", d.Name)
+			}
+			if _, err := connect.ParseCert(d.BundleEndpointCACert); err != nil {
+				return fmt.Errorf("invalid FederatedTrustDomains: trust domain %q: invalid BundleEndpointCACert: %w", d.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
 func validateSetIntermediate(intermediatePEM, rootPEM string, spiffeID *connect.SpiffeIDSigning) error {
 	// Get the key from the incoming intermediate cert so we can compare it
 	// to the currently stored key.
@@ -43,17 +78,12 @@ func validateSetIntermediate(intermediatePEM, rootPEM string, spiffeID *connect.
 	return nil
 }
 
-func validateIntermediateSignedByPrivateKey(intermediatePEM string, privateKey string) error {
+func validateIntermediateSignedByPrivateKey(intermediatePEM string, privKey crypto.Signer) error {
 	intermediate, err := connect.ParseCert(intermediatePEM)
 	if err != nil {
 		return fmt.Errorf("error parsing intermediate PEM: %v", err)
 	}
 
-	privKey, err := connect.ParseSigner(privateKey)
-	if err != nil {
-		return err
-	}
-
 	// Compare the two keys to make sure they match.
 	b1, err := x509.MarshalPKIXPublicKey(intermediate.PublicKey)
 	if err != nil {
@@ -94,3 +124,90 @@ func validateSignIntermediate(csr *x509.CertificateRequest, spiffeID *connect.Sp
 	}
 	return nil
 }
+
+// signLeafCert signs the leaf certificate requested by the CSR with the given
+// CA certificate and private key, and returns it PEM encoded.
+func signLeafCert(csr *x509.CertificateRequest, caCert *x509.Certificate, signer crypto.Signer, serial uint64, ttl time.Duration) (string, error) {
+	// Create the keyId for the cert from the signing private key.
+	keyId, err := connect.KeyId(signer.Public())
+	if err != nil {
+		return "", err
+	}
+
+	// Create the subjectKeyId for the cert from the csr public key.
+	subjectKeyID, err := connect.KeyId(csr.PublicKey)
+	if err != nil {
+		return "", err
+	}
+
+	// Cert template for generation
+	sn := &big.Int{}
+	sn.SetUint64(serial)
+	// Sign the certificate valid from 1 minute in the past, this helps it be
+	// accepted right away even when nodes are not in close time sync across the
+	// cluster. A minute is more than enough for typical DC clock drift.
+	effectiveNow := time.Now().Add(-1 * time.Minute)
+	template := x509.Certificate{
+		SerialNumber: sn,
+		URIs:         csr.URIs,
+		Signature:    csr.Signature,
+		// We use the correct signature algorithm for the CA key we are signing with
+		// regardless of the algorithm used to sign the CSR signature above since
+		// the leaf might use a different key type.
+		SignatureAlgorithm:    connect.SigAlgoForKey(signer),
+		PublicKeyAlgorithm:    csr.PublicKeyAlgorithm,
+		PublicKey:             csr.PublicKey,
+		BasicConstraintsValid: true,
+		KeyUsage: x509.KeyUsageDataEncipherment |
+			x509.KeyUsageKeyAgreement |
+			x509.KeyUsageDigitalSignature |
+			x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{
+			x509.ExtKeyUsageClientAuth,
+			x509.ExtKeyUsageServerAuth,
+		},
+		NotAfter:       effectiveNow.Add(ttl),
+		NotBefore:      effectiveNow,
+		AuthorityKeyId: keyId,
+		SubjectKeyId:   subjectKeyID,
+		DNSNames:       csr.DNSNames,
+		IPAddresses:    csr.IPAddresses,
+	}
+
+	// Create the certificate, PEM encode it and return that value.
+	var buf bytes.Buffer
+	bs, err := x509.CreateCertificate(
+		rand.Reader, &template, caCert, csr.PublicKey, signer)
+	if err != nil {
+		return "", fmt.Errorf("error generating certificate: %s", err)
+	}
+	err = pem.Encode(&buf, &pem.Block{Type: "CERTIFICATE", Bytes: bs})
+	if err != nil {
+		return "", fmt.Errorf("error encoding certificate: %s", err)
+	}
+
+	// Set the response
+	return buf.String(), nil
+}
+
+// signRevocationList signs the revocation list of the given certificates with
+// the given CA certificate and private key, valid until the CA certificate
+// expires, and returns it PEM encoded.
+func signRevocationList(revoked []pkix.RevokedCertificate, number *big.Int, caCert *x509.Certificate, signer crypto.Signer) (string, error) {
+	template := &x509.RevocationList{
+		Number:              number,
+		ThisUpdate:          time.Now().Add(-1 * CertificateTimeDriftBuffer),
+		NextUpdate:          caCert.NotAfter,
+		RevokedCertificates: revoked,
+	}
+	bs, err := x509.CreateRevocationList(rand.Reader, template, caCert, signer)
+	if err != nil {
+		return "", fmt.Errorf("error signing revocation list: %s", err)
+	}
+
+	var buf bytes.Buffer
+	if err := pem.Encode(&buf, &pem.Block{Type: "X509 CRL", Bytes: bs}); err != nil {
+		return "", fmt.Errorf("error encoding revocation list: %s", err)
+	}
+	return buf.String(), nil
+}
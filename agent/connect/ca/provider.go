@@ -5,7 +5,9 @@ package ca
 
 import (
 	"crypto/x509"
+	"crypto/x509/pkix"
 	"errors"
+	"math/big"
 )
 
 //go:generate mockery --name Provider --inpackage
@@ -29,6 +31,24 @@ type PrimaryUsesIntermediate interface {
 	GenerateLeafSigningCert() (string, error)
 }
 
+// RevocationListSigner is an optional interface that CA providers may
+// implement to sign certificate revocation lists with the key of their active
+// leaf signing certificate. The lists let the proxies reject revoked leaf
+// certificates during the TLS handshake.
+//
+// The Consul and external providers implement it, while the Vault and AWS
+// providers don't hold the key of their leaf signing certificate. Proxies
+// only check the lists once every datacenter has one, so as long as a
+// datacenter uses such a provider, the serial number revocations are only
+// enforced by the Consul agents and servers.
+type RevocationListSigner interface {
+	// SignRevocationList returns a PEM encoded certificate revocation list of
+	// the given certificates, issued by the active leaf signing certificate
+	// and valid until that certificate expires. number must increase every
+	// time the list of the same signing certificate changes.
+	SignRevocationList(revoked []pkix.RevokedCertificate, number *big.Int) (string, error)
+}
+
 // ProviderConfig encapsulates all the data Consul passes to `Configure` on a
 // new provider instance. The provider must treat this as read-only and make
 // copies of any map or slice if it might modify them internally.
@@ -57,6 +77,11 @@ type ProviderConfig struct {
 	// of state like UUIDs of external resources that the provider has created and
 	// needs to continue to manage.
 	State map[string]string
+
+	// DryRun is set when the provider is only configured to preview a
+	// configuration change. The provider must not leave behind any resource it
+	// creates, as it is cleaned up without ever becoming active.
+	DryRun bool
 }
 
 // Provider is the interface for Consul to interact with
@@ -707,6 +707,10 @@ func ParseAWSCAConfig(raw map[string]interface{}) (*structs.AWSCAProviderConfig,
 		return nil, err
 	}
 
+	if err := validateFederatedTrustDomains(config.FederatedTrustDomains); err != nil {
+		return nil, err
+	}
+
 	// Extra keytype validation since PCA is more limited than other providers
 	_, _, err = keyTypeToAlgos(config.PrivateKeyType, config.PrivateKeyBits)
 	if err != nil {
@@ -5,6 +5,7 @@ package ca
 
 import (
 	"bytes"
+	"crypto"
 	"crypto/rand"
 	"crypto/sha256"
 	"crypto/x509"
@@ -41,6 +42,10 @@ type ConsulProvider struct {
 	spiffeID  *connect.SpiffeIDSigning
 	logger    hclog.Logger
 
+	// keys holds the private keys when the provider is configured to use a
+	// PKCS#11 token. It is nil when the keys are kept in the state store.
+	keys consulKeyStore
+
 	// testState is only used to test Consul leader's handling of providers that
 	// need to persist state. Consul provider actually manages it's state directly
 	// in the FSM since it is highly sensitive not (root private keys) not just
@@ -64,6 +69,23 @@ type ConsulProviderStateDelegate interface {
 	ApplyCARequest(*structs.CARequest) (interface{}, error)
 }
 
+// consulKeyStore creates and uses private keys that never leave an external
+// key store. Only the opaque handles it returns are written to the state.
+type consulKeyStore interface {
+	// GenerateKey creates a new private key of the given type and size and
+	// returns its handle.
+	GenerateKey(keyType string, keyBits int) (string, error)
+	// Signer returns a signer backed by the key with the given handle.
+	Signer(handle string) (crypto.Signer, error)
+	// Close releases the resources held by the key store.
+	Close() error
+}
+
+// newConsulKeyStore opens the PKCS#11 token described by the given config.
+// When ephemeral is set the keys only live as long as the key store. It is a
+// variable so tests can use a key store that does not need an HSM.
+var newConsulKeyStore = newPKCS11KeyStore
+
 func hexStringHash(input string) string {
 	hash := sha256.Sum256([]byte(input))
 	return connect.HexString(hash[:])
@@ -78,6 +100,11 @@ func (c *ConsulProvider) Configure(cfg ProviderConfig) error {
 	}
 	c.config = config
 	c.id = hexStringHash(fmt.Sprintf("%s,%s,%s,%d,%v", config.PrivateKey, config.RootCert, config.PrivateKeyType, config.PrivateKeyBits, cfg.IsPrimary))
+	if config.PKCS11 != nil {
+		// Keys created in a different token cannot be reused, so the token is
+		// part of the ID.
+		c.id = hexStringHash(fmt.Sprintf("%s,%s,%s", c.id, config.PKCS11.Lib, config.PKCS11.TokenLabel))
+	}
 	c.clusterID = cfg.ClusterID
 	c.isPrimary = cfg.IsPrimary
 	c.spiffeID = connect.SpiffeIDSigningForCluster(c.clusterID)
@@ -85,6 +112,22 @@ func (c *ConsulProvider) Configure(cfg ProviderConfig) error {
 	// Passthrough test state for state handling tests. See testState doc.
 	c.parseTestState(cfg.RawConfig, cfg.State)
 
+	if c.keys != nil {
+		if err := c.keys.Close(); err != nil {
+			c.logger.Warn("failed to close the PKCS#11 session", "error", err)
+		}
+		c.keys = nil
+	}
+	if config.PKCS11 != nil {
+		// The keys generated for a dry run are destroyed along with the
+		// session when the provider is cleaned up.
+		keys, err := newConsulKeyStore(config.PKCS11, cfg.DryRun)
+		if err != nil {
+			return fmt.Errorf("error opening PKCS#11 token: %v", err)
+		}
+		c.keys = keys
+	}
+
 	// Exit early if the state store has an entry for this provider's config.
 	providerState, err := c.Delegate.ProviderState(c.id)
 	if err != nil {
@@ -171,23 +214,26 @@ func (c *ConsulProvider) GenerateCAChain() (string, error) {
 	// Generate a private key if needed
 	newState := *providerState
 	if c.config.PrivateKey == "" {
-		_, pk, err := connect.GeneratePrivateKeyWithConfig(c.config.PrivateKeyType, c.config.PrivateKeyBits)
-		if err != nil {
+		if err := c.generatePrivateKey(&newState); err != nil {
 			return "", err
 		}
-		newState.PrivateKey = pk
 	} else {
 		newState.PrivateKey = c.config.PrivateKey
 	}
 
 	// Generate the root CA if necessary
 	if c.config.RootCert == "" {
+		signer, err := c.signer(&newState)
+		if err != nil {
+			return "", fmt.Errorf("error loading private key: %s", err)
+		}
+
 		nextSerial, err := c.incrementAndGetNextSerialNumber()
 		if err != nil {
 			return "", fmt.Errorf("error computing next serial number: %v", err)
 		}
 
-		ca, err := c.generateCA(newState.PrivateKey, nextSerial, c.config.RootCertTTL)
+		ca, err := c.generateCA(signer, nextSerial, c.config.RootCertTTL)
 		if err != nil {
 			return "", fmt.Errorf("error generating CA: %v", err)
 		}
@@ -222,7 +268,11 @@ func (c *ConsulProvider) GenerateIntermediateCSR() (string, string, error) {
 	}
 
 	// Create a new private key and CSR.
-	signer, pk, err := connect.GeneratePrivateKeyWithConfig(c.config.PrivateKeyType, c.config.PrivateKeyBits)
+	newState := *providerState
+	if err := c.generatePrivateKey(&newState); err != nil {
+		return "", "", err
+	}
+	signer, err := c.signer(&newState)
 	if err != nil {
 		return "", "", err
 	}
@@ -233,8 +283,6 @@ func (c *ConsulProvider) GenerateIntermediateCSR() (string, string, error) {
 	}
 
 	// Write the new provider state to the store.
-	newState := *providerState
-	newState.PrivateKey = pk
 	args := &structs.CARequest{
 		Op:            structs.CAOpSetProviderState,
 		ProviderState: &newState,
@@ -261,7 +309,11 @@ func (c *ConsulProvider) SetIntermediate(intermediatePEM, rootPEM, _ string) err
 	if err = validateSetIntermediate(intermediatePEM, rootPEM, c.spiffeID); err != nil {
 		return err
 	}
-	if err := validateIntermediateSignedByPrivateKey(intermediatePEM, providerState.PrivateKey); err != nil {
+	signer, err := c.signer(providerState)
+	if err != nil {
+		return err
+	}
+	if err := validateIntermediateSignedByPrivateKey(intermediatePEM, signer); err != nil {
 		return err
 	}
 
@@ -306,6 +358,14 @@ func (c *ConsulProvider) Cleanup(_ bool, _ map[string]interface{}) error {
 		return err
 	}
 
+	// The keys are left in the token, only the session is closed.
+	if c.keys != nil {
+		if err := c.keys.Close(); err != nil {
+			c.logger.Warn("failed to close the PKCS#11 session", "error", err)
+		}
+		c.keys = nil
+	}
+
 	return nil
 }
 
@@ -324,25 +384,8 @@ func (c *ConsulProvider) Sign(csr *x509.CertificateRequest) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	if providerState.PrivateKey == "" {
-		return "", ErrNotInitialized
-	}
-
-	// Create the keyId for the cert from the signing private key.
-	signer, err := connect.ParseSigner(providerState.PrivateKey)
-	if err != nil {
-		return "", err
-	}
-	if signer == nil {
-		return "", ErrNotInitialized
-	}
-	keyId, err := connect.KeyId(signer.Public())
-	if err != nil {
-		return "", err
-	}
 
-	// Create the subjectKeyId for the cert from the csr public key.
-	subjectKeyID, err := connect.KeyId(csr.PublicKey)
+	signer, err := c.signer(providerState)
 	if err != nil {
 		return "", err
 	}
@@ -362,54 +405,31 @@ func (c *ConsulProvider) Sign(csr *x509.CertificateRequest) (string, error) {
 		return "", fmt.Errorf("error computing next serial number: %v", err)
 	}
 
-	// Cert template for generation
-	sn := &big.Int{}
-	sn.SetUint64(nextSerial)
-	// Sign the certificate valid from 1 minute in the past, this helps it be
-	// accepted right away even when nodes are not in close time sync across the
-	// cluster. A minute is more than enough for typical DC clock drift.
-	effectiveNow := time.Now().Add(-1 * time.Minute)
-	template := x509.Certificate{
-		SerialNumber: sn,
-		URIs:         csr.URIs,
-		Signature:    csr.Signature,
-		// We use the correct signature algorithm for the CA key we are signing with
-		// regardless of the algorithm used to sign the CSR signature above since
-		// the leaf might use a different key type.
-		SignatureAlgorithm:    connect.SigAlgoForKey(signer),
-		PublicKeyAlgorithm:    csr.PublicKeyAlgorithm,
-		PublicKey:             csr.PublicKey,
-		BasicConstraintsValid: true,
-		KeyUsage: x509.KeyUsageDataEncipherment |
-			x509.KeyUsageKeyAgreement |
-			x509.KeyUsageDigitalSignature |
-			x509.KeyUsageKeyEncipherment,
-		ExtKeyUsage: []x509.ExtKeyUsage{
-			x509.ExtKeyUsageClientAuth,
-			x509.ExtKeyUsageServerAuth,
-		},
-		NotAfter:       effectiveNow.Add(c.config.LeafCertTTL),
-		NotBefore:      effectiveNow,
-		AuthorityKeyId: keyId,
-		SubjectKeyId:   subjectKeyID,
-		DNSNames:       csr.DNSNames,
-		IPAddresses:    csr.IPAddresses,
+	return signLeafCert(csr, caCert, signer, nextSerial, c.config.LeafCertTTL)
+}
+
+// SignRevocationList implements RevocationListSigner.
+func (c *ConsulProvider) SignRevocationList(revoked []pkix.RevokedCertificate, number *big.Int) (string, error) {
+	providerState, err := c.getState()
+	if err != nil {
+		return "", err
 	}
 
-	// Create the certificate, PEM encode it and return that value.
-	var buf bytes.Buffer
-	bs, err := x509.CreateCertificate(
-		rand.Reader, &template, caCert, csr.PublicKey, signer)
+	signer, err := c.signer(providerState)
 	if err != nil {
-		return "", fmt.Errorf("error generating certificate: %s", err)
+		return "", err
 	}
-	err = pem.Encode(&buf, &pem.Block{Type: "CERTIFICATE", Bytes: bs})
+
+	certPEM, err := c.ActiveLeafSigningCert()
 	if err != nil {
-		return "", fmt.Errorf("error encoding certificate: %s", err)
+		return "", err
+	}
+	caCert, err := connect.ParseCert(certPEM)
+	if err != nil {
+		return "", fmt.Errorf("error parsing CA cert: %s", err)
 	}
 
-	// Set the response
-	return buf.String(), nil
+	return signRevocationList(revoked, number, caCert, signer)
 }
 
 // SignIntermediate will validate the CSR to ensure the trust domain in the
@@ -428,7 +448,7 @@ func (c *ConsulProvider) SignIntermediate(csr *x509.CertificateRequest) (string,
 	}
 
 	// Get the signing private key.
-	signer, err := connect.ParseSigner(providerState.PrivateKey)
+	signer, err := c.signer(providerState)
 	if err != nil {
 		return "", err
 	}
@@ -509,9 +529,9 @@ func (c *ConsulProvider) CrossSignCA(cert *x509.Certificate) (string, error) {
 		return "", err
 	}
 
-	privKey, err := connect.ParseSigner(providerState.PrivateKey)
+	privKey, err := c.signer(providerState)
 	if err != nil {
-		return "", fmt.Errorf("error parsing private key %q: %s", providerState.PrivateKey, err)
+		return "", fmt.Errorf("error loading private key: %s", err)
 	}
 
 	rootCA, err := connect.ParseCert(providerState.RootCert)
@@ -584,11 +604,17 @@ func (c *ConsulProvider) getState() (*structs.CAConsulProviderState, error) {
 }
 
 func (c *ConsulProvider) incrementAndGetNextSerialNumber() (uint64, error) {
+	return incrementAndGetNextSerialNumber(c.Delegate)
+}
+
+// incrementAndGetNextSerialNumber returns the next serial number from the
+// counter shared by the providers that keep their state in the state store.
+func incrementAndGetNextSerialNumber(delegate ConsulProviderStateDelegate) (uint64, error) {
 	args := &structs.CARequest{
 		Op: structs.CAOpIncrementProviderSerialNumber,
 	}
 
-	raw, err := c.Delegate.ApplyCARequest(args)
+	raw, err := delegate.ApplyCARequest(args)
 	if err != nil {
 		return 0, err
 	}
@@ -596,13 +622,44 @@ func (c *ConsulProvider) incrementAndGetNextSerialNumber() (uint64, error) {
 	return raw.(uint64), nil
 }
 
-// generateCA makes a new root CA using the current private key
-func (c *ConsulProvider) generateCA(privateKey string, sn uint64, rootCertTTL time.Duration) (string, error) {
-	privKey, err := connect.ParseSigner(privateKey)
+// generatePrivateKey creates a new private key and stores it, or its handle
+// when using a PKCS#11 token, in the given state.
+func (c *ConsulProvider) generatePrivateKey(state *structs.CAConsulProviderState) error {
+	if c.keys != nil {
+		handle, err := c.keys.GenerateKey(c.config.PrivateKeyType, c.config.PrivateKeyBits)
+		if err != nil {
+			return fmt.Errorf("error generating private key in PKCS#11 token: %v", err)
+		}
+		state.PrivateKey = ""
+		state.PrivateKeyHandle = handle
+		return nil
+	}
+
+	_, pk, err := connect.GeneratePrivateKeyWithConfig(c.config.PrivateKeyType, c.config.PrivateKeyBits)
 	if err != nil {
-		return "", fmt.Errorf("error parsing private key %q: %s", privateKey, err)
+		return err
 	}
+	state.PrivateKey = pk
+	state.PrivateKeyHandle = ""
+	return nil
+}
+
+// signer returns the signer for the private key of the given state.
+func (c *ConsulProvider) signer(state *structs.CAConsulProviderState) (crypto.Signer, error) {
+	if state.PrivateKeyHandle != "" {
+		if c.keys == nil {
+			return nil, fmt.Errorf("private key is kept in a PKCS#11 token but the provider is not configured to use one")
+		}
+		return c.keys.Signer(state.PrivateKeyHandle)
+	}
+	if state.PrivateKey == "" {
+		return nil, ErrNotInitialized
+	}
+	return connect.ParseSigner(state.PrivateKey)
+}
 
+// generateCA makes a new root CA using the given private key
+func (c *ConsulProvider) generateCA(privKey crypto.Signer, sn uint64, rootCertTTL time.Duration) (string, error) {
 	// The URI (SPIFFE compatible) for the cert
 	id := connect.SpiffeIDSigningForCluster(c.clusterID)
 	keyId, err := connect.KeyId(privKey.Public())
@@ -0,0 +1,422 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build cgo
+
+package ca
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/miekg/pkcs11"
+
+	"github.com/hernad/consul/agent/structs"
+)
+
+const (
+	pkcs11HandlePrefix     = "pkcs11:id="
+	pkcs11DefaultKeyLabel  = "consul-connect-ca"
+	pkcs11KeyIDLength      = 16
+	pkcs11FindObjectsLimit = 2
+)
+
+var (
+	oidPublicKeyRSA   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
+	oidPublicKeyECDSA = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+
+	// pkcs11CurveParams holds the DER encoded OIDs of the named curves, keyed
+	// by their size, as expected in CKA_EC_PARAMS.
+	pkcs11CurveParams = map[int][]byte{
+		224: {0x06, 0x05, 0x2b, 0x81, 0x04, 0x00, 0x21},
+		256: {0x06, 0x08, 0x2a, 0x86, 0x48, 0xce, 0x3d, 0x03, 0x01, 0x07},
+		384: {0x06, 0x05, 0x2b, 0x81, 0x04, 0x00, 0x22},
+		521: {0x06, 0x05, 0x2b, 0x81, 0x04, 0x00, 0x23},
+	}
+
+	// pkcs11DigestInfoPrefixes holds the DER prefixes of the PKCS #1 v1.5
+	// DigestInfo structure, which CKM_RSA_PKCS expects the caller to add.
+	pkcs11DigestInfoPrefixes = map[crypto.Hash][]byte{
+		crypto.SHA256: {0x30, 0x31, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x01, 0x05, 0x00, 0x04, 0x20},
+		crypto.SHA384: {0x30, 0x41, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x02, 0x05, 0x00, 0x04, 0x30},
+		crypto.SHA512: {0x30, 0x51, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x03, 0x05, 0x00, 0x04, 0x40},
+	}
+)
+
+var (
+	// pkcs11Modules holds the loaded PKCS#11 modules, keyed by path. A module
+	// can only be initialized once per process, so it is shared by all the
+	// providers and never finalized.
+	pkcs11Modules     = make(map[string]*pkcs11.Ctx)
+	pkcs11ModulesLock sync.Mutex
+)
+
+func loadPKCS11Module(lib string) (*pkcs11.Ctx, error) {
+	pkcs11ModulesLock.Lock()
+	defer pkcs11ModulesLock.Unlock()
+
+	if ctx, ok := pkcs11Modules[lib]; ok {
+		return ctx, nil
+	}
+
+	ctx := pkcs11.New(lib)
+	if ctx == nil {
+		return nil, fmt.Errorf("failed to load PKCS#11 module %q", lib)
+	}
+	if err := ctx.Initialize(); err != nil && !isPKCS11Error(err, pkcs11.CKR_CRYPTOKI_ALREADY_INITIALIZED) {
+		ctx.Destroy()
+		return nil, fmt.Errorf("failed to initialize PKCS#11 module %q: %v", lib, err)
+	}
+	pkcs11Modules[lib] = ctx
+	return ctx, nil
+}
+
+func isPKCS11Error(err error, code uint) bool {
+	var perr pkcs11.Error
+	return errors.As(err, &perr) && uint(perr) == code
+}
+
+// pkcs11KeyStore keeps the provider's private keys in a PKCS#11 token. It uses
+// a single session, so all the operations are serialized.
+type pkcs11KeyStore struct {
+	ctx      *pkcs11.Ctx
+	keyLabel string
+	// ephemeral keys are session objects, which the token destroys when the
+	// session is closed.
+	ephemeral bool
+
+	lock    sync.Mutex
+	session pkcs11.SessionHandle
+	closed  bool
+}
+
+var _ consulKeyStore = (*pkcs11KeyStore)(nil)
+
+func newPKCS11KeyStore(config *structs.ConsulCAPKCS11Config, ephemeral bool) (consulKeyStore, error) {
+	ctx, err := loadPKCS11Module(config.Lib)
+	if err != nil {
+		return nil, err
+	}
+
+	slots, err := ctx.GetSlotList(true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list slots: %v", err)
+	}
+	slot, found := uint(0), false
+	for _, id := range slots {
+		info, err := ctx.GetTokenInfo(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read token info of slot %d: %v", id, err)
+		}
+		if strings.TrimSpace(info.Label) == config.TokenLabel {
+			slot, found = id, true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("no token with label %q found", config.TokenLabel)
+	}
+
+	session, err := ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open session: %v", err)
+	}
+	err = ctx.Login(session, pkcs11.CKU_USER, config.PIN)
+	if err != nil && !isPKCS11Error(err, pkcs11.CKR_USER_ALREADY_LOGGED_IN) {
+		ctx.CloseSession(session)
+		return nil, fmt.Errorf("failed to log in to token %q: %v", config.TokenLabel, err)
+	}
+
+	keyLabel := config.KeyLabel
+	if keyLabel == "" {
+		keyLabel = pkcs11DefaultKeyLabel
+	}
+	return &pkcs11KeyStore{ctx: ctx, keyLabel: keyLabel, ephemeral: ephemeral, session: session}, nil
+}
+
+// GenerateKey implements consulKeyStore. The private key is created as a
+// sensitive, non-extractable token object, or session object when the key
+// store is ephemeral.
+func (s *pkcs11KeyStore) GenerateKey(keyType string, keyBits int) (string, error) {
+	id := make([]byte, pkcs11KeyIDLength)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+
+	public := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, !s.ephemeral),
+		pkcs11.NewAttribute(pkcs11.CKA_VERIFY, true),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, s.keyLabel),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, id),
+	}
+	private := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, !s.ephemeral),
+		pkcs11.NewAttribute(pkcs11.CKA_PRIVATE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_SENSITIVE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_EXTRACTABLE, false),
+		pkcs11.NewAttribute(pkcs11.CKA_SIGN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, s.keyLabel),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, id),
+	}
+
+	var mechanism *pkcs11.Mechanism
+	switch strings.ToLower(keyType) {
+	case "ec":
+		params, ok := pkcs11CurveParams[keyBits]
+		if !ok {
+			return "", fmt.Errorf("unsupported EC key size: %d", keyBits)
+		}
+		mechanism = pkcs11.NewMechanism(pkcs11.CKM_EC_KEY_PAIR_GEN, nil)
+		public = append(public, pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, params))
+	case "rsa":
+		mechanism = pkcs11.NewMechanism(pkcs11.CKM_RSA_PKCS_KEY_PAIR_GEN, nil)
+		public = append(public,
+			pkcs11.NewAttribute(pkcs11.CKA_MODULUS_BITS, keyBits),
+			pkcs11.NewAttribute(pkcs11.CKA_PUBLIC_EXPONENT, []byte{0x01, 0x00, 0x01}),
+		)
+	default:
+		return "", fmt.Errorf("unknown private key type requested: %s", keyType)
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.closed {
+		return "", errors.New("PKCS#11 session is closed")
+	}
+
+	if _, _, err := s.ctx.GenerateKeyPair(s.session, []*pkcs11.Mechanism{mechanism}, public, private); err != nil {
+		return "", fmt.Errorf("failed to generate key pair: %v", err)
+	}
+	return pkcs11HandlePrefix + hex.EncodeToString(id), nil
+}
+
+// Signer implements consulKeyStore.
+func (s *pkcs11KeyStore) Signer(handle string) (crypto.Signer, error) {
+	if !strings.HasPrefix(handle, pkcs11HandlePrefix) {
+		return nil, fmt.Errorf("invalid PKCS#11 key handle %q", handle)
+	}
+	id, err := hex.DecodeString(strings.TrimPrefix(handle, pkcs11HandlePrefix))
+	if err != nil {
+		return nil, fmt.Errorf("invalid PKCS#11 key handle %q: %v", handle, err)
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.closed {
+		return nil, errors.New("PKCS#11 session is closed")
+	}
+
+	private, err := s.findObject(pkcs11.CKO_PRIVATE_KEY, id)
+	if err != nil {
+		return nil, err
+	}
+	public, err := s.findObject(pkcs11.CKO_PUBLIC_KEY, id)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := s.publicKey(public)
+	if err != nil {
+		return nil, err
+	}
+	return &pkcs11Signer{store: s, object: private, public: pub}, nil
+}
+
+// Close implements consulKeyStore.
+func (s *pkcs11KeyStore) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	return s.ctx.CloseSession(s.session)
+}
+
+func (s *pkcs11KeyStore) findObject(class uint, id []byte) (pkcs11.ObjectHandle, error) {
+	template := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, class),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, id),
+	}
+	if err := s.ctx.FindObjectsInit(s.session, template); err != nil {
+		return 0, fmt.Errorf("failed to search for key: %v", err)
+	}
+	objects, _, err := s.ctx.FindObjects(s.session, pkcs11FindObjectsLimit)
+	if finalErr := s.ctx.FindObjectsFinal(s.session); err == nil {
+		err = finalErr
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to search for key: %v", err)
+	}
+
+	switch len(objects) {
+	case 0:
+		return 0, fmt.Errorf("key %x not found in token", id)
+	case 1:
+		return objects[0], nil
+	default:
+		return 0, fmt.Errorf("more than one key with ID %x found in token", id)
+	}
+}
+
+// publicKey reads the public key of the given object. The public key is
+// encoded as a PKIX structure so it can be parsed by the standard library.
+func (s *pkcs11KeyStore) publicKey(object pkcs11.ObjectHandle) (crypto.PublicKey, error) {
+	attrs, err := s.ctx.GetAttributeValue(s.session, object, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, nil),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key type: %v", err)
+	}
+	keyType, err := pkcs11Uint(attrs[0].Value)
+	if err != nil {
+		return nil, err
+	}
+
+	switch keyType {
+	case pkcs11.CKK_EC:
+		attrs, err := s.ctx.GetAttributeValue(s.session, object, []*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, nil),
+			pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read EC public key: %v", err)
+		}
+		// The point is usually wrapped in a DER OCTET STRING but some tokens
+		// return it raw.
+		point := attrs[1].Value
+		var unwrapped []byte
+		if rest, err := asn1.Unmarshal(point, &unwrapped); err == nil && len(rest) == 0 {
+			point = unwrapped
+		}
+		return parsePKCS11PublicKey(pkix.AlgorithmIdentifier{
+			Algorithm:  oidPublicKeyECDSA,
+			Parameters: asn1.RawValue{FullBytes: attrs[0].Value},
+		}, point)
+
+	case pkcs11.CKK_RSA:
+		attrs, err := s.ctx.GetAttributeValue(s.session, object, []*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_MODULUS, nil),
+			pkcs11.NewAttribute(pkcs11.CKA_PUBLIC_EXPONENT, nil),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read RSA public key: %v", err)
+		}
+		exponent := new(big.Int).SetBytes(attrs[1].Value)
+		if !exponent.IsInt64() {
+			return nil, fmt.Errorf("invalid RSA public exponent")
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(attrs[0].Value),
+			E: int(exponent.Int64()),
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported key type %d", keyType)
+	}
+}
+
+func parsePKCS11PublicKey(algorithm pkix.AlgorithmIdentifier, key []byte) (crypto.PublicKey, error) {
+	der, err := asn1.Marshal(struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}{
+		Algorithm: algorithm,
+		PublicKey: asn1.BitString{Bytes: key, BitLength: 8 * len(key)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParsePKIXPublicKey(der)
+}
+
+// pkcs11Uint decodes a CK_ULONG attribute, which is in native byte order.
+func pkcs11Uint(value []byte) (uint, error) {
+	if len(value) == 0 || len(value) > 8 {
+		return 0, fmt.Errorf("invalid attribute length %d", len(value))
+	}
+	var out uint
+	for i := len(value) - 1; i >= 0; i-- {
+		out = out<<8 | uint(value[i])
+	}
+	return out, nil
+}
+
+// pkcs11Signer is a crypto.Signer backed by a private key in a PKCS#11 token.
+type pkcs11Signer struct {
+	store  *pkcs11KeyStore
+	object pkcs11.ObjectHandle
+	public crypto.PublicKey
+}
+
+var _ crypto.Signer = (*pkcs11Signer)(nil)
+
+func (s *pkcs11Signer) Public() crypto.PublicKey {
+	return s.public
+}
+
+// Sign implements crypto.Signer. ECDSA and RSA PKCS #1 v1.5 signatures are
+// supported, which covers the algorithms picked by connect.SigAlgoForKey.
+func (s *pkcs11Signer) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	var (
+		mechanism *pkcs11.Mechanism
+		input     []byte
+	)
+	switch s.public.(type) {
+	case *rsa.PublicKey:
+		if _, ok := opts.(*rsa.PSSOptions); ok {
+			return nil, errors.New("RSA-PSS signatures are not supported")
+		}
+		prefix, ok := pkcs11DigestInfoPrefixes[opts.HashFunc()]
+		if !ok {
+			return nil, fmt.Errorf("unsupported hash function %v", opts.HashFunc())
+		}
+		mechanism = pkcs11.NewMechanism(pkcs11.CKM_RSA_PKCS, nil)
+		input = append(append([]byte{}, prefix...), digest...)
+	default:
+		mechanism = pkcs11.NewMechanism(pkcs11.CKM_ECDSA, nil)
+		input = digest
+	}
+
+	s.store.lock.Lock()
+	defer s.store.lock.Unlock()
+	if s.store.closed {
+		return nil, errors.New("PKCS#11 session is closed")
+	}
+
+	if err := s.store.ctx.SignInit(s.store.session, []*pkcs11.Mechanism{mechanism}, s.object); err != nil {
+		return nil, fmt.Errorf("failed to sign: %v", err)
+	}
+	sig, err := s.store.ctx.Sign(s.store.session, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign: %v", err)
+	}
+
+	if _, ok := s.public.(*rsa.PublicKey); ok {
+		return sig, nil
+	}
+
+	// PKCS#11 returns the raw r and s values, while x509 expects them ASN.1
+	// encoded.
+	if len(sig)%2 != 0 {
+		return nil, fmt.Errorf("invalid ECDSA signature length %d", len(sig))
+	}
+	half := len(sig) / 2
+	return asn1.Marshal(struct {
+		R, S *big.Int
+	}{
+		R: new(big.Int).SetBytes(sig[:half]),
+		S: new(big.Int).SetBytes(sig[half:]),
+	})
+}
@@ -0,0 +1,16 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build !cgo
+
+package ca
+
+import (
+	"errors"
+
+	"github.com/hernad/consul/agent/structs"
+)
+
+func newPKCS11KeyStore(_ *structs.ConsulCAPKCS11Config, _ bool) (consulKeyStore, error) {
+	return nil, errors.New("PKCS#11 tokens are only supported when Consul is built with cgo")
+}
@@ -0,0 +1,113 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build cgo
+
+package ca
+
+import (
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hernad/consul/agent/structs"
+)
+
+// testSoftHSMToken creates a SoftHSM token and returns the config to use it.
+// The test is skipped when SoftHSM is not installed.
+func testSoftHSMToken(t *testing.T) *structs.ConsulCAPKCS11Config {
+	lib := os.Getenv("SOFTHSM2_LIB")
+	if lib == "" {
+		for _, path := range []string{
+			"/usr/lib/softhsm/libsofthsm2.so",
+			"/usr/lib/x86_64-linux-gnu/softhsm/libsofthsm2.so",
+			"/usr/local/lib/softhsm/libsofthsm2.so",
+		} {
+			if _, err := os.Stat(path); err == nil {
+				lib = path
+				break
+			}
+		}
+	}
+	util, err := exec.LookPath("softhsm2-util")
+	if lib == "" || err != nil {
+		t.Skip("SoftHSM not found - install softhsm2 to run this test")
+	}
+
+	dir := t.TempDir()
+	conf := filepath.Join(dir, "softhsm2.conf")
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "tokens"), 0700))
+	require.NoError(t, os.WriteFile(conf, []byte(fmt.Sprintf("directories.tokendir = %s\n", filepath.Join(dir, "tokens"))), 0600))
+	t.Setenv("SOFTHSM2_CONF", conf)
+
+	out, err := exec.Command(util, "--init-token", "--free", "--label", "consul", "--pin", "1234", "--so-pin", "5678").CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	return &structs.ConsulCAPKCS11Config{
+		Lib:        lib,
+		TokenLabel: "consul",
+		PIN:        "1234",
+	}
+}
+
+func TestPKCS11KeyStore(t *testing.T) {
+	config := testSoftHSMToken(t)
+
+	keys, err := newPKCS11KeyStore(config, false)
+	require.NoError(t, err)
+	defer keys.Close()
+
+	for _, tc := range KeyTestCases {
+		tc := tc
+		t.Run(tc.Desc, func(t *testing.T) {
+			handle, err := keys.GenerateKey(tc.KeyType, tc.KeyBits)
+			require.NoError(t, err)
+
+			signer, err := keys.Signer(handle)
+			require.NoError(t, err)
+
+			// Self sign a certificate and check the signature with the public
+			// key read from the token.
+			template := &x509.Certificate{
+				SerialNumber:          big.NewInt(1),
+				Subject:               pkix.Name{CommonName: "test"},
+				NotBefore:             time.Now(),
+				NotAfter:              time.Now().Add(time.Hour),
+				IsCA:                  true,
+				BasicConstraintsValid: true,
+				KeyUsage:              x509.KeyUsageCertSign,
+			}
+			der, err := x509.CreateCertificate(rand.Reader, template, template, signer.Public(), signer)
+			require.NoError(t, err)
+			cert, err := x509.ParseCertificate(der)
+			require.NoError(t, err)
+			require.NoError(t, cert.CheckSignatureFrom(cert))
+		})
+	}
+
+	_, err = keys.Signer(pkcs11HandlePrefix + "00")
+	require.ErrorContains(t, err, "not found")
+
+	t.Run("ephemeral keys are destroyed with the session", func(t *testing.T) {
+		ephemeral, err := newPKCS11KeyStore(config, true)
+		require.NoError(t, err)
+
+		handle, err := ephemeral.GenerateKey("ec", 256)
+		require.NoError(t, err)
+		_, err = ephemeral.Signer(handle)
+		require.NoError(t, err)
+		require.NoError(t, ephemeral.Close())
+
+		_, err = keys.Signer(handle)
+		require.ErrorContains(t, err, "not found")
+	})
+}
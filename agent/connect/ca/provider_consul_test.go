@@ -4,8 +4,13 @@
 package ca
 
 import (
+	"crypto"
 	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"fmt"
+	"math/big"
+	"sync"
 	"testing"
 	"time"
 
@@ -255,6 +260,43 @@ func TestConsulCAProvider_SignLeaf(t *testing.T) {
 	}
 }
 
+func TestConsulCAProvider_SignRevocationList(t *testing.T) {
+	t.Parallel()
+
+	conf := testConsulCAConfig()
+	delegate := newMockDelegate(t, conf)
+
+	provider := TestConsulProvider(t, delegate)
+	require.NoError(t, provider.Configure(testProviderConfig(conf)))
+	rootPEM, err := provider.GenerateCAChain()
+	require.NoError(t, err)
+	root, err := connect.ParseCert(rootPEM)
+	require.NoError(t, err)
+
+	revokedAt := time.Now().UTC().Truncate(time.Second)
+	revoked := []pkix.RevokedCertificate{
+		{SerialNumber: big.NewInt(3), RevocationTime: revokedAt},
+		{SerialNumber: big.NewInt(7), RevocationTime: revokedAt},
+	}
+	crlPEM, err := provider.SignRevocationList(revoked, big.NewInt(42))
+	require.NoError(t, err)
+
+	block, _ := pem.Decode([]byte(crlPEM))
+	require.NotNil(t, block)
+	require.Equal(t, "X509 CRL", block.Type)
+	crl, err := x509.ParseRevocationList(block.Bytes)
+	require.NoError(t, err)
+
+	// The list is signed by the leaf signing cert and valid as long as it.
+	require.NoError(t, crl.CheckSignatureFrom(root))
+	require.Equal(t, root.SubjectKeyId, crl.AuthorityKeyId)
+	require.Equal(t, root.NotAfter, crl.NextUpdate)
+	require.Equal(t, big.NewInt(42), crl.Number)
+	require.Len(t, crl.RevokedCertificates, 2)
+	require.Equal(t, big.NewInt(3), crl.RevokedCertificates[0].SerialNumber)
+	require.Equal(t, big.NewInt(7), crl.RevokedCertificates[1].SerialNumber)
+}
+
 func TestConsulCAProvider_CrossSignCA(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
@@ -537,3 +579,137 @@ func TestConsulCAProvider_MigrateOldID(t *testing.T) {
 		})
 	}
 }
+
+// testKeyStore is an in-memory consulKeyStore used in place of a PKCS#11
+// token.
+type testKeyStore struct {
+	lock   sync.Mutex
+	keys   map[string]crypto.Signer
+	closed bool
+}
+
+func (s *testKeyStore) GenerateKey(keyType string, keyBits int) (string, error) {
+	signer, _, err := connect.GeneratePrivateKeyWithConfig(keyType, keyBits)
+	if err != nil {
+		return "", err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	handle := fmt.Sprintf("test:%d", len(s.keys))
+	s.keys[handle] = signer
+	return handle, nil
+}
+
+func (s *testKeyStore) Signer(handle string) (crypto.Signer, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	signer, ok := s.keys[handle]
+	if !ok {
+		return nil, fmt.Errorf("key %q not found", handle)
+	}
+	return signer, nil
+}
+
+func (s *testKeyStore) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.closed = true
+	return nil
+}
+
+func TestConsulCAProvider_PKCS11(t *testing.T) {
+	keys := &testKeyStore{keys: make(map[string]crypto.Signer)}
+	var opened []*structs.ConsulCAPKCS11Config
+	var ephemeral []bool
+	orig := newConsulKeyStore
+	newConsulKeyStore = func(config *structs.ConsulCAPKCS11Config, e bool) (consulKeyStore, error) {
+		opened = append(opened, config)
+		ephemeral = append(ephemeral, e)
+		return keys, nil
+	}
+	t.Cleanup(func() { newConsulKeyStore = orig })
+
+	pkcs11Config := map[string]interface{}{
+		"Lib":        "/usr/lib/softhsm/libsofthsm2.so",
+		"TokenLabel": "consul",
+		"PIN":        "1234",
+	}
+
+	conf1 := testConsulCAConfig()
+	conf1.Config["PKCS11"] = pkcs11Config
+	delegate1 := newMockDelegate(t, conf1)
+	provider1 := TestConsulProvider(t, delegate1)
+	require.NoError(t, provider1.Configure(testProviderConfig(conf1)))
+	require.Len(t, opened, 1)
+	require.Equal(t, "consul", opened[0].TokenLabel)
+	require.False(t, ephemeral[0])
+
+	_, err := provider1.GenerateCAChain()
+	require.NoError(t, err)
+
+	// Only the handle of the key is kept in the state.
+	state1, err := delegate1.ProviderState(provider1.id)
+	require.NoError(t, err)
+	require.Empty(t, state1.PrivateKey)
+	require.Contains(t, keys.keys, state1.PrivateKeyHandle)
+
+	conf2 := testConsulCAConfig()
+	conf2.CreateIndex = 10
+	conf2.Config["PKCS11"] = pkcs11Config
+	delegate2 := newMockDelegate(t, conf2)
+	provider2 := TestConsulProvider(t, delegate2)
+	cfg := testProviderConfig(conf2)
+	cfg.IsPrimary = false
+	cfg.Datacenter = "dc2"
+	require.NoError(t, provider2.Configure(cfg))
+
+	testSignIntermediateCrossDC(t, provider1, provider2)
+
+	state2, err := delegate2.ProviderState(provider2.id)
+	require.NoError(t, err)
+	require.Empty(t, state2.PrivateKey)
+	require.Contains(t, keys.keys, state2.PrivateKeyHandle)
+	require.NotEqual(t, state1.PrivateKeyHandle, state2.PrivateKeyHandle)
+
+	t.Run("requires the token to use the key", func(t *testing.T) {
+		provider := TestConsulProvider(t, delegate1)
+		provider.config = provider1.config
+		provider.id = provider1.id
+		provider.isPrimary = true
+		provider.spiffeID = provider1.spiffeID
+
+		raw, _ := connect.TestCSR(t, &connect.SpiffeIDService{
+			Host:       connect.TestClusterID + ".consul",
+			Namespace:  "default",
+			Datacenter: "dc1",
+			Service:    "foo",
+		})
+		csr, err := connect.ParseCSR(raw)
+		require.NoError(t, err)
+		_, err = provider.Sign(csr)
+		require.ErrorContains(t, err, "not configured to use one")
+	})
+
+	t.Run("dry run keys are ephemeral", func(t *testing.T) {
+		conf := testConsulCAConfig()
+		conf.Config["PKCS11"] = pkcs11Config
+		provider := TestConsulProvider(t, newMockDelegate(t, conf))
+		cfg := testProviderConfig(conf)
+		cfg.DryRun = true
+		require.NoError(t, provider.Configure(cfg))
+		require.True(t, ephemeral[len(ephemeral)-1])
+	})
+
+	t.Run("rejects a private key", func(t *testing.T) {
+		conf := testConsulCAConfig()
+		conf.Config["PKCS11"] = pkcs11Config
+		conf.Config["PrivateKey"] = "key"
+		conf.Config["RootCert"] = "cert"
+		provider := TestConsulProvider(t, newMockDelegate(t, conf))
+		require.ErrorContains(t, provider.Configure(testProviderConfig(conf)), "PrivateKey cannot be set")
+	})
+
+	require.NoError(t, provider1.Cleanup(false, nil))
+	require.True(t, keys.closed)
+}
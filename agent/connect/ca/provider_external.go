@@ -0,0 +1,568 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package ca
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/hernad/consul/agent/connect"
+	"github.com/hernad/consul/agent/structs"
+	"github.com/hernad/consul/api"
+	"github.com/hernad/consul/lib"
+	"github.com/hernad/consul/lib/decode"
+)
+
+const (
+	externalIssuerInfoPath     = "/api/v1/cfssl/info"
+	externalIssuerSignPath     = "/api/v1/cfssl/sign"
+	externalIssuerAuthSignPath = "/api/v1/cfssl/authsign"
+
+	externalIssuerTimeout = 30 * time.Second
+)
+
+// ExternalProvider is a CA provider backed by an external issuer exposing a
+// cfssl compatible signing API. The issuer's CA is the root and signs the
+// intermediates, while the intermediate private keys are created by Consul
+// and kept in the state store like the built-in provider's keys. Leaf
+// certificates are signed locally with the intermediate.
+type ExternalProvider struct {
+	Delegate ConsulProviderStateDelegate
+
+	config    *structs.ExternalCAProviderConfig
+	issuer    *externalIssuer
+	id        string
+	isPrimary bool
+	spiffeID  *connect.SpiffeIDSigning
+	logger    hclog.Logger
+
+	sync.Mutex
+}
+
+var _ Provider = (*ExternalProvider)(nil)
+var _ PrimaryUsesIntermediate = (*ExternalProvider)(nil)
+var _ RevocationListSigner = (*ExternalProvider)(nil)
+
+// NewExternalProvider returns a new ExternalProvider that is ready to be used.
+func NewExternalProvider(delegate ConsulProviderStateDelegate, logger hclog.Logger) *ExternalProvider {
+	return &ExternalProvider{Delegate: delegate, logger: logger}
+}
+
+// Configure sets up the provider using the given configuration.
+func (e *ExternalProvider) Configure(cfg ProviderConfig) error {
+	config, err := ParseExternalCAConfig(cfg.RawConfig)
+	if err != nil {
+		return err
+	}
+
+	issuer, err := newExternalIssuer(config)
+	if err != nil {
+		return err
+	}
+
+	e.config = config
+	e.issuer = issuer
+	e.isPrimary = cfg.IsPrimary
+	e.spiffeID = connect.SpiffeIDSigningForCluster(cfg.ClusterID)
+	e.id = hexStringHash(fmt.Sprintf("external,%s,%s,%s,%s,%d,%v", config.Address, config.Label,
+		config.Profile, config.PrivateKeyType, config.PrivateKeyBits, cfg.IsPrimary))
+
+	providerState, err := e.Delegate.ProviderState(e.id)
+	if err != nil {
+		return err
+	}
+	if providerState == nil {
+		args := &structs.CARequest{
+			Op:            structs.CAOpSetProviderState,
+			ProviderState: &structs.CAConsulProviderState{ID: e.id},
+		}
+		if _, err := e.Delegate.ApplyCARequest(args); err != nil {
+			return err
+		}
+	}
+
+	e.logger.Debug("external CA provider configured", "id", e.id, "address", config.Address, "is_primary", e.isPrimary)
+	return nil
+}
+
+// State implements Provider. The private keys and certificates are kept in the
+// same table as the built-in provider's state, so there is nothing to return.
+func (e *ExternalProvider) State() (map[string]string, error) {
+	return nil, nil
+}
+
+// GenerateCAChain returns the issuer's CA certificate, which is fetched the
+// first time and then kept in the state so the root only changes when the
+// provider configuration does.
+func (e *ExternalProvider) GenerateCAChain() (string, error) {
+	if !e.isPrimary {
+		return "", fmt.Errorf("provider is not the root certificate authority")
+	}
+
+	providerState, err := e.getState()
+	if err != nil {
+		return "", err
+	}
+	if providerState.RootCert != "" {
+		return providerState.RootCert, nil
+	}
+
+	root, err := e.issuer.root()
+	if err != nil {
+		return "", fmt.Errorf("error fetching the issuer's CA certificate: %w", err)
+	}
+	cert, err := connect.ParseCert(root)
+	if err != nil {
+		return "", fmt.Errorf("error parsing the issuer's CA certificate: %v", err)
+	}
+	if !cert.IsCA {
+		return "", fmt.Errorf("the issuer's certificate is not a CA certificate")
+	}
+
+	newState := *providerState
+	newState.RootCert = lib.EnsureTrailingNewline(root)
+	if err := e.setState(&newState); err != nil {
+		return "", err
+	}
+	return newState.RootCert, nil
+}
+
+// GenerateLeafSigningCert implements PrimaryUsesIntermediate. It creates a new
+// private key and has the issuer sign the intermediate for it.
+func (e *ExternalProvider) GenerateLeafSigningCert() (string, error) {
+	if !e.isPrimary {
+		return "", fmt.Errorf("provider is not the root certificate authority")
+	}
+
+	providerState, err := e.getState()
+	if err != nil {
+		return "", err
+	}
+	if providerState.RootCert == "" {
+		return "", ErrNotInitialized
+	}
+
+	signer, pk, err := connect.GeneratePrivateKeyWithConfig(e.config.PrivateKeyType, e.config.PrivateKeyBits)
+	if err != nil {
+		return "", err
+	}
+	csr, err := connect.CreateCACSR(e.spiffeID, signer)
+	if err != nil {
+		return "", err
+	}
+
+	intermediate, err := e.issuer.sign(csr, []string{e.spiffeID.URI().String()})
+	if err != nil {
+		return "", fmt.Errorf("error signing the intermediate: %w", err)
+	}
+	if err := validateSetIntermediate(intermediate, providerState.RootCert, e.spiffeID); err != nil {
+		return "", err
+	}
+	if err := validateIntermediateSignedByPrivateKey(intermediate, signer); err != nil {
+		return "", err
+	}
+
+	newState := *providerState
+	newState.PrivateKey = pk
+	newState.IntermediateCert = lib.EnsureTrailingNewline(intermediate)
+	if err := e.setState(&newState); err != nil {
+		return "", err
+	}
+	return newState.IntermediateCert, nil
+}
+
+// ActiveLeafSigningCert implements Provider.
+func (e *ExternalProvider) ActiveLeafSigningCert() (string, error) {
+	providerState, err := e.getState()
+	if err != nil {
+		return "", err
+	}
+	return providerState.IntermediateCert, nil
+}
+
+// Sign signs a leaf certificate with the active intermediate.
+func (e *ExternalProvider) Sign(csr *x509.CertificateRequest) (string, error) {
+	connect.HackSANExtensionForCSR(csr)
+
+	// Lock during the signing so we don't use the same index twice
+	// for different cert serial numbers.
+	e.Lock()
+	defer e.Unlock()
+
+	providerState, err := e.getState()
+	if err != nil {
+		return "", err
+	}
+	if providerState.PrivateKey == "" || providerState.IntermediateCert == "" {
+		return "", ErrNotInitialized
+	}
+
+	signer, err := connect.ParseSigner(providerState.PrivateKey)
+	if err != nil {
+		return "", err
+	}
+	caCert, err := connect.ParseCert(providerState.IntermediateCert)
+	if err != nil {
+		return "", fmt.Errorf("error parsing CA cert: %s", err)
+	}
+
+	nextSerial, err := incrementAndGetNextSerialNumber(e.Delegate)
+	if err != nil {
+		return "", fmt.Errorf("error computing next serial number: %v", err)
+	}
+
+	return signLeafCert(csr, caCert, signer, nextSerial, e.config.LeafCertTTL)
+}
+
+// SignRevocationList implements RevocationListSigner with the key of the
+// active intermediate.
+func (e *ExternalProvider) SignRevocationList(revoked []pkix.RevokedCertificate, number *big.Int) (string, error) {
+	providerState, err := e.getState()
+	if err != nil {
+		return "", err
+	}
+	if providerState.PrivateKey == "" || providerState.IntermediateCert == "" {
+		return "", ErrNotInitialized
+	}
+
+	signer, err := connect.ParseSigner(providerState.PrivateKey)
+	if err != nil {
+		return "", err
+	}
+	caCert, err := connect.ParseCert(providerState.IntermediateCert)
+	if err != nil {
+		return "", fmt.Errorf("error parsing CA cert: %s", err)
+	}
+	return signRevocationList(revoked, number, caCert, signer)
+}
+
+// SignIntermediate has the issuer sign the intermediate of a secondary
+// datacenter.
+func (e *ExternalProvider) SignIntermediate(csr *x509.CertificateRequest) (string, error) {
+	if err := validateSignIntermediate(csr, e.spiffeID); err != nil {
+		return "", err
+	}
+
+	var hosts []string
+	for _, uri := range csr.URIs {
+		hosts = append(hosts, uri.String())
+	}
+	csrPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csr.Raw})
+
+	cert, err := e.issuer.sign(string(csrPEM), hosts)
+	if err != nil {
+		return "", fmt.Errorf("error signing the intermediate: %w", err)
+	}
+	return lib.EnsureTrailingNewline(cert), nil
+}
+
+// CrossSignCA implements Provider. The issuer's CA cannot be asked to cross
+// sign another CA.
+func (e *ExternalProvider) CrossSignCA(*x509.Certificate) (string, error) {
+	return "", errors.New("the external CA provider does not support cross-signing")
+}
+
+// SupportsCrossSigning implements Provider.
+func (e *ExternalProvider) SupportsCrossSigning() (bool, error) {
+	return false, nil
+}
+
+// GenerateIntermediateCSR creates a private key and a CSR for the primary
+// datacenter to sign.
+func (e *ExternalProvider) GenerateIntermediateCSR() (string, string, error) {
+	if e.isPrimary {
+		return "", "", fmt.Errorf("provider is the root certificate authority, " +
+			"cannot generate an intermediate CSR")
+	}
+
+	providerState, err := e.getState()
+	if err != nil {
+		return "", "", err
+	}
+
+	signer, pk, err := connect.GeneratePrivateKeyWithConfig(e.config.PrivateKeyType, e.config.PrivateKeyBits)
+	if err != nil {
+		return "", "", err
+	}
+	csr, err := connect.CreateCACSR(e.spiffeID, signer)
+	if err != nil {
+		return "", "", err
+	}
+
+	newState := *providerState
+	newState.PrivateKey = pk
+	if err := e.setState(&newState); err != nil {
+		return "", "", err
+	}
+	return csr, "", nil
+}
+
+// SetIntermediate validates that the given intermediate is for the private key
+// created by GenerateIntermediateCSR and stores it.
+func (e *ExternalProvider) SetIntermediate(intermediatePEM, rootPEM, _ string) error {
+	if e.isPrimary {
+		return fmt.Errorf("cannot set an intermediate using another root in the primary datacenter")
+	}
+
+	providerState, err := e.getState()
+	if err != nil {
+		return err
+	}
+	if providerState.PrivateKey == "" {
+		return ErrNotInitialized
+	}
+
+	if err := validateSetIntermediate(intermediatePEM, rootPEM, e.spiffeID); err != nil {
+		return err
+	}
+	signer, err := connect.ParseSigner(providerState.PrivateKey)
+	if err != nil {
+		return err
+	}
+	if err := validateIntermediateSignedByPrivateKey(intermediatePEM, signer); err != nil {
+		return err
+	}
+
+	newState := *providerState
+	newState.IntermediateCert = intermediatePEM
+	newState.RootCert = rootPEM
+	return e.setState(&newState)
+}
+
+// Cleanup removes the state of this provider instance.
+func (e *ExternalProvider) Cleanup(_ bool, _ map[string]interface{}) error {
+	args := &structs.CARequest{
+		Op:            structs.CAOpDeleteProviderState,
+		ProviderState: &structs.CAConsulProviderState{ID: e.id},
+	}
+	_, err := e.Delegate.ApplyCARequest(args)
+	return err
+}
+
+// getState returns the current provider state from the state delegate, and
+// returns ErrNotInitialized if no entry is found.
+func (e *ExternalProvider) getState() (*structs.CAConsulProviderState, error) {
+	providerState, err := e.Delegate.ProviderState(e.id)
+	if err != nil {
+		return nil, err
+	}
+	if providerState == nil {
+		return nil, ErrNotInitialized
+	}
+	return providerState, nil
+}
+
+func (e *ExternalProvider) setState(state *structs.CAConsulProviderState) error {
+	args := &structs.CARequest{
+		Op:            structs.CAOpSetProviderState,
+		ProviderState: state,
+	}
+	_, err := e.Delegate.ApplyCARequest(args)
+	return err
+}
+
+func ParseExternalCAConfig(raw map[string]interface{}) (*structs.ExternalCAProviderConfig, error) {
+	config := structs.ExternalCAProviderConfig{
+		CommonCAProviderConfig: defaultCommonConfig(),
+	}
+
+	decodeConf := &mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			structs.ParseDurationFunc(),
+			decode.HookTranslateKeys,
+		),
+		Result:           &config,
+		WeaklyTypedInput: true,
+	}
+
+	decoder, err := mapstructure.NewDecoder(decodeConf)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := decoder.Decode(raw); err != nil {
+		return nil, fmt.Errorf("error decoding config: %s", err)
+	}
+
+	if config.Address == "" {
+		return nil, fmt.Errorf("must provide the address of the issuer")
+	}
+	config.Address = strings.TrimSuffix(config.Address, "/")
+
+	if config.AuthKey != "" {
+		if _, err := hex.DecodeString(config.AuthKey); err != nil {
+			return nil, fmt.Errorf("AuthKey must be hex encoded: %v", err)
+		}
+	}
+
+	if err := config.CommonCAProviderConfig.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := validateFederatedTrustDomains(config.FederatedTrustDomains); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// externalIssuer is a client for a cfssl compatible signing API.
+type externalIssuer struct {
+	address string
+	label   string
+	profile string
+	authKey []byte
+	client  *http.Client
+}
+
+func newExternalIssuer(config *structs.ExternalCAProviderConfig) (*externalIssuer, error) {
+	authKey, err := hex.DecodeString(config.AuthKey)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := api.SetupTLSConfig(&api.TLSConfig{
+		Address:            config.TLSServerName,
+		CAFile:             config.CAFile,
+		CAPath:             config.CAPath,
+		CertFile:           config.CertFile,
+		KeyFile:            config.KeyFile,
+		InsecureSkipVerify: config.TLSSkipVerify,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error configuring TLS for the issuer: %v", err)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &externalIssuer{
+		address: config.Address,
+		label:   config.Label,
+		profile: config.Profile,
+		authKey: authKey,
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   externalIssuerTimeout,
+		},
+	}, nil
+}
+
+type externalIssuerInfoRequest struct {
+	Label   string `json:"label,omitempty"`
+	Profile string `json:"profile,omitempty"`
+}
+
+type externalIssuerSignRequest struct {
+	Hosts   []string `json:"hosts,omitempty"`
+	Request string   `json:"certificate_request"`
+	Label   string   `json:"label,omitempty"`
+	Profile string   `json:"profile,omitempty"`
+}
+
+type externalIssuerAuthRequest struct {
+	Token   []byte `json:"token"`
+	Request []byte `json:"request"`
+}
+
+type externalIssuerResponse struct {
+	Success bool            `json:"success"`
+	Result  json.RawMessage `json:"result"`
+	Errors  []struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+type externalIssuerCertificate struct {
+	Certificate string `json:"certificate"`
+}
+
+// root returns the PEM encoded CA certificate of the issuer.
+func (i *externalIssuer) root() (string, error) {
+	body, err := json.Marshal(externalIssuerInfoRequest{Label: i.label, Profile: i.profile})
+	if err != nil {
+		return "", err
+	}
+	return i.certificate(externalIssuerInfoPath, body)
+}
+
+// sign has the issuer sign the given PEM encoded CSR and returns the PEM
+// encoded certificate.
+func (i *externalIssuer) sign(csr string, hosts []string) (string, error) {
+	body, err := json.Marshal(externalIssuerSignRequest{
+		Hosts:   hosts,
+		Request: csr,
+		Label:   i.label,
+		Profile: i.profile,
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(i.authKey) == 0 {
+		return i.certificate(externalIssuerSignPath, body)
+	}
+
+	mac := hmac.New(sha256.New, i.authKey)
+	mac.Write(body)
+	body, err = json.Marshal(externalIssuerAuthRequest{Token: mac.Sum(nil), Request: body})
+	if err != nil {
+		return "", err
+	}
+	return i.certificate(externalIssuerAuthSignPath, body)
+}
+
+func (i *externalIssuer) certificate(path string, body []byte) (string, error) {
+	resp, err := i.client.Post(i.address+path, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return "", ErrRateLimited
+	}
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	var out externalIssuerResponse
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return "", fmt.Errorf("unexpected response from the issuer (HTTP %d): %s", resp.StatusCode, strings.TrimSpace(string(raw)))
+	}
+	if !out.Success || resp.StatusCode != http.StatusOK {
+		msgs := make([]string, 0, len(out.Errors))
+		for _, e := range out.Errors {
+			msgs = append(msgs, e.Message)
+		}
+		return "", fmt.Errorf("the issuer returned an error (HTTP %d): %s", resp.StatusCode, strings.Join(msgs, "; "))
+	}
+
+	var result externalIssuerCertificate
+	if err := json.Unmarshal(out.Result, &result); err != nil {
+		return "", fmt.Errorf("error decoding the issuer's response: %v", err)
+	}
+	if result.Certificate == "" {
+		return "", errors.New("the issuer did not return a certificate")
+	}
+	return result.Certificate, nil
+}
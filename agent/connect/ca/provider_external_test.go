@@ -0,0 +1,311 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package ca
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hernad/consul/agent/connect"
+	"github.com/hernad/consul/agent/structs"
+	"github.com/hernad/consul/sdk/testutil"
+)
+
+// testExternalIssuer is a stand-in for a cfssl compatible issuer that signs
+// CA certificates with a test root.
+type testExternalIssuer struct {
+	*httptest.Server
+
+	root    *structs.CARoot
+	authKey []byte
+	signed  int32
+}
+
+func newTestExternalIssuer(t *testing.T, authKey []byte) *testExternalIssuer {
+	issuer := &testExternalIssuer{
+		root:    connect.TestCA(t, nil),
+		authKey: authKey,
+	}
+	issuer.Server = httptest.NewServer(http.HandlerFunc(issuer.handle))
+	t.Cleanup(issuer.Close)
+	return issuer
+}
+
+func (i *testExternalIssuer) respond(w http.ResponseWriter, status int, certificate, message string) {
+	resp := map[string]interface{}{
+		"success":  status == http.StatusOK,
+		"result":   map[string]string{"certificate": certificate},
+		"errors":   []map[string]interface{}{},
+		"messages": []string{},
+	}
+	if message != "" {
+		resp["errors"] = []map[string]interface{}{{"code": status, "message": message}}
+	}
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (i *testExternalIssuer) handle(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case externalIssuerInfoPath:
+		i.respond(w, http.StatusOK, i.root.RootCert, "")
+		return
+	case externalIssuerSignPath:
+		if len(i.authKey) > 0 {
+			i.respond(w, http.StatusUnauthorized, "", "authentication required")
+			return
+		}
+		var req externalIssuerSignRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			i.respond(w, http.StatusBadRequest, "", err.Error())
+			return
+		}
+		i.sign(w, req)
+	case externalIssuerAuthSignPath:
+		var auth externalIssuerAuthRequest
+		if err := json.NewDecoder(r.Body).Decode(&auth); err != nil {
+			i.respond(w, http.StatusBadRequest, "", err.Error())
+			return
+		}
+		mac := hmac.New(sha256.New, i.authKey)
+		mac.Write(auth.Request)
+		if !hmac.Equal(mac.Sum(nil), auth.Token) {
+			i.respond(w, http.StatusUnauthorized, "", "invalid token")
+			return
+		}
+		var req externalIssuerSignRequest
+		if err := json.Unmarshal(auth.Request, &req); err != nil {
+			i.respond(w, http.StatusBadRequest, "", err.Error())
+			return
+		}
+		i.sign(w, req)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (i *testExternalIssuer) sign(w http.ResponseWriter, req externalIssuerSignRequest) {
+	block, _ := pem.Decode([]byte(req.Request))
+	if block == nil {
+		i.respond(w, http.StatusBadRequest, "", "invalid CSR")
+		return
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		i.respond(w, http.StatusBadRequest, "", err.Error())
+		return
+	}
+
+	rootCert, err := connect.ParseCert(i.root.RootCert)
+	if err != nil {
+		i.respond(w, http.StatusInternalServerError, "", err.Error())
+		return
+	}
+	rootKey, err := connect.ParseSigner(i.root.SigningKey)
+	if err != nil {
+		i.respond(w, http.StatusInternalServerError, "", err.Error())
+		return
+	}
+
+	// Like cfssl, the hosts of the request become the SANs of the
+	// certificate.
+	var uris []*url.URL
+	for _, host := range req.Hosts {
+		uri, err := url.Parse(host)
+		if err != nil {
+			i.respond(w, http.StatusBadRequest, "", err.Error())
+			return
+		}
+		uris = append(uris, uri)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(int64(atomic.AddInt32(&i.signed, 1)) + 100),
+		Subject:               csr.Subject,
+		URIs:                  uris,
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, rootCert, csr.PublicKey, rootKey)
+	if err != nil {
+		i.respond(w, http.StatusInternalServerError, "", err.Error())
+		return
+	}
+	i.respond(w, http.StatusOK, string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})), "")
+}
+
+func testExternalCAConfig(issuer *testExternalIssuer) *structs.CAConfiguration {
+	conf := &structs.CAConfiguration{
+		ClusterID: connect.TestClusterID,
+		Provider:  structs.ExternalCAProvider,
+		Config: map[string]interface{}{
+			"Address":     issuer.URL,
+			"LeafCertTTL": "1h",
+		},
+	}
+	if len(issuer.authKey) > 0 {
+		conf.Config["auth_key"] = hex.EncodeToString(issuer.authKey)
+	}
+	return conf
+}
+
+func TestExternalCAProvider(t *testing.T) {
+	t.Parallel()
+
+	run := func(t *testing.T, authKey []byte) {
+		issuer := newTestExternalIssuer(t, authKey)
+
+		conf1 := testExternalCAConfig(issuer)
+		delegate1 := newMockDelegate(t, conf1)
+		provider1 := NewExternalProvider(delegate1, testutil.Logger(t))
+		require.NoError(t, provider1.Configure(testProviderConfig(conf1)))
+
+		root, err := provider1.GenerateCAChain()
+		require.NoError(t, err)
+		require.Equal(t, issuer.root.RootCert, root)
+
+		intermediate, err := provider1.GenerateLeafSigningCert()
+		require.NoError(t, err)
+		active, err := provider1.ActiveLeafSigningCert()
+		require.NoError(t, err)
+		require.Equal(t, intermediate, active)
+
+		// Leaf certificates are signed locally and chain to the issuer's root.
+		spiffeService := &connect.SpiffeIDService{
+			Host:       connect.TestClusterID + ".consul",
+			Namespace:  "default",
+			Datacenter: "dc1",
+			Service:    "foo",
+		}
+		raw, _ := connect.TestCSR(t, spiffeService)
+		csr, err := connect.ParseCSR(raw)
+		require.NoError(t, err)
+
+		leafPEM, err := provider1.Sign(csr)
+		require.NoError(t, err)
+		leaf, err := connect.ParseCert(leafPEM)
+		require.NoError(t, err)
+		require.Equal(t, spiffeService.URI(), leaf.URIs[0])
+
+		intermediatePool := x509.NewCertPool()
+		intermediatePool.AppendCertsFromPEM([]byte(intermediate))
+		rootPool := x509.NewCertPool()
+		rootPool.AppendCertsFromPEM([]byte(root))
+		_, err = leaf.Verify(x509.VerifyOptions{
+			Intermediates: intermediatePool,
+			Roots:         rootPool,
+		})
+		require.NoError(t, err)
+
+		// Revocation lists are signed with the intermediate.
+		crlPEM, err := provider1.SignRevocationList([]pkix.RevokedCertificate{
+			{SerialNumber: leaf.SerialNumber, RevocationTime: time.Now().UTC()},
+		}, big.NewInt(1))
+		require.NoError(t, err)
+		block, _ := pem.Decode([]byte(crlPEM))
+		require.NotNil(t, block)
+		crl, err := x509.ParseRevocationList(block.Bytes)
+		require.NoError(t, err)
+		intermediateCert, err := connect.ParseCert(intermediate)
+		require.NoError(t, err)
+		require.NoError(t, crl.CheckSignatureFrom(intermediateCert))
+		require.Equal(t, intermediateCert.NotAfter, crl.NextUpdate)
+		require.Len(t, crl.RevokedCertificates, 1)
+		require.Equal(t, leaf.SerialNumber, crl.RevokedCertificates[0].SerialNumber)
+
+		// The intermediate of a secondary datacenter is signed by the issuer
+		// too.
+		conf2 := testExternalCAConfig(issuer)
+		conf2.CreateIndex = 10
+		delegate2 := newMockDelegate(t, conf2)
+		provider2 := NewExternalProvider(delegate2, testutil.Logger(t))
+		cfg := testProviderConfig(conf2)
+		cfg.IsPrimary = false
+		cfg.Datacenter = "dc2"
+		require.NoError(t, provider2.Configure(cfg))
+
+		testSignIntermediateCrossDC(t, provider1, provider2)
+
+		// The private keys are kept in the state store.
+		state, err := delegate1.ProviderState(provider1.id)
+		require.NoError(t, err)
+		require.NotEmpty(t, state.PrivateKey)
+
+		require.NoError(t, provider1.Cleanup(false, nil))
+		state, err = delegate1.ProviderState(provider1.id)
+		require.NoError(t, err)
+		require.Nil(t, state)
+	}
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		run(t, nil)
+	})
+
+	t.Run("authenticated", func(t *testing.T) {
+		run(t, []byte("0123456789abcdef"))
+	})
+
+	t.Run("issuer error", func(t *testing.T) {
+		issuer := newTestExternalIssuer(t, []byte("0123456789abcdef"))
+		conf := testExternalCAConfig(issuer)
+		conf.Config["auth_key"] = hex.EncodeToString([]byte("wrong"))
+		provider := NewExternalProvider(newMockDelegate(t, conf), testutil.Logger(t))
+		require.NoError(t, provider.Configure(testProviderConfig(conf)))
+
+		_, err := provider.GenerateCAChain()
+		require.NoError(t, err)
+		_, err = provider.GenerateLeafSigningCert()
+		require.ErrorContains(t, err, "invalid token")
+	})
+
+	t.Run("cross signing", func(t *testing.T) {
+		issuer := newTestExternalIssuer(t, nil)
+		conf := testExternalCAConfig(issuer)
+		provider := NewExternalProvider(newMockDelegate(t, conf), testutil.Logger(t))
+		require.NoError(t, provider.Configure(testProviderConfig(conf)))
+
+		supported, err := provider.SupportsCrossSigning()
+		require.NoError(t, err)
+		require.False(t, supported)
+	})
+}
+
+func TestParseExternalCAConfig(t *testing.T) {
+	_, err := ParseExternalCAConfig(map[string]interface{}{})
+	require.ErrorContains(t, err, "must provide the address of the issuer")
+
+	_, err = ParseExternalCAConfig(map[string]interface{}{
+		"Address": "http://127.0.0.1:8888",
+		"AuthKey": "not hex",
+	})
+	require.ErrorContains(t, err, "AuthKey must be hex encoded")
+
+	config, err := ParseExternalCAConfig(map[string]interface{}{
+		"address":  "http://127.0.0.1:8888/",
+		"profile":  "intermediate",
+		"auth_key": "00ff",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "http://127.0.0.1:8888", config.Address)
+	require.Equal(t, "intermediate", config.Profile)
+	require.Equal(t, "00ff", config.AuthKey)
+}
@@ -28,6 +28,13 @@ func TestStructs_CAConfiguration_MsgpackEncodeDecode(t *testing.T) {
 		"CSRMaxConcurrent": int64(55),
 		"PrivateKeyType":   "rsa",
 		"PrivateKeyBits":   int64(4096),
+		"FederatedTrustDomains": []interface{}{
+			map[string]interface{}{
+				"Name":              "example.org",
+				"SpiffeIDTemplates": []interface{}{"/ns/{namespace}/sa/{service}"},
+				"BundleEndpointURL": "https://example.org/bundle",
+			},
+		},
 	}
 	expectCommonBase := &structs.CommonCAProviderConfig{
 		LeafCertTTL:         30 * time.Hour,
@@ -38,6 +45,13 @@ func TestStructs_CAConfiguration_MsgpackEncodeDecode(t *testing.T) {
 		PrivateKeyType:      "rsa",
 		PrivateKeyBits:      4096,
 		RootCertTTL:         10 * 24 * 365 * time.Hour,
+		FederatedTrustDomains: []structs.FederatedTrustDomain{
+			{
+				Name:              "example.org",
+				SpiffeIDTemplates: []string{"/ns/{namespace}/sa/{service}"},
+				BundleEndpointURL: "https://example.org/bundle",
+			},
+		},
 	}
 
 	cases := map[string]testcase{
@@ -938,6 +938,10 @@ func ParseVaultCAConfig(raw map[string]interface{}) (*structs.VaultCAProviderCon
 		return nil, err
 	}
 
+	if err := validateFederatedTrustDomains(config.FederatedTrustDomains); err != nil {
+		return nil, err
+	}
+
 	return &config, nil
 }
 
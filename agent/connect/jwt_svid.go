@@ -0,0 +1,115 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package connect
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+
+	"github.com/hernad/consul/agent/structs"
+)
+
+const (
+	// DefaultJWTSVIDTTL is the lifetime of a JWT-SVID when none is requested.
+	DefaultJWTSVIDTTL = 5 * time.Minute
+
+	// MaxJWTSVIDTTL is the maximum lifetime of a JWT-SVID. JWT-SVIDs are bearer
+	// tokens that cannot be revoked so they must be short-lived.
+	MaxJWTSVIDTTL = time.Hour
+
+	// jwtSVIDAlgorithm is the signature algorithm of the JWT-SVIDs.
+	jwtSVIDAlgorithm = jose.ES256
+)
+
+// JWTSVIDIssuer returns the "iss" claim of the JWT-SVIDs issued by Consul in
+// the given trust domain.
+func JWTSVIDIssuer(trustDomain string) string {
+	return "spiffe://" + trustDomain
+}
+
+// GenerateJWTSigningKey generates a new key to sign JWT-SVIDs. The returned key
+// is not active.
+func GenerateJWTSigningKey() (*structs.CAJWTSigningKey, error) {
+	signer, privatePEM, err := GeneratePrivateKeyWithConfig("ec", 256)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+	keyID, err := KeyId(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	return &structs.CAJWTSigningKey{
+		ID:         base64.RawURLEncoding.EncodeToString(keyID),
+		PublicKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+		PrivateKey: privatePEM,
+		CreatedAt:  time.Now().UTC(),
+	}, nil
+}
+
+// SignJWTSVID returns a JWT-SVID for the given service identity signed with
+// key. It is valid from now until now+ttl.
+func SignJWTSVID(key *structs.CAJWTSigningKey, trustDomain string, id *SpiffeIDService, audience []string, now time.Time, ttl time.Duration) (string, error) {
+	if key.PrivateKey == "" {
+		return "", fmt.Errorf("JWT signing key %s has no private key", key.ID)
+	}
+	privateKey, err := ParseSigner(key.PrivateKey)
+	if err != nil {
+		return "", fmt.Errorf("invalid JWT signing key %s: %w", key.ID, err)
+	}
+
+	signer, err := jose.NewSigner(jose.SigningKey{
+		Algorithm: jwtSVIDAlgorithm,
+		Key: jose.JSONWebKey{
+			Key:       privateKey,
+			KeyID:     key.ID,
+			Algorithm: string(jwtSVIDAlgorithm),
+		},
+	}, (&jose.SignerOptions{}).WithType("JWT"))
+	if err != nil {
+		return "", err
+	}
+
+	claims := jwt.Claims{
+		Issuer:   JWTSVIDIssuer(trustDomain),
+		Subject:  id.URI().String(),
+		Audience: jwt.Audience(audience),
+		IssuedAt: jwt.NewNumericDate(now),
+		Expiry:   jwt.NewNumericDate(now.Add(ttl)),
+	}
+	return jwt.Signed(signer).Claims(claims).CompactSerialize()
+}
+
+// JWTSVIDKeySet returns the JSON Web Key Set to verify the JWT-SVIDs signed
+// with the given keys.
+func JWTSVIDKeySet(keys []*structs.CAJWTSigningKey) (*jose.JSONWebKeySet, error) {
+	set := &jose.JSONWebKeySet{Keys: make([]jose.JSONWebKey, 0, len(keys))}
+	for _, key := range keys {
+		block, _ := pem.Decode([]byte(key.PublicKey))
+		if block == nil {
+			return nil, fmt.Errorf("JWT signing key %s has no PEM encoded public key", key.ID)
+		}
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWT signing key %s: %w", key.ID, err)
+		}
+		set.Keys = append(set.Keys, jose.JSONWebKey{
+			Key:       pub,
+			KeyID:     key.ID,
+			Algorithm: string(jwtSVIDAlgorithm),
+			Use:       "sig",
+		})
+	}
+	return set, nil
+}
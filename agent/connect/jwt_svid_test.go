@@ -0,0 +1,71 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package connect
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+
+	"github.com/hernad/consul/agent/structs"
+)
+
+func TestSignJWTSVID(t *testing.T) {
+	key, err := GenerateJWTSigningKey()
+	require.NoError(t, err)
+	other, err := GenerateJWTSigningKey()
+	require.NoError(t, err)
+	require.NotEqual(t, key.ID, other.ID)
+
+	id := &SpiffeIDService{
+		Host:       "11111111-2222-3333-4444-555555555555.consul",
+		Namespace:  "default",
+		Datacenter: "dc1",
+		Service:    "web",
+	}
+	now := time.Now()
+	token, err := SignJWTSVID(key, id.Host, id, []string{"api"}, now, DefaultJWTSVIDTTL)
+	require.NoError(t, err)
+
+	// The key set survives a JSON round trip, like it does when served.
+	set, err := JWTSVIDKeySet([]*structs.CAJWTSigningKey{other, key})
+	require.NoError(t, err)
+	data, err := json.Marshal(set)
+	require.NoError(t, err)
+	var decoded jose.JSONWebKeySet
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Len(t, decoded.Keys, 2)
+
+	parsed, err := jwt.ParseSigned(token)
+	require.NoError(t, err)
+	require.Len(t, parsed.Headers, 1)
+	require.Equal(t, key.ID, parsed.Headers[0].KeyID)
+	require.Equal(t, "ES256", parsed.Headers[0].Algorithm)
+
+	keys := decoded.Key(key.ID)
+	require.Len(t, keys, 1)
+
+	var claims jwt.Claims
+	require.NoError(t, parsed.Claims(keys[0].Key, &claims))
+	require.NoError(t, claims.Validate(jwt.Expected{
+		Issuer:   "spiffe://11111111-2222-3333-4444-555555555555.consul",
+		Subject:  "spiffe://11111111-2222-3333-4444-555555555555.consul/ns/default/dc/dc1/svc/web",
+		Audience: jwt.Audience{"api"},
+		Time:     now.Add(time.Minute),
+	}))
+	require.Error(t, claims.Validate(jwt.Expected{Time: now.Add(DefaultJWTSVIDTTL + time.Minute)}))
+
+	// The token is not valid with another key.
+	otherKeys := decoded.Key(other.ID)
+	require.Len(t, otherKeys, 1)
+	require.Error(t, parsed.Claims(otherKeys[0].Key, &claims))
+
+	// Keys without their private part cannot sign.
+	_, err = SignJWTSVID(&structs.CAJWTSigningKey{ID: "pub", PublicKey: key.PublicKey}, id.Host, id, []string{"api"}, now, DefaultJWTSVIDTTL)
+	require.ErrorContains(t, err, "has no private key")
+}
@@ -0,0 +1,108 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package connect
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	"gopkg.in/square/go-jose.v2"
+)
+
+// spiffeBundleX509Use is the use of the keys of a SPIFFE bundle that are
+// X.509 authorities.
+const spiffeBundleX509Use = "x509-svid"
+
+// SpiffeBundle is a trust bundle in the format served by SPIFFE bundle
+// endpoints: a JWK set whose X.509 authorities are the keys with the
+// "x509-svid" use. Keys with other uses, like JWT-SVID keys, are ignored.
+type SpiffeBundle struct {
+	// RootPEMs are the PEM encoded X.509 authorities of the trust domain.
+	RootPEMs []string
+
+	// Sequence is the spiffe_sequence of the bundle, which the issuer
+	// increments every time the bundle changes.
+	Sequence uint64
+
+	// RefreshHint is how often the issuer asks for the bundle to be fetched
+	// again. It is zero if the bundle has no hint.
+	RefreshHint time.Duration
+}
+
+type spiffeBundleJSON struct {
+	Keys        []json.RawMessage `json:"keys"`
+	Sequence    uint64            `json:"spiffe_sequence,omitempty"`
+	RefreshHint int64             `json:"spiffe_refresh_hint,omitempty"`
+}
+
+// MarshalJSON encodes the bundle in the SPIFFE bundle format.
+func (b *SpiffeBundle) MarshalJSON() ([]byte, error) {
+	out := spiffeBundleJSON{
+		Keys:        make([]json.RawMessage, 0, len(b.RootPEMs)),
+		Sequence:    b.Sequence,
+		RefreshHint: int64(b.RefreshHint / time.Second),
+	}
+	for _, rootPEM := range b.RootPEMs {
+		cert, err := ParseCert(rootPEM)
+		if err != nil {
+			return nil, err
+		}
+		key := jose.JSONWebKey{
+			Key:          cert.PublicKey,
+			Certificates: []*x509.Certificate{cert},
+			Use:          spiffeBundleX509Use,
+		}
+		raw, err := key.MarshalJSON()
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode root certificate: %w", err)
+		}
+		out.Keys = append(out.Keys, raw)
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON decodes a bundle in the SPIFFE bundle format.
+func (b *SpiffeBundle) UnmarshalJSON(data []byte) error {
+	var in spiffeBundleJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+
+	bundle := SpiffeBundle{
+		Sequence:    in.Sequence,
+		RefreshHint: time.Duration(in.RefreshHint) * time.Second,
+	}
+	for i, raw := range in.Keys {
+		var use struct {
+			Use string `json:"use"`
+		}
+		if err := json.Unmarshal(raw, &use); err != nil {
+			return fmt.Errorf("invalid key %d: %w", i, err)
+		}
+		if use.Use != spiffeBundleX509Use {
+			continue
+		}
+
+		var key jose.JSONWebKey
+		if err := key.UnmarshalJSON(raw); err != nil {
+			return fmt.Errorf("invalid key %d: %w", i, err)
+		}
+		if len(key.Certificates) != 1 {
+			return fmt.Errorf("invalid key %d: X.509 authorities must have exactly one certificate, got %d", i, len(key.Certificates))
+		}
+		bundle.RootPEMs = append(bundle.RootPEMs, string(pem.EncodeToMemory(&pem.Block{
+			Type:  "CERTIFICATE",
+			Bytes: key.Certificates[0].Raw,
+		})))
+	}
+	if len(bundle.RootPEMs) == 0 {
+		return fmt.Errorf("bundle has no X.509 authorities")
+	}
+
+	*b = bundle
+	return nil
+}
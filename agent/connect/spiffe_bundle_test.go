@@ -0,0 +1,78 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package connect
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpiffeBundle_RoundTrip(t *testing.T) {
+	ca1 := TestCA(t, nil)
+	ca2 := TestCA(t, nil)
+
+	bundle := &SpiffeBundle{
+		RootPEMs:    []string{ca1.RootCert, ca2.RootCert},
+		Sequence:    42,
+		RefreshHint: 5 * time.Minute,
+	}
+	data, err := json.Marshal(bundle)
+	require.NoError(t, err)
+
+	var raw struct {
+		Keys []struct {
+			Use string   `json:"use"`
+			X5c []string `json:"x5c"`
+		} `json:"keys"`
+		Sequence    uint64 `json:"spiffe_sequence"`
+		RefreshHint int64  `json:"spiffe_refresh_hint"`
+	}
+	require.NoError(t, json.Unmarshal(data, &raw))
+	require.Len(t, raw.Keys, 2)
+	require.Equal(t, "x509-svid", raw.Keys[0].Use)
+	require.Len(t, raw.Keys[0].X5c, 1)
+	require.Equal(t, uint64(42), raw.Sequence)
+	require.Equal(t, int64(300), raw.RefreshHint)
+
+	var decoded SpiffeBundle
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, uint64(42), decoded.Sequence)
+	require.Equal(t, 5*time.Minute, decoded.RefreshHint)
+	require.Len(t, decoded.RootPEMs, 2)
+	for i, pem := range decoded.RootPEMs {
+		require.Equal(t, strings.TrimSpace(bundle.RootPEMs[i]), strings.TrimSpace(pem))
+	}
+}
+
+func TestSpiffeBundle_UnmarshalJSON(t *testing.T) {
+	ca := TestCA(t, nil)
+	data, err := json.Marshal(&SpiffeBundle{RootPEMs: []string{ca.RootCert}})
+	require.NoError(t, err)
+
+	// Keys with other uses, like JWT-SVID keys, are ignored.
+	var raw map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &raw))
+	keys := raw["keys"].([]interface{})
+	raw["keys"] = append(keys, map[string]interface{}{
+		"use": "jwt-svid",
+		"kty": "EC",
+		"kid": "jwt",
+		"crv": "P-256",
+		"x":   "fC7Yn6c2Ysy3Mpf1Dn2QgCZvm_1iefe4ICVDnJ2D44U",
+		"y":   "ooh9Ivdmt5tK0HBiR6nx8M6dM8Hj3iLGn2zB-sUp-8w",
+	})
+	data, err = json.Marshal(raw)
+	require.NoError(t, err)
+
+	var bundle SpiffeBundle
+	require.NoError(t, json.Unmarshal(data, &bundle))
+	require.Len(t, bundle.RootPEMs, 1)
+
+	err = json.Unmarshal([]byte(`{"keys":[]}`), &bundle)
+	require.ErrorContains(t, err, "no X.509 authorities")
+}
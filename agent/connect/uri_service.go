@@ -17,6 +17,11 @@ type SpiffeIDService struct {
 	Namespace  string
 	Datacenter string
 	Service    string
+
+	// FederatedTrustDomain is set to Host when the ID was matched by the
+	// SpiffeIDTemplate of a federated trust domain rather than issued by
+	// Consul. Such an identity is not the local service of the same name.
+	FederatedTrustDomain string
 }
 
 func (id SpiffeIDService) NamespaceOrDefault() string {
@@ -0,0 +1,304 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package connect
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/hernad/consul/agent/structs"
+)
+
+// The placeholders that can be used in a SpiffeIDTemplate. Each one matches a
+// single path segment.
+const (
+	spiffeIDTemplateService    = "service"
+	spiffeIDTemplateNamespace  = "namespace"
+	spiffeIDTemplatePartition  = "partition"
+	spiffeIDTemplateDatacenter = "datacenter"
+)
+
+var spiffeIDTemplatePlaceholderRegexp = regexp.MustCompile(`\{([^{}]*)\}`)
+
+// SpiffeIDTemplate maps the SPIFFE IDs of a trust domain that is not managed
+// by Consul, such as one managed by SPIRE, to service identities. The path
+// of the SPIFFE IDs is described by a template like
+// "/ns/{namespace}/sa/{service}" where the {service}, {namespace},
+// {partition} and {datacenter} placeholders each match one path segment.
+// Only {service} is required; the namespace and partition default to
+// "default" when they are not part of the template.
+type SpiffeIDTemplate struct {
+	TrustDomain string
+	Path        string
+
+	// literals has one more element than placeholders, the path is
+	// literals[0] + placeholders[0] + literals[1] + ...
+	literals     []string
+	placeholders []string
+	re           *regexp.Regexp
+}
+
+// ParseSpiffeIDTemplate parses the template of the SPIFFE IDs of the given
+// trust domain.
+func ParseSpiffeIDTemplate(trustDomain, path string) (*SpiffeIDTemplate, error) {
+	if trustDomain == "" {
+		return nil, fmt.Errorf("trust domain is required")
+	}
+	if strings.ContainsAny(trustDomain, "/:") {
+		return nil, fmt.Errorf("trust domain %q must be a host name", trustDomain)
+	}
+	if !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("SPIFFE ID template %q must start with a /", path)
+	}
+
+	t := &SpiffeIDTemplate{
+		TrustDomain: trustDomain,
+		Path:        path,
+	}
+
+	var (
+		pattern strings.Builder
+		seen    = make(map[string]bool)
+		last    int
+	)
+	pattern.WriteString("^")
+	for _, loc := range spiffeIDTemplatePlaceholderRegexp.FindAllStringSubmatchIndex(path, -1) {
+		literal := path[last:loc[0]]
+		name := path[loc[2]:loc[3]]
+		last = loc[1]
+
+		switch name {
+		case spiffeIDTemplateService, spiffeIDTemplateNamespace,
+			spiffeIDTemplatePartition, spiffeIDTemplateDatacenter:
+		default:
+			return nil, fmt.Errorf("SPIFFE ID template %q has unknown placeholder {%s}", path, name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("SPIFFE ID template %q has placeholder {%s} more than once", path, name)
+		}
+		seen[name] = true
+
+		if strings.ContainsAny(literal, "{}") {
+			return nil, fmt.Errorf("SPIFFE ID template %q has an invalid placeholder", path)
+		}
+		t.literals = append(t.literals, literal)
+		t.placeholders = append(t.placeholders, name)
+		pattern.WriteString(regexp.QuoteMeta(literal))
+		pattern.WriteString("([^/]+)")
+	}
+
+	literal := path[last:]
+	if strings.ContainsAny(literal, "{}") {
+		return nil, fmt.Errorf("SPIFFE ID template %q has an invalid placeholder", path)
+	}
+	t.literals = append(t.literals, literal)
+	pattern.WriteString(regexp.QuoteMeta(literal))
+	pattern.WriteString("$")
+
+	if !seen[spiffeIDTemplateService] {
+		return nil, fmt.Errorf("SPIFFE ID template %q must contain the {%s} placeholder", path, spiffeIDTemplateService)
+	}
+
+	re, err := regexp.Compile(pattern.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid SPIFFE ID template %q: %w", path, err)
+	}
+	t.re = re
+	return t, nil
+}
+
+// Match returns the service identity of the given SPIFFE ID, or nil if the ID
+// is not in the trust domain or does not match the template.
+func (t *SpiffeIDTemplate) Match(input *url.URL) (*SpiffeIDService, error) {
+	if input.Scheme != "spiffe" || input.Host != t.TrustDomain {
+		return nil, nil
+	}
+
+	path := input.Path
+	if input.RawPath != "" {
+		path = input.RawPath
+	}
+	v := t.re.FindStringSubmatch(path)
+	if v == nil {
+		return nil, nil
+	}
+
+	id := &SpiffeIDService{
+		Host:                 input.Host,
+		Partition:            "default",
+		Namespace:            "default",
+		FederatedTrustDomain: t.TrustDomain,
+	}
+	for i, name := range t.placeholders {
+		value := v[i+1]
+		if input.RawPath != "" {
+			var err error
+			if value, err = url.PathUnescape(value); err != nil {
+				return nil, fmt.Errorf("Invalid %s: %s", name, err)
+			}
+		}
+
+		switch name {
+		case spiffeIDTemplateService:
+			id.Service = value
+		case spiffeIDTemplateNamespace:
+			id.Namespace = value
+		case spiffeIDTemplatePartition:
+			id.Partition = value
+		case spiffeIDTemplateDatacenter:
+			id.Datacenter = value
+		}
+	}
+	return id, nil
+}
+
+// URI returns the SPIFFE ID of the given service in the trust domain of the
+// template. The Host of id is ignored. It returns false if the template
+// cannot represent the service, for example because the service is in a
+// namespace other than the default one and the template has no {namespace}
+// placeholder.
+func (t *SpiffeIDTemplate) URI(id SpiffeIDService) (*url.URL, bool) {
+	identity := func(s string) string { return s }
+	path, ok := t.render(id, identity, identity)
+	if !ok {
+		return nil, false
+	}
+	return &url.URL{
+		Scheme: "spiffe",
+		Host:   t.TrustDomain,
+		Path:   path,
+	}, true
+}
+
+// Pattern returns a regular expression matching the SPIFFE IDs of the given
+// service in the trust domain of the template. A wildcard namespace or
+// service, and an empty datacenter, match any value. It returns false if the
+// template cannot represent the service.
+func (t *SpiffeIDTemplate) Pattern(id SpiffeIDService) (string, bool) {
+	path, ok := t.render(id, regexp.QuoteMeta, func(v string) string {
+		if v == "" || v == "*" {
+			return "[^/]+"
+		}
+		return regexp.QuoteMeta(v)
+	})
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("^spiffe://%s%s$", regexp.QuoteMeta(t.TrustDomain), path), true
+}
+
+func (t *SpiffeIDTemplate) render(id SpiffeIDService, literal, value func(string) string) (string, bool) {
+	values := map[string]string{
+		spiffeIDTemplateService:    id.Service,
+		spiffeIDTemplateNamespace:  id.NamespaceOrDefault(),
+		spiffeIDTemplatePartition:  id.PartitionOrDefault(),
+		spiffeIDTemplateDatacenter: id.Datacenter,
+	}
+	if !t.has(spiffeIDTemplateNamespace) && !isDefaultOrWildcard(values[spiffeIDTemplateNamespace]) {
+		return "", false
+	}
+	if !t.has(spiffeIDTemplatePartition) && !isDefaultOrWildcard(values[spiffeIDTemplatePartition]) {
+		return "", false
+	}
+
+	var path strings.Builder
+	for i, name := range t.placeholders {
+		path.WriteString(literal(t.literals[i]))
+		path.WriteString(value(values[name]))
+	}
+	path.WriteString(literal(t.literals[len(t.literals)-1]))
+	return path.String(), true
+}
+
+func (t *SpiffeIDTemplate) has(placeholder string) bool {
+	for _, name := range t.placeholders {
+		if name == placeholder {
+			return true
+		}
+	}
+	return false
+}
+
+// isDefaultOrWildcard returns whether a namespace or partition name refers to
+// the default one, which is the only one a template without the matching
+// placeholder can represent.
+func isDefaultOrWildcard(name string) bool {
+	return name == "" || name == "*" || name == "default"
+}
+
+// SpiffeIDTemplates is the list of the SPIFFE ID templates of all the trust
+// domains not managed by Consul.
+type SpiffeIDTemplates []*SpiffeIDTemplate
+
+// Match returns the service identity of the given SPIFFE ID using the first
+// template that matches it, or nil if none does.
+func (ts SpiffeIDTemplates) Match(input *url.URL) (*SpiffeIDService, error) {
+	for _, t := range ts {
+		id, err := t.Match(input)
+		if err != nil || id != nil {
+			return id, err
+		}
+	}
+	return nil, nil
+}
+
+// ForTrustDomain returns the templates of the given trust domain.
+func (ts SpiffeIDTemplates) ForTrustDomain(trustDomain string) SpiffeIDTemplates {
+	var out SpiffeIDTemplates
+	for _, t := range ts {
+		if t.TrustDomain == trustDomain {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// URIs returns the SPIFFE IDs of the given service in all the trust domains
+// that can represent it.
+func (ts SpiffeIDTemplates) URIs(id SpiffeIDService) []*url.URL {
+	var out []*url.URL
+	for _, t := range ts {
+		if u, ok := t.URI(id); ok {
+			out = append(out, u)
+		}
+	}
+	return out
+}
+
+// ParseCertURIWithTemplates parses the URI value from a TLS certificate like
+// ParseCertURI but also accepts the SPIFFE IDs described by the templates.
+func ParseCertURIWithTemplates(input *url.URL, templates SpiffeIDTemplates) (CertURI, error) {
+	id, err := templates.Match(input)
+	if err != nil {
+		return nil, err
+	}
+	if id != nil {
+		return id, nil
+	}
+	return ParseCertURI(input)
+}
+
+// NewSpiffeIDTemplates parses the SPIFFE ID templates of the given federated
+// trust domains.
+func NewSpiffeIDTemplates(domains []structs.FederatedTrustDomain) (SpiffeIDTemplates, error) {
+	var out SpiffeIDTemplates
+	seen := make(map[string]bool, len(domains))
+	for _, d := range domains {
+		if seen[d.Name] {
+			return nil, fmt.Errorf("trust domain %q is configured more than once", d.Name)
+		}
+		seen[d.Name] = true
+
+		for _, path := range d.SpiffeIDTemplates {
+			t, err := ParseSpiffeIDTemplate(d.Name, path)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
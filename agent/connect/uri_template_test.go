@@ -0,0 +1,179 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package connect
+
+import (
+	"net/url"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hernad/consul/agent/structs"
+)
+
+func TestParseSpiffeIDTemplate(t *testing.T) {
+	var cases = []struct {
+		Name        string
+		TrustDomain string
+		Path        string
+		Error       string
+	}{
+		{"valid", "example.org", "/ns/{namespace}/sa/{service}", ""},
+		{"all placeholders", "example.org", "/{partition}/{namespace}/{datacenter}/{service}", ""},
+		{"no trust domain", "", "/svc/{service}", "trust domain is required"},
+		{"trust domain with path", "example.org/foo", "/svc/{service}", "must be a host name"},
+		{"relative path", "example.org", "svc/{service}", "must start with a /"},
+		{"no service", "example.org", "/ns/{namespace}", "must contain the {service} placeholder"},
+		{"unknown placeholder", "example.org", "/{node}/{service}", "unknown placeholder {node}"},
+		{"duplicate placeholder", "example.org", "/{service}/{service}", "more than once"},
+		{"unbalanced brace", "example.org", "/{service}/{namespace", "invalid placeholder"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			_, err := ParseSpiffeIDTemplate(tc.TrustDomain, tc.Path)
+			if tc.Error == "" {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.Error)
+			}
+		})
+	}
+}
+
+func TestSpiffeIDTemplate_Match(t *testing.T) {
+	tmpl, err := ParseSpiffeIDTemplate("example.org", "/ns/{namespace}/sa/{service}")
+	require.NoError(t, err)
+
+	var cases = []struct {
+		Name string
+		URI  string
+		ID   *SpiffeIDService
+	}{
+		{
+			Name: "match",
+			URI:  "spiffe://example.org/ns/default/sa/web",
+			ID: &SpiffeIDService{
+				Host:                 "example.org",
+				Partition:            "default",
+				Namespace:            "default",
+				Service:              "web",
+				FederatedTrustDomain: "example.org",
+			},
+		},
+		{
+			Name: "encoded",
+			URI:  "spiffe://example.org/ns/default/sa/web%2Fapi",
+			ID: &SpiffeIDService{
+				Host:                 "example.org",
+				Partition:            "default",
+				Namespace:            "default",
+				Service:              "web/api",
+				FederatedTrustDomain: "example.org",
+			},
+		},
+		{
+			Name: "other trust domain",
+			URI:  "spiffe://example.com/ns/default/sa/web",
+		},
+		{
+			Name: "other path",
+			URI:  "spiffe://example.org/ns/default/svc/web",
+		},
+		{
+			Name: "extra segment",
+			URI:  "spiffe://example.org/ns/default/sa/web/extra",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			u, err := url.Parse(tc.URI)
+			require.NoError(t, err)
+
+			id, err := tmpl.Match(u)
+			require.NoError(t, err)
+			require.Equal(t, tc.ID, id)
+		})
+	}
+}
+
+func TestSpiffeIDTemplate_URIAndPattern(t *testing.T) {
+	withNS, err := ParseSpiffeIDTemplate("example.org", "/ns/{namespace}/dc/{datacenter}/sa/{service}")
+	require.NoError(t, err)
+	withoutNS, err := ParseSpiffeIDTemplate("example.org", "/workload/{service}")
+	require.NoError(t, err)
+
+	web := SpiffeIDService{Host: "1234.consul", Namespace: "default", Datacenter: "dc1", Service: "web"}
+
+	u, ok := withNS.URI(web)
+	require.True(t, ok)
+	require.Equal(t, "spiffe://example.org/ns/default/dc/dc1/sa/web", u.String())
+
+	u, ok = withoutNS.URI(web)
+	require.True(t, ok)
+	require.Equal(t, "spiffe://example.org/workload/web", u.String())
+
+	// A template without a {partition} placeholder only represents the
+	// default partition.
+	other := web
+	other.Partition = "other"
+	_, ok = withoutNS.URI(other)
+	require.False(t, ok)
+
+	// The datacenter is not part of patterns.
+	web.Datacenter = ""
+	pattern, ok := withNS.Pattern(web)
+	require.True(t, ok)
+	require.Equal(t, `^spiffe://example\.org/ns/default/dc/[^/]+/sa/web$`, pattern)
+	require.Regexp(t, regexp.MustCompile(pattern), "spiffe://example.org/ns/default/dc/dc2/sa/web")
+
+	wildcard := SpiffeIDService{Namespace: "*", Service: "*"}
+	pattern, ok = withoutNS.Pattern(wildcard)
+	require.True(t, ok)
+	require.Equal(t, `^spiffe://example\.org/workload/[^/]+$`, pattern)
+}
+
+func TestParseCertURIWithTemplates(t *testing.T) {
+	templates, err := NewSpiffeIDTemplates([]structs.FederatedTrustDomain{
+		{Name: "example.org", SpiffeIDTemplates: []string{"/ns/{namespace}/sa/{service}", "/svc/{service}"}},
+	})
+	require.NoError(t, err)
+
+	parse := func(input string) CertURI {
+		u, err := url.Parse(input)
+		require.NoError(t, err)
+		id, err := ParseCertURIWithTemplates(u, templates)
+		require.NoError(t, err)
+		return id
+	}
+
+	id := parse("spiffe://example.org/svc/web")
+	require.Equal(t, &SpiffeIDService{Host: "example.org", Partition: "default", Namespace: "default", Service: "web", FederatedTrustDomain: "example.org"}, id)
+
+	// Consul's own SPIFFE IDs are still accepted.
+	id = parse("spiffe://1234.consul/ns/default/dc/dc1/svc/web")
+	require.Equal(t, &SpiffeIDService{Host: "1234.consul", Partition: "default", Namespace: "default", Datacenter: "dc1", Service: "web"}, id)
+
+	_, err = NewSpiffeIDTemplates([]structs.FederatedTrustDomain{
+		{Name: "example.org"},
+		{Name: "example.org"},
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "configured more than once")
+}
+
+func TestSpiffeIDTemplates_ForTrustDomain(t *testing.T) {
+	templates, err := NewSpiffeIDTemplates([]structs.FederatedTrustDomain{
+		{Name: "example.org", SpiffeIDTemplates: []string{"/ns/{namespace}/sa/{service}", "/svc/{service}"}},
+		{Name: "example.com", SpiffeIDTemplates: []string{"/workload/{service}"}},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, templates.ForTrustDomain("example.org"), 2)
+	require.Equal(t, "/workload/{service}", templates.ForTrustDomain("example.com")[0].Path)
+	require.Empty(t, templates.ForTrustDomain("1234.consul"))
+}
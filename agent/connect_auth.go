@@ -7,6 +7,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/url"
 
 	"github.com/hernad/consul/acl"
 	"github.com/hernad/consul/agent/cache"
@@ -49,8 +50,31 @@ func (a *Agent) ConnectAuthorize(token string,
 		return returnErr(HTTPError{StatusCode: http.StatusBadRequest, Reason: "Target service must be specified"})
 	}
 
+	// The roots carry the revocations and the SPIFFE ID templates of the
+	// federated trust domains.
+	rootsArgs := &structs.DCSpecificRequest{
+		Datacenter:   a.config.Datacenter,
+		QueryOptions: structs.QueryOptions{Token: token},
+	}
+	rawRoots, _, err := a.cache.Get(context.TODO(), cachetype.ConnectCARootName, rootsArgs)
+	if err != nil {
+		return returnErr(err)
+	}
+	roots, ok := rawRoots.(*structs.IndexedCARoots)
+	if !ok {
+		return returnErr(fmt.Errorf("internal error: roots response type not correct"))
+	}
+	templates, err := connect.NewSpiffeIDTemplates(roots.FederatedTrustDomains)
+	if err != nil {
+		return returnErr(err)
+	}
+
 	// Parse the certificate URI from the client ID
-	uri, err := connect.ParseCertURIFromString(req.ClientCertURI)
+	var uri connect.CertURI
+	uriRaw, err := url.Parse(req.ClientCertURI)
+	if err == nil {
+		uri, err = connect.ParseCertURIWithTemplates(uriRaw, templates)
+	}
 	if err != nil {
 		return returnErr(HTTPError{StatusCode: http.StatusBadRequest, Reason: "ClientCertURI not a valid Connect identifier"})
 	}
@@ -83,7 +107,20 @@ func (a *Agent) ConnectAuthorize(token string,
 	// Note that we DON'T explicitly validate the trust-domain matches ours. See
 	// the PR for this change for details.
 
-	// TODO(banks): Implement revocation list checking here.
+	// Deny certificates and identities that have been revoked even though they
+	// still chain to a trusted root.
+	if req.ClientCertSerial != "" {
+		if rev := roots.SerialRevoked(req.ClientCertSerial); rev != nil {
+			return false, fmt.Sprintf("Client certificate %s has been revoked", rev.SerialNumber), nil, nil
+		}
+	}
+	// Revoked service identities are Consul identities, a workload of a
+	// federated trust domain that shares the name is a different identity.
+	if uriService.FederatedTrustDomain == "" {
+		if rev := roots.ServiceRevoked(uriService.Service, uriService.GetEnterpriseMeta()); rev != nil {
+			return false, fmt.Sprintf("Service identity %q has been revoked", uriService.Service), nil, nil
+		}
+	}
 
 	// Get the intentions for this target service.
 	args := &structs.IntentionQueryRequest{
@@ -118,8 +155,10 @@ func (a *Agent) ConnectAuthorize(token string,
 	var ixnMatch *structs.Intention
 	for _, ixn := range reply.Matches[0] {
 		// We match on the intention source because the uriService is the source of the connection to authorize.
+		// Workloads of a federated trust domain only match intentions that name it.
 		if _, ok := connect.AuthorizeIntentionTarget(
-			uriService.Service, uriService.Namespace, uriService.Partition, "", ixn, structs.IntentionMatchSource); ok {
+			uriService.Service, uriService.Namespace, uriService.Partition, "", uriService.FederatedTrustDomain,
+			ixn, structs.IntentionMatchSource); ok {
 			ixnMatch = ixn
 			break
 		}
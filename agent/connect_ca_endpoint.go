@@ -4,14 +4,22 @@
 package agent
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/hernad/consul/agent/connect"
 	"github.com/hernad/consul/agent/consul"
 	"github.com/hernad/consul/agent/structs"
 )
 
+// spiffeBundleRefreshHint is how often the consumers of the SPIFFE bundle
+// served by Consul are asked to fetch it again.
+const spiffeBundleRefreshHint = 5 * time.Minute
+
 // GET /v1/connect/ca/roots
 func (s *HTTPHandlers) ConnectCARoots(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
 	var args structs.DCSpecificRequest
@@ -54,6 +62,73 @@ func (s *HTTPHandlers) ConnectCARoots(resp http.ResponseWriter, req *http.Reques
 	return nil, nil
 }
 
+// GET /v1/connect/ca/spiffe-bundle
+//
+// ConnectCASpiffeBundle serves the CA roots as a SPIFFE bundle so that other
+// trust domains, like SPIRE, can federate with Consul.
+func (s *HTTPHandlers) ConnectCASpiffeBundle(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	var args structs.DCSpecificRequest
+	if done := s.parse(resp, req, &args.Datacenter, &args.QueryOptions); done {
+		return nil, nil
+	}
+
+	var reply structs.IndexedCARoots
+	defer setMeta(resp, &reply.QueryMeta)
+	if err := s.agent.RPC(req.Context(), "ConnectCA.Roots", &args, &reply); err != nil {
+		return nil, err
+	}
+
+	bundle := &connect.SpiffeBundle{
+		Sequence:    reply.Index,
+		RefreshHint: spiffeBundleRefreshHint,
+	}
+	for _, root := range reply.Roots {
+		bundle.RootPEMs = append(bundle.RootPEMs, root.RootCert)
+	}
+	out, err := json.Marshal(bundle)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Header().Set("Content-Type", "application/json")
+	if _, err := resp.Write(out); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// GET /v1/connect/ca/jwks
+//
+// ConnectCAJWKS serves the JSON Web Key Set to verify the JWT-SVIDs issued by
+// Consul.
+func (s *HTTPHandlers) ConnectCAJWKS(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	var args structs.DCSpecificRequest
+	if done := s.parse(resp, req, &args.Datacenter, &args.QueryOptions); done {
+		return nil, nil
+	}
+
+	var reply structs.IndexedCARoots
+	defer setMeta(resp, &reply.QueryMeta)
+	if err := s.agent.RPC(req.Context(), "ConnectCA.Roots", &args, &reply); err != nil {
+		return nil, err
+	}
+
+	keySet, err := connect.JWTSVIDKeySet(reply.JWTSigningKeys)
+	if err != nil {
+		return nil, err
+	}
+	out, err := json.Marshal(keySet)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Header().Set("Content-Type", "application/json")
+	if _, err := resp.Write(out); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
 // /v1/connect/ca/configuration
 func (s *HTTPHandlers) ConnectCAConfiguration(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
 	switch req.Method {
@@ -86,6 +161,7 @@ func (s *HTTPHandlers) ConnectCAConfigurationGet(resp http.ResponseWriter, req *
 }
 
 // PUT /v1/connect/ca/configuration
+// PUT /v1/connect/ca/configuration?dry-run
 func (s *HTTPHandlers) ConnectCAConfigurationSet(req *http.Request) (interface{}, error) {
 	// Method is tested in ConnectCAConfiguration
 
@@ -96,14 +172,68 @@ func (s *HTTPHandlers) ConnectCAConfigurationSet(req *http.Request) (interface{}
 		return nil, HTTPError{StatusCode: http.StatusBadRequest, Reason: fmt.Sprintf("Request decode failed: %v", err)}
 	}
 
+	if _, ok := req.URL.Query()["dry-run"]; ok {
+		var reply structs.CADryRunReport
+		err := s.agent.RPC(req.Context(), "ConnectCA.ConfigurationDryRun", &args, &reply)
+		if err != nil {
+			return nil, connectCAConfigurationSetError(err)
+		}
+		return reply, nil
+	}
+
 	var reply interface{}
 	err := s.agent.RPC(req.Context(), "ConnectCA.ConfigurationSet", &args, &reply)
+	return nil, connectCAConfigurationSetError(err)
+}
+
+func connectCAConfigurationSetError(err error) error {
 	if err != nil && err.Error() == consul.ErrStateReadOnly.Error() {
-		return nil, HTTPError{
+		return HTTPError{
 			StatusCode: http.StatusBadRequest,
 			Reason: "Provider State is read-only. It must be omitted" +
 				" or identical to the current value",
 		}
 	}
-	return nil, err
+	return err
+}
+
+// PUT /v1/connect/ca/revoke
+func (s *HTTPHandlers) ConnectCARevoke(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	args := structs.CARevocationRequest{
+		Revocation: &structs.CARevocation{},
+	}
+	s.parseDC(req, &args.Datacenter)
+	s.parseToken(req, &args.Token)
+	if err := decodeBody(req.Body, args.Revocation); err != nil {
+		return nil, HTTPError{StatusCode: http.StatusBadRequest, Reason: fmt.Sprintf("Request decode failed: %v", err)}
+	}
+	if err := args.Revocation.Validate(); err != nil {
+		return nil, HTTPError{StatusCode: http.StatusBadRequest, Reason: err.Error()}
+	}
+
+	var reply structs.CARevocation
+	if err := s.agent.RPC(req.Context(), "ConnectCA.Revoke", &args, &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+// DELETE /v1/connect/ca/revocation/:id
+func (s *HTTPHandlers) ConnectCARevocationDelete(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	id := strings.TrimPrefix(req.URL.Path, "/v1/connect/ca/revocation/")
+	if id == "" {
+		return nil, HTTPError{StatusCode: http.StatusBadRequest, Reason: "Missing revocation ID"}
+	}
+
+	args := structs.CARevocationRequest{
+		Revocation: &structs.CARevocation{ID: id},
+	}
+	s.parseDC(req, &args.Datacenter)
+	s.parseToken(req, &args.Token)
+
+	var reply struct{}
+	if err := s.agent.RPC(req.Context(), "ConnectCA.RevocationDelete", &args, &reply); err != nil {
+		return nil, err
+	}
+	return true, nil
 }
@@ -6,6 +6,7 @@ package agent
 import (
 	"bytes"
 	"crypto/x509"
+	"encoding/json"
 	"encoding/pem"
 	"io"
 	"net/http"
@@ -265,6 +266,90 @@ func TestConnectCAConfig(t *testing.T) {
 	}
 }
 
+func TestConnectCAConfig_DryRun(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	a := NewTestAgent(t, "")
+	defer a.Shutdown()
+	testrpc.WaitForTestAgent(t, a.RPC, "dc1")
+
+	body := bytes.NewBufferString(`{
+		"Provider": "consul",
+		"Config": {
+			"PrivateKeyType": "rsa",
+			"PrivateKeyBits": 2048
+		}
+	}`)
+	req, _ := http.NewRequest("PUT", "/v1/connect/ca/configuration?dry-run", body)
+	resp := httptest.NewRecorder()
+	obj, err := a.srv.ConnectCAConfiguration(resp, req)
+	require.NoError(t, err)
+
+	report := obj.(structs.CADryRunReport)
+	require.True(t, report.RootRotation)
+	require.Equal(t, "rsa", report.NewRoot.PrivateKeyType)
+
+	// The configuration was not applied.
+	req, _ = http.NewRequest("GET", "/v1/connect/ca/configuration", nil)
+	resp = httptest.NewRecorder()
+	obj, err = a.srv.ConnectCAConfiguration(resp, req)
+	require.NoError(t, err)
+	require.NotContains(t, obj.(structs.CAConfiguration).Config, "PrivateKeyType")
+}
+
+func TestConnectCARevoke(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	a := NewTestAgent(t, "")
+	defer a.Shutdown()
+	testrpc.WaitForTestAgent(t, a.RPC, "dc1")
+
+	// Invalid revocations are rejected before reaching the servers.
+	body := bytes.NewBufferString(`{"Reason": "compromised"}`)
+	req, _ := http.NewRequest("PUT", "/v1/connect/ca/revoke", body)
+	resp := httptest.NewRecorder()
+	_, err := a.srv.ConnectCARevoke(resp, req)
+	require.Error(t, err)
+	require.Equal(t, http.StatusBadRequest, err.(HTTPError).StatusCode)
+
+	body = bytes.NewBufferString(`{"Service": "web", "Reason": "compromised"}`)
+	req, _ = http.NewRequest("PUT", "/v1/connect/ca/revoke", body)
+	resp = httptest.NewRecorder()
+	obj, err := a.srv.ConnectCARevoke(resp, req)
+	require.NoError(t, err)
+	rev := obj.(structs.CARevocation)
+	require.NotEmpty(t, rev.ID)
+	require.Equal(t, "web", rev.Service)
+
+	// The revocation is listed with the roots.
+	req, _ = http.NewRequest("GET", "/v1/connect/ca/roots", nil)
+	resp = httptest.NewRecorder()
+	obj, err = a.srv.ConnectCARoots(resp, req)
+	require.NoError(t, err)
+	roots := obj.(structs.IndexedCARoots)
+	require.Len(t, roots.Revocations, 1)
+	require.Equal(t, rev.ID, roots.Revocations[0].ID)
+
+	req, _ = http.NewRequest("DELETE", "/v1/connect/ca/revocation/"+rev.ID, nil)
+	resp = httptest.NewRecorder()
+	_, err = a.srv.ConnectCARevocationDelete(resp, req)
+	require.NoError(t, err)
+
+	req, _ = http.NewRequest("GET", "/v1/connect/ca/roots", nil)
+	resp = httptest.NewRecorder()
+	obj, err = a.srv.ConnectCARoots(resp, req)
+	require.NoError(t, err)
+	require.Empty(t, obj.(structs.IndexedCARoots).Revocations)
+}
+
 func TestConnectCARoots_PEMEncoding(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
@@ -302,3 +387,32 @@ func TestConnectCARoots_PEMEncoding(t *testing.T) {
 	_, err = x509.ParseCertificate(block.Bytes)
 	require.NoError(t, err)
 }
+
+func TestConnectCASpiffeBundle(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	a := NewTestAgent(t, "")
+	defer a.Shutdown()
+	testrpc.WaitForActiveCARoot(t, a.RPC, "dc1", nil)
+
+	// NewTestAgent already bootstraps one CA so this adds a second one.
+	connect.TestCAConfigSet(t, a, nil)
+
+	req, _ := http.NewRequest("GET", "/v1/connect/ca/spiffe-bundle", nil)
+	recorder := httptest.NewRecorder()
+	obj, err := a.srv.ConnectCASpiffeBundle(recorder, req)
+	require.NoError(t, err)
+	require.Nil(t, obj)
+	resp := recorder.Result()
+	require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	var bundle connect.SpiffeBundle
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&bundle))
+	require.Len(t, bundle.RootPEMs, 2)
+	require.NotZero(t, bundle.Sequence)
+	require.Equal(t, spiffeBundleRefreshHint, bundle.RefreshHint)
+}
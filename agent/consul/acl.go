@@ -7,6 +7,7 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -102,6 +103,10 @@ func (id *missingIdentity) NodeIdentityList() []*structs.ACLNodeIdentity {
 	return nil
 }
 
+func (id *missingIdentity) TemplatedPolicyList() []*structs.ACLTemplatedPolicy {
+	return nil
+}
+
 func (id *missingIdentity) IsExpired(asOf time.Time) bool {
 	return false
 }
@@ -136,6 +141,7 @@ type ACLResolverBackend interface {
 	ResolveIdentityFromToken(token string) (bool, structs.ACLIdentity, error)
 	ResolvePolicyFromID(policyID string) (bool, *structs.ACLPolicy, error)
 	ResolveRoleFromID(roleID string) (bool, *structs.ACLRole, error)
+	ResolvePolicyTemplateFromID(templateID string) (bool, *structs.ACLPolicyTemplate, error)
 	IsServerManagementToken(token string) bool
 	// TODO: separate methods for each RPC call (there are 4)
 	RPC(ctx context.Context, method string, args interface{}, reply interface{}) error
@@ -227,6 +233,7 @@ type ACLResolverSettings struct {
 //   - Resolving tokens remotely via an ACL.TokenRead RPC
 //   - Resolving policies remotely via an ACL.PolicyResolve RPC
 //   - Resolving roles remotely via an ACL.RoleResolve RPC
+//   - Resolving policy templates remotely via an ACL.PolicyTemplateResolve RPC
 //
 // Remote Resolution:
 //
@@ -255,6 +262,7 @@ type ACLResolver struct {
 	identityGroup singleflight.Group
 	policyGroup   singleflight.Group
 	roleGroup     singleflight.Group
+	templateGroup singleflight.Group
 	legacyGroup   singleflight.Group
 
 	down acl.Authorizer
@@ -546,6 +554,59 @@ func (r *ACLResolver) fetchAndCacheRolesForIdentity(identity structs.ACLIdentity
 	return out, nil
 }
 
+func (r *ACLResolver) fetchAndCachePolicyTemplatesForIdentity(identity structs.ACLIdentity, templateIDs []string, cached map[string]*structs.PolicyTemplateCacheEntry) (map[string]*structs.ACLPolicyTemplate, error) {
+	req := structs.ACLPolicyTemplateBatchGetRequest{
+		Datacenter:        r.backend.ACLDatacenter(),
+		PolicyTemplateIDs: templateIDs,
+		QueryOptions: structs.QueryOptions{
+			Token:      identity.SecretToken(),
+			AllowStale: true,
+		},
+	}
+
+	var resp structs.ACLPolicyTemplateBatchResponse
+	err := r.backend.RPC(context.Background(), "ACL.PolicyTemplateResolve", &req, &resp)
+	if err == nil {
+		out := make(map[string]*structs.ACLPolicyTemplate)
+		for _, template := range resp.PolicyTemplates {
+			out[template.ID] = template
+		}
+
+		for _, templateID := range templateIDs {
+			r.cache.PutPolicyTemplate(templateID, out[templateID])
+		}
+		return out, nil
+	}
+
+	if handledErr := r.maybeHandleIdentityErrorDuringFetch(identity, err); handledErr != nil {
+		return nil, handledErr
+	}
+
+	// other RPC error - use cache if available
+
+	extendCache := r.config.ACLDownPolicy == "extend-cache" || r.config.ACLDownPolicy == "async-cache"
+
+	out := make(map[string]*structs.ACLPolicyTemplate)
+	insufficientCache := false
+	for _, templateID := range templateIDs {
+		if entry, ok := cached[templateID]; extendCache && ok {
+			r.cache.PutPolicyTemplate(templateID, entry.PolicyTemplate)
+			if entry.PolicyTemplate != nil {
+				out[templateID] = entry.PolicyTemplate
+			}
+		} else {
+			r.cache.PutPolicyTemplate(templateID, nil)
+			insufficientCache = true
+		}
+	}
+
+	if insufficientCache {
+		return nil, ACLRemoteError{Err: err}
+	}
+
+	return out, nil
+}
+
 func (r *ACLResolver) maybeHandleIdentityErrorDuringFetch(identity structs.ACLIdentity, err error) error {
 	if acl.IsErrNotFound(err) {
 		// make sure to indicate that this identity is no longer valid within
@@ -596,9 +657,10 @@ func (r *ACLResolver) resolvePoliciesForIdentity(identity structs.ACLIdentity) (
 		roleIDs           = identity.RoleIDs()
 		serviceIdentities = structs.ACLServiceIdentities(identity.ServiceIdentityList())
 		nodeIdentities    = structs.ACLNodeIdentities(identity.NodeIdentityList())
+		templatedPolicies = structs.ACLTemplatedPolicies(identity.TemplatedPolicyList())
 	)
 
-	if len(policyIDs) == 0 && len(serviceIdentities) == 0 && len(roleIDs) == 0 && len(nodeIdentities) == 0 {
+	if len(policyIDs) == 0 && len(serviceIdentities) == 0 && len(roleIDs) == 0 && len(nodeIdentities) == 0 && len(templatedPolicies) == 0 {
 		// In this case the default policy will be all that is in effect.
 		return nil, nil
 	}
@@ -616,17 +678,25 @@ func (r *ACLResolver) resolvePoliciesForIdentity(identity structs.ACLIdentity) (
 		}
 		serviceIdentities = append(serviceIdentities, role.ServiceIdentities...)
 		nodeIdentities = append(nodeIdentities, role.NodeIdentityList()...)
+		templatedPolicies = append(templatedPolicies, role.TemplatedPolicyList()...)
 	}
 
 	// Now deduplicate any policies or service identities that occur more than once.
 	policyIDs = dedupeStringSlice(policyIDs)
 	serviceIdentities = serviceIdentities.Deduplicate()
 	nodeIdentities = nodeIdentities.Deduplicate()
+	templatedPolicies = templatedPolicies.Deduplicate()
 
 	// Generate synthetic policies for all service identities in effect.
 	syntheticPolicies := r.synthesizePoliciesForServiceIdentities(serviceIdentities, identity.EnterpriseMetadata())
 	syntheticPolicies = append(syntheticPolicies, r.synthesizePoliciesForNodeIdentities(nodeIdentities, identity.EnterpriseMetadata())...)
 
+	templatePolicies, err := r.synthesizePoliciesForTemplatedPolicies(identity, templatedPolicies)
+	if err != nil {
+		return nil, err
+	}
+	syntheticPolicies = append(syntheticPolicies, templatePolicies...)
+
 	// For the new ACLs policy replication is mandatory for correct operation on servers. Therefore
 	// we only attempt to resolve policies locally
 	policies, err := r.collectPoliciesForIdentity(identity, policyIDs, len(syntheticPolicies))
@@ -669,6 +739,45 @@ func (r *ACLResolver) synthesizePoliciesForNodeIdentities(nodeIdentities []*stru
 	return syntheticPolicies
 }
 
+func (r *ACLResolver) synthesizePoliciesForTemplatedPolicies(identity structs.ACLIdentity, templatedPolicies structs.ACLTemplatedPolicies) ([]*structs.ACLPolicy, error) {
+	if len(templatedPolicies) == 0 {
+		return nil, nil
+	}
+
+	templateIDs := make([]string, 0, len(templatedPolicies))
+	for _, link := range templatedPolicies {
+		templateIDs = append(templateIDs, link.TemplateID)
+	}
+
+	templates, err := r.collectPolicyTemplatesForIdentity(identity, dedupeStringSlice(templateIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	syntheticPolicies := make([]*structs.ACLPolicy, 0, len(templatedPolicies))
+	for _, link := range templatedPolicies {
+		template, ok := templates[link.TemplateID]
+		if !ok {
+			// the template was deleted, like a deleted policy it grants nothing
+			continue
+		}
+
+		policy, err := template.SyntheticPolicy(link, identity.EnterpriseMetadata())
+		if err != nil {
+			// the variables may no longer match an updated template
+			r.logger.Warn("failed to render policy template for identity",
+				"template", template.Name,
+				"accessorID", acl.AliasIfAnonymousToken(identity.ID()),
+				"error", err,
+			)
+			continue
+		}
+		syntheticPolicies = append(syntheticPolicies, policy)
+	}
+
+	return syntheticPolicies, nil
+}
+
 func mergeStringSlice(a, b []string) []string {
 	out := make([]string, 0, len(a)+len(b))
 	out = append(out, a...)
@@ -890,6 +999,92 @@ func (r *ACLResolver) collectRolesForIdentity(identity structs.ACLIdentity, role
 	return roles, nil
 }
 
+func (r *ACLResolver) collectPolicyTemplatesForIdentity(identity structs.ACLIdentity, templateIDs []string) (map[string]*structs.ACLPolicyTemplate, error) {
+	templates := make(map[string]*structs.ACLPolicyTemplate, len(templateIDs))
+
+	var missing []string
+	var expired []*structs.ACLPolicyTemplate
+	expCacheMap := make(map[string]*structs.PolicyTemplateCacheEntry)
+
+	for _, templateID := range templateIDs {
+		if done, template, err := r.backend.ResolvePolicyTemplateFromID(templateID); done {
+			if err != nil && !acl.IsErrNotFound(err) {
+				return nil, err
+			}
+
+			if template != nil {
+				templates[templateID] = template
+			} else {
+				r.logger.Warn("policy template not found for identity",
+					"template", templateID,
+					"accessorID", acl.AliasIfAnonymousToken(identity.ID()),
+				)
+			}
+
+			continue
+		}
+
+		// create the missing list which we can execute an RPC to get all the missing templates at once
+		entry := r.cache.GetPolicyTemplate(templateID)
+		if entry == nil {
+			missing = append(missing, templateID)
+			continue
+		}
+
+		if entry.PolicyTemplate == nil {
+			// this happens when we cache a negative response for the template's existence
+			continue
+		}
+
+		if entry.Age() >= r.config.ACLPolicyTTL {
+			expired = append(expired, entry.PolicyTemplate)
+			expCacheMap[templateID] = entry
+		} else {
+			templates[templateID] = entry.PolicyTemplate
+		}
+	}
+
+	// Hot-path if we have no missing or expired templates
+	if len(missing)+len(expired) == 0 {
+		return templates, nil
+	}
+
+	hasMissing := len(missing) > 0
+
+	fetchIDs := missing
+	for _, template := range expired {
+		fetchIDs = append(fetchIDs, template.ID)
+	}
+
+	waitChan := r.templateGroup.DoChan(identity.SecretToken(), func() (interface{}, error) {
+		templates, err := r.fetchAndCachePolicyTemplatesForIdentity(identity, fetchIDs, expCacheMap)
+		return templates, err
+	})
+
+	waitForResult := hasMissing || r.config.ACLDownPolicy != "async-cache"
+	if !waitForResult {
+		// waitForResult being false requires that all the templates were cached already
+		for _, template := range expired {
+			templates[template.ID] = template
+		}
+		return templates, nil
+	}
+
+	res := <-waitChan
+
+	if res.Err != nil {
+		return nil, res.Err
+	}
+
+	if res.Val != nil {
+		for id, template := range res.Val.(map[string]*structs.ACLPolicyTemplate) {
+			templates[id] = template
+		}
+	}
+
+	return templates, nil
+}
+
 func (r *ACLResolver) resolveTokenToIdentityAndPolicies(token string) (structs.ACLIdentity, structs.ACLPolicies, error) {
 	var lastErr error
 	var lastIdentity structs.ACLIdentity
@@ -1060,6 +1255,123 @@ func (r *ACLResolver) ResolveToken(tokenSecretID string) (resolver.Result, error
 	return resolver.Result{Authorizer: acl.NewChainedAuthorizer(chain), ACLIdentity: identity}, nil
 }
 
+// ExplainAuthorizations returns the decisions of the authorizer of the token
+// for the requests, along with the policy and rule of the token that produced
+// each of them.
+func (r *ACLResolver) ExplainAuthorizations(tokenSecretID string, requests []structs.ACLAuthorizationRequest) ([]structs.ACLAuthorizationExplanation, error) {
+	result, err := r.ResolveToken(tokenSecretID)
+	if err != nil {
+		return nil, err
+	}
+	responses, err := structs.CreateACLAuthorizationResponses(result, requests)
+	if err != nil {
+		return nil, err
+	}
+	explanations := make([]structs.ACLAuthorizationExplanation, len(responses))
+	for idx, resp := range responses {
+		explanations[idx].ACLAuthorizationResponse = resp
+	}
+
+	var reason string
+	switch ident := result.ACLIdentity.(type) {
+	case nil:
+		reason = "ACLs are disabled"
+	case *structs.AgentRecoveryTokenIdentity:
+		reason = "the token is the agent recovery token"
+	case *structs.ACLServerIdentity:
+		reason = "the token is the server management token"
+	case *missingIdentity:
+		reason = fmt.Sprintf("the token could not be resolved (%s), the down policy applied", ident.reason)
+	}
+	if reason != "" {
+		for idx := range explanations {
+			explanations[idx].Reason = reason
+		}
+		return explanations, nil
+	}
+
+	if tokenSecretID == "" {
+		tokenSecretID = anonymousToken
+	}
+	identity, policies, err := r.resolveTokenToIdentityAndPolicies(tokenSecretID)
+	if err != nil {
+		return nil, err
+	}
+	var conf acl.Config
+	if r.aclConf != nil {
+		conf = *r.aclConf
+	}
+	setEnterpriseConf(identity.EnterpriseMetadata(), &conf)
+
+	type parsedPolicy struct {
+		*structs.ACLPolicy
+		policy *acl.Policy
+		authz  acl.Authorizer
+	}
+	parsed := make([]parsedPolicy, 0, len(policies))
+	for _, policy := range policies {
+		p, err := acl.NewPolicyFromSource(policy.Rules, &conf, policy.EnterprisePolicyMeta())
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse policy %q: %w", policy.Name, err)
+		}
+		authz, err := acl.NewPolicyAuthorizer([]*acl.Policy{p}, &conf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile policy %q: %w", policy.Name, err)
+		}
+		parsed = append(parsed, parsedPolicy{ACLPolicy: policy, policy: p, authz: authz})
+	}
+
+	var ctx acl.AuthorizerContext
+	for idx := range explanations {
+		e := &explanations[idx]
+		e.FillAuthzContext(&ctx)
+
+		decisive := -1
+		for _, p := range parsed {
+			rule, ok := p.policy.MatchingRule(e.Resource, e.Segment)
+			if !ok {
+				continue
+			}
+			decision, err := acl.Enforce(p.authz, e.Resource, e.Segment, e.Access, &ctx)
+			if err != nil {
+				return nil, err
+			}
+			e.Matches = append(e.Matches, structs.ACLAuthorizationPolicyMatch{
+				PolicyID:   p.ID,
+				PolicyName: p.Name,
+				Rule:       rule,
+				Allow:      decision == acl.Allow,
+			})
+			if decisive < 0 || rule.TakesPrecedenceOver(e.Matches[decisive].Rule) {
+				decisive = len(e.Matches) - 1
+			}
+		}
+
+		if decisive < 0 {
+			e.Reason = fmt.Sprintf("no policy of the token has a rule for the resource, the default policy %q applied", r.config.ACLDefaultPolicy)
+			continue
+		}
+		match := e.Matches[decisive]
+		e.PolicyID = match.PolicyID
+		e.PolicyName = match.PolicyName
+		e.Rule = &match.Rule
+		e.Reason = fmt.Sprintf("rule %s of policy %q", formatRuleMatch(e.Resource, match.Rule), match.PolicyName)
+	}
+	return explanations, nil
+}
+
+// formatRuleMatch formats the rule the way it is written in policies.
+func formatRuleMatch(resource acl.Resource, rule acl.RuleMatch) string {
+	if rule.Name == "" && !strings.HasSuffix(rule.Type, "_prefix") {
+		return fmt.Sprintf("%s = %q", rule.Type, rule.Policy)
+	}
+	field := "policy"
+	if resource == acl.ResourceIntention {
+		field = "intentions"
+	}
+	return fmt.Sprintf("%s %q { %s = %q }", rule.Type, rule.Name, field, rule.Policy)
+}
+
 func (r *ACLResolver) ACLsEnabled() bool {
 	// Whether we desire ACLs to be enabled according to configuration
 	if !r.config.ACLsEnabled {
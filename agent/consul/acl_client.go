@@ -23,6 +23,8 @@ var clientACLCacheConfig = &structs.ACLCachesConfig{
 	Authorizers: 256,
 	// Roles - number of ACL roles that can be cached
 	Roles: 128,
+	// PolicyTemplates - number of ACL policy templates that can be cached
+	PolicyTemplates: 128,
 }
 
 type clientACLResolverBackend struct {
@@ -54,3 +56,8 @@ func (c *clientACLResolverBackend) ResolveRoleFromID(roleID string) (bool, *stru
 	// clients do no local role resolution at the moment
 	return false, nil, nil
 }
+
+func (c *clientACLResolverBackend) ResolvePolicyTemplateFromID(templateID string) (bool, *structs.ACLPolicyTemplate, error) {
+	// clients do no local policy template resolution at the moment
+	return false, nil, nil
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/hernad/consul/acl/resolver"
 	"github.com/hernad/consul/agent/consul/auth"
 	"github.com/hernad/consul/agent/consul/authmethod"
+	"github.com/hernad/consul/agent/consul/authmethod/ssoauth"
 	"github.com/hernad/consul/agent/consul/state"
 	"github.com/hernad/consul/agent/structs"
 	"github.com/hernad/consul/agent/structs/aclfilter"
@@ -331,6 +332,23 @@ func (a *ACL) TokenRead(args *structs.ACLTokenGetRequest, reply *structs.ACLToke
 				return fmt.Errorf("token does not exist: %w", acl.ErrNotFound)
 			}
 
+			if args.TokenIDType != structs.ACLTokenAccessor {
+				// Reading a token by its secret is how secondary datacenters
+				// without token replication resolve tokens, so it counts as a use.
+				a.srv.recordACLTokenUsage(token)
+			}
+
+			// The usage is not watched as it changes far more often than the
+			// token itself and must not wake up blocking queries.
+			lastUsed, err := state.ACLTokenUsageGet(nil, token.AccessorID)
+			if err != nil {
+				return err
+			}
+			if lastUsed != nil {
+				token = token.Clone()
+				token.LastUsedTime = lastUsed
+			}
+
 			reply.Index, reply.Token = index, token
 			reply.SourceDatacenter = args.Datacenter
 
@@ -486,6 +504,7 @@ func (a *ACL) TokenClone(args *structs.ACLTokenSetRequest, reply *structs.ACLTok
 		Roles:             token.Roles,
 		ServiceIdentities: token.ServiceIdentities,
 		NodeIdentities:    token.NodeIdentities,
+		TemplatedPolicies: token.TemplatedPolicies,
 		Local:             token.Local,
 		Description:       token.Description,
 		ExpirationTime:    token.ExpirationTime,
@@ -503,6 +522,56 @@ func (a *ACL) TokenClone(args *structs.ACLTokenSetRequest, reply *structs.ACLTok
 	return err
 }
 
+// TokenCreateChild creates a child of the token making the request. This does
+// not require acl:write as the child may only be granted a subset of the
+// privileges of its parent and is deleted along with it.
+func (a *ACL) TokenCreateChild(args *structs.ACLTokenSetRequest, reply *structs.ACLToken) error {
+	if err := a.aclPreCheck(); err != nil {
+		return err
+	}
+
+	if err := a.srv.validateEnterpriseRequest(&args.ACLToken.EnterpriseMeta, true); err != nil {
+		return err
+	}
+
+	// clients will not know whether the server has local token store. In the case
+	// where it doesn't we will transparently forward requests.
+	if !a.srv.LocalTokensEnabled() {
+		args.Datacenter = a.srv.config.PrimaryDatacenter
+	}
+
+	if done, err := a.srv.ForwardRPC("ACL.TokenCreateChild", args, reply); done {
+		return err
+	}
+
+	defer metrics.MeasureSince([]string{"acl", "token", "create_child"}, time.Now())
+
+	_, parent, err := a.srv.fsm.State().ACLTokenGetBySecret(nil, args.Token, nil)
+	if err != nil {
+		return err
+	} else if parent == nil && !a.srv.InPrimaryDatacenter() {
+		// the parent may be a global token that was not replicated to this
+		// datacenter yet, so let the primary DC look it up
+		args.Datacenter = a.srv.config.PrimaryDatacenter
+		return a.srv.forwardDC("ACL.TokenCreateChild", a.srv.config.PrimaryDatacenter, args, reply)
+	} else if parent == nil || parent.IsExpired(time.Now()) {
+		return fmt.Errorf("parent token does not exist: %w", acl.ErrNotFound)
+	} else if !a.srv.InPrimaryDatacenter() && !parent.Local {
+		// global token writes must be forwarded to the primary DC
+		args.Datacenter = a.srv.config.PrimaryDatacenter
+		return a.srv.forwardDC("ACL.TokenCreateChild", a.srv.config.PrimaryDatacenter, args, reply)
+	}
+
+	// The child always lives next to its parent.
+	args.ACLToken.EnterpriseMeta = parent.EnterpriseMeta
+
+	updated, err := a.srv.aclTokenWriter().CreateChild(parent, &args.ACLToken)
+	if err == nil {
+		*reply = *updated
+	}
+	return err
+}
+
 func (a *ACL) TokenSet(args *structs.ACLTokenSetRequest, reply *structs.ACLToken) error {
 	if err := a.aclPreCheck(); err != nil {
 		return err
@@ -686,14 +755,34 @@ func (a *ACL) TokenList(args *structs.ACLTokenListRequest, reply *structs.ACLTok
 				return err
 			}
 
+			// The usages are not watched as they change far more often than
+			// the tokens themselves and must not wake up blocking queries.
+			usages, err := state.ACLTokenUsageList(nil)
+			if err != nil {
+				return err
+			}
+
 			now := time.Now()
+			// The usage of global tokens is only known to the primary
+			// datacenter, so secondaries cannot tell if they are unused.
+			primary := a.srv.InPrimaryDatacenter()
 
 			stubs := make([]*structs.ACLTokenListStub, 0, len(tokens))
 			for _, token := range tokens {
 				if token.IsExpired(now) {
 					continue
 				}
-				stubs = append(stubs, token.Stub())
+				if args.UnusedFor > 0 && !primary && !token.Local {
+					continue
+				}
+				stub := token.Stub()
+				if lastUsed, ok := usages[token.AccessorID]; ok {
+					stub.LastUsedTime = &lastUsed
+				}
+				if args.UnusedFor > 0 && !stub.UnusedSince(now.Add(-args.UnusedFor)) {
+					continue
+				}
+				stubs = append(stubs, stub)
 			}
 
 			// filter down to just the tokens that the requester has permissions to read
@@ -1118,6 +1207,335 @@ func (a *ACL) PolicyResolve(args *structs.ACLPolicyBatchGetRequest, reply *struc
 	return nil
 }
 
+func (a *ACL) PolicyTemplateRead(args *structs.ACLPolicyTemplateGetRequest, reply *structs.ACLPolicyTemplateResponse) error {
+	if err := a.aclPreCheck(); err != nil {
+		return err
+	}
+
+	if err := a.srv.validateEnterpriseRequest(&args.EnterpriseMeta, false); err != nil {
+		return err
+	}
+
+	if done, err := a.srv.ForwardRPC("ACL.PolicyTemplateRead", args, reply); done {
+		return err
+	}
+
+	var authzContext acl.AuthorizerContext
+	if authz, err := a.srv.ResolveTokenAndDefaultMeta(args.Token, &args.EnterpriseMeta, &authzContext); err != nil {
+		return err
+	} else if err := authz.ToAllowAuthorizer().ACLReadAllowed(&authzContext); err != nil {
+		return err
+	}
+
+	return a.srv.blockingQuery(&args.QueryOptions, &reply.QueryMeta,
+		func(ws memdb.WatchSet, state *state.Store) error {
+			var (
+				index    uint64
+				template *structs.ACLPolicyTemplate
+				err      error
+			)
+			if args.PolicyTemplateID != "" {
+				index, template, err = state.ACLPolicyTemplateGetByID(ws, args.PolicyTemplateID, &args.EnterpriseMeta)
+			} else {
+				index, template, err = state.ACLPolicyTemplateGetByName(ws, args.PolicyTemplateName, &args.EnterpriseMeta)
+			}
+
+			if err != nil {
+				return err
+			}
+
+			reply.Index, reply.PolicyTemplate = index, template
+			if template == nil {
+				return errNotFound
+			}
+			return nil
+		})
+}
+
+func (a *ACL) PolicyTemplateSet(args *structs.ACLPolicyTemplateSetRequest, reply *structs.ACLPolicyTemplate) error {
+	if err := a.aclPreCheck(); err != nil {
+		return err
+	}
+
+	if err := a.srv.validateEnterpriseRequest(&args.PolicyTemplate.EnterpriseMeta, true); err != nil {
+		return err
+	}
+
+	if !a.srv.InPrimaryDatacenter() {
+		args.Datacenter = a.srv.config.PrimaryDatacenter
+	}
+
+	if done, err := a.srv.ForwardRPC("ACL.PolicyTemplateSet", args, reply); done {
+		return err
+	}
+
+	defer metrics.MeasureSince([]string{"acl", "policytemplate", "upsert"}, time.Now())
+
+	// Verify token is permitted to modify ACLs
+	var authzContext acl.AuthorizerContext
+
+	if authz, err := a.srv.ResolveTokenAndDefaultMeta(args.Token, &args.PolicyTemplate.EnterpriseMeta, &authzContext); err != nil {
+		return err
+	} else if err := authz.ToAllowAuthorizer().ACLWriteAllowed(&authzContext); err != nil {
+		return err
+	}
+
+	template := &args.PolicyTemplate
+	state := a.srv.fsm.State()
+
+	// ensure a name is set
+	if template.Name == "" {
+		return fmt.Errorf("Invalid Policy Template: no Name is set")
+	}
+
+	if err := acl.ValidatePolicyName(template.Name); err != nil {
+		return err
+	}
+
+	_, nameMatch, err := state.ACLPolicyTemplateGetByName(nil, template.Name, &template.EnterpriseMeta)
+	if err != nil {
+		return fmt.Errorf("acl policy template lookup by name failed: %v", err)
+	}
+
+	if template.ID == "" {
+		// with no template ID one will be generated
+		template.ID, err = lib.GenerateUUID(a.srv.checkPolicyTemplateUUID)
+		if err != nil {
+			return err
+		}
+
+		// validate the name is unique
+		if nameMatch != nil {
+			return fmt.Errorf("Invalid Policy Template: A Policy Template with Name %q already exists", template.Name)
+		}
+	} else {
+		if _, err := uuid.ParseUUID(template.ID); err != nil {
+			return fmt.Errorf("Policy Template ID invalid UUID")
+		}
+
+		// Verify the template exists
+		_, idMatch, err := state.ACLPolicyTemplateGetByID(nil, template.ID, nil)
+		if err != nil {
+			return fmt.Errorf("acl policy template lookup by id failed: %v", err)
+		} else if idMatch == nil {
+			return fmt.Errorf("cannot find policy template %s", template.ID)
+		}
+
+		// Verify that the name isn't changing or that the name is not already used
+		if idMatch.Name != template.Name && nameMatch != nil {
+			return fmt.Errorf("Invalid Policy Template: A policy template with name %q already exists", template.Name)
+		}
+	}
+
+	// validate the rules with placeholder values for the variables
+	placeholders := make(map[string]string)
+	for _, name := range template.Variables() {
+		placeholders[name] = "placeholder"
+	}
+	rules, err := template.Render(placeholders, &template.EnterpriseMeta)
+	if err != nil {
+		return err
+	}
+	if _, err := acl.NewPolicyFromSource(rules, a.srv.aclConfig, template.EnterpriseMeta.ToEnterprisePolicyMeta()); err != nil {
+		return err
+	}
+
+	// calculate the hash for this template
+	template.SetHash(true)
+
+	req := &structs.ACLPolicyTemplateBatchSetRequest{
+		PolicyTemplates: structs.ACLPolicyTemplates{template},
+	}
+
+	_, err = a.srv.raftApply(structs.ACLPolicyTemplateSetType, req)
+	if err != nil {
+		return fmt.Errorf("Failed to apply policy template upsert request: %v", err)
+	}
+
+	// Remove from the cache to prevent stale cache usage
+	a.srv.ACLResolver.cache.RemovePolicyTemplate(template.ID)
+
+	if _, template, err := a.srv.fsm.State().ACLPolicyTemplateGetByID(nil, template.ID, &template.EnterpriseMeta); err == nil && template != nil {
+		*reply = *template
+	}
+
+	return nil
+}
+
+func (a *ACL) PolicyTemplateDelete(args *structs.ACLPolicyTemplateDeleteRequest, reply *string) error {
+	if err := a.aclPreCheck(); err != nil {
+		return err
+	}
+
+	if err := a.srv.validateEnterpriseRequest(&args.EnterpriseMeta, true); err != nil {
+		return err
+	}
+
+	if !a.srv.InPrimaryDatacenter() {
+		args.Datacenter = a.srv.config.PrimaryDatacenter
+	}
+
+	if done, err := a.srv.ForwardRPC("ACL.PolicyTemplateDelete", args, reply); done {
+		return err
+	}
+
+	defer metrics.MeasureSince([]string{"acl", "policytemplate", "delete"}, time.Now())
+
+	// Verify token is permitted to modify ACLs
+	var authzContext acl.AuthorizerContext
+
+	if authz, err := a.srv.ResolveTokenAndDefaultMeta(args.Token, &args.EnterpriseMeta, &authzContext); err != nil {
+		return err
+	} else if err := authz.ToAllowAuthorizer().ACLWriteAllowed(&authzContext); err != nil {
+		return err
+	}
+
+	_, template, err := a.srv.fsm.State().ACLPolicyTemplateGetByID(nil, args.PolicyTemplateID, &args.EnterpriseMeta)
+	if err != nil {
+		return err
+	}
+
+	if template == nil {
+		return fmt.Errorf("policy template does not exist: %w", acl.ErrNotFound)
+	}
+
+	req := structs.ACLPolicyTemplateBatchDeleteRequest{
+		PolicyTemplateIDs: []string{args.PolicyTemplateID},
+	}
+
+	_, err = a.srv.raftApply(structs.ACLPolicyTemplateDeleteType, &req)
+	if err != nil {
+		return fmt.Errorf("Failed to apply policy template delete request: %v", err)
+	}
+
+	a.srv.ACLResolver.cache.RemovePolicyTemplate(template.ID)
+
+	*reply = template.Name
+
+	return nil
+}
+
+func (a *ACL) PolicyTemplateList(args *structs.ACLPolicyTemplateListRequest, reply *structs.ACLPolicyTemplateListResponse) error {
+	if err := a.aclPreCheck(); err != nil {
+		return err
+	}
+
+	if err := a.srv.validateEnterpriseRequest(&args.EnterpriseMeta, false); err != nil {
+		return err
+	}
+
+	if done, err := a.srv.ForwardRPC("ACL.PolicyTemplateList", args, reply); done {
+		return err
+	}
+
+	var authzContext acl.AuthorizerContext
+	if authz, err := a.srv.ResolveTokenAndDefaultMeta(args.Token, &args.EnterpriseMeta, &authzContext); err != nil {
+		return err
+	} else if err := authz.ToAllowAuthorizer().ACLReadAllowed(&authzContext); err != nil {
+		return err
+	}
+
+	return a.srv.blockingQuery(&args.QueryOptions, &reply.QueryMeta,
+		func(ws memdb.WatchSet, state *state.Store) error {
+			index, templates, err := state.ACLPolicyTemplateList(ws, &args.EnterpriseMeta)
+			if err != nil {
+				return err
+			}
+
+			reply.Index, reply.PolicyTemplates = index, templates
+			return nil
+		})
+}
+
+// PolicyTemplateResolve is used to retrieve a subset of the policy templates
+// linked to a given token, directly or through its roles. The template ids in
+// the args simply act as a filter on the templates linked to the token.
+func (a *ACL) PolicyTemplateResolve(args *structs.ACLPolicyTemplateBatchGetRequest, reply *structs.ACLPolicyTemplateBatchResponse) error {
+	if err := a.aclPreCheck(); err != nil {
+		return err
+	}
+
+	if done, err := a.srv.ForwardRPC("ACL.PolicyTemplateResolve", args, reply); done {
+		return err
+	}
+
+	identity, err := a.srv.ACLResolver.resolveIdentityFromToken(args.Token)
+	if err != nil {
+		return err
+	} else if identity == nil {
+		return acl.ErrNotFound
+	}
+
+	roles, err := a.srv.ACLResolver.resolveRolesForIdentity(identity)
+	if err != nil {
+		return err
+	}
+
+	linked := make(map[string]struct{})
+	for _, link := range identity.TemplatedPolicyList() {
+		linked[link.TemplateID] = struct{}{}
+	}
+	for _, role := range roles {
+		for _, link := range role.TemplatedPolicies {
+			linked[link.TemplateID] = struct{}{}
+		}
+	}
+
+	for _, templateID := range args.PolicyTemplateIDs {
+		if _, ok := linked[templateID]; !ok {
+			// send a permission denied to indicate that the request included
+			// template ids not linked to this token
+			return acl.ErrPermissionDenied
+		}
+	}
+
+	_, templates, err := a.srv.fsm.State().ACLPolicyTemplateBatchGet(nil, args.PolicyTemplateIDs)
+	if err != nil {
+		return err
+	}
+	reply.PolicyTemplates = templates
+
+	a.srv.SetQueryMeta(&reply.QueryMeta, args.Token)
+
+	return nil
+}
+
+// TokenUsageUpdate records when tokens were last used. It is called
+// periodically by every server with the usage it observed.
+func (a *ACL) TokenUsageUpdate(args *structs.ACLTokenUsageUpdateRequest, reply *struct{}) error {
+	if err := a.aclPreCheck(); err != nil {
+		return err
+	}
+
+	if done, err := a.srv.ForwardRPC("ACL.TokenUsageUpdate", args, reply); done {
+		return err
+	}
+
+	defer metrics.MeasureSince([]string{"acl", "token", "usage_update"}, time.Now())
+
+	// Verify token is permitted to modify ACLs
+	var authzContext acl.AuthorizerContext
+	if authz, err := a.srv.ResolveTokenAndDefaultMeta(args.Token, nil, &authzContext); err != nil {
+		return err
+	} else if err := authz.ToAllowAuthorizer().ACLWriteAllowed(&authzContext); err != nil {
+		return err
+	}
+
+	for _, usage := range args.Usages {
+		if usage.AccessorID == "" {
+			return fmt.Errorf("Invalid token usage: missing AccessorID")
+		}
+	}
+
+	// We set the "safe to ignore" flag on this update type so old servers
+	// don't crash if they see one of these.
+	t := structs.ACLTokenUsageUpdateType | structs.IgnoreUnknownTypeFlag
+	if _, err := a.srv.raftApply(t, args); err != nil {
+		return fmt.Errorf("Failed to apply token usage update request: %v", err)
+	}
+	return nil
+}
+
 // ReplicationStatus is used to retrieve the current ACL replication status.
 func (a *ACL) ReplicationStatus(args *structs.DCSpecificRequest,
 	reply *structs.ACLReplicationStatus) error {
@@ -1354,6 +1772,30 @@ func (a *ACL) RoleSet(args *structs.ACLRoleSetRequest, reply *structs.ACLRole) e
 	}
 	role.NodeIdentities = role.NodeIdentities.Deduplicate()
 
+	// Validate all the templated policies and convert template names to IDs
+	for _, link := range role.TemplatedPolicies {
+		var template *structs.ACLPolicyTemplate
+		if link.TemplateID == "" {
+			_, template, err = state.ACLPolicyTemplateGetByName(nil, link.TemplateName, &role.EnterpriseMeta)
+		} else {
+			_, template, err = state.ACLPolicyTemplateGetByID(nil, link.TemplateID, &role.EnterpriseMeta)
+		}
+		if err != nil {
+			return fmt.Errorf("Error looking up policy template %q: %v", link.TemplateID+link.TemplateName, err)
+		}
+		if template == nil {
+			return fmt.Errorf("No such ACL policy template %q", link.TemplateID+link.TemplateName)
+		}
+		if err := template.ValidateVariables(link.Variables); err != nil {
+			return err
+		}
+
+		// Do not store the template name within raft/memdb as the template could be renamed in the future.
+		link.TemplateID = template.ID
+		link.TemplateName = ""
+	}
+	role.TemplatedPolicies = role.TemplatedPolicies.Deduplicate()
+
 	// calculate the hash for this role
 	role.SetHash(true)
 
@@ -1671,6 +2113,7 @@ func (a *ACL) BindingRuleSet(args *structs.ACLBindingRuleSetRequest, reply *stru
 	case structs.BindingRuleBindTypeService:
 	case structs.BindingRuleBindTypeNode:
 	case structs.BindingRuleBindTypeRole:
+	case structs.BindingRuleBindTypeTemplatedPolicy:
 	default:
 		return fmt.Errorf("Invalid Binding Rule: unknown BindType %q", rule.BindType)
 	}
@@ -1681,6 +2124,12 @@ func (a *ACL) BindingRuleSet(args *structs.ACLBindingRuleSetRequest, reply *stru
 		return fmt.Errorf("Invalid Binding Rule: invalid BindName")
 	}
 
+	if valid, err := auth.IsValidBindVars(rule.BindType, rule.BindVars, blankID.ProjectedVarNames()); err != nil {
+		return fmt.Errorf("Invalid Binding Rule: invalid BindVars: %v", err)
+	} else if !valid {
+		return fmt.Errorf("Invalid Binding Rule: BindVars are only allowed with the %q BindType", structs.BindingRuleBindTypeTemplatedPolicy)
+	}
+
 	req := &structs.ACLBindingRuleBatchSetRequest{
 		BindingRules: structs.ACLBindingRules{rule},
 	}
@@ -1914,6 +2363,10 @@ func (a *ACL) AuthMethodSet(args *structs.ACLAuthMethodSetRequest, reply *struct
 		return fmt.Errorf("Invalid Auth Method: TokenLocality should be one of 'local' or 'global'")
 	}
 
+	if err := ssoauth.InlineJWKSFile(method); err != nil {
+		return fmt.Errorf("Invalid Auth Method: %v", err)
+	}
+
 	// Instantiate a validator but do not cache it yet. This will validate the
 	// configuration.
 	validator, err := authmethod.NewValidator(a.srv.logger, method)
@@ -2155,3 +2608,54 @@ func (a *ACL) Authorize(args *structs.RemoteACLAuthorizationRequest, reply *[]st
 	*reply = responses
 	return nil
 }
+
+// AuthorizeExplain returns the decisions of the authorizations along with the
+// policy and rule that produced them, for the token of the request or the
+// token with the given accessor ID.
+func (a *ACL) AuthorizeExplain(args *structs.ACLAuthorizeExplainRequest, reply *[]structs.ACLAuthorizationExplanation) error {
+	if err := a.aclPreCheck(); err != nil {
+		return err
+	}
+
+	if err := a.srv.validateEnterpriseRequest(&args.EnterpriseMeta, false); err != nil {
+		return err
+	}
+
+	// Tokens looked up by accessor may only be known by the primary
+	// datacenter, as in ACL.TokenRead.
+	if args.AccessorID != "" && !a.srv.LocalTokensEnabled() {
+		args.Datacenter = a.srv.config.PrimaryDatacenter
+	}
+
+	if done, err := a.srv.ForwardRPC("ACL.AuthorizeExplain", args, reply); done {
+		return err
+	}
+
+	secretID := args.Token
+	if args.AccessorID != "" {
+		var authzContext acl.AuthorizerContext
+		authz, err := a.srv.ResolveTokenAndDefaultMeta(args.Token, &args.EnterpriseMeta, &authzContext)
+		if err != nil {
+			return err
+		} else if err := authz.ToAllowAuthorizer().ACLReadAllowed(&authzContext); err != nil {
+			return err
+		}
+
+		_, token, err := a.srv.fsm.State().ACLTokenGetByAccessor(nil, args.AccessorID, &args.EnterpriseMeta)
+		if err != nil {
+			return err
+		}
+		if token == nil || token.IsExpired(time.Now()) {
+			return fmt.Errorf("token %q: %w", args.AccessorID, acl.ErrNotFound)
+		}
+		secretID = token.SecretID
+	}
+
+	explanations, err := a.srv.ExplainAuthorizations(secretID, args.Requests)
+	if err != nil {
+		return err
+	}
+
+	*reply = explanations
+	return nil
+}
@@ -518,6 +518,134 @@ func TestACLEndpoint_TokenClone(t *testing.T) {
 	})
 }
 
+func TestACLEndpoint_TokenCreateChild(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	_, srv, codec := testACLServerWithConfig(t, nil, false)
+	waitForLeaderEstablishment(t, srv)
+
+	p1, err := upsertTestPolicy(codec, TestDefaultInitialManagementToken, "dc1")
+	require.NoError(t, err)
+	p2, err := upsertTestPolicy(codec, TestDefaultInitialManagementToken, "dc1")
+	require.NoError(t, err)
+
+	// The parent has no ACL privileges at all.
+	parent, err := upsertTestToken(codec, TestDefaultInitialManagementToken, "dc1", func(t *structs.ACLToken) {
+		t.Policies = []structs.ACLTokenPolicyLink{{ID: p1.ID}}
+	})
+	require.NoError(t, err)
+
+	createChild := func(token structs.ACLToken, secretID string) (*structs.ACLToken, error) {
+		req := structs.ACLTokenSetRequest{
+			Datacenter:   "dc1",
+			ACLToken:     token,
+			WriteRequest: structs.WriteRequest{Token: secretID},
+		}
+		var out structs.ACLToken
+		if err := msgpackrpc.CallWithCodec(codec, "ACL.TokenCreateChild", &req, &out); err != nil {
+			return nil, err
+		}
+		return &out, nil
+	}
+
+	t.Run("subset of the parent", func(t *testing.T) {
+		child, err := createChild(structs.ACLToken{
+			Description:   "child",
+			Policies:      []structs.ACLTokenPolicyLink{{ID: p1.ID}},
+			ExpirationTTL: 10 * time.Minute,
+		}, parent.SecretID)
+		require.NoError(t, err)
+		require.Equal(t, parent.AccessorID, child.ParentAccessorID)
+		require.NotNil(t, child.ExpirationTime)
+	})
+
+	t.Run("not a subset of the parent", func(t *testing.T) {
+		_, err := createChild(structs.ACLToken{
+			Policies:      []structs.ACLTokenPolicyLink{{ID: p2.ID}},
+			ExpirationTTL: 10 * time.Minute,
+		}, parent.SecretID)
+		require.Error(t, err)
+		require.True(t, acl.IsErrPermissionDenied(err), "unexpected error: %v", err)
+	})
+
+	t.Run("unknown parent", func(t *testing.T) {
+		_, err := createChild(structs.ACLToken{
+			Policies:      []structs.ACLTokenPolicyLink{{ID: p1.ID}},
+			ExpirationTTL: 10 * time.Minute,
+		}, "7fc9bdd1-6c2c-4d8a-8b0a-9c9e2dcd4b61")
+		require.Error(t, err)
+	})
+
+	t.Run("deleted with the parent", func(t *testing.T) {
+		child, err := createChild(structs.ACLToken{
+			Policies:      []structs.ACLTokenPolicyLink{{ID: p1.ID}},
+			ExpirationTTL: 10 * time.Minute,
+		}, parent.SecretID)
+		require.NoError(t, err)
+
+		req := structs.ACLTokenDeleteRequest{
+			Datacenter:   "dc1",
+			TokenID:      parent.AccessorID,
+			WriteRequest: structs.WriteRequest{Token: TestDefaultInitialManagementToken},
+		}
+		var resp string
+		require.NoError(t, msgpackrpc.CallWithCodec(codec, "ACL.TokenDelete", &req, &resp))
+
+		_, token, err := srv.fsm.State().ACLTokenGetByAccessor(nil, child.AccessorID, nil)
+		require.NoError(t, err)
+		require.Nil(t, token)
+	})
+}
+
+func TestACLEndpoint_TokenCreateChild_Secondary(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	_, s1, codec1 := testACLServerWithConfig(t, nil, false)
+	waitForLeaderEstablishment(t, s1)
+
+	_, s2, codec2 := testACLServerWithConfig(t, func(c *Config) {
+		c.Datacenter = "dc2"
+		// disable local tokens
+		c.ACLTokenReplication = false
+	}, true)
+	waitForLeaderEstablishment(t, s2)
+
+	joinWAN(t, s2, s1)
+
+	policy, err := upsertTestPolicy(codec1, TestDefaultInitialManagementToken, "dc1")
+	require.NoError(t, err)
+	parent, err := upsertTestToken(codec1, TestDefaultInitialManagementToken, "dc1", func(t *structs.ACLToken) {
+		t.Policies = []structs.ACLTokenPolicyLink{{ID: policy.ID}}
+	})
+	require.NoError(t, err)
+
+	// The global parent is unknown to dc2, so the child is created in the
+	// primary datacenter.
+	req := structs.ACLTokenSetRequest{
+		Datacenter: "dc2",
+		ACLToken: structs.ACLToken{
+			Policies:      []structs.ACLTokenPolicyLink{{ID: policy.ID}},
+			ExpirationTTL: 10 * time.Minute,
+		},
+		WriteRequest: structs.WriteRequest{Token: parent.SecretID},
+	}
+	var child structs.ACLToken
+	require.NoError(t, msgpackrpc.CallWithCodec(codec2, "ACL.TokenCreateChild", &req, &child))
+	require.Equal(t, parent.AccessorID, child.ParentAccessorID)
+
+	_, token, err := s1.fsm.State().ACLTokenGetByAccessor(nil, child.AccessorID, nil)
+	require.NoError(t, err)
+	require.NotNil(t, token)
+}
+
 func TestACLEndpoint_TokenSet(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
@@ -2386,6 +2514,108 @@ func TestACLEndpoint_PolicyResolve(t *testing.T) {
 	require.ElementsMatch(t, gatherIDs(t, resp.Policies), policies)
 }
 
+func TestACLEndpoint_PolicyTemplate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	_, srv, codec := testACLServerWithConfig(t, nil, false)
+	waitForLeaderEstablishment(t, srv)
+	aclEp := ACL{srv: srv}
+
+	var template structs.ACLPolicyTemplate
+
+	t.Run("Create it", func(t *testing.T) {
+		req := structs.ACLPolicyTemplateSetRequest{
+			Datacenter: "dc1",
+			PolicyTemplate: structs.ACLPolicyTemplate{
+				Name:  "kv-owner",
+				Rules: `key_prefix "${app}/" { policy = "write" }`,
+			},
+			WriteRequest: structs.WriteRequest{Token: TestDefaultInitialManagementToken},
+		}
+		require.NoError(t, aclEp.PolicyTemplateSet(&req, &template))
+		require.NotEmpty(t, template.ID)
+
+		readReq := structs.ACLPolicyTemplateGetRequest{
+			Datacenter:         "dc1",
+			PolicyTemplateName: "kv-owner",
+			QueryOptions:       structs.QueryOptions{Token: TestDefaultInitialManagementToken},
+		}
+		var readResp structs.ACLPolicyTemplateResponse
+		require.NoError(t, aclEp.PolicyTemplateRead(&readReq, &readResp))
+		require.Equal(t, template.ID, readResp.PolicyTemplate.ID)
+	})
+
+	t.Run("Invalid rules", func(t *testing.T) {
+		req := structs.ACLPolicyTemplateSetRequest{
+			Datacenter: "dc1",
+			PolicyTemplate: structs.ACLPolicyTemplate{
+				Name:  "broken",
+				Rules: `key_prefix "${app}/" { policy = "sudo" }`,
+			},
+			WriteRequest: structs.WriteRequest{Token: TestDefaultInitialManagementToken},
+		}
+		var resp structs.ACLPolicyTemplate
+		require.Error(t, aclEp.PolicyTemplateSet(&req, &resp))
+	})
+
+	t.Run("Link with missing variables", func(t *testing.T) {
+		_, err := upsertTestToken(codec, TestDefaultInitialManagementToken, "dc1", func(token *structs.ACLToken) {
+			token.TemplatedPolicies = structs.ACLTemplatedPolicies{
+				{TemplateName: "kv-owner"},
+			}
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("Resolve a linking token", func(t *testing.T) {
+		token, err := upsertTestToken(codec, TestDefaultInitialManagementToken, "dc1", func(token *structs.ACLToken) {
+			token.Policies = nil
+			token.TemplatedPolicies = structs.ACLTemplatedPolicies{
+				{TemplateName: "kv-owner", Variables: map[string]string{"app": "web"}},
+			}
+		})
+		require.NoError(t, err)
+		require.Len(t, token.TemplatedPolicies, 1)
+		require.Equal(t, template.ID, token.TemplatedPolicies[0].TemplateID)
+
+		authz, err := srv.ResolveToken(token.SecretID)
+		require.NoError(t, err)
+		require.Equal(t, acl.Allow, authz.KeyWrite("web/config", nil))
+		require.Equal(t, acl.Deny, authz.KeyWrite("db/config", nil))
+
+		resolveReq := structs.ACLPolicyTemplateBatchGetRequest{
+			Datacenter:        "dc1",
+			PolicyTemplateIDs: []string{template.ID},
+			QueryOptions:      structs.QueryOptions{Token: token.SecretID},
+		}
+		var resolveResp structs.ACLPolicyTemplateBatchResponse
+		require.NoError(t, aclEp.PolicyTemplateResolve(&resolveReq, &resolveResp))
+		require.Len(t, resolveResp.PolicyTemplates, 1)
+	})
+
+	t.Run("Delete it", func(t *testing.T) {
+		req := structs.ACLPolicyTemplateDeleteRequest{
+			Datacenter:       "dc1",
+			PolicyTemplateID: template.ID,
+			WriteRequest:     structs.WriteRequest{Token: TestDefaultInitialManagementToken},
+		}
+		var resp string
+		require.NoError(t, aclEp.PolicyTemplateDelete(&req, &resp))
+
+		listReq := structs.ACLPolicyTemplateListRequest{
+			Datacenter:   "dc1",
+			QueryOptions: structs.QueryOptions{Token: TestDefaultInitialManagementToken},
+		}
+		var listResp structs.ACLPolicyTemplateListResponse
+		require.NoError(t, aclEp.PolicyTemplateList(&listReq, &listResp))
+		require.Empty(t, listResp.PolicyTemplates)
+	})
+}
+
 func TestACLEndpoint_RoleRead(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
@@ -5237,6 +5467,75 @@ func TestACLEndpoint_Login_jwt(t *testing.T) {
 	}
 }
 
+func TestACLEndpoint_Login_jwtJWKSFile(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	_, srv, codec := testACLServerWithConfig(t, nil, false)
+	waitForLeaderEstablishment(t, srv)
+
+	aclEp := ACL{srv: srv}
+
+	oidcServer := oidcauthtest.Start(t)
+	pubKey, privKey := oidcServer.SigningKeys()
+	jwks, err := oidcauthtest.JWKS(pubKey)
+	require.NoError(t, err)
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(jwksFile, []byte(jwks), 0600))
+
+	method, err := upsertTestCustomizedAuthMethod(codec, TestDefaultInitialManagementToken, "dc1", func(method *structs.ACLAuthMethod) {
+		method.Type = "jwt"
+		method.Config = map[string]interface{}{
+			"JWTSupportedAlgs": []string{"ES256"},
+			"BoundAudiences":   []string{"https://consul.test"},
+			"JWKSFile":         jwksFile,
+		}
+	})
+	require.NoError(t, err)
+
+	// The key set is stored with the auth method so that every server can
+	// validate logins.
+	methodResp, err := retrieveTestAuthMethod(codec, TestDefaultInitialManagementToken, "dc1", method.Name)
+	require.NoError(t, err)
+	require.Equal(t, jwks, methodResp.AuthMethod.Config["JWKS"])
+	require.NotContains(t, methodResp.AuthMethod.Config, "JWKSFile")
+
+	require.NoError(t, os.Remove(jwksFile))
+
+	cl := jwt.Claims{
+		Subject:   "r3qXcK2bix9eFECzsU3Sbmh0K16fatW6@clients",
+		Audience:  jwt.Audience{"https://consul.test"},
+		NotBefore: jwt.NewNumericDate(time.Now().Add(-5 * time.Second)),
+		Expiry:    jwt.NewNumericDate(time.Now().Add(5 * time.Second)),
+	}
+	jwtData, err := oidcauthtest.SignJWT(privKey, cl, struct{}{})
+	require.NoError(t, err)
+
+	req := structs.ACLLoginRequest{
+		Auth: &structs.ACLLoginParams{
+			AuthMethod:  method.Name,
+			BearerToken: jwtData,
+		},
+		Datacenter: "dc1",
+	}
+	resp := structs.ACLToken{}
+
+	// The token is valid, there are just no binding rules.
+	testutil.RequireErrorContains(t, aclEp.Login(&req, &resp), "Permission denied")
+
+	// A missing file is reported when the auth method is written.
+	_, err = upsertTestCustomizedAuthMethod(codec, TestDefaultInitialManagementToken, "dc1", func(method *structs.ACLAuthMethod) {
+		method.Type = "jwt"
+		method.Config = map[string]interface{}{
+			"JWKSFile": jwksFile,
+		}
+	})
+	testutil.RequireErrorContains(t, err, "error reading JWKSFile")
+}
+
 func TestACLEndpoint_Logout(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
@@ -5322,6 +5621,106 @@ func TestACLEndpoint_Logout(t *testing.T) {
 	})
 }
 
+func TestACLEndpoint_AuthorizeExplain(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	_, srv, codec := testACLServerWithConfig(t, nil, false)
+	waitForLeaderEstablishment(t, srv)
+
+	readAll, err := upsertTestPolicyWithRules(codec, TestDefaultInitialManagementToken, "dc1", `key_prefix "" { policy = "read" }`)
+	require.NoError(t, err)
+	app, err := upsertTestPolicyWithRules(codec, TestDefaultInitialManagementToken, "dc1", `
+key_prefix "app/" { policy = "write" }
+key "app/secret" { policy = "deny" }`)
+	require.NoError(t, err)
+
+	token, err := upsertTestToken(codec, TestDefaultInitialManagementToken, "dc1", func(token *structs.ACLToken) {
+		token.Policies = []structs.ACLTokenPolicyLink{{ID: readAll.ID}, {ID: app.ID}}
+	})
+	require.NoError(t, err)
+
+	aclEp := ACL{srv: srv}
+	requests := []structs.ACLAuthorizationRequest{
+		{Resource: "key", Segment: "app/config", Access: "write"},
+		{Resource: "key", Segment: "app/secret", Access: "read"},
+		{Resource: "key", Segment: "other", Access: "read"},
+		{Resource: "node", Segment: "node1", Access: "read"},
+	}
+
+	t.Run("token of the request", func(t *testing.T) {
+		args := structs.ACLAuthorizeExplainRequest{
+			Datacenter:   "dc1",
+			Requests:     requests,
+			QueryOptions: structs.QueryOptions{Token: token.SecretID},
+		}
+		var out []structs.ACLAuthorizationExplanation
+		require.NoError(t, aclEp.AuthorizeExplain(&args, &out))
+		require.Len(t, out, 4)
+
+		require.True(t, out[0].Allow)
+		require.Equal(t, app.ID, out[0].PolicyID)
+		require.Equal(t, &acl.RuleMatch{Type: "key_prefix", Name: "app/", Policy: "write"}, out[0].Rule)
+		require.Len(t, out[0].Matches, 2)
+
+		require.False(t, out[1].Allow)
+		require.Equal(t, app.ID, out[1].PolicyID)
+		require.Equal(t, &acl.RuleMatch{Type: "key", Name: "app/secret", Policy: "deny"}, out[1].Rule)
+		require.Equal(t, `rule key "app/secret" { policy = "deny" } of policy "`+app.Name+`"`, out[1].Reason)
+
+		require.True(t, out[2].Allow)
+		require.Equal(t, readAll.ID, out[2].PolicyID)
+		require.Len(t, out[2].Matches, 1)
+
+		require.False(t, out[3].Allow)
+		require.Nil(t, out[3].Rule)
+		require.Empty(t, out[3].Matches)
+		require.Contains(t, out[3].Reason, "the default policy")
+	})
+
+	t.Run("token by accessor", func(t *testing.T) {
+		args := structs.ACLAuthorizeExplainRequest{
+			Datacenter:   "dc1",
+			AccessorID:   token.AccessorID,
+			Requests:     requests[:1],
+			QueryOptions: structs.QueryOptions{Token: TestDefaultInitialManagementToken},
+		}
+		var out []structs.ACLAuthorizationExplanation
+		require.NoError(t, aclEp.AuthorizeExplain(&args, &out))
+		require.Len(t, out, 1)
+		require.True(t, out[0].Allow)
+		require.Equal(t, app.ID, out[0].PolicyID)
+	})
+
+	t.Run("token by accessor requires acl read", func(t *testing.T) {
+		args := structs.ACLAuthorizeExplainRequest{
+			Datacenter:   "dc1",
+			AccessorID:   token.AccessorID,
+			Requests:     requests[:1],
+			QueryOptions: structs.QueryOptions{Token: token.SecretID},
+		}
+		var out []structs.ACLAuthorizationExplanation
+		err := aclEp.AuthorizeExplain(&args, &out)
+		require.True(t, acl.IsErrPermissionDenied(err), "unexpected error: %v", err)
+	})
+
+	t.Run("management token", func(t *testing.T) {
+		args := structs.ACLAuthorizeExplainRequest{
+			Datacenter:   "dc1",
+			Requests:     requests[:1],
+			QueryOptions: structs.QueryOptions{Token: TestDefaultInitialManagementToken},
+		}
+		var out []structs.ACLAuthorizationExplanation
+		require.NoError(t, aclEp.AuthorizeExplain(&args, &out))
+		require.True(t, out[0].Allow)
+		require.Equal(t, structs.ACLPolicyGlobalManagementID, out[0].PolicyID)
+		require.Equal(t, &acl.RuleMatch{Type: "key_prefix", Name: "", Policy: "write"}, out[0].Rule)
+	})
+}
+
 func gatherIDs(t *testing.T, v interface{}) []string {
 	t.Helper()
 
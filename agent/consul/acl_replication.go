@@ -126,6 +126,26 @@ func (s *Server) fetchACLRoles(lastRemoteIndex uint64) (*structs.ACLRoleListResp
 	return &response, nil
 }
 
+func (s *Server) fetchACLPolicyTemplates(lastRemoteIndex uint64) (*structs.ACLPolicyTemplateListResponse, error) {
+	defer metrics.MeasureSince([]string{"leader", "replication", "acl", "policytemplate", "fetch"}, time.Now())
+
+	req := structs.ACLPolicyTemplateListRequest{
+		Datacenter: s.config.PrimaryDatacenter,
+		QueryOptions: structs.QueryOptions{
+			AllowStale:    true,
+			MinQueryIndex: lastRemoteIndex,
+			Token:         s.tokens.ReplicationToken(),
+		},
+		EnterpriseMeta: *s.replicationEnterpriseMeta(),
+	}
+
+	var response structs.ACLPolicyTemplateListResponse
+	if err := s.RPC(context.Background(), "ACL.PolicyTemplateList", &req, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
 func (s *Server) fetchACLPoliciesBatch(policyIDs []string) (*structs.ACLPolicyBatchResponse, error) {
 	req := structs.ACLPolicyBatchGetRequest{
 		Datacenter: s.config.PrimaryDatacenter,
@@ -371,6 +391,11 @@ func (s *Server) replicateACLRoles(ctx context.Context, logger hclog.Logger, las
 	return s.replicateACLType(ctx, logger, tr, lastRemoteIndex)
 }
 
+func (s *Server) replicateACLPolicyTemplates(ctx context.Context, logger hclog.Logger, lastRemoteIndex uint64) (uint64, bool, error) {
+	tr := &aclPolicyTemplateReplicator{}
+	return s.replicateACLType(ctx, logger, tr, lastRemoteIndex)
+}
+
 func (s *Server) replicateACLType(ctx context.Context, logger hclog.Logger, tr aclTypeReplicator, lastRemoteIndex uint64) (uint64, bool, error) {
 	lenRemote, remoteIndex, err := tr.FetchRemote(s, lastRemoteIndex)
 	if err != nil {
@@ -500,6 +525,8 @@ func (s *Server) updateACLReplicationStatusIndex(replicationType structs.ACLRepl
 		s.aclReplicationStatus.ReplicatedIndex = index
 	case structs.ACLReplicateRoles:
 		s.aclReplicationStatus.ReplicatedRoleIndex = index
+	case structs.ACLReplicatePolicyTemplates:
+		s.aclReplicationStatus.ReplicatedPolicyTemplateIndex = index
 	default:
 		panic("unknown replication type: " + replicationType.SingularNoun())
 	}
@@ -528,9 +555,9 @@ func (s *Server) updateACLReplicationStatusRunning(replicationType structs.ACLRe
 	// The running state represents which type of overall replication has been
 	// configured. Though there are various types of internal plumbing for acl
 	// replication, to the end user there are only 3 distinctly configurable
-	// variants: legacy, policy, token. Roles replicate with policies so we
-	// round that up here.
-	if replicationType == structs.ACLReplicateRoles {
+	// variants: legacy, policy, token. Roles and policy templates replicate
+	// with policies so we round that up here.
+	if replicationType == structs.ACLReplicateRoles || replicationType == structs.ACLReplicatePolicyTemplates {
 		replicationType = structs.ACLReplicatePolicies
 	}
 
@@ -624,6 +624,122 @@ func TestACLReplication_Policies(t *testing.T) {
 	})
 }
 
+func TestACLReplication_PolicyTemplates(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	dir1, s1 := testServerWithConfig(t, func(c *Config) {
+		c.PrimaryDatacenter = "dc1"
+		c.ACLsEnabled = true
+		c.ACLInitialManagementToken = "root"
+	})
+	defer os.RemoveAll(dir1)
+	defer s1.Shutdown()
+	testrpc.WaitForLeader(t, s1.RPC, "dc1")
+
+	dir2, s2 := testServerWithConfig(t, func(c *Config) {
+		c.Datacenter = "dc2"
+		c.PrimaryDatacenter = "dc1"
+		c.ACLsEnabled = true
+		c.ACLTokenReplication = false
+		c.ACLReplicationRate = 100
+		c.ACLReplicationBurst = 100
+		c.ACLReplicationApplyLimit = 1000000
+	})
+	s2.tokens.UpdateReplicationToken("root", tokenStore.TokenSourceConfig)
+	testrpc.WaitForLeader(t, s2.RPC, "dc2")
+	defer os.RemoveAll(dir2)
+	defer s2.Shutdown()
+
+	// Try to join.
+	joinWAN(t, s2, s1)
+	testrpc.WaitForLeader(t, s1.RPC, "dc1")
+	testrpc.WaitForLeader(t, s1.RPC, "dc2", testrpc.WithToken("root"))
+	waitForNewACLReplication(t, s2, structs.ACLReplicatePolicies, 1, 0, 0)
+
+	// Create a bunch of new templates
+	var templates structs.ACLPolicyTemplates
+	for i := 0; i < 50; i++ {
+		arg := structs.ACLPolicyTemplateSetRequest{
+			Datacenter: "dc1",
+			PolicyTemplate: structs.ACLPolicyTemplate{
+				Name:        fmt.Sprintf("template-%d", i),
+				Description: fmt.Sprintf("template-%d", i),
+				Rules:       fmt.Sprintf(`key_prefix "${app}/%d/" { policy = "read" }`, i),
+			},
+			WriteRequest: structs.WriteRequest{Token: "root"},
+		}
+		var template structs.ACLPolicyTemplate
+		require.NoError(t, s1.RPC(context.Background(), "ACL.PolicyTemplateSet", &arg, &template))
+		templates = append(templates, &template)
+	}
+
+	checkSame := func(t *retry.R) {
+		index, remote, err := s1.fsm.State().ACLPolicyTemplateList(nil, nil)
+		require.NoError(t, err)
+		_, local, err := s2.fsm.State().ACLPolicyTemplateList(nil, nil)
+		require.NoError(t, err)
+
+		require.Len(t, local, len(remote))
+		for i, template := range remote {
+			require.Equal(t, template.Hash, local[i].Hash)
+		}
+
+		s2.aclReplicationStatusLock.RLock()
+		status := s2.aclReplicationStatus
+		s2.aclReplicationStatusLock.RUnlock()
+
+		require.True(t, status.Enabled)
+		require.True(t, status.Running)
+		require.Equal(t, status.ReplicationType, structs.ACLReplicatePolicies)
+		require.Equal(t, status.ReplicatedPolicyTemplateIndex, index)
+		require.Equal(t, status.SourceDatacenter, "dc1")
+	}
+	// Wait for the replica to converge.
+	retry.Run(t, func(r *retry.R) {
+		checkSame(r)
+	})
+
+	// Update those templates
+	for i := 0; i < 50; i++ {
+		arg := structs.ACLPolicyTemplateSetRequest{
+			Datacenter: "dc1",
+			PolicyTemplate: structs.ACLPolicyTemplate{
+				ID:          templates[i].ID,
+				Name:        fmt.Sprintf("template-%d-modified", i),
+				Description: fmt.Sprintf("template-%d-modified", i),
+				Rules:       templates[i].Rules,
+			},
+			WriteRequest: structs.WriteRequest{Token: "root"},
+		}
+		var template structs.ACLPolicyTemplate
+		require.NoError(t, s1.RPC(context.Background(), "ACL.PolicyTemplateSet", &arg, &template))
+	}
+
+	// Wait for the replica to converge.
+	retry.Run(t, func(r *retry.R) {
+		checkSame(r)
+	})
+
+	for _, template := range templates {
+		arg := structs.ACLPolicyTemplateDeleteRequest{
+			Datacenter:       "dc1",
+			PolicyTemplateID: template.ID,
+			WriteRequest:     structs.WriteRequest{Token: "root"},
+		}
+
+		var dontCare string
+		require.NoError(t, s1.RPC(context.Background(), "ACL.PolicyTemplateDelete", &arg, &dontCare))
+	}
+
+	// Wait for the replica to converge.
+	retry.Run(t, func(r *retry.R) {
+		checkSame(r)
+	})
+}
+
 func TestACLReplication_TokensRedacted(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
@@ -337,3 +337,122 @@ func (r *aclRoleReplicator) UpdateLocalBatch(ctx context.Context, srv *Server, s
 
 	return err
 }
+
+////////////////////////////////
+
+type aclPolicyTemplateReplicator struct {
+	local   structs.ACLPolicyTemplates
+	remote  structs.ACLPolicyTemplates
+	updated []*structs.ACLPolicyTemplate
+}
+
+var _ aclTypeReplicator = (*aclPolicyTemplateReplicator)(nil)
+
+func (r *aclPolicyTemplateReplicator) Type() structs.ACLReplicationType {
+	return structs.ACLReplicatePolicyTemplates
+}
+func (r *aclPolicyTemplateReplicator) SingularNoun() string { return "policy template" }
+func (r *aclPolicyTemplateReplicator) PluralNoun() string   { return "policy templates" }
+
+func (r *aclPolicyTemplateReplicator) FetchRemote(srv *Server, lastRemoteIndex uint64) (int, uint64, error) {
+	r.remote = nil
+
+	remote, err := srv.fetchACLPolicyTemplates(lastRemoteIndex)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	r.remote = remote.PolicyTemplates
+	return len(remote.PolicyTemplates), remote.QueryMeta.Index, nil
+}
+
+func (r *aclPolicyTemplateReplicator) FetchLocal(srv *Server) (int, uint64, error) {
+	r.local = nil
+
+	idx, local, err := srv.fsm.State().ACLPolicyTemplateList(nil, srv.replicationEnterpriseMeta())
+	if err != nil {
+		return 0, 0, err
+	}
+
+	r.local = local
+	return len(local), idx, nil
+}
+
+func (r *aclPolicyTemplateReplicator) SortState() (int, int) {
+	r.local.Sort()
+	r.remote.Sort()
+
+	return len(r.local), len(r.remote)
+}
+func (r *aclPolicyTemplateReplicator) LocalMeta(i int) (id string, modIndex uint64, hash []byte) {
+	v := r.local[i]
+	return v.ID, v.ModifyIndex, v.Hash
+}
+func (r *aclPolicyTemplateReplicator) RemoteMeta(i int) (id string, modIndex uint64, hash []byte) {
+	v := r.remote[i]
+	return v.ID, v.ModifyIndex, v.Hash
+}
+
+func (r *aclPolicyTemplateReplicator) FetchUpdated(srv *Server, updates []string) (int, error) {
+	r.updated = nil
+
+	if len(updates) > 0 {
+		// Like roles, the listing of policy templates has all of their data
+		// so the updates are taken from r.remote instead of a second query.
+		keep := make(map[string]struct{})
+		for _, id := range updates {
+			keep[id] = struct{}{}
+		}
+
+		subset := make([]*structs.ACLPolicyTemplate, 0, len(updates))
+		for _, template := range r.remote {
+			if _, ok := keep[template.ID]; ok {
+				subset = append(subset, template)
+			}
+		}
+
+		if len(subset) != len(keep) { // only possible via programming bug
+			for _, template := range subset {
+				delete(keep, template.ID)
+			}
+			missing := make([]string, 0, len(keep))
+			for id := range keep {
+				missing = append(missing, id)
+			}
+			return 0, fmt.Errorf("policy template replication trying to replicated uncached policy templates with IDs: %v", missing)
+		}
+		r.updated = subset
+	}
+
+	return len(r.updated), nil
+}
+
+func (r *aclPolicyTemplateReplicator) DeleteLocalBatch(srv *Server, batch []string) error {
+	req := structs.ACLPolicyTemplateBatchDeleteRequest{
+		PolicyTemplateIDs: batch,
+	}
+
+	_, err := srv.leaderRaftApply("ACL.PolicyTemplateDelete", structs.ACLPolicyTemplateDeleteType, &req)
+	return err
+}
+
+func (r *aclPolicyTemplateReplicator) LenPendingUpdates() int {
+	return len(r.updated)
+}
+
+func (r *aclPolicyTemplateReplicator) PendingUpdateEstimatedSize(i int) int {
+	return r.updated[i].EstimateSize()
+}
+
+func (r *aclPolicyTemplateReplicator) PendingUpdateIsRedacted(i int) bool {
+	return false
+}
+
+func (r *aclPolicyTemplateReplicator) UpdateLocalBatch(ctx context.Context, srv *Server, start, end int) error {
+	req := structs.ACLPolicyTemplateBatchSetRequest{
+		PolicyTemplates: r.updated[start:end],
+	}
+
+	_, err := srv.leaderRaftApply("ACL.PolicyTemplateSet", structs.ACLPolicyTemplateSetType, &req)
+	return err
+}
@@ -26,12 +26,15 @@ var serverACLCacheConfig *structs.ACLCachesConfig = &structs.ACLCachesConfig{
 	//     enable token replication or be using DC local tokens. In both
 	//     cases resolving the tokens from memdb will avoid the cache
 	//     entirely
+	// 4 - Policy templates are not replicated, so servers outside of the
+	//     primary datacenter resolve them remotely and cache them.
 	//
-	Identities:     10 * 1024,
-	Policies:       0,
-	ParsedPolicies: 512,
-	Authorizers:    1024,
-	Roles:          0,
+	Identities:      10 * 1024,
+	Policies:        0,
+	ParsedPolicies:  512,
+	Authorizers:     1024,
+	Roles:           0,
+	PolicyTemplates: 128,
 }
 
 func (s *Server) checkTokenUUID(id string) (bool, error) {
@@ -67,6 +70,17 @@ func (s *Server) checkPolicyUUID(id string) (bool, error) {
 	return !structs.ACLIDReserved(id), nil
 }
 
+func (s *Server) checkPolicyTemplateUUID(id string) (bool, error) {
+	state := s.fsm.State()
+	if _, template, err := state.ACLPolicyTemplateGetByID(nil, id, nil); err != nil {
+		return false, err
+	} else if template != nil {
+		return false, nil
+	}
+
+	return !structs.ACLIDReserved(id), nil
+}
+
 func (s *Server) checkRoleUUID(id string) (bool, error) {
 	state := s.fsm.State()
 	if _, role, err := state.ACLRoleGetByID(nil, id, nil); err != nil {
@@ -149,6 +163,7 @@ func (s *Server) ResolveIdentityFromToken(token string) (bool, structs.ACLIdenti
 	if err != nil {
 		return true, nil, err
 	} else if aclToken != nil && !aclToken.IsExpired(time.Now()) {
+		s.recordACLTokenUsage(aclToken)
 		return true, aclToken, nil
 	}
 	if aclToken == nil && token == acl.AnonymousTokenSecret && s.InPrimaryDatacenter() {
@@ -198,6 +213,19 @@ func (s *serverACLResolverBackend) ResolveRoleFromID(roleID string) (bool, *stru
 	return s.InPrimaryDatacenter() || index > 0, role, acl.ErrNotFound
 }
 
+func (s *serverACLResolverBackend) ResolvePolicyTemplateFromID(templateID string) (bool, *structs.ACLPolicyTemplate, error) {
+	index, template, err := s.fsm.State().ACLPolicyTemplateGetByID(nil, templateID, nil)
+	if err != nil {
+		return true, nil, err
+	} else if template != nil {
+		return true, template, nil
+	}
+
+	// If the max index of the policy templates table is non-zero then they
+	// have been replicated, until then we may need to allow remote resolution.
+	return s.InPrimaryDatacenter() || index > 0, template, acl.ErrNotFound
+}
+
 func (s *Server) filterACL(token string, subj interface{}) error {
 	return filterACL(s.ACLResolver, token, subj)
 }
@@ -669,6 +669,11 @@ func (d *ACLResolverTestDelegate) ResolveRoleFromID(roleID string) (bool, *struc
 	return testRoleForID(roleID)
 }
 
+func (d *ACLResolverTestDelegate) ResolvePolicyTemplateFromID(templateID string) (bool, *structs.ACLPolicyTemplate, error) {
+	// none of the test identities link policy templates
+	return false, nil, nil
+}
+
 func (d *ACLResolverTestDelegate) RPC(ctx context.Context, method string, args interface{}, reply interface{}) error {
 	switch method {
 	case "ACL.TokenRead":
@@ -0,0 +1,198 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package consul
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+
+	"github.com/hernad/consul/agent/structs"
+)
+
+// aclTokenUsageBatchSize is the maximum number of token usages sent in a
+// single ACL.TokenUsageUpdate request.
+const aclTokenUsageBatchSize = 512
+
+// aclTokenUsageTracker samples when tokens are used on this server so that it
+// can be periodically written through Raft. A token's usage is only recorded
+// again once the configured granularity has passed since it was last recorded,
+// so busy tokens do not cause a write for every request.
+type aclTokenUsageTracker struct {
+	granularity time.Duration
+
+	lock sync.Mutex
+	// pending holds the usages that have not been flushed yet, keyed by
+	// accessor ID.
+	pending map[string]aclTokenUsageSample
+	// recorded holds when the usage of a token was last recorded, keyed by
+	// accessor ID.
+	recorded map[string]time.Time
+}
+
+// aclTokenUsageSample is a usage that has not been flushed yet.
+type aclTokenUsageSample struct {
+	lastUsed time.Time
+	// local is whether the token is local to this datacenter. The usage of
+	// global tokens is aggregated in the primary datacenter.
+	local bool
+}
+
+func newACLTokenUsageTracker(granularity time.Duration) *aclTokenUsageTracker {
+	return &aclTokenUsageTracker{
+		granularity: granularity,
+		pending:     make(map[string]aclTokenUsageSample),
+		recorded:    make(map[string]time.Time),
+	}
+}
+
+// record notes that the token with the given accessor was used at the given
+// time.
+func (t *aclTokenUsageTracker) record(accessorID string, local bool, now time.Time) {
+	if accessorID == "" {
+		return
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if last, ok := t.recorded[accessorID]; ok && now.Sub(last) < t.granularity {
+		return
+	}
+	t.recorded[accessorID] = now
+	t.pending[accessorID] = aclTokenUsageSample{lastUsed: now, local: local}
+}
+
+// flush returns the usages of local and global tokens recorded since the last
+// flush and forgets the samples that are older than the granularity.
+func (t *aclTokenUsageTracker) flush(now time.Time) (local, global structs.ACLTokenUsages) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for accessorID, sample := range t.pending {
+		usage := &structs.ACLTokenUsage{
+			AccessorID: accessorID,
+			LastUsed:   sample.lastUsed,
+		}
+		if sample.local {
+			local = append(local, usage)
+		} else {
+			global = append(global, usage)
+		}
+	}
+	t.pending = make(map[string]aclTokenUsageSample)
+
+	for accessorID, last := range t.recorded {
+		if now.Sub(last) >= t.granularity {
+			delete(t.recorded, accessorID)
+		}
+	}
+	return local, global
+}
+
+// requeue puts back usages that failed to be written so they are sent again
+// by the next flush. Tokens used again in the meantime keep their newer usage.
+func (t *aclTokenUsageTracker) requeue(usages structs.ACLTokenUsages, local bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for _, usage := range usages {
+		if sample, ok := t.pending[usage.AccessorID]; ok && !sample.lastUsed.Before(usage.LastUsed) {
+			continue
+		}
+		t.pending[usage.AccessorID] = aclTokenUsageSample{lastUsed: usage.LastUsed, local: local}
+	}
+}
+
+// recordACLTokenUsage records that the given token was used, if ACL token
+// usage tracking is running on this server.
+func (s *Server) recordACLTokenUsage(token *structs.ACLToken) {
+	if s.aclTokenUsage == nil || token == nil {
+		return
+	}
+	s.aclTokenUsage.record(token.AccessorID, token.Local, time.Now())
+}
+
+// aclTokenUsageUpdate is a long-running routine that periodically writes the
+// token usage observed by this server through the leader.
+func (s *Server) aclTokenUsageUpdate() {
+	for {
+		select {
+		case <-time.After(s.config.ACLTokenUsageFlushInterval):
+			if err := s.flushACLTokenUsage(); err != nil {
+				s.logger.Warn("Failed to update ACL token usage", "error", err)
+			}
+		case <-s.shutdownCh:
+			return
+		}
+	}
+}
+
+// flushACLTokenUsage sends the pending token usages to the leader in batches.
+// The usages of global tokens are sent to the primary datacenter so that it
+// knows about the uses in every datacenter. Usages that could not be written
+// are kept and sent again by the next flush.
+func (s *Server) flushACLTokenUsage() error {
+	local, global := s.aclTokenUsage.flush(time.Now())
+	if len(local) == 0 && len(global) == 0 {
+		return nil
+	}
+
+	// The usages are written with the server management token, which is only
+	// available once the leader has initialized ACLs. Secondary datacenters
+	// write the usages of global tokens with the replication token instead,
+	// as the server management token is local to each datacenter.
+	token, err := s.GetSystemMetadata(structs.ServerManagementTokenAccessorID)
+	if err != nil {
+		s.aclTokenUsage.requeue(local, true)
+		s.aclTokenUsage.requeue(global, false)
+		return err
+	}
+
+	var errs error
+	if len(local) > 0 {
+		if token == "" {
+			s.aclTokenUsage.requeue(local, true)
+		} else if err := s.sendACLTokenUsages(s.config.Datacenter, token, local, true); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+	if len(global) > 0 {
+		globalToken := token
+		if !s.InPrimaryDatacenter() {
+			globalToken = s.tokens.ReplicationToken()
+		}
+		if globalToken == "" {
+			s.aclTokenUsage.requeue(global, false)
+		} else if err := s.sendACLTokenUsages(s.config.PrimaryDatacenter, globalToken, global, false); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+	return errs
+}
+
+// sendACLTokenUsages writes the given usages to the leader of the datacenter
+// in batches, requeueing the usages of the batches that failed.
+func (s *Server) sendACLTokenUsages(dc, token string, usages structs.ACLTokenUsages, local bool) error {
+	for start := 0; start < len(usages); start += aclTokenUsageBatchSize {
+		end := start + aclTokenUsageBatchSize
+		if end > len(usages) {
+			end = len(usages)
+		}
+
+		req := structs.ACLTokenUsageUpdateRequest{
+			Usages:       usages[start:end],
+			Datacenter:   dc,
+			WriteRequest: structs.WriteRequest{Token: token},
+		}
+		var reply struct{}
+		if err := s.RPC(context.Background(), "ACL.TokenUsageUpdate", &req, &reply); err != nil {
+			s.aclTokenUsage.requeue(usages[start:], local)
+			return err
+		}
+	}
+	return nil
+}
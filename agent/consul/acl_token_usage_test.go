@@ -0,0 +1,257 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package consul
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	msgpackrpc "github.com/hashicorp/consul-net-rpc/net-rpc-msgpackrpc"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hernad/consul/acl"
+	"github.com/hernad/consul/agent/structs"
+	tokenStore "github.com/hernad/consul/agent/token"
+	"github.com/hernad/consul/sdk/testutil/retry"
+	"github.com/hernad/consul/testrpc"
+)
+
+func TestACLTokenUsageTracker(t *testing.T) {
+	t.Parallel()
+
+	tracker := newACLTokenUsageTracker(time.Hour)
+	start := time.Date(2020, 5, 22, 18, 0, 0, 0, time.UTC)
+
+	tracker.record("a", true, start)
+	tracker.record("b", true, start)
+	tracker.record("c", false, start)
+	// within the granularity, so not recorded again
+	tracker.record("a", true, start.Add(30*time.Minute))
+	tracker.record("", true, start)
+
+	local, global := tracker.flush(start.Add(time.Minute))
+	sort.Slice(local, func(i, j int) bool {
+		return local[i].AccessorID < local[j].AccessorID
+	})
+	require.Equal(t, structs.ACLTokenUsages{
+		{AccessorID: "a", LastUsed: start},
+		{AccessorID: "b", LastUsed: start},
+	}, local)
+	require.Equal(t, structs.ACLTokenUsages{
+		{AccessorID: "c", LastUsed: start},
+	}, global)
+
+	// nothing is pending after a flush, and the samples are still too recent
+	// to record another use
+	tracker.record("a", true, start.Add(45*time.Minute))
+	local, global = tracker.flush(start.Add(50 * time.Minute))
+	require.Empty(t, local)
+	require.Empty(t, global)
+
+	// once the granularity has passed the token is recorded again
+	later := start.Add(2 * time.Hour)
+	tracker.record("a", true, later)
+	local, global = tracker.flush(later)
+	require.Equal(t, structs.ACLTokenUsages{
+		{AccessorID: "a", LastUsed: later},
+	}, local)
+	require.Empty(t, global)
+}
+
+func TestACLTokenUsageTracker_Requeue(t *testing.T) {
+	t.Parallel()
+
+	tracker := newACLTokenUsageTracker(time.Hour)
+	start := time.Date(2020, 5, 22, 18, 0, 0, 0, time.UTC)
+
+	tracker.record("a", true, start)
+	tracker.record("b", false, start)
+	local, global := tracker.flush(start)
+
+	// a newer use recorded while the flush was failing wins over the
+	// requeued one
+	later := start.Add(2 * time.Hour)
+	tracker.record("a", true, later)
+
+	tracker.requeue(local, true)
+	tracker.requeue(global, false)
+
+	local, global = tracker.flush(later)
+	require.Equal(t, structs.ACLTokenUsages{
+		{AccessorID: "a", LastUsed: later},
+	}, local)
+	require.Equal(t, structs.ACLTokenUsages{
+		{AccessorID: "b", LastUsed: start},
+	}, global)
+}
+
+func TestACLEndpoint_TokenUsage(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	_, srv, codec := testACLServerWithConfig(t, func(c *Config) {
+		c.ACLTokenUsageFlushInterval = 10 * time.Millisecond
+		c.ACLTokenUsageGranularity = time.Millisecond
+	}, false)
+	waitForLeaderEstablishment(t, srv)
+
+	t1, err := upsertTestToken(codec, TestDefaultInitialManagementToken, "dc1", nil)
+	require.NoError(t, err)
+	t2, err := upsertTestToken(codec, TestDefaultInitialManagementToken, "dc1", nil)
+	require.NoError(t, err)
+	t3, err := upsertTestToken(codec, TestDefaultInitialManagementToken, "dc1", nil)
+	require.NoError(t, err)
+
+	readToken := func(t require.TestingT, accessorID string) *structs.ACLToken {
+		req := structs.ACLTokenGetRequest{
+			Datacenter:   "dc1",
+			TokenID:      accessorID,
+			TokenIDType:  structs.ACLTokenAccessor,
+			QueryOptions: structs.QueryOptions{Token: TestDefaultInitialManagementToken},
+		}
+		var resp structs.ACLTokenResponse
+		require.NoError(t, msgpackrpc.CallWithCodec(codec, "ACL.TokenRead", &req, &resp))
+		require.NotNil(t, resp.Token)
+		return resp.Token
+	}
+
+	listTokens := func(t *testing.T, unusedFor time.Duration) map[string]*structs.ACLTokenListStub {
+		req := structs.ACLTokenListRequest{
+			Datacenter:    "dc1",
+			IncludeLocal:  true,
+			IncludeGlobal: true,
+			UnusedFor:     unusedFor,
+			QueryOptions:  structs.QueryOptions{Token: TestDefaultInitialManagementToken},
+		}
+		var resp structs.ACLTokenListResponse
+		require.NoError(t, msgpackrpc.CallWithCodec(codec, "ACL.TokenList", &req, &resp))
+		stubs := make(map[string]*structs.ACLTokenListStub)
+		for _, stub := range resp.Tokens {
+			stubs[stub.AccessorID] = stub
+		}
+		return stubs
+	}
+
+	t.Run("records usage", func(t *testing.T) {
+		require.Nil(t, readToken(t, t2.AccessorID).LastUsedTime)
+
+		before := time.Now()
+		_, _, err := srv.ResolveIdentityFromToken(t2.SecretID)
+		require.NoError(t, err)
+
+		retry.Run(t, func(r *retry.R) {
+			token := readToken(r, t2.AccessorID)
+			require.NotNil(r, token.LastUsedTime)
+			require.False(r, token.LastUsedTime.Before(before))
+		})
+
+		stubs := listTokens(t, 0)
+		require.Contains(t, stubs, t2.AccessorID)
+		require.NotNil(t, stubs[t2.AccessorID].LastUsedTime)
+	})
+
+	t.Run("requires acl write", func(t *testing.T) {
+		req := structs.ACLTokenUsageUpdateRequest{
+			Datacenter: "dc1",
+			Usages: structs.ACLTokenUsages{
+				{AccessorID: t1.AccessorID, LastUsed: time.Now()},
+			},
+			WriteRequest: structs.WriteRequest{Token: t3.SecretID},
+		}
+		err := msgpackrpc.CallWithCodec(codec, "ACL.TokenUsageUpdate", &req, &struct{}{})
+		require.Error(t, err)
+		require.True(t, acl.IsErrPermissionDenied(err), "unexpected error: %v", err)
+	})
+
+	t.Run("unused for", func(t *testing.T) {
+		req := structs.ACLTokenUsageUpdateRequest{
+			Datacenter: "dc1",
+			Usages: structs.ACLTokenUsages{
+				{AccessorID: t1.AccessorID, LastUsed: time.Now().Add(-48 * time.Hour)},
+			},
+			WriteRequest: structs.WriteRequest{Token: TestDefaultInitialManagementToken},
+		}
+		require.NoError(t, msgpackrpc.CallWithCodec(codec, "ACL.TokenUsageUpdate", &req, &struct{}{}))
+
+		// t2 was just used and t3 was never used but was just created.
+		stubs := listTokens(t, 24*time.Hour)
+		require.Contains(t, stubs, t1.AccessorID)
+		require.NotContains(t, stubs, t2.AccessorID)
+		require.NotContains(t, stubs, t3.AccessorID)
+	})
+}
+
+func TestACLTokenUsage_GlobalTokensAggregatedInPrimary(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	_, s1 := testServerWithConfig(t, func(c *Config) {
+		c.PrimaryDatacenter = "dc1"
+		c.ACLsEnabled = true
+		c.ACLInitialManagementToken = "root"
+	})
+	testrpc.WaitForLeader(t, s1.RPC, "dc1")
+	codec1 := rpcClient(t, s1)
+	defer codec1.Close()
+
+	_, s2 := testServerWithConfig(t, func(c *Config) {
+		c.Datacenter = "dc2"
+		c.PrimaryDatacenter = "dc1"
+		c.ACLsEnabled = true
+		c.ACLTokenReplication = true
+		c.ACLTokenUsageFlushInterval = 10 * time.Millisecond
+		c.ACLTokenUsageGranularity = time.Millisecond
+	})
+	s2.tokens.UpdateReplicationToken("root", tokenStore.TokenSourceConfig)
+	testrpc.WaitForLeader(t, s2.RPC, "dc2")
+
+	joinWAN(t, s2, s1)
+	testrpc.WaitForLeader(t, s1.RPC, "dc1")
+	testrpc.WaitForLeader(t, s1.RPC, "dc2")
+	waitForNewACLReplication(t, s2, structs.ACLReplicateTokens, 1, 1, 0)
+
+	token, err := upsertTestToken(codec1, "root", "dc1", nil)
+	require.NoError(t, err)
+
+	retry.Run(t, func(r *retry.R) {
+		_, replicated, err := s2.fsm.State().ACLTokenGetByAccessor(nil, token.AccessorID, nil)
+		require.NoError(r, err)
+		require.NotNil(r, replicated)
+	})
+
+	before := time.Now()
+	_, identity, err := s2.ResolveIdentityFromToken(token.SecretID)
+	require.NoError(t, err)
+	require.NotNil(t, identity)
+
+	// The use in dc2 is recorded in the primary datacenter.
+	retry.Run(t, func(r *retry.R) {
+		lastUsed, err := s1.fsm.State().ACLTokenUsageGet(nil, token.AccessorID)
+		require.NoError(r, err)
+		require.NotNil(r, lastUsed)
+		require.False(r, lastUsed.Before(before))
+	})
+
+	// dc2 does not know the usage of global tokens, so it does not list
+	// them as unused.
+	req := structs.ACLTokenListRequest{
+		Datacenter:    "dc2",
+		IncludeLocal:  true,
+		IncludeGlobal: true,
+		UnusedFor:     time.Nanosecond,
+		QueryOptions:  structs.QueryOptions{Token: "root"},
+	}
+	var resp structs.ACLTokenListResponse
+	require.NoError(t, s2.RPC(context.Background(), "ACL.TokenList", &req, &resp))
+	for _, stub := range resp.Tokens {
+		require.True(t, stub.Local, "global token %s listed in dc2", stub.AccessorID)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package consul
+
+import (
+	"reflect"
+
+	"github.com/hashicorp/consul-net-rpc/net/rpc"
+	"github.com/hashicorp/go-uuid"
+
+	"github.com/hernad/consul/acl"
+	"github.com/hernad/consul/agent/audit"
+	"github.com/hernad/consul/agent/structs"
+)
+
+// auditRPCInterceptor returns an interceptor recording the RPC requests in
+// the audit log before calling next, which may be nil.
+func (s *Server) auditRPCInterceptor(auditor *audit.Logger, next rpc.ServerServiceCallInterceptor) rpc.ServerServiceCallInterceptor {
+	return func(method string, argv, replyv reflect.Value, handler func() error) {
+		var err error
+		if next != nil {
+			next(method, argv, replyv, func() error {
+				err = handler()
+				return err
+			})
+		} else {
+			err = handler()
+		}
+
+		info, ok := argv.Interface().(structs.RPCInfo)
+		if !ok {
+			return
+		}
+		read := info.IsRead()
+		decision := audit.DecisionAllow
+		if acl.IsErrPermissionDenied(err) || acl.IsErrNotFound(err) {
+			decision = audit.DecisionDeny
+		}
+		if !auditor.Wants(audit.SourceRPC, method, read, decision) {
+			return
+		}
+
+		var accessorID string
+		if s.ACLResolver != nil {
+			if authz, err := s.ACLResolver.ResolveToken(info.TokenSecret()); err == nil {
+				accessorID = authz.AccessorID()
+			}
+		}
+
+		// Requests made for an HTTP request share its ID.
+		var requestID string
+		if r, ok := info.(structs.AuditedRequest); ok {
+			requestID = r.AuditRequestID()
+		}
+		if requestID == "" {
+			requestID, _ = uuid.GenerateUUID()
+		}
+		e := audit.Event{
+			RequestID:  requestID,
+			Source:     audit.SourceRPC,
+			AccessorID: accessorID,
+			Operation:  "write",
+			Resource:   method,
+			Decision:   decision,
+		}
+		if read {
+			e.Operation = "read"
+		}
+		if err != nil {
+			e.Error = err.Error()
+		}
+		auditor.Log(e, read)
+	}
+}
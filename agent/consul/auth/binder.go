@@ -35,14 +35,16 @@ func NewBinder(store BinderStateStore, datacenter string) *Binder {
 type BinderStateStore interface {
 	ACLBindingRuleList(ws memdb.WatchSet, methodName string, entMeta *acl.EnterpriseMeta) (uint64, structs.ACLBindingRules, error)
 	ACLRoleGetByName(ws memdb.WatchSet, roleName string, entMeta *acl.EnterpriseMeta) (uint64, *structs.ACLRole, error)
+	ACLPolicyTemplateGetByName(ws memdb.WatchSet, name string, entMeta *acl.EnterpriseMeta) (uint64, *structs.ACLPolicyTemplate, error)
 }
 
-// Bindings contains the ACL roles, service identities, node identities and
-// enterprise meta to be assigned to the created token.
+// Bindings contains the ACL roles, service identities, node identities,
+// templated policies and enterprise meta to be assigned to the created token.
 type Bindings struct {
 	Roles             []structs.ACLTokenRoleLink
 	ServiceIdentities []*structs.ACLServiceIdentity
 	NodeIdentities    []*structs.ACLNodeIdentity
+	TemplatedPolicies structs.ACLTemplatedPolicies
 	EnterpriseMeta    acl.EnterpriseMeta
 }
 
@@ -55,7 +57,8 @@ func (b *Bindings) None() bool {
 
 	return len(b.ServiceIdentities) == 0 &&
 		len(b.NodeIdentities) == 0 &&
-		len(b.Roles) == 0
+		len(b.Roles) == 0 &&
+		len(b.TemplatedPolicies) == 0
 }
 
 // Bind collects the ACL roles, service identities, etc. to be assigned to the
@@ -120,6 +123,28 @@ func (b *Binder) Bind(authMethod *structs.ACLAuthMethod, verifiedIdentity *authm
 					ID: role.ID,
 				})
 			}
+
+		case structs.BindingRuleBindTypeTemplatedPolicy:
+			_, policyTemplate, err := b.store.ACLPolicyTemplateGetByName(nil, bindName, &bindings.EnterpriseMeta)
+			if err != nil {
+				return nil, err
+			}
+			if policyTemplate == nil {
+				continue
+			}
+
+			bindVars, err := computeBindVars(rule.BindVars, verifiedIdentity.ProjectedVars)
+			if err != nil {
+				return nil, fmt.Errorf("cannot compute bind vars for bind target: %w", err)
+			}
+			if err := policyTemplate.ValidateVariables(bindVars); err != nil {
+				return nil, fmt.Errorf("computed bind vars for bind target are invalid: %w", err)
+			}
+
+			bindings.TemplatedPolicies = append(bindings.TemplatedPolicies, &structs.ACLTemplatedPolicy{
+				TemplateID: policyTemplate.ID,
+				Variables:  bindVars,
+			})
 		}
 	}
 
@@ -145,6 +170,42 @@ func IsValidBindName(bindType, bindName string, availableVariables []string) (bo
 	return valid, nil
 }
 
+// IsValidBindVars returns whether the given BindVars templates are allowed for
+// the bind type and are valid HIL when interpolating the auth method's
+// available variables.
+func IsValidBindVars(bindType string, bindVars map[string]string, availableVariables []string) (bool, error) {
+	if len(bindVars) == 0 {
+		return true, nil
+	}
+	if bindType != structs.BindingRuleBindTypeTemplatedPolicy {
+		return false, nil
+	}
+
+	fakeVarMap := make(map[string]string)
+	for _, v := range availableVariables {
+		fakeVarMap[v] = "fake"
+	}
+
+	if _, err := computeBindVars(bindVars, fakeVarMap); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// computeBindVars processes the HIL of the values of the provided bind vars
+// using the projected variables.
+func computeBindVars(bindVars map[string]string, projectedVars map[string]string) (map[string]string, error) {
+	out := make(map[string]string, len(bindVars))
+	for name, value := range bindVars {
+		computed, err := template.InterpolateHIL(value, projectedVars, true)
+		if err != nil {
+			return nil, err
+		}
+		out[name] = computed
+	}
+	return out, nil
+}
+
 // computeBindName processes the HIL for the provided bind type+name using the
 // projected variables.
 //
@@ -165,6 +226,8 @@ func computeBindName(bindType, bindName string, projectedVars map[string]string)
 		valid = acl.IsValidNodeIdentityName(bindName)
 	case structs.BindingRuleBindTypeRole:
 		valid = acl.IsValidRoleName(bindName)
+	case structs.BindingRuleBindTypeTemplatedPolicy:
+		valid = acl.ValidatePolicyName(bindName) == nil
 	default:
 		return "", false, fmt.Errorf("unknown binding rule bind type: %s", bindType)
 	}
@@ -32,6 +32,9 @@ func TestBindings_None(t *testing.T) {
 
 	b = &Bindings{NodeIdentities: []*structs.ACLNodeIdentity{{NodeName: "node-123"}}}
 	require.False(t, b.None())
+
+	b = &Bindings{TemplatedPolicies: structs.ACLTemplatedPolicies{{TemplateID: generateID(t)}}}
+	require.False(t, b.None())
 }
 
 func TestBinder_Roles_Success(t *testing.T) {
@@ -258,6 +261,98 @@ func TestBinder_NodeIdentities_NameValidation(t *testing.T) {
 	require.Contains(t, err.Error(), "bind name for bind target is invalid")
 }
 
+func TestBinder_TemplatedPolicies_Success(t *testing.T) {
+	store := testStateStore(t)
+	binder := &Binder{store: store}
+
+	authMethod := &structs.ACLAuthMethod{
+		Name: "test-auth-method",
+		Type: "testing",
+	}
+	require.NoError(t, store.ACLAuthMethodSet(0, authMethod))
+
+	policyTemplate := &structs.ACLPolicyTemplate{
+		ID:    generateID(t),
+		Name:  "kv-owner",
+		Rules: `key_prefix "${team}/${app}/" { policy = "write" }`,
+	}
+	require.NoError(t, store.ACLPolicyTemplateSet(0, policyTemplate))
+
+	bindingRules := structs.ACLBindingRules{
+		{
+			ID:         generateID(t),
+			Selector:   "tier==web",
+			BindType:   structs.BindingRuleBindTypeTemplatedPolicy,
+			BindName:   "kv-owner",
+			BindVars:   map[string]string{"team": "${team}", "app": "web-${name}"},
+			AuthMethod: authMethod.Name,
+		},
+		{
+			ID:         generateID(t),
+			Selector:   "tier==web",
+			BindType:   structs.BindingRuleBindTypeTemplatedPolicy,
+			BindName:   "this-template-does-not-exist",
+			AuthMethod: authMethod.Name,
+		},
+	}
+	require.NoError(t, store.ACLBindingRuleBatchSet(0, bindingRules))
+
+	result, err := binder.Bind(&structs.ACLAuthMethod{}, &authmethod.Identity{
+		SelectableFields: map[string]string{
+			"tier": "web",
+		},
+		ProjectedVars: map[string]string{
+			"team": "payments",
+			"name": "billing",
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, structs.ACLTemplatedPolicies{
+		{
+			TemplateID: policyTemplate.ID,
+			Variables:  map[string]string{"team": "payments", "app": "web-billing"},
+		},
+	}, result.TemplatedPolicies)
+}
+
+func TestBinder_TemplatedPolicies_VarsValidation(t *testing.T) {
+	store := testStateStore(t)
+	binder := &Binder{store: store}
+
+	authMethod := &structs.ACLAuthMethod{
+		Name: "test-auth-method",
+		Type: "testing",
+	}
+	require.NoError(t, store.ACLAuthMethodSet(0, authMethod))
+
+	policyTemplate := &structs.ACLPolicyTemplate{
+		ID:    generateID(t),
+		Name:  "kv-owner",
+		Rules: `key_prefix "${app}/" { policy = "write" }`,
+	}
+	require.NoError(t, store.ACLPolicyTemplateSet(0, policyTemplate))
+
+	bindingRules := structs.ACLBindingRules{
+		{
+			ID:         generateID(t),
+			Selector:   "",
+			BindType:   structs.BindingRuleBindTypeTemplatedPolicy,
+			BindName:   "kv-owner",
+			BindVars:   map[string]string{"app": "${name}"},
+			AuthMethod: authMethod.Name,
+		},
+	}
+	require.NoError(t, store.ACLBindingRuleBatchSet(0, bindingRules))
+
+	_, err := binder.Bind(&structs.ACLAuthMethod{}, &authmethod.Identity{
+		ProjectedVars: map[string]string{
+			"name": `web" { policy = "write" }`,
+		},
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "computed bind vars for bind target are invalid")
+}
+
 func Test_IsValidBindName(t *testing.T) {
 	type testcase struct {
 		name     string
@@ -47,6 +47,7 @@ func (l *Login) TokenForVerifiedIdentity(identity *authmethod.Identity, authMeth
 		ServiceIdentities: bindings.ServiceIdentities,
 		NodeIdentities:    bindings.NodeIdentities,
 		Roles:             bindings.Roles,
+		TemplatedPolicies: bindings.TemplatedPolicies,
 		EnterpriseMeta:    bindings.EnterpriseMeta,
 	}
 	token.ACLAuthMethodEnterpriseMeta.FillWithEnterpriseMeta(&authMethod.EnterpriseMeta)
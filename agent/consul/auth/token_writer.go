@@ -14,6 +14,7 @@ import (
 	"github.com/hernad/consul/acl"
 	"github.com/hernad/consul/agent/structs"
 	"github.com/hernad/consul/lib"
+	"github.com/hernad/consul/lib/stringslice"
 )
 
 // ErrCannotWriteGlobalToken indicates that writing a token failed because
@@ -55,16 +56,73 @@ type ACLCache interface {
 type TokenWriterStore interface {
 	ACLTokenGetByAccessor(ws memdb.WatchSet, accessorID string, entMeta *acl.EnterpriseMeta) (uint64, *structs.ACLToken, error)
 	ACLTokenGetBySecret(ws memdb.WatchSet, secretID string, entMeta *acl.EnterpriseMeta) (uint64, *structs.ACLToken, error)
+	ACLTokenListByParent(ws memdb.WatchSet, parentAccessorID string) (uint64, structs.ACLTokens, error)
 	ACLRoleGetByID(ws memdb.WatchSet, id string, entMeta *acl.EnterpriseMeta) (uint64, *structs.ACLRole, error)
 	ACLRoleGetByName(ws memdb.WatchSet, name string, entMeta *acl.EnterpriseMeta) (uint64, *structs.ACLRole, error)
 	ACLPolicyGetByID(ws memdb.WatchSet, id string, entMeta *acl.EnterpriseMeta) (uint64, *structs.ACLPolicy, error)
 	ACLPolicyGetByName(ws memdb.WatchSet, name string, entMeta *acl.EnterpriseMeta) (uint64, *structs.ACLPolicy, error)
+	ACLPolicyTemplateGetByID(ws memdb.WatchSet, id string, entMeta *acl.EnterpriseMeta) (uint64, *structs.ACLPolicyTemplate, error)
+	ACLPolicyTemplateGetByName(ws memdb.WatchSet, name string, entMeta *acl.EnterpriseMeta) (uint64, *structs.ACLPolicyTemplate, error)
 	ACLTokenUpsertValidateEnterprise(token *structs.ACLToken, existing *structs.ACLToken) error
 }
 
 // Create a new token. Setting fromLogin to true changes behavior slightly for
 // tokens created by login (as opposed to set manually via the API).
 func (w *TokenWriter) Create(token *structs.ACLToken, fromLogin bool) (*structs.ACLToken, error) {
+	if token.ParentAccessorID != "" {
+		return nil, errors.New("ParentAccessorID field is only set when creating a child token")
+	}
+	return w.create(token, nil, fromLogin)
+}
+
+// CreateChild creates a new token minted from the given parent token. The
+// child may only be granted a subset of the parent's policies, roles and
+// identities, must expire no later than the parent, and is deleted along with
+// the parent or when the parent is updated to no longer have one of them.
+func (w *TokenWriter) CreateChild(parent, token *structs.ACLToken) (*structs.ACLToken, error) {
+	if parent.AccessorID == acl.AnonymousTokenID {
+		return nil, acl.PermissionDeniedError{Cause: "Cannot create a child of the anonymous token"}
+	}
+	if token.ParentAccessorID != "" && token.ParentAccessorID != parent.AccessorID {
+		return nil, errors.New("ParentAccessorID must be the AccessorID of the token making the request")
+	}
+	if token.AccessorID != "" || token.SecretID != "" {
+		return nil, errors.New("AccessorID and SecretID cannot be set on a child token")
+	}
+	if token.Local != parent.Local {
+		return nil, errors.New("Child tokens must have the same locality as their parent")
+	}
+	if token.ExpirationTTL <= 0 && !token.HasExpirationTime() {
+		return nil, errors.New("Child tokens require an ExpirationTTL or ExpirationTime")
+	}
+
+	policies, err := w.normalizePolicyLinks(token.Policies, &token.EnterpriseMeta)
+	if err != nil {
+		return nil, err
+	}
+	token.Policies = policies
+
+	roles, err := w.normalizeRoleLinks(token.Roles, &token.EnterpriseMeta)
+	if err != nil {
+		return nil, err
+	}
+	token.Roles = roles
+
+	templatedPolicies, err := w.normalizeTemplatedPolicies(token.TemplatedPolicies, &token.EnterpriseMeta, token.Local)
+	if err != nil {
+		return nil, err
+	}
+	token.TemplatedPolicies = templatedPolicies
+
+	if err := checkChildTokenPrivileges(parent, token); err != nil {
+		return nil, err
+	}
+
+	token.ParentAccessorID = parent.AccessorID
+	return w.create(token, parent, false)
+}
+
+func (w *TokenWriter) create(token, parent *structs.ACLToken, fromLogin bool) (*structs.ACLToken, error) {
 	if err := w.checkCanWriteToken(token); err != nil {
 		return nil, err
 	}
@@ -140,6 +198,11 @@ func (w *TokenWriter) Create(token *structs.ACLToken, fromLogin bool) (*structs.
 		}
 	}
 
+	if parent != nil && parent.HasExpirationTime() && token.ExpirationTime.After(*parent.ExpirationTime) {
+		return nil, fmt.Errorf("ExpirationTime cannot be after the expiration time of the parent token (%s)",
+			parent.ExpirationTime)
+	}
+
 	if fromLogin {
 		if token.AuthMethod == "" {
 			return nil, errors.New("AuthMethod field is required during login")
@@ -211,9 +274,64 @@ func (w *TokenWriter) Update(token *structs.ACLToken) (*structs.ACLToken, error)
 		token.ExpirationTime = match.ExpirationTime
 	}
 
+	if token.ParentAccessorID == "" {
+		token.ParentAccessorID = match.ParentAccessorID
+	} else if match.ParentAccessorID != token.ParentAccessorID {
+		return nil, fmt.Errorf("Cannot change ParentAccessorID of %s", token.AccessorID)
+	}
+
 	token.CreateTime = match.CreateTime
 
-	return w.write(token, match, false)
+	updated, err := w.write(token, match, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := w.revokeChildren(updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// revokeChildren deletes the child tokens of the given parent that were
+// granted privileges the parent no longer has, so that an update of the
+// parent cannot leave its children more privileged than itself.
+func (w *TokenWriter) revokeChildren(parent *structs.ACLToken) error {
+	_, children, err := w.Store.ACLTokenListByParent(nil, parent.AccessorID)
+	if err != nil {
+		return fmt.Errorf("Failed child token lookup: %w", err)
+	}
+
+	req := &structs.ACLTokenBatchDeleteRequest{}
+	var revoked structs.ACLTokens
+	for _, child := range children {
+		if err := checkChildTokenPrivileges(parent, child); err != nil {
+			req.TokenIDs = append(req.TokenIDs, child.AccessorID)
+			revoked = append(revoked, child)
+		}
+	}
+	if len(revoked) == 0 {
+		return nil
+	}
+
+	// Deleting the children also deletes their own children, which must be
+	// purged from the ACL cache as well.
+	for i := 0; i < len(revoked); i++ {
+		_, descendants, err := w.Store.ACLTokenListByParent(nil, revoked[i].AccessorID)
+		if err != nil {
+			return fmt.Errorf("Failed child token lookup: %w", err)
+		}
+		revoked = append(revoked, descendants...)
+	}
+
+	if _, err := w.RaftApply(structs.ACLTokenDeleteRequestType, req); err != nil {
+		return fmt.Errorf("Failed to apply child token delete request: %w", err)
+	}
+
+	for _, token := range revoked {
+		w.ACLCache.RemoveIdentityWithSecretToken(token.SecretID)
+	}
+	return nil
 }
 
 // Delete the ACL token with the given SecretID from the state store.
@@ -242,6 +360,127 @@ func (w *TokenWriter) Delete(secretID string, fromLogout bool) error {
 	return nil
 }
 
+// checkChildTokenPrivileges ensures the child token is only granted
+// privileges the parent token also has. The links of the child must already
+// be normalized.
+func checkChildTokenPrivileges(parent, child *structs.ACLToken) error {
+	if len(child.Policies) == 0 && len(child.Roles) == 0 && len(child.ServiceIdentities) == 0 &&
+		len(child.NodeIdentities) == 0 && len(child.TemplatedPolicies) == 0 {
+		return errors.New("Child tokens must be granted at least one policy, role or identity of their parent")
+	}
+
+	for _, link := range child.Policies {
+		if !parentHasPolicy(parent, link.ID) {
+			return fmt.Errorf("%w: parent token is not linked to policy %q", acl.ErrPermissionDenied, link.ID)
+		}
+	}
+
+	for _, link := range child.Roles {
+		if !parentHasRole(parent, link.ID) {
+			return fmt.Errorf("%w: parent token is not linked to role %q", acl.ErrPermissionDenied, link.ID)
+		}
+	}
+
+	for _, svcID := range child.ServiceIdentities {
+		if !parentHasServiceIdentity(parent, svcID) {
+			return fmt.Errorf("%w: parent token does not have service identity %q in all of the requested datacenters",
+				acl.ErrPermissionDenied, svcID.ServiceName)
+		}
+	}
+
+	for _, nodeID := range child.NodeIdentities {
+		if !parentHasNodeIdentity(parent, nodeID) {
+			return fmt.Errorf("%w: parent token does not have node identity %q in datacenter %q",
+				acl.ErrPermissionDenied, nodeID.NodeName, nodeID.Datacenter)
+		}
+	}
+
+	for _, templated := range child.TemplatedPolicies {
+		if !parentHasTemplatedPolicy(parent, templated) {
+			return fmt.Errorf("%w: parent token does not have templated policy %q with the requested variables and datacenters",
+				acl.ErrPermissionDenied, templated.TemplateID)
+		}
+	}
+
+	return nil
+}
+
+func parentHasPolicy(parent *structs.ACLToken, id string) bool {
+	for _, link := range parent.Policies {
+		if link.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func parentHasRole(parent *structs.ACLToken, id string) bool {
+	for _, link := range parent.Roles {
+		if link.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func parentHasServiceIdentity(parent *structs.ACLToken, svcID *structs.ACLServiceIdentity) bool {
+	for _, candidate := range parent.ServiceIdentities {
+		if candidate.ServiceName == svcID.ServiceName && datacentersSubset(candidate.Datacenters, svcID.Datacenters) {
+			return true
+		}
+	}
+	return false
+}
+
+func parentHasNodeIdentity(parent *structs.ACLToken, nodeID *structs.ACLNodeIdentity) bool {
+	for _, candidate := range parent.NodeIdentities {
+		if candidate.NodeName == nodeID.NodeName && candidate.Datacenter == nodeID.Datacenter {
+			return true
+		}
+	}
+	return false
+}
+
+func parentHasTemplatedPolicy(parent *structs.ACLToken, templated *structs.ACLTemplatedPolicy) bool {
+	for _, candidate := range parent.TemplatedPolicies {
+		if candidate.TemplateID == templated.TemplateID &&
+			variablesEqual(candidate.Variables, templated.Variables) &&
+			datacentersSubset(candidate.Datacenters, templated.Datacenters) {
+			return true
+		}
+	}
+	return false
+}
+
+func variablesEqual(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if bv, ok := b[k]; !ok || bv != v {
+			return false
+		}
+	}
+	return true
+}
+
+// datacentersSubset returns true if the requested datacenters are within the
+// allowed datacenters. An empty list allows all datacenters.
+func datacentersSubset(allowed, requested []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	if len(requested) == 0 {
+		return false
+	}
+	for _, dc := range requested {
+		if !stringslice.Contains(allowed, dc) {
+			return false
+		}
+	}
+	return true
+}
+
 func validateTokenID(id string) error {
 	if structs.ACLIDReserved(id) {
 		return fmt.Errorf("UUIDs with the prefix %q are reserved", structs.ACLReservedIDPrefix)
@@ -309,10 +548,20 @@ func (w *TokenWriter) write(token, existing *structs.ACLToken, fromLogin bool) (
 	}
 	token.NodeIdentities = nodeIdentities
 
+	templatedPolicies, err := w.normalizeTemplatedPolicies(token.TemplatedPolicies, &token.EnterpriseMeta, token.Local)
+	if err != nil {
+		return nil, err
+	}
+	token.TemplatedPolicies = templatedPolicies
+
 	if err := w.enterpriseValidation(token, existing); err != nil {
 		return nil, err
 	}
 
+	// The last used time is tracked separately and never persisted with the
+	// token itself.
+	token.LastUsedTime = nil
+
 	token.SetHash(true)
 
 	// Persist the token by writing to Raft.
@@ -442,3 +691,44 @@ func (w *TokenWriter) normalizeNodeIdentities(nodeIDs structs.ACLNodeIdentities)
 	}
 	return nodeIDs.Deduplicate(), nil
 }
+
+func (w *TokenWriter) normalizeTemplatedPolicies(links structs.ACLTemplatedPolicies, entMeta *acl.EnterpriseMeta, tokenLocal bool) (structs.ACLTemplatedPolicies, error) {
+	normalized := make(structs.ACLTemplatedPolicies, 0, len(links))
+	for _, link := range links {
+		// The links may be shared with a token of the state store.
+		link = link.Clone()
+
+		var template *structs.ACLPolicyTemplate
+		var err error
+		if link.TemplateID == "" {
+			_, template, err = w.Store.ACLPolicyTemplateGetByName(nil, link.TemplateName, entMeta)
+			switch {
+			case err != nil:
+				return nil, fmt.Errorf("Error looking up policy template for name: %q: %w", link.TemplateName, err)
+			case template == nil:
+				return nil, fmt.Errorf("No such ACL policy template with name %q", link.TemplateName)
+			}
+			link.TemplateID = template.ID
+		} else {
+			_, template, err = w.Store.ACLPolicyTemplateGetByID(nil, link.TemplateID, entMeta)
+			switch {
+			case err != nil:
+				return nil, fmt.Errorf("Error looking up policy template for ID: %q: %w", link.TemplateID, err)
+			case template == nil:
+				return nil, fmt.Errorf("No such ACL policy template with ID %q", link.TemplateID)
+			}
+		}
+
+		if tokenLocal && len(link.Datacenters) > 0 {
+			return nil, fmt.Errorf("Templated policy %q cannot specify a list of datacenters on a local token", template.Name)
+		}
+		if err := template.ValidateVariables(link.Variables); err != nil {
+			return nil, err
+		}
+
+		// Do not persist the template name as the template could be renamed in the future.
+		link.TemplateName = ""
+		normalized = append(normalized, link)
+	}
+	return normalized.Deduplicate(), nil
+}
@@ -433,6 +433,168 @@ func TestTokenWriter_Create_Success(t *testing.T) {
 	require.NotNil(t, updated)
 }
 
+func TestTokenWriter_CreateChild(t *testing.T) {
+	aclCache := &MockACLCache{}
+	aclCache.On("RemoveIdentityWithSecretToken", mock.Anything)
+
+	store := testStateStore(t)
+
+	policyA := &structs.ACLPolicy{ID: generateID(t), Name: "policy-a"}
+	require.NoError(t, store.ACLPolicySet(0, policyA))
+	policyB := &structs.ACLPolicy{ID: generateID(t), Name: "policy-b"}
+	require.NoError(t, store.ACLPolicySet(0, policyB))
+
+	parentExpiration := time.Now().Add(2 * time.Hour)
+	parent := &structs.ACLToken{
+		AccessorID: generateID(t),
+		SecretID:   generateID(t),
+		Policies:   []structs.ACLTokenPolicyLink{{ID: policyA.ID}},
+		ServiceIdentities: structs.ACLServiceIdentities{
+			{ServiceName: "web", Datacenters: []string{"dc1", "dc2"}},
+		},
+		NodeIdentities: structs.ACLNodeIdentities{
+			{NodeName: "node-1", Datacenter: "dc1"},
+		},
+		ExpirationTime: &parentExpiration,
+	}
+	require.NoError(t, store.ACLTokenSet(0, parent))
+
+	writer := buildTokenWriter(store, aclCache)
+
+	testCases := map[string]struct {
+		token         structs.ACLToken
+		errorContains string
+	}{
+		"no expiration": {
+			token: structs.ACLToken{
+				Policies: []structs.ACLTokenPolicyLink{{ID: policyA.ID}},
+			},
+			errorContains: "require an ExpirationTTL or ExpirationTime",
+		},
+		"no privileges": {
+			token:         structs.ACLToken{ExpirationTTL: time.Hour},
+			errorContains: "must be granted at least one",
+		},
+		"policy not linked to parent": {
+			token: structs.ACLToken{
+				Policies:      []structs.ACLTokenPolicyLink{{Name: policyB.Name}},
+				ExpirationTTL: time.Hour,
+			},
+			errorContains: "parent token is not linked to policy",
+		},
+		"service identity in more datacenters": {
+			token: structs.ACLToken{
+				ServiceIdentities: structs.ACLServiceIdentities{{ServiceName: "web"}},
+				ExpirationTTL:     time.Hour,
+			},
+			errorContains: "parent token does not have service identity",
+		},
+		"node identity not on parent": {
+			token: structs.ACLToken{
+				NodeIdentities: structs.ACLNodeIdentities{{NodeName: "node-2", Datacenter: "dc1"}},
+				ExpirationTTL:  time.Hour,
+			},
+			errorContains: "parent token does not have node identity",
+		},
+		"outlives parent": {
+			token: structs.ACLToken{
+				Policies:      []structs.ACLTokenPolicyLink{{ID: policyA.ID}},
+				ExpirationTTL: 3 * time.Hour,
+			},
+			errorContains: "cannot be after the expiration time of the parent token",
+		},
+		"different locality": {
+			token: structs.ACLToken{
+				Policies:      []structs.ACLTokenPolicyLink{{ID: policyA.ID}},
+				Local:         true,
+				ExpirationTTL: time.Hour,
+			},
+			errorContains: "same locality",
+		},
+		"SecretID set": {
+			token: structs.ACLToken{
+				SecretID:      generateID(t),
+				Policies:      []structs.ACLTokenPolicyLink{{ID: policyA.ID}},
+				ExpirationTTL: time.Hour,
+			},
+			errorContains: "cannot be set on a child token",
+		},
+	}
+	for desc, tc := range testCases {
+		t.Run(desc, func(t *testing.T) {
+			_, err := writer.CreateChild(parent, &tc.token)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.errorContains)
+		})
+	}
+
+	t.Run("success", func(t *testing.T) {
+		child, err := writer.CreateChild(parent, &structs.ACLToken{
+			Policies: []structs.ACLTokenPolicyLink{{Name: policyA.Name}},
+			ServiceIdentities: structs.ACLServiceIdentities{
+				{ServiceName: "web", Datacenters: []string{"dc2"}},
+			},
+			ExpirationTTL: time.Hour,
+		})
+		require.NoError(t, err)
+		require.Equal(t, parent.AccessorID, child.ParentAccessorID)
+		require.Equal(t, []structs.ACLTokenPolicyLink{{ID: policyA.ID, Name: policyA.Name}}, child.Policies)
+		require.NotNil(t, child.ExpirationTime)
+
+		// Updates cannot detach the child from its parent.
+		update := child.Clone()
+		update.ParentAccessorID = generateID(t)
+		_, err = writer.Update(update)
+		require.ErrorContains(t, err, "Cannot change ParentAccessorID")
+	})
+
+	t.Run("updating the parent revokes children it no longer covers", func(t *testing.T) {
+		parent := &structs.ACLToken{
+			AccessorID: generateID(t),
+			SecretID:   generateID(t),
+			Policies:   []structs.ACLTokenPolicyLink{{ID: policyA.ID}, {ID: policyB.ID}},
+		}
+		require.NoError(t, store.ACLTokenSet(0, parent))
+
+		childA, err := writer.CreateChild(parent, &structs.ACLToken{
+			Policies:      []structs.ACLTokenPolicyLink{{ID: policyA.ID}},
+			ExpirationTTL: time.Hour,
+		})
+		require.NoError(t, err)
+		childB, err := writer.CreateChild(parent, &structs.ACLToken{
+			Policies:      []structs.ACLTokenPolicyLink{{ID: policyB.ID}},
+			ExpirationTTL: time.Hour,
+		})
+		require.NoError(t, err)
+		grandchild, err := writer.CreateChild(childB, &structs.ACLToken{
+			Policies:      []structs.ACLTokenPolicyLink{{ID: policyB.ID}},
+			ExpirationTTL: 30 * time.Minute,
+		})
+		require.NoError(t, err)
+
+		update := parent.Clone()
+		update.Policies = []structs.ACLTokenPolicyLink{{ID: policyA.ID}}
+		_, err = writer.Update(update)
+		require.NoError(t, err)
+
+		_, token, err := store.ACLTokenGetByAccessor(nil, childA.AccessorID, nil)
+		require.NoError(t, err)
+		require.NotNil(t, token)
+
+		for _, revoked := range []*structs.ACLToken{childB, grandchild} {
+			_, token, err := store.ACLTokenGetByAccessor(nil, revoked.AccessorID, nil)
+			require.NoError(t, err)
+			require.Nil(t, token)
+			aclCache.AssertCalled(t, "RemoveIdentityWithSecretToken", revoked.SecretID)
+		}
+	})
+
+	t.Run("ParentAccessorID outside of a child token", func(t *testing.T) {
+		_, err := writer.Create(&structs.ACLToken{ParentAccessorID: parent.AccessorID}, false)
+		require.ErrorContains(t, err, "only set when creating a child token")
+	})
+}
+
 func TestTokenWriter_Update_Validation(t *testing.T) {
 	aclCache := &MockACLCache{}
 	aclCache.On("RemoveIdentityWithSecretToken", mock.Anything)
@@ -656,6 +818,13 @@ func TestTokenWriter_Delete(t *testing.T) {
 
 func raftApplyACLTokenSet(store *state.Store) RaftApplyFn {
 	return func(msgType structs.MessageType, msg interface{}) (interface{}, error) {
+		if msgType == structs.ACLTokenDeleteRequestType {
+			req, ok := msg.(*structs.ACLTokenBatchDeleteRequest)
+			if !ok {
+				return nil, fmt.Errorf("unexpected message: %T", msg)
+			}
+			return nil, store.ACLTokenBatchDelete(0, req.TokenIDs)
+		}
 		if msgType != structs.ACLTokenSetRequestType {
 			return nil, fmt.Errorf("unexpected message type: %v", msgType)
 		}
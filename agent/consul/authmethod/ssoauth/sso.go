@@ -5,6 +5,8 @@ package ssoauth
 
 import (
 	"context"
+	"fmt"
+	"os"
 	"time"
 
 	"github.com/hernad/consul/agent/consul/authmethod"
@@ -62,6 +64,40 @@ func NewValidator(logger hclog.Logger, method *structs.ACLAuthMethod) (*Validato
 	return v, nil
 }
 
+// InlineJWKSFile replaces the JWKSFile of a jwt auth method with the JWKS
+// document it contains. ACL auth methods are replicated to every server and
+// logins are validated by whichever server handles them, so the key set is
+// stored with the method instead of a path that may only exist on the
+// server handling the write. Rotating the keys requires updating the auth
+// method again.
+func InlineJWKSFile(method *structs.ACLAuthMethod) error {
+	if method.Type != "jwt" || method.Config == nil {
+		return nil
+	}
+	path, ok := method.Config["JWKSFile"].(string)
+	if !ok || path == "" {
+		return nil
+	}
+	if jwks, ok := method.Config["JWKS"].(string); ok && jwks != "" {
+		// Leave both set so that validation reports the conflict.
+		return nil
+	}
+
+	jwks, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading JWKSFile: %v", err)
+	}
+
+	config := make(map[string]interface{}, len(method.Config))
+	for k, v := range method.Config {
+		config[k] = v
+	}
+	delete(config, "JWKSFile")
+	config["JWKS"] = string(jwks)
+	method.Config = config
+	return nil
+}
+
 // Name implements authmethod.Validator.
 func (v *Validator) Name() string { return v.name }
 
@@ -133,6 +169,8 @@ type Config struct {
 	// just for type=jwt
 	JWKSURL              string        `json:",omitempty"`
 	JWKSCACert           string        `json:",omitempty"`
+	JWKS                 string        `json:",omitempty"`
+	JWKSFile             string        `json:",omitempty"`
 	JWTValidationPubKeys []string      `json:",omitempty"`
 	BoundIssuer          string        `json:",omitempty"`
 	ExpirationLeeway     time.Duration `json:",omitempty"`
@@ -157,6 +195,8 @@ func (c *Config) convertForLibrary(methodType string) *oidcauth.Config {
 		// just for type=jwt
 		JWKSURL:              c.JWKSURL,
 		JWKSCACert:           c.JWKSCACert,
+		JWKS:                 c.JWKS,
+		JWKSFile:             c.JWKSFile,
 		JWTValidationPubKeys: c.JWTValidationPubKeys,
 		BoundIssuer:          c.BoundIssuer,
 		ExpirationLeeway:     c.ExpirationLeeway,
@@ -5,6 +5,8 @@ package ssoauth
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -36,6 +38,9 @@ func TestJWT_NewValidator(t *testing.T) {
 	}
 
 	oidcServer := oidcauthtest.Start(t)
+	pubKey, _ := oidcServer.SigningKeys()
+	jwks, err := oidcauthtest.JWKS(pubKey)
+	require.NoError(t, err)
 
 	// Note that we won't test ALL of the available config variations here.
 	// The go-sso library has exhaustive tests.
@@ -60,6 +65,12 @@ func TestJWT_NewValidator(t *testing.T) {
 			method.Config["JWKSURL"] = oidcServer.Addr() + "/certs"
 			method.Config["JWKSCACert"] = oidcServer.CACert()
 		}), ""},
+		"normal jwt - inline jwks": {makeAuthMethod("jwt", func(method AM) {
+			method.Config["JWKS"] = jwks
+		}), ""},
+		"invalid jwt - inline jwks": {makeAuthMethod("jwt", func(method AM) {
+			method.Config["JWKS"] = "not-json"
+		}), "error checking JWKS"},
 		"normal jwt - oidc discovery": {makeAuthMethod("jwt", func(method AM) {
 			method.Config["OIDCDiscoveryURL"] = oidcServer.Addr()
 			method.Config["OIDCDiscoveryCACert"] = oidcServer.CACert()
@@ -116,6 +127,11 @@ func TestJWT_ValidateLogin(t *testing.T) {
 	oidcServer := oidcauthtest.Start(t)
 	pubKey, privKey := oidcServer.SigningKeys()
 
+	jwks, err := oidcauthtest.JWKS(pubKey)
+	require.NoError(t, err)
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(jwksFile, []byte(jwks), 0600))
+
 	cases := map[string]struct {
 		f         func(config mConfig)
 		issuer    string
@@ -133,6 +149,24 @@ func TestJWT_ValidateLogin(t *testing.T) {
 		},
 			"https://legit.issuer.internal/",
 			""},
+		"success - jwt inline jwks": {func(config mConfig) {
+			config["BoundIssuer"] = "https://legit.issuer.internal/"
+			config["JWKS"] = jwks
+		},
+			"https://legit.issuer.internal/",
+			""},
+		"success - jwt jwks file": {func(config mConfig) {
+			config["BoundIssuer"] = "https://legit.issuer.internal/"
+			config["JWKSFile"] = jwksFile
+		},
+			"https://legit.issuer.internal/",
+			""},
+		"failure - jwt jwks file bound issuer": {func(config mConfig) {
+			config["BoundIssuer"] = "https://other.issuer.internal/"
+			config["JWKSFile"] = jwksFile
+		},
+			"https://legit.issuer.internal/",
+			"invalid issuer claim"},
 		"success - jwt oidc discovery": {func(config mConfig) {
 			config["OIDCDiscoveryURL"] = oidcServer.Addr()
 			config["OIDCDiscoveryCACert"] = oidcServer.CACert()
@@ -273,6 +273,16 @@ type Config struct {
 	// on a token.
 	ACLTokenMinExpirationTTL time.Duration
 
+	// ACLTokenUsageFlushInterval is how often each server writes the token
+	// usage it observed through Raft.
+	ACLTokenUsageFlushInterval time.Duration
+
+	// ACLTokenUsageGranularity controls how precise the recorded last-used time
+	// of a token is. A token's usage is only recorded again once this much
+	// time has passed, which bounds the number of Raft writes caused by busy
+	// tokens. It is unlikely a user would ever need to tune this.
+	ACLTokenUsageGranularity time.Duration
+
 	// ServerUp callback can be used to trigger a notification that
 	// a Consul server is now up and known about.
 	ServerUp func()
@@ -528,6 +538,8 @@ func DefaultConfig() *Config {
 		TombstoneTTLGranularity:              30 * time.Second,
 		SessionTTLMin:                        10 * time.Second,
 		ACLTokenMinExpirationTTL:             1 * time.Minute,
+		ACLTokenUsageFlushInterval:           1 * time.Minute,
+		ACLTokenUsageGranularity:             1 * time.Hour,
 		// Duration is stored as an int64. Setting the default max
 		// to the max possible duration (approx 290 years).
 		ACLTokenMaxExpirationTTL: 1<<63 - 1,
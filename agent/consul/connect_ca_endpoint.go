@@ -11,6 +11,7 @@ import (
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/go-memdb"
 
+	"github.com/hernad/consul/acl"
 	"github.com/hernad/consul/agent/connect"
 	"github.com/hernad/consul/agent/consul/state"
 	"github.com/hernad/consul/agent/structs"
@@ -106,6 +107,152 @@ func (s *ConnectCA) ConfigurationSet(
 	return s.srv.caManager.UpdateConfiguration(args)
 }
 
+// ConfigurationDryRun validates a new configuration for the CA and reports
+// what applying it would change, without applying it.
+func (s *ConnectCA) ConfigurationDryRun(
+	args *structs.CARequest,
+	reply *structs.CADryRunReport) error {
+	// Exit early if Connect hasn't been enabled.
+	if !s.srv.config.ConnectEnabled {
+		return ErrConnectNotEnabled
+	}
+
+	if done, err := s.srv.ForwardRPC("ConnectCA.ConfigurationDryRun", args, reply); done {
+		return err
+	}
+
+	// This action requires operator write access, like applying the
+	// configuration does.
+	authz, err := s.srv.ResolveToken(args.Token)
+	if err != nil {
+		return err
+	}
+	if err := authz.ToAllowAuthorizer().OperatorWriteAllowed(nil); err != nil {
+		return err
+	}
+
+	report, err := s.srv.caManager.DryRunConfiguration(args, s.srv.router.GetDatacenters())
+	if err != nil {
+		return err
+	}
+	*reply = *report
+	return nil
+}
+
+// Revoke revokes a leaf certificate or a service identity in every
+// datacenter.
+func (s *ConnectCA) Revoke(
+	args *structs.CARevocationRequest,
+	reply *structs.CARevocation) error {
+	// Exit early if Connect hasn't been enabled.
+	if !s.srv.config.ConnectEnabled {
+		return ErrConnectNotEnabled
+	}
+
+	// Revocations are written in the primary datacenter and replicated to
+	// the others along with the roots.
+	args.Datacenter = s.srv.config.PrimaryDatacenter
+
+	if done, err := s.srv.ForwardRPC("ConnectCA.Revoke", args, reply); done {
+		return err
+	}
+
+	// This action requires operator write access.
+	authz, err := s.srv.ResolveToken(args.Token)
+	if err != nil {
+		return err
+	}
+	if err := authz.ToAllowAuthorizer().OperatorWriteAllowed(nil); err != nil {
+		return err
+	}
+
+	if args.Revocation == nil {
+		return fmt.Errorf("missing revocation")
+	}
+
+	rev, err := s.srv.caManager.Revoke(args.Revocation)
+	if err != nil {
+		return err
+	}
+	*reply = *rev
+	return nil
+}
+
+// RevocationDelete deletes a revocation in every datacenter.
+func (s *ConnectCA) RevocationDelete(
+	args *structs.CARevocationRequest,
+	reply *struct{}) error {
+	// Exit early if Connect hasn't been enabled.
+	if !s.srv.config.ConnectEnabled {
+		return ErrConnectNotEnabled
+	}
+
+	args.Datacenter = s.srv.config.PrimaryDatacenter
+
+	if done, err := s.srv.ForwardRPC("ConnectCA.RevocationDelete", args, reply); done {
+		return err
+	}
+
+	// This action requires operator write access.
+	authz, err := s.srv.ResolveToken(args.Token)
+	if err != nil {
+		return err
+	}
+	if err := authz.ToAllowAuthorizer().OperatorWriteAllowed(nil); err != nil {
+		return err
+	}
+
+	if args.Revocation == nil || args.Revocation.ID == "" {
+		return fmt.Errorf("missing revocation ID")
+	}
+
+	return s.srv.caManager.DeleteRevocation(args.Revocation.ID)
+}
+
+// RevocationListSet stores the revocation lists signed by a secondary
+// datacenter, replacing its previous lists.
+func (s *ConnectCA) RevocationListSet(
+	args *structs.CARevocationListRequest,
+	reply *struct{}) error {
+	// Exit early if Connect hasn't been enabled.
+	if !s.srv.config.ConnectEnabled {
+		return ErrConnectNotEnabled
+	}
+
+	if done, err := s.srv.ForwardRPC("ConnectCA.RevocationListSet", args, reply); done {
+		return err
+	}
+
+	// Verify we are allowed to serve this request
+	if s.srv.config.PrimaryDatacenter != s.srv.config.Datacenter {
+		return ErrNotPrimaryDatacenter
+	}
+
+	// This action requires operator write access.
+	authz, err := s.srv.ResolveToken(args.Token)
+	if err != nil {
+		return err
+	}
+	if err := authz.ToAllowAuthorizer().OperatorWriteAllowed(nil); err != nil {
+		return err
+	}
+
+	// The primary datacenter signs its own lists.
+	if args.SourceDatacenter == "" || args.SourceDatacenter == s.srv.config.Datacenter {
+		return fmt.Errorf("invalid source datacenter %q", args.SourceDatacenter)
+	}
+	for _, l := range args.RevocationLists {
+		if l.SigningKeyID == "" || l.PEM == "" {
+			return fmt.Errorf("revocation lists must have a SigningKeyID and a PEM")
+		}
+		if l.Datacenter != args.SourceDatacenter {
+			return fmt.Errorf("revocation list of datacenter %q published by %q", l.Datacenter, args.SourceDatacenter)
+		}
+	}
+
+	return s.srv.setCARevocationLists(args.SourceDatacenter, args.RevocationLists)
+}
+
 // Roots returns the currently trusted root certificates.
 func (s *ConnectCA) Roots(
 	args *structs.DCSpecificRequest,
@@ -165,6 +312,62 @@ func (s *ConnectCA) Sign(
 	return nil
 }
 
+// SignJWTSVID issues a JWT-SVID to a service. The signing keys only exist in
+// the primary datacenter, so the other datacenters authorize the request
+// locally and forward it there.
+func (s *ConnectCA) SignJWTSVID(
+	args *structs.CAJWTSVIDRequest,
+	reply *structs.JWTSVID) error {
+	// Exit early if Connect hasn't been enabled.
+	if !s.srv.config.ConnectEnabled {
+		return ErrConnectNotEnabled
+	}
+
+	if done, err := s.srv.ForwardRPC("ConnectCA.SignJWTSVID", args, reply); done {
+		return err
+	}
+
+	authz, err := s.srv.ResolveToken(args.Token)
+	if err != nil {
+		return err
+	}
+
+	dc := s.srv.config.Datacenter
+	if args.SourceDatacenter != "" {
+		// The request was forwarded by a server of another datacenter that
+		// already authorized it, which requires operator write access like
+		// the other requests between the CAs.
+		if s.srv.config.PrimaryDatacenter != s.srv.config.Datacenter {
+			return ErrNotPrimaryDatacenter
+		}
+		if err := authz.ToAllowAuthorizer().OperatorWriteAllowed(nil); err != nil {
+			return err
+		}
+		dc = args.SourceDatacenter
+	} else {
+		var authzContext acl.AuthorizerContext
+		args.FillAuthzContext(&authzContext)
+		if err := authz.ToAllowAuthorizer().ServiceWriteAllowed(args.Service, &authzContext); err != nil {
+			return err
+		}
+	}
+
+	if s.srv.config.PrimaryDatacenter != s.srv.config.Datacenter {
+		fwd := *args
+		fwd.Datacenter = s.srv.config.PrimaryDatacenter
+		fwd.SourceDatacenter = s.srv.config.Datacenter
+		fwd.Token = s.srv.tokens.ReplicationToken()
+		return s.srv.forwardDC("ConnectCA.SignJWTSVID", fwd.Datacenter, &fwd, reply)
+	}
+
+	svid, err := s.srv.caManager.SignJWTSVID(args, dc)
+	if err != nil {
+		return err
+	}
+	*reply = *svid
+	return nil
+}
+
 // SignIntermediate signs an intermediate certificate for a remote datacenter.
 func (s *ConnectCA) SignIntermediate(
 	args *structs.CASignRequest,
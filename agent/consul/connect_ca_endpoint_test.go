@@ -7,22 +7,29 @@ import (
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
+	"math/big"
+	"net"
 	"os"
 	"sync"
 	"testing"
 	"time"
 
 	msgpackrpc "github.com/hashicorp/consul-net-rpc/net-rpc-msgpackrpc"
+	"github.com/hashicorp/go-memdb"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/square/go-jose.v2/jwt"
 
 	"github.com/hernad/consul/acl"
 	"github.com/hernad/consul/agent/connect"
 	ca "github.com/hernad/consul/agent/connect/ca"
+	"github.com/hernad/consul/agent/consul/state"
+	"github.com/hernad/consul/agent/metadata"
 	"github.com/hernad/consul/agent/structs"
 	"github.com/hernad/consul/sdk/testutil"
 	"github.com/hernad/consul/sdk/testutil/retry"
 	"github.com/hernad/consul/testrpc"
+	"github.com/hernad/consul/types"
 )
 
 func testParseCert(t *testing.T, pemValue string) *x509.Certificate {
@@ -820,7 +827,557 @@ func TestConnectCAConfig_UpdateSecondary(t *testing.T) {
 	}
 }
 
+func TestConnectCAConfig_DryRun(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	dir1, s1 := testServer(t)
+	defer os.RemoveAll(dir1)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	defer codec.Close()
+
+	testrpc.WaitForTestAgent(t, s1.RPC, "dc1")
+
+	_, oldRoot, err := getTestRoots(s1, "dc1")
+	require.NoError(t, err)
+
+	for _, service := range []*structs.NodeService{
+		{Kind: structs.ServiceKindMeshGateway, Service: "mesh-gateway", Port: 8443},
+		{
+			Kind:    structs.ServiceKindConnectProxy,
+			Service: "web-sidecar-proxy",
+			Port:    21000,
+			Proxy:   structs.ConnectProxyConfig{DestinationServiceName: "web"},
+		},
+	} {
+		args := &structs.RegisterRequest{
+			Datacenter: "dc1",
+			Node:       "node1",
+			Address:    "127.0.0.1",
+			Service:    service,
+		}
+		var out struct{}
+		require.NoError(t, msgpackrpc.CallWithCodec(codec, "Catalog.Register", args, &out))
+	}
+
+	// caSerial returns the last serial number used by the Consul provider.
+	caSerial := func(t *testing.T) uint64 {
+		snap := s1.fsm.State().Snapshot()
+		defer snap.Close()
+		iter, err := snap.Indexes()
+		require.NoError(t, err)
+		for raw := iter.Next(); raw != nil; raw = iter.Next() {
+			if idx := raw.(*state.IndexEntry); idx.Key == "connect-ca-builtin-serial" {
+				return idx.Value
+			}
+		}
+		return 0
+	}
+	serial := caSerial(t)
+
+	dryRun := func(t *testing.T, config *structs.CAConfiguration) *structs.CADryRunReport {
+		args := &structs.CARequest{
+			Datacenter: "dc1",
+			Config:     config,
+		}
+		var reply structs.CADryRunReport
+		require.NoError(t, msgpackrpc.CallWithCodec(codec, "ConnectCA.ConfigurationDryRun", args, &reply))
+		return &reply
+	}
+
+	t.Run("no changes", func(t *testing.T) {
+		args := &structs.DCSpecificRequest{Datacenter: "dc1"}
+		var current structs.CAConfiguration
+		require.NoError(t, msgpackrpc.CallWithCodec(codec, "ConnectCA.ConfigurationGet", args, &current))
+
+		report := dryRun(t, &structs.CAConfiguration{
+			Provider: current.Provider,
+			Config:   current.Config,
+		})
+		require.True(t, report.NoOp)
+		require.False(t, report.RootRotation)
+	})
+
+	t.Run("config only", func(t *testing.T) {
+		report := dryRun(t, &structs.CAConfiguration{
+			Provider: "consul",
+			Config: map[string]interface{}{
+				"LeafCertTTL": "96h",
+			},
+		})
+		require.False(t, report.NoOp)
+		require.False(t, report.RootRotation)
+		require.Equal(t, structs.CADryRunCrossSigningNotRequired, report.CrossSigning)
+		require.Equal(t, oldRoot.ID, report.NewRoot.ID)
+		require.Empty(t, report.Gateways)
+	})
+
+	t.Run("root rotation", func(t *testing.T) {
+		report := dryRun(t, &structs.CAConfiguration{
+			Provider: "consul",
+			Config: map[string]interface{}{
+				"PrivateKeyType": "rsa",
+				"PrivateKeyBits": 2048,
+			},
+		})
+		require.True(t, report.RootRotation)
+		require.Equal(t, structs.CADryRunCrossSigningSucceeded, report.CrossSigning)
+		require.Equal(t, oldRoot.ID, report.CurrentRoot.ID)
+		require.NotEqual(t, oldRoot.ID, report.NewRoot.ID)
+		require.Equal(t, "rsa", report.NewRoot.PrivateKeyType)
+		require.Equal(t, 2048, report.NewRoot.PrivateKeyBits)
+		require.Equal(t, []structs.CADryRunDatacenter{{
+			Datacenter:     "dc1",
+			Primary:        true,
+			Provider:       "consul",
+			PrivateKeyType: "rsa",
+			PrivateKeyBits: 2048,
+		}}, report.Datacenters)
+		require.Len(t, report.Gateways, 1)
+		require.Equal(t, structs.ServiceKindMeshGateway, report.Gateways[0].Kind)
+		require.Equal(t, "mesh-gateway", report.Gateways[0].Name)
+		require.Equal(t, 1, report.Gateways[0].Instances)
+		require.Equal(t, 1, report.Proxies)
+		require.Empty(t, report.Warnings)
+	})
+
+	t.Run("force without cross-signing", func(t *testing.T) {
+		report := dryRun(t, &structs.CAConfiguration{
+			Provider: "consul",
+			Config: map[string]interface{}{
+				"PrivateKeyType": "ec",
+				"PrivateKeyBits": 384,
+			},
+			ForceWithoutCrossSigning: true,
+		})
+		require.True(t, report.RootRotation)
+		require.Equal(t, structs.CADryRunCrossSigningSkipped, report.CrossSigning)
+		require.Len(t, report.Warnings, 1)
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		args := &structs.CARequest{
+			Datacenter: "dc1",
+			Config: &structs.CAConfiguration{
+				Provider: "consul",
+				Config: map[string]interface{}{
+					"PrivateKeyType": "rsa",
+					"PrivateKeyBits": 1024,
+				},
+			},
+		}
+		var reply structs.CADryRunReport
+		err := msgpackrpc.CallWithCodec(codec, "ConnectCA.ConfigurationDryRun", args, &reply)
+		require.ErrorContains(t, err, "error configuring provider")
+	})
+
+	// None of the dry runs changed the CA.
+	require.Equal(t, serial, caSerial(t))
+	rootList, activeRoot, err := getTestRoots(s1, "dc1")
+	require.NoError(t, err)
+	require.Len(t, rootList.Roots, 1)
+	require.Equal(t, oldRoot.ID, activeRoot.ID)
+
+	args := &structs.DCSpecificRequest{Datacenter: "dc1"}
+	var config structs.CAConfiguration
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "ConnectCA.ConfigurationGet", args, &config))
+	require.Equal(t, s1.config.CAConfig.Config["LeafCertTTL"], config.Config["LeafCertTTL"])
+}
+
+func TestConnectCAConfig_DryRun_Datacenters(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	dir1, s1 := testServerWithConfig(t, func(c *Config) {
+		c.Datacenter = "primary"
+		c.PrimaryDatacenter = "primary"
+	})
+	defer os.RemoveAll(dir1)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	defer codec.Close()
+
+	testrpc.WaitForLeader(t, s1.RPC, "primary")
+
+	dir2, s2 := testServerWithConfig(t, func(c *Config) {
+		c.Datacenter = "secondary"
+		c.PrimaryDatacenter = "primary"
+	})
+	defer os.RemoveAll(dir2)
+	defer s2.Shutdown()
+
+	joinWAN(t, s2, s1)
+	testrpc.WaitForLeader(t, s2.RPC, "secondary")
+
+	_, activeRoot, err := getTestRoots(s1, "primary")
+	require.NoError(t, err)
+	testrpc.WaitForActiveCARoot(t, s2.RPC, "secondary", activeRoot)
+
+	rsaConfig := &structs.CAConfiguration{
+		Provider: "consul",
+		Config: map[string]interface{}{
+			"PrivateKeyType": "rsa",
+			"PrivateKeyBits": 2048,
+		},
+	}
+
+	t.Run("primary", func(t *testing.T) {
+		args := &structs.CARequest{
+			Datacenter: "primary",
+			Config:     rsaConfig,
+		}
+		var report structs.CADryRunReport
+		require.NoError(t, msgpackrpc.CallWithCodec(codec, "ConnectCA.ConfigurationDryRun", args, &report))
+
+		require.True(t, report.RootRotation)
+		require.Len(t, report.Datacenters, 2)
+		require.Equal(t, structs.CADryRunDatacenter{
+			Datacenter:     "secondary",
+			Provider:       "consul",
+			PrivateKeyType: "ec",
+			PrivateKeyBits: 256,
+		}, report.Datacenters[1])
+		require.Len(t, report.Warnings, 1)
+		require.Contains(t, report.Warnings[0], `Datacenter "secondary" uses ec-256 keys but the new root uses rsa-2048 keys`)
+	})
+
+	t.Run("secondary", func(t *testing.T) {
+		args := &structs.CARequest{
+			Datacenter: "secondary",
+			Config:     rsaConfig,
+		}
+		var report structs.CADryRunReport
+		require.NoError(t, msgpackrpc.CallWithCodec(codec, "ConnectCA.ConfigurationDryRun", args, &report))
+
+		require.False(t, report.RootRotation)
+		require.True(t, report.IntermediateRotation)
+		require.Empty(t, report.Datacenters)
+		require.Len(t, report.Warnings, 1)
+		require.Contains(t, report.Warnings[0], "uses rsa-2048 keys but the root of the primary datacenter uses ec-256 keys")
+	})
+}
+
 // Test CA signing
+func TestConnectCARevoke(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	dir1, s1 := testServerWithConfig(t, func(c *Config) {
+		c.Datacenter = "primary"
+		c.PrimaryDatacenter = "primary"
+	})
+	defer os.RemoveAll(dir1)
+	defer s1.Shutdown()
+	testrpc.WaitForLeader(t, s1.RPC, "primary")
+
+	dir2, s2 := testServerWithConfig(t, func(c *Config) {
+		c.Datacenter = "secondary"
+		c.PrimaryDatacenter = "primary"
+	})
+	defer os.RemoveAll(dir2)
+	defer s2.Shutdown()
+	codec := rpcClient(t, s2)
+	defer codec.Close()
+
+	joinWAN(t, s2, s1)
+	testrpc.WaitForLeader(t, s2.RPC, "secondary")
+	_, activeRoot, err := getTestRoots(s1, "primary")
+	require.NoError(t, err)
+	testrpc.WaitForActiveCARoot(t, s2.RPC, "secondary", activeRoot)
+
+	sign := func(service string) error {
+		id := connect.TestSpiffeIDServiceWithHostDC(t, service, connect.TestClusterID+".consul", "secondary")
+		csr, _ := connect.TestCSR(t, id)
+		args := &structs.CASignRequest{Datacenter: "secondary", CSR: csr}
+		var reply structs.IssuedCert
+		return msgpackrpc.CallWithCodec(codec, "ConnectCA.Sign", args, &reply)
+	}
+	require.NoError(t, sign("web"))
+
+	// Revocations must name either a serial or a service.
+	args := &structs.CARevocationRequest{
+		Datacenter: "secondary",
+		Revocation: &structs.CARevocation{Reason: "compromised"},
+	}
+	var rev structs.CARevocation
+	err = msgpackrpc.CallWithCodec(codec, "ConnectCA.Revoke", args, &rev)
+	require.ErrorContains(t, err, "either a SerialNumber or a Service")
+
+	args.Revocation.SerialNumber = "not hex"
+	err = msgpackrpc.CallWithCodec(codec, "ConnectCA.Revoke", args, &rev)
+	require.ErrorContains(t, err, "must be hex encoded")
+
+	// Revocations made in a secondary are written in the primary, with the
+	// serial number in the format used by IssuedCert.
+	args.Revocation.SerialNumber = "0A1B2C"
+	var serialRev structs.CARevocation
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "ConnectCA.Revoke", args, &serialRev))
+	require.NotEmpty(t, serialRev.ID)
+	require.Equal(t, "0a:1b:2c", serialRev.SerialNumber)
+
+	args.Revocation = &structs.CARevocation{Service: "web", Reason: "compromised"}
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "ConnectCA.Revoke", args, &rev))
+
+	_, revs, err := s1.fsm.State().CARevocations(nil)
+	require.NoError(t, err)
+	require.Len(t, revs, 2)
+
+	// The revocations are distributed with the roots of both datacenters.
+	for _, dc := range []string{"primary", "secondary"} {
+		retry.Run(t, func(r *retry.R) {
+			var roots structs.IndexedCARoots
+			rootArgs := &structs.DCSpecificRequest{Datacenter: dc}
+			require.NoError(r, msgpackrpc.CallWithCodec(codec, "ConnectCA.Roots", rootArgs, &roots))
+			require.Len(r, roots.Revocations, 2)
+			require.NotNil(r, roots.SerialRevoked("0a:1b:2c"))
+			require.NotNil(r, roots.ServiceRevoked("web", nil))
+		})
+	}
+
+	// Certificates are no longer signed for the revoked identity.
+	err = sign("web")
+	require.ErrorContains(t, err, `service identity "web" has been revoked`)
+	require.NoError(t, sign("api"))
+
+	// Deleting the revocation trusts the identity again.
+	delArgs := &structs.CARevocationRequest{
+		Datacenter: "secondary",
+		Revocation: &structs.CARevocation{ID: rev.ID},
+	}
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "ConnectCA.RevocationDelete", delArgs, &struct{}{}))
+	retry.Run(t, func(r *retry.R) {
+		_, revs, err := s2.fsm.State().CARevocations(nil)
+		require.NoError(r, err)
+		require.Len(r, revs, 1)
+		require.Equal(r, serialRev.ID, revs[0].ID)
+	})
+	require.NoError(t, sign("web"))
+}
+
+func TestConnectCARevocationLists(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	dir1, s1 := testServerWithConfig(t, func(c *Config) {
+		c.Datacenter = "primary"
+		c.PrimaryDatacenter = "primary"
+	})
+	defer os.RemoveAll(dir1)
+	defer s1.Shutdown()
+	testrpc.WaitForLeader(t, s1.RPC, "primary")
+
+	dir2, s2 := testServerWithConfig(t, func(c *Config) {
+		c.Datacenter = "secondary"
+		c.PrimaryDatacenter = "primary"
+	})
+	defer os.RemoveAll(dir2)
+	defer s2.Shutdown()
+	codec := rpcClient(t, s2)
+	defer codec.Close()
+
+	joinWAN(t, s2, s1)
+	testrpc.WaitForLeader(t, s2.RPC, "secondary")
+	_, activeRoot, err := getTestRoots(s1, "primary")
+	require.NoError(t, err)
+	testrpc.WaitForActiveCARoot(t, s2.RPC, "secondary", activeRoot)
+
+	// Both datacenters sign a list for their leaf signing key and the
+	// secondary publishes its list in the primary.
+	retry.Run(t, func(r *retry.R) {
+		_, lists, err := s1.fsm.State().CARevocationLists(nil)
+		require.NoError(r, err)
+		require.Len(r, lists, 2)
+	})
+
+	args := &structs.CARevocationRequest{
+		Datacenter: "secondary",
+		Revocation: &structs.CARevocation{SerialNumber: "0a:1b:2c"},
+	}
+	var serialRev structs.CARevocation
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "ConnectCA.Revoke", args, &serialRev))
+	require.Equal(t, serialRev.RevokedAt.Add(72*time.Hour), serialRev.ExpiresAt)
+
+	args.Revocation = &structs.CARevocation{Service: "web"}
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "ConnectCA.Revoke", args, &structs.CARevocation{}))
+
+	// The lists are not distributed until they cover the certificates
+	// signed before the first of them, one leaf certificate TTL later.
+	var roots structs.IndexedCARoots
+	rootArgs := &structs.DCSpecificRequest{Datacenter: "primary"}
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "ConnectCA.Roots", rootArgs, &roots))
+	require.Len(t, roots.Revocations, 2)
+	require.Empty(t, roots.RevocationLists)
+
+	covered := serialRev.ExpiresAt
+	for _, dc := range []string{"primary", "secondary"} {
+		retry.Run(t, func(r *retry.R) {
+			require.NoError(r, s2.updateCARevocationLists(memdb.NewWatchSet(), covered))
+			require.NoError(r, s1.updateCARevocationLists(memdb.NewWatchSet(), covered))
+
+			var roots structs.IndexedCARoots
+			rootArgs := &structs.DCSpecificRequest{Datacenter: dc}
+			require.NoError(r, msgpackrpc.CallWithCodec(codec, "ConnectCA.Roots", rootArgs, &roots))
+			require.Len(r, roots.RevocationLists, 2)
+
+			// Every list revokes the serial number.
+			for _, l := range roots.RevocationLists {
+				block, _ := pem.Decode([]byte(l.PEM))
+				require.NotNil(r, block)
+				crl, err := x509.ParseRevocationList(block.Bytes)
+				require.NoError(r, err)
+				require.Len(r, crl.RevokedCertificates, 1)
+				require.Equal(r, big.NewInt(0x0a1b2c), crl.RevokedCertificates[0].SerialNumber)
+			}
+		})
+	}
+
+	// A new datacenter has no list yet, so the lists are no longer
+	// distributed, and the blocking queries of the roots are woken up.
+	rootArgs.QueryOptions = structs.QueryOptions{
+		MinQueryIndex: roots.Index,
+		MaxQueryTime:  30 * time.Second,
+	}
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "ConnectCA.Roots", rootArgs, &roots))
+	require.Len(t, roots.RevocationLists, 2)
+	rootArgs.MinQueryIndex = roots.Index
+	require.NoError(t, s1.router.AddServer(types.AreaWAN, &metadata.Server{
+		ID:         "tertiary-server",
+		Name:       "s3.tertiary",
+		ShortName:  "s3",
+		Datacenter: "tertiary",
+		Addr:       &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 1},
+	}))
+	start := time.Now()
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "ConnectCA.Roots", rootArgs, &roots))
+	require.Less(t, time.Since(start), 10*time.Second)
+	require.Empty(t, roots.RevocationLists)
+	rootArgs.QueryOptions = structs.QueryOptions{}
+
+	// The revocation is deleted once the revoked certificate expired, and the
+	// lists are no longer distributed without a serial number to revoke.
+	require.NoError(t, s1.updateCARevocationLists(memdb.NewWatchSet(), serialRev.ExpiresAt.Add(time.Minute)))
+	retry.Run(t, func(r *retry.R) {
+		var roots structs.IndexedCARoots
+		rootArgs := &structs.DCSpecificRequest{Datacenter: "secondary"}
+		require.NoError(r, msgpackrpc.CallWithCodec(codec, "ConnectCA.Roots", rootArgs, &roots))
+		require.Len(r, roots.Revocations, 1)
+		require.Equal(r, "web", roots.Revocations[0].Service)
+		require.Empty(r, roots.RevocationLists)
+	})
+
+	// Only the secondary datacenters publish their lists.
+	listArgs := &structs.CARevocationListRequest{
+		Datacenter:       "primary",
+		SourceDatacenter: "primary",
+	}
+	err = msgpackrpc.CallWithCodec(codec, "ConnectCA.RevocationListSet", listArgs, &struct{}{})
+	require.ErrorContains(t, err, "invalid source datacenter")
+}
+
+func TestConnectCASignJWTSVID(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	dir1, s1 := testServerWithConfig(t, func(c *Config) {
+		c.Datacenter = "primary"
+		c.PrimaryDatacenter = "primary"
+	})
+	defer os.RemoveAll(dir1)
+	defer s1.Shutdown()
+	testrpc.WaitForLeader(t, s1.RPC, "primary")
+
+	dir2, s2 := testServerWithConfig(t, func(c *Config) {
+		c.Datacenter = "secondary"
+		c.PrimaryDatacenter = "primary"
+	})
+	defer os.RemoveAll(dir2)
+	defer s2.Shutdown()
+	codec := rpcClient(t, s2)
+	defer codec.Close()
+
+	joinWAN(t, s2, s1)
+	testrpc.WaitForLeader(t, s2.RPC, "secondary")
+	_, activeRoot, err := getTestRoots(s1, "primary")
+	require.NoError(t, err)
+	testrpc.WaitForActiveCARoot(t, s2.RPC, "secondary", activeRoot)
+
+	// The public keys are replicated to the secondary datacenter.
+	var roots structs.IndexedCARoots
+	retry.Run(t, func(r *retry.R) {
+		rootArgs := &structs.DCSpecificRequest{Datacenter: "secondary"}
+		require.NoError(r, msgpackrpc.CallWithCodec(codec, "ConnectCA.Roots", rootArgs, &roots))
+		require.Len(r, roots.JWTSigningKeys, 1)
+	})
+	require.Empty(t, roots.JWTSigningKeys[0].PrivateKey)
+
+	args := &structs.CAJWTSVIDRequest{
+		Datacenter: "secondary",
+		Service:    "web",
+		Audience:   []string{"api"},
+	}
+	var svid structs.JWTSVID
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "ConnectCA.SignJWTSVID", args, &svid))
+
+	// The token is signed in the primary for the service of the secondary.
+	expectedID := connect.SpiffeIDService{
+		Host:       roots.TrustDomain,
+		Namespace:  "default",
+		Datacenter: "secondary",
+		Service:    "web",
+	}
+	require.Equal(t, expectedID.URI().String(), svid.SpiffeID)
+
+	keySet, err := connect.JWTSVIDKeySet(roots.JWTSigningKeys)
+	require.NoError(t, err)
+	parsed, err := jwt.ParseSigned(svid.Token)
+	require.NoError(t, err)
+	keys := keySet.Key(parsed.Headers[0].KeyID)
+	require.Len(t, keys, 1)
+	var claims jwt.Claims
+	require.NoError(t, parsed.Claims(keys[0].Key, &claims))
+	require.NoError(t, claims.Validate(jwt.Expected{
+		Issuer:   connect.JWTSVIDIssuer(roots.TrustDomain),
+		Subject:  svid.SpiffeID,
+		Audience: jwt.Audience{"api"},
+	}))
+	require.WithinDuration(t, time.Now().Add(connect.DefaultJWTSVIDTTL), svid.ExpiresAt, time.Minute)
+
+	// Invalid requests are rejected.
+	args.TTL = 2 * connect.MaxJWTSVIDTTL
+	err = msgpackrpc.CallWithCodec(codec, "ConnectCA.SignJWTSVID", args, &svid)
+	require.ErrorContains(t, err, "TTL must be at most")
+	args.TTL = 0
+	args.Audience = nil
+	err = msgpackrpc.CallWithCodec(codec, "ConnectCA.SignJWTSVID", args, &svid)
+	require.ErrorContains(t, err, "at least one audience is required")
+
+	// Revoked identities cannot get tokens.
+	revArgs := &structs.CARevocationRequest{
+		Datacenter: "primary",
+		Revocation: &structs.CARevocation{Service: "web"},
+	}
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "ConnectCA.Revoke", revArgs, &structs.CARevocation{}))
+	args.Audience = []string{"api"}
+	err = msgpackrpc.CallWithCodec(codec, "ConnectCA.SignJWTSVID", args, &svid)
+	require.ErrorContains(t, err, `service identity "web" has been revoked`)
+}
+
 func TestConnectCASign(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
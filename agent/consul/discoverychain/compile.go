@@ -1096,6 +1096,18 @@ RESOLVE_AGAIN:
 		}
 	}
 
+	// HTTP/3 is only used for targets reached directly, since mesh gateways
+	// only proxy TCP. The same holds for terminating gateways, but whether a
+	// target is served by one is only known from its endpoints, so the
+	// clusters of such targets ignore EnableHTTP3.
+	if !target.External && target.Peer == "" &&
+		target.Datacenter == c.evaluateInDatacenter &&
+		target.Partition == c.evaluateInPartition {
+		if serviceDefault := c.entries.GetService(targetID); serviceDefault != nil {
+			target.EnableHTTP3 = serviceDefault.EnableHTTP3
+		}
+	}
+
 	// Retain this target in the final results.
 	c.retainedTargets[target.ID] = struct{}{}
 
@@ -147,6 +147,9 @@ func init() {
 	registerCommand(structs.PeeringSecretsWriteType, (*FSM).applyPeeringSecretsWrite)
 	registerCommand(structs.ResourceOperationType, (*FSM).applyResourceOperation)
 	registerCommand(structs.UpdateVirtualIPRequestType, (*FSM).applyManualVirtualIPs)
+	registerCommand(structs.ACLPolicyTemplateSetType, (*FSM).applyACLPolicyTemplateSetOperation)
+	registerCommand(structs.ACLPolicyTemplateDeleteType, (*FSM).applyACLPolicyTemplateDeleteOperation)
+	registerCommand(structs.ACLTokenUsageUpdateType, (*FSM).applyACLTokenUsageUpdate)
 }
 
 func (c *FSM) applyRegister(buf []byte, index uint64) interface{} {
@@ -456,6 +459,70 @@ func ApplyConnectCAOperationFromRequest(state *state.Store, req *structs.CAReque
 		}
 
 		return sn
+	case structs.CAOpSetRevocations:
+		if err := state.CASetRevocations(index, req.Revocations); err != nil {
+			return err
+		}
+
+		return true
+	case structs.CAOpDeleteRevocations:
+		ids := make([]string, 0, len(req.Revocations))
+		for _, r := range req.Revocations {
+			ids = append(ids, r.ID)
+		}
+		if err := state.CADeleteRevocations(index, ids); err != nil {
+			return err
+		}
+
+		return true
+	case structs.CAOpSetFederatedTrustBundles:
+		if err := state.CASetFederatedTrustBundles(index, req.FederatedTrustBundles); err != nil {
+			return err
+		}
+
+		return true
+	case structs.CAOpDeleteFederatedTrustBundles:
+		names := make([]string, 0, len(req.FederatedTrustBundles))
+		for _, b := range req.FederatedTrustBundles {
+			names = append(names, b.TrustDomain)
+		}
+		if err := state.CADeleteFederatedTrustBundles(index, names); err != nil {
+			return err
+		}
+
+		return true
+	case structs.CAOpSetJWTSigningKeys:
+		if err := state.CASetJWTSigningKeys(index, req.JWTSigningKeys); err != nil {
+			return err
+		}
+
+		return true
+	case structs.CAOpDeleteJWTSigningKeys:
+		ids := make([]string, 0, len(req.JWTSigningKeys))
+		for _, k := range req.JWTSigningKeys {
+			ids = append(ids, k.ID)
+		}
+		if err := state.CADeleteJWTSigningKeys(index, ids); err != nil {
+			return err
+		}
+
+		return true
+	case structs.CAOpSetRevocationLists:
+		if err := state.CASetRevocationLists(index, req.RevocationLists); err != nil {
+			return err
+		}
+
+		return true
+	case structs.CAOpDeleteRevocationLists:
+		ids := make([]string, 0, len(req.RevocationLists))
+		for _, l := range req.RevocationLists {
+			ids = append(ids, l.SigningKeyID)
+		}
+		if err := state.CADeleteRevocationLists(index, ids); err != nil {
+			return err
+		}
+
+		return true
 	default:
 		return fmt.Errorf("Invalid CA operation '%s'", req.Op)
 	}
@@ -614,6 +681,39 @@ func (c *FSM) applyACLRoleDeleteOperation(buf []byte, index uint64) interface{}
 	return c.state.ACLRoleBatchDelete(index, req.RoleIDs)
 }
 
+func (c *FSM) applyACLPolicyTemplateSetOperation(buf []byte, index uint64) interface{} {
+	var req structs.ACLPolicyTemplateBatchSetRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+	defer metrics.MeasureSinceWithLabels([]string{"fsm", "acl", "policytemplate"}, time.Now(),
+		[]metrics.Label{{Name: "op", Value: "upsert"}})
+
+	return c.state.ACLPolicyTemplateBatchSet(index, req.PolicyTemplates)
+}
+
+func (c *FSM) applyACLPolicyTemplateDeleteOperation(buf []byte, index uint64) interface{} {
+	var req structs.ACLPolicyTemplateBatchDeleteRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+	defer metrics.MeasureSinceWithLabels([]string{"fsm", "acl", "policytemplate"}, time.Now(),
+		[]metrics.Label{{Name: "op", Value: "delete"}})
+
+	return c.state.ACLPolicyTemplateBatchDelete(index, req.PolicyTemplateIDs)
+}
+
+func (c *FSM) applyACLTokenUsageUpdate(buf []byte, index uint64) interface{} {
+	var req structs.ACLTokenUsageUpdateRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+	defer metrics.MeasureSinceWithLabels([]string{"fsm", "acl", "token"}, time.Now(),
+		[]metrics.Label{{Name: "op", Value: "usage"}})
+
+	return c.state.ACLTokenUsageBatchUpdate(index, req.Usages)
+}
+
 func (c *FSM) applyACLBindingRuleSetOperation(buf []byte, index uint64) interface{} {
 	var req structs.ACLBindingRuleBatchSetRequest
 	if err := structs.Decode(buf, &req); err != nil {
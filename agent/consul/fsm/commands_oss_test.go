@@ -1278,6 +1278,174 @@ func TestFSM_CARoots(t *testing.T) {
 	}
 }
 
+func TestFSM_CARevocations(t *testing.T) {
+	t.Parallel()
+
+	logger := testutil.Logger(t)
+	fsm, err := New(nil, logger)
+	require.NoError(t, err)
+
+	rev1 := &structs.CARevocation{ID: "rev1", Service: "web"}
+	rev2 := &structs.CARevocation{ID: "rev2", SerialNumber: "0a:1b"}
+
+	apply := func(req structs.CARequest) {
+		buf, err := structs.Encode(structs.ConnectCARequestType, req)
+		require.NoError(t, err)
+		resp := fsm.Apply(makeLog(buf))
+		require.Equal(t, true, resp)
+	}
+
+	apply(structs.CARequest{
+		Op:          structs.CAOpSetRevocations,
+		Revocations: []*structs.CARevocation{rev1, rev2},
+	})
+
+	_, revs, err := fsm.state.CARevocations(nil)
+	require.NoError(t, err)
+	require.Len(t, revs, 2)
+
+	apply(structs.CARequest{
+		Op:          structs.CAOpDeleteRevocations,
+		Revocations: []*structs.CARevocation{{ID: "rev1"}},
+	})
+
+	_, revs, err = fsm.state.CARevocations(nil)
+	require.NoError(t, err)
+	require.Len(t, revs, 1)
+	require.Equal(t, "rev2", revs[0].ID)
+
+	// Invalid revocations are rejected.
+	buf, err := structs.Encode(structs.ConnectCARequestType, structs.CARequest{
+		Op:          structs.CAOpSetRevocations,
+		Revocations: []*structs.CARevocation{{ID: "rev3"}},
+	})
+	require.NoError(t, err)
+	resp := fsm.Apply(makeLog(buf))
+	require.Error(t, resp.(error))
+}
+
+func TestFSM_CAFederatedTrustBundles(t *testing.T) {
+	t.Parallel()
+
+	logger := testutil.Logger(t)
+	fsm, err := New(nil, logger)
+	require.NoError(t, err)
+
+	apply := func(req structs.CARequest) {
+		buf, err := structs.Encode(structs.ConnectCARequestType, req)
+		require.NoError(t, err)
+		resp := fsm.Apply(makeLog(buf))
+		require.Equal(t, true, resp)
+	}
+
+	apply(structs.CARequest{
+		Op: structs.CAOpSetFederatedTrustBundles,
+		FederatedTrustBundles: []*structs.FederatedTrustBundle{
+			{TrustDomain: "example.org", RootPEMs: []string{"pem1"}, Sequence: 3},
+			{TrustDomain: "example.com", RootPEMs: []string{"pem2"}},
+		},
+	})
+
+	_, bundles, err := fsm.state.CAFederatedTrustBundles(nil)
+	require.NoError(t, err)
+	require.Len(t, bundles, 2)
+
+	apply(structs.CARequest{
+		Op:                    structs.CAOpDeleteFederatedTrustBundles,
+		FederatedTrustBundles: []*structs.FederatedTrustBundle{{TrustDomain: "example.com"}},
+	})
+
+	_, bundles, err = fsm.state.CAFederatedTrustBundles(nil)
+	require.NoError(t, err)
+	require.Len(t, bundles, 1)
+	require.Equal(t, "example.org", bundles[0].TrustDomain)
+	require.Equal(t, uint64(3), bundles[0].Sequence)
+}
+
+func TestFSM_CAJWTSigningKeys(t *testing.T) {
+	t.Parallel()
+
+	logger := testutil.Logger(t)
+	fsm, err := New(nil, logger)
+	require.NoError(t, err)
+
+	apply := func(req structs.CARequest) interface{} {
+		buf, err := structs.Encode(structs.ConnectCARequestType, req)
+		require.NoError(t, err)
+		return fsm.Apply(makeLog(buf))
+	}
+
+	resp := apply(structs.CARequest{
+		Op: structs.CAOpSetJWTSigningKeys,
+		JWTSigningKeys: []*structs.CAJWTSigningKey{
+			{ID: "key1", PublicKey: "pub1", PrivateKey: "priv1"},
+			{ID: "key2", PublicKey: "pub2", PrivateKey: "priv2", Active: true},
+		},
+	})
+	require.Equal(t, true, resp)
+
+	_, keys, err := fsm.state.CAJWTSigningKeys(nil)
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+
+	resp = apply(structs.CARequest{
+		Op:             structs.CAOpDeleteJWTSigningKeys,
+		JWTSigningKeys: []*structs.CAJWTSigningKey{{ID: "key1"}},
+	})
+	require.Equal(t, true, resp)
+
+	_, keys, err = fsm.state.CAJWTSigningKeys(nil)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	require.Equal(t, "key2", keys[0].ID)
+	require.True(t, keys[0].Active)
+
+	// A second active key is rejected.
+	resp = apply(structs.CARequest{
+		Op:             structs.CAOpSetJWTSigningKeys,
+		JWTSigningKeys: []*structs.CAJWTSigningKey{{ID: "key3", Active: true}},
+	})
+	require.Error(t, resp.(error))
+}
+
+func TestFSM_CARevocationLists(t *testing.T) {
+	t.Parallel()
+
+	logger := testutil.Logger(t)
+	fsm, err := New(nil, logger)
+	require.NoError(t, err)
+
+	apply := func(req structs.CARequest) interface{} {
+		buf, err := structs.Encode(structs.ConnectCARequestType, req)
+		require.NoError(t, err)
+		return fsm.Apply(makeLog(buf))
+	}
+
+	resp := apply(structs.CARequest{
+		Op: structs.CAOpSetRevocationLists,
+		RevocationLists: []*structs.CARevocationList{
+			{SigningKeyID: "aa:bb", Datacenter: "dc1", PEM: "crl1"},
+			{SigningKeyID: "cc:dd", Datacenter: "dc2", PEM: "crl2"},
+		},
+	})
+	require.Equal(t, true, resp)
+
+	_, lists, err := fsm.state.CARevocationLists(nil)
+	require.NoError(t, err)
+	require.Len(t, lists, 2)
+
+	resp = apply(structs.CARequest{
+		Op:              structs.CAOpDeleteRevocationLists,
+		RevocationLists: []*structs.CARevocationList{{SigningKeyID: "aa:bb"}},
+	})
+	require.Equal(t, true, resp)
+
+	_, lists, err = fsm.state.CARevocationLists(nil)
+	require.NoError(t, err)
+	require.Len(t, lists, 1)
+	require.Equal(t, "cc:dd", lists[0].SigningKeyID)
+}
+
 func TestFSM_CABuiltinProvider(t *testing.T) {
 	t.Parallel()
 
@@ -31,11 +31,17 @@ func init() {
 	registerRestorer(structs.ConnectCARequestType, restoreConnectCA)
 	registerRestorer(structs.ConnectCAProviderStateType, restoreConnectCAProviderState)
 	registerRestorer(structs.ConnectCAConfigType, restoreConnectCAConfig)
+	registerRestorer(structs.ConnectCARevocationType, restoreConnectCARevocation)
+	registerRestorer(structs.ConnectCAFederatedTrustBundleType, restoreConnectCAFederatedTrustBundle)
+	registerRestorer(structs.ConnectCAJWTSigningKeyType, restoreConnectCAJWTSigningKey)
+	registerRestorer(structs.ConnectCARevocationListType, restoreConnectCARevocationList)
 	registerRestorer(structs.IndexRequestType, restoreIndex)
 	registerRestorer(structs.ACLTokenSetRequestType, restoreToken)
 	registerRestorer(structs.ACLPolicySetRequestType, restorePolicy)
 	registerRestorer(structs.ConfigEntryRequestType, restoreConfigEntry)
 	registerRestorer(structs.ACLRoleSetRequestType, restoreRole)
+	registerRestorer(structs.ACLPolicyTemplateSetType, restorePolicyTemplate)
+	registerRestorer(structs.ACLTokenUsageUpdateType, restoreTokenUsage)
 	registerRestorer(structs.ACLBindingRuleSetRequestType, restoreBindingRule)
 	registerRestorer(structs.ACLAuthMethodSetRequestType, restoreAuthMethod)
 	registerRestorer(structs.FederationStateRequestType, restoreFederationState)
@@ -84,6 +90,18 @@ func persistOSS(s *snapshot, sink raft.SnapshotSink, encoder *codec.Encoder) err
 	if err := s.persistConnectCAConfig(sink, encoder); err != nil {
 		return err
 	}
+	if err := s.persistConnectCARevocations(sink, encoder); err != nil {
+		return err
+	}
+	if err := s.persistConnectCAFederatedTrustBundles(sink, encoder); err != nil {
+		return err
+	}
+	if err := s.persistConnectCAJWTSigningKeys(sink, encoder); err != nil {
+		return err
+	}
+	if err := s.persistConnectCARevocationLists(sink, encoder); err != nil {
+		return err
+	}
 	if err := s.persistConfigEntries(sink, encoder); err != nil {
 		return err
 	}
@@ -236,6 +254,20 @@ func (s *snapshot) persistACLs(sink raft.SnapshotSink,
 		}
 	}
 
+	templates, err := s.state.ACLPolicyTemplates()
+	if err != nil {
+		return err
+	}
+
+	for template := templates.Next(); template != nil; template = templates.Next() {
+		if _, err := sink.Write([]byte{byte(structs.ACLPolicyTemplateSetType)}); err != nil {
+			return err
+		}
+		if err := encoder.Encode(template.(*structs.ACLPolicyTemplate)); err != nil {
+			return err
+		}
+	}
+
 	roles, err := s.state.ACLRoles()
 	if err != nil {
 		return err
@@ -278,6 +310,20 @@ func (s *snapshot) persistACLs(sink raft.SnapshotSink,
 		}
 	}
 
+	usages, err := s.state.ACLTokenUsages()
+	if err != nil {
+		return err
+	}
+
+	for usage := usages.Next(); usage != nil; usage = usages.Next() {
+		if _, err := sink.Write([]byte{byte(structs.ACLTokenUsageUpdateType)}); err != nil {
+			return err
+		}
+		if err := encoder.Encode(usage.(*structs.ACLTokenUsage)); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -423,6 +469,78 @@ func (s *snapshot) persistConnectCAProviderState(sink raft.SnapshotSink,
 	return nil
 }
 
+func (s *snapshot) persistConnectCARevocations(sink raft.SnapshotSink,
+	encoder *codec.Encoder) error {
+	revs, err := s.state.CARevocations()
+	if err != nil {
+		return err
+	}
+
+	for _, r := range revs {
+		if _, err := sink.Write([]byte{byte(structs.ConnectCARevocationType)}); err != nil {
+			return err
+		}
+		if err := encoder.Encode(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *snapshot) persistConnectCAFederatedTrustBundles(sink raft.SnapshotSink,
+	encoder *codec.Encoder) error {
+	bundles, err := s.state.CAFederatedTrustBundles()
+	if err != nil {
+		return err
+	}
+
+	for _, b := range bundles {
+		if _, err := sink.Write([]byte{byte(structs.ConnectCAFederatedTrustBundleType)}); err != nil {
+			return err
+		}
+		if err := encoder.Encode(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *snapshot) persistConnectCAJWTSigningKeys(sink raft.SnapshotSink,
+	encoder *codec.Encoder) error {
+	keys, err := s.state.CAJWTSigningKeys()
+	if err != nil {
+		return err
+	}
+
+	for _, k := range keys {
+		if _, err := sink.Write([]byte{byte(structs.ConnectCAJWTSigningKeyType)}); err != nil {
+			return err
+		}
+		if err := encoder.Encode(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *snapshot) persistConnectCARevocationLists(sink raft.SnapshotSink,
+	encoder *codec.Encoder) error {
+	lists, err := s.state.CARevocationLists()
+	if err != nil {
+		return err
+	}
+
+	for _, l := range lists {
+		if _, err := sink.Write([]byte{byte(structs.ConnectCARevocationListType)}); err != nil {
+			return err
+		}
+		if err := encoder.Encode(l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *snapshot) persistLegacyIntentions(sink raft.SnapshotSink,
 	encoder *codec.Encoder) error {
 	//nolint:staticcheck
@@ -762,6 +880,50 @@ func restoreConnectCAConfig(header *SnapshotHeader, restore *state.Restore, deco
 	return nil
 }
 
+func restoreConnectCARevocation(header *SnapshotHeader, restore *state.Restore, decoder *codec.Decoder) error {
+	var req structs.CARevocation
+	if err := decoder.Decode(&req); err != nil {
+		return err
+	}
+	if err := restore.CARevocation(&req); err != nil {
+		return err
+	}
+	return nil
+}
+
+func restoreConnectCAFederatedTrustBundle(header *SnapshotHeader, restore *state.Restore, decoder *codec.Decoder) error {
+	var req structs.FederatedTrustBundle
+	if err := decoder.Decode(&req); err != nil {
+		return err
+	}
+	if err := restore.CAFederatedTrustBundle(&req); err != nil {
+		return err
+	}
+	return nil
+}
+
+func restoreConnectCAJWTSigningKey(header *SnapshotHeader, restore *state.Restore, decoder *codec.Decoder) error {
+	var req structs.CAJWTSigningKey
+	if err := decoder.Decode(&req); err != nil {
+		return err
+	}
+	if err := restore.CAJWTSigningKey(&req); err != nil {
+		return err
+	}
+	return nil
+}
+
+func restoreConnectCARevocationList(header *SnapshotHeader, restore *state.Restore, decoder *codec.Decoder) error {
+	var req structs.CARevocationList
+	if err := decoder.Decode(&req); err != nil {
+		return err
+	}
+	if err := restore.CARevocationList(&req); err != nil {
+		return err
+	}
+	return nil
+}
+
 func restoreIndex(header *SnapshotHeader, restore *state.Restore, decoder *codec.Decoder) error {
 	var req state.IndexEntry
 	if err := decoder.Decode(&req); err != nil {
@@ -790,6 +952,22 @@ func restorePolicy(header *SnapshotHeader, restore *state.Restore, decoder *code
 	return restore.ACLPolicy(&req)
 }
 
+func restorePolicyTemplate(header *SnapshotHeader, restore *state.Restore, decoder *codec.Decoder) error {
+	var req structs.ACLPolicyTemplate
+	if err := decoder.Decode(&req); err != nil {
+		return err
+	}
+	return restore.ACLPolicyTemplate(&req)
+}
+
+func restoreTokenUsage(header *SnapshotHeader, restore *state.Restore, decoder *codec.Decoder) error {
+	var req structs.ACLTokenUsage
+	if err := decoder.Decode(&req); err != nil {
+		return err
+	}
+	return restore.ACLTokenUsage(&req)
+}
+
 func restoreConfigEntry(header *SnapshotHeader, restore *state.Restore, decoder *codec.Decoder) error {
 	var req structs.ConfigEntryRequest
 	if err := decoder.Decode(&req); err != nil {
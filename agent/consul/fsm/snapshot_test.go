@@ -140,6 +140,11 @@ func TestFSM_SnapshotRestore_OSS(t *testing.T) {
 	}
 	require.NoError(t, fsm.state.ACLBootstrap(10, 0, token))
 
+	tokenLastUsed := time.Date(2020, 5, 22, 18, 0, 0, 0, time.UTC)
+	require.NoError(t, fsm.state.ACLTokenUsageBatchUpdate(11, structs.ACLTokenUsages{
+		{AccessorID: token.AccessorID, LastUsed: tokenLastUsed},
+	}))
+
 	method := &structs.ACLAuthMethod{
 		Name:        "some-method",
 		Type:        "testing",
@@ -249,6 +254,43 @@ func TestFSM_SnapshotRestore_OSS(t *testing.T) {
 	err = fsm.state.CASetConfig(17, caConfig)
 	require.NoError(t, err)
 
+	// CA Revocations
+	revocation := &structs.CARevocation{
+		ID:        "c5b9ab62-3c1f-4c5b-8e7c-b8a43a7a3a58",
+		Service:   "web",
+		Reason:    "compromised",
+		RevokedAt: time.Now().UTC().Round(time.Second),
+	}
+	require.NoError(t, fsm.state.CASetRevocations(17, []*structs.CARevocation{revocation}))
+
+	// Federated trust bundles
+	fedBundle := &structs.FederatedTrustBundle{
+		TrustDomain: "example.org",
+		RootPEMs:    []string{"pem"},
+		Sequence:    12,
+		RefreshHint: 5 * time.Minute,
+	}
+	require.NoError(t, fsm.state.CASetFederatedTrustBundles(17, []*structs.FederatedTrustBundle{fedBundle}))
+
+	// JWT-SVID signing keys
+	jwtKey := &structs.CAJWTSigningKey{
+		ID:         "key1",
+		PublicKey:  "pub",
+		PrivateKey: "priv",
+		Active:     true,
+		CreatedAt:  time.Now().UTC().Round(time.Second),
+	}
+	require.NoError(t, fsm.state.CASetJWTSigningKeys(17, []*structs.CAJWTSigningKey{jwtKey}))
+
+	// Certificate revocation lists
+	revList := &structs.CARevocationList{
+		SigningKeyID: "aa:bb",
+		Datacenter:   "dc1",
+		PEM:          "crl",
+		NotAfter:     time.Now().UTC().Add(time.Hour).Round(time.Second),
+	}
+	require.NoError(t, fsm.state.CASetRevocationLists(17, []*structs.CARevocationList{revList}))
+
 	// Config entries
 	serviceConfig := &structs.ServiceConfigEntry{
 		Kind:     structs.ServiceDefaults,
@@ -746,6 +788,12 @@ func TestFSM_SnapshotRestore_OSS(t *testing.T) {
 	rtoken.CreateTime = rtoken.CreateTime.Round(0)
 	require.Equal(t, token2, rtoken)
 
+	// Verify the ACL token usage is restored
+	lastUsed, err := fsm2.state.ACLTokenUsageGet(nil, token.AccessorID)
+	require.NoError(t, err)
+	require.NotNil(t, lastUsed)
+	require.True(t, tokenLastUsed.Equal(*lastUsed))
+
 	// Verify the acl-token-bootstrap index was restored
 	canBootstrap, index, err := fsm2.state.CanBootstrapACLToken()
 	require.NoError(t, err)
@@ -812,6 +860,26 @@ func TestFSM_SnapshotRestore_OSS(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, caConfig, caConf)
 
+	// Verify CA revocations are restored.
+	_, revocations, err := fsm2.state.CARevocations(nil)
+	require.NoError(t, err)
+	require.Equal(t, []*structs.CARevocation{revocation}, revocations)
+
+	// Verify federated trust bundles are restored.
+	_, fedBundles, err := fsm2.state.CAFederatedTrustBundles(nil)
+	require.NoError(t, err)
+	require.Equal(t, []*structs.FederatedTrustBundle{fedBundle}, fedBundles)
+
+	// Verify JWT-SVID signing keys are restored.
+	_, jwtKeys, err := fsm2.state.CAJWTSigningKeys(nil)
+	require.NoError(t, err)
+	require.Equal(t, []*structs.CAJWTSigningKey{jwtKey}, jwtKeys)
+
+	// Verify certificate revocation lists are restored.
+	_, revLists, err := fsm2.state.CARevocationLists(nil)
+	require.NoError(t, err)
+	require.Equal(t, []*structs.CARevocationList{revList}, revLists)
+
 	// Verify config entries are restored
 	_, serviceConfEntry, err := fsm2.state.ConfigEntry(nil, structs.ServiceDefaults, "foo", structs.DefaultEnterpriseMetaInDefaultPartition())
 	require.NoError(t, err)
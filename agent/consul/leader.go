@@ -24,6 +24,7 @@ import (
 	"golang.org/x/time/rate"
 
 	"github.com/hernad/consul/acl"
+	"github.com/hernad/consul/agent/connect"
 	"github.com/hernad/consul/agent/metadata"
 	"github.com/hernad/consul/agent/structs"
 	"github.com/hernad/consul/agent/structs/aclfilter"
@@ -60,6 +61,36 @@ var (
 	// caRootPruneInterval is how often we check for stale CARoots to remove.
 	caRootPruneInterval = time.Hour
 
+	// federatedTrustBundleRefreshInterval is how often we check whether the
+	// trust bundles of federated trust domains are due for a refresh.
+	federatedTrustBundleRefreshInterval = time.Minute
+
+	// defaultFederatedTrustBundleRefreshHint is how often a trust bundle is
+	// fetched when its bundle endpoint does not give a refresh hint.
+	defaultFederatedTrustBundleRefreshHint = 5 * time.Minute
+
+	// caRevocationListInterval is how often we check whether the CA
+	// revocation lists or the revocations have expired, in addition to
+	// updating them when the revocations change.
+	caRevocationListInterval = time.Minute
+
+	// jwtSigningKeyRotationInterval is how often we check whether the JWT
+	// signing keys are due for a rotation.
+	jwtSigningKeyRotationInterval = time.Minute
+
+	// jwtSigningKeyRotationPeriod is how long a JWT signing key is active
+	// before it is rotated.
+	jwtSigningKeyRotationPeriod = 7 * 24 * time.Hour
+
+	// jwtSigningKeyPublishDelay is how long the next JWT signing key is
+	// published before it becomes active, so that it reaches the verifiers
+	// first.
+	jwtSigningKeyPublishDelay = 10 * time.Minute
+
+	// jwtSigningKeyRetention is how long a JWT signing key is kept after it
+	// was rotated out, long enough for the tokens it signed to expire.
+	jwtSigningKeyRetention = 2 * connect.MaxJWTSVIDTTL
+
 	// minCentralizedConfigVersion is the minimum Consul version in which centralized
 	// config is supported
 	minCentralizedConfigVersion = version.Must(version.NewVersion("1.5.0"))
@@ -611,6 +642,7 @@ func (s *Server) startACLReplication(ctx context.Context) {
 	s.initReplicationStatus()
 	s.leaderRoutineManager.Start(ctx, aclPolicyReplicationRoutineName, s.runACLPolicyReplicator)
 	s.leaderRoutineManager.Start(ctx, aclRoleReplicationRoutineName, s.runACLRoleReplicator)
+	s.leaderRoutineManager.Start(ctx, aclPolicyTemplateReplicationRoutineName, s.runACLPolicyTemplateReplicator)
 
 	if s.config.ACLTokenReplication {
 		s.leaderRoutineManager.Start(ctx, aclTokenReplicationRoutineName, s.runACLTokenReplicator)
@@ -638,6 +670,14 @@ func (s *Server) runACLRoleReplicator(ctx context.Context) error {
 	return s.runACLReplicator(ctx, roleLogger, structs.ACLReplicateRoles, s.replicateACLRoles, "acl-roles")
 }
 
+// This function is only intended to be run as a managed go routine, it will block until
+// the context passed in indicates that it should exit.
+func (s *Server) runACLPolicyTemplateReplicator(ctx context.Context) error {
+	templateLogger := s.aclReplicationLogger(structs.ACLReplicatePolicyTemplates.SingularNoun())
+	templateLogger.Info("started ACL Policy Template replication")
+	return s.runACLReplicator(ctx, templateLogger, structs.ACLReplicatePolicyTemplates, s.replicateACLPolicyTemplates, "acl-policy-templates")
+}
+
 // This function is only intended to be run as a managed go routine, it will block until
 // the context passed in indicates that it should exit.
 func (s *Server) runACLTokenReplicator(ctx context.Context) error {
@@ -720,6 +760,7 @@ func (s *Server) stopACLReplication() {
 	// these will be no-ops when not started
 	s.leaderRoutineManager.Stop(aclPolicyReplicationRoutineName)
 	s.leaderRoutineManager.Stop(aclRoleReplicationRoutineName)
+	s.leaderRoutineManager.Stop(aclPolicyTemplateReplicationRoutineName)
 	s.leaderRoutineManager.Stop(aclTokenReplicationRoutineName)
 }
 
@@ -53,6 +53,9 @@ func (s *Server) startConnectLeader(ctx context.Context) error {
 
 	s.caManager.Start(ctx)
 	s.leaderRoutineManager.Start(ctx, caRootPruningRoutineName, s.runCARootPruning)
+	s.leaderRoutineManager.Start(ctx, caRevocationListRoutineName, s.runCARevocationLists)
+	s.leaderRoutineManager.Start(ctx, federatedTrustBundleRoutineName, s.runFederatedTrustBundleRefresh)
+	s.leaderRoutineManager.Start(ctx, jwtSigningKeyRoutineName, s.runJWTSigningKeyRotation)
 	s.leaderRoutineManager.Start(ctx, caRootMetricRoutineName, rootCAExpiryMonitor(s).Monitor)
 	s.leaderRoutineManager.Start(ctx, caSigningMetricRoutineName, signingCAExpiryMonitor(s).Monitor)
 	s.leaderRoutineManager.Start(ctx, virtualIPCheckRoutineName, s.runVirtualIPVersionCheck)
@@ -66,6 +69,9 @@ func (s *Server) stopConnectLeader() {
 	s.caManager.Stop()
 	s.leaderRoutineManager.Stop(intentionMigrationRoutineName)
 	s.leaderRoutineManager.Stop(caRootPruningRoutineName)
+	s.leaderRoutineManager.Stop(caRevocationListRoutineName)
+	s.leaderRoutineManager.Stop(federatedTrustBundleRoutineName)
+	s.leaderRoutineManager.Stop(jwtSigningKeyRoutineName)
 	s.leaderRoutineManager.Stop(caRootMetricRoutineName)
 	s.leaderRoutineManager.Stop(caSigningMetricRoutineName)
 	s.leaderRoutineManager.Stop(virtualIPCheckRoutineName)
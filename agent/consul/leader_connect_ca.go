@@ -455,14 +455,22 @@ func (c *CAManager) secondaryInitialize(provider ca.Provider, conf *structs.CACo
 
 // createProvider returns a connect CA provider from the given config.
 func (c *CAManager) newProvider(conf *structs.CAConfiguration) (ca.Provider, error) {
+	return c.newProviderWithDelegate(conf, c.delegate)
+}
+
+// newProviderWithDelegate returns a connect CA provider from the given config
+// that keeps its state using the given delegate if it stores it in Consul.
+func (c *CAManager) newProviderWithDelegate(conf *structs.CAConfiguration, delegate ca.ConsulProviderStateDelegate) (ca.Provider, error) {
 	logger := c.logger.Named(conf.Provider)
 	switch conf.Provider {
 	case structs.ConsulCAProvider:
-		return ca.NewConsulProvider(c.delegate, logger), nil
+		return ca.NewConsulProvider(delegate, logger), nil
 	case structs.VaultCAProvider:
 		return ca.NewVaultProvider(logger), nil
 	case structs.AWSCAProvider:
 		return ca.NewAWSProvider(logger), nil
+	case structs.ExternalCAProvider:
+		return ca.NewExternalProvider(delegate, logger), nil
 	default:
 		if c.providerShim != nil {
 			return c.providerShim, nil
@@ -793,6 +801,10 @@ func (c *CAManager) UpdateConfiguration(args *structs.CARequest) (reterr error)
 		return nil
 	}
 
+	if err := c.validateFederatedTrustDomains(args.Config); err != nil {
+		return err
+	}
+
 	// If the provider hasn't changed, we need to load the current Provider state
 	// so it can decide if it needs to change resources or not based on the config
 	// change.
@@ -854,6 +866,50 @@ func (c *CAManager) UpdateConfiguration(args *structs.CARequest) (reterr error)
 	return nil
 }
 
+// validateFederatedTrustDomains checks that none of the federated trust domains
+// of the given configuration is the trust domain of this cluster or of a peer.
+// SPIFFE IDs are matched against the federated trust domains first, so such a
+// domain would let the workloads of the cluster or of the peer pass as
+// federated workloads, and its bundle would replace their roots in the proxies.
+func (c *CAManager) validateFederatedTrustDomains(config *structs.CAConfiguration) error {
+	common, err := config.GetCommonConfig()
+	if err != nil {
+		return err
+	}
+	if len(common.FederatedTrustDomains) == 0 {
+		return nil
+	}
+
+	reserved := make(map[string]string)
+	if config.ClusterID != "" {
+		reserved[connect.SpiffeIDSigningForCluster(config.ClusterID).Host()] = "the trust domain of this cluster"
+	}
+	state := c.delegate.State()
+	_, roots, err := state.CARoots(nil)
+	if err != nil {
+		return err
+	}
+	for _, r := range roots {
+		if r.ExternalTrustDomain != "" {
+			reserved[strings.ToLower(r.ExternalTrustDomain)] = "the trust domain of this cluster"
+		}
+	}
+	_, bundles, err := state.PeeringTrustBundleList(nil, *structs.WildcardEnterpriseMetaInPartition(acl.WildcardPartitionName))
+	if err != nil {
+		return err
+	}
+	for _, b := range bundles {
+		reserved[strings.ToLower(b.TrustDomain)] = fmt.Sprintf("the trust domain of peer %q", b.PeerName)
+	}
+
+	for _, d := range common.FederatedTrustDomains {
+		if owner, ok := reserved[strings.ToLower(d.Name)]; ok {
+			return fmt.Errorf("invalid FederatedTrustDomains: trust domain %q is %s", d.Name, owner)
+		}
+	}
+	return nil
+}
+
 // ValidateConfigUpdater is an optional interface that may be implemented
 // by a ca.Provider. If the provider implements this interface, the
 // ValidateConfigurationUpdate will be called when a user attempts to change the
@@ -867,6 +923,12 @@ type ValidateConfigUpdater interface {
 	ValidateConfigUpdate(previous, next map[string]interface{}) error
 }
 
+// errCrossSigningNotSupported is returned when a change would rotate the root
+// but the current provider can't cross-sign the new one.
+var errCrossSigningNotSupported = errors.New("The current CA Provider does not support cross-signing. " +
+	"You can try again with ForceWithoutCrossSigningSet but this may cause " +
+	"disruption - see documentation for more.")
+
 func (c *CAManager) primaryUpdateRootCA(newProvider ca.Provider, args *structs.CARequest, config *structs.CAConfiguration) error {
 	// See if the provider needs to persist any state along with the config
 	pState, err := newProvider.State()
@@ -971,9 +1033,7 @@ func (c *CAManager) primaryUpdateRootCA(newProvider ca.Provider, args *structs.C
 			return fmt.Errorf("CA provider error: %s", err)
 		}
 		if !canXSign && !args.Config.ForceWithoutCrossSigning {
-			return errors.New("The current CA Provider does not support cross-signing. " +
-				"You can try again with ForceWithoutCrossSigningSet but this may cause " +
-				"disruption - see documentation for more.")
+			return errCrossSigningNotSupported
 		}
 		if args.Config.ForceWithoutCrossSigning {
 			c.logger.Warn("ForceWithoutCrossSigning set, CA reconfiguration skipping cross-signing")
@@ -1273,6 +1333,18 @@ func (c *CAManager) secondaryCARootWatch(ctx context.Context) error {
 		default:
 		}
 
+		// Revocations apply to every datacenter, so copy them before anything
+		// that could fail while the intermediate is being renewed.
+		if err := c.secondaryUpdateRevocations(roots.Revocations); err != nil {
+			return err
+		}
+		if err := c.secondaryUpdateJWTSigningKeys(roots.JWTSigningKeys); err != nil {
+			return err
+		}
+		if err := c.secondaryUpdateRevocationLists(roots.RevocationLists); err != nil {
+			return err
+		}
+
 		// Attempt to update the roots using the returned data.
 		if err := c.secondaryUpdateRoots(roots); err != nil {
 			return err
@@ -1523,6 +1595,14 @@ func (c *CAManager) SignCertificate(csr *x509.CertificateRequest, spiffeID conne
 		}
 		entMeta.Merge(serviceID.GetEnterpriseMeta())
 
+		rev, err := caServiceRevocation(state, serviceID.Service, &entMeta)
+		if err != nil {
+			return nil, err
+		}
+		if rev != nil {
+			return nil, connect.InvalidCSRError("service identity %q has been revoked", serviceID.Service)
+		}
+
 	case isMeshGateway:
 		if !signingID.CanSign(spiffeID) {
 			return nil, connect.InvalidCSRError("SPIFFE ID in CSR from a different trust domain: %s, "+
@@ -1692,6 +1772,8 @@ func providerPrettyName(provider string) string {
 		return "Vault"
 	case "aws-pca":
 		return "Aws-Pca"
+	case "external":
+		return "External"
 	case "provider-name":
 		return "Provider-Name"
 	default:
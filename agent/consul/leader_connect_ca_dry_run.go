@@ -0,0 +1,474 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package consul
+
+import (
+	"bytes"
+	"crypto/x509"
+	"fmt"
+	"reflect"
+
+	"github.com/hernad/consul/agent/connect"
+	"github.com/hernad/consul/agent/connect/ca"
+	"github.com/hernad/consul/agent/consul/fsm"
+	"github.com/hernad/consul/agent/consul/state"
+	"github.com/hernad/consul/agent/structs"
+	"github.com/hernad/consul/proto/private/pbpeering"
+)
+
+// dryRunGatewayKinds are the kinds of gateways that are reported as affected
+// by a CA change.
+var dryRunGatewayKinds = []structs.ServiceKind{
+	structs.ServiceKindMeshGateway,
+	structs.ServiceKindTerminatingGateway,
+	structs.ServiceKindIngressGateway,
+	structs.ServiceKindAPIGateway,
+}
+
+// DryRunConfiguration validates the given CA configuration the same way
+// UpdateConfiguration does and reports what applying it would change, without
+// persisting anything.
+//
+// The new provider keeps the state it writes in a scratch store, and its root
+// is only generated when the provider keeps its state in Consul so that the
+// dry run does not create resources in an external system. The keys the Consul
+// provider generates in a PKCS#11 token are session objects, destroyed when
+// the provider is cleaned up at the end of the dry run. The new root is
+// cross-signed by a copy of the current provider using a scratch store too,
+// and only when that provider keeps its keys in Consul. datacenters is the
+// list of known datacenters whose CA configuration is compared with the new
+// root.
+func (c *CAManager) DryRunConfiguration(args *structs.CARequest, datacenters []string) (*structs.CADryRunReport, error) {
+	report, err := c.dryRunLocal(args)
+	if err != nil {
+		return nil, err
+	}
+
+	// The other datacenters are queried once the reconfiguration state is
+	// released, so a slow datacenter does not hold back CA updates.
+	if len(report.Datacenters) > 0 && report.Datacenters[0].Primary {
+		c.dryRunDatacenters(report, datacenters, args.Token)
+	}
+	return report, nil
+}
+
+// dryRunLocal builds the report of DryRunConfiguration for this datacenter.
+func (c *CAManager) dryRunLocal(args *structs.CARequest) (*structs.CADryRunReport, error) {
+	// Hold the reconfiguration state so the configuration can't change while
+	// the dry run is running.
+	oldState, err := c.setState(caStateReconfig, true)
+	if err != nil {
+		return nil, err
+	}
+	defer c.setState(oldState, false)
+
+	state := c.delegate.State()
+	_, config, err := state.CAConfig(nil)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return nil, fmt.Errorf("CA configuration has not been initialized")
+	}
+
+	if len(args.Config.State) > 0 &&
+		!reflect.DeepEqual(args.Config.State, config.State) {
+		return nil, ErrStateReadOnly
+	}
+
+	// Work on a copy so the request is left untouched.
+	newConfig := *args.Config
+	newConfig.ClusterID = config.ClusterID
+
+	report := &structs.CADryRunReport{
+		Provider:     newConfig.Provider,
+		CrossSigning: structs.CADryRunCrossSigningNotRequired,
+	}
+	_, activeRoot, err := state.CARootActive(nil)
+	if err != nil {
+		return nil, err
+	}
+	if activeRoot != nil {
+		report.CurrentRoot = newCADryRunRoot(activeRoot)
+	}
+
+	if newConfig.Provider == config.Provider && reflect.DeepEqual(newConfig.Config, config.Config) {
+		report.NoOp = true
+		return report, nil
+	}
+	if newConfig.Provider == config.Provider {
+		newConfig.State = config.State
+	}
+	if err := c.validateFederatedTrustDomains(&newConfig); err != nil {
+		return nil, err
+	}
+
+	newProvider, err := c.newProviderWithDelegate(&newConfig, newDryRunProviderDelegate(c.delegate))
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize provider: %v", err)
+	}
+	isPrimary := c.serverConf.Datacenter == c.serverConf.PrimaryDatacenter
+	pCfg := ca.ProviderConfig{
+		ClusterID:  newConfig.ClusterID,
+		Datacenter: c.serverConf.Datacenter,
+		IsPrimary:  isPrimary,
+		RawConfig:  newConfig.Config,
+		State:      newConfig.State,
+		DryRun:     true,
+	}
+
+	if newConfig.Provider == config.Provider {
+		if validator, ok := newProvider.(ValidateConfigUpdater); ok {
+			if err := validator.ValidateConfigUpdate(config.Config, newConfig.Config); err != nil {
+				return nil, fmt.Errorf("new configuration is incompatible with previous configuration: %w", err)
+			}
+		}
+	}
+
+	if err := newProvider.Configure(pCfg); err != nil {
+		return nil, fmt.Errorf("error configuring provider: %v", err)
+	}
+	defer func() {
+		// Passing the provider its own config stops it without releasing any
+		// resources it may share with the current provider.
+		if err := newProvider.Cleanup(false, newConfig.Config); err != nil {
+			c.logger.Warn("failed to clean up CA provider after a dry run", "provider", newConfig.Provider, "error", err)
+		}
+	}()
+
+	if isPrimary {
+		err = c.dryRunPrimary(report, newProvider, config, &newConfig, activeRoot)
+	} else {
+		err = c.dryRunSecondary(report, newProvider, &newConfig)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if report.RootRotation || report.IntermediateRotation {
+		if err := dryRunImpact(report, state); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// dryRunPrimary checks whether the new provider would rotate the root of the
+// primary datacenter and, if so, whether the current provider can cross-sign
+// the new root.
+func (c *CAManager) dryRunPrimary(report *structs.CADryRunReport, newProvider ca.Provider, config, newConfig *structs.CAConfiguration,
+	activeRoot *structs.CARoot) error {
+
+	keyType, keyBits := caConfigKeyInfo(newConfig)
+
+	var newRoot *x509.Certificate
+	if caProviderStateInConsul(newConfig.Provider) {
+		caPEM, err := newProvider.GenerateCAChain()
+		if err != nil {
+			return fmt.Errorf("error generating CA root certificate: %v", err)
+		}
+		root, err := newCARoot(caPEM, newConfig.Provider, newConfig.ClusterID)
+		if err != nil {
+			return err
+		}
+		newRoot, err = connect.ParseCert(caPEM)
+		if err != nil {
+			return err
+		}
+
+		report.NewRoot = newCADryRunRoot(root)
+		report.RootRotation = activeRoot == nil || activeRoot.ID != root.ID
+		keyType, keyBits = root.PrivateKeyType, root.PrivateKeyBits
+	} else {
+		// Generating the root of the other providers could create resources
+		// outside of Consul.
+		report.RootRotation = true
+		report.Warnings = append(report.Warnings, fmt.Sprintf(
+			"The %s provider keeps its root outside of Consul so it was not generated; the root is assumed to change.",
+			newConfig.Provider))
+	}
+
+	if !report.RootRotation || activeRoot == nil {
+		return nil
+	}
+
+	oldProvider, _ := c.getCAProvider()
+	if oldProvider == nil {
+		return fmt.Errorf("internal error: CA provider is nil")
+	}
+	canXSign, err := oldProvider.SupportsCrossSigning()
+	if err != nil {
+		return fmt.Errorf("CA provider error: %s", err)
+	}
+
+	switch {
+	case newConfig.ForceWithoutCrossSigning:
+		report.CrossSigning = structs.CADryRunCrossSigningSkipped
+		report.Warnings = append(report.Warnings,
+			"Cross-signing would be skipped because ForceWithoutCrossSigning is set; proxies and gateways "+
+				"will reject new leaf certificates until they have observed the new root.")
+	case !canXSign:
+		return errCrossSigningNotSupported
+	case newRoot == nil || !caProviderStateInConsul(config.Provider):
+		// Cross-signing with a provider keeping its keys outside of Consul
+		// would use them, so only its support is reported.
+		report.CrossSigning = structs.CADryRunCrossSigningSupported
+	default:
+		xcPEM, err := c.dryRunCrossSign(config, newRoot)
+		if err != nil {
+			return fmt.Errorf("error cross-signing the new root: %w", err)
+		}
+		report.CrossSigning = structs.CADryRunCrossSigningSucceeded
+		if err := verifyCrossSignedRoot(xcPEM, newRoot, activeRoot); err != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf(
+				"The cross-signed root can't be verified using the current root: %v.", err))
+		}
+	}
+
+	report.Datacenters = append(report.Datacenters, structs.CADryRunDatacenter{
+		Datacenter:     c.serverConf.Datacenter,
+		Primary:        true,
+		Provider:       newConfig.Provider,
+		PrivateKeyType: keyType,
+		PrivateKeyBits: keyBits,
+	})
+	return nil
+}
+
+// dryRunCrossSign cross-signs newRoot with a copy of the current provider,
+// which keeps the state it writes, such as the serial numbers, in a scratch
+// store. config is the configuration of the current provider.
+func (c *CAManager) dryRunCrossSign(config *structs.CAConfiguration, newRoot *x509.Certificate) (string, error) {
+	provider, err := c.newProviderWithDelegate(config, newDryRunProviderDelegate(c.delegate))
+	if err != nil {
+		return "", fmt.Errorf("could not initialize provider: %v", err)
+	}
+	pCfg := ca.ProviderConfig{
+		ClusterID:  config.ClusterID,
+		Datacenter: c.serverConf.Datacenter,
+		IsPrimary:  true,
+		RawConfig:  config.Config,
+		State:      config.State,
+		DryRun:     true,
+	}
+	if err := provider.Configure(pCfg); err != nil {
+		return "", fmt.Errorf("error configuring provider: %v", err)
+	}
+	defer func() {
+		if err := provider.Cleanup(false, config.Config); err != nil {
+			c.logger.Warn("failed to clean up CA provider after a dry run", "provider", config.Provider, "error", err)
+		}
+	}()
+	return provider.CrossSignCA(newRoot)
+}
+
+// dryRunDatacenters compares the configuration of the other datacenters with
+// the new root of the primary datacenter, the first of report.Datacenters.
+func (c *CAManager) dryRunDatacenters(report *structs.CADryRunReport, datacenters []string, token string) {
+	keyType, keyBits := report.Datacenters[0].PrivateKeyType, report.Datacenters[0].PrivateKeyBits
+	for _, dc := range datacenters {
+		if dc == c.serverConf.Datacenter {
+			continue
+		}
+
+		entry := structs.CADryRunDatacenter{Datacenter: dc}
+		args := structs.DCSpecificRequest{
+			Datacenter:   dc,
+			QueryOptions: structs.QueryOptions{Token: token},
+		}
+		var conf structs.CAConfiguration
+		if err := c.delegate.forwardDC("ConnectCA.ConfigurationGet", dc, &args, &conf); err != nil {
+			entry.Error = err.Error()
+			report.Warnings = append(report.Warnings, fmt.Sprintf(
+				"The CA configuration of datacenter %q could not be read: %v.", dc, err))
+		} else {
+			entry.Provider = conf.Provider
+			entry.PrivateKeyType, entry.PrivateKeyBits = caConfigKeyInfo(&conf)
+			if entry.PrivateKeyType != keyType || entry.PrivateKeyBits != keyBits {
+				report.Warnings = append(report.Warnings, fmt.Sprintf(
+					"Datacenter %q uses %s-%d keys but the new root uses %s-%d keys.",
+					dc, entry.PrivateKeyType, entry.PrivateKeyBits, keyType, keyBits))
+			}
+		}
+		report.Datacenters = append(report.Datacenters, entry)
+	}
+}
+
+// dryRunSecondary checks whether the new provider of a secondary datacenter
+// would need a new intermediate certificate from the primary datacenter.
+func (c *CAManager) dryRunSecondary(report *structs.CADryRunReport, newProvider ca.Provider, newConfig *structs.CAConfiguration) error {
+	intermediate, err := newProvider.ActiveLeafSigningCert()
+	if err != nil {
+		return err
+	}
+	report.IntermediateRotation = intermediate == ""
+
+	primaryRoot, err := c.secondaryGetActivePrimaryCARoot()
+	if err != nil {
+		return err
+	}
+	keyType, keyBits := caConfigKeyInfo(newConfig)
+	if keyType != primaryRoot.PrivateKeyType || keyBits != primaryRoot.PrivateKeyBits {
+		report.Warnings = append(report.Warnings, fmt.Sprintf(
+			"The new configuration uses %s-%d keys but the root of the primary datacenter uses %s-%d keys.",
+			keyType, keyBits, primaryRoot.PrivateKeyType, primaryRoot.PrivateKeyBits))
+	}
+	return nil
+}
+
+// dryRunImpact adds the peers, gateways and proxies of this datacenter that
+// would have to pick up new certificates to the report.
+func dryRunImpact(report *structs.CADryRunReport, store *state.Store) error {
+	entMeta := structs.WildcardEnterpriseMetaInPartition(structs.WildcardSpecifier)
+
+	// Peers only trust the root so they are not affected by a new
+	// intermediate.
+	if report.RootRotation {
+		_, peerings, err := store.PeeringList(nil, *entMeta)
+		if err != nil {
+			return fmt.Errorf("failed to list peerings: %w", err)
+		}
+		for _, p := range peerings {
+			if !p.IsActive() {
+				continue
+			}
+			report.Peers = append(report.Peers, structs.CADryRunPeer{
+				Name:      p.Name,
+				Partition: p.Partition,
+				State:     p.State.String(),
+			})
+			if p.State != pbpeering.PeeringState_ACTIVE {
+				report.Warnings = append(report.Warnings, fmt.Sprintf(
+					"Peer %q is in state %s and would only receive the new root once it is connected.",
+					p.Name, p.State.String()))
+			}
+		}
+	}
+
+	for _, kind := range dryRunGatewayKinds {
+		_, nodes, err := store.ServiceDump(nil, kind, true, entMeta, structs.DefaultPeerKeyword)
+		if err != nil {
+			return fmt.Errorf("failed to list %s services: %w", kind, err)
+		}
+
+		seen := make(map[structs.ServiceName]int)
+		for _, node := range nodes {
+			name := node.Service.CompoundServiceName()
+			if i, ok := seen[name]; ok {
+				report.Gateways[i].Instances++
+				continue
+			}
+			seen[name] = len(report.Gateways)
+			report.Gateways = append(report.Gateways, structs.CADryRunGateway{
+				Kind:           kind,
+				Name:           name.Name,
+				Instances:      1,
+				EnterpriseMeta: name.EnterpriseMeta,
+			})
+		}
+	}
+
+	_, proxies, err := store.ServiceDump(nil, structs.ServiceKindConnectProxy, true, entMeta, structs.DefaultPeerKeyword)
+	if err != nil {
+		return fmt.Errorf("failed to list proxies: %w", err)
+	}
+	report.Proxies = len(proxies)
+	return nil
+}
+
+// verifyCrossSignedRoot checks that the cross-signed certificate is for the
+// key of the new root and chains to the current root.
+func verifyCrossSignedRoot(xcPEM string, newRoot *x509.Certificate, activeRoot *structs.CARoot) error {
+	xc, err := connect.ParseCert(xcPEM)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(xc.RawSubjectPublicKeyInfo, newRoot.RawSubjectPublicKeyInfo) {
+		return fmt.Errorf("the certificate is not for the key of the new root")
+	}
+
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM([]byte(activeRoot.RootCert)) {
+		return fmt.Errorf("failed to parse the current root")
+	}
+	intermediates := x509.NewCertPool()
+	for _, pem := range activeRoot.IntermediateCerts {
+		intermediates.AppendCertsFromPEM([]byte(pem))
+	}
+	_, err = xc.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	return err
+}
+
+// caProviderStateInConsul returns whether the given provider keeps all of its
+// state in Consul, which makes it safe to exercise during a dry run.
+func caProviderStateInConsul(provider string) bool {
+	return provider == structs.ConsulCAProvider || provider == structs.ExternalCAProvider
+}
+
+// caConfigKeyInfo returns the type and size of the keys the provider of the
+// given configuration uses.
+func caConfigKeyInfo(conf *structs.CAConfiguration) (string, int) {
+	keyType, keyBits := connect.DefaultPrivateKeyType, connect.DefaultPrivateKeyBits
+	common, err := conf.GetCommonConfig()
+	if err == nil && common.PrivateKeyType != "" {
+		keyType, keyBits = common.PrivateKeyType, common.PrivateKeyBits
+	}
+	return keyType, keyBits
+}
+
+func newCADryRunRoot(root *structs.CARoot) *structs.CADryRunRoot {
+	return &structs.CADryRunRoot{
+		ID:             root.ID,
+		PrivateKeyType: root.PrivateKeyType,
+		PrivateKeyBits: root.PrivateKeyBits,
+		NotAfter:       root.NotAfter,
+	}
+}
+
+// dryRunProviderDelegate keeps the state a CA provider writes during a dry run
+// in a scratch state store so the real one is left untouched. Reads fall
+// through to the real state until the provider writes its own.
+type dryRunProviderDelegate struct {
+	delegate ca.ConsulProviderStateDelegate
+	store    *state.Store
+	index    uint64
+	written  map[string]bool
+}
+
+func newDryRunProviderDelegate(delegate ca.ConsulProviderStateDelegate) *dryRunProviderDelegate {
+	return &dryRunProviderDelegate{
+		delegate: delegate,
+		store:    state.NewStateStore(nil),
+		written:  make(map[string]bool),
+	}
+}
+
+func (d *dryRunProviderDelegate) ProviderState(id string) (*structs.CAConsulProviderState, error) {
+	if !d.written[id] {
+		return d.delegate.ProviderState(id)
+	}
+	_, s, err := d.store.CAProviderState(id)
+	return s, err
+}
+
+func (d *dryRunProviderDelegate) ApplyCARequest(req *structs.CARequest) (interface{}, error) {
+	switch req.Op {
+	case structs.CAOpSetProviderState, structs.CAOpDeleteProviderState:
+		d.written[req.ProviderState.ID] = true
+	case structs.CAOpIncrementProviderSerialNumber:
+	default:
+		return nil, fmt.Errorf("CA operation %q is not allowed during a dry run", req.Op)
+	}
+
+	d.index++
+	result := fsm.ApplyConnectCAOperationFromRequest(d.store, req, d.index)
+	if err, ok := result.(error); ok && err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,208 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package consul
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/hernad/consul/agent/connect"
+	"github.com/hernad/consul/agent/structs"
+	"github.com/hernad/consul/logging"
+)
+
+const (
+	// federatedTrustBundleFetchTimeout is the timeout of a request to a
+	// SPIFFE bundle endpoint.
+	federatedTrustBundleFetchTimeout = 10 * time.Second
+
+	// federatedTrustBundleMaxSize is the maximum size of a bundle fetched from
+	// a SPIFFE bundle endpoint.
+	federatedTrustBundleMaxSize = 1 << 20
+)
+
+// runFederatedTrustBundleRefresh periodically fetches the trust bundles of the
+// federated trust domains that have a SPIFFE bundle endpoint and stores them
+// so they are distributed with the CA roots.
+func (s *Server) runFederatedTrustBundleRefresh(ctx context.Context) error {
+	logger := s.loggers.Named(logging.Connect)
+	fetched := make(map[string]time.Time)
+
+	ticker := time.NewTicker(federatedTrustBundleRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.refreshFederatedTrustBundles(ctx, fetched, time.Now()); err != nil {
+			logger.Error("error refreshing federated trust bundles", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// refreshFederatedTrustBundles fetches the bundles that are due according to
+// their refresh hint, stores the ones that changed and deletes the bundles of
+// the trust domains that no longer have a bundle endpoint. fetched records the
+// last time each trust domain was fetched.
+func (s *Server) refreshFederatedTrustBundles(ctx context.Context, fetched map[string]time.Time, now time.Time) error {
+	if !s.config.ConnectEnabled {
+		return nil
+	}
+
+	state := s.fsm.State()
+	_, caConf, err := state.CAConfig(nil)
+	if err != nil {
+		return err
+	}
+	if caConf == nil {
+		return nil
+	}
+	common, err := caConf.GetCommonConfig()
+	if err != nil {
+		return err
+	}
+	_, bundles, err := state.CAFederatedTrustBundles(nil)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]*structs.FederatedTrustBundle, len(bundles))
+	for _, b := range bundles {
+		existing[b.TrustDomain] = b
+	}
+
+	logger := s.loggers.Named(logging.Connect)
+	configured := make(map[string]bool)
+	var updated []*structs.FederatedTrustBundle
+	for _, d := range common.FederatedTrustDomains {
+		if d.BundleEndpointURL == "" {
+			continue
+		}
+		configured[d.Name] = true
+
+		refresh := defaultFederatedTrustBundleRefreshHint
+		if b := existing[d.Name]; b != nil && b.RefreshHint > 0 {
+			refresh = b.RefreshHint
+		}
+		if last, ok := fetched[d.Name]; ok && now.Sub(last) < refresh {
+			continue
+		}
+		fetched[d.Name] = now
+
+		bundle, err := fetchSpiffeBundle(ctx, d)
+		if err != nil {
+			// Keep the previous bundle, if any, so that a temporary failure of
+			// the endpoint does not break the connections from the trust
+			// domain.
+			logger.Warn("failed to fetch federated trust bundle",
+				"trust_domain", d.Name,
+				"url", d.BundleEndpointURL,
+				"error", err,
+			)
+			continue
+		}
+
+		if b := existing[d.Name]; b != nil && b.Sequence == bundle.Sequence &&
+			b.RefreshHint == bundle.RefreshHint && reflect.DeepEqual(b.RootPEMs, bundle.RootPEMs) {
+			continue
+		}
+		updated = append(updated, &structs.FederatedTrustBundle{
+			TrustDomain: d.Name,
+			RootPEMs:    bundle.RootPEMs,
+			Sequence:    bundle.Sequence,
+			RefreshHint: bundle.RefreshHint,
+		})
+	}
+
+	if len(updated) > 0 {
+		args := &structs.CARequest{
+			Op:                    structs.CAOpSetFederatedTrustBundles,
+			FederatedTrustBundles: updated,
+		}
+		if _, err := s.raftApply(structs.ConnectCARequestType, args); err != nil {
+			return fmt.Errorf("failed to store federated trust bundles: %w", err)
+		}
+		for _, b := range updated {
+			logger.Info("updated federated trust bundle",
+				"trust_domain", b.TrustDomain,
+				"sequence", b.Sequence,
+				"roots", len(b.RootPEMs),
+			)
+		}
+	}
+
+	var removed []*structs.FederatedTrustBundle
+	for name := range existing {
+		if !configured[name] {
+			removed = append(removed, &structs.FederatedTrustBundle{TrustDomain: name})
+		}
+	}
+	for name := range fetched {
+		if !configured[name] {
+			delete(fetched, name)
+		}
+	}
+	if len(removed) > 0 {
+		args := &structs.CARequest{
+			Op:                    structs.CAOpDeleteFederatedTrustBundles,
+			FederatedTrustBundles: removed,
+		}
+		if _, err := s.raftApply(structs.ConnectCARequestType, args); err != nil {
+			return fmt.Errorf("failed to delete federated trust bundles: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// fetchSpiffeBundle fetches the trust bundle of the given trust domain from
+// its SPIFFE bundle endpoint.
+func fetchSpiffeBundle(ctx context.Context, d structs.FederatedTrustDomain) (*connect.SpiffeBundle, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if d.BundleEndpointCACert != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(d.BundleEndpointCACert)) {
+			return nil, fmt.Errorf("invalid bundle endpoint CA certificate")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	client := &http.Client{
+		Timeout: federatedTrustBundleFetchTimeout,
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		},
+	}
+	defer client.CloseIdleConnections()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.BundleEndpointURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response code %d", resp.StatusCode)
+	}
+
+	var bundle connect.SpiffeBundle
+	if err := json.NewDecoder(io.LimitReader(resp.Body, federatedTrustBundleMaxSize)).Decode(&bundle); err != nil {
+		return nil, fmt.Errorf("invalid bundle: %w", err)
+	}
+	return &bundle, nil
+}
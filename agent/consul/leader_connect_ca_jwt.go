@@ -0,0 +1,264 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package consul
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hernad/consul/agent/connect"
+	"github.com/hernad/consul/agent/structs"
+	"github.com/hernad/consul/logging"
+)
+
+// runJWTSigningKeyRotation makes sure the primary datacenter always has an
+// active key to sign JWT-SVIDs and rotates it periodically.
+func (s *Server) runJWTSigningKeyRotation(ctx context.Context) error {
+	logger := s.loggers.Named(logging.Connect)
+
+	ticker := time.NewTicker(jwtSigningKeyRotationInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.rotateJWTSigningKeys(time.Now().UTC()); err != nil {
+			logger.Error("error rotating JWT signing keys", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// rotateJWTSigningKeys creates the first JWT signing key, or rotates the
+// active key once it is older than jwtSigningKeyRotationPeriod. The next key is
+// first published inactive so that the verifiers learn about it before it is
+// used, and the keys that were rotated out are deleted once the tokens they
+// signed have expired.
+func (s *Server) rotateJWTSigningKeys(now time.Time) error {
+	if !s.config.ConnectEnabled || s.config.PrimaryDatacenter != s.config.Datacenter {
+		return nil
+	}
+
+	_, keys, err := s.fsm.State().CAJWTSigningKeys(nil)
+	if err != nil {
+		return err
+	}
+
+	// IMPORTANT: the keys must NEVER be modified, since they are pointers
+	// directly to the structures in the memdb store.
+	var active, pending *structs.CAJWTSigningKey
+	var deleted []*structs.CAJWTSigningKey
+	for _, k := range keys {
+		switch {
+		case k.Active:
+			active = k
+		case k.RotatedOutAt.IsZero():
+			pending = k
+		case now.Sub(k.RotatedOutAt) > jwtSigningKeyRetention:
+			deleted = append(deleted, &structs.CAJWTSigningKey{ID: k.ID})
+		}
+	}
+
+	var set []*structs.CAJWTSigningKey
+	switch {
+	case active == nil && pending == nil:
+		key, err := connect.GenerateJWTSigningKey()
+		if err != nil {
+			return err
+		}
+		key.Active = true
+		key.CreatedAt = now
+		set = append(set, key)
+
+	case pending == nil && now.Sub(active.CreatedAt) >= jwtSigningKeyRotationPeriod:
+		key, err := connect.GenerateJWTSigningKey()
+		if err != nil {
+			return err
+		}
+		key.CreatedAt = now
+		set = append(set, key)
+
+	case pending != nil && (active == nil || now.Sub(pending.CreatedAt) >= jwtSigningKeyPublishDelay):
+		if active != nil {
+			old := *active
+			old.Active = false
+			old.RotatedOutAt = now
+			set = append(set, &old)
+		}
+		next := *pending
+		next.Active = true
+		set = append(set, &next)
+	}
+
+	logger := s.loggers.Named(logging.Connect)
+	if len(set) > 0 {
+		args := &structs.CARequest{
+			Op:             structs.CAOpSetJWTSigningKeys,
+			JWTSigningKeys: set,
+		}
+		if _, err := s.raftApply(structs.ConnectCARequestType, args); err != nil {
+			return fmt.Errorf("failed to store JWT signing keys: %w", err)
+		}
+		for _, k := range set {
+			logger.Info("updated JWT signing key", "id", k.ID, "active", k.Active)
+		}
+	}
+
+	if len(deleted) > 0 {
+		args := &structs.CARequest{
+			Op:             structs.CAOpDeleteJWTSigningKeys,
+			JWTSigningKeys: deleted,
+		}
+		if _, err := s.raftApply(structs.ConnectCARequestType, args); err != nil {
+			return fmt.Errorf("failed to delete JWT signing keys: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// SignJWTSVID issues a JWT-SVID to a service of the given datacenter. It must
+// only be called in the primary datacenter, the only one with the private
+// signing keys. The caller must have been authorized already.
+func (c *CAManager) SignJWTSVID(args *structs.CAJWTSVIDRequest, dc string) (*structs.JWTSVID, error) {
+	if args.Service == "" {
+		return nil, fmt.Errorf("missing service name")
+	}
+	if len(args.Audience) == 0 {
+		return nil, fmt.Errorf("at least one audience is required")
+	}
+	ttl := args.TTL
+	switch {
+	case ttl < 0:
+		return nil, fmt.Errorf("TTL must not be negative")
+	case ttl == 0:
+		ttl = connect.DefaultJWTSVIDTTL
+	case ttl > connect.MaxJWTSVIDTTL:
+		return nil, fmt.Errorf("TTL must be at most %s", connect.MaxJWTSVIDTTL)
+	}
+
+	state := c.delegate.State()
+	_, config, err := state.CAConfig(nil)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil || config.ClusterID == "" {
+		return nil, fmt.Errorf("CA has not finished initializing")
+	}
+
+	entMeta := args.EnterpriseMeta
+	entMeta.Normalize()
+	rev, err := caServiceRevocation(state, args.Service, &entMeta)
+	if err != nil {
+		return nil, err
+	}
+	if rev != nil {
+		return nil, fmt.Errorf("service identity %q has been revoked", args.Service)
+	}
+
+	_, keys, err := state.CAJWTSigningKeys(nil)
+	if err != nil {
+		return nil, err
+	}
+	var key *structs.CAJWTSigningKey
+	for _, k := range keys {
+		if k.Active {
+			key = k
+			break
+		}
+	}
+	if key == nil {
+		return nil, fmt.Errorf("no active JWT signing key")
+	}
+
+	id := &connect.SpiffeIDService{
+		Host:       connect.SpiffeIDSigningForCluster(config.ClusterID).Host(),
+		Datacenter: dc,
+		Partition:  entMeta.PartitionOrEmpty(),
+		Namespace:  entMeta.NamespaceOrDefault(),
+		Service:    args.Service,
+	}
+	now := time.Now()
+	token, err := connect.SignJWTSVID(key, id.Host, id, args.Audience, now, ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	return &structs.JWTSVID{
+		Token:     token,
+		SpiffeID:  id.URI().String(),
+		ExpiresAt: now.Add(ttl).Truncate(time.Second),
+	}, nil
+}
+
+// secondaryUpdateJWTSigningKeys makes the JWT signing keys stored in this
+// datacenter match the public keys of the primary datacenter.
+func (c *CAManager) secondaryUpdateJWTSigningKeys(primary []*structs.CAJWTSigningKey) error {
+	_, local, err := c.delegate.State().CAJWTSigningKeys(nil)
+	if err != nil {
+		return err
+	}
+
+	localByID := make(map[string]*structs.CAJWTSigningKey, len(local))
+	for _, k := range local {
+		localByID[k.ID] = k
+	}
+
+	var set []*structs.CAJWTSigningKey
+	for _, k := range primary {
+		existing, ok := localByID[k.ID]
+		delete(localByID, k.ID)
+		if ok && caJWTSigningKeyEqual(existing, k) {
+			continue
+		}
+		// The raft indexes are the ones of this datacenter.
+		set = append(set, &structs.CAJWTSigningKey{
+			ID:           k.ID,
+			PublicKey:    k.PublicKey,
+			Active:       k.Active,
+			CreatedAt:    k.CreatedAt,
+			RotatedOutAt: k.RotatedOutAt,
+		})
+	}
+
+	// Delete the keys first so that a key that is no longer active in the
+	// primary datacenter never conflicts with the new active key.
+	if len(localByID) > 0 {
+		deleted := make([]*structs.CAJWTSigningKey, 0, len(localByID))
+		for id := range localByID {
+			deleted = append(deleted, &structs.CAJWTSigningKey{ID: id})
+		}
+		req := &structs.CARequest{
+			Op:             structs.CAOpDeleteJWTSigningKeys,
+			JWTSigningKeys: deleted,
+		}
+		if _, err := c.delegate.ApplyCARequest(req); err != nil {
+			return fmt.Errorf("failed to replicate JWT signing keys: %w", err)
+		}
+	}
+
+	if len(set) > 0 {
+		req := &structs.CARequest{
+			Op:             structs.CAOpSetJWTSigningKeys,
+			JWTSigningKeys: set,
+		}
+		if _, err := c.delegate.ApplyCARequest(req); err != nil {
+			return fmt.Errorf("failed to replicate JWT signing keys: %w", err)
+		}
+	}
+	return nil
+}
+
+// caJWTSigningKeyEqual compares the public parts of two JWT signing keys,
+// ignoring their raft indexes.
+func caJWTSigningKeyEqual(a, b *structs.CAJWTSigningKey) bool {
+	return a.PublicKey == b.PublicKey &&
+		a.Active == b.Active &&
+		a.CreatedAt.Equal(b.CreatedAt) &&
+		a.RotatedOutAt.Equal(b.RotatedOutAt)
+}
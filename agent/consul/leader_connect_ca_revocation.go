@@ -0,0 +1,721 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package consul
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/armon/go-metrics/prometheus"
+	"github.com/hashicorp/go-memdb"
+
+	"github.com/hernad/consul/acl"
+	"github.com/hernad/consul/agent/connect"
+	"github.com/hernad/consul/agent/connect/ca"
+	"github.com/hernad/consul/agent/consul/state"
+	"github.com/hernad/consul/agent/structs"
+	"github.com/hernad/consul/lib"
+	"github.com/hernad/consul/logging"
+)
+
+// Revoke stores a new revocation of a leaf certificate or a service identity.
+// It must only be called in the primary datacenter; secondaries copy the
+// revocations of the primary along with its roots.
+func (c *CAManager) Revoke(rev *structs.CARevocation) (*structs.CARevocation, error) {
+	if err := rev.Validate(); err != nil {
+		return nil, err
+	}
+
+	rev = &structs.CARevocation{
+		SerialNumber:   rev.SerialNumber,
+		Service:        rev.Service,
+		Reason:         rev.Reason,
+		RevokedAt:      time.Now().UTC(),
+		EnterpriseMeta: rev.EnterpriseMeta,
+	}
+	state := c.delegate.State()
+	if rev.SerialNumber != "" {
+		rev.SerialNumber = normalizeSerialNumber(rev.SerialNumber)
+		rev.EnterpriseMeta = *structs.DefaultEnterpriseMetaInDefaultPartition()
+
+		// The revoked certificate was signed before now, so it expires at the
+		// latest one leaf certificate TTL from now.
+		_, config, err := state.CAConfig(nil)
+		if err != nil {
+			return nil, err
+		}
+		if config == nil {
+			return nil, fmt.Errorf("CA has not finished initializing")
+		}
+		common, err := config.GetCommonConfig()
+		if err != nil {
+			return nil, err
+		}
+		rev.ExpiresAt = rev.RevokedAt.Add(common.LeafCertTTL)
+	}
+	rev.Normalize()
+
+	id, err := lib.GenerateUUID(func(id string) (bool, error) {
+		_, revs, err := state.CARevocations(nil)
+		if err != nil {
+			return false, err
+		}
+		for _, r := range revs {
+			if r.ID == id {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	rev.ID = id
+
+	req := &structs.CARequest{
+		Op:          structs.CAOpSetRevocations,
+		Revocations: []*structs.CARevocation{rev},
+	}
+	if _, err := c.delegate.ApplyCARequest(req); err != nil {
+		return nil, err
+	}
+
+	c.logger.Info("revoked leaf certificate",
+		"id", rev.ID,
+		"serial", rev.SerialNumber,
+		"service", rev.Service,
+		"reason", rev.Reason,
+	)
+	return rev, nil
+}
+
+// DeleteRevocation deletes the revocation with the given ID, trusting the
+// certificate or service identity again.
+func (c *CAManager) DeleteRevocation(id string) error {
+	req := &structs.CARequest{
+		Op:          structs.CAOpDeleteRevocations,
+		Revocations: []*structs.CARevocation{{ID: id}},
+	}
+	_, err := c.delegate.ApplyCARequest(req)
+	return err
+}
+
+// secondaryUpdateRevocations makes the revocations stored in this datacenter
+// match the ones of the primary datacenter.
+func (c *CAManager) secondaryUpdateRevocations(primary []*structs.CARevocation) error {
+	_, local, err := c.delegate.State().CARevocations(nil)
+	if err != nil {
+		return err
+	}
+
+	localByID := make(map[string]*structs.CARevocation, len(local))
+	for _, r := range local {
+		localByID[r.ID] = r
+	}
+
+	var set []*structs.CARevocation
+	for _, r := range primary {
+		existing, ok := localByID[r.ID]
+		delete(localByID, r.ID)
+		if ok && caRevocationEqual(existing, r) {
+			continue
+		}
+		// The raft indexes are the ones of this datacenter.
+		r = &structs.CARevocation{
+			ID:             r.ID,
+			SerialNumber:   r.SerialNumber,
+			Service:        r.Service,
+			Reason:         r.Reason,
+			RevokedAt:      r.RevokedAt,
+			ExpiresAt:      r.ExpiresAt,
+			EnterpriseMeta: r.EnterpriseMeta,
+		}
+		set = append(set, r)
+	}
+
+	if len(set) > 0 {
+		req := &structs.CARequest{
+			Op:          structs.CAOpSetRevocations,
+			Revocations: set,
+		}
+		if _, err := c.delegate.ApplyCARequest(req); err != nil {
+			return fmt.Errorf("failed to replicate revocations: %w", err)
+		}
+	}
+
+	if len(localByID) > 0 {
+		deleted := make([]*structs.CARevocation, 0, len(localByID))
+		for _, r := range localByID {
+			deleted = append(deleted, r)
+		}
+		req := &structs.CARequest{
+			Op:          structs.CAOpDeleteRevocations,
+			Revocations: deleted,
+		}
+		if _, err := c.delegate.ApplyCARequest(req); err != nil {
+			return fmt.Errorf("failed to replicate revocations: %w", err)
+		}
+	}
+	return nil
+}
+
+// caRevocationEqual compares two revocations ignoring their raft indexes.
+func caRevocationEqual(a, b *structs.CARevocation) bool {
+	return a.SerialNumber == b.SerialNumber &&
+		a.Service == b.Service &&
+		a.Reason == b.Reason &&
+		a.RevokedAt.Equal(b.RevokedAt) &&
+		a.ExpiresAt.Equal(b.ExpiresAt) &&
+		reflect.DeepEqual(a.EnterpriseMeta, b.EnterpriseMeta)
+}
+
+// caServiceRevocation returns the revocation of the given service identity,
+// if any.
+func caServiceRevocation(state *state.Store, service string, entMeta *acl.EnterpriseMeta) (*structs.CARevocation, error) {
+	_, revs, err := state.CARevocations(nil)
+	if err != nil {
+		return nil, err
+	}
+	roots := structs.IndexedCARoots{Revocations: revs}
+	return roots.ServiceRevoked(service, entMeta), nil
+}
+
+// normalizeSerialNumber returns the serial number in the format used by
+// IssuedCert, accepting it with or without the colon separators. The serial
+// number must already have been validated.
+func normalizeSerialNumber(serial string) string {
+	n, _ := new(big.Int).SetString(strings.ReplaceAll(serial, ":", ""), 16)
+	return connect.HexString(n.Bytes())
+}
+
+var metricsKeyMeshRevocationListsUnsupported = []string{"mesh", "revocation-lists", "unsupported"}
+
+var LeaderCARevocationListGauges = []prometheus.GaugeDefinition{
+	{
+		Name: metricsKeyMeshRevocationListsUnsupported,
+		Help: "1 if the CA provider of this datacenter can't sign revocation lists, so the proxies don't enforce the serial number revocations in any datacenter.",
+	},
+}
+
+// runCARevocationLists keeps the revocation lists of the leaf signing keys of
+// this datacenter up to date and, in the primary datacenter, deletes the
+// serial number revocations of the certificates that have expired.
+func (s *Server) runCARevocationLists(ctx context.Context) error {
+	logger := s.loggers.Named(logging.Connect)
+
+	var datacenters []string
+	warned := false
+	for {
+		ws := memdb.NewWatchSet()
+
+		// Whether the lists are given to the proxies also depends on the
+		// known datacenters, see getCARoots.
+		dcs, dcsCh := s.router.GetDatacentersWatch()
+		ws.Add(dcsCh)
+		if datacenters != nil && !reflect.DeepEqual(datacenters, dcs) {
+			if err := s.touchCARevocationLists(); err != nil {
+				logger.Error("error updating CA revocation lists", "error", err)
+			} else {
+				datacenters = dcs
+			}
+		} else {
+			datacenters = dcs
+		}
+
+		if err := s.updateCARevocationLists(ws, time.Now().UTC()); err != nil {
+			logger.Error("error updating CA revocation lists", "error", err)
+		}
+
+		unsupported, err := s.caRevocationListsUnsupported()
+		if err != nil {
+			logger.Error("error updating CA revocation lists", "error", err)
+		}
+		if unsupported {
+			metrics.SetGauge(metricsKeyMeshRevocationListsUnsupported, 1)
+			if !warned {
+				logger.Warn("the CA provider can't sign revocation lists, so the proxies of every datacenter " +
+					"accept the revoked leaf certificates until they expire; the servers still refuse them")
+			}
+		} else {
+			metrics.SetGauge(metricsKeyMeshRevocationListsUnsupported, 0)
+		}
+		warned = unsupported
+
+		// Update the lists as soon as the revocations or the signing key
+		// change, and periodically to prune what expired and retry failures.
+		watchCtx, cancel := context.WithTimeout(ctx, caRevocationListInterval)
+		ws.WatchCtx(watchCtx)
+		cancel()
+
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+	}
+}
+
+// updateCARevocationLists signs the revocation list of the active leaf signing
+// key of this datacenter whenever the revocations change, and publishes the
+// lists of this datacenter in the primary datacenter.
+//
+// Proxies checking revocation lists reject every certificate whose issuer has
+// no list. So a datacenter whose provider can't sign lists deletes all of its
+// lists, and its lists only become Complete one leaf certificate TTL after the
+// first of them was signed, once the certificates of older keys have expired.
+//
+// The list of a key that was rotated out can't be signed again, so it misses
+// the certificates revoked after the rotation. The servers still reject them
+// and the agents replace them.
+func (s *Server) updateCARevocationLists(ws memdb.WatchSet, now time.Time) error {
+	if !s.config.ConnectEnabled {
+		return nil
+	}
+
+	state := s.fsm.State()
+	ws.Add(state.AbandonCh())
+
+	revIndex, revs, err := state.CARevocations(ws)
+	if err != nil {
+		return err
+	}
+	// The roots change along with the leaf signing key.
+	if _, _, err := state.CARoots(ws); err != nil {
+		return err
+	}
+	_, config, err := state.CAConfig(nil)
+	if err != nil {
+		return err
+	}
+	if config == nil {
+		return nil
+	}
+	common, err := config.GetCommonConfig()
+	if err != nil {
+		return err
+	}
+
+	primary := s.config.PrimaryDatacenter == s.config.Datacenter
+	if primary {
+		if revs, err = s.pruneExpiredCARevocations(revs, now); err != nil {
+			return err
+		}
+	}
+
+	provider, _ := s.caManager.getCAProvider()
+	if provider == nil {
+		return nil
+	}
+
+	_, lists, err := state.CARevocationLists(nil)
+	if err != nil {
+		return err
+	}
+
+	// IMPORTANT: the lists must NEVER be modified, since they are pointers
+	// directly to the structures in the memdb store.
+	dc := s.config.Datacenter
+	var own, expired []*structs.CARevocationList
+	for _, l := range lists {
+		switch {
+		case l.Datacenter == dc:
+			own = append(own, l)
+		case primary && now.After(l.NotAfter):
+			expired = append(expired, &structs.CARevocationList{SigningKeyID: l.SigningKeyID})
+		}
+	}
+
+	var updated []*structs.CARevocationList
+	if signer, ok := provider.(ca.RevocationListSigner); ok {
+		updated, err = signCARevocationList(provider, signer, own, revs, revIndex, now)
+		if err != nil {
+			return err
+		}
+		for _, l := range updated {
+			l.Datacenter = dc
+		}
+		markCARevocationListsComplete(updated, common.LeafCertTTL, now)
+	}
+
+	if err := s.setCARevocationLists(dc, updated); err != nil {
+		return err
+	}
+
+	if len(expired) > 0 {
+		args := &structs.CARequest{
+			Op:              structs.CAOpDeleteRevocationLists,
+			RevocationLists: expired,
+		}
+		if _, err := s.raftApply(structs.ConnectCARequestType, args); err != nil {
+			return fmt.Errorf("failed to delete revocation lists: %w", err)
+		}
+	}
+
+	if primary {
+		return nil
+	}
+
+	args := structs.CARevocationListRequest{
+		Datacenter:       s.config.PrimaryDatacenter,
+		SourceDatacenter: dc,
+		RevocationLists:  updated,
+		WriteRequest:     structs.WriteRequest{Token: s.tokens.ReplicationToken()},
+	}
+	var reply struct{}
+	if err := s.forwardDC("ConnectCA.RevocationListSet", s.config.PrimaryDatacenter, &args, &reply); err != nil {
+		return fmt.Errorf("failed to publish the revocation lists in the primary datacenter: %w", err)
+	}
+	return nil
+}
+
+// touchCARevocationLists bumps the index of the revocation lists without
+// changing them, so the blocking queries of the roots run again.
+func (s *Server) touchCARevocationLists() error {
+	args := &structs.CARequest{
+		Op: structs.CAOpSetRevocationLists,
+	}
+	if _, err := s.raftApply(structs.ConnectCARequestType, args); err != nil {
+		return fmt.Errorf("failed to store revocation lists: %w", err)
+	}
+	return nil
+}
+
+// caRevocationListsUnsupported returns whether there are serial number
+// revocations that the CA provider of this datacenter can't sign revocation
+// lists for.
+func (s *Server) caRevocationListsUnsupported() (bool, error) {
+	provider, _ := s.caManager.getCAProvider()
+	if provider == nil {
+		return false, nil
+	}
+	if _, ok := provider.(ca.RevocationListSigner); ok {
+		return false, nil
+	}
+	_, revs, err := s.fsm.State().CARevocations(nil)
+	if err != nil {
+		return false, err
+	}
+	for _, r := range revs {
+		if r.SerialNumber != "" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// signCARevocationList returns copies of the unexpired lists in own, with the
+// list of the active leaf signing key of provider signed again if the
+// revocations changed since it was signed.
+func signCARevocationList(
+	provider ca.Provider,
+	signer ca.RevocationListSigner,
+	own []*structs.CARevocationList,
+	revs []*structs.CARevocation,
+	revIndex uint64,
+	now time.Time,
+) ([]*structs.CARevocationList, error) {
+	certPEM, err := provider.ActiveLeafSigningCert()
+	if err != nil {
+		return nil, err
+	}
+	cert, err := connect.ParseCert(certPEM)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing leaf signing cert: %w", err)
+	}
+	keyID := connect.EncodeSigningKeyID(cert.SubjectKeyId)
+
+	var updated []*structs.CARevocationList
+	var active *structs.CARevocationList
+	for _, l := range own {
+		if now.After(l.NotAfter) {
+			continue
+		}
+		l := *l
+		updated = append(updated, &l)
+		if l.SigningKeyID == keyID {
+			active = updated[len(updated)-1]
+		}
+	}
+	if active == nil {
+		active = &structs.CARevocationList{
+			SigningKeyID: keyID,
+			NotAfter:     cert.NotAfter,
+			CreatedAt:    now,
+		}
+		updated = append(updated, active)
+	}
+
+	// The index of the revocations increases every time they change.
+	number := new(big.Int).SetUint64(revIndex)
+	if n := caRevocationListNumber(active.PEM); n != nil && n.Cmp(number) == 0 {
+		return updated, nil
+	}
+
+	var revoked []pkix.RevokedCertificate
+	for _, r := range revs {
+		if r.SerialNumber == "" {
+			continue
+		}
+		serial, ok := new(big.Int).SetString(strings.ReplaceAll(r.SerialNumber, ":", ""), 16)
+		if !ok {
+			continue
+		}
+		revoked = append(revoked, pkix.RevokedCertificate{
+			SerialNumber:   serial,
+			RevocationTime: r.RevokedAt,
+		})
+	}
+
+	active.PEM, err = signer.SignRevocationList(revoked, number)
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// markCARevocationListsComplete marks the lists of a datacenter Complete once
+// leafCertTTL has passed since the oldest of them was signed. The datacenter
+// deletes all its lists when it can't sign one for its active key, so every
+// leaf certificate signed by a key without a list has expired by then.
+func markCARevocationListsComplete(lists []*structs.CARevocationList, leafCertTTL time.Duration, now time.Time) {
+	complete := false
+	oldest := now
+	for _, l := range lists {
+		if l.Complete {
+			complete = true
+		}
+		if l.CreatedAt.Before(oldest) {
+			oldest = l.CreatedAt
+		}
+	}
+	if now.Sub(oldest) >= leafCertTTL {
+		complete = true
+	}
+	for _, l := range lists {
+		l.Complete = complete
+	}
+}
+
+// caRevocationListNumber returns the CRL number of the given PEM encoded list,
+// or nil if it can't be parsed.
+func caRevocationListNumber(crlPEM string) *big.Int {
+	block, _ := pem.Decode([]byte(crlPEM))
+	if block == nil {
+		return nil
+	}
+	crl, err := x509.ParseRevocationList(block.Bytes)
+	if err != nil {
+		return nil
+	}
+	return crl.Number
+}
+
+// pruneExpiredCARevocations deletes the serial number revocations of the
+// certificates that have expired, and returns the remaining revocations.
+func (s *Server) pruneExpiredCARevocations(revs []*structs.CARevocation, now time.Time) ([]*structs.CARevocation, error) {
+	var remaining, expired []*structs.CARevocation
+	for _, r := range revs {
+		if r.SerialNumber != "" && !r.ExpiresAt.IsZero() && now.After(r.ExpiresAt) {
+			expired = append(expired, &structs.CARevocation{ID: r.ID})
+			continue
+		}
+		remaining = append(remaining, r)
+	}
+	if len(expired) == 0 {
+		return revs, nil
+	}
+
+	args := &structs.CARequest{
+		Op:          structs.CAOpDeleteRevocations,
+		Revocations: expired,
+	}
+	if _, err := s.raftApply(structs.ConnectCARequestType, args); err != nil {
+		return nil, fmt.Errorf("failed to delete expired revocations: %w", err)
+	}
+	s.loggers.Named(logging.Connect).Info("deleted the revocations of expired certificates", "count", len(expired))
+	return remaining, nil
+}
+
+// setCARevocationLists replaces the revocation lists of the given datacenter.
+func (s *Server) setCARevocationLists(dc string, lists []*structs.CARevocationList) error {
+	_, existing, err := s.fsm.State().CARevocationLists(nil)
+	if err != nil {
+		return err
+	}
+
+	existingByID := make(map[string]*structs.CARevocationList)
+	for _, l := range existing {
+		if l.Datacenter == dc {
+			existingByID[l.SigningKeyID] = l
+		}
+	}
+
+	var set []*structs.CARevocationList
+	for _, l := range lists {
+		e, ok := existingByID[l.SigningKeyID]
+		delete(existingByID, l.SigningKeyID)
+		if ok && caRevocationListEqual(e, l) {
+			continue
+		}
+		// The raft indexes are the ones of this datacenter.
+		set = append(set, &structs.CARevocationList{
+			SigningKeyID: l.SigningKeyID,
+			Datacenter:   dc,
+			PEM:          l.PEM,
+			NotAfter:     l.NotAfter,
+			CreatedAt:    l.CreatedAt,
+			Complete:     l.Complete,
+		})
+	}
+
+	if len(set) > 0 {
+		args := &structs.CARequest{
+			Op:              structs.CAOpSetRevocationLists,
+			RevocationLists: set,
+		}
+		if _, err := s.raftApply(structs.ConnectCARequestType, args); err != nil {
+			return fmt.Errorf("failed to store revocation lists: %w", err)
+		}
+	}
+
+	if len(existingByID) > 0 {
+		deleted := make([]*structs.CARevocationList, 0, len(existingByID))
+		for id := range existingByID {
+			deleted = append(deleted, &structs.CARevocationList{SigningKeyID: id})
+		}
+		args := &structs.CARequest{
+			Op:              structs.CAOpDeleteRevocationLists,
+			RevocationLists: deleted,
+		}
+		if _, err := s.raftApply(structs.ConnectCARequestType, args); err != nil {
+			return fmt.Errorf("failed to delete revocation lists: %w", err)
+		}
+	}
+	return nil
+}
+
+// secondaryUpdateRevocationLists makes the revocation lists of the other
+// datacenters stored in this datacenter match the ones of the primary
+// datacenter. The lists of this datacenter are signed here.
+func (c *CAManager) secondaryUpdateRevocationLists(primary []*structs.CARevocationList) error {
+	_, local, err := c.delegate.State().CARevocationLists(nil)
+	if err != nil {
+		return err
+	}
+
+	dc := c.serverConf.Datacenter
+	localByID := make(map[string]*structs.CARevocationList, len(local))
+	for _, l := range local {
+		if l.Datacenter != dc {
+			localByID[l.SigningKeyID] = l
+		}
+	}
+
+	var set []*structs.CARevocationList
+	for _, l := range primary {
+		if l.Datacenter == dc {
+			continue
+		}
+		existing, ok := localByID[l.SigningKeyID]
+		delete(localByID, l.SigningKeyID)
+		if ok && caRevocationListEqual(existing, l) {
+			continue
+		}
+		// The raft indexes are the ones of this datacenter.
+		set = append(set, &structs.CARevocationList{
+			SigningKeyID: l.SigningKeyID,
+			Datacenter:   l.Datacenter,
+			PEM:          l.PEM,
+			NotAfter:     l.NotAfter,
+			CreatedAt:    l.CreatedAt,
+			Complete:     l.Complete,
+		})
+	}
+
+	if len(set) > 0 {
+		req := &structs.CARequest{
+			Op:              structs.CAOpSetRevocationLists,
+			RevocationLists: set,
+		}
+		if _, err := c.delegate.ApplyCARequest(req); err != nil {
+			return fmt.Errorf("failed to replicate revocation lists: %w", err)
+		}
+	}
+
+	if len(localByID) > 0 {
+		deleted := make([]*structs.CARevocationList, 0, len(localByID))
+		for id := range localByID {
+			deleted = append(deleted, &structs.CARevocationList{SigningKeyID: id})
+		}
+		req := &structs.CARequest{
+			Op:              structs.CAOpDeleteRevocationLists,
+			RevocationLists: deleted,
+		}
+		if _, err := c.delegate.ApplyCARequest(req); err != nil {
+			return fmt.Errorf("failed to replicate revocation lists: %w", err)
+		}
+	}
+	return nil
+}
+
+// caRevocationListEqual compares two revocation lists ignoring their raft
+// indexes.
+func caRevocationListEqual(a, b *structs.CARevocationList) bool {
+	return a.Datacenter == b.Datacenter &&
+		a.PEM == b.PEM &&
+		a.NotAfter.Equal(b.NotAfter) &&
+		a.CreatedAt.Equal(b.CreatedAt) &&
+		a.Complete == b.Complete
+}
+
+// caRevocationListsComplete reports whether the revocation lists can be given
+// to the proxies: there must be a serial number revocation to enforce, a list
+// for the active leaf signing key of this datacenter, and a Complete list for
+// every known datacenter.
+func caRevocationListsComplete(
+	dc string,
+	datacenters []string,
+	activeRoot *structs.CARoot,
+	revs []*structs.CARevocation,
+	lists []*structs.CARevocationList,
+) bool {
+	serials := false
+	for _, r := range revs {
+		if r.SerialNumber != "" {
+			serials = true
+			break
+		}
+	}
+	if !serials || activeRoot == nil {
+		return false
+	}
+
+	complete := make(map[string]bool)
+	activeKey := false
+	for _, l := range lists {
+		if l.Complete {
+			complete[l.Datacenter] = true
+		}
+		if l.Datacenter == dc && l.SigningKeyID == activeRoot.SigningKeyID {
+			activeKey = true
+		}
+	}
+	if !activeKey || !complete[dc] {
+		return false
+	}
+	for _, d := range datacenters {
+		if !complete[d] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,66 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package consul
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hernad/consul/agent/structs"
+)
+
+func TestMarkCARevocationListsComplete(t *testing.T) {
+	now := time.Now()
+	ttl := 72 * time.Hour
+
+	lists := []*structs.CARevocationList{
+		{SigningKeyID: "old", CreatedAt: now.Add(-time.Hour)},
+		{SigningKeyID: "new", CreatedAt: now},
+	}
+	markCARevocationListsComplete(lists, ttl, now)
+	require.False(t, lists[0].Complete)
+	require.False(t, lists[1].Complete)
+
+	// The certificates signed before the first list have expired.
+	markCARevocationListsComplete(lists, ttl, now.Add(ttl))
+	require.True(t, lists[0].Complete)
+	require.True(t, lists[1].Complete)
+
+	// The list of a new key is complete as long as the older ones are.
+	lists = []*structs.CARevocationList{
+		{SigningKeyID: "old", CreatedAt: now.Add(-time.Hour), Complete: true},
+		{SigningKeyID: "new", CreatedAt: now},
+	}
+	markCARevocationListsComplete(lists, ttl, now)
+	require.True(t, lists[1].Complete)
+}
+
+func TestCARevocationListsComplete(t *testing.T) {
+	activeRoot := &structs.CARoot{SigningKeyID: "aa"}
+	serial := []*structs.CARevocation{{SerialNumber: "01"}}
+	lists := []*structs.CARevocationList{
+		{SigningKeyID: "aa", Datacenter: "dc1", Complete: true},
+		{SigningKeyID: "bb", Datacenter: "dc2", Complete: true},
+	}
+	dcs := []string{"dc1", "dc2"}
+
+	require.True(t, caRevocationListsComplete("dc1", dcs, activeRoot, serial, lists))
+
+	// Nothing to enforce without serial number revocations.
+	service := []*structs.CARevocation{{Service: "web"}}
+	require.False(t, caRevocationListsComplete("dc1", dcs, activeRoot, service, lists))
+
+	// Every datacenter must have a complete list.
+	require.False(t, caRevocationListsComplete("dc1", []string{"dc1", "dc2", "dc3"}, activeRoot, serial, lists))
+	incomplete := []*structs.CARevocationList{
+		lists[0],
+		{SigningKeyID: "bb", Datacenter: "dc2"},
+	}
+	require.False(t, caRevocationListsComplete("dc1", dcs, activeRoot, serial, incomplete))
+
+	// The active signing key of this datacenter must have a list.
+	require.False(t, caRevocationListsComplete("dc1", dcs, &structs.CARoot{SigningKeyID: "cc"}, serial, lists))
+}
@@ -6,11 +6,16 @@ package consul
 import (
 	"context"
 	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -23,6 +28,7 @@ import (
 	"github.com/hernad/consul/agent/connect/ca"
 	"github.com/hernad/consul/agent/structs"
 	"github.com/hernad/consul/agent/token"
+	"github.com/hernad/consul/proto/private/pbpeering"
 	"github.com/hernad/consul/sdk/testutil"
 	"github.com/hernad/consul/sdk/testutil/retry"
 	"github.com/hernad/consul/testrpc"
@@ -1270,6 +1276,263 @@ func TestLeader_CARootPruning(t *testing.T) {
 	require.NotEqual(t, roots[0].ID, oldRoot.ID)
 }
 
+func TestLeader_FederatedTrustBundleRefresh(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	var (
+		lock    sync.Mutex
+		fetches int
+		bundle  = &connect.SpiffeBundle{
+			RootPEMs: []string{connect.TestCA(t, nil).RootCert},
+			Sequence: 1,
+		}
+	)
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		fetches++
+		require.NoError(t, json.NewEncoder(w).Encode(bundle))
+	}))
+	defer srv.Close()
+	fetchCount := func() int {
+		lock.Lock()
+		defer lock.Unlock()
+		return fetches
+	}
+	caCert := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}))
+
+	dir1, s1 := testServer(t)
+	defer os.RemoveAll(dir1)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	defer codec.Close()
+
+	testrpc.WaitForActiveCARoot(t, s1.RPC, "dc1", nil)
+
+	setDomains := func(domains []interface{}) {
+		args := &structs.CARequest{
+			Datacenter: "dc1",
+			Config: &structs.CAConfiguration{
+				Provider: "consul",
+				Config: map[string]interface{}{
+					"FederatedTrustDomains": domains,
+				},
+			},
+		}
+		var reply interface{}
+		require.NoError(t, msgpackrpc.CallWithCodec(codec, "ConnectCA.ConfigurationSet", args, &reply))
+	}
+	setDomains([]interface{}{
+		map[string]interface{}{
+			"Name":                 "example.org",
+			"SpiffeIDTemplates":    []interface{}{"/svc/{service}"},
+			"BundleEndpointURL":    srv.URL,
+			"BundleEndpointCACert": caCert,
+		},
+	})
+
+	state := s1.fsm.State()
+	fetched := make(map[string]time.Time)
+	now := time.Now()
+
+	// The bundle is fetched and distributed with the roots.
+	require.NoError(t, s1.refreshFederatedTrustBundles(context.Background(), fetched, now))
+	idx, bundles, err := state.CAFederatedTrustBundles(nil)
+	require.NoError(t, err)
+	require.Len(t, bundles, 1)
+	require.Equal(t, "example.org", bundles[0].TrustDomain)
+	require.Equal(t, bundle.RootPEMs, bundles[0].RootPEMs)
+
+	var roots structs.IndexedCARoots
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "ConnectCA.Roots", &structs.DCSpecificRequest{Datacenter: "dc1"}, &roots))
+	require.NotNil(t, roots.FederatedTrustBundle("example.org"))
+
+	// The bundle is not fetched again before it is due.
+	require.NoError(t, s1.refreshFederatedTrustBundles(context.Background(), fetched, now.Add(time.Minute)))
+	require.Equal(t, 1, fetchCount())
+
+	// An unchanged bundle is not written again.
+	now = now.Add(defaultFederatedTrustBundleRefreshHint)
+	require.NoError(t, s1.refreshFederatedTrustBundles(context.Background(), fetched, now))
+	require.Equal(t, 2, fetchCount())
+	idx2, _, err := state.CAFederatedTrustBundles(nil)
+	require.NoError(t, err)
+	require.Equal(t, idx, idx2)
+
+	// A new sequence is stored along with its refresh hint.
+	lock.Lock()
+	bundle = &connect.SpiffeBundle{
+		RootPEMs:    []string{connect.TestCA(t, nil).RootCert},
+		Sequence:    2,
+		RefreshHint: time.Minute,
+	}
+	lock.Unlock()
+	now = now.Add(defaultFederatedTrustBundleRefreshHint)
+	require.NoError(t, s1.refreshFederatedTrustBundles(context.Background(), fetched, now))
+	_, bundles, err = state.CAFederatedTrustBundles(nil)
+	require.NoError(t, err)
+	require.Len(t, bundles, 1)
+	require.Equal(t, uint64(2), bundles[0].Sequence)
+	require.Equal(t, time.Minute, bundles[0].RefreshHint)
+
+	require.NoError(t, s1.refreshFederatedTrustBundles(context.Background(), fetched, now.Add(time.Minute)))
+	require.Equal(t, 4, fetchCount())
+
+	// The bundle is deleted once the trust domain no longer has an endpoint.
+	setDomains([]interface{}{
+		map[string]interface{}{
+			"Name":              "example.org",
+			"SpiffeIDTemplates": []interface{}{"/svc/{service}"},
+		},
+	})
+	require.NoError(t, s1.refreshFederatedTrustBundles(context.Background(), fetched, now.Add(2*time.Minute)))
+	_, bundles, err = state.CAFederatedTrustBundles(nil)
+	require.NoError(t, err)
+	require.Empty(t, bundles)
+	require.Empty(t, fetched)
+}
+
+func TestLeader_FederatedTrustDomains_Reserved(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	dir1, s1 := testServer(t)
+	defer os.RemoveAll(dir1)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	defer codec.Close()
+
+	testrpc.WaitForActiveCARoot(t, s1.RPC, "dc1", nil)
+
+	state := s1.fsm.State()
+	require.NoError(t, state.PeeringWrite(100, &pbpeering.PeeringWriteRequest{
+		Peering: &pbpeering.Peering{
+			ID:   "9e650110-ac74-4c5a-a6a8-9348b2bed4e9",
+			Name: "my-peer",
+		},
+	}))
+	require.NoError(t, state.PeeringTrustBundleWrite(101, &pbpeering.PeeringTrustBundle{
+		TrustDomain: "952e6bd1-f4d6-47f7-83ff-84b31babaa17.consul",
+		PeerName:    "my-peer",
+		RootPEMs:    []string{connect.TestCA(t, nil).RootCert},
+	}))
+
+	_, caConf, err := state.CAConfig(nil)
+	require.NoError(t, err)
+	localDomain := connect.SpiffeIDSigningForCluster(caConf.ClusterID).Host()
+
+	setDomain := func(name string) error {
+		args := &structs.CARequest{
+			Datacenter: "dc1",
+			Config: &structs.CAConfiguration{
+				Provider: "consul",
+				Config: map[string]interface{}{
+					"FederatedTrustDomains": []interface{}{
+						map[string]interface{}{
+							"Name":              name,
+							"SpiffeIDTemplates": []interface{}{"/ns/{namespace}/dc/{datacenter}/svc/{service}"},
+						},
+					},
+				},
+			},
+		}
+		var reply interface{}
+		return msgpackrpc.CallWithCodec(codec, "ConnectCA.ConfigurationSet", args, &reply)
+	}
+
+	err = setDomain(strings.ToUpper(localDomain))
+	testutil.RequireErrorContains(t, err, "is the trust domain of this cluster")
+
+	err = setDomain("952e6bd1-f4d6-47f7-83ff-84b31babaa17.consul")
+	testutil.RequireErrorContains(t, err, `is the trust domain of peer "my-peer"`)
+
+	// Neither domain was stored.
+	_, caConf, err = state.CAConfig(nil)
+	require.NoError(t, err)
+	common, err := caConf.GetCommonConfig()
+	require.NoError(t, err)
+	require.Empty(t, common.FederatedTrustDomains)
+
+	require.NoError(t, setDomain("example.org"))
+}
+
+func TestLeader_JWTSigningKeyRotation(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	dir1, s1 := testServer(t)
+	defer os.RemoveAll(dir1)
+	defer s1.Shutdown()
+
+	testrpc.WaitForActiveCARoot(t, s1.RPC, "dc1", nil)
+
+	// The leader creates the first key right away.
+	state := s1.fsm.State()
+	var first *structs.CAJWTSigningKey
+	retry.Run(t, func(r *retry.R) {
+		_, keys, err := state.CAJWTSigningKeys(nil)
+		require.NoError(r, err)
+		require.Len(r, keys, 1)
+		first = keys[0]
+	})
+	require.True(t, first.Active)
+	require.NotEmpty(t, first.PrivateKey)
+
+	keysByID := func() map[string]*structs.CAJWTSigningKey {
+		_, keys, err := state.CAJWTSigningKeys(nil)
+		require.NoError(t, err)
+		m := make(map[string]*structs.CAJWTSigningKey)
+		for _, k := range keys {
+			m[k.ID] = k
+		}
+		return m
+	}
+
+	// Nothing changes before the rotation period.
+	now := first.CreatedAt.Add(time.Hour)
+	require.NoError(t, s1.rotateJWTSigningKeys(now))
+	require.Len(t, keysByID(), 1)
+
+	// The next key is published inactive first.
+	now = first.CreatedAt.Add(jwtSigningKeyRotationPeriod)
+	require.NoError(t, s1.rotateJWTSigningKeys(now))
+	keys := keysByID()
+	require.Len(t, keys, 2)
+	require.True(t, keys[first.ID].Active)
+	var next *structs.CAJWTSigningKey
+	for id, k := range keys {
+		if id != first.ID {
+			next = k
+		}
+	}
+	require.False(t, next.Active)
+
+	// It becomes active after the publish delay.
+	now = now.Add(jwtSigningKeyPublishDelay)
+	require.NoError(t, s1.rotateJWTSigningKeys(now))
+	keys = keysByID()
+	require.Len(t, keys, 2)
+	require.False(t, keys[first.ID].Active)
+	require.Equal(t, now, keys[first.ID].RotatedOutAt)
+	require.True(t, keys[next.ID].Active)
+
+	// The old key is deleted once its tokens have expired.
+	require.NoError(t, s1.rotateJWTSigningKeys(now.Add(jwtSigningKeyRetention+time.Second)))
+	keys = keysByID()
+	require.Len(t, keys, 1)
+	require.Contains(t, keys, next.ID)
+}
+
 func TestConnectCA_ConfigurationSet_PersistsRoots(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
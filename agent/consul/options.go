@@ -9,6 +9,7 @@ import (
 	"github.com/hashicorp/consul-net-rpc/net/rpc"
 	"github.com/hashicorp/go-hclog"
 
+	"github.com/hernad/consul/agent/audit"
 	"github.com/hernad/consul/agent/consul/stream"
 	"github.com/hernad/consul/agent/grpc-external/limiter"
 	"github.com/hernad/consul/agent/hcp"
@@ -36,6 +37,10 @@ type Deps struct {
 	// NewRequestRecorderFunc provides a middleware.RequestRecorder for the server to use; it cannot be nil
 	NewRequestRecorderFunc func(logger hclog.Logger, isLeader func() bool, localDC string) *middleware.RequestRecorder
 
+	// Auditor records the HTTP and RPC requests in the audit log. It is nil
+	// when the audit log is disabled.
+	Auditor *audit.Logger
+
 	// HCP contains the dependencies required when integrating with the HashiCorp Cloud Platform
 	HCP hcp.Deps
 
@@ -137,27 +137,31 @@ const (
 )
 
 const (
-	aclPolicyReplicationRoutineName       = "ACL policy replication"
-	aclRoleReplicationRoutineName         = "ACL role replication"
-	aclTokenReplicationRoutineName        = "ACL token replication"
-	aclTokenReapingRoutineName            = "acl token reaping"
-	caRootPruningRoutineName              = "CA root pruning"
-	caRootMetricRoutineName               = "CA root expiration metric"
-	caSigningMetricRoutineName            = "CA signing expiration metric"
-	configEntryControllersRoutineName     = "config entry controllers"
-	configReplicationRoutineName          = "config entry replication"
-	federationStateReplicationRoutineName = "federation state replication"
-	federationStateAntiEntropyRoutineName = "federation state anti-entropy"
-	federationStatePruningRoutineName     = "federation state pruning"
-	intentionMigrationRoutineName         = "intention config entry migration"
-	secondaryCARootWatchRoutineName       = "secondary CA roots watch"
-	intermediateCertRenewWatchRoutineName = "intermediate cert renew watch"
-	backgroundCAInitializationRoutineName = "CA initialization"
-	virtualIPCheckRoutineName             = "virtual IP version check"
-	peeringStreamsRoutineName             = "streaming peering resources"
-	peeringDeletionRoutineName            = "peering deferred deletion"
-	peeringStreamsMetricsRoutineName      = "metrics for streaming peering resources"
-	raftLogVerifierRoutineName            = "raft log verifier"
+	aclPolicyReplicationRoutineName         = "ACL policy replication"
+	aclRoleReplicationRoutineName           = "ACL role replication"
+	aclPolicyTemplateReplicationRoutineName = "ACL policy template replication"
+	aclTokenReplicationRoutineName          = "ACL token replication"
+	aclTokenReapingRoutineName              = "acl token reaping"
+	caRootPruningRoutineName                = "CA root pruning"
+	caRevocationListRoutineName             = "CA revocation lists"
+	caRootMetricRoutineName                 = "CA root expiration metric"
+	caSigningMetricRoutineName              = "CA signing expiration metric"
+	configEntryControllersRoutineName       = "config entry controllers"
+	configReplicationRoutineName            = "config entry replication"
+	federationStateReplicationRoutineName   = "federation state replication"
+	federationStateAntiEntropyRoutineName   = "federation state anti-entropy"
+	federationStatePruningRoutineName       = "federation state pruning"
+	federatedTrustBundleRoutineName         = "federated trust bundle refresh"
+	intentionMigrationRoutineName           = "intention config entry migration"
+	jwtSigningKeyRoutineName                = "JWT signing key rotation"
+	secondaryCARootWatchRoutineName         = "secondary CA roots watch"
+	intermediateCertRenewWatchRoutineName   = "intermediate cert renew watch"
+	backgroundCAInitializationRoutineName   = "CA initialization"
+	virtualIPCheckRoutineName               = "virtual IP version check"
+	peeringStreamsRoutineName               = "streaming peering resources"
+	peeringDeletionRoutineName              = "peering deferred deletion"
+	peeringStreamsMetricsRoutineName        = "metrics for streaming peering resources"
+	raftLogVerifierRoutineName              = "raft log verifier"
 )
 
 var (
@@ -197,6 +201,10 @@ type Server struct {
 
 	aclAuthMethodValidators authmethod.Cache
 
+	// aclTokenUsage samples when tokens are used on this server. It is nil
+	// when ACLs are disabled.
+	aclTokenUsage *aclTokenUsageTracker
+
 	// autopilot is the Autopilot instance for this server.
 	autopilot *autopilot.Autopilot
 
@@ -530,6 +538,10 @@ func NewServer(config *Config, flat Deps, externalGRPCServer *grpc.Server, incom
 	}
 	incomingRPCLimiter.Register(s)
 
+	if s.config.ACLsEnabled {
+		s.aclTokenUsage = newACLTokenUsageTracker(s.config.ACLTokenUsageGranularity)
+	}
+
 	s.raftStorageBackend, err = raftstorage.NewBackend(&raftHandle{s}, logger.Named("raft-storage-backend"))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create storage backend: %w", err)
@@ -565,8 +577,15 @@ func NewServer(config *Config, flat Deps, externalGRPCServer *grpc.Server, incom
 		rpc.WithPreBodyInterceptor(middleware.GetNetRPCRateLimitingInterceptor(s.incomingRPCLimiter, middleware.NewPanicHandler(s.logger))),
 	}
 
+	var interceptor rpc.ServerServiceCallInterceptor
 	if flat.GetNetRPCInterceptorFunc != nil {
-		rpcServerOpts = append(rpcServerOpts, rpc.WithServerServiceCallInterceptor(flat.GetNetRPCInterceptorFunc(recorder)))
+		interceptor = flat.GetNetRPCInterceptorFunc(recorder)
+	}
+	if flat.Auditor != nil {
+		interceptor = s.auditRPCInterceptor(flat.Auditor, interceptor)
+	}
+	if interceptor != nil {
+		rpcServerOpts = append(rpcServerOpts, rpc.WithServerServiceCallInterceptor(interceptor))
 	}
 
 	s.rpcServer = rpc.NewServerWithOpts(rpcServerOpts...)
@@ -850,6 +869,11 @@ func NewServer(config *Config, flat Deps, externalGRPCServer *grpc.Server, incom
 	// Start the metrics handlers.
 	go s.updateMetrics()
 
+	// Start tracking when ACL tokens are used.
+	if s.aclTokenUsage != nil {
+		go s.aclTokenUsageUpdate()
+	}
+
 	// Now we are setup, configure the HCP manager
 	go s.hcpManager.Run(&lib.StopChannelContext{StopCh: shutdownCh})
 
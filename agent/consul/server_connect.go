@@ -23,7 +23,71 @@ func (s *Server) getCARoots(ws memdb.WatchSet, state *state.Store) (*structs.Ind
 		return nil, fmt.Errorf("CA has not finished initializing")
 	}
 
-	indexedRoots := &structs.IndexedCARoots{}
+	revIndex, revocations, err := state.CARevocations(ws)
+	if err != nil {
+		return nil, err
+	}
+	if revIndex > index {
+		index = revIndex
+	}
+
+	listIndex, lists, err := state.CARevocationLists(ws)
+	if err != nil {
+		return nil, err
+	}
+	if listIndex > index {
+		index = listIndex
+	}
+
+	bundleIndex, bundles, err := state.CAFederatedTrustBundles(ws)
+	if err != nil {
+		return nil, err
+	}
+	if bundleIndex > index {
+		index = bundleIndex
+	}
+
+	jwtIndex, jwtKeys, err := state.CAJWTSigningKeys(ws)
+	if err != nil {
+		return nil, err
+	}
+	if jwtIndex > index {
+		index = jwtIndex
+	}
+
+	indexedRoots := &structs.IndexedCARoots{
+		Revocations:           revocations,
+		FederatedTrustBundles: bundles,
+	}
+
+	// Proxies checking the revocation lists reject every certificate whose
+	// issuer has no list, so they are only distributed once they cover the
+	// leaf certificates of every datacenter.
+	var activeRoot *structs.CARoot
+	for _, r := range roots {
+		if r.Active {
+			activeRoot = r
+			break
+		}
+	}
+	// The leader bumps the index of the lists when the datacenters change.
+	datacenters, datacentersCh := s.router.GetDatacentersWatch()
+	ws.Add(datacentersCh)
+	if caRevocationListsComplete(s.config.Datacenter, datacenters, activeRoot, revocations, lists) {
+		indexedRoots.RevocationLists = lists
+	}
+
+	// Only the public part of the JWT signing keys is distributed.
+	for _, k := range jwtKeys {
+		indexedRoots.JWTSigningKeys = append(indexedRoots.JWTSigningKeys, &structs.CAJWTSigningKey{
+			ID:           k.ID,
+			PublicKey:    k.PublicKey,
+			Active:       k.Active,
+			CreatedAt:    k.CreatedAt,
+			RotatedOutAt: k.RotatedOutAt,
+			RaftIndex:    k.RaftIndex,
+		})
+	}
 
 	// Build TrustDomain based on the ClusterID stored.
 	signingID := connect.SpiffeIDSigningForCluster(config.ClusterID)
@@ -35,6 +99,14 @@ func (s *Server) getCARoots(ws memdb.WatchSet, state *state.Store) (*structs.Ind
 
 	indexedRoots.TrustDomain = signingID.Host()
 
+	// The federated trust domains are distributed along with the roots since
+	// everything that validates certificates needs both.
+	common, err := config.GetCommonConfig()
+	if err != nil {
+		return nil, err
+	}
+	indexedRoots.FederatedTrustDomains = common.FederatedTrustDomains
+
 	indexedRoots.Index, indexedRoots.Roots = index, roots
 	if indexedRoots.Roots == nil {
 		indexedRoots.Roots = make(structs.CARoots, 0)
@@ -499,6 +499,11 @@ func aclTokenSetTxn(tx WriteTxn, idx uint64, token *structs.ACLToken, opts ACLTo
 		return err
 	}
 
+	var numValidTemplates int
+	if numValidTemplates, err = resolveTemplatedPolicyLinks(tx, token.TemplatedPolicies, &token.EnterpriseMeta, opts.AllowMissingPolicyAndRoleIDs); err != nil {
+		return err
+	}
+
 	if token.AuthMethod != "" && !opts.FromReplication {
 		methodMeta := token.ACLAuthMethodEnterpriseMeta.ToEnterpriseMeta()
 		methodMeta.Merge(&token.EnterpriseMeta)
@@ -510,6 +515,15 @@ func aclTokenSetTxn(tx WriteTxn, idx uint64, token *structs.ACLToken, opts ACLTo
 		}
 	}
 
+	if token.ParentAccessorID != "" && !opts.FromReplication {
+		_, parent, err := aclTokenGetFromIndex(tx, token.ParentAccessorID, indexAccessor, nil)
+		if err != nil {
+			return fmt.Errorf("failed parent token lookup: %s", err)
+		} else if parent == nil {
+			return fmt.Errorf("No such parent token with AccessorID: %s", token.ParentAccessorID)
+		}
+	}
+
 	for _, svcid := range token.ServiceIdentities {
 		if svcid.ServiceName == "" {
 			return fmt.Errorf("Encountered a Token with an empty service identity name in the state store")
@@ -526,7 +540,7 @@ func aclTokenSetTxn(tx WriteTxn, idx uint64, token *structs.ACLToken, opts ACLTo
 	}
 
 	if opts.ProhibitUnprivileged {
-		if numValidRoles == 0 && numValidPolicies == 0 && len(token.ServiceIdentities) == 0 && len(token.NodeIdentities) == 0 {
+		if numValidRoles == 0 && numValidPolicies == 0 && numValidTemplates == 0 && len(token.ServiceIdentities) == 0 && len(token.NodeIdentities) == 0 {
 			return ErrTokenHasNoPrivileges
 		}
 	}
@@ -600,6 +614,40 @@ func (s *Store) ACLTokenBatchGet(ws memdb.WatchSet, accessors []string) (uint64,
 	return idx, tokens, nil
 }
 
+// ACLTokenListByParent returns the tokens that were minted as children of the
+// token with the given AccessorID.
+func (s *Store) ACLTokenListByParent(ws memdb.WatchSet, parentAccessorID string) (uint64, structs.ACLTokens, error) {
+	tx := s.db.Txn(false)
+	defer tx.Abort()
+
+	iter, err := tx.Get(tableACLTokens, indexParent, parentAccessorID)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed acl token lookup: %v", err)
+	}
+	ws.Add(iter.WatchCh())
+
+	var tokens structs.ACLTokens
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		token, err := fixupTokenPolicyLinks(tx, raw.(*structs.ACLToken))
+		if err != nil {
+			return 0, nil, err
+		}
+		token, err = fixupTokenRoleLinks(tx, token)
+		if err != nil {
+			return 0, nil, err
+		}
+		token, err = fixupTokenTemplateLinks(tx, token)
+		if err != nil {
+			return 0, nil, err
+		}
+		tokens = append(tokens, token)
+	}
+
+	idx := maxIndexTxn(tx, tableACLTokens)
+
+	return idx, tokens, nil
+}
+
 func aclTokenGetTxn(tx ReadTxn, ws memdb.WatchSet, value, index string, entMeta *acl.EnterpriseMeta) (*structs.ACLToken, error) {
 	watchCh, rawToken, err := aclTokenGetFromIndex(tx, value, index, entMeta)
 	if err != nil {
@@ -617,6 +665,10 @@ func aclTokenGetTxn(tx ReadTxn, ws memdb.WatchSet, value, index string, entMeta
 		if err != nil {
 			return nil, err
 		}
+		token, err = fixupTokenTemplateLinks(tx, token)
+		if err != nil {
+			return nil, err
+		}
 		return token, nil
 	}
 
@@ -694,6 +746,10 @@ func (s *Store) ACLTokenList(ws memdb.WatchSet, local, global bool, policy, role
 		if err != nil {
 			return 0, nil, err
 		}
+		token, err = fixupTokenTemplateLinks(tx, token)
+		if err != nil {
+			return 0, nil, err
+		}
 		result = append(result, token)
 	}
 
@@ -803,7 +859,33 @@ func aclTokenDeleteTxn(tx WriteTxn, idx uint64, value, index string, entMeta *ac
 		return fmt.Errorf("Deletion of the builtin anonymous token is not permitted")
 	}
 
-	return aclTokenDeleteWithToken(tx, token.(*structs.ACLToken), idx)
+	return aclTokenDeleteWithChildrenTxn(tx, idx, token.(*structs.ACLToken))
+}
+
+// aclTokenDeleteWithChildrenTxn deletes the token along with its usage and,
+// recursively, every child token that was minted from it.
+func aclTokenDeleteWithChildrenTxn(tx WriteTxn, idx uint64, token *structs.ACLToken) error {
+	iter, err := tx.Get(tableACLTokens, indexParent, token.AccessorID)
+	if err != nil {
+		return fmt.Errorf("failed acl token lookup: %v", err)
+	}
+
+	var children structs.ACLTokens
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		children = append(children, raw.(*structs.ACLToken))
+	}
+
+	for _, child := range children {
+		if err := aclTokenDeleteWithChildrenTxn(tx, idx, child); err != nil {
+			return err
+		}
+	}
+
+	if err := aclTokenUsageDeleteTxn(tx, idx, token.AccessorID); err != nil {
+		return err
+	}
+
+	return aclTokenDeleteWithToken(tx, token, idx)
 }
 
 func aclTokenDeleteAllForAuthMethodTxn(tx WriteTxn, idx uint64, methodName string, methodGlobalLocality bool, methodMeta *acl.EnterpriseMeta) error {
@@ -830,7 +912,7 @@ func aclTokenDeleteAllForAuthMethodTxn(tx WriteTxn, idx uint64, methodName strin
 	if len(tokens) > 0 {
 		// delete them all
 		for _, token := range tokens {
-			if err := aclTokenDeleteWithToken(tx, token, idx); err != nil {
+			if err := aclTokenDeleteWithChildrenTxn(tx, idx, token); err != nil {
 				return err
 			}
 		}
@@ -1127,6 +1209,10 @@ func aclRoleSetTxn(tx WriteTxn, idx uint64, role *structs.ACLRole, allowMissing
 		return err
 	}
 
+	if _, err := resolveTemplatedPolicyLinks(tx, role.TemplatedPolicies, &role.EnterpriseMeta, allowMissing); err != nil {
+		return err
+	}
+
 	for _, svcid := range role.ServiceIdentities {
 		if svcid.ServiceName == "" {
 			return fmt.Errorf("Encountered a Role with an empty service identity name in the state store")
@@ -1211,6 +1297,10 @@ func getRoleWithTxn(tx ReadTxn, ws memdb.WatchSet, value string, fn aclRoleGetFn
 		if err != nil {
 			return nil, err
 		}
+		role, err = fixupRoleTemplateLinks(tx, role)
+		if err != nil {
+			return nil, err
+		}
 		return role, nil
 	}
 
@@ -1262,6 +1352,10 @@ func (s *Store) ACLRoleList(ws memdb.WatchSet, policy string, entMeta *acl.Enter
 		if err != nil {
 			return 0, nil, err
 		}
+		role, err = fixupRoleTemplateLinks(tx, role)
+		if err != nil {
+			return 0, nil, err
+		}
 		result = append(result, role)
 	}
 
@@ -53,6 +53,29 @@ func aclChangeUnsubscribeEvent(tx ReadTxn, changes Changes) ([]stream.Event, err
 				}
 				secretIDs = appendSecretIDsFromTokenIterator(secretIDs, tokens)
 			}
+
+		case tableACLPolicyTemplates:
+			template := changeObject(change).(*structs.ACLPolicyTemplate)
+			q := Query{Value: template.ID, EnterpriseMeta: template.EnterpriseMeta}
+			tokens, err := tx.Get(tableACLTokens, indexTemplates, q)
+			if err != nil {
+				return nil, err
+			}
+			secretIDs = appendSecretIDsFromTokenIterator(secretIDs, tokens)
+
+			roles, err := tx.Get(tableACLRoles, indexTemplates, q)
+			if err != nil {
+				return nil, err
+			}
+			for role := roles.Next(); role != nil; role = roles.Next() {
+				role := role.(*structs.ACLRole)
+
+				tokens, err := aclTokenListByRole(tx, role.ID, &template.EnterpriseMeta)
+				if err != nil {
+					return nil, err
+				}
+				secretIDs = appendSecretIDsFromTokenIterator(secretIDs, tokens)
+			}
 		}
 	}
 	// There may be duplicate secretIDs here. We rely on this event allowing
@@ -41,11 +41,42 @@ func testIndexerTableACLPolicies() map[string]indexerTestCase {
 	}
 }
 
+func testIndexerTableACLPolicyTemplates() map[string]indexerTestCase {
+	obj := &structs.ACLPolicyTemplate{
+		ID:   "123e4567-e89b-12d3-a456-426614174abc",
+		Name: "TeMpLaTeNaMe",
+	}
+	encodedID := []byte{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x4a, 0xbc}
+	return map[string]indexerTestCase{
+		indexID: {
+			read: indexValue{
+				source:   obj.ID,
+				expected: encodedID,
+			},
+			write: indexValue{
+				source:   obj,
+				expected: encodedID,
+			},
+		},
+		indexName: {
+			read: indexValue{
+				source:   Query{Value: "TemplateName"},
+				expected: []byte("templatename\x00"),
+			},
+			write: indexValue{
+				source:   obj,
+				expected: []byte("templatename\x00"),
+			},
+		},
+	}
+}
+
 func testIndexerTableACLTokens() map[string]indexerTestCase {
 	policyID1 := "123e4567-e89a-12d7-a456-426614174001"
 	policyID2 := "123e4567-e89a-12d7-a456-426614174002"
 	roleID1 := "123e4567-e89a-12d7-a457-426614174001"
 	roleID2 := "123e4567-e89a-12d7-a457-426614174002"
+	templateID := "123e4567-e89a-12d7-a458-426614174001"
 	obj := &structs.ACLToken{
 		AccessorID: "123e4567-e89a-12d7-a456-426614174abc",
 		SecretID:   "123e4567-e89a-12d7-a456-426614174abd",
@@ -56,8 +87,14 @@ func testIndexerTableACLTokens() map[string]indexerTestCase {
 		Roles: []structs.ACLTokenRoleLink{
 			{ID: roleID1}, {ID: roleID2},
 		},
-		AuthMethod: "test-Auth-Method",
+		TemplatedPolicies: structs.ACLTemplatedPolicies{
+			{TemplateID: templateID}, {TemplateID: templateID, Variables: map[string]string{"name": "web"}},
+		},
+		AuthMethod:       "test-Auth-Method",
+		ParentAccessorID: "123e4567-e89a-12d7-a459-426614174001",
 	}
+	encodedParentID := []byte{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9a, 0x12, 0xd7, 0xa4, 0x59, 0x42, 0x66, 0x14, 0x17, 0x40, 0x01}
+	encodedTID := []byte{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9a, 0x12, 0xd7, 0xa4, 0x58, 0x42, 0x66, 0x14, 0x17, 0x40, 0x01}
 	encodedPID1 := []byte{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9a, 0x12, 0xd7, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x01}
 	encodedPID2 := []byte{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9a, 0x12, 0xd7, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x02}
 	encodedRID1 := []byte{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9a, 0x12, 0xd7, 0xa4, 0x57, 0x42, 0x66, 0x14, 0x17, 0x40, 0x1}
@@ -87,6 +124,16 @@ func testIndexerTableACLTokens() map[string]indexerTestCase {
 				expected: [][]byte{encodedRID1, encodedRID2},
 			},
 		},
+		indexTemplates: {
+			read: indexValue{
+				source:   Query{Value: templateID},
+				expected: encodedTID,
+			},
+			writeMulti: indexValueMulti{
+				source:   obj,
+				expected: [][]byte{encodedTID},
+			},
+		},
 		indexAuthMethod: {
 			read: indexValue{
 				source: AuthMethodQuery{
@@ -99,19 +146,36 @@ func testIndexerTableACLTokens() map[string]indexerTestCase {
 				expected: []byte("test-auth-method\x00"),
 			},
 		},
+		indexParent: {
+			read: indexValue{
+				source:   obj.ParentAccessorID,
+				expected: encodedParentID,
+			},
+			write: indexValue{
+				source:   obj,
+				expected: encodedParentID,
+			},
+		},
 	}
 }
 
 func testIndexerTableACLRoles() map[string]indexerTestCase {
 	policyID1 := "123e4567-e89a-12d7-a456-426614174001"
 	policyID2 := "123e4567-e89a-12d7-a456-426614174002"
+	templateID1 := "123e4567-e89a-12d7-a458-426614174001"
+	templateID2 := "123e4567-e89a-12d7-a458-426614174002"
 	obj := &structs.ACLRole{
 		ID:   "123e4567-e89a-12d7-a456-426614174abc",
 		Name: "RoLe",
 		Policies: []structs.ACLRolePolicyLink{
 			{ID: policyID1}, {ID: policyID2},
 		},
+		TemplatedPolicies: structs.ACLTemplatedPolicies{
+			{TemplateID: templateID1}, {TemplateID: templateID2},
+		},
 	}
+	encodedTID1 := []byte{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9a, 0x12, 0xd7, 0xa4, 0x58, 0x42, 0x66, 0x14, 0x17, 0x40, 0x01}
+	encodedTID2 := []byte{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9a, 0x12, 0xd7, 0xa4, 0x58, 0x42, 0x66, 0x14, 0x17, 0x40, 0x02}
 	encodedID := []byte{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9a, 0x12, 0xd7, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x4a, 0xbc}
 	encodedPID1 := []byte{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9a, 0x12, 0xd7, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x01}
 	encodedPID2 := []byte{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9a, 0x12, 0xd7, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x02}
@@ -146,6 +210,16 @@ func testIndexerTableACLRoles() map[string]indexerTestCase {
 				expected: [][]byte{encodedPID1, encodedPID2},
 			},
 		},
+		indexTemplates: {
+			read: indexValue{
+				source:   Query{Value: templateID1},
+				expected: encodedTID1,
+			},
+			writeMulti: indexValueMulti{
+				source:   obj,
+				expected: [][]byte{encodedTID1, encodedTID2},
+			},
+		},
 	}
 }
 
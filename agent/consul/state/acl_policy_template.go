@@ -0,0 +1,293 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package state
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-memdb"
+
+	"github.com/hernad/consul/acl"
+	"github.com/hernad/consul/agent/structs"
+)
+
+// ACLPolicyTemplates is used when saving a snapshot
+func (s *Snapshot) ACLPolicyTemplates() (memdb.ResultIterator, error) {
+	return s.tx.Get(tableACLPolicyTemplates, indexID)
+}
+
+func (s *Restore) ACLPolicyTemplate(template *structs.ACLPolicyTemplate) error {
+	return aclPolicyTemplateInsert(s.tx, template)
+}
+
+func (s *Store) ACLPolicyTemplateBatchSet(idx uint64, templates structs.ACLPolicyTemplates) error {
+	tx := s.db.WriteTxn(idx)
+	defer tx.Abort()
+
+	for _, template := range templates {
+		if err := aclPolicyTemplateSetTxn(tx, idx, template); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *Store) ACLPolicyTemplateSet(idx uint64, template *structs.ACLPolicyTemplate) error {
+	tx := s.db.WriteTxn(idx)
+	defer tx.Abort()
+
+	if err := aclPolicyTemplateSetTxn(tx, idx, template); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func aclPolicyTemplateSetTxn(tx WriteTxn, idx uint64, template *structs.ACLPolicyTemplate) error {
+	// Check that the ID is set
+	if template.ID == "" {
+		return ErrMissingACLPolicyTemplateID
+	}
+
+	if template.Name == "" {
+		return ErrMissingACLPolicyTemplateName
+	}
+
+	_, existingRaw, err := aclPolicyTemplateGetByID(tx, template.ID, nil)
+	if err != nil {
+		return err
+	}
+
+	// ensure the name is unique (cannot conflict with another template with a different ID)
+	q := Query{Value: template.Name, EnterpriseMeta: template.EnterpriseMeta}
+	nameMatch, err := tx.First(tableACLPolicyTemplates, indexName, q)
+	if err != nil {
+		return err
+	}
+	if nameMatch != nil && template.ID != nameMatch.(*structs.ACLPolicyTemplate).ID {
+		return fmt.Errorf("A policy template with name %q already exists", template.Name)
+	}
+
+	// Set the indexes
+	if existingRaw != nil {
+		template.CreateIndex = existingRaw.(*structs.ACLPolicyTemplate).CreateIndex
+		template.ModifyIndex = idx
+	} else {
+		template.CreateIndex = idx
+		template.ModifyIndex = idx
+	}
+
+	return aclPolicyTemplateInsert(tx, template)
+}
+
+func (s *Store) ACLPolicyTemplateGetByID(ws memdb.WatchSet, id string, entMeta *acl.EnterpriseMeta) (uint64, *structs.ACLPolicyTemplate, error) {
+	return s.aclPolicyTemplateGet(ws, id, aclPolicyTemplateGetByID, entMeta)
+}
+
+func (s *Store) ACLPolicyTemplateGetByName(ws memdb.WatchSet, name string, entMeta *acl.EnterpriseMeta) (uint64, *structs.ACLPolicyTemplate, error) {
+	return s.aclPolicyTemplateGet(ws, name, aclPolicyTemplateGetByName, entMeta)
+}
+
+func aclPolicyTemplateGetByID(tx ReadTxn, id string, _ *acl.EnterpriseMeta) (<-chan struct{}, interface{}, error) {
+	return tx.FirstWatch(tableACLPolicyTemplates, indexID, id)
+}
+
+func aclPolicyTemplateGetByName(tx ReadTxn, name string, entMeta *acl.EnterpriseMeta) (<-chan struct{}, interface{}, error) {
+	if entMeta == nil {
+		entMeta = structs.DefaultEnterpriseMetaInDefaultPartition()
+	}
+	q := Query{Value: name, EnterpriseMeta: *entMeta}
+	return tx.FirstWatch(tableACLPolicyTemplates, indexName, q)
+}
+
+func (s *Store) ACLPolicyTemplateBatchGet(ws memdb.WatchSet, ids []string) (uint64, structs.ACLPolicyTemplates, error) {
+	tx := s.db.Txn(false)
+	defer tx.Abort()
+
+	templates := make(structs.ACLPolicyTemplates, 0)
+	for _, id := range ids {
+		template, err := getPolicyTemplateWithTxn(tx, ws, id, aclPolicyTemplateGetByID, nil)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		if template != nil {
+			templates = append(templates, template)
+		}
+	}
+
+	idx := maxIndexTxn(tx, tableACLPolicyTemplates)
+
+	return idx, templates, nil
+}
+
+type aclPolicyTemplateGetFn func(ReadTxn, string, *acl.EnterpriseMeta) (<-chan struct{}, interface{}, error)
+
+func getPolicyTemplateWithTxn(tx ReadTxn, ws memdb.WatchSet, value string, fn aclPolicyTemplateGetFn, entMeta *acl.EnterpriseMeta) (*structs.ACLPolicyTemplate, error) {
+	watchCh, template, err := fn(tx, value, entMeta)
+	if err != nil {
+		return nil, fmt.Errorf("failed acl policy template lookup: %v", err)
+	}
+	ws.Add(watchCh)
+
+	if template == nil {
+		return nil, nil
+	}
+
+	return template.(*structs.ACLPolicyTemplate), nil
+}
+
+func (s *Store) aclPolicyTemplateGet(ws memdb.WatchSet, value string, fn aclPolicyTemplateGetFn, entMeta *acl.EnterpriseMeta) (uint64, *structs.ACLPolicyTemplate, error) {
+	tx := s.db.Txn(false)
+	defer tx.Abort()
+
+	template, err := getPolicyTemplateWithTxn(tx, ws, value, fn, entMeta)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return maxIndexTxn(tx, tableACLPolicyTemplates), template, nil
+}
+
+func (s *Store) ACLPolicyTemplateList(ws memdb.WatchSet, entMeta *acl.EnterpriseMeta) (uint64, structs.ACLPolicyTemplates, error) {
+	tx := s.db.Txn(false)
+	defer tx.Abort()
+
+	iter, err := tx.Get(tableACLPolicyTemplates, indexName+"_prefix", entMeta)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed acl policy template lookup: %v", err)
+	}
+	ws.Add(iter.WatchCh())
+
+	var result structs.ACLPolicyTemplates
+	for template := iter.Next(); template != nil; template = iter.Next() {
+		result = append(result, template.(*structs.ACLPolicyTemplate))
+	}
+
+	return maxIndexTxn(tx, tableACLPolicyTemplates), result, nil
+}
+
+func (s *Store) ACLPolicyTemplateDeleteByID(idx uint64, id string, entMeta *acl.EnterpriseMeta) error {
+	return s.ACLPolicyTemplateBatchDelete(idx, []string{id})
+}
+
+func (s *Store) ACLPolicyTemplateBatchDelete(idx uint64, ids []string) error {
+	tx := s.db.WriteTxn(idx)
+	defer tx.Abort()
+
+	for _, id := range ids {
+		_, rawTemplate, err := aclPolicyTemplateGetByID(tx, id, nil)
+		if err != nil {
+			return fmt.Errorf("failed acl policy template lookup: %v", err)
+		}
+		if rawTemplate == nil {
+			continue
+		}
+
+		if err := tx.Delete(tableACLPolicyTemplates, rawTemplate); err != nil {
+			return fmt.Errorf("failed deleting acl policy template: %v", err)
+		}
+		if err := indexUpdateMaxTxn(tx, idx, tableACLPolicyTemplates); err != nil {
+			return fmt.Errorf("failed updating acl policy templates index: %v", err)
+		}
+	}
+	return tx.Commit()
+}
+
+func aclPolicyTemplateInsert(tx WriteTxn, template *structs.ACLPolicyTemplate) error {
+	if err := tx.Insert(tableACLPolicyTemplates, template); err != nil {
+		return fmt.Errorf("failed inserting acl policy template: %v", err)
+	}
+	return updateTableIndexEntries(tx, tableACLPolicyTemplates, template.ModifyIndex, &template.EnterpriseMeta)
+}
+
+// resolveTemplatedPolicyLinks checks that the linked templates exist and sets
+// their names, returning the number of valid links.
+func resolveTemplatedPolicyLinks(tx ReadTxn, links structs.ACLTemplatedPolicies, entMeta *acl.EnterpriseMeta, allowMissing bool) (int, error) {
+	var numValid int
+	for _, link := range links {
+		if link.TemplateID == "" {
+			return 0, fmt.Errorf("Encountered a templated policy linked by Name in the state store")
+		}
+
+		template, err := getPolicyTemplateWithTxn(tx, nil, link.TemplateID, aclPolicyTemplateGetByID, entMeta)
+		if err != nil {
+			return 0, err
+		}
+
+		if template != nil {
+			// the name doesn't matter here
+			link.TemplateName = template.Name
+			numValid++
+		} else if !allowMissing {
+			return 0, fmt.Errorf("No such policy template with ID: %s", link.TemplateID)
+		}
+	}
+	return numValid, nil
+}
+
+// fixupTemplatedPolicyLinks is to be used when retrieving tokens and roles
+// from memdb. The template links could have gotten stale when a linked
+// template was deleted or renamed. This returns the corrected links and
+// whether they differ from the original ones, which are never modified.
+//
+// Templates are not replicated, so when none was ever written locally, as in
+// secondary datacenters, the links of replicated tokens and roles are kept.
+func fixupTemplatedPolicyLinks(tx ReadTxn, original structs.ACLTemplatedPolicies, entMeta *acl.EnterpriseMeta) (structs.ACLTemplatedPolicies, bool, error) {
+	if len(original) == 0 || maxIndexTxn(tx, tableACLPolicyTemplates) == 0 {
+		return original, false, nil
+	}
+
+	owned := false
+	links := original
+
+	for linkIndex, link := range original {
+		if link.TemplateID == "" {
+			return nil, false, fmt.Errorf("Detected corrupted templated policy within the state store - missing template ID")
+		}
+
+		template, err := getPolicyTemplateWithTxn(tx, nil, link.TemplateID, aclPolicyTemplateGetByID, entMeta)
+		if err != nil {
+			return nil, false, err
+		}
+
+		if template == nil || template.Name != link.TemplateName {
+			if !owned {
+				links = make(structs.ACLTemplatedPolicies, linkIndex, len(original))
+				copy(links, original[:linkIndex])
+				owned = true
+			}
+			if template != nil {
+				fixed := link.Clone()
+				fixed.TemplateName = template.Name
+				links = append(links, fixed)
+			}
+		} else if owned {
+			links = append(links, link)
+		}
+	}
+
+	return links, owned, nil
+}
+
+func fixupTokenTemplateLinks(tx ReadTxn, original *structs.ACLToken) (*structs.ACLToken, error) {
+	links, changed, err := fixupTemplatedPolicyLinks(tx, original.TemplatedPolicies, &original.EnterpriseMeta)
+	if err != nil || !changed {
+		return original, err
+	}
+	token := *original
+	token.TemplatedPolicies = links
+	return &token, nil
+}
+
+func fixupRoleTemplateLinks(tx ReadTxn, original *structs.ACLRole) (*structs.ACLRole, error) {
+	links, changed, err := fixupTemplatedPolicyLinks(tx, original.TemplatedPolicies, &original.EnterpriseMeta)
+	if err != nil || !changed {
+		return original, err
+	}
+	role := *original
+	role.TemplatedPolicies = links
+	return &role, nil
+}
@@ -0,0 +1,181 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hernad/consul/agent/structs"
+)
+
+const (
+	testPolicyTemplateID_A = "5b3b1a30-3a2b-4a0b-9a44-1e0b1ad2b6a1"
+	testPolicyTemplateID_B = "8c3f0d2e-5a47-4c3e-8f8b-0a6c1e2d9b7f"
+)
+
+func setupExtraPolicyTemplates(t *testing.T, s *Store) {
+	templates := structs.ACLPolicyTemplates{
+		&structs.ACLPolicyTemplate{
+			ID:    testPolicyTemplateID_A,
+			Name:  "kv-owner",
+			Rules: `key_prefix "${app}/" { policy = "write" }`,
+		},
+		&structs.ACLPolicyTemplate{
+			ID:    testPolicyTemplateID_B,
+			Name:  "service-reader",
+			Rules: `service "${app}" { policy = "read" }`,
+		},
+	}
+
+	for _, template := range templates {
+		template.SetHash(true)
+	}
+
+	require.NoError(t, s.ACLPolicyTemplateBatchSet(2, templates))
+}
+
+func TestStateStore_ACLPolicyTemplate_SetGetDelete(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Missing ID", func(t *testing.T) {
+		t.Parallel()
+		s := testACLStateStore(t)
+
+		template := &structs.ACLPolicyTemplate{Name: "kv-owner"}
+		require.Equal(t, ErrMissingACLPolicyTemplateID, s.ACLPolicyTemplateSet(3, template))
+	})
+
+	t.Run("Missing Name", func(t *testing.T) {
+		t.Parallel()
+		s := testACLStateStore(t)
+
+		template := &structs.ACLPolicyTemplate{ID: testPolicyTemplateID_A}
+		require.Equal(t, ErrMissingACLPolicyTemplateName, s.ACLPolicyTemplateSet(3, template))
+	})
+
+	t.Run("Duplicate Name", func(t *testing.T) {
+		t.Parallel()
+		s := testACLStateStore(t)
+		setupExtraPolicyTemplates(t, s)
+
+		template := &structs.ACLPolicyTemplate{
+			ID:   testPolicyTemplateID_B,
+			Name: "kv-owner",
+		}
+		require.Error(t, s.ACLPolicyTemplateSet(3, template))
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		t.Parallel()
+		s := testACLStateStore(t)
+		setupExtraPolicyTemplates(t, s)
+
+		update := &structs.ACLPolicyTemplate{
+			ID:    testPolicyTemplateID_A,
+			Name:  "kv-writer",
+			Rules: `key_prefix "${app}/" { policy = "write" }`,
+		}
+		require.NoError(t, s.ACLPolicyTemplateSet(3, update))
+
+		idx, rtemplate, err := s.ACLPolicyTemplateGetByName(nil, "kv-writer", nil)
+		require.NoError(t, err)
+		require.Equal(t, uint64(3), idx)
+		require.Equal(t, testPolicyTemplateID_A, rtemplate.ID)
+		require.Equal(t, uint64(2), rtemplate.CreateIndex)
+		require.Equal(t, uint64(3), rtemplate.ModifyIndex)
+
+		_, rtemplate, err = s.ACLPolicyTemplateGetByName(nil, "kv-owner", nil)
+		require.NoError(t, err)
+		require.Nil(t, rtemplate)
+
+		_, templates, err := s.ACLPolicyTemplateList(nil, nil)
+		require.NoError(t, err)
+		require.Len(t, templates, 2)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		t.Parallel()
+		s := testACLStateStore(t)
+		setupExtraPolicyTemplates(t, s)
+
+		require.NoError(t, s.ACLPolicyTemplateDeleteByID(3, testPolicyTemplateID_A, nil))
+
+		idx, rtemplate, err := s.ACLPolicyTemplateGetByID(nil, testPolicyTemplateID_A, nil)
+		require.NoError(t, err)
+		require.Equal(t, uint64(3), idx)
+		require.Nil(t, rtemplate)
+
+		_, templates, err := s.ACLPolicyTemplateBatchGet(nil, []string{testPolicyTemplateID_A, testPolicyTemplateID_B})
+		require.NoError(t, err)
+		require.Len(t, templates, 1)
+		require.Equal(t, testPolicyTemplateID_B, templates[0].ID)
+	})
+}
+
+func TestStateStore_ACLToken_FixupTemplatedPolicyLinks(t *testing.T) {
+	t.Parallel()
+
+	s := testACLTokensStateStore(t)
+	setupExtraPolicyTemplates(t, s)
+
+	token := &structs.ACLToken{
+		AccessorID: "47eea4da-bda1-48a6-901c-3e36d2d9262f",
+		SecretID:   "689b5f4e-4d23-4ae0-a7d1-2b0c9c3f4a5e",
+		TemplatedPolicies: structs.ACLTemplatedPolicies{
+			{TemplateID: testPolicyTemplateID_A, Variables: map[string]string{"app": "web"}},
+			{TemplateID: testPolicyTemplateID_B, Variables: map[string]string{"app": "web"}},
+		},
+	}
+	require.NoError(t, s.ACLTokenSet(3, token))
+
+	_, rtoken, err := s.ACLTokenGetByAccessor(nil, token.AccessorID, nil)
+	require.NoError(t, err)
+	require.Len(t, rtoken.TemplatedPolicies, 2)
+	require.Equal(t, "kv-owner", rtoken.TemplatedPolicies[0].TemplateName)
+	require.Equal(t, "service-reader", rtoken.TemplatedPolicies[1].TemplateName)
+
+	t.Run("Linking a missing template", func(t *testing.T) {
+		missing := &structs.ACLToken{
+			AccessorID: "9a2d5c1e-7b3f-4e8a-a1c6-0d4f2b8e6c3a",
+			SecretID:   "1e6b9f0c-2d7a-4c5e-b3f8-6a0d9c2e4b1f",
+			TemplatedPolicies: structs.ACLTemplatedPolicies{
+				{TemplateID: "0b8e3f4a-6c1d-4a9e-8b2f-5d7c0e1a3f6b"},
+			},
+		}
+		require.Error(t, s.ACLTokenSet(4, missing))
+	})
+
+	t.Run("Renaming a template", func(t *testing.T) {
+		renamed := &structs.ACLPolicyTemplate{
+			ID:    testPolicyTemplateID_A,
+			Name:  "kv-writer",
+			Rules: `key_prefix "${app}/" { policy = "write" }`,
+		}
+		require.NoError(t, s.ACLPolicyTemplateSet(5, renamed))
+
+		_, rtoken, err := s.ACLTokenGetByAccessor(nil, token.AccessorID, nil)
+		require.NoError(t, err)
+		require.Len(t, rtoken.TemplatedPolicies, 2)
+		require.Equal(t, "kv-writer", rtoken.TemplatedPolicies[0].TemplateName)
+	})
+
+	t.Run("Deleting a template", func(t *testing.T) {
+		require.NoError(t, s.ACLPolicyTemplateDeleteByID(6, testPolicyTemplateID_B, nil))
+
+		_, rtoken, err := s.ACLTokenGetByAccessor(nil, token.AccessorID, nil)
+		require.NoError(t, err)
+		require.Len(t, rtoken.TemplatedPolicies, 1)
+		require.Equal(t, testPolicyTemplateID_A, rtoken.TemplatedPolicies[0].TemplateID)
+
+		_, tokens, err := s.ACLTokenList(nil, true, true, "", "", "", nil, nil)
+		require.NoError(t, err)
+		for _, listed := range tokens {
+			if listed.AccessorID == token.AccessorID {
+				require.Len(t, listed.TemplatedPolicies, 1)
+			}
+		}
+	})
+}
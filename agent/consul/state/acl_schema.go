@@ -19,14 +19,19 @@ const (
 	tableACLBindingRules = "acl-binding-rules"
 	tableACLAuthMethods  = "acl-auth-methods"
 
+	tableACLPolicyTemplates = "acl-policy-templates"
+	tableACLTokenUsages     = "acl-token-usages"
+
 	indexAccessor      = "accessor"
 	indexPolicies      = "policies"
 	indexRoles         = "roles"
+	indexTemplates     = "templates"
 	indexAuthMethod    = "authmethod"
 	indexLocality      = "locality"
 	indexName          = "name"
 	indexExpiresGlobal = "expires-global"
 	indexExpiresLocal  = "expires-local"
+	indexParent        = "parent"
 )
 
 func tokensTableSchema() *memdb.TableSchema {
@@ -70,6 +75,15 @@ func tokensTableSchema() *memdb.TableSchema {
 					writeIndexMulti: indexRolesFromACLToken,
 				},
 			},
+			indexTemplates: {
+				Name:         indexTemplates,
+				AllowMissing: true,
+				Unique:       false,
+				Indexer: indexerMulti[Query, *structs.ACLToken]{
+					readIndex:       indexFromUUIDQuery,
+					writeIndexMulti: indexTemplatesFromACLToken,
+				},
+			},
 			indexAuthMethod: {
 				Name:         indexAuthMethod,
 				AllowMissing: true,
@@ -106,6 +120,15 @@ func tokensTableSchema() *memdb.TableSchema {
 					writeIndex: indexExpiresLocalFromACLToken,
 				},
 			},
+			indexParent: {
+				Name:         indexParent,
+				AllowMissing: true,
+				Unique:       false,
+				Indexer: indexerSingle[string, *structs.ACLToken]{
+					readIndex:  indexFromUUIDString,
+					writeIndex: indexParentFromACLToken,
+				},
+			},
 		},
 	}
 }
@@ -146,6 +169,89 @@ func indexNameFromACLPolicy(p *structs.ACLPolicy) ([]byte, error) {
 	return b.Bytes(), nil
 }
 
+func tokenUsagesTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: tableACLTokenUsages,
+		Indexes: map[string]*memdb.IndexSchema{
+			indexID: {
+				Name:         indexID,
+				AllowMissing: false,
+				Unique:       true,
+				Indexer: &memdb.UUIDFieldIndex{
+					Field: "AccessorID",
+				},
+			},
+		},
+	}
+}
+
+func policyTemplatesTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: tableACLPolicyTemplates,
+		Indexes: map[string]*memdb.IndexSchema{
+			indexID: {
+				Name:         indexID,
+				AllowMissing: false,
+				Unique:       true,
+				Indexer: &memdb.UUIDFieldIndex{
+					Field: "ID",
+				},
+			},
+			indexName: {
+				Name:         indexName,
+				AllowMissing: false,
+				Unique:       true,
+				Indexer: indexerSingleWithPrefix[Query, *structs.ACLPolicyTemplate, any]{
+					readIndex:   indexFromQuery,
+					writeIndex:  indexNameFromACLPolicyTemplate,
+					prefixIndex: prefixIndexFromQuery,
+				},
+			},
+		},
+	}
+}
+
+func indexNameFromACLPolicyTemplate(t *structs.ACLPolicyTemplate) ([]byte, error) {
+	if t.Name == "" {
+		return nil, errMissingValueForIndex
+	}
+
+	var b indexBuilder
+	b.String(strings.ToLower(t.Name))
+	return b.Bytes(), nil
+}
+
+// multiIndexTemplates returns the index values of the templates of the links.
+// A template may be linked several times with different variables.
+func multiIndexTemplates(links structs.ACLTemplatedPolicies) ([][]byte, error) {
+	if len(links) == 0 {
+		return nil, errMissingValueForIndex
+	}
+
+	vals := make([][]byte, 0, len(links))
+	seen := make(map[string]struct{}, len(links))
+	for _, link := range links {
+		if _, ok := seen[link.TemplateID]; ok {
+			continue
+		}
+		seen[link.TemplateID] = struct{}{}
+		v, err := uuidStringToBytes(link.TemplateID)
+		if err != nil {
+			return nil, err
+		}
+		vals = append(vals, v)
+	}
+	return vals, nil
+}
+
+func indexTemplatesFromACLToken(token *structs.ACLToken) ([][]byte, error) {
+	return multiIndexTemplates(token.TemplatedPolicies)
+}
+
+func multiIndexTemplateFromACLRole(r *structs.ACLRole) ([][]byte, error) {
+	return multiIndexTemplates(r.TemplatedPolicies)
+}
+
 func rolesTableSchema() *memdb.TableSchema {
 	return &memdb.TableSchema{
 		Name: tableACLRoles,
@@ -178,6 +284,15 @@ func rolesTableSchema() *memdb.TableSchema {
 					writeIndexMulti: multiIndexPolicyFromACLRole,
 				},
 			},
+			indexTemplates: {
+				Name:         indexTemplates,
+				AllowMissing: true,
+				Unique:       false,
+				Indexer: indexerMulti[Query, *structs.ACLRole]{
+					readIndex:       indexFromUUIDQuery,
+					writeIndexMulti: multiIndexTemplateFromACLRole,
+				},
+			},
 		},
 	}
 }
@@ -298,6 +413,20 @@ func indexAccessorIDFromACLToken(t *structs.ACLToken) ([]byte, error) {
 	return b.Bytes(), nil
 }
 
+func indexParentFromACLToken(t *structs.ACLToken) ([]byte, error) {
+	if t.ParentAccessorID == "" {
+		return nil, errMissingValueForIndex
+	}
+
+	uuid, err := uuidStringToBytes(t.ParentAccessorID)
+	if err != nil {
+		return nil, err
+	}
+	var b indexBuilder
+	b.Raw(uuid)
+	return b.Bytes(), nil
+}
+
 func indexSecretIDFromACLToken(t *structs.ACLToken) ([]byte, error) {
 	if t.SecretID == "" {
 		return nil, errMissingValueForIndex
@@ -1316,6 +1316,73 @@ func TestStateStore_ACLToken_Delete(t *testing.T) {
 		require.Nil(t, rtoken)
 	})
 
+	t.Run("Children", func(t *testing.T) {
+		t.Parallel()
+		s := testACLTokensStateStore(t)
+
+		parent := &structs.ACLToken{
+			AccessorID: "f1093997-b6c7-496d-bfb8-6b1b1895641b",
+			SecretID:   "34ec8eb3-095d-417a-a937-b439af7a8e8b",
+			Policies: []structs.ACLTokenPolicyLink{
+				{
+					ID: structs.ACLPolicyGlobalManagementID,
+				},
+			},
+		}
+		child := &structs.ACLToken{
+			AccessorID:       "a0bfe8d4-b2f3-4b48-b387-f28afb820eab",
+			SecretID:         "ec8ab5e0-8a3e-4b2a-9d1b-4c0e6f6f0f1d",
+			ParentAccessorID: parent.AccessorID,
+			Policies: []structs.ACLTokenPolicyLink{
+				{
+					ID: structs.ACLPolicyGlobalManagementID,
+				},
+			},
+		}
+		grandchild := &structs.ACLToken{
+			AccessorID:       "3b8c5b7e-0f1d-4e59-8d5e-0b8b0b7c7e21",
+			SecretID:         "9c4f6c1e-3a1b-4f7e-9d59-7c1e2b0d7f44",
+			ParentAccessorID: child.AccessorID,
+			Policies: []structs.ACLTokenPolicyLink{
+				{
+					ID: structs.ACLPolicyGlobalManagementID,
+				},
+			},
+		}
+		unrelated := &structs.ACLToken{
+			AccessorID: "77b7a1d4-39b8-4b7e-a4e5-1e3e0a5c6f90",
+			SecretID:   "2d4f5e6a-8b9c-4d0e-9f1a-2b3c4d5e6f70",
+			Policies: []structs.ACLTokenPolicyLink{
+				{
+					ID: structs.ACLPolicyGlobalManagementID,
+				},
+			},
+		}
+
+		require.NoError(t, s.ACLTokenBatchSet(2, structs.ACLTokens{parent.Clone(), unrelated.Clone()}, ACLTokenSetOptions{}))
+		require.NoError(t, s.ACLTokenSet(3, child.Clone()))
+		require.NoError(t, s.ACLTokenSet(4, grandchild.Clone()))
+
+		// The parent of a child token must exist.
+		orphan := grandchild.Clone()
+		orphan.AccessorID = "c3a1e2d4-5f6a-4b7c-8d9e-0f1a2b3c4d5e"
+		orphan.SecretID = "d4e5f6a7-b8c9-4d0e-8f1a-2b3c4d5e6f7a"
+		orphan.ParentAccessorID = "e5f6a7b8-c9d0-4e1f-8a2b-3c4d5e6f7a8b"
+		require.Error(t, s.ACLTokenSet(5, orphan))
+
+		require.NoError(t, s.ACLTokenDeleteByAccessor(6, parent.AccessorID, nil))
+
+		for _, token := range []*structs.ACLToken{parent, child, grandchild} {
+			_, rtoken, err := s.ACLTokenGetByAccessor(nil, token.AccessorID, nil)
+			require.NoError(t, err)
+			require.Nil(t, rtoken)
+		}
+
+		_, rtoken, err := s.ACLTokenGetByAccessor(nil, unrelated.AccessorID, nil)
+		require.NoError(t, err)
+		require.NotNil(t, rtoken)
+	})
+
 	t.Run("Multiple", func(t *testing.T) {
 		t.Parallel()
 		s := testACLTokensStateStore(t)
@@ -0,0 +1,147 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package state
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-memdb"
+
+	"github.com/hernad/consul/agent/structs"
+)
+
+// ACLTokenUsages is used when saving a snapshot
+func (s *Snapshot) ACLTokenUsages() (memdb.ResultIterator, error) {
+	return s.tx.Get(tableACLTokenUsages, indexID)
+}
+
+func (s *Restore) ACLTokenUsage(usage *structs.ACLTokenUsage) error {
+	if err := s.tx.Insert(tableACLTokenUsages, usage); err != nil {
+		return fmt.Errorf("failed restoring acl token usage: %s", err)
+	}
+	if err := indexUpdateMaxTxn(s.tx, usage.ModifyIndex, tableACLTokenUsages); err != nil {
+		return fmt.Errorf("failed updating index: %s", err)
+	}
+	return nil
+}
+
+// ACLTokenUsageBatchUpdate records when the tokens were last used. Usages of
+// tokens that do not exist are ignored, and the recorded times only ever move
+// forward.
+func (s *Store) ACLTokenUsageBatchUpdate(idx uint64, usages structs.ACLTokenUsages) error {
+	tx := s.db.WriteTxn(idx)
+	defer tx.Abort()
+
+	for _, usage := range usages {
+		if err := aclTokenUsageUpdateTxn(tx, idx, usage); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func aclTokenUsageUpdateTxn(tx WriteTxn, idx uint64, usage *structs.ACLTokenUsage) error {
+	if usage.AccessorID == "" {
+		return ErrMissingACLTokenAccessor
+	}
+
+	_, token, err := aclTokenGetFromIndex(tx, usage.AccessorID, indexAccessor, nil)
+	if err != nil {
+		return fmt.Errorf("failed acl token lookup: %v", err)
+	}
+	if token == nil {
+		return nil
+	}
+
+	existing, err := tx.First(tableACLTokenUsages, indexID, usage.AccessorID)
+	if err != nil {
+		return fmt.Errorf("failed acl token usage lookup: %v", err)
+	}
+
+	updated := &structs.ACLTokenUsage{
+		AccessorID: usage.AccessorID,
+		LastUsed:   usage.LastUsed,
+		RaftIndex: structs.RaftIndex{
+			CreateIndex: idx,
+			ModifyIndex: idx,
+		},
+	}
+	if existing != nil {
+		prev := existing.(*structs.ACLTokenUsage)
+		if !usage.LastUsed.After(prev.LastUsed) {
+			return nil
+		}
+		updated.CreateIndex = prev.CreateIndex
+	}
+
+	if err := tx.Insert(tableACLTokenUsages, updated); err != nil {
+		return fmt.Errorf("failed inserting acl token usage: %v", err)
+	}
+	if err := indexUpdateMaxTxn(tx, idx, tableACLTokenUsages); err != nil {
+		return fmt.Errorf("failed updating acl token usages index: %v", err)
+	}
+	return nil
+}
+
+// ACLTokenUsageGet returns when the token with the given accessor was last
+// used, or nil when it was never recorded.
+func (s *Store) ACLTokenUsageGet(ws memdb.WatchSet, accessorID string) (*time.Time, error) {
+	tx := s.db.Txn(false)
+	defer tx.Abort()
+
+	return aclTokenUsageGetTxn(tx, ws, accessorID)
+}
+
+func aclTokenUsageGetTxn(tx ReadTxn, ws memdb.WatchSet, accessorID string) (*time.Time, error) {
+	watchCh, raw, err := tx.FirstWatch(tableACLTokenUsages, indexID, accessorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed acl token usage lookup: %v", err)
+	}
+	ws.Add(watchCh)
+
+	if raw == nil {
+		return nil, nil
+	}
+	lastUsed := raw.(*structs.ACLTokenUsage).LastUsed
+	return &lastUsed, nil
+}
+
+// ACLTokenUsageList returns when the tokens were last used, keyed by accessor.
+func (s *Store) ACLTokenUsageList(ws memdb.WatchSet) (map[string]time.Time, error) {
+	tx := s.db.Txn(false)
+	defer tx.Abort()
+
+	iter, err := tx.Get(tableACLTokenUsages, indexID)
+	if err != nil {
+		return nil, fmt.Errorf("failed acl token usage lookup: %v", err)
+	}
+	ws.Add(iter.WatchCh())
+
+	usages := make(map[string]time.Time)
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		usage := raw.(*structs.ACLTokenUsage)
+		usages[usage.AccessorID] = usage.LastUsed
+	}
+	return usages, nil
+}
+
+func aclTokenUsageDeleteTxn(tx WriteTxn, idx uint64, accessorID string) error {
+	existing, err := tx.First(tableACLTokenUsages, indexID, accessorID)
+	if err != nil {
+		return fmt.Errorf("failed acl token usage lookup: %v", err)
+	}
+	if existing == nil {
+		return nil
+	}
+
+	if err := tx.Delete(tableACLTokenUsages, existing); err != nil {
+		return fmt.Errorf("failed deleting acl token usage: %v", err)
+	}
+	if err := indexUpdateMaxTxn(tx, idx, tableACLTokenUsages); err != nil {
+		return fmt.Errorf("failed updating acl token usages index: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package state
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hernad/consul/agent/structs"
+)
+
+func TestStateStore_ACLTokenUsage_BatchUpdate(t *testing.T) {
+	t.Parallel()
+
+	const (
+		accessorID = "a4f68bd6-3af5-4f56-b764-3c6f20247879"
+		missingID  = "f1e6a2c1-9d53-4d0c-b2a4-6f8cfa0bd1e4"
+	)
+
+	s := testACLTokensStateStore(t)
+	require.NoError(t, s.ACLTokenSet(2, &structs.ACLToken{
+		AccessorID: accessorID,
+		SecretID:   "f9a41f5e-6a35-4d51-8c4c-6d0fdd0c37ab",
+	}))
+
+	lastUsed, err := s.ACLTokenUsageGet(nil, accessorID)
+	require.NoError(t, err)
+	require.Nil(t, lastUsed)
+
+	first := time.Date(2020, 5, 22, 18, 0, 0, 0, time.UTC)
+	require.NoError(t, s.ACLTokenUsageBatchUpdate(3, structs.ACLTokenUsages{
+		{AccessorID: accessorID, LastUsed: first},
+		// usages of unknown tokens are dropped
+		{AccessorID: missingID, LastUsed: first},
+	}))
+
+	lastUsed, err = s.ACLTokenUsageGet(nil, accessorID)
+	require.NoError(t, err)
+	require.NotNil(t, lastUsed)
+	require.True(t, first.Equal(*lastUsed))
+
+	usages, err := s.ACLTokenUsageList(nil)
+	require.NoError(t, err)
+	require.Len(t, usages, 1)
+	require.Contains(t, usages, accessorID)
+
+	t.Run("only moves forward", func(t *testing.T) {
+		require.NoError(t, s.ACLTokenUsageBatchUpdate(4, structs.ACLTokenUsages{
+			{AccessorID: accessorID, LastUsed: first.Add(-time.Hour)},
+		}))
+		lastUsed, err := s.ACLTokenUsageGet(nil, accessorID)
+		require.NoError(t, err)
+		require.True(t, first.Equal(*lastUsed))
+
+		later := first.Add(time.Hour)
+		require.NoError(t, s.ACLTokenUsageBatchUpdate(5, structs.ACLTokenUsages{
+			{AccessorID: accessorID, LastUsed: later},
+		}))
+		lastUsed, err = s.ACLTokenUsageGet(nil, accessorID)
+		require.NoError(t, err)
+		require.True(t, later.Equal(*lastUsed))
+	})
+
+	t.Run("missing accessor", func(t *testing.T) {
+		err := s.ACLTokenUsageBatchUpdate(6, structs.ACLTokenUsages{{LastUsed: first}})
+		require.Equal(t, ErrMissingACLTokenAccessor, err)
+	})
+
+	t.Run("deleted with the token", func(t *testing.T) {
+		require.NoError(t, s.ACLTokenDeleteByAccessor(7, accessorID, nil))
+
+		lastUsed, err := s.ACLTokenUsageGet(nil, accessorID)
+		require.NoError(t, err)
+		require.Nil(t, lastUsed)
+
+		usages, err := s.ACLTokenUsageList(nil)
+		require.NoError(t, err)
+		require.Empty(t, usages)
+	})
+}
@@ -220,7 +220,9 @@ func (s *ServiceIntentionSourceIndex) FromObject(obj interface{}) (bool, [][]byt
 
 	vals := make([][]byte, 0, len(ixnEntry.Sources))
 	for _, src := range ixnEntry.Sources {
-		if src.SamenessGroup != "" {
+		// Sources of federated trust domains are not services of this
+		// cluster even when they share a name with one.
+		if src.SamenessGroup != "" || src.TrustDomain != "" {
 			continue
 		}
 
@@ -440,7 +442,7 @@ func readSourceIntentionsFromConfigEntriesForServiceTxn(
 		}
 
 		for _, src := range entry.Sources {
-			if src.SourceServiceName() == sn {
+			if src.TrustDomain == "" && src.SourceServiceName() == sn {
 				canAdd, err := intentionMatches(targetType, kind, entry.HasWildcardDestination())
 				if err != nil {
 					return nil, err
@@ -18,6 +18,10 @@ const (
 	tableConnectCAConfig        = "connect-ca-config"
 	tableConnectCARoots         = "connect-ca-roots"
 	tableConnectCALeafCerts     = "connect-ca-leaf-certs"
+	tableConnectCARevocations   = "connect-ca-revocations"
+	tableConnectCAFedBundles    = "connect-ca-federated-bundles"
+	tableConnectCAJWTKeys       = "connect-ca-jwt-keys"
+	tableConnectCARevLists      = "connect-ca-revocation-lists"
 )
 
 // caBuiltinProviderTableSchema returns a new table schema used for storing
@@ -77,6 +81,80 @@ func caRootTableSchema() *memdb.TableSchema {
 	}
 }
 
+// caRevocationTableSchema returns a new table schema used for storing
+// revoked leaf certificates and service identities for Connect.
+func caRevocationTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: tableConnectCARevocations,
+		Indexes: map[string]*memdb.IndexSchema{
+			"id": {
+				Name:         "id",
+				AllowMissing: false,
+				Unique:       true,
+				Indexer: &memdb.StringFieldIndex{
+					Field: "ID",
+				},
+			},
+		},
+	}
+}
+
+// caFederatedTrustBundleTableSchema returns a new table schema used for
+// storing the trust bundles fetched from the SPIFFE bundle endpoints of
+// federated trust domains.
+func caFederatedTrustBundleTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: tableConnectCAFedBundles,
+		Indexes: map[string]*memdb.IndexSchema{
+			"id": {
+				Name:         "id",
+				AllowMissing: false,
+				Unique:       true,
+				Indexer: &memdb.StringFieldIndex{
+					Field: "TrustDomain",
+				},
+			},
+		},
+	}
+}
+
+// caJWTSigningKeyTableSchema returns a new table schema used for storing the
+// keys that sign the JWT-SVIDs of the services.
+func caJWTSigningKeyTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: tableConnectCAJWTKeys,
+		Indexes: map[string]*memdb.IndexSchema{
+			"id": {
+				Name:         "id",
+				AllowMissing: false,
+				Unique:       true,
+				Indexer: &memdb.StringFieldIndex{
+					Field: "ID",
+				},
+			},
+		},
+	}
+}
+
+// caRevocationListTableSchema returns a new table schema used for storing the
+// certificate revocation lists signed by the leaf signing keys of every
+// datacenter.
+func caRevocationListTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: tableConnectCARevLists,
+		Indexes: map[string]*memdb.IndexSchema{
+			"id": {
+				Name:         "id",
+				AllowMissing: false,
+				Unique:       true,
+				Indexer: &memdb.StringFieldIndex{
+					Field: "SigningKeyID",
+				},
+			},
+		},
+	}
+}
+
 // CAConfig is used to pull the CA config from the snapshot.
 func (s *Snapshot) CAConfig() (*structs.CAConfiguration, error) {
 	c, err := s.tx.First(tableConnectCAConfig, "id")
@@ -443,6 +521,472 @@ func (s *Store) CADeleteProviderState(idx uint64, id string) error {
 	return tx.Commit()
 }
 
+// CARevocations is used to pull all the CA revocations for the snapshot.
+func (s *Snapshot) CARevocations() ([]*structs.CARevocation, error) {
+	iter, err := s.tx.Get(tableConnectCARevocations, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []*structs.CARevocation
+	for wrapped := iter.Next(); wrapped != nil; wrapped = iter.Next() {
+		ret = append(ret, wrapped.(*structs.CARevocation))
+	}
+
+	return ret, nil
+}
+
+// CARevocation is used when restoring from a snapshot.
+func (s *Restore) CARevocation(r *structs.CARevocation) error {
+	if err := s.tx.Insert(tableConnectCARevocations, r); err != nil {
+		return fmt.Errorf("failed restoring CA revocation: %s", err)
+	}
+	if err := indexUpdateMaxTxn(s.tx, r.ModifyIndex, tableConnectCARevocations); err != nil {
+		return fmt.Errorf("failed updating index: %s", err)
+	}
+
+	return nil
+}
+
+// CARevocations returns the list of all revoked leaf certificates and service
+// identities.
+func (s *Store) CARevocations(ws memdb.WatchSet) (uint64, []*structs.CARevocation, error) {
+	tx := s.db.Txn(false)
+	defer tx.Abort()
+
+	return caRevocationsTxn(tx, ws)
+}
+
+func caRevocationsTxn(tx ReadTxn, ws memdb.WatchSet) (uint64, []*structs.CARevocation, error) {
+	idx := maxIndexTxn(tx, tableConnectCARevocations)
+
+	iter, err := tx.Get(tableConnectCARevocations, "id")
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed CA revocation lookup: %s", err)
+	}
+	ws.Add(iter.WatchCh())
+
+	var results []*structs.CARevocation
+	for v := iter.Next(); v != nil; v = iter.Next() {
+		results = append(results, v.(*structs.CARevocation))
+	}
+	return idx, results, nil
+}
+
+// CASetRevocations creates or updates the given revocations.
+func (s *Store) CASetRevocations(idx uint64, revs []*structs.CARevocation) error {
+	tx := s.db.WriteTxn(idx)
+	defer tx.Abort()
+
+	for _, r := range revs {
+		if r.ID == "" {
+			return ErrMissingCARevocationID
+		}
+		if err := r.Validate(); err != nil {
+			return err
+		}
+
+		existing, err := tx.First(tableConnectCARevocations, "id", r.ID)
+		if err != nil {
+			return fmt.Errorf("failed CA revocation lookup: %s", err)
+		}
+
+		if existing != nil {
+			r.CreateIndex = existing.(*structs.CARevocation).CreateIndex
+		} else {
+			r.CreateIndex = idx
+		}
+		r.ModifyIndex = idx
+
+		if err := tx.Insert(tableConnectCARevocations, r); err != nil {
+			return fmt.Errorf("failed updating CA revocation: %s", err)
+		}
+	}
+
+	if err := tx.Insert(tableIndex, &IndexEntry{tableConnectCARevocations, idx}); err != nil {
+		return fmt.Errorf("failed updating index: %s", err)
+	}
+
+	return tx.Commit()
+}
+
+// CADeleteRevocations deletes the revocations with the given IDs. Unknown IDs
+// are ignored.
+func (s *Store) CADeleteRevocations(idx uint64, ids []string) error {
+	tx := s.db.WriteTxn(idx)
+	defer tx.Abort()
+
+	for _, id := range ids {
+		existing, err := tx.First(tableConnectCARevocations, "id", id)
+		if err != nil {
+			return fmt.Errorf("failed CA revocation lookup: %s", err)
+		}
+		if existing == nil {
+			continue
+		}
+		if err := tx.Delete(tableConnectCARevocations, existing); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Insert(tableIndex, &IndexEntry{tableConnectCARevocations, idx}); err != nil {
+		return fmt.Errorf("failed updating index: %s", err)
+	}
+
+	return tx.Commit()
+}
+
+// CAFederatedTrustBundles is used to pull all the federated trust bundles for
+// the snapshot.
+func (s *Snapshot) CAFederatedTrustBundles() ([]*structs.FederatedTrustBundle, error) {
+	iter, err := s.tx.Get(tableConnectCAFedBundles, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []*structs.FederatedTrustBundle
+	for wrapped := iter.Next(); wrapped != nil; wrapped = iter.Next() {
+		ret = append(ret, wrapped.(*structs.FederatedTrustBundle))
+	}
+
+	return ret, nil
+}
+
+// CAFederatedTrustBundle is used when restoring from a snapshot.
+func (s *Restore) CAFederatedTrustBundle(b *structs.FederatedTrustBundle) error {
+	if err := s.tx.Insert(tableConnectCAFedBundles, b); err != nil {
+		return fmt.Errorf("failed restoring federated trust bundle: %s", err)
+	}
+	if err := indexUpdateMaxTxn(s.tx, b.ModifyIndex, tableConnectCAFedBundles); err != nil {
+		return fmt.Errorf("failed updating index: %s", err)
+	}
+
+	return nil
+}
+
+// CAFederatedTrustBundles returns the trust bundles fetched from the bundle
+// endpoints of the federated trust domains.
+func (s *Store) CAFederatedTrustBundles(ws memdb.WatchSet) (uint64, []*structs.FederatedTrustBundle, error) {
+	tx := s.db.Txn(false)
+	defer tx.Abort()
+
+	return caFederatedTrustBundlesTxn(tx, ws)
+}
+
+func caFederatedTrustBundlesTxn(tx ReadTxn, ws memdb.WatchSet) (uint64, []*structs.FederatedTrustBundle, error) {
+	idx := maxIndexTxn(tx, tableConnectCAFedBundles)
+
+	iter, err := tx.Get(tableConnectCAFedBundles, "id")
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed federated trust bundle lookup: %s", err)
+	}
+	ws.Add(iter.WatchCh())
+
+	var results []*structs.FederatedTrustBundle
+	for v := iter.Next(); v != nil; v = iter.Next() {
+		results = append(results, v.(*structs.FederatedTrustBundle))
+	}
+	return idx, results, nil
+}
+
+// CASetFederatedTrustBundles creates or updates the given trust bundles.
+func (s *Store) CASetFederatedTrustBundles(idx uint64, bundles []*structs.FederatedTrustBundle) error {
+	tx := s.db.WriteTxn(idx)
+	defer tx.Abort()
+
+	for _, b := range bundles {
+		if b.TrustDomain == "" {
+			return ErrMissingFederatedTrustDomain
+		}
+
+		existing, err := tx.First(tableConnectCAFedBundles, "id", b.TrustDomain)
+		if err != nil {
+			return fmt.Errorf("failed federated trust bundle lookup: %s", err)
+		}
+
+		if existing != nil {
+			b.CreateIndex = existing.(*structs.FederatedTrustBundle).CreateIndex
+		} else {
+			b.CreateIndex = idx
+		}
+		b.ModifyIndex = idx
+
+		if err := tx.Insert(tableConnectCAFedBundles, b); err != nil {
+			return fmt.Errorf("failed updating federated trust bundle: %s", err)
+		}
+	}
+
+	if err := tx.Insert(tableIndex, &IndexEntry{tableConnectCAFedBundles, idx}); err != nil {
+		return fmt.Errorf("failed updating index: %s", err)
+	}
+
+	return tx.Commit()
+}
+
+// CADeleteFederatedTrustBundles deletes the trust bundles of the given trust
+// domains. Unknown trust domains are ignored.
+func (s *Store) CADeleteFederatedTrustBundles(idx uint64, trustDomains []string) error {
+	tx := s.db.WriteTxn(idx)
+	defer tx.Abort()
+
+	for _, name := range trustDomains {
+		existing, err := tx.First(tableConnectCAFedBundles, "id", name)
+		if err != nil {
+			return fmt.Errorf("failed federated trust bundle lookup: %s", err)
+		}
+		if existing == nil {
+			continue
+		}
+		if err := tx.Delete(tableConnectCAFedBundles, existing); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Insert(tableIndex, &IndexEntry{tableConnectCAFedBundles, idx}); err != nil {
+		return fmt.Errorf("failed updating index: %s", err)
+	}
+
+	return tx.Commit()
+}
+
+// CAJWTSigningKeys is used to pull all the JWT-SVID signing keys for the
+// snapshot.
+func (s *Snapshot) CAJWTSigningKeys() ([]*structs.CAJWTSigningKey, error) {
+	iter, err := s.tx.Get(tableConnectCAJWTKeys, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []*structs.CAJWTSigningKey
+	for wrapped := iter.Next(); wrapped != nil; wrapped = iter.Next() {
+		ret = append(ret, wrapped.(*structs.CAJWTSigningKey))
+	}
+
+	return ret, nil
+}
+
+// CAJWTSigningKey is used when restoring from a snapshot.
+func (s *Restore) CAJWTSigningKey(k *structs.CAJWTSigningKey) error {
+	if err := s.tx.Insert(tableConnectCAJWTKeys, k); err != nil {
+		return fmt.Errorf("failed restoring JWT signing key: %s", err)
+	}
+	if err := indexUpdateMaxTxn(s.tx, k.ModifyIndex, tableConnectCAJWTKeys); err != nil {
+		return fmt.Errorf("failed updating index: %s", err)
+	}
+
+	return nil
+}
+
+// CAJWTSigningKeys returns the keys used to sign the JWT-SVIDs of the
+// services.
+func (s *Store) CAJWTSigningKeys(ws memdb.WatchSet) (uint64, []*structs.CAJWTSigningKey, error) {
+	tx := s.db.Txn(false)
+	defer tx.Abort()
+
+	return caJWTSigningKeysTxn(tx, ws)
+}
+
+func caJWTSigningKeysTxn(tx ReadTxn, ws memdb.WatchSet) (uint64, []*structs.CAJWTSigningKey, error) {
+	idx := maxIndexTxn(tx, tableConnectCAJWTKeys)
+
+	iter, err := tx.Get(tableConnectCAJWTKeys, "id")
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed JWT signing key lookup: %s", err)
+	}
+	ws.Add(iter.WatchCh())
+
+	var results []*structs.CAJWTSigningKey
+	for v := iter.Next(); v != nil; v = iter.Next() {
+		results = append(results, v.(*structs.CAJWTSigningKey))
+	}
+	return idx, results, nil
+}
+
+// CASetJWTSigningKeys creates or updates the given JWT-SVID signing keys. At
+// most one key may be active once they are stored.
+func (s *Store) CASetJWTSigningKeys(idx uint64, keys []*structs.CAJWTSigningKey) error {
+	tx := s.db.WriteTxn(idx)
+	defer tx.Abort()
+
+	for _, k := range keys {
+		if k.ID == "" {
+			return ErrMissingCAJWTSigningKeyID
+		}
+
+		existing, err := tx.First(tableConnectCAJWTKeys, "id", k.ID)
+		if err != nil {
+			return fmt.Errorf("failed JWT signing key lookup: %s", err)
+		}
+
+		if existing != nil {
+			k.CreateIndex = existing.(*structs.CAJWTSigningKey).CreateIndex
+		} else {
+			k.CreateIndex = idx
+		}
+		k.ModifyIndex = idx
+
+		if err := tx.Insert(tableConnectCAJWTKeys, k); err != nil {
+			return fmt.Errorf("failed updating JWT signing key: %s", err)
+		}
+	}
+
+	_, all, err := caJWTSigningKeysTxn(tx, nil)
+	if err != nil {
+		return err
+	}
+	active := 0
+	for _, k := range all {
+		if k.Active {
+			active++
+		}
+	}
+	if active > 1 {
+		return fmt.Errorf("Invalid JWT signing key set: multiple active keys")
+	}
+
+	if err := tx.Insert(tableIndex, &IndexEntry{tableConnectCAJWTKeys, idx}); err != nil {
+		return fmt.Errorf("failed updating index: %s", err)
+	}
+
+	return tx.Commit()
+}
+
+// CADeleteJWTSigningKeys deletes the JWT-SVID signing keys with the given IDs.
+// Unknown IDs are ignored.
+func (s *Store) CADeleteJWTSigningKeys(idx uint64, ids []string) error {
+	tx := s.db.WriteTxn(idx)
+	defer tx.Abort()
+
+	for _, id := range ids {
+		existing, err := tx.First(tableConnectCAJWTKeys, "id", id)
+		if err != nil {
+			return fmt.Errorf("failed JWT signing key lookup: %s", err)
+		}
+		if existing == nil {
+			continue
+		}
+		if err := tx.Delete(tableConnectCAJWTKeys, existing); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Insert(tableIndex, &IndexEntry{tableConnectCAJWTKeys, idx}); err != nil {
+		return fmt.Errorf("failed updating index: %s", err)
+	}
+
+	return tx.Commit()
+}
+
+// CARevocationLists is used to pull all the certificate revocation lists for
+// the snapshot.
+func (s *Snapshot) CARevocationLists() ([]*structs.CARevocationList, error) {
+	iter, err := s.tx.Get(tableConnectCARevLists, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []*structs.CARevocationList
+	for wrapped := iter.Next(); wrapped != nil; wrapped = iter.Next() {
+		ret = append(ret, wrapped.(*structs.CARevocationList))
+	}
+
+	return ret, nil
+}
+
+// CARevocationList is used when restoring from a snapshot.
+func (s *Restore) CARevocationList(l *structs.CARevocationList) error {
+	if err := s.tx.Insert(tableConnectCARevLists, l); err != nil {
+		return fmt.Errorf("failed restoring CA revocation list: %s", err)
+	}
+	if err := indexUpdateMaxTxn(s.tx, l.ModifyIndex, tableConnectCARevLists); err != nil {
+		return fmt.Errorf("failed updating index: %s", err)
+	}
+
+	return nil
+}
+
+// CARevocationLists returns the certificate revocation lists signed by the
+// leaf signing keys of every datacenter.
+func (s *Store) CARevocationLists(ws memdb.WatchSet) (uint64, []*structs.CARevocationList, error) {
+	tx := s.db.Txn(false)
+	defer tx.Abort()
+
+	idx := maxIndexTxn(tx, tableConnectCARevLists)
+
+	iter, err := tx.Get(tableConnectCARevLists, "id")
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed CA revocation list lookup: %s", err)
+	}
+	ws.Add(iter.WatchCh())
+
+	var results []*structs.CARevocationList
+	for v := iter.Next(); v != nil; v = iter.Next() {
+		results = append(results, v.(*structs.CARevocationList))
+	}
+	return idx, results, nil
+}
+
+// CASetRevocationLists creates or updates the given certificate revocation
+// lists.
+func (s *Store) CASetRevocationLists(idx uint64, lists []*structs.CARevocationList) error {
+	tx := s.db.WriteTxn(idx)
+	defer tx.Abort()
+
+	for _, l := range lists {
+		if l.SigningKeyID == "" {
+			return ErrMissingCARevocationListKeyID
+		}
+
+		existing, err := tx.First(tableConnectCARevLists, "id", l.SigningKeyID)
+		if err != nil {
+			return fmt.Errorf("failed CA revocation list lookup: %s", err)
+		}
+
+		if existing != nil {
+			l.CreateIndex = existing.(*structs.CARevocationList).CreateIndex
+		} else {
+			l.CreateIndex = idx
+		}
+		l.ModifyIndex = idx
+
+		if err := tx.Insert(tableConnectCARevLists, l); err != nil {
+			return fmt.Errorf("failed updating CA revocation list: %s", err)
+		}
+	}
+
+	if err := tx.Insert(tableIndex, &IndexEntry{tableConnectCARevLists, idx}); err != nil {
+		return fmt.Errorf("failed updating index: %s", err)
+	}
+
+	return tx.Commit()
+}
+
+// CADeleteRevocationLists deletes the certificate revocation lists of the
+// given signing key IDs. Unknown IDs are ignored.
+func (s *Store) CADeleteRevocationLists(idx uint64, ids []string) error {
+	tx := s.db.WriteTxn(idx)
+	defer tx.Abort()
+
+	for _, id := range ids {
+		existing, err := tx.First(tableConnectCARevLists, "id", id)
+		if err != nil {
+			return fmt.Errorf("failed CA revocation list lookup: %s", err)
+		}
+		if existing == nil {
+			continue
+		}
+		if err := tx.Delete(tableConnectCARevLists, existing); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Insert(tableIndex, &IndexEntry{tableConnectCARevLists, idx}); err != nil {
+		return fmt.Errorf("failed updating index: %s", err)
+	}
+
+	return tx.Commit()
+}
+
 func (s *Store) CALeafSetIndex(idx uint64, index uint64) error {
 	tx := s.db.WriteTxn(idx)
 	defer tx.Abort()
@@ -495,3 +495,253 @@ func TestStore_CABuiltinProvider_Snapshot_Restore(t *testing.T) {
 		assert.Equal(t, state, res)
 	}
 }
+
+func TestStore_CARevocations(t *testing.T) {
+	s := testStateStore(t)
+
+	// Call list to populate the watch set
+	ws := memdb.NewWatchSet()
+	_, _, err := s.CARevocations(ws)
+	require.NoError(t, err)
+
+	rev1 := &structs.CARevocation{ID: "rev1", Service: "web", Reason: "compromised"}
+	rev2 := &structs.CARevocation{ID: "rev2", SerialNumber: "0a:1b"}
+	require.NoError(t, s.CASetRevocations(1, []*structs.CARevocation{rev1, rev2}))
+	require.True(t, watchFired(ws), "watch fired")
+
+	ws = memdb.NewWatchSet()
+	idx, revs, err := s.CARevocations(ws)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), idx)
+	require.Len(t, revs, 2)
+
+	// Updating a revocation preserves its create index.
+	rev1 = &structs.CARevocation{ID: "rev1", Service: "web", Reason: "still compromised"}
+	require.NoError(t, s.CASetRevocations(2, []*structs.CARevocation{rev1}))
+	require.True(t, watchFired(ws), "watch fired")
+	require.Equal(t, structs.RaftIndex{CreateIndex: 1, ModifyIndex: 2}, rev1.RaftIndex)
+
+	// Revocations must have an ID and exactly one of serial or service.
+	err = s.CASetRevocations(3, []*structs.CARevocation{{Service: "web"}})
+	require.ErrorIs(t, err, ErrMissingCARevocationID)
+	err = s.CASetRevocations(3, []*structs.CARevocation{{ID: "rev3"}})
+	require.ErrorContains(t, err, "either a SerialNumber or a Service")
+	err = s.CASetRevocations(3, []*structs.CARevocation{{ID: "rev3", Service: "web", SerialNumber: "0a"}})
+	require.ErrorContains(t, err, "not have both")
+
+	ws = memdb.NewWatchSet()
+	_, _, err = s.CARevocations(ws)
+	require.NoError(t, err)
+	require.NoError(t, s.CADeleteRevocations(4, []string{"rev1", "unknown"}))
+	require.True(t, watchFired(ws), "watch fired")
+
+	idx, revs, err = s.CARevocations(nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), idx)
+	require.Len(t, revs, 1)
+	require.Equal(t, "rev2", revs[0].ID)
+
+	// Snapshot and restore.
+	snap := s.Snapshot()
+	defer snap.Close()
+	snapped, err := snap.CARevocations()
+	require.NoError(t, err)
+	require.Equal(t, revs, snapped)
+
+	s2 := testStateStore(t)
+	restore := s2.Restore()
+	for _, r := range snapped {
+		require.NoError(t, restore.CARevocation(r))
+	}
+	restore.Commit()
+
+	idx, restored, err := s2.CARevocations(nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), idx)
+	require.Equal(t, revs, restored)
+}
+
+func TestStore_CAFederatedTrustBundles(t *testing.T) {
+	s := testStateStore(t)
+
+	// Call list to populate the watch set
+	ws := memdb.NewWatchSet()
+	_, _, err := s.CAFederatedTrustBundles(ws)
+	require.NoError(t, err)
+
+	b1 := &structs.FederatedTrustBundle{TrustDomain: "example.org", RootPEMs: []string{"pem1"}, Sequence: 1}
+	b2 := &structs.FederatedTrustBundle{TrustDomain: "example.com", RootPEMs: []string{"pem2"}}
+	require.NoError(t, s.CASetFederatedTrustBundles(1, []*structs.FederatedTrustBundle{b1, b2}))
+	require.True(t, watchFired(ws), "watch fired")
+
+	ws = memdb.NewWatchSet()
+	idx, bundles, err := s.CAFederatedTrustBundles(ws)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), idx)
+	require.Len(t, bundles, 2)
+
+	// Updating a bundle preserves its create index.
+	b1 = &structs.FederatedTrustBundle{TrustDomain: "example.org", RootPEMs: []string{"pem1", "pem3"}, Sequence: 2}
+	require.NoError(t, s.CASetFederatedTrustBundles(2, []*structs.FederatedTrustBundle{b1}))
+	require.True(t, watchFired(ws), "watch fired")
+	require.Equal(t, structs.RaftIndex{CreateIndex: 1, ModifyIndex: 2}, b1.RaftIndex)
+
+	err = s.CASetFederatedTrustBundles(3, []*structs.FederatedTrustBundle{{RootPEMs: []string{"pem"}}})
+	require.ErrorIs(t, err, ErrMissingFederatedTrustDomain)
+
+	ws = memdb.NewWatchSet()
+	_, _, err = s.CAFederatedTrustBundles(ws)
+	require.NoError(t, err)
+	require.NoError(t, s.CADeleteFederatedTrustBundles(4, []string{"example.com", "unknown"}))
+	require.True(t, watchFired(ws), "watch fired")
+
+	idx, bundles, err = s.CAFederatedTrustBundles(nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), idx)
+	require.Equal(t, []*structs.FederatedTrustBundle{b1}, bundles)
+
+	// Snapshot and restore.
+	snap := s.Snapshot()
+	defer snap.Close()
+	snapped, err := snap.CAFederatedTrustBundles()
+	require.NoError(t, err)
+	require.Equal(t, bundles, snapped)
+
+	s2 := testStateStore(t)
+	restore := s2.Restore()
+	for _, b := range snapped {
+		require.NoError(t, restore.CAFederatedTrustBundle(b))
+	}
+	restore.Commit()
+
+	idx, restored, err := s2.CAFederatedTrustBundles(nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), idx)
+	require.Equal(t, bundles, restored)
+}
+
+func TestStore_CAJWTSigningKeys(t *testing.T) {
+	s := testStateStore(t)
+
+	// Call list to populate the watch set
+	ws := memdb.NewWatchSet()
+	_, _, err := s.CAJWTSigningKeys(ws)
+	require.NoError(t, err)
+
+	key1 := &structs.CAJWTSigningKey{ID: "key1", PublicKey: "pub1", PrivateKey: "priv1", Active: true}
+	require.NoError(t, s.CASetJWTSigningKeys(1, []*structs.CAJWTSigningKey{key1}))
+	require.True(t, watchFired(ws), "watch fired")
+
+	// Rotating sets the new key active and the old one inactive at once.
+	ws = memdb.NewWatchSet()
+	_, _, err = s.CAJWTSigningKeys(ws)
+	require.NoError(t, err)
+	key1 = &structs.CAJWTSigningKey{ID: "key1", PublicKey: "pub1", PrivateKey: "priv1"}
+	key2 := &structs.CAJWTSigningKey{ID: "key2", PublicKey: "pub2", PrivateKey: "priv2", Active: true}
+	require.NoError(t, s.CASetJWTSigningKeys(2, []*structs.CAJWTSigningKey{key1, key2}))
+	require.True(t, watchFired(ws), "watch fired")
+	require.Equal(t, structs.RaftIndex{CreateIndex: 1, ModifyIndex: 2}, key1.RaftIndex)
+
+	idx, keys, err := s.CAJWTSigningKeys(nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), idx)
+	require.Len(t, keys, 2)
+
+	// Keys must have an ID and only one can be active.
+	err = s.CASetJWTSigningKeys(3, []*structs.CAJWTSigningKey{{PublicKey: "pub"}})
+	require.ErrorIs(t, err, ErrMissingCAJWTSigningKeyID)
+	err = s.CASetJWTSigningKeys(3, []*structs.CAJWTSigningKey{{ID: "key3", Active: true}})
+	require.ErrorContains(t, err, "multiple active keys")
+
+	ws = memdb.NewWatchSet()
+	_, _, err = s.CAJWTSigningKeys(ws)
+	require.NoError(t, err)
+	require.NoError(t, s.CADeleteJWTSigningKeys(4, []string{"key1", "unknown"}))
+	require.True(t, watchFired(ws), "watch fired")
+
+	idx, keys, err = s.CAJWTSigningKeys(nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), idx)
+	require.Equal(t, []*structs.CAJWTSigningKey{key2}, keys)
+
+	// Snapshot and restore.
+	snap := s.Snapshot()
+	defer snap.Close()
+	snapped, err := snap.CAJWTSigningKeys()
+	require.NoError(t, err)
+	require.Equal(t, keys, snapped)
+
+	s2 := testStateStore(t)
+	restore := s2.Restore()
+	for _, k := range snapped {
+		require.NoError(t, restore.CAJWTSigningKey(k))
+	}
+	restore.Commit()
+
+	idx, restored, err := s2.CAJWTSigningKeys(nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), idx)
+	require.Equal(t, keys, restored)
+}
+
+func TestStore_CARevocationLists(t *testing.T) {
+	s := testStateStore(t)
+
+	// Call list to populate the watch set
+	ws := memdb.NewWatchSet()
+	_, _, err := s.CARevocationLists(ws)
+	require.NoError(t, err)
+
+	list1 := &structs.CARevocationList{SigningKeyID: "aa:bb", Datacenter: "dc1", PEM: "crl1"}
+	list2 := &structs.CARevocationList{SigningKeyID: "cc:dd", Datacenter: "dc2", PEM: "crl2"}
+	require.NoError(t, s.CASetRevocationLists(1, []*structs.CARevocationList{list1, list2}))
+	require.True(t, watchFired(ws), "watch fired")
+
+	// Re-signing a list replaces it.
+	ws = memdb.NewWatchSet()
+	_, _, err = s.CARevocationLists(ws)
+	require.NoError(t, err)
+	list1 = &structs.CARevocationList{SigningKeyID: "aa:bb", Datacenter: "dc1", PEM: "crl1-updated"}
+	require.NoError(t, s.CASetRevocationLists(2, []*structs.CARevocationList{list1}))
+	require.True(t, watchFired(ws), "watch fired")
+	require.Equal(t, structs.RaftIndex{CreateIndex: 1, ModifyIndex: 2}, list1.RaftIndex)
+
+	idx, lists, err := s.CARevocationLists(nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), idx)
+	require.Equal(t, []*structs.CARevocationList{list1, list2}, lists)
+
+	// Lists must have a signing key ID.
+	err = s.CASetRevocationLists(3, []*structs.CARevocationList{{PEM: "crl"}})
+	require.ErrorIs(t, err, ErrMissingCARevocationListKeyID)
+
+	ws = memdb.NewWatchSet()
+	_, _, err = s.CARevocationLists(ws)
+	require.NoError(t, err)
+	require.NoError(t, s.CADeleteRevocationLists(4, []string{"cc:dd", "unknown"}))
+	require.True(t, watchFired(ws), "watch fired")
+
+	idx, lists, err = s.CARevocationLists(nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), idx)
+	require.Equal(t, []*structs.CARevocationList{list1}, lists)
+
+	// Snapshot and restore.
+	snap := s.Snapshot()
+	defer snap.Close()
+	snapped, err := snap.CARevocationLists()
+	require.NoError(t, err)
+	require.Equal(t, lists, snapped)
+
+	s2 := testStateStore(t)
+	restore := s2.Restore()
+	for _, l := range snapped {
+		require.NoError(t, restore.CARevocationList(l))
+	}
+	restore.Commit()
+
+	idx, restored, err := s2.CARevocationLists(nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), idx)
+	require.Equal(t, lists, restored)
+}
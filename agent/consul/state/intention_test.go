@@ -2088,6 +2088,47 @@ func TestStore_IntentionDecision(t *testing.T) {
 	}
 }
 
+func TestStore_IntentionMatchOne_federatedSource(t *testing.T) {
+	s := testConfigStateStore(t)
+
+	entries := []*structs.ServiceIntentionsConfigEntry{
+		{
+			Kind: structs.ServiceIntentions,
+			Name: "api",
+			Sources: []*structs.SourceIntention{
+				{Name: "web", TrustDomain: "example.org", Action: structs.IntentionActionAllow},
+			},
+		},
+		{
+			Kind: structs.ServiceIntentions,
+			Name: "db",
+			Sources: []*structs.SourceIntention{
+				{Name: "web", Action: structs.IntentionActionAllow},
+				{Name: "web", TrustDomain: "example.org", Action: structs.IntentionActionDeny},
+			},
+		},
+	}
+	for i, entry := range entries {
+		require.NoError(t, entry.Normalize())
+		require.NoError(t, entry.Validate())
+		require.NoError(t, s.EnsureConfigEntry(uint64(i+1), entry))
+	}
+
+	// The workload of the federated trust domain is not the local web
+	// service, so its intentions are not the intentions of web.
+	entry := structs.IntentionMatchEntry{
+		Namespace: structs.IntentionDefaultNamespace,
+		Partition: acl.DefaultPartitionName,
+		Name:      "web",
+	}
+	_, intentions, err := s.IntentionMatchOne(nil, entry, structs.IntentionMatchSource, structs.IntentionTargetService)
+	require.NoError(t, err)
+	require.Len(t, intentions, 1)
+	require.Equal(t, "db", intentions[0].DestinationName)
+	require.Equal(t, structs.IntentionActionAllow, intentions[0].Action)
+	require.Empty(t, intentions[0].SourceTrustDomain)
+}
+
 func disableLegacyIntentions(s *Store) error {
 	return s.SystemMetadataSet(1, &structs.SystemMetadataEntry{
 		Key:   structs.SystemMetadataIntentionFormatKey,
@@ -20,6 +20,10 @@ func newDBSchema() *memdb.DBSchema {
 		bindingRulesTableSchema,
 		caBuiltinProviderTableSchema,
 		caConfigTableSchema,
+		caFederatedTrustBundleTableSchema,
+		caJWTSigningKeyTableSchema,
+		caRevocationListTableSchema,
+		caRevocationTableSchema,
 		caRootTableSchema,
 		checksTableSchema,
 		configTableSchema,
@@ -38,6 +42,7 @@ func newDBSchema() *memdb.DBSchema {
 		peeringSecretsTableSchema,
 		peeringSecretUUIDsTableSchema,
 		policiesTableSchema,
+		policyTemplatesTableSchema,
 		preparedQueriesTableSchema,
 		rolesTableSchema,
 		servicesTableSchema,
@@ -46,6 +51,7 @@ func newDBSchema() *memdb.DBSchema {
 		sessionsTableSchema,
 		systemMetadataTableSchema,
 		tokensTableSchema,
+		tokenUsagesTableSchema,
 		tombstonesTableSchema,
 		usageTableSchema,
 	)
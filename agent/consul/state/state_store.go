@@ -48,6 +48,14 @@ var (
 	// policy with an empty Name.
 	ErrMissingACLPolicyName = errors.New("Missing ACL Policy Name")
 
+	// ErrMissingACLPolicyTemplateID is returned when a policy template set is
+	// called on a template with an empty ID.
+	ErrMissingACLPolicyTemplateID = errors.New("Missing ACL Policy Template ID")
+
+	// ErrMissingACLPolicyTemplateName is returned when a policy template set
+	// is called on a template with an empty Name.
+	ErrMissingACLPolicyTemplateName = errors.New("Missing ACL Policy Template Name")
+
 	// ErrMissingACLRoleID is returned when a role set is called on
 	// a role with an empty ID.
 	ErrMissingACLRoleID = errors.New("Missing ACL Role ID")
@@ -80,6 +88,22 @@ var (
 	// with an CARoot with an empty ID.
 	ErrMissingCARootID = errors.New("Missing CA Root ID")
 
+	// ErrMissingCARevocationID is returned when a CARevocation set is called
+	// with a CARevocation with an empty ID.
+	ErrMissingCARevocationID = errors.New("Missing CA Revocation ID")
+
+	// ErrMissingFederatedTrustDomain is returned when a FederatedTrustBundle
+	// set is called with a FederatedTrustBundle with an empty TrustDomain.
+	ErrMissingFederatedTrustDomain = errors.New("Missing federated trust domain")
+
+	// ErrMissingCAJWTSigningKeyID is returned when a CAJWTSigningKey set is
+	// called with a CAJWTSigningKey with an empty ID.
+	ErrMissingCAJWTSigningKeyID = errors.New("Missing CA JWT signing key ID")
+
+	// ErrMissingCARevocationListKeyID is returned when a CARevocationList set
+	// is called with a CARevocationList with an empty SigningKeyID.
+	ErrMissingCARevocationListKeyID = errors.New("Missing CA revocation list signing key ID")
+
 	// ErrMissingIntentionID is returned when an Intention set is called
 	// with an Intention with an empty ID.
 	ErrMissingIntentionID = errors.New("Missing Intention ID")
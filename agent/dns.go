@@ -9,8 +9,10 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"math/rand"
 	"net"
 	"regexp"
+	"sort"
 	"strings"
 	"sync/atomic"
 	"time"
@@ -49,6 +51,10 @@ var DNSSummaries = []prometheus.SummaryDefinition{
 		Name: []string{"dns", "domain_query"},
 		Help: "Measures the time spent handling a domain query for the given node.",
 	},
+	{
+		Name: []string{"dns", "zone_transfer"},
+		Help: "Measures the time spent handling a zone transfer (AXFR/IXFR) request for the given node.",
+	},
 }
 
 const (
@@ -103,6 +109,14 @@ type dnsConfig struct {
 	// TTLStict sets TTLs to service by full name match. It Has higher priority than TTLRadix
 	TTLStrict          map[string]time.Duration
 	DisableCompression bool
+	// ZoneTransferAllowFrom lists the networks allowed to perform AXFR/IXFR
+	// requests. Zone transfers are refused when empty.
+	ZoneTransferAllowFrom []*net.IPNet
+	// OrderByRTT returns the nearest service instances first instead of
+	// shuffling them.
+	OrderByRTT bool
+	// WeightedAnswers orders service instances according to their weights.
+	WeightedAnswers bool
 
 	enterpriseDNSConfig
 }
@@ -144,6 +158,14 @@ type DNSServer struct {
 	// the recursor handler is only enabled if recursors are configured. This flag is used during config hot-reloading
 	recursorEnabled uint32
 
+	// zones keeps the most recent zone snapshots so that IXFR requests can
+	// be answered with the difference between two serials.
+	zones zoneHistory
+
+	// zoneWatchRunning is set while the catalog is watched to record the
+	// zone history, see startZoneWatch.
+	zoneWatchRunning uint32
+
 	defaultEnterpriseMeta acl.EnterpriseMeta
 }
 
@@ -184,22 +206,25 @@ func NewDNSServer(a *Agent) (*DNSServer, error) {
 // GetDNSConfig takes global config and creates the config used by DNS server
 func GetDNSConfig(conf *config.RuntimeConfig) (*dnsConfig, error) {
 	cfg := &dnsConfig{
-		AllowStale:         conf.DNSAllowStale,
-		ARecordLimit:       conf.DNSARecordLimit,
-		Datacenter:         conf.Datacenter,
-		EnableTruncate:     conf.DNSEnableTruncate,
-		MaxStale:           conf.DNSMaxStale,
-		NodeName:           conf.NodeName,
-		NodeTTL:            conf.DNSNodeTTL,
-		OnlyPassing:        conf.DNSOnlyPassing,
-		RecursorStrategy:   conf.DNSRecursorStrategy,
-		RecursorTimeout:    conf.DNSRecursorTimeout,
-		SegmentName:        conf.SegmentName,
-		UDPAnswerLimit:     conf.DNSUDPAnswerLimit,
-		NodeMetaTXT:        conf.DNSNodeMetaTXT,
-		DisableCompression: conf.DNSDisableCompression,
-		UseCache:           conf.DNSUseCache,
-		CacheMaxAge:        conf.DNSCacheMaxAge,
+		AllowStale:            conf.DNSAllowStale,
+		ARecordLimit:          conf.DNSARecordLimit,
+		Datacenter:            conf.Datacenter,
+		EnableTruncate:        conf.DNSEnableTruncate,
+		MaxStale:              conf.DNSMaxStale,
+		NodeName:              conf.NodeName,
+		NodeTTL:               conf.DNSNodeTTL,
+		OnlyPassing:           conf.DNSOnlyPassing,
+		RecursorStrategy:      conf.DNSRecursorStrategy,
+		RecursorTimeout:       conf.DNSRecursorTimeout,
+		SegmentName:           conf.SegmentName,
+		UDPAnswerLimit:        conf.DNSUDPAnswerLimit,
+		NodeMetaTXT:           conf.DNSNodeMetaTXT,
+		DisableCompression:    conf.DNSDisableCompression,
+		UseCache:              conf.DNSUseCache,
+		CacheMaxAge:           conf.DNSCacheMaxAge,
+		ZoneTransferAllowFrom: conf.DNSAllowZoneTransferFrom,
+		OrderByRTT:            conf.DNSOrderByRTT,
+		WeightedAnswers:       conf.DNSWeightedAnswers,
 		SOAConfig: dnsSOAConfig{
 			Expire:  conf.DNSSOA.Expire,
 			Minttl:  conf.DNSSOA.Minttl,
@@ -492,6 +517,11 @@ func (d *DNSServer) handlePtr(resp dns.ResponseWriter, req *dns.Msg) {
 // handleQuery is used to handle DNS queries in the configured domain
 func (d *DNSServer) handleQuery(resp dns.ResponseWriter, req *dns.Msg) {
 	q := req.Question[0]
+	if q.Qtype == dns.TypeAXFR || q.Qtype == dns.TypeIXFR {
+		d.handleZoneTransfer(resp, req)
+		return
+	}
+
 	defer func(s time.Time) {
 		metrics.MeasureSinceWithLabels([]string{"dns", "domain_query"}, s,
 			[]metrics.Label{{Name: "node", Value: d.agent.config.NodeName}})
@@ -525,7 +555,7 @@ func (d *DNSServer) handleQuery(resp dns.ResponseWriter, req *dns.Msg) {
 	switch req.Question[0].Qtype {
 	case dns.TypeSOA:
 		ns, glue := d.nameservers(req.Question[0].Name, cfg, maxRecursionLevelDefault)
-		m.Answer = append(m.Answer, d.soa(cfg, q.Name))
+		m.Answer = append(m.Answer, d.apexSOA(cfg, resp.RemoteAddr(), q.Name))
 		m.Ns = append(m.Ns, ns...)
 		m.Extra = append(m.Extra, glue...)
 		m.SetRcode(req, dns.RcodeSuccess)
@@ -536,9 +566,6 @@ func (d *DNSServer) handleQuery(resp dns.ResponseWriter, req *dns.Msg) {
 		m.Extra = glue
 		m.SetRcode(req, dns.RcodeSuccess)
 
-	case dns.TypeAXFR:
-		m.SetRcode(req, dns.RcodeNotImplemented)
-
 	default:
 		err = d.dispatch(resp.RemoteAddr(), req, m, maxRecursionLevelDefault)
 		rCode := rCodeFromError(err)
@@ -1434,6 +1461,16 @@ func (d *DNSServer) lookupServiceNodes(cfg *dnsConfig, lookup serviceLookup) (st
 		EnterpriseMeta: lookup.EnterpriseMeta,
 	}
 
+	// Let the servers sort the instances by their distance to this agent.
+	if cfg.OrderByRTT {
+		args.Source = structs.QuerySource{
+			Datacenter:    d.agent.config.Datacenter,
+			Segment:       d.agent.config.SegmentName,
+			Node:          d.agent.config.NodeName,
+			NodePartition: d.agent.config.PartitionOrEmpty(),
+		}
+	}
+
 	out, _, err := d.agent.rpcClientHealth.ServiceNodes(context.TODO(), args)
 	if err != nil {
 		return out, err
@@ -1459,8 +1496,17 @@ func (d *DNSServer) serviceLookup(cfg *dnsConfig, lookup serviceLookup, req, res
 		return errNameNotFound
 	}
 
-	// Perform a random shuffle
-	out.Nodes.Shuffle()
+	// Perform a random shuffle, unless the nodes are already sorted by
+	// distance
+	if !cfg.OrderByRTT {
+		out.Nodes.Shuffle()
+	}
+	if cfg.WeightedAnswers {
+		out.Nodes = weightedOrder(out.Nodes, !cfg.OrderByRTT)
+		if len(out.Nodes) == 0 {
+			return errNoData
+		}
+	}
 
 	// Determine the TTL
 	ttl, _ := cfg.GetTTLForService(lookup.Service)
@@ -1722,6 +1768,51 @@ func findWeight(node structs.CheckServiceNode) int {
 	}
 }
 
+// weightedOrder removes the instances with a weight of zero and, if shuffle
+// is set, reorders the others randomly so that the probability of an instance
+// coming before another one is proportional to its weight. The same record
+// can't be repeated in an answer, so the weights affect the position of the
+// instances instead, which matters once the answer is truncated to the record
+// limits.
+func weightedOrder(nodes structs.CheckServiceNodes, shuffle bool) structs.CheckServiceNodes {
+	out := make(structs.CheckServiceNodes, 0, len(nodes))
+	keys := make([]float64, 0, len(nodes))
+	for _, node := range nodes {
+		weight := findWeight(node)
+		if weight <= 0 {
+			continue
+		}
+		out = append(out, node)
+		// Weighted random sampling without replacement, see Efraimidis and
+		// Spirakis: sorting by u^(1/w) with u uniform in [0, 1).
+		keys = append(keys, math.Pow(rand.Float64(), 1/float64(weight)))
+	}
+
+	if shuffle {
+		sort.Sort(&weightedNodes{nodes: out, keys: keys})
+	}
+	return out
+}
+
+// weightedNodes sorts nodes by descending keys.
+type weightedNodes struct {
+	nodes structs.CheckServiceNodes
+	keys  []float64
+}
+
+func (w *weightedNodes) Len() int {
+	return len(w.nodes)
+}
+
+func (w *weightedNodes) Swap(i, j int) {
+	w.nodes[i], w.nodes[j] = w.nodes[j], w.nodes[i]
+	w.keys[i], w.keys[j] = w.keys[j], w.keys[i]
+}
+
+func (w *weightedNodes) Less(i, j int) bool {
+	return w.keys[i] > w.keys[j]
+}
+
 func (d *DNSServer) encodeIPAsFqdn(questionName string, lookup serviceLookup, ip net.IP) string {
 	ipv4 := ip.To4()
 	respDomain := d.getResponseDomain(questionName)
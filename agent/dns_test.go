@@ -1279,6 +1279,227 @@ func TestDNS_SOA_Settings(t *testing.T) {
 	testSoaWithConfig("dns_config={soa={refresh=1800,retry=300}}", 0, 86400, 1800, 300)
 }
 
+func TestDNS_ZoneTransfer(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := NewTestAgent(t, `
+		dns_config {
+			allow_zone_transfer_from = ["127.0.0.0/8"]
+		}
+	`)
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	register := func(node, address, service string, port int) {
+		args := &structs.RegisterRequest{
+			Datacenter: "dc1",
+			Node:       node,
+			Address:    address,
+			Service: &structs.NodeService{
+				Service: service,
+				Port:    port,
+			},
+		}
+		var out struct{}
+		require.NoError(t, a.RPC(context.Background(), "Catalog.Register", args, &out))
+	}
+	register("foo", "127.0.0.2", "db", 12345)
+
+	transfer := func(m *dns.Msg) []dns.RR {
+		tr := new(dns.Transfer)
+		env, err := tr.In(m, a.DNSAddr())
+		require.NoError(t, err)
+
+		var records []dns.RR
+		for e := range env {
+			require.NoError(t, e.Error)
+			records = append(records, e.RR...)
+		}
+		return records
+	}
+	find := func(records []dns.RR, s string) bool {
+		for _, rr := range records {
+			if rr.String() == s {
+				return true
+			}
+		}
+		return false
+	}
+
+	m := new(dns.Msg)
+	m.SetAxfr("consul.")
+	axfr := transfer(m)
+	require.GreaterOrEqual(t, len(axfr), 2)
+
+	soa, ok := axfr[0].(*dns.SOA)
+	require.True(t, ok, "first record is not a SOA: %v", axfr[0])
+	require.Equal(t, axfr[0].String(), axfr[len(axfr)-1].String())
+	require.NotZero(t, soa.Serial)
+
+	require.True(t, find(axfr, "foo.node.dc1.consul.\t0\tIN\tA\t127.0.0.2"), "missing node record: %v", axfr)
+	require.True(t, find(axfr, "db.service.dc1.consul.\t0\tIN\tA\t127.0.0.2"), "missing service record: %v", axfr)
+	require.True(t, find(axfr, "db.service.dc1.consul.\t0\tIN\tSRV\t1 1 12345 foo.node.dc1.consul."), "missing SRV record: %v", axfr)
+
+	// The SOA served to normal queries carries the same serial.
+	q := new(dns.Msg)
+	q.SetQuestion("consul.", dns.TypeSOA)
+	in, _, err := new(dns.Client).Exchange(q, a.DNSAddr())
+	require.NoError(t, err)
+	require.Len(t, in.Answer, 1)
+	require.Equal(t, soa.Serial, in.Answer[0].(*dns.SOA).Serial)
+
+	// An up to date client only receives the SOA.
+	m = new(dns.Msg)
+	m.SetIxfr("consul.", soa.Serial, soa.Ns, soa.Mbox)
+	ixfr := transfer(m)
+	require.Len(t, ixfr, 1)
+
+	// After a change, the client receives the difference.
+	register("bar", "127.0.0.3", "web", 8080)
+
+	m = new(dns.Msg)
+	m.SetIxfr("consul.", soa.Serial, soa.Ns, soa.Mbox)
+	ixfr = transfer(m)
+	require.Greater(t, len(ixfr), 4)
+
+	newSOA := ixfr[0].(*dns.SOA)
+	require.Greater(t, newSOA.Serial, soa.Serial)
+	require.Equal(t, soa.Serial, ixfr[1].(*dns.SOA).Serial)
+	require.Equal(t, newSOA.Serial, ixfr[2].(*dns.SOA).Serial)
+	require.Equal(t, newSOA.Serial, ixfr[len(ixfr)-1].(*dns.SOA).Serial)
+	require.True(t, find(ixfr, "web.service.dc1.consul.\t0\tIN\tA\t127.0.0.3"), "missing added record: %v", ixfr)
+	require.False(t, find(ixfr, "db.service.dc1.consul.\t0\tIN\tA\t127.0.0.2"), "unchanged record sent: %v", ixfr)
+}
+
+func TestDNS_ZoneTransfer_HistoryRecordedOnCatalogChanges(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := NewTestAgent(t, `
+		dns_config {
+			allow_zone_transfer_from = ["127.0.0.0/8"]
+		}
+	`)
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	register := func(node, address string) uint64 {
+		args := &structs.RegisterRequest{
+			Datacenter: "dc1",
+			Node:       node,
+			Address:    address,
+		}
+		var out struct{}
+		require.NoError(t, a.RPC(context.Background(), "Catalog.Register", args, &out))
+
+		var dump structs.IndexedNodeDump
+		req := &structs.DCSpecificRequest{Datacenter: "dc1"}
+		require.NoError(t, a.RPC(context.Background(), "Internal.NodeDump", req, &dump))
+		return dump.Index
+	}
+	transfer := func(m *dns.Msg) []dns.RR {
+		env, err := new(dns.Transfer).In(m, a.DNSAddr())
+		require.NoError(t, err)
+
+		var records []dns.RR
+		for e := range env {
+			require.NoError(t, e.Error)
+			records = append(records, e.RR...)
+		}
+		return records
+	}
+
+	// The first transfer starts watching the catalog.
+	m := new(dns.Msg)
+	m.SetAxfr("consul.")
+	soa := transfer(m)[0].(*dns.SOA)
+
+	// The zone is recorded after each change even though no client asked
+	// for it.
+	var server *DNSServer
+	for _, s := range a.dnsServers {
+		if s.Server != nil && s.Server.Net == "tcp" {
+			server = s
+		}
+	}
+	require.NotNil(t, server)
+	serial := uint32(register("foo", "127.0.0.2"))
+	retry.Run(t, func(r *retry.R) {
+		if server.zones.get("consul.", serial) == nil {
+			r.Fatalf("zone with serial %d not recorded", serial)
+		}
+	})
+	require.Greater(t, serial, soa.Serial)
+	register("bar", "127.0.0.3")
+
+	// A client at the intermediate serial only receives the later change.
+	m = new(dns.Msg)
+	m.SetIxfr("consul.", serial, soa.Ns, soa.Mbox)
+	ixfr := transfer(m)
+	require.Equal(t, serial, ixfr[1].(*dns.SOA).Serial)
+
+	var names []string
+	for _, rr := range ixfr {
+		if a, ok := rr.(*dns.A); ok {
+			names = append(names, a.Hdr.Name)
+		}
+	}
+	require.Equal(t, []string{"bar.node.dc1.consul."}, names)
+}
+
+func TestDNS_ZoneTransfer_Refused(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := NewTestAgent(t, "")
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	m := new(dns.Msg)
+	m.SetAxfr("consul.")
+
+	c := &dns.Client{Net: "tcp"}
+	in, _, err := c.Exchange(m, a.DNSAddr())
+	require.NoError(t, err)
+	require.Equal(t, dns.RcodeRefused, in.Rcode)
+	require.Empty(t, in.Answer)
+}
+
+func TestDNS_ZoneTransfer_SOASerialOnlyForAllowedClients(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := NewTestAgent(t, `
+		dns_config {
+			allow_zone_transfer_from = ["192.0.2.0/24"]
+		}
+	`)
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	// The client is not allowed to transfer the zone so the serial is not
+	// computed from the catalog.
+	q := new(dns.Msg)
+	q.SetQuestion("consul.", dns.TypeSOA)
+	in, _, err := new(dns.Client).Exchange(q, a.DNSAddr())
+	require.NoError(t, err)
+	require.Len(t, in.Answer, 1)
+
+	zones := &a.dnsServers[0].zones
+	zones.Lock()
+	defer zones.Unlock()
+	require.Empty(t, zones.snapshots)
+}
+
 func TestDNS_ServiceReverseLookupNodeAddress(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
@@ -4734,6 +4955,132 @@ func TestDNS_ServiceLookup_OnlyPassing(t *testing.T) {
 	require.Equal(t, []string{"127.0.0.1", "127.0.0.2"}, ips)
 }
 
+func TestDNS_ServiceLookup_OrderByRTT(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := NewTestAgent(t, `
+		dns_config {
+			order_by_rtt = true
+		}
+	`)
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	serviceNodes := []struct {
+		name    string
+		address string
+		coord   *coordinate.Coordinate
+	}{
+		{"foo3", "198.18.0.3", lib.GenerateCoordinate(30 * time.Millisecond)},
+		{"foo1", "198.18.0.1", lib.GenerateCoordinate(1 * time.Millisecond)},
+		{"foo2", "198.18.0.2", lib.GenerateCoordinate(10 * time.Millisecond)},
+	}
+	for _, cfg := range serviceNodes {
+		args := &structs.RegisterRequest{
+			Datacenter: "dc1",
+			Node:       cfg.name,
+			Address:    cfg.address,
+			Service: &structs.NodeService{
+				Service: "db",
+				Port:    12345,
+			},
+		}
+
+		var out struct{}
+		require.NoError(t, a.RPC(context.Background(), "Catalog.Register", args, &out))
+
+		coordArgs := structs.CoordinateUpdateRequest{
+			Datacenter: "dc1",
+			Node:       cfg.name,
+			Coord:      cfg.coord,
+		}
+		require.NoError(t, a.RPC(context.Background(), "Coordinate.Update", &coordArgs, &out))
+	}
+
+	// The agent itself sits at the origin.
+	retry.Run(t, func(r *retry.R) {
+		coordArgs := structs.CoordinateUpdateRequest{
+			Datacenter: "dc1",
+			Node:       a.config.NodeName,
+			Coord:      lib.GenerateCoordinate(0),
+		}
+		var out struct{}
+		require.NoError(r, a.RPC(context.Background(), "Coordinate.Update", &coordArgs, &out))
+	})
+
+	retry.Run(t, func(r *retry.R) {
+		m := new(dns.Msg)
+		m.SetQuestion("db.service.consul.", dns.TypeA)
+
+		in, _, err := new(dns.Client).Exchange(m, a.DNSAddr())
+		require.NoError(r, err)
+		require.Len(r, in.Answer, 3)
+
+		var addrs []string
+		for _, rr := range in.Answer {
+			addrs = append(addrs, rr.(*dns.A).A.String())
+		}
+		require.Equal(r, []string{"198.18.0.1", "198.18.0.2", "198.18.0.3"}, addrs)
+	})
+}
+
+func TestDNS_ServiceLookup_WeightedAnswers(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := NewTestAgent(t, `
+		dns_config {
+			weighted_answers = true
+		}
+	`)
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	register := func(node, address string, weights *structs.Weights, status string) {
+		args := &structs.RegisterRequest{
+			Datacenter: "dc1",
+			Node:       node,
+			Address:    address,
+			Service: &structs.NodeService{
+				Service: "db",
+				Port:    12345,
+				Weights: weights,
+			},
+			Check: &structs.HealthCheck{
+				CheckID:   "db",
+				Name:      "db",
+				ServiceID: "db",
+				Status:    status,
+			},
+		}
+		var out struct{}
+		require.NoError(t, a.RPC(context.Background(), "Catalog.Register", args, &out))
+	}
+	register("heavy", "198.18.0.1", &structs.Weights{Passing: 100, Warning: 1}, api.HealthPassing)
+	register("light", "198.18.0.2", &structs.Weights{Passing: 1, Warning: 1}, api.HealthPassing)
+	register("drained", "198.18.0.3", &structs.Weights{Passing: 1, Warning: 0}, api.HealthWarning)
+
+	heavyFirst := 0
+	for i := 0; i < 50; i++ {
+		m := new(dns.Msg)
+		m.SetQuestion("db.service.consul.", dns.TypeA)
+
+		in, _, err := new(dns.Client).Exchange(m, a.DNSAddr())
+		require.NoError(t, err)
+		require.Len(t, in.Answer, 2, "instance with a weight of zero must be omitted")
+		if in.Answer[0].(*dns.A).A.String() == "198.18.0.1" {
+			heavyFirst++
+		}
+	}
+	// The heavy instance comes first with a probability of 100/101.
+	require.Greater(t, heavyFirst, 40)
+}
+
 func TestDNS_ServiceLookup_Randomize(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
@@ -0,0 +1,571 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package agent
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/miekg/dns"
+
+	agentdns "github.com/hernad/consul/agent/dns"
+	"github.com/hernad/consul/agent/structs"
+)
+
+const (
+	// zoneHistorySize is the number of zone snapshots kept around to answer
+	// IXFR requests with incremental differences. Older serials are answered
+	// with a full transfer.
+	zoneHistorySize = 16
+
+	// zoneWatchRetryInterval is the time to wait before watching the catalog
+	// again after a failed request.
+	zoneWatchRetryInterval = 5 * time.Second
+
+	// zoneTransferChunkSize is the maximum number of records sent in a
+	// single message of a zone transfer over TCP.
+	zoneTransferChunkSize = 64
+
+	// zoneNameservers is the maximum number of NS records in the zone.
+	zoneNameservers = 3
+)
+
+// zoneSnapshot is the content of the consul zone for the local datacenter at
+// a given catalog index.
+type zoneSnapshot struct {
+	domain string
+	serial uint32
+
+	// records holds every record of the zone except the SOA, sorted by name
+	// so that transfers are deterministic.
+	records []dns.RR
+	keys    map[string]struct{}
+}
+
+// zoneHistory is a bounded list of the most recently generated zone
+// snapshots, oldest first.
+type zoneHistory struct {
+	sync.Mutex
+	snapshots []*zoneSnapshot
+}
+
+func (h *zoneHistory) add(snap *zoneSnapshot) {
+	h.Lock()
+	defer h.Unlock()
+
+	for _, s := range h.snapshots {
+		if s.domain == snap.domain && s.serial == snap.serial {
+			return
+		}
+	}
+	h.snapshots = append(h.snapshots, snap)
+	if len(h.snapshots) > zoneHistorySize {
+		h.snapshots = h.snapshots[len(h.snapshots)-zoneHistorySize:]
+	}
+}
+
+func (h *zoneHistory) get(domain string, serial uint32) *zoneSnapshot {
+	h.Lock()
+	defer h.Unlock()
+
+	for _, s := range h.snapshots {
+		if s.domain == domain && s.serial == serial {
+			return s
+		}
+	}
+	return nil
+}
+
+// handleZoneTransfer is used to handle AXFR and IXFR requests for the
+// configured domains. Only clients in dns_config.allow_zone_transfer_from
+// are allowed to transfer the zone and the content of the zone is filtered
+// with the ACL rules of the agent's default token, like any other query.
+func (d *DNSServer) handleZoneTransfer(resp dns.ResponseWriter, req *dns.Msg) {
+	q := req.Question[0]
+	defer func(s time.Time) {
+		metrics.MeasureSinceWithLabels([]string{"dns", "zone_transfer"}, s,
+			[]metrics.Label{{Name: "node", Value: d.agent.config.NodeName}})
+		d.logger.Debug("zone transfer served to client",
+			"name", q.Name,
+			"type", dns.Type(q.Qtype),
+			"latency", time.Since(s).String(),
+			"client", resp.RemoteAddr().String(),
+			"client_network", resp.RemoteAddr().Network(),
+		)
+	}(time.Now())
+
+	cfg := d.config.Load().(*dnsConfig)
+
+	m := new(dns.Msg)
+	m.SetReply(req)
+	m.Compress = !cfg.DisableCompression
+	m.Authoritative = true
+
+	records, rCode := d.zoneTransferRecords(cfg, resp.RemoteAddr(), req)
+	if rCode != dns.RcodeSuccess {
+		m.SetRcode(req, rCode)
+		if err := resp.WriteMsg(m); err != nil {
+			d.logger.Warn("failed to respond", "error", err)
+		}
+		return
+	}
+
+	if _, ok := resp.RemoteAddr().(*net.TCPAddr); !ok {
+		// RFC 1995: when an IXFR response does not fit in a UDP message the
+		// server replies with its current SOA, telling the client to retry
+		// over TCP.
+		m.Answer = records[:1]
+		if err := resp.WriteMsg(m); err != nil {
+			d.logger.Warn("failed to respond", "error", err)
+		}
+		return
+	}
+
+	for len(records) > 0 {
+		n := len(records)
+		if n > zoneTransferChunkSize {
+			n = zoneTransferChunkSize
+		}
+
+		m := new(dns.Msg)
+		m.SetReply(req)
+		m.Compress = !cfg.DisableCompression
+		m.Authoritative = true
+		m.Answer = records[:n]
+		records = records[n:]
+
+		if err := resp.WriteMsg(m); err != nil {
+			d.logger.Warn("failed to respond", "error", err)
+			return
+		}
+	}
+}
+
+// zoneTransferRecords returns the sequence of records that answers the AXFR
+// or IXFR request, or the response code to use when the request can not be
+// served.
+func (d *DNSServer) zoneTransferRecords(cfg *dnsConfig, remoteAddr net.Addr, req *dns.Msg) ([]dns.RR, int) {
+	q := req.Question[0]
+
+	if !zoneTransferAllowed(cfg, remoteAddr) {
+		d.logger.Warn("zone transfer refused", "client", remoteAddr.String())
+		return nil, dns.RcodeRefused
+	}
+
+	// AXFR is only defined over TCP (RFC 5936).
+	if _, ok := remoteAddr.(*net.TCPAddr); !ok && q.Qtype == dns.TypeAXFR {
+		return nil, dns.RcodeRefused
+	}
+
+	domain := d.zoneApex(q.Name)
+	if domain == "" {
+		return nil, dns.RcodeNotAuth
+	}
+
+	var clientSerial uint32
+	if q.Qtype == dns.TypeIXFR {
+		if len(req.Ns) == 0 {
+			return nil, dns.RcodeFormatError
+		}
+		soa, ok := req.Ns[0].(*dns.SOA)
+		if !ok {
+			return nil, dns.RcodeFormatError
+		}
+		clientSerial = soa.Serial
+	}
+
+	snap, err := d.zoneSnapshot(cfg, domain)
+	if err != nil {
+		d.logger.Warn("unable to build zone for transfer", "zone", domain, "error", err)
+		return nil, rCodeFromError(err)
+	}
+	soa := d.zoneSOA(cfg, snap)
+	d.startZoneWatch()
+
+	if q.Qtype == dns.TypeIXFR {
+		// The client is up to date.
+		if clientSerial == snap.serial {
+			return []dns.RR{soa}, dns.RcodeSuccess
+		}
+
+		if old := d.zones.get(domain, clientSerial); old != nil {
+			return ixfrRecords(soa, old, snap), dns.RcodeSuccess
+		}
+		// We don't know about this serial anymore, fall back to sending
+		// the whole zone which is allowed by RFC 1995.
+	}
+
+	records := make([]dns.RR, 0, len(snap.records)+2)
+	records = append(records, soa)
+	records = append(records, snap.records...)
+	records = append(records, soa)
+	return records, dns.RcodeSuccess
+}
+
+// ixfrRecords returns the incremental transfer from old to cur as defined by
+// RFC 1995: the current SOA, then the old SOA followed by the deleted records,
+// the current SOA followed by the added records and the current SOA again.
+func ixfrRecords(soa *dns.SOA, old, cur *zoneSnapshot) []dns.RR {
+	oldSOA := *soa
+	oldSOA.Serial = old.serial
+
+	records := []dns.RR{soa, &oldSOA}
+	for _, rr := range old.records {
+		if _, ok := cur.keys[rr.String()]; !ok {
+			records = append(records, rr)
+		}
+	}
+	records = append(records, soa)
+	for _, rr := range cur.records {
+		if _, ok := old.keys[rr.String()]; !ok {
+			records = append(records, rr)
+		}
+	}
+	return append(records, soa)
+}
+
+func zoneTransferAllowed(cfg *dnsConfig, addr net.Addr) bool {
+	var ip net.IP
+	switch a := addr.(type) {
+	case *net.TCPAddr:
+		ip = a.IP
+	case *net.UDPAddr:
+		ip = a.IP
+	}
+	if ip == nil {
+		return false
+	}
+
+	for _, n := range cfg.ZoneTransferAllowFrom {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// zoneApex returns the configured domain matching questionName, or an empty
+// string if questionName is not the apex of one of our zones.
+func (d *DNSServer) zoneApex(questionName string) string {
+	name := strings.ToLower(dns.Fqdn(questionName))
+	switch {
+	case name == d.domain:
+		return d.domain
+	case d.altDomain != "." && name == d.altDomain:
+		return d.altDomain
+	default:
+		return ""
+	}
+}
+
+// apexSOA returns the SOA record for questionName. For the clients allowed to
+// transfer the zone, the serial of the zone apex is the catalog index, so that
+// secondaries are able to detect changes and request a transfer. Computing it
+// requires dumping the catalog, so other clients get the regular SOA.
+func (d *DNSServer) apexSOA(cfg *dnsConfig, remoteAddr net.Addr, questionName string) *dns.SOA {
+	if !zoneTransferAllowed(cfg, remoteAddr) {
+		return d.soa(cfg, questionName)
+	}
+
+	domain := d.zoneApex(questionName)
+	if domain == "" {
+		return d.soa(cfg, questionName)
+	}
+
+	snap, err := d.zoneSnapshot(cfg, domain)
+	if err != nil {
+		d.logger.Warn("unable to compute zone serial", "zone", domain, "error", err)
+		return d.soa(cfg, questionName)
+	}
+	return d.zoneSOA(cfg, snap)
+}
+
+// zoneSOA returns the SOA record for the zone snapshot.
+func (d *DNSServer) zoneSOA(cfg *dnsConfig, snap *zoneSnapshot) *dns.SOA {
+	soa := d.soa(cfg, snap.domain)
+	soa.Hdr.Name = snap.domain
+	soa.Ns = "ns." + snap.domain
+	soa.Mbox = "hostmaster." + snap.domain
+	soa.Serial = snap.serial
+	return soa
+}
+
+// zoneSnapshot builds the content of the zone for the local datacenter and
+// records it in the zone history. The serial of the zone is the Raft index
+// of the catalog, truncated to 32 bits.
+func (d *DNSServer) zoneSnapshot(cfg *dnsConfig, domain string) (*zoneSnapshot, error) {
+	out, err := d.zoneNodeDump(cfg, 0)
+	if err != nil {
+		return nil, err
+	}
+	return d.addZoneSnapshot(cfg, domain, out), nil
+}
+
+// zoneNodeDump returns the nodes of the local datacenter. A non zero
+// minIndex blocks until the catalog changes past that index.
+func (d *DNSServer) zoneNodeDump(cfg *dnsConfig, minIndex uint64) (*structs.IndexedNodeDump, error) {
+	args := structs.DCSpecificRequest{
+		Datacenter: cfg.Datacenter,
+		QueryOptions: structs.QueryOptions{
+			Token:         d.agent.tokens.UserToken(),
+			AllowStale:    cfg.AllowStale,
+			MinQueryIndex: minIndex,
+		},
+		EnterpriseMeta: d.defaultEnterpriseMeta,
+	}
+
+	var out structs.IndexedNodeDump
+RPC:
+	if err := d.agent.RPC(context.Background(), "Internal.NodeDump", &args, &out); err != nil {
+		return nil, fmt.Errorf("failed rpc request: %w", err)
+	}
+
+	// Verify that request is not too stale, redo the request
+	if args.AllowStale {
+		if out.LastContact > cfg.MaxStale {
+			args.AllowStale = false
+			d.logger.Warn("Query results too stale, re-requesting")
+			goto RPC
+		} else if out.LastContact > staleCounterThreshold {
+			metrics.IncrCounter([]string{"dns", "stale_queries"}, 1)
+		}
+	}
+	return &out, nil
+}
+
+// addZoneSnapshot builds the zone from the node dump and records it in the
+// zone history.
+func (d *DNSServer) addZoneSnapshot(cfg *dnsConfig, domain string, out *structs.IndexedNodeDump) *zoneSnapshot {
+	snap := &zoneSnapshot{
+		domain: domain,
+		serial: uint32(out.Index),
+		keys:   make(map[string]struct{}),
+	}
+	for _, rr := range d.zoneRecords(cfg, domain, out.Dump) {
+		key := rr.String()
+		if _, ok := snap.keys[key]; ok {
+			continue
+		}
+		snap.keys[key] = struct{}{}
+		snap.records = append(snap.records, rr)
+	}
+	sort.SliceStable(snap.records, func(i, j int) bool {
+		a, b := snap.records[i].Header(), snap.records[j].Header()
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.Rrtype != b.Rrtype {
+			return a.Rrtype < b.Rrtype
+		}
+		return snap.records[i].String() < snap.records[j].String()
+	})
+
+	d.zones.add(snap)
+	return snap
+}
+
+// startZoneWatch starts recording the zone each time the catalog changes, so
+// that IXFR requests receive the difference from the serial the client has
+// even when nobody transferred the zone in between. It is started by the
+// first transfer so agents without secondaries don't watch the catalog.
+//
+// Blocking queries return the latest state of the catalog, so changes
+// committed while a snapshot is being built are still merged into the next
+// serial: the history is only as fine-grained as the watch.
+func (d *DNSServer) startZoneWatch() {
+	if !atomic.CompareAndSwapUint32(&d.zoneWatchRunning, 0, 1) {
+		return
+	}
+	go d.watchZone()
+}
+
+func (d *DNSServer) watchZone() {
+	defer atomic.StoreUint32(&d.zoneWatchRunning, 0)
+
+	var index uint64
+	for {
+		cfg := d.config.Load().(*dnsConfig)
+		if len(cfg.ZoneTransferAllowFrom) == 0 {
+			// Zone transfers were disabled by a config reload.
+			return
+		}
+
+		out, err := d.zoneNodeDump(cfg, index)
+		if err != nil {
+			d.logger.Warn("unable to watch zone for transfer", "error", err)
+			select {
+			case <-d.agent.shutdownCh:
+				return
+			case <-time.After(zoneWatchRetryInterval):
+			}
+			continue
+		}
+
+		select {
+		case <-d.agent.shutdownCh:
+			return
+		default:
+		}
+
+		if out.Index < index {
+			// The index went backwards, e.g. after a snapshot restore.
+			index = 0
+			continue
+		}
+		if out.Index == index {
+			continue
+		}
+		index = out.Index
+
+		d.addZoneSnapshot(cfg, d.domain, out)
+		if d.altDomain != "." {
+			d.addZoneSnapshot(cfg, d.altDomain, out)
+		}
+	}
+}
+
+// zoneRecords returns the records of the zone for the nodes and services of
+// the local datacenter: NS records for the servers, node records and the A,
+// AAAA and SRV records of healthy service instances. Services pointing to an
+// external hostname are only published through their SRV records.
+func (d *DNSServer) zoneRecords(cfg *dnsConfig, domain string, dump structs.NodeDump) []dns.RR {
+	var (
+		records   []dns.RR
+		instances structs.CheckServiceNodes
+		servers   []string
+	)
+	dc := cfg.Datacenter
+
+	for _, info := range dump {
+		if agentdns.InvalidNameRe.MatchString(info.Node) {
+			d.logger.Warn("Skipping invalid node for zone transfer", "node", info.Node)
+			continue
+		}
+
+		node := &structs.Node{
+			ID:              info.ID,
+			Node:            info.Node,
+			Partition:       info.Partition,
+			Address:         info.Address,
+			Datacenter:      dc,
+			TaggedAddresses: info.TaggedAddresses,
+			Meta:            info.Meta,
+		}
+		nodeFQDN := strings.ToLower(nodeCanonicalDNSName(serviceLookup{Datacenter: dc}, node.Node, domain))
+
+		nodeAddr := d.agent.TranslateAddress(dc, node.Address, node.TaggedAddresses, TranslateAddressAcceptAny)
+		if ip := net.ParseIP(nodeAddr); ip != nil {
+			if rr := makeARecord(dns.TypeANY, ip, cfg.NodeTTL); rr != nil {
+				rr.Header().Name = nodeFQDN
+				records = append(records, rr)
+			}
+		} else if nodeAddr != "" {
+			records = append(records, &dns.CNAME{
+				Hdr: dns.RR_Header{
+					Name:   nodeFQDN,
+					Rrtype: dns.TypeCNAME,
+					Class:  dns.ClassINET,
+					Ttl:    uint32(cfg.NodeTTL / time.Second),
+				},
+				Target: dns.Fqdn(nodeAddr),
+			})
+		}
+		if cfg.NodeMetaTXT {
+			records = append(records, d.generateMeta(nodeFQDN, node, cfg.NodeTTL)...)
+		}
+
+		for _, svc := range info.Services {
+			var checks structs.HealthChecks
+			for _, c := range info.Checks {
+				if c.ServiceID == "" || c.ServiceID == svc.ID {
+					checks = append(checks, c)
+				}
+			}
+			instances = append(instances, structs.CheckServiceNode{
+				Node:    node,
+				Service: svc,
+				Checks:  checks,
+			})
+		}
+	}
+
+	for _, instance := range instances.Filter(cfg.OnlyPassing) {
+		svc := instance.Service
+		if agentdns.InvalidNameRe.MatchString(svc.Service) {
+			d.logger.Warn("Skipping invalid service for zone transfer", "service", svc.Service)
+			continue
+		}
+		if svc.Service == structs.ConsulServiceName {
+			servers = append(servers, instance.Node.Node)
+		}
+
+		name := strings.ToLower(fmt.Sprintf("%s.service.%s.%s", svc.Service, dc, domain))
+		ttl, _ := cfg.GetTTLForService(svc.Service)
+
+		serviceAddr := d.agent.TranslateServiceAddress(dc, svc.Address, svc.TaggedAddresses, TranslateAddressAcceptAny)
+		nodeAddr := d.agent.TranslateAddress(dc, instance.Node.Address, instance.Node.TaggedAddresses, TranslateAddressAcceptAny)
+
+		var target string
+		switch ip := net.ParseIP(serviceAddr); {
+		case serviceAddr == "":
+			target = strings.ToLower(nodeCanonicalDNSName(serviceLookup{Datacenter: dc}, instance.Node.Node, domain))
+			if nodeIP := net.ParseIP(nodeAddr); nodeIP != nil {
+				if rr := makeARecord(dns.TypeANY, nodeIP, ttl); rr != nil {
+					rr.Header().Name = name
+					records = append(records, rr)
+				}
+			}
+		case ip != nil:
+			target = d.encodeIPAsFqdn(domain, serviceLookup{Datacenter: dc}, ip)
+			for _, rrName := range []string{name, target} {
+				if rr := makeARecord(dns.TypeANY, ip, ttl); rr != nil {
+					rr.Header().Name = rrName
+					records = append(records, rr)
+				}
+			}
+		default:
+			target = dns.Fqdn(serviceAddr)
+		}
+
+		records = append(records, &dns.SRV{
+			Hdr: dns.RR_Header{
+				Name:   name,
+				Rrtype: dns.TypeSRV,
+				Class:  dns.ClassINET,
+				Ttl:    uint32(ttl / time.Second),
+			},
+			Priority: 1,
+			Weight:   uint16(findWeight(instance)),
+			Port:     uint16(d.agent.TranslateServicePort(dc, svc.Port, svc.TaggedAddresses)),
+			Target:   target,
+		})
+	}
+
+	// Unlike NS answers, the zone must not change between two transfers
+	// unless the catalog does, so pick the servers deterministically.
+	sort.Strings(servers)
+	for i, server := range servers {
+		if i == zoneNameservers {
+			break
+		}
+		records = append(records, &dns.NS{
+			Hdr: dns.RR_Header{
+				Name:   domain,
+				Rrtype: dns.TypeNS,
+				Class:  dns.ClassINET,
+				Ttl:    uint32(cfg.NodeTTL / time.Second),
+			},
+			Ns: strings.ToLower(nodeCanonicalDNSName(serviceLookup{Datacenter: dc}, server, domain)),
+		})
+	}
+
+	return records
+}
@@ -0,0 +1,38 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package exec
+
+import (
+	"regexp"
+)
+
+// SandboxConfig configures how processes started with Sandbox are isolated
+// from the host.
+type SandboxConfig struct {
+	// UID and GID are the host user and group the sandboxed process runs as.
+	// They are mapped to root in the user namespace of the process, so it has
+	// no more privileges on the host than this user. They cannot be root, and
+	// unless the agent runs as root they must be the user and group of the
+	// agent.
+	UID int
+	GID int
+
+	// CgroupParent is the cgroup v2 directory under which a cgroup is created
+	// for each sandboxed process. The cpu and memory controllers must be
+	// enabled in its cgroup.subtree_control when limits are configured. No
+	// cgroup is created when it is empty.
+	CgroupParent string
+
+	// CPUMax limits the CPU time of the process, in number of CPUs. Zero
+	// means no limit.
+	CPUMax float64
+
+	// MemoryMax limits the memory of the process, in bytes. Zero means no
+	// limit.
+	MemoryMax int64
+}
+
+// invalidCgroupChars matches the characters that are replaced in the name of
+// the cgroups created for sandboxed processes.
+var invalidCgroupChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]`)
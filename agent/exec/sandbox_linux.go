@@ -0,0 +1,275 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build linux
+// +build linux
+
+package exec
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// cpuPeriod is the period, in microseconds, used for the cpu.max limit.
+	cpuPeriod = 100000
+
+	// sandboxInitArg is the argv[0] the agent binary is re-executed with to
+	// set up the mounts of a sandbox before executing the sandboxed command.
+	sandboxInitArg = "consul-sandbox-init"
+)
+
+func init() {
+	if len(os.Args) > 0 && os.Args[0] == sandboxInitArg {
+		sandboxInit(os.Args[1:])
+	}
+}
+
+// sandboxInit runs in the namespaces of the sandbox, before the sandboxed
+// command. It stops mount events from propagating to the host, makes the
+// filesystem read-only, mounts a /proc matching the PID namespace and an empty
+// /tmp, then executes args[0] with the arguments args[1:]. It never returns.
+func sandboxInit(args []string) {
+	if err := sandboxMounts(); err != nil {
+		fmt.Fprintf(os.Stderr, "sandbox: %v\n", err)
+		os.Exit(126)
+	}
+	if len(args) < 2 {
+		fmt.Fprintln(os.Stderr, "sandbox: missing command")
+		os.Exit(126)
+	}
+	err := syscall.Exec(args[0], args[1:], os.Environ())
+	fmt.Fprintf(os.Stderr, "sandbox: failed to execute %s: %v\n", args[0], err)
+	os.Exit(127)
+}
+
+func sandboxMounts() error {
+	if err := unix.Mount("", "/", "", unix.MS_REC|unix.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("failed to make mounts private: %w", err)
+	}
+
+	// mount_setattr requires Linux 5.12, older kernels remount each mount
+	// read-only instead.
+	err := unix.MountSetattr(unix.AT_FDCWD, "/", unix.AT_RECURSIVE, &unix.MountAttr{
+		Attr_set: unix.MOUNT_ATTR_RDONLY,
+	})
+	if errors.Is(err, unix.ENOSYS) {
+		err = remountReadOnly()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to make the filesystem read-only: %w", err)
+	}
+
+	if err := unix.Mount("proc", "/proc", "proc", unix.MS_NOSUID|unix.MS_NODEV|unix.MS_NOEXEC, ""); err != nil {
+		return fmt.Errorf("failed to mount /proc: %w", err)
+	}
+	if err := unix.Mount("tmpfs", "/tmp", "tmpfs", unix.MS_NOSUID|unix.MS_NODEV, "mode=1777"); err != nil {
+		return fmt.Errorf("failed to mount /tmp: %w", err)
+	}
+	return nil
+}
+
+// lockedMountFlags are the flags of a mount that the kernel doesn't allow a
+// user namespace to clear, so they must be kept when remounting.
+var lockedMountFlags = map[string]uintptr{
+	"nosuid":      unix.MS_NOSUID,
+	"nodev":       unix.MS_NODEV,
+	"noexec":      unix.MS_NOEXEC,
+	"noatime":     unix.MS_NOATIME,
+	"nodiratime":  unix.MS_NODIRATIME,
+	"relatime":    unix.MS_RELATIME,
+	"strictatime": unix.MS_STRICTATIME,
+}
+
+// remountReadOnly makes every mount of the mount namespace read-only with a
+// bind remount.
+func remountReadOnly() error {
+	mountinfo, err := os.ReadFile("/proc/self/mountinfo")
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(mountinfo)), "\n") {
+		// The fifth field is the mount point and the sixth the options of
+		// the mount, see proc(5).
+		fields := strings.Fields(line)
+		if len(fields) < 6 {
+			return fmt.Errorf("invalid mountinfo line %q", line)
+		}
+		target := unescapeMountinfo(fields[4])
+
+		flags := uintptr(unix.MS_BIND | unix.MS_REMOUNT | unix.MS_RDONLY)
+		for _, opt := range strings.Split(fields[5], ",") {
+			flags |= lockedMountFlags[opt]
+		}
+		if err := unix.Mount("", target, "", flags, ""); err != nil {
+			return fmt.Errorf("failed to remount %s: %w", target, err)
+		}
+	}
+	return nil
+}
+
+// unescapeMountinfo decodes the octal escapes of the whitespace and
+// backslashes in a path of /proc/self/mountinfo.
+func unescapeMountinfo(s string) string {
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+4 <= len(s) {
+			if c, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// Sandboxed is a command configured by Sandbox.
+type Sandboxed struct {
+	cmd      *exec.Cmd
+	cgroup   string
+	cgroupFD *os.File
+}
+
+// Sandbox configures cmd to run in new user, mount and PID namespaces and,
+// if cfg.CgroupParent is set, in a dedicated cgroup with the configured CPU
+// and memory limits. In the mount namespace the filesystem of the host is
+// read-only, and /proc and /tmp are replaced. It must be called before cmd is
+// started, and Cleanup must be called once it has exited.
+func Sandbox(cmd *exec.Cmd, name string, cfg SandboxConfig) (*Sandboxed, error) {
+	if cfg.UID == 0 || cfg.GID == 0 {
+		return nil, fmt.Errorf("sandboxed processes cannot run as root")
+	}
+
+	// The mounts can only be set up from inside the namespaces, so the agent
+	// is re-executed to do it before executing the command.
+	self, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the agent executable: %w", err)
+	}
+	cmd.Args = append([]string{sandboxInitArg, cmd.Path}, cmd.Args...)
+	cmd.Path = self
+
+	attr := &syscall.SysProcAttr{
+		Setpgid:    true,
+		Cloneflags: syscall.CLONE_NEWUSER | syscall.CLONE_NEWNS | syscall.CLONE_NEWPID,
+		UidMappings: []syscall.SysProcIDMap{
+			{ContainerID: 0, HostID: cfg.UID, Size: 1},
+		},
+		GidMappings: []syscall.SysProcIDMap{
+			{ContainerID: 0, HostID: cfg.GID, Size: 1},
+		},
+		// setgroups must be denied for an unprivileged agent to be allowed
+		// to write the GID mapping.
+		GidMappingsEnableSetgroups: false,
+		// The process still has the IDs of the agent until it switches to
+		// the mapped root, which it needs to keep its capabilities in the
+		// namespace across exec.
+		Credential: &syscall.Credential{Uid: 0, Gid: 0, NoSetGroups: true},
+		// Don't leave the sandbox behind if the agent dies.
+		Pdeathsig: syscall.SIGKILL,
+	}
+	if os.Getuid() == 0 {
+		// Drop the supplementary groups of a root agent, they are not
+		// mapped in the namespace but still grant access to the host.
+		attr.GidMappingsEnableSetgroups = true
+		attr.Credential.NoSetGroups = false
+	}
+	cmd.SysProcAttr = attr
+
+	s := &Sandboxed{cmd: cmd}
+	if cfg.CgroupParent == "" {
+		return s, nil
+	}
+
+	dir, err := os.MkdirTemp(cfg.CgroupParent, invalidCgroupChars.ReplaceAllString(name, "_")+"-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cgroup: %w", err)
+	}
+	s.cgroup = dir
+
+	if cfg.CPUMax > 0 {
+		quota := int64(cfg.CPUMax * cpuPeriod)
+		if err := s.writeCgroup("cpu.max", fmt.Sprintf("%d %d", quota, cpuPeriod)); err != nil {
+			s.Cleanup()
+			return nil, err
+		}
+	}
+	if cfg.MemoryMax > 0 {
+		if err := s.writeCgroup("memory.max", strconv.FormatInt(cfg.MemoryMax, 10)); err != nil {
+			s.Cleanup()
+			return nil, err
+		}
+	}
+
+	fd, err := os.Open(dir)
+	if err != nil {
+		s.Cleanup()
+		return nil, fmt.Errorf("failed to open cgroup: %w", err)
+	}
+	s.cgroupFD = fd
+	attr.UseCgroupFD = true
+	attr.CgroupFD = int(fd.Fd())
+
+	return s, nil
+}
+
+func (s *Sandboxed) writeCgroup(file, value string) error {
+	if err := os.WriteFile(filepath.Join(s.cgroup, file), []byte(value), 0); err != nil {
+		return fmt.Errorf("failed to set %s of cgroup: %w", file, err)
+	}
+	return nil
+}
+
+// Kill kills every process of the sandbox. The process started by the command
+// is the init process of its PID namespace so the kernel kills the rest of the
+// namespace once it is gone, the cgroup is killed as well when supported so
+// that no process is left running until then.
+func (s *Sandboxed) Kill() error {
+	if s.cgroup != "" {
+		// cgroup.kill requires Linux 5.14, ignore the error on older kernels.
+		_ = s.writeCgroup("cgroup.kill", "1")
+	}
+	err := syscall.Kill(-s.cmd.Process.Pid, syscall.SIGKILL)
+	if errors.Is(err, syscall.ESRCH) {
+		return nil
+	}
+	return err
+}
+
+// Cleanup releases the resources of the sandbox once the command has exited.
+func (s *Sandboxed) Cleanup() error {
+	if s.cgroupFD != nil {
+		s.cgroupFD.Close()
+		s.cgroupFD = nil
+	}
+	if s.cgroup == "" {
+		return nil
+	}
+
+	// The cgroup can only be removed once the kernel is done killing the
+	// remaining processes of the PID namespace.
+	var err error
+	for i := 0; i < 10; i++ {
+		if err = os.Remove(s.cgroup); err == nil || os.IsNotExist(err) {
+			s.cgroup = ""
+			return nil
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return fmt.Errorf("failed to remove cgroup: %w", err)
+}
@@ -0,0 +1,30 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build !linux
+// +build !linux
+
+package exec
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// Sandboxed is a command configured by Sandbox.
+type Sandboxed struct{}
+
+// Sandbox is only supported on Linux.
+func Sandbox(cmd *exec.Cmd, name string, cfg SandboxConfig) (*Sandboxed, error) {
+	return nil, fmt.Errorf("sandboxed processes are only supported on Linux")
+}
+
+// Kill is a no-op outside of Linux.
+func (s *Sandboxed) Kill() error {
+	return nil
+}
+
+// Cleanup is a no-op outside of Linux.
+func (s *Sandboxed) Cleanup() error {
+	return nil
+}
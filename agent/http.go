@@ -23,12 +23,14 @@ import (
 	"github.com/armon/go-metrics"
 	"github.com/armon/go-metrics/prometheus"
 	"github.com/hashicorp/go-cleanhttp"
+	"github.com/hashicorp/go-uuid"
 	"github.com/mitchellh/mapstructure"
 	"github.com/pkg/errors"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
 	"github.com/hernad/consul/acl"
+	"github.com/hernad/consul/agent/audit"
 	"github.com/hernad/consul/agent/cache"
 	"github.com/hernad/consul/agent/config"
 	"github.com/hernad/consul/agent/consul"
@@ -357,6 +359,12 @@ func (s *HTTPHandlers) wrap(handler endpoint, methods []string) http.HandlerFunc
 		setTranslateAddr(resp, s.agent.config.TranslateWANAddrs)
 		setACLDefaultPolicy(resp, s.agent.config.ACLResolverSettings.ACLDefaultPolicy)
 
+		// The RPC requests made for the request are recorded with its ID.
+		if s.agent.baseDeps.Auditor != nil {
+			requestID, _ := uuid.GenerateUUID()
+			req = req.WithContext(audit.ContextWithRequestID(req.Context(), requestID))
+		}
+
 		// Obfuscate any tokens from appearing in the logs
 		formVals, err := url.ParseQuery(req.URL.RawQuery)
 		if err != nil {
@@ -556,6 +564,7 @@ func (s *HTTPHandlers) wrap(handler endpoint, methods []string) http.HandlerFunc
 				// Invoke the handler
 				obj, err = handler(resp, req)
 			}
+			s.auditRequest(req, isForbidden(err), err)
 		}
 		contentType := "application/json"
 		httpCode := http.StatusOK
@@ -596,6 +605,45 @@ func (s *HTTPHandlers) wrap(handler endpoint, methods []string) http.HandlerFunc
 	}
 }
 
+// auditRequest records the request in the audit log, if enabled. denied is
+// whether err is an ACL error.
+func (s *HTTPHandlers) auditRequest(req *http.Request, denied bool, err error) {
+	auditor := s.agent.baseDeps.Auditor
+	read := req.Method == http.MethodGet || req.Method == http.MethodHead
+	decision := audit.DecisionAllow
+	if denied {
+		decision = audit.DecisionDeny
+	}
+	if !auditor.Wants(audit.SourceHTTP, req.URL.Path, read, decision) {
+		return
+	}
+
+	var token string
+	s.parseToken(req, &token)
+	var accessorID string
+	if authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, nil, nil); err == nil {
+		accessorID = authz.AccessorID()
+	}
+
+	// The X-Request-Id header is recorded so the events can be correlated
+	// with the logs of a proxy in front of the agent, but any client can
+	// set it.
+	e := audit.Event{
+		RequestID:       audit.RequestIDFromContext(req.Context()),
+		Source:          audit.SourceHTTP,
+		AccessorID:      accessorID,
+		Operation:       req.Method,
+		Resource:        req.URL.Path,
+		Decision:        decision,
+		RemoteAddr:      req.RemoteAddr,
+		ClientRequestID: req.Header.Get("X-Request-Id"),
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	auditor.Log(e, read)
+}
+
 // marshalJSON marshals the object into JSON, respecting the user's pretty-ness
 // configuration.
 func (s *HTTPHandlers) marshalJSON(req *http.Request, obj interface{}) ([]byte, error) {
@@ -8,10 +8,15 @@ func init() {
 	registerEndpoint("/v1/acl/login", []string{"POST"}, (*HTTPHandlers).ACLLogin)
 	registerEndpoint("/v1/acl/logout", []string{"POST"}, (*HTTPHandlers).ACLLogout)
 	registerEndpoint("/v1/acl/replication", []string{"GET"}, (*HTTPHandlers).ACLReplicationStatus)
+	registerEndpoint("/v1/acl/authorize-explain", []string{"POST"}, (*HTTPHandlers).ACLAuthorizeExplain)
 	registerEndpoint("/v1/acl/policies", []string{"GET"}, (*HTTPHandlers).ACLPolicyList)
 	registerEndpoint("/v1/acl/policy", []string{"PUT"}, (*HTTPHandlers).ACLPolicyCreate)
 	registerEndpoint("/v1/acl/policy/", []string{"GET", "PUT", "DELETE"}, (*HTTPHandlers).ACLPolicyCRUD)
 	registerEndpoint("/v1/acl/policy/name/", []string{"GET"}, (*HTTPHandlers).ACLPolicyReadByName)
+	registerEndpoint("/v1/acl/policy-templates", []string{"GET"}, (*HTTPHandlers).ACLPolicyTemplateList)
+	registerEndpoint("/v1/acl/policy-template", []string{"PUT"}, (*HTTPHandlers).ACLPolicyTemplateCreate)
+	registerEndpoint("/v1/acl/policy-template/", []string{"GET", "PUT", "DELETE"}, (*HTTPHandlers).ACLPolicyTemplateCRUD)
+	registerEndpoint("/v1/acl/policy-template/name/", []string{"GET"}, (*HTTPHandlers).ACLPolicyTemplateReadByName)
 	registerEndpoint("/v1/acl/roles", []string{"GET"}, (*HTTPHandlers).ACLRoleList)
 	registerEndpoint("/v1/acl/role", []string{"PUT"}, (*HTTPHandlers).ACLRoleCreate)
 	registerEndpoint("/v1/acl/role/name/", []string{"GET"}, (*HTTPHandlers).ACLRoleReadByName)
@@ -25,6 +30,7 @@ func init() {
 	registerEndpoint("/v1/acl/tokens", []string{"GET"}, (*HTTPHandlers).ACLTokenList)
 	registerEndpoint("/v1/acl/token", []string{"PUT"}, (*HTTPHandlers).ACLTokenCreate)
 	registerEndpoint("/v1/acl/token/self", []string{"GET"}, (*HTTPHandlers).ACLTokenSelf)
+	registerEndpoint("/v1/acl/token/self/child", []string{"PUT"}, (*HTTPHandlers).ACLTokenCreateChild)
 	registerEndpoint("/v1/acl/token/", []string{"GET", "PUT", "DELETE"}, (*HTTPHandlers).ACLTokenCRUD)
 	registerEndpoint("/v1/agent/token/", []string{"PUT"}, (*HTTPHandlers).AgentToken)
 	registerEndpoint("/v1/agent/self", []string{"GET"}, (*HTTPHandlers).AgentSelf)
@@ -44,6 +50,7 @@ func init() {
 	registerEndpoint("/v1/agent/force-leave/", []string{"PUT"}, (*HTTPHandlers).AgentForceLeave)
 	registerEndpoint("/v1/agent/health/service/id/", []string{"GET"}, (*HTTPHandlers).AgentHealthServiceByID)
 	registerEndpoint("/v1/agent/health/service/name/", []string{"GET"}, (*HTTPHandlers).AgentHealthServiceByName)
+	registerEndpoint("/v1/agent/check/", []string{"GET"}, (*HTTPHandlers).AgentCheckHistory)
 	registerEndpoint("/v1/agent/check/register", []string{"PUT"}, (*HTTPHandlers).AgentRegisterCheck)
 	registerEndpoint("/v1/agent/check/deregister/", []string{"PUT"}, (*HTTPHandlers).AgentDeregisterCheck)
 	registerEndpoint("/v1/agent/check/pass/", []string{"PUT"}, (*HTTPHandlers).AgentCheckPass)
@@ -53,6 +60,8 @@ func init() {
 	registerEndpoint("/v1/agent/connect/authorize", []string{"POST"}, (*HTTPHandlers).AgentConnectAuthorize)
 	registerEndpoint("/v1/agent/connect/ca/roots", []string{"GET"}, (*HTTPHandlers).AgentConnectCARoots)
 	registerEndpoint("/v1/agent/connect/ca/leaf/", []string{"GET"}, (*HTTPHandlers).AgentConnectCALeafCert)
+	registerEndpoint("/v1/agent/connect/ca/jwt-svid/", []string{"PUT"}, (*HTTPHandlers).AgentConnectCAJWTSVID)
+	registerEndpoint("/v1/agent/xds/debug/", []string{"GET"}, (*HTTPHandlers).AgentXDSDebug)
 	registerEndpoint("/v1/agent/service/register", []string{"PUT"}, (*HTTPHandlers).AgentRegisterService)
 	registerEndpoint("/v1/agent/service/deregister/", []string{"PUT"}, (*HTTPHandlers).AgentDeregisterService)
 	registerEndpoint("/v1/agent/service/maintenance/", []string{"PUT"}, (*HTTPHandlers).AgentServiceMaintenance)
@@ -69,7 +78,11 @@ func init() {
 	registerEndpoint("/v1/config/", []string{"GET", "DELETE"}, (*HTTPHandlers).Config)
 	registerEndpoint("/v1/config", []string{"PUT"}, (*HTTPHandlers).ConfigApply)
 	registerEndpoint("/v1/connect/ca/configuration", []string{"GET", "PUT"}, (*HTTPHandlers).ConnectCAConfiguration)
+	registerEndpoint("/v1/connect/ca/jwks", []string{"GET"}, (*HTTPHandlers).ConnectCAJWKS)
+	registerEndpoint("/v1/connect/ca/revoke", []string{"PUT"}, (*HTTPHandlers).ConnectCARevoke)
+	registerEndpoint("/v1/connect/ca/revocation/", []string{"DELETE"}, (*HTTPHandlers).ConnectCARevocationDelete)
 	registerEndpoint("/v1/connect/ca/roots", []string{"GET"}, (*HTTPHandlers).ConnectCARoots)
+	registerEndpoint("/v1/connect/ca/spiffe-bundle", []string{"GET"}, (*HTTPHandlers).ConnectCASpiffeBundle)
 	registerEndpoint("/v1/connect/intentions", []string{"GET", "POST"}, (*HTTPHandlers).IntentionEndpoint) // POST is deprecated
 	registerEndpoint("/v1/connect/intentions/match", []string{"GET"}, (*HTTPHandlers).IntentionMatch)
 	registerEndpoint("/v1/connect/intentions/check", []string{"GET"}, (*HTTPHandlers).IntentionCheck)
@@ -30,6 +30,8 @@ import (
 	"github.com/stretchr/testify/require"
 	"golang.org/x/net/http2"
 
+	"github.com/hernad/consul/acl"
+	"github.com/hernad/consul/agent/audit"
 	"github.com/hernad/consul/agent/config"
 	"github.com/hernad/consul/agent/consul"
 	"github.com/hernad/consul/agent/structs"
@@ -468,6 +470,85 @@ func TestHTTPAPI_BlockEndpoints(t *testing.T) {
 	}
 }
 
+func TestHTTPAPI_AuditLog(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	dir := testutil.TempDir(t, "audit")
+	a := NewTestAgent(t, TestACLConfig()+`
+		audit {
+			enabled = true
+			rpc_enabled = true
+			sink "test" {
+				type = "file"
+				path = "`+filepath.Join(dir, "audit.json")+`"
+				endpoints = ["/v1/kv/", "KVS."]
+			}
+		}
+	`)
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	for _, token := range []string{"", "root"} {
+		req, _ := http.NewRequest("PUT", "/v1/kv/foo", strings.NewReader("bar"))
+		if token != "" {
+			req.Header.Add("X-Consul-Token", token)
+		}
+		req.Header.Add("X-Request-Id", "req-"+token)
+		resp := httptest.NewRecorder()
+		a.srv.h.ServeHTTP(resp, req)
+	}
+
+	// Reads are not recorded by default
+	req, _ := http.NewRequest("GET", "/v1/kv/foo?token=root", nil)
+	a.srv.h.ServeHTTP(httptest.NewRecorder(), req)
+
+	files, err := filepath.Glob(filepath.Join(dir, "audit-*.json"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	f, err := os.Open(files[0])
+	require.NoError(t, err)
+	defer f.Close()
+
+	var httpEvents, rpcEvents []audit.Event
+	dec := json.NewDecoder(f)
+	for dec.More() {
+		var e audit.Event
+		require.NoError(t, dec.Decode(&e))
+		if e.Source == audit.SourceHTTP {
+			httpEvents = append(httpEvents, e)
+		} else {
+			rpcEvents = append(rpcEvents, e)
+		}
+	}
+
+	require.Len(t, httpEvents, 2)
+	require.NotEmpty(t, httpEvents[0].RequestID)
+	require.NotEqual(t, httpEvents[0].RequestID, httpEvents[1].RequestID)
+	require.Equal(t, "req-", httpEvents[0].ClientRequestID)
+	require.Equal(t, "PUT", httpEvents[0].Operation)
+	require.Equal(t, "/v1/kv/foo", httpEvents[0].Resource)
+	require.Equal(t, audit.DecisionDeny, httpEvents[0].Decision)
+	require.Equal(t, acl.AnonymousTokenID, httpEvents[0].AccessorID)
+	require.Equal(t, "req-root", httpEvents[1].ClientRequestID)
+	require.Equal(t, audit.DecisionAllow, httpEvents[1].Decision)
+	require.NotEmpty(t, httpEvents[1].AccessorID)
+	require.NotEqual(t, acl.AnonymousTokenID, httpEvents[1].AccessorID)
+
+	require.Len(t, rpcEvents, 2)
+	for i, decision := range []string{audit.DecisionDeny, audit.DecisionAllow} {
+		require.Equal(t, "KVS.Apply", rpcEvents[i].Resource)
+		require.Equal(t, "write", rpcEvents[i].Operation)
+		require.Equal(t, decision, rpcEvents[i].Decision)
+		require.Equal(t, httpEvents[i].AccessorID, rpcEvents[i].AccessorID)
+		require.Equal(t, httpEvents[i].RequestID, rpcEvents[i].RequestID)
+		require.Empty(t, rpcEvents[i].ClientRequestID)
+	}
+}
+
 func TestHTTPAPI_Ban_Nonprintable_Characters(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
@@ -83,11 +83,12 @@ func (m *Manager) attemptLeafRefresh(
 		} else if roots == nil {
 			return nil, state, errors.New("no CA roots")
 		}
-		if activeRootHasKey(roots, state.authorityKeyID) {
+		if activeRootHasKey(roots, state.authorityKeyID) && roots.SerialRevoked(existing.SerialNumber) == nil {
 			return nil, state, nil
 		}
 
-		// if we reach here then the current leaf was not signed by the same CAs, just regen
+		// if we reach here then the current leaf was not signed by the same CAs
+		// or has been revoked, just regen
 		return m.generateNewLeaf(req, state, false)
 	}
 
@@ -145,6 +146,13 @@ func (m *Manager) attemptLeafRefresh(
 				return nil, state, errors.New("no CA roots")
 			}
 
+			// A revoked certificate is replaced right away, without waiting for
+			// the jitter used for root changes since only this certificate is
+			// affected.
+			if roots.SerialRevoked(existing.SerialNumber) != nil {
+				return m.generateNewLeaf(req, state, false)
+			}
+
 			// Handle _possibly_ changed roots. We still need to verify the new active
 			// root is not the same as the one our current cert was signed by since we
 			// can be notified spuriously if we are the first request since the
@@ -87,6 +87,71 @@ func TestManager_changingRoots(t *testing.T) {
 	}
 }
 
+// Tests that a revoked certificate is replaced as soon as the revocation is
+// distributed with the roots.
+func TestManager_revokedLeaf(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	// Use a long root change jitter to show that it doesn't apply.
+	m, signer := testManager(t, func(cfg *Config) {
+		cfg.TestOverrideCAChangeInitialDelay = time.Hour
+	})
+
+	caRoot := signer.UpdateCA(t, nil)
+
+	req := &ConnectCALeafRequest{
+		Datacenter: "dc1", Service: "web",
+		MinQueryIndex: 0, MaxQueryTime: 10 * time.Second,
+	}
+
+	// First fetch should return immediately
+	getCh := testAsyncGet(t, m, req)
+	var issued *structs.IssuedCert
+	var idx uint64
+	select {
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("shouldn't block waiting for fetch")
+	case result := <-getCh:
+		require.NoError(t, result.Err)
+		issued = result.Value
+		idx = result.Index
+	}
+
+	// Second fetch should block with set index
+	req.MinQueryIndex = idx
+	getCh = testAsyncGet(t, m, req)
+	select {
+	case result := <-getCh:
+		t.Fatalf("should not return: %#v", result)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Revoking the certificate doesn't change the active root but must still
+	// trigger a new certificate.
+	signer.RootsReader.Set(&structs.IndexedCARoots{
+		ActiveRootID: caRoot.ID,
+		TrustDomain:  connect.TestTrustDomain,
+		Roots:        []*structs.CARoot{caRoot},
+		Revocations: []*structs.CARevocation{
+			{ID: "rev1", SerialNumber: issued.SerialNumber},
+		},
+		QueryMeta: structs.QueryMeta{Index: signer.nextIndex()},
+	})
+	select {
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("shouldn't block waiting for fetch")
+	case result := <-getCh:
+		require.NoError(t, result.Err)
+		require.True(t, result.Index > idx)
+		require.NotEqual(t, issued.SerialNumber, result.Value.SerialNumber)
+		requireLeafValidUnderCA(t, result.Value, caRoot)
+	}
+}
+
 // Tests that if the root change jitter is longer than the time left on the
 // timeout, we return normally but then still renew the cert on a subsequent
 // call.
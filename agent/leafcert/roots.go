@@ -138,8 +138,10 @@ func (r *rootWatcher) rootWatcher(ctx context.Context) {
 			// The Fetch calls will also validate this since the first call here we
 			// don't know if it changed or not, but there is no point waking up all
 			// Fetch calls to check this if we know none of them will need to act on
-			// this update.
-			if oldRoots != nil && oldRoots.ActiveRootID == roots.ActiveRootID {
+			// this update. Changes to the revocations are distributed too since
+			// they can revoke the certificate of an inflight request.
+			if oldRoots != nil && oldRoots.ActiveRootID == roots.ActiveRootID &&
+				sameRevocations(oldRoots.Revocations, roots.Revocations) {
 				continue
 			}
 
@@ -150,3 +152,16 @@ func (r *rootWatcher) rootWatcher(ctx context.Context) {
 		}
 	}
 }
+
+// sameRevocations returns true if both lists contain the same revocations.
+func sameRevocations(a, b []*structs.CARevocation) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].ID != b[i].ID || a[i].ModifyIndex != b[i].ModifyIndex {
+			return false
+		}
+	}
+	return true
+}
@@ -65,6 +65,21 @@ type Config struct {
 	NodeLocality        *structs.Locality
 	Partition           string // this defaults if empty
 	TaggedAddresses     map[string]string
+
+	// CheckHistorySize is the number of status transitions kept for each
+	// check.
+	CheckHistorySize int
+
+	// CheckFlapWindow, CheckFlapStartThreshold and CheckFlapStopThreshold
+	// configure the detection of flapping checks, see CheckState.Flapping.
+	// A zero CheckFlapWindow disables it.
+	CheckFlapWindow         time.Duration
+	CheckFlapStartThreshold int
+	CheckFlapStopThreshold  int
+
+	// CheckFlapStatus is the status reported for flapping checks when it is
+	// worse than their own status. Empty leaves the status unchanged.
+	CheckFlapStatus string
 }
 
 // ServiceState describes the state of a service record.
@@ -136,6 +151,24 @@ type CheckState struct {
 	// IsLocallyDefined indicates whether the check was defined locally in config
 	// as opposed to being registered through the Agent API.
 	IsLocallyDefined bool
+
+	// History contains the most recent status transitions of the health
+	// check, oldest first. The slice is replaced rather than modified so it
+	// can be shared between clones.
+	History []CheckTransition
+
+	// Flapping is true when the health check changed status at least
+	// CheckFlapStartThreshold times within CheckFlapWindow. It stays true
+	// until the number of changes in the window drops to
+	// CheckFlapStopThreshold.
+	Flapping bool
+}
+
+// CheckTransition is a status change of a health check.
+type CheckTransition struct {
+	Status string
+	Output string
+	Time   time.Time
 }
 
 // Clone returns a shallow copy of the object.
@@ -200,8 +233,11 @@ type State struct {
 	services map[structs.ServiceID]*ServiceState
 
 	// Checks tracks the local checks. checkAliases are aliased checks.
-	checks       map[structs.CheckID]*CheckState
-	checkAliases map[structs.ServiceID]map[structs.CheckID]chan<- struct{}
+	// checkComposites are composite checks, indexed by the checks they
+	// combine.
+	checks          map[structs.CheckID]*CheckState
+	checkAliases    map[structs.ServiceID]map[structs.CheckID]chan<- struct{}
+	checkComposites map[structs.CheckID]map[structs.CheckID]chan<- struct{}
 
 	// metadata tracks the node metadata fields
 	metadata map[string]string
@@ -229,6 +265,7 @@ func NewState(c Config, logger hclog.Logger, tokens *token.Store) *State {
 		services:            make(map[structs.ServiceID]*ServiceState),
 		checks:              make(map[structs.CheckID]*CheckState),
 		checkAliases:        make(map[structs.ServiceID]map[structs.CheckID]chan<- struct{}),
+		checkComposites:     make(map[structs.CheckID]map[structs.CheckID]chan<- struct{}),
 		metadata:            make(map[string]string),
 		tokens:              tokens,
 		notifyHandlers:      make(map[chan<- struct{}]struct{}),
@@ -637,6 +674,42 @@ func (l *State) RemoveAliasCheck(checkID structs.CheckID, srcServiceID structs.S
 	}
 }
 
+// AddCompositeCheck creates a composite check. When any of the srcCheckIDs is
+// changed, notifyCh is notified so that checkID can reflect that using the
+// semantics of checks.CheckComposite.
+func (l *State) AddCompositeCheck(checkID structs.CheckID, srcCheckIDs []structs.CheckID, notifyCh chan<- struct{}) error {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.agentEnterpriseMeta.PartitionOrDefault() != checkID.PartitionOrDefault() {
+		return fmt.Errorf("cannot add composite check ID %q to node in partition %q", checkID.String(), l.config.Partition)
+	}
+
+	for _, srcCheckID := range srcCheckIDs {
+		m, ok := l.checkComposites[srcCheckID]
+		if !ok {
+			m = make(map[structs.CheckID]chan<- struct{})
+			l.checkComposites[srcCheckID] = m
+		}
+		m[checkID] = notifyCh
+	}
+
+	return nil
+}
+
+// RemoveCompositeCheck removes the mappings for the composite check.
+func (l *State) RemoveCompositeCheck(checkID structs.CheckID) {
+	l.Lock()
+	defer l.Unlock()
+
+	for srcCheckID, m := range l.checkComposites {
+		delete(m, checkID)
+		if len(m) == 0 {
+			delete(l.checkComposites, srcCheckID)
+		}
+	}
+}
+
 // RemoveCheck is used to remove a health check from the local state.
 // The agent will make a best effort to ensure it is deregistered
 // todo(fs): RemoveService returns an error for a non-existent service. RemoveCheck should as well.
@@ -655,6 +728,7 @@ func (l *State) removeCheckLocked(id structs.CheckID) error {
 
 	// If this is a check for an aliased service, then notify the waiters.
 	l.notifyIfAliased(c.Check.CompoundServiceID())
+	l.notifyIfComposed(c.Check.CompoundCheckID())
 
 	// To remove the check on the server we need the token.
 	// Therefore, we mark the service as deleted and keep the
@@ -680,6 +754,10 @@ func (l *State) UpdateCheck(id structs.CheckID, status, output string) {
 		output = ""
 	}
 
+	// Record the transition before the idempotency check below, since a
+	// flapping check can settle without its status or output changing.
+	status, output = l.recordCheckTransitionLocked(c, status, output)
+
 	// Update the critical time tracking (this doesn't cause a server updates
 	// so we can always keep this up to date).
 	if status == api.HealthCritical {
@@ -737,6 +815,7 @@ func (l *State) UpdateCheck(id structs.CheckID, status, output string) {
 
 	// If this is a check for an aliased service, then notify the waiters.
 	l.notifyIfAliased(c.Check.CompoundServiceID())
+	l.notifyIfComposed(c.Check.CompoundCheckID())
 
 	// Update status and mark out of sync
 	c.Check.Status = status
@@ -745,6 +824,97 @@ func (l *State) UpdateCheck(id structs.CheckID, status, output string) {
 	l.TriggerSyncChanges()
 }
 
+// recordCheckTransitionLocked appends a transition to the history of the
+// check if the status changed and updates whether the check is flapping. It
+// returns the status and output to report for the check, which differ from
+// the given ones when the check is flapping and CheckFlapStatus is set.
+func (l *State) recordCheckTransitionLocked(c *CheckState, status, output string) (string, string) {
+	now := time.Now()
+
+	if size := l.config.CheckHistorySize; size > 0 {
+		if n := len(c.History); n == 0 || c.History[n-1].Status != status {
+			keep := c.History
+			if len(keep) >= size {
+				keep = keep[len(keep)-size+1:]
+			}
+			history := make([]CheckTransition, len(keep), len(keep)+1)
+			copy(history, keep)
+			c.History = append(history, CheckTransition{Status: status, Output: output, Time: now})
+		}
+	}
+
+	if l.config.CheckFlapWindow <= 0 {
+		c.Flapping = false
+		return status, output
+	}
+
+	// The first entry is the initial status of the check, not a transition.
+	var transitions int
+	for i := len(c.History) - 1; i > 0; i-- {
+		if now.Sub(c.History[i].Time) > l.config.CheckFlapWindow {
+			break
+		}
+		transitions++
+	}
+	switch {
+	case !c.Flapping && transitions >= l.config.CheckFlapStartThreshold:
+		c.Flapping = true
+	case c.Flapping && transitions <= l.config.CheckFlapStopThreshold:
+		c.Flapping = false
+	}
+
+	if c.Flapping && l.config.CheckFlapStatus != "" {
+		if healthStatusRank(l.config.CheckFlapStatus) > healthStatusRank(status) {
+			status = l.config.CheckFlapStatus
+		}
+		output = "Check is flapping. " + output
+	}
+	return status, output
+}
+
+// RestoreCheckHistory replaces the history of a check with the given
+// transitions, for example the history persisted before an agent restart,
+// and recomputes whether the check is flapping. The current status of the
+// check is appended when it differs from the last restored transition.
+func (l *State) RestoreCheckHistory(id structs.CheckID, history []CheckTransition, flapping bool) {
+	l.Lock()
+	defer l.Unlock()
+
+	c := l.checks[id]
+	if c == nil || c.Deleted || len(history) == 0 {
+		return
+	}
+
+	c = c.Clone()
+	if size := l.config.CheckHistorySize; len(history) > size {
+		history = history[len(history)-size:]
+	}
+	c.History = make([]CheckTransition, len(history))
+	copy(c.History, history)
+	c.Flapping = flapping
+
+	status, output := l.recordCheckTransitionLocked(c, c.Check.Status, c.Check.Output)
+	if c.Check.Status != status || c.Check.Output != output {
+		c.Check.Status = status
+		c.Check.Output = output
+		c.InSync = false
+		l.TriggerSyncChanges()
+	}
+	l.checks[id] = c
+}
+
+// healthStatusRank orders the check statuses from best to worst.
+func healthStatusRank(status string) int {
+	switch status {
+	case api.HealthPassing:
+		return 0
+	case api.HealthWarning:
+		return 1
+	default:
+		return 2
+	}
+}
+
 // Check returns the locally registered check that the
 // agent is aware of and are being kept in sync with the server
 func (l *State) Check(id structs.CheckID) *structs.HealthCheck {
@@ -838,12 +1008,19 @@ func (l *State) setCheckStateLocked(c *CheckState) {
 	existing := l.checks[id]
 	if existing != nil {
 		c.InSync = c.Check.IsSame(existing.Check)
+		if c.History == nil {
+			c.History, c.Flapping = existing.History, existing.Flapping
+		}
+	}
+	if len(c.History) == 0 {
+		l.recordCheckTransitionLocked(c, c.Check.Status, c.Check.Output)
 	}
 
 	l.checks[id] = c
 
 	// If this is a check for an aliased service, then notify the waiters.
 	l.notifyIfAliased(c.Check.CompoundServiceID())
+	l.notifyIfComposed(c.Check.CompoundCheckID())
 
 	l.TriggerSyncChanges()
 }
@@ -1617,6 +1794,19 @@ func (l *State) notifyIfAliased(serviceID structs.ServiceID) {
 	}
 }
 
+// notifyIfComposed will notify the composite checks combining a check of its
+// changes.
+func (l *State) notifyIfComposed(checkID structs.CheckID) {
+	for _, notifyCh := range l.checkComposites[checkID] {
+		// Do not block, see notifyIfAliased. This must be called with the
+		// lock held.
+		select {
+		case notifyCh <- struct{}{}:
+		default:
+		}
+	}
+}
+
 // aclAccessorID is used to convert an ACLToken's secretID to its accessorID for non-
 // critical purposes, such as logging. Therefore we interpret all errors as empty-string
 // so we can safely log it without handling non-critical errors at the usage site.
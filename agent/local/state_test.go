@@ -2264,6 +2264,182 @@ func TestAgent_AliasCheck(t *testing.T) {
 	}
 }
 
+func TestAgent_CompositeCheck(t *testing.T) {
+	t.Parallel()
+
+	cfg := loadRuntimeConfig(t, `bind_addr = "127.0.0.1" data_dir = "dummy" node_name = "dummy"`)
+	l := local.NewState(agent.LocalConfig(cfg), nil, new(token.Store))
+	l.TriggerSyncChanges = func() {}
+
+	require.NoError(t, l.AddCheck(&structs.HealthCheck{CheckID: types.CheckID("c1")}, "", false))
+	require.NoError(t, l.AddCheck(&structs.HealthCheck{CheckID: types.CheckID("c2")}, "", false))
+
+	// Add a composite check combining c1 only
+	compositeID := structs.NewCheckID(types.CheckID("composite"), nil)
+	notifyCh := make(chan struct{}, 1)
+	require.NoError(t, l.AddCompositeCheck(compositeID, []structs.CheckID{structs.NewCheckID("c1", nil)}, notifyCh))
+
+	// Update and verify we get notified
+	l.UpdateCheck(structs.NewCheckID(types.CheckID("c1"), nil), api.HealthCritical, "")
+	select {
+	case <-notifyCh:
+	default:
+		t.Fatal("notify not received")
+	}
+
+	// Update other check and verify we do not get notified
+	l.UpdateCheck(structs.NewCheckID(types.CheckID("c2"), nil), api.HealthCritical, "")
+	select {
+	case <-notifyCh:
+		t.Fatal("notify received")
+	default:
+	}
+
+	// Remove the combined check and verify we get notified
+	require.NoError(t, l.RemoveCheck(structs.NewCheckID(types.CheckID("c1"), nil)))
+	select {
+	case <-notifyCh:
+	default:
+		t.Fatal("notify not received")
+	}
+
+	// Verify we are not notified anymore once the composite check is removed
+	l.RemoveCompositeCheck(compositeID)
+	require.NoError(t, l.AddCheck(&structs.HealthCheck{CheckID: types.CheckID("c1")}, "", false))
+	select {
+	case <-notifyCh:
+		t.Fatal("notify received")
+	default:
+	}
+}
+
+func TestAgent_CheckHistory(t *testing.T) {
+	t.Parallel()
+
+	cfg := loadRuntimeConfig(t, `bind_addr = "127.0.0.1" data_dir = "dummy" node_name = "dummy" check_history_size = 3`)
+	l := local.NewState(agent.LocalConfig(cfg), nil, new(token.Store))
+	l.TriggerSyncChanges = func() {}
+
+	require.NoError(t, l.AddCheck(&structs.HealthCheck{CheckID: "c1", Status: api.HealthCritical}, "", false))
+	id := structs.NewCheckID("c1", nil)
+
+	statuses := func() []string {
+		var out []string
+		for _, t := range l.CheckState(id).History {
+			out = append(out, t.Status)
+		}
+		return out
+	}
+	require.Equal(t, []string{api.HealthCritical}, statuses())
+
+	// Only status changes are recorded
+	l.UpdateCheck(id, api.HealthCritical, "still down")
+	l.UpdateCheck(id, api.HealthPassing, "up")
+	l.UpdateCheck(id, api.HealthPassing, "still up")
+	require.Equal(t, []string{api.HealthCritical, api.HealthPassing}, statuses())
+	require.Equal(t, "up", l.CheckState(id).History[1].Output)
+
+	// The oldest transitions are dropped
+	l.UpdateCheck(id, api.HealthWarning, "")
+	l.UpdateCheck(id, api.HealthCritical, "")
+	require.Equal(t, []string{api.HealthPassing, api.HealthWarning, api.HealthCritical}, statuses())
+
+	// The history is kept when the check is updated
+	require.NoError(t, l.AddCheck(&structs.HealthCheck{CheckID: "c1", Status: api.HealthCritical, Notes: "updated"}, "", false))
+	require.Equal(t, []string{api.HealthPassing, api.HealthWarning, api.HealthCritical}, statuses())
+	require.False(t, l.CheckState(id).Flapping)
+}
+
+func TestAgent_CheckFlapping(t *testing.T) {
+	t.Parallel()
+
+	cfg := loadRuntimeConfig(t, `bind_addr = "127.0.0.1" data_dir = "dummy" node_name = "dummy"
+		check_flap_detection {
+			window = "1h"
+			start_threshold = 3
+			stop_threshold = 1
+			status = "warning"
+		}`)
+	l := local.NewState(agent.LocalConfig(cfg), nil, new(token.Store))
+	l.TriggerSyncChanges = func() {}
+
+	require.NoError(t, l.AddCheck(&structs.HealthCheck{CheckID: "c1", Status: api.HealthPassing}, "", false))
+	id := structs.NewCheckID("c1", nil)
+
+	l.UpdateCheck(id, api.HealthCritical, "down")
+	l.UpdateCheck(id, api.HealthPassing, "up")
+	require.False(t, l.CheckState(id).Flapping)
+	require.Equal(t, api.HealthPassing, l.Check(id).Status)
+
+	// The third transition within the window starts flapping, and the
+	// passing check is reported with the flapping status.
+	l.UpdateCheck(id, api.HealthCritical, "down")
+	l.UpdateCheck(id, api.HealthPassing, "up")
+	c := l.CheckState(id)
+	require.True(t, c.Flapping)
+	require.Equal(t, api.HealthWarning, c.Check.Status)
+	require.Equal(t, "Check is flapping. up", c.Check.Output)
+
+	// A worse status is not hidden by the flapping status
+	l.UpdateCheck(id, api.HealthCritical, "down")
+	require.Equal(t, api.HealthCritical, l.Check(id).Status)
+
+	// The check stops flapping once the transitions leave the window
+	c = l.CheckState(id)
+	history := make([]local.CheckTransition, len(c.History))
+	for i, t := range c.History {
+		t.Time = t.Time.Add(-2 * time.Hour)
+		history[i] = t
+	}
+	c.History = history
+	l.SetCheckState(c)
+	l.UpdateCheck(id, api.HealthPassing, "up")
+	c = l.CheckState(id)
+	require.False(t, c.Flapping)
+	require.Equal(t, api.HealthPassing, c.Check.Status)
+	require.Equal(t, "up", c.Check.Output)
+}
+
+func TestAgent_RestoreCheckHistory(t *testing.T) {
+	t.Parallel()
+
+	cfg := loadRuntimeConfig(t, `bind_addr = "127.0.0.1" data_dir = "dummy" node_name = "dummy"
+		check_flap_detection {
+			window = "1h"
+			start_threshold = 3
+			stop_threshold = 1
+			status = "warning"
+		}`)
+	l := local.NewState(agent.LocalConfig(cfg), nil, new(token.Store))
+	l.TriggerSyncChanges = func() {}
+
+	require.NoError(t, l.AddCheck(&structs.HealthCheck{CheckID: "c1", Status: api.HealthPassing, Output: "up"}, "", false))
+	id := structs.NewCheckID("c1", nil)
+
+	now := time.Now()
+	history := []local.CheckTransition{
+		{Status: api.HealthPassing, Time: now.Add(-4 * time.Minute)},
+		{Status: api.HealthCritical, Time: now.Add(-3 * time.Minute)},
+		{Status: api.HealthPassing, Time: now.Add(-2 * time.Minute)},
+		{Status: api.HealthCritical, Time: now.Add(-time.Minute)},
+	}
+	l.RestoreCheckHistory(id, history, false)
+
+	// The current status is appended to the restored history, which makes
+	// the check flapping again.
+	c := l.CheckState(id)
+	require.Len(t, c.History, 5)
+	require.Equal(t, history[:4], c.History[:4])
+	require.Equal(t, api.HealthPassing, c.History[4].Status)
+	require.True(t, c.Flapping)
+	require.Equal(t, api.HealthWarning, c.Check.Status)
+	require.Equal(t, "Check is flapping. up", c.Check.Output)
+
+	// Unknown checks are ignored
+	l.RestoreCheckHistory(structs.NewCheckID("c2", nil), history, true)
+	require.Nil(t, l.CheckState(structs.NewCheckID("c2", nil)))
+}
+
 func TestAgent_AliasCheck_ServiceNotification(t *testing.T) {
 	t.Parallel()
 
@@ -484,6 +484,11 @@ func assertWatchChanRecvs(t *testing.T, ch <-chan *ConfigSnapshot, expect *Confi
 
 	select {
 	case got, ok := <-ch:
+		if expect != nil && got != nil {
+			// Config versions are shared by all the proxies of the agent.
+			require.NotZero(t, got.ConfigVersion)
+			expect.ConfigVersion = got.ConfigVersion
+		}
 		require.Equal(t, expect, got)
 		if expect == nil {
 			require.False(t, ok, "watch chan should be closed")
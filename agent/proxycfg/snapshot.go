@@ -939,6 +939,12 @@ type ConfigSnapshot struct {
 	ServerSNIFn ServerSNIFunc
 	Roots       *structs.IndexedCARoots
 
+	// ConfigVersion changes every time the snapshot changes in a way that is
+	// not limited to the endpoints of its upstreams: two snapshots of a proxy
+	// with the same ConfigVersion only differ by their endpoints. It is unique
+	// across all the proxies of the agent.
+	ConfigVersion uint64
+
 	// connect-proxy specific
 	ConnectProxy configSnapshotConnectProxy
 
@@ -1079,6 +1085,33 @@ func (s *ConfigSnapshot) RootPEMs() string {
 	return rootPEMs
 }
 
+// RevocationListPEMs returns the revocation lists of the leaf signing keys of
+// every datacenter, or an empty string when the servers don't distribute them.
+func (s *ConfigSnapshot) RevocationListPEMs() string {
+	if s.Roots == nil {
+		return ""
+	}
+	var pems string
+	for _, l := range s.Roots.RevocationLists {
+		pems += lib.EnsureTrailingNewline(l.PEM)
+	}
+	return pems
+}
+
+// SpiffeIDTemplates returns the SPIFFE ID templates of the federated trust
+// domains. The templates are validated when the CA configuration is written
+// so none are returned if they fail to parse here.
+func (s *ConfigSnapshot) SpiffeIDTemplates() connect.SpiffeIDTemplates {
+	if s.Roots == nil {
+		return nil
+	}
+	templates, err := connect.NewSpiffeIDTemplates(s.Roots.FederatedTrustDomains)
+	if err != nil {
+		return nil
+	}
+	return templates
+}
+
 func (s *ConfigSnapshot) MeshConfig() *structs.MeshConfigEntry {
 	switch s.Kind {
 	case structs.ServiceKindConnectProxy:
@@ -10,6 +10,7 @@ import (
 	"net"
 	"reflect"
 	"runtime/debug"
+	"strings"
 	"sync/atomic"
 	"time"
 
@@ -60,6 +61,11 @@ const (
 	defaultPreparedQueryPollInterval   = 30 * time.Second
 )
 
+// configVersion is the last ConfigSnapshot.ConfigVersion handed out. It is
+// shared by all the proxies so that the version of a proxy never goes back
+// when its state is recreated.
+var configVersion atomic.Uint64
+
 type stateConfig struct {
 	logger                hclog.Logger
 	source                *structs.QuerySource
@@ -350,6 +356,8 @@ func (s *state) unsafeRun(ctx context.Context, snap *ConfigSnapshot) {
 	sendCh := make(chan struct{})
 	var coalesceTimer *time.Timer
 
+	snap.ConfigVersion = configVersion.Add(1)
+
 	scheduleUpdate := func() {
 		// Wait for MAX(<rate limiter delay>, coalesceTimeout)
 		delay := s.rateLimiter.Reserve().Delay()
@@ -386,6 +394,9 @@ func (s *state) unsafeRun(ctx context.Context, snap *ConfigSnapshot) {
 				)
 				continue
 			}
+			if !s.endpointsOnlyUpdate(u) {
+				snap.ConfigVersion = configVersion.Add(1)
+			}
 
 		case <-sendCh:
 			// Allow the next change to trigger a send
@@ -446,6 +457,23 @@ func (s *state) unsafeRun(ctx context.Context, snap *ConfigSnapshot) {
 	}
 }
 
+// endpointsOnlyUpdate returns whether the update only changes the endpoints of
+// an upstream target. Transparent proxies also derive their passthrough
+// upstreams and listeners from the endpoints, so none of their updates are.
+func (s *state) endpointsOnlyUpdate(u UpdateEvent) bool {
+	if !strings.HasPrefix(u.CorrelationID, "upstream-target:") {
+		return false
+	}
+	switch s.serviceInstance.kind {
+	case structs.ServiceKindConnectProxy:
+		return s.serviceInstance.proxyCfg.Mode != structs.ProxyModeTransparent
+	case structs.ServiceKindIngressGateway, structs.ServiceKindAPIGateway:
+		return true
+	default:
+		return false
+	}
+}
+
 // CurrentSnapshot synchronously returns the current ConfigSnapshot if there is
 // one ready. If we don't have one yet because not all necessary parts have been
 // returned (i.e. both roots and leaf cert), nil is returned.
@@ -3989,3 +3989,30 @@ func Test_hostnameEndpoints(t *testing.T) {
 }
 
 const aclToken = "foo"
+
+func TestState_endpointsOnlyUpdate(t *testing.T) {
+	targetUpdate := UpdateEvent{CorrelationID: "upstream-target:db.default.default.dc1:db"}
+	rootsUpdate := UpdateEvent{CorrelationID: rootsWatchID}
+
+	cases := map[string]struct {
+		kind   structs.ServiceKind
+		mode   structs.ProxyMode
+		update UpdateEvent
+		expect bool
+	}{
+		"connect proxy target":             {structs.ServiceKindConnectProxy, structs.ProxyModeDefault, targetUpdate, true},
+		"connect proxy roots":              {structs.ServiceKindConnectProxy, structs.ProxyModeDefault, rootsUpdate, false},
+		"transparent connect proxy target": {structs.ServiceKindConnectProxy, structs.ProxyModeTransparent, targetUpdate, false},
+		"ingress gateway target":           {structs.ServiceKindIngressGateway, structs.ProxyModeDefault, targetUpdate, true},
+		"mesh gateway target":              {structs.ServiceKindMeshGateway, structs.ProxyModeDefault, targetUpdate, false},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := &state{serviceInstance: serviceInstance{
+				kind:     tc.kind,
+				proxyCfg: structs.ConnectProxyConfig{Mode: tc.mode},
+			}}
+			require.Equal(t, tc.expect, s.endpointsOnlyUpdate(tc.update))
+		})
+	}
+}
@@ -42,6 +42,10 @@ type Router struct {
 	// managers for that datacenter. This is used to quickly lookup routes.
 	managers map[string][]*Manager
 
+	// datacentersCh is closed and replaced when a datacenter is added to or
+	// removed from managers.
+	datacentersCh chan struct{}
+
 	// routeFn is a hook to actually do the routing.
 	routeFn func(datacenter string) (*Manager, *metadata.Server, bool)
 
@@ -109,6 +113,7 @@ func NewRouter(logger hclog.Logger, localDatacenter, serverName string, tracker
 		serverName:        serverName,
 		areas:             make(map[types.AreaID]*areaInfo),
 		managers:          make(map[string][]*Manager),
+		datacentersCh:     make(chan struct{}),
 		grpcServerTracker: tracker,
 	}
 
@@ -213,6 +218,7 @@ func (r *Router) removeManagerFromIndex(datacenter string, manager *Manager) {
 			r.managers[datacenter] = append(managers[:i], managers[i+1:]...)
 			if len(r.managers[datacenter]) == 0 {
 				delete(r.managers, datacenter)
+				r.notifyDatacenters()
 			}
 			return
 		}
@@ -273,6 +279,9 @@ func (r *Router) maybeInitializeManager(area *areaInfo, dc string) *Manager {
 
 	managers := r.managers[dc]
 	r.managers[dc] = append(managers, manager)
+	if len(managers) == 0 {
+		r.notifyDatacenters()
+	}
 	go manager.Run()
 
 	return manager
@@ -462,6 +471,28 @@ func (r *Router) GetDatacenters() []string {
 	return dcs
 }
 
+// GetDatacentersWatch returns the same list as GetDatacenters, along with a
+// channel closed when the list changes.
+func (r *Router) GetDatacentersWatch() ([]string, <-chan struct{}) {
+	r.RLock()
+	defer r.RUnlock()
+
+	dcs := make([]string, 0, len(r.managers))
+	for dc := range r.managers {
+		dcs = append(dcs, dc)
+	}
+
+	sort.Strings(dcs)
+	return dcs, r.datacentersCh
+}
+
+// notifyDatacenters wakes up the watchers of the list of datacenters. This
+// assumes the lock is already held for writing.
+func (r *Router) notifyDatacenters() {
+	close(r.datacentersCh)
+	r.datacentersCh = make(chan struct{})
+}
+
 // GetRemoteDatacenters returns a list of remote datacenters known to the router, sorted by
 // name.
 func (r *Router) GetRemoteDatacenters(local string) []string {
@@ -15,6 +15,7 @@ import (
 	"github.com/hashicorp/serf/coordinate"
 	"github.com/hashicorp/serf/serf"
 
+	"github.com/hernad/consul/agent/metadata"
 	"github.com/hernad/consul/agent/structs"
 	"github.com/hernad/consul/lib"
 	"github.com/hernad/consul/sdk/testutil"
@@ -390,6 +391,43 @@ func TestRouter_GetDatacenters(t *testing.T) {
 	}
 }
 
+func TestRouter_GetDatacentersWatch(t *testing.T) {
+	r := testRouter(t, "dc0")
+
+	self := "node0.dc0"
+	wan := testCluster(self)
+	require.NoError(t, r.AddArea(types.AreaWAN, wan, &fauxConnPool{}))
+
+	dcs, ch := r.GetDatacentersWatch()
+	require.Equal(t, []string{"dc0", "dc1", "dc2", "dcX"}, dcs)
+
+	// A new server of a known datacenter does not change the list.
+	require.NoError(t, r.AddServer(types.AreaWAN, &metadata.Server{Name: "node5", Datacenter: "dc1"}))
+	select {
+	case <-ch:
+		t.Fatal("the channel should not be closed")
+	default:
+	}
+
+	require.NoError(t, r.AddServer(types.AreaWAN, &metadata.Server{Name: "node1", Datacenter: "dc3"}))
+	select {
+	case <-ch:
+	default:
+		t.Fatal("the channel should be closed")
+	}
+	dcs, ch = r.GetDatacentersWatch()
+	require.Equal(t, []string{"dc0", "dc1", "dc2", "dc3", "dcX"}, dcs)
+
+	require.NoError(t, r.RemoveServer(types.AreaWAN, &metadata.Server{Name: "node1", Datacenter: "dc3"}))
+	select {
+	case <-ch:
+	default:
+		t.Fatal("the channel should be closed")
+	}
+	dcs, _ = r.GetDatacentersWatch()
+	require.Equal(t, []string{"dc0", "dc1", "dc2", "dcX"}, dcs)
+}
+
 func TestRouter_distanceSorter(t *testing.T) {
 	actual := &datacenterSorter{
 		Names: []string{"foo", "bar", "baz", "zoo"},
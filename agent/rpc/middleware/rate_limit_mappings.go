@@ -9,37 +9,45 @@ import "github.com/hernad/consul/agent/consul/rate"
 // for rate limiting purposes. Please be sure to update this list
 // if a net/rpc endpoint is removed.
 var rpcRateLimitSpecs = map[string]rate.OperationSpec{
-	"ACL.AuthMethodDelete":  {Type: rate.OperationTypeWrite, Category: rate.OperationCategoryACL},
-	"ACL.AuthMethodList":    {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
-	"ACL.AuthMethodRead":    {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
-	"ACL.AuthMethodSet":     {Type: rate.OperationTypeWrite, Category: rate.OperationCategoryACL},
-	"ACL.Authorize":         {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
-	"ACL.BindingRuleDelete": {Type: rate.OperationTypeWrite, Category: rate.OperationCategoryACL},
-	"ACL.BindingRuleList":   {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
-	"ACL.BindingRuleRead":   {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
-	"ACL.BindingRuleSet":    {Type: rate.OperationTypeWrite, Category: rate.OperationCategoryACL},
-	"ACL.BootstrapTokens":   {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
-	"ACL.Login":             {Type: rate.OperationTypeWrite, Category: rate.OperationCategoryACL},
-	"ACL.Logout":            {Type: rate.OperationTypeWrite, Category: rate.OperationCategoryACL},
-	"ACL.PolicyBatchRead":   {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
-	"ACL.PolicyDelete":      {Type: rate.OperationTypeWrite, Category: rate.OperationCategoryACL},
-	"ACL.PolicyList":        {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
-	"ACL.PolicyRead":        {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
-	"ACL.PolicyResolve":     {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
-	"ACL.PolicySet":         {Type: rate.OperationTypeWrite, Category: rate.OperationCategoryACL},
-	"ACL.ReplicationStatus": {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
-	"ACL.RoleBatchRead":     {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
-	"ACL.RoleDelete":        {Type: rate.OperationTypeWrite, Category: rate.OperationCategoryACL},
-	"ACL.RoleList":          {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
-	"ACL.RoleRead":          {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
-	"ACL.RoleResolve":       {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
-	"ACL.RoleSet":           {Type: rate.OperationTypeWrite, Category: rate.OperationCategoryACL},
-	"ACL.TokenBatchRead":    {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
-	"ACL.TokenClone":        {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
-	"ACL.TokenDelete":       {Type: rate.OperationTypeWrite, Category: rate.OperationCategoryACL},
-	"ACL.TokenList":         {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
-	"ACL.TokenRead":         {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
-	"ACL.TokenSet":          {Type: rate.OperationTypeWrite, Category: rate.OperationCategoryACL},
+	"ACL.AuthMethodDelete":      {Type: rate.OperationTypeWrite, Category: rate.OperationCategoryACL},
+	"ACL.AuthMethodList":        {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
+	"ACL.AuthMethodRead":        {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
+	"ACL.AuthMethodSet":         {Type: rate.OperationTypeWrite, Category: rate.OperationCategoryACL},
+	"ACL.Authorize":             {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
+	"ACL.AuthorizeExplain":      {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
+	"ACL.BindingRuleDelete":     {Type: rate.OperationTypeWrite, Category: rate.OperationCategoryACL},
+	"ACL.BindingRuleList":       {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
+	"ACL.BindingRuleRead":       {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
+	"ACL.BindingRuleSet":        {Type: rate.OperationTypeWrite, Category: rate.OperationCategoryACL},
+	"ACL.BootstrapTokens":       {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
+	"ACL.Login":                 {Type: rate.OperationTypeWrite, Category: rate.OperationCategoryACL},
+	"ACL.Logout":                {Type: rate.OperationTypeWrite, Category: rate.OperationCategoryACL},
+	"ACL.PolicyBatchRead":       {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
+	"ACL.PolicyDelete":          {Type: rate.OperationTypeWrite, Category: rate.OperationCategoryACL},
+	"ACL.PolicyList":            {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
+	"ACL.PolicyRead":            {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
+	"ACL.PolicyResolve":         {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
+	"ACL.PolicySet":             {Type: rate.OperationTypeWrite, Category: rate.OperationCategoryACL},
+	"ACL.PolicyTemplateDelete":  {Type: rate.OperationTypeWrite, Category: rate.OperationCategoryACL},
+	"ACL.PolicyTemplateList":    {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
+	"ACL.PolicyTemplateRead":    {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
+	"ACL.PolicyTemplateResolve": {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
+	"ACL.PolicyTemplateSet":     {Type: rate.OperationTypeWrite, Category: rate.OperationCategoryACL},
+	"ACL.ReplicationStatus":     {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
+	"ACL.RoleBatchRead":         {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
+	"ACL.RoleDelete":            {Type: rate.OperationTypeWrite, Category: rate.OperationCategoryACL},
+	"ACL.RoleList":              {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
+	"ACL.RoleRead":              {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
+	"ACL.RoleResolve":           {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
+	"ACL.RoleSet":               {Type: rate.OperationTypeWrite, Category: rate.OperationCategoryACL},
+	"ACL.TokenBatchRead":        {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
+	"ACL.TokenClone":            {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
+	"ACL.TokenCreateChild":      {Type: rate.OperationTypeWrite, Category: rate.OperationCategoryACL},
+	"ACL.TokenDelete":           {Type: rate.OperationTypeWrite, Category: rate.OperationCategoryACL},
+	"ACL.TokenList":             {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
+	"ACL.TokenRead":             {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
+	"ACL.TokenSet":              {Type: rate.OperationTypeWrite, Category: rate.OperationCategoryACL},
+	"ACL.TokenUsageUpdate":      {Type: rate.OperationTypeWrite, Category: rate.OperationCategoryACL},
 
 	"AutoConfig.InitialConfiguration": {Type: rate.OperationTypeRead, Category: rate.OperationCategoryAutoConfig},
 
@@ -64,11 +72,16 @@ var rpcRateLimitSpecs = map[string]rate.OperationSpec{
 	"ConfigEntry.ListAll":              {Type: rate.OperationTypeRead, Category: rate.OperationCategoryConfigEntry},
 	"ConfigEntry.ResolveServiceConfig": {Type: rate.OperationTypeRead, Category: rate.OperationCategoryConfigEntry},
 
-	"ConnectCA.ConfigurationGet": {Type: rate.OperationTypeRead, Category: rate.OperationCategoryConnectCA},
-	"ConnectCA.ConfigurationSet": {Type: rate.OperationTypeWrite, Category: rate.OperationCategoryConnectCA},
-	"ConnectCA.Roots":            {Type: rate.OperationTypeRead, Category: rate.OperationCategoryConnectCA},
-	"ConnectCA.Sign":             {Type: rate.OperationTypeWrite, Category: rate.OperationCategoryConnectCA},
-	"ConnectCA.SignIntermediate": {Type: rate.OperationTypeWrite, Category: rate.OperationCategoryConnectCA},
+	"ConnectCA.ConfigurationDryRun": {Type: rate.OperationTypeWrite, Category: rate.OperationCategoryConnectCA},
+	"ConnectCA.ConfigurationGet":    {Type: rate.OperationTypeRead, Category: rate.OperationCategoryConnectCA},
+	"ConnectCA.ConfigurationSet":    {Type: rate.OperationTypeWrite, Category: rate.OperationCategoryConnectCA},
+	"ConnectCA.Revoke":              {Type: rate.OperationTypeWrite, Category: rate.OperationCategoryConnectCA},
+	"ConnectCA.RevocationDelete":    {Type: rate.OperationTypeWrite, Category: rate.OperationCategoryConnectCA},
+	"ConnectCA.RevocationListSet":   {Type: rate.OperationTypeWrite, Category: rate.OperationCategoryConnectCA},
+	"ConnectCA.Roots":               {Type: rate.OperationTypeRead, Category: rate.OperationCategoryConnectCA},
+	"ConnectCA.Sign":                {Type: rate.OperationTypeWrite, Category: rate.OperationCategoryConnectCA},
+	"ConnectCA.SignIntermediate":    {Type: rate.OperationTypeWrite, Category: rate.OperationCategoryConnectCA},
+	"ConnectCA.SignJWTSVID":         {Type: rate.OperationTypeWrite, Category: rate.OperationCategoryConnectCA},
 
 	"Coordinate.ListDatacenters": {Type: rate.OperationTypeRead, Category: rate.OperationCategoryCoordinate},
 	"Coordinate.ListNodes":       {Type: rate.OperationTypeRead, Category: rate.OperationCategoryCoordinate},
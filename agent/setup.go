@@ -19,6 +19,7 @@ import (
 	"github.com/hashicorp/raft-wal/verifier"
 	"google.golang.org/grpc/grpclog"
 
+	"github.com/hernad/consul/agent/audit"
 	autoconf "github.com/hernad/consul/agent/auto-config"
 	"github.com/hernad/consul/agent/cache"
 	"github.com/hernad/consul/agent/config"
@@ -256,6 +257,11 @@ func NewBaseDeps(configLoader ConfigLoader, logOut io.Writer, providedLogger hcl
 	d.NewRequestRecorderFunc = middleware.NewRequestRecorder
 	d.GetNetRPCInterceptorFunc = middleware.GetNetRPCInterceptor
 
+	d.Auditor, err = audit.New(cfg.Audit)
+	if err != nil {
+		return d, err
+	}
+
 	d.EventPublisher = stream.NewEventPublisher(10 * time.Second)
 
 	d.XDSStreamLimiter = limiter.NewSessionLimiter()
@@ -361,6 +367,7 @@ func getPrometheusDefs(cfg *config.RuntimeConfig, isServer bool) ([]prometheus.G
 		gauges = append(gauges,
 			consul.AutopilotGauges,
 			consul.LeaderCertExpirationGauges,
+			consul.LeaderCARevocationListGauges,
 			consul.LeaderPeeringMetrics,
 			xdscapacity.StatsGauges,
 		)
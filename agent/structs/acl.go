@@ -126,6 +126,7 @@ type ACLIdentity interface {
 	RoleIDs() []string
 	ServiceIdentityList() []*ACLServiceIdentity
 	NodeIdentityList() []*ACLNodeIdentity
+	TemplatedPolicyList() []*ACLTemplatedPolicy
 	IsExpired(asOf time.Time) bool
 	IsLocal() bool
 	EnterpriseMetadata() *acl.EnterpriseMeta
@@ -314,6 +315,10 @@ type ACLToken struct {
 	// The node identities that this token should be allowed to manage.
 	NodeIdentities ACLNodeIdentities `json:",omitempty"`
 
+	// List of policy templates to generate synthetic policies for, with the
+	// values of their variables.
+	TemplatedPolicies ACLTemplatedPolicies `json:",omitempty"`
+
 	// Whether this token is DC local. This means that it will not be synced
 	// to the ACL datacenter and replicated to others.
 	Local bool
@@ -324,6 +329,10 @@ type ACLToken struct {
 	// ACLAuthMethodEnterpriseMeta is the EnterpriseMeta for the AuthMethod that this token was created from
 	ACLAuthMethodEnterpriseMeta
 
+	// ParentAccessorID is the AccessorID of the token this token was minted
+	// from as a child token. Child tokens are deleted along with their parent.
+	ParentAccessorID string `json:",omitempty"`
+
 	// ExpirationTime represents the point after which a token should be
 	// considered revoked and is eligible for destruction. The zero value
 	// represents NO expiration.
@@ -344,6 +353,14 @@ type ACLToken struct {
 	// The time when this token was created
 	CreateTime time.Time `json:",omitempty"`
 
+	// LastUsedTime is when the token was last resolved by the servers of the
+	// datacenter it was read from, or of any datacenter for global tokens
+	// read from the primary datacenter, which aggregates their usage. It is
+	// only as precise as the usage granularity configured on the servers and
+	// is never persisted with the token, only filled in when the token is
+	// read.
+	LastUsedTime *time.Time `json:",omitempty"`
+
 	// Hash of the contents of the token
 	//
 	// This is needed mainly for replication purposes. When replicating from
@@ -394,6 +411,7 @@ func (t *ACLToken) Clone() *ACLToken {
 	t2.Roles = nil
 	t2.ServiceIdentities = nil
 	t2.NodeIdentities = nil
+	t2.TemplatedPolicies = nil
 
 	if len(t.Policies) > 0 {
 		t2.Policies = make([]ACLTokenPolicyLink, len(t.Policies))
@@ -415,6 +433,12 @@ func (t *ACLToken) Clone() *ACLToken {
 			t2.NodeIdentities[i] = n.Clone()
 		}
 	}
+	if len(t.TemplatedPolicies) > 0 {
+		t2.TemplatedPolicies = make([]*ACLTemplatedPolicy, len(t.TemplatedPolicies))
+		for i, p := range t.TemplatedPolicies {
+			t2.TemplatedPolicies[i] = p.Clone()
+		}
+	}
 
 	return &t2
 }
@@ -463,6 +487,18 @@ func (t *ACLToken) ServiceIdentityList() []*ACLServiceIdentity {
 	return out
 }
 
+func (t *ACLToken) TemplatedPolicyList() []*ACLTemplatedPolicy {
+	if len(t.TemplatedPolicies) == 0 {
+		return nil
+	}
+
+	out := make([]*ACLTemplatedPolicy, 0, len(t.TemplatedPolicies))
+	for _, p := range t.TemplatedPolicies {
+		out = append(out, p.Clone())
+	}
+	return out
+}
+
 func (t *ACLToken) IsExpired(asOf time.Time) bool {
 	if asOf.IsZero() || !t.HasExpirationTime() {
 		return false
@@ -523,6 +559,10 @@ func (t *ACLToken) SetHash(force bool) []byte {
 			nodeID.AddToHash(hash)
 		}
 
+		for _, templated := range t.TemplatedPolicies {
+			templated.AddToHash(hash)
+		}
+
 		t.EnterpriseMeta.AddToHash(hash, false)
 
 		// Finalize the hash
@@ -536,7 +576,7 @@ func (t *ACLToken) SetHash(force bool) []byte {
 
 func (t *ACLToken) EstimateSize() int {
 	// 41 = 16 (RaftIndex) + 8 (Hash) + 8 (ExpirationTime) + 8 (CreateTime) + 1 (Local)
-	size := 41 + len(t.AccessorID) + len(t.SecretID) + len(t.Description) + len(t.AuthMethod)
+	size := 41 + len(t.AccessorID) + len(t.SecretID) + len(t.Description) + len(t.AuthMethod) + len(t.ParentAccessorID)
 	for _, link := range t.Policies {
 		size += len(link.ID) + len(link.Name)
 	}
@@ -549,6 +589,9 @@ func (t *ACLToken) EstimateSize() int {
 	for _, nodeID := range t.NodeIdentities {
 		size += nodeID.EstimateSize()
 	}
+	for _, templated := range t.TemplatedPolicies {
+		size += templated.EstimateSize()
+	}
 	return size + t.EnterpriseMeta.EstimateSize()
 }
 
@@ -563,10 +606,13 @@ type ACLTokenListStub struct {
 	Roles             []ACLTokenRoleLink   `json:",omitempty"`
 	ServiceIdentities ACLServiceIdentities `json:",omitempty"`
 	NodeIdentities    ACLNodeIdentities    `json:",omitempty"`
+	TemplatedPolicies ACLTemplatedPolicies `json:",omitempty"`
 	Local             bool
 	AuthMethod        string     `json:",omitempty"`
+	ParentAccessorID  string     `json:",omitempty"`
 	ExpirationTime    *time.Time `json:",omitempty"`
 	CreateTime        time.Time  `json:",omitempty"`
+	LastUsedTime      *time.Time `json:",omitempty"`
 	Hash              []byte
 	CreateIndex       uint64
 	ModifyIndex       uint64
@@ -574,6 +620,15 @@ type ACLTokenListStub struct {
 	ACLAuthMethodEnterpriseMeta
 }
 
+// UnusedSince returns true if the token was not used since the given time. A
+// token that was never used is considered unused since it was created.
+func (token *ACLTokenListStub) UnusedSince(since time.Time) bool {
+	if token.LastUsedTime != nil {
+		return token.LastUsedTime.Before(since)
+	}
+	return token.CreateTime.Before(since)
+}
+
 type ACLTokenListStubs []*ACLTokenListStub
 
 func (token *ACLToken) Stub() *ACLTokenListStub {
@@ -585,10 +640,13 @@ func (token *ACLToken) Stub() *ACLTokenListStub {
 		Roles:                       token.Roles,
 		ServiceIdentities:           token.ServiceIdentities,
 		NodeIdentities:              token.NodeIdentities,
+		TemplatedPolicies:           token.TemplatedPolicies,
 		Local:                       token.Local,
 		AuthMethod:                  token.AuthMethod,
+		ParentAccessorID:            token.ParentAccessorID,
 		ExpirationTime:              token.ExpirationTime,
 		CreateTime:                  token.CreateTime,
+		LastUsedTime:                token.LastUsedTime,
 		Hash:                        token.Hash,
 		CreateIndex:                 token.CreateIndex,
 		ModifyIndex:                 token.ModifyIndex,
@@ -870,6 +928,10 @@ type ACLRole struct {
 	// List of nodes to generate synthetic policies for.
 	NodeIdentities ACLNodeIdentities `json:",omitempty"`
 
+	// List of policy templates to generate synthetic policies for, with the
+	// values of their variables.
+	TemplatedPolicies ACLTemplatedPolicies `json:",omitempty"`
+
 	// Hash of the contents of the role
 	// This does not take into account the ID (which is immutable)
 	// nor the raft metadata.
@@ -909,6 +971,7 @@ func (r *ACLRole) Clone() *ACLRole {
 	r2.Policies = nil
 	r2.ServiceIdentities = nil
 	r2.NodeIdentities = nil
+	r2.TemplatedPolicies = nil
 
 	if len(r.Policies) > 0 {
 		r2.Policies = make([]ACLRolePolicyLink, len(r.Policies))
@@ -926,6 +989,12 @@ func (r *ACLRole) Clone() *ACLRole {
 			r2.NodeIdentities[i] = n.Clone()
 		}
 	}
+	if len(r.TemplatedPolicies) > 0 {
+		r2.TemplatedPolicies = make([]*ACLTemplatedPolicy, len(r.TemplatedPolicies))
+		for i, p := range r.TemplatedPolicies {
+			r2.TemplatedPolicies[i] = p.Clone()
+		}
+	}
 	return &r2
 }
 
@@ -957,6 +1026,9 @@ func (r *ACLRole) SetHash(force bool) []byte {
 		for _, nodeID := range r.NodeIdentities {
 			nodeID.AddToHash(hash)
 		}
+		for _, templated := range r.TemplatedPolicies {
+			templated.AddToHash(hash)
+		}
 
 		r.EnterpriseMeta.AddToHash(hash, false)
 
@@ -984,10 +1056,25 @@ func (r *ACLRole) EstimateSize() int {
 	for _, nodeID := range r.NodeIdentities {
 		size += nodeID.EstimateSize()
 	}
+	for _, templated := range r.TemplatedPolicies {
+		size += templated.EstimateSize()
+	}
 
 	return size + r.EnterpriseMeta.EstimateSize()
 }
 
+func (r *ACLRole) TemplatedPolicyList() []*ACLTemplatedPolicy {
+	if len(r.TemplatedPolicies) == 0 {
+		return nil
+	}
+
+	out := make([]*ACLTemplatedPolicy, 0, len(r.TemplatedPolicies))
+	for _, p := range r.TemplatedPolicies {
+		out = append(out, p.Clone())
+	}
+	return out
+}
+
 const (
 	// BindingRuleBindTypeService is the binding rule bind type that
 	// assigns a Service Identity to the token that is created using the value
@@ -1032,6 +1119,24 @@ const (
 	//   }
 	// }
 	BindingRuleBindTypeNode = "node"
+
+	// BindingRuleBindTypeTemplatedPolicy is the binding rule bind type that
+	// only allows the binding rule to function if a policy template with the
+	// given name (BindName) exists at login-time. If it does the token that is
+	// created is linked to that template with the computed BindVars like:
+	//
+	// &ACLToken{
+	//   ...other fields...
+	//   TemplatedPolicies: []*ACLTemplatedPolicy{
+	//     &ACLTemplatedPolicy{
+	//       TemplateName: "<computed BindName>",
+	//       Variables: <computed BindVars>,
+	//     }
+	//   }
+	// }
+	//
+	// If it does not exist at login-time the rule is ignored.
+	BindingRuleBindTypeTemplatedPolicy = "templated-policy"
 )
 
 type ACLBindingRule struct {
@@ -1051,8 +1156,9 @@ type ACLBindingRule struct {
 	// BindType adjusts how this binding rule is applied at login time.  The
 	// valid values are:
 	//
-	//  - BindingRuleBindTypeService = "service"
-	//  - BindingRuleBindTypeRole    = "role"
+	//  - BindingRuleBindTypeService        = "service"
+	//  - BindingRuleBindTypeRole           = "role"
+	//  - BindingRuleBindTypeTemplatedPolicy = "templated-policy"
 	BindType string
 
 	// BindName is the target of the binding. Can be lightly templated using
@@ -1060,6 +1166,11 @@ type ACLBindingRule struct {
 	// upon the BindType.
 	BindName string
 
+	// BindVars are the values of the variables of the policy template bound
+	// by a rule of the templated-policy BindType. The values can be lightly
+	// templated like BindName.
+	BindVars map[string]string `json:",omitempty"`
+
 	// Embedded Enterprise ACL metadata
 	acl.EnterpriseMeta `mapstructure:",squash"`
 
@@ -1069,6 +1180,12 @@ type ACLBindingRule struct {
 
 func (r *ACLBindingRule) Clone() *ACLBindingRule {
 	r2 := *r
+	if r.BindVars != nil {
+		r2.BindVars = make(map[string]string, len(r.BindVars))
+		for k, v := range r.BindVars {
+			r2.BindVars[k] = v
+		}
+	}
 	return &r2
 }
 
@@ -1226,9 +1343,10 @@ func (m *ACLAuthMethod) UnmarshalJSON(data []byte) (err error) {
 type ACLReplicationType string
 
 const (
-	ACLReplicatePolicies ACLReplicationType = "policies"
-	ACLReplicateRoles    ACLReplicationType = "roles"
-	ACLReplicateTokens   ACLReplicationType = "tokens"
+	ACLReplicatePolicies        ACLReplicationType = "policies"
+	ACLReplicateRoles           ACLReplicationType = "roles"
+	ACLReplicatePolicyTemplates ACLReplicationType = "policy-templates"
+	ACLReplicateTokens          ACLReplicationType = "tokens"
 )
 
 func (t ACLReplicationType) SingularNoun() string {
@@ -1237,6 +1355,8 @@ func (t ACLReplicationType) SingularNoun() string {
 		return "policy"
 	case ACLReplicateRoles:
 		return "role"
+	case ACLReplicatePolicyTemplates:
+		return "policy template"
 	case ACLReplicateTokens:
 		return "token"
 	default:
@@ -1247,16 +1367,17 @@ func (t ACLReplicationType) SingularNoun() string {
 // ACLReplicationStatus provides information about the health of the ACL
 // replication system.
 type ACLReplicationStatus struct {
-	Enabled              bool
-	Running              bool
-	SourceDatacenter     string
-	ReplicationType      ACLReplicationType
-	ReplicatedIndex      uint64
-	ReplicatedRoleIndex  uint64
-	ReplicatedTokenIndex uint64
-	LastSuccess          time.Time
-	LastError            time.Time
-	LastErrorMessage     string
+	Enabled                       bool
+	Running                       bool
+	SourceDatacenter              string
+	ReplicationType               ACLReplicationType
+	ReplicatedIndex               uint64
+	ReplicatedRoleIndex           uint64
+	ReplicatedPolicyTemplateIndex uint64
+	ReplicatedTokenIndex          uint64
+	LastSuccess                   time.Time
+	LastError                     time.Time
+	LastErrorMessage              string
 }
 
 // ACLTokenSetRequest is used for token creation and update operations
@@ -1300,12 +1421,13 @@ func (r *ACLTokenDeleteRequest) RequestDatacenter() string {
 
 // ACLTokenListRequest is used for token listing operations at the RPC layer
 type ACLTokenListRequest struct {
-	IncludeLocal  bool   // Whether local tokens should be included
-	IncludeGlobal bool   // Whether global tokens should be included
-	Policy        string // Policy filter
-	Role          string // Role filter
-	AuthMethod    string // Auth Method filter
-	Datacenter    string // The datacenter to perform the request within
+	IncludeLocal  bool          // Whether local tokens should be included
+	IncludeGlobal bool          // Whether global tokens should be included
+	Policy        string        // Policy filter
+	Role          string        // Role filter
+	AuthMethod    string        // Auth Method filter
+	UnusedFor     time.Duration // Only include tokens not used within this duration
+	Datacenter    string        // The datacenter to perform the request within
 	ACLAuthMethodEnterpriseMeta
 	acl.EnterpriseMeta
 	QueryOptions
@@ -1322,6 +1444,28 @@ type ACLTokenListResponse struct {
 	QueryMeta
 }
 
+// ACLTokenUsage records when a token was last used.
+type ACLTokenUsage struct {
+	AccessorID string
+	LastUsed   time.Time
+
+	RaftIndex `hash:"ignore"`
+}
+
+type ACLTokenUsages []*ACLTokenUsage
+
+// ACLTokenUsageUpdateRequest is used by servers to report when the tokens
+// they resolved were last used. It is also the Raft payload persisting them.
+type ACLTokenUsageUpdateRequest struct {
+	Usages     ACLTokenUsages
+	Datacenter string
+	WriteRequest
+}
+
+func (r *ACLTokenUsageUpdateRequest) RequestDatacenter() string {
+	return r.Datacenter
+}
+
 // ACLTokenBatchGetRequest is used for reading multiple tokens, this is
 // different from the the token list request in that only tokens with the
 // the requested ids are returned
@@ -1790,6 +1934,56 @@ func (r *RemoteACLAuthorizationRequest) RequestDatacenter() string {
 	return r.Datacenter
 }
 
+// ACLAuthorizeExplainRequest is used to explain why a token is allowed or
+// denied a list of authorizations.
+type ACLAuthorizeExplainRequest struct {
+	Datacenter string
+
+	// AccessorID is the accessor ID of the token to explain the
+	// authorizations of. When empty the token of the request is used.
+	// Explaining the authorizations of another token requires acl:read.
+	AccessorID string
+
+	Requests []ACLAuthorizationRequest
+	acl.EnterpriseMeta
+	QueryOptions
+}
+
+func (r *ACLAuthorizeExplainRequest) RequestDatacenter() string {
+	return r.Datacenter
+}
+
+// ACLAuthorizationExplanation is the decision of an authorization along with
+// what produced it.
+type ACLAuthorizationExplanation struct {
+	ACLAuthorizationResponse
+
+	// Reason describes what produced the decision.
+	Reason string
+
+	// PolicyID and PolicyName identify the policy whose rule produced the
+	// decision, and Rule is that rule. They are empty when the decision
+	// was not produced by a policy of the token.
+	PolicyID   string         `json:",omitempty"`
+	PolicyName string         `json:",omitempty"`
+	Rule       *acl.RuleMatch `json:",omitempty"`
+
+	// Matches are all the policies of the token with a rule applying to
+	// the authorization.
+	Matches []ACLAuthorizationPolicyMatch
+}
+
+// ACLAuthorizationPolicyMatch is a policy with a rule applying to an
+// authorization.
+type ACLAuthorizationPolicyMatch struct {
+	PolicyID   string
+	PolicyName string
+	Rule       acl.RuleMatch
+
+	// Allow is whether the policy alone allows the authorization.
+	Allow bool
+}
+
 func CreateACLAuthorizationResponses(authz acl.Authorizer, requests []ACLAuthorizationRequest) ([]ACLAuthorizationResponse, error) {
 	responses := make([]ACLAuthorizationResponse, len(requests))
 	var ctx acl.AuthorizerContext
@@ -1844,6 +2038,10 @@ func (id *AgentRecoveryTokenIdentity) NodeIdentityList() []*ACLNodeIdentity {
 	return nil
 }
 
+func (id *AgentRecoveryTokenIdentity) TemplatedPolicyList() []*ACLTemplatedPolicy {
+	return nil
+}
+
 func (id *AgentRecoveryTokenIdentity) IsExpired(asOf time.Time) bool {
 	return false
 }
@@ -1892,6 +2090,10 @@ func (i *ACLServerIdentity) NodeIdentityList() []*ACLNodeIdentity {
 	return nil
 }
 
+func (i *ACLServerIdentity) TemplatedPolicyList() []*ACLTemplatedPolicy {
+	return nil
+}
+
 func (i *ACLServerIdentity) IsExpired(asOf time.Time) bool {
 	return false
 }
@@ -12,11 +12,12 @@ import (
 )
 
 type ACLCachesConfig struct {
-	Identities     int
-	Policies       int
-	ParsedPolicies int
-	Authorizers    int
-	Roles          int
+	Identities      int
+	Policies        int
+	ParsedPolicies  int
+	Authorizers     int
+	Roles           int
+	PolicyTemplates int
 }
 
 type ACLCaches struct {
@@ -25,6 +26,7 @@ type ACLCaches struct {
 	policies       *lru.TwoQueueCache // policy ID -> ACLPolicy
 	authorizers    *lru.TwoQueueCache // token secret -> acl.Authorizer
 	roles          *lru.TwoQueueCache // role ID -> ACLRole
+	templates      *lru.TwoQueueCache // policy template ID -> ACLPolicyTemplate
 }
 
 type IdentityCacheEntry struct {
@@ -73,6 +75,15 @@ func (e *RoleCacheEntry) Age() time.Duration {
 	return time.Since(e.CacheTime)
 }
 
+type PolicyTemplateCacheEntry struct {
+	PolicyTemplate *ACLPolicyTemplate
+	CacheTime      time.Time
+}
+
+func (e *PolicyTemplateCacheEntry) Age() time.Duration {
+	return time.Since(e.CacheTime)
+}
+
 func NewACLCaches(config *ACLCachesConfig) (*ACLCaches, error) {
 	cache := &ACLCaches{}
 
@@ -121,6 +132,15 @@ func NewACLCaches(config *ACLCachesConfig) (*ACLCaches, error) {
 		cache.roles = roleCache
 	}
 
+	if config != nil && config.PolicyTemplates > 0 {
+		templateCache, err := lru.New2Q(config.PolicyTemplates)
+		if err != nil {
+			return nil, err
+		}
+
+		cache.templates = templateCache
+	}
+
 	return cache, nil
 }
 
@@ -195,6 +215,19 @@ func (c *ACLCaches) GetRole(roleID string) *RoleCacheEntry {
 	return nil
 }
 
+// GetPolicyTemplate fetches a policy template from the cache by id and returns it
+func (c *ACLCaches) GetPolicyTemplate(templateID string) *PolicyTemplateCacheEntry {
+	if c == nil || c.templates == nil {
+		return nil
+	}
+
+	if raw, ok := c.templates.Get(templateID); ok {
+		return raw.(*PolicyTemplateCacheEntry)
+	}
+
+	return nil
+}
+
 // PutIdentity adds a new identity to the cache
 func (c *ACLCaches) PutIdentity(id string, ident ACLIdentity) {
 	if c == nil || c.identities == nil {
@@ -252,6 +285,14 @@ func (c *ACLCaches) PutRole(roleID string, role *ACLRole) {
 	c.roles.Add(roleID, &RoleCacheEntry{Role: role, CacheTime: time.Now()})
 }
 
+func (c *ACLCaches) PutPolicyTemplate(templateID string, template *ACLPolicyTemplate) {
+	if c == nil || c.templates == nil {
+		return
+	}
+
+	c.templates.Add(templateID, &PolicyTemplateCacheEntry{PolicyTemplate: template, CacheTime: time.Now()})
+}
+
 func (c *ACLCaches) RemoveIdentity(id string) {
 	if c != nil && c.identities != nil {
 		c.identities.Remove(id)
@@ -270,6 +311,12 @@ func (c *ACLCaches) RemoveRole(roleID string) {
 	}
 }
 
+func (c *ACLCaches) RemovePolicyTemplate(templateID string) {
+	if c != nil && c.templates != nil {
+		c.templates.Remove(templateID)
+	}
+}
+
 func (c *ACLCaches) Purge() {
 	if c != nil {
 		if c.identities != nil {
@@ -287,6 +334,9 @@ func (c *ACLCaches) Purge() {
 		if c.roles != nil {
 			c.roles.Purge()
 		}
+		if c.templates != nil {
+			c.templates.Purge()
+		}
 	}
 }
 
@@ -17,7 +17,7 @@ func TestStructs_ACLCaches(t *testing.T) {
 
 		t.Run("Valid Sizes", func(t *testing.T) {
 			// 1 isn't valid due to a bug in golang-lru library
-			config := ACLCachesConfig{2, 2, 2, 2, 2}
+			config := ACLCachesConfig{2, 2, 2, 2, 2, 2}
 
 			cache, err := NewACLCaches(&config)
 			require.NoError(t, err)
@@ -26,11 +26,12 @@ func TestStructs_ACLCaches(t *testing.T) {
 			require.NotNil(t, cache.policies)
 			require.NotNil(t, cache.parsedPolicies)
 			require.NotNil(t, cache.authorizers)
+			require.NotNil(t, cache.templates)
 		})
 
 		t.Run("Zero Sizes", func(t *testing.T) {
 			// 1 isn't valid due to a bug in golang-lru library
-			config := ACLCachesConfig{0, 0, 0, 0, 0}
+			config := ACLCachesConfig{0, 0, 0, 0, 0, 0}
 
 			cache, err := NewACLCaches(&config)
 			require.NoError(t, err)
@@ -39,6 +40,7 @@ func TestStructs_ACLCaches(t *testing.T) {
 			require.Nil(t, cache.policies)
 			require.Nil(t, cache.parsedPolicies)
 			require.Nil(t, cache.authorizers)
+			require.Nil(t, cache.templates)
 		})
 	})
 
@@ -121,4 +123,22 @@ func TestStructs_ACLCaches(t *testing.T) {
 		require.NotNil(t, entry)
 		require.NotNil(t, entry.Role)
 	})
+
+	t.Run("PolicyTemplates", func(t *testing.T) {
+		// 1 isn't valid due to a bug in golang-lru library
+		config := ACLCachesConfig{PolicyTemplates: 4}
+
+		cache, err := NewACLCaches(&config)
+		require.NoError(t, err)
+		require.NotNil(t, cache)
+
+		cache.PutPolicyTemplate("foo", &ACLPolicyTemplate{})
+
+		entry := cache.GetPolicyTemplate("foo")
+		require.NotNil(t, entry)
+		require.NotNil(t, entry.PolicyTemplate)
+
+		cache.RemovePolicyTemplate("foo")
+		require.Nil(t, cache.GetPolicyTemplate("foo"))
+	})
 }
@@ -0,0 +1,413 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package structs
+
+import (
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+
+	"github.com/hernad/consul/acl"
+	"github.com/hernad/consul/lib"
+	"github.com/hernad/consul/lib/stringslice"
+)
+
+const (
+	// ACLPolicyTemplateVariableNamespace and ACLPolicyTemplateVariablePartition
+	// are always bound to the namespace and partition of the token or role the
+	// template is linked to.
+	ACLPolicyTemplateVariableNamespace = "namespace"
+	ACLPolicyTemplateVariablePartition = "partition"
+)
+
+var (
+	aclPolicyTemplateVariableRe = regexp.MustCompile(`\$\{([a-zA-Z_][a-zA-Z0-9_]*)\}`)
+
+	// Values are interpolated in the rules of the template, so they must not
+	// be able to close a string or introduce another variable.
+	validACLPolicyTemplateVariableValue = regexp.MustCompile(`^[a-zA-Z0-9_.:/*-]{1,256}$`)
+)
+
+// ACLPolicyTemplate is a policy whose rules contain variables, written
+// ${name}, which are bound when the template is linked to a token or role or
+// selected by a binding rule. Each set of bound variables generates a
+// synthetic policy the same way service and node identities do, so a single
+// template can replace many near-identical policies.
+type ACLPolicyTemplate struct {
+	// This is the internal UUID associated with the template
+	ID string
+
+	// Unique name to reference the template by.
+	//   - Valid Characters: [a-zA-Z0-9-]
+	//   - Valid Lengths: 1 - 128
+	Name string
+
+	// Human readable description (Optional)
+	Description string
+
+	// The rule set of the generated policies, with ${variable} placeholders.
+	Rules string
+
+	// Datacenters that the generated policies are valid within.
+	//   - No wildcards allowed
+	//   - If empty then the policies are valid within all datacenters
+	Datacenters []string `json:",omitempty"`
+
+	// Hash of the contents of the template
+	// This does not take into account the ID (which is immutable)
+	// nor the raft metadata.
+	Hash []byte
+
+	// Embedded Enterprise ACL Metadata
+	acl.EnterpriseMeta `mapstructure:",squash"`
+
+	// Embedded Raft Metadata
+	RaftIndex `hash:"ignore"`
+}
+
+func (t *ACLPolicyTemplate) UnmarshalJSON(data []byte) error {
+	type Alias ACLPolicyTemplate
+	aux := &struct {
+		Hash string
+		*Alias
+	}{
+		Alias: (*Alias)(t),
+	}
+
+	if err := lib.UnmarshalJSON(data, &aux); err != nil {
+		return err
+	}
+	if aux.Hash != "" {
+		t.Hash = []byte(aux.Hash)
+	}
+	return nil
+}
+
+func (t *ACLPolicyTemplate) Clone() *ACLPolicyTemplate {
+	t2 := *t
+	t2.Datacenters = stringslice.CloneStringSlice(t.Datacenters)
+	return &t2
+}
+
+// Variables returns the sorted names of the variables of the rules that must
+// be bound by the links to the template. The namespace and partition variables
+// are bound implicitly and are not included.
+func (t *ACLPolicyTemplate) Variables() []string {
+	seen := make(map[string]struct{})
+	var names []string
+	for _, m := range aclPolicyTemplateVariableRe.FindAllStringSubmatch(t.Rules, -1) {
+		name := m[1]
+		if name == ACLPolicyTemplateVariableNamespace || name == ACLPolicyTemplateVariablePartition {
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Render returns the rules of the template with the variables bound to the
+// given values and to the namespace and partition of entMeta.
+func (t *ACLPolicyTemplate) Render(variables map[string]string, entMeta *acl.EnterpriseMeta) (string, error) {
+	if err := t.ValidateVariables(variables); err != nil {
+		return "", err
+	}
+	if entMeta == nil {
+		entMeta = DefaultEnterpriseMetaInDefaultPartition()
+	}
+	rules := aclPolicyTemplateVariableRe.ReplaceAllStringFunc(t.Rules, func(v string) string {
+		name := v[2 : len(v)-1]
+		switch name {
+		case ACLPolicyTemplateVariableNamespace:
+			return entMeta.NamespaceOrDefault()
+		case ACLPolicyTemplateVariablePartition:
+			return entMeta.PartitionOrDefault()
+		default:
+			return variables[name]
+		}
+	})
+	return rules, nil
+}
+
+// ValidateVariables returns an error unless the variables bind exactly the
+// variables of the template to valid values.
+func (t *ACLPolicyTemplate) ValidateVariables(variables map[string]string) error {
+	names := t.Variables()
+	for _, name := range names {
+		value, ok := variables[name]
+		if !ok {
+			return fmt.Errorf("Missing value for variable %q of policy template %q", name, t.Name)
+		}
+		if !validACLPolicyTemplateVariableValue.MatchString(value) {
+			return fmt.Errorf("Invalid value %q for variable %q of policy template %q", value, name, t.Name)
+		}
+	}
+	if len(variables) > len(names) {
+		for name := range variables {
+			if !stringslice.Contains(names, name) {
+				return fmt.Errorf("Policy template %q has no variable %q", t.Name, name)
+			}
+		}
+	}
+	return nil
+}
+
+// SyntheticPolicy returns the policy generated from the template for the link.
+func (t *ACLPolicyTemplate) SyntheticPolicy(link *ACLTemplatedPolicy, entMeta *acl.EnterpriseMeta) (*ACLPolicy, error) {
+	rules, err := t.Render(link.Variables, entMeta)
+	if err != nil {
+		return nil, err
+	}
+
+	hasher := fnv.New128a()
+	hashID := fmt.Sprintf("%x", hasher.Sum([]byte(rules)))
+
+	policy := &ACLPolicy{}
+	policy.ID = hashID
+	policy.Name = fmt.Sprintf("templated-policy-%s", hashID)
+	policy.Description = fmt.Sprintf("synthetic policy for policy template %q", t.Name)
+	policy.Rules = rules
+	policy.Datacenters = t.Datacenters
+	if len(link.Datacenters) > 0 {
+		policy.Datacenters = link.Datacenters
+	}
+	policy.EnterpriseMeta.Merge(entMeta)
+	policy.SetHash(true)
+	return policy, nil
+}
+
+func (t *ACLPolicyTemplate) SetHash(force bool) []byte {
+	if force || t.Hash == nil {
+		// Initialize a 256bit Blake2 hash (32 bytes)
+		hash, err := blake2b.New256(nil)
+		if err != nil {
+			panic(err)
+		}
+
+		// Write all the user set fields
+		hash.Write([]byte(t.Name))
+		hash.Write([]byte(t.Description))
+		hash.Write([]byte(t.Rules))
+		for _, dc := range t.Datacenters {
+			hash.Write([]byte(dc))
+		}
+
+		t.EnterpriseMeta.AddToHash(hash, false)
+
+		// Finalize the hash
+		hashVal := hash.Sum(nil)
+
+		// Set and return the hash
+		t.Hash = hashVal
+	}
+	return t.Hash
+}
+
+func (t *ACLPolicyTemplate) EstimateSize() int {
+	// 60 = 36 (uuid) + 16 (RaftIndex) + 8 (Hash)
+	size := 60 + len(t.Name) + len(t.Description) + len(t.Rules)
+	for _, dc := range t.Datacenters {
+		size += len(dc)
+	}
+
+	return size + t.EnterpriseMeta.EstimateSize()
+}
+
+type ACLPolicyTemplates []*ACLPolicyTemplate
+
+func (templates ACLPolicyTemplates) Sort() {
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].ID < templates[j].ID
+	})
+}
+
+// ACLTemplatedPolicy links a token or role to a policy template with the
+// values of its variables.
+type ACLTemplatedPolicy struct {
+	TemplateID   string
+	TemplateName string `hash:"ignore"`
+
+	// Variables are the values of the variables of the template.
+	Variables map[string]string `json:",omitempty"`
+
+	// Datacenters that the generated policy is valid within, overriding
+	// the datacenters of the template.
+	//   - No wildcards allowed
+	//   - If empty then the datacenters of the template are used
+	Datacenters []string `json:",omitempty"`
+}
+
+func (p *ACLTemplatedPolicy) Clone() *ACLTemplatedPolicy {
+	p2 := *p
+	if p.Variables != nil {
+		p2.Variables = make(map[string]string, len(p.Variables))
+		for k, v := range p.Variables {
+			p2.Variables[k] = v
+		}
+	}
+	p2.Datacenters = stringslice.CloneStringSlice(p.Datacenters)
+	return &p2
+}
+
+// key identifies the template and variables of the link, regardless of the
+// datacenters.
+func (p *ACLTemplatedPolicy) key() string {
+	names := make([]string, 0, len(p.Variables))
+	for name := range p.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString(p.TemplateID)
+	for _, name := range names {
+		fmt.Fprintf(&b, "\x00%s=%s", name, p.Variables[name])
+	}
+	return b.String()
+}
+
+func (p *ACLTemplatedPolicy) AddToHash(h hash.Hash) {
+	h.Write([]byte(p.key()))
+	for _, dc := range p.Datacenters {
+		h.Write([]byte(dc))
+	}
+}
+
+func (p *ACLTemplatedPolicy) EstimateSize() int {
+	size := len(p.TemplateID) + len(p.TemplateName)
+	for k, v := range p.Variables {
+		size += len(k) + len(v)
+	}
+	for _, dc := range p.Datacenters {
+		size += len(dc)
+	}
+	return size
+}
+
+type ACLTemplatedPolicies []*ACLTemplatedPolicy
+
+// Deduplicate returns a new list of templated policies without duplicates.
+// Links to the same template with the same variables but different
+// datacenters are merged into a single link with all datacenters.
+func (ps ACLTemplatedPolicies) Deduplicate() ACLTemplatedPolicies {
+	unique := make(map[string]*ACLTemplatedPolicy)
+	var results ACLTemplatedPolicies
+
+	for _, p := range ps {
+		key := p.key()
+		entry, ok := unique[key]
+		if ok {
+			if len(entry.Datacenters) == 0 || len(p.Datacenters) == 0 {
+				// One of them is valid in all datacenters.
+				entry.Datacenters = nil
+			} else {
+				dcs := stringslice.CloneStringSlice(p.Datacenters)
+				sort.Strings(dcs)
+				entry.Datacenters = stringslice.MergeSorted(dcs, entry.Datacenters)
+			}
+		} else {
+			entry = p.Clone()
+			sort.Strings(entry.Datacenters)
+			unique[key] = entry
+			results = append(results, entry)
+		}
+	}
+	return results
+}
+
+// ACLPolicyTemplateSetRequest is used at the RPC layer for creation and update requests
+type ACLPolicyTemplateSetRequest struct {
+	PolicyTemplate ACLPolicyTemplate // The template to upsert
+	Datacenter     string            // The datacenter to perform the request within
+	WriteRequest
+}
+
+func (r *ACLPolicyTemplateSetRequest) RequestDatacenter() string {
+	return r.Datacenter
+}
+
+// ACLPolicyTemplateDeleteRequest is used at the RPC layer deletion requests
+type ACLPolicyTemplateDeleteRequest struct {
+	PolicyTemplateID string // The id of the template to delete
+	Datacenter       string // The datacenter to perform the request within
+	acl.EnterpriseMeta
+	WriteRequest
+}
+
+func (r *ACLPolicyTemplateDeleteRequest) RequestDatacenter() string {
+	return r.Datacenter
+}
+
+// ACLPolicyTemplateGetRequest is used at the RPC layer to perform template read operations
+type ACLPolicyTemplateGetRequest struct {
+	PolicyTemplateID   string // id used for the lookup (one of PolicyTemplateID or PolicyTemplateName is allowed)
+	PolicyTemplateName string // name used for the lookup (one of PolicyTemplateID or PolicyTemplateName is allowed)
+	Datacenter         string // The datacenter to perform the request within
+	acl.EnterpriseMeta
+	QueryOptions
+}
+
+func (r *ACLPolicyTemplateGetRequest) RequestDatacenter() string {
+	return r.Datacenter
+}
+
+// ACLPolicyTemplateListRequest is used at the RPC layer to request a listing of templates
+type ACLPolicyTemplateListRequest struct {
+	Datacenter string // The datacenter to perform the request within
+	acl.EnterpriseMeta
+	QueryOptions
+}
+
+func (r *ACLPolicyTemplateListRequest) RequestDatacenter() string {
+	return r.Datacenter
+}
+
+// ACLPolicyTemplateBatchGetRequest is used at the RPC layer to request a
+// subset of the templates linked to the token used for retrieval
+type ACLPolicyTemplateBatchGetRequest struct {
+	PolicyTemplateIDs []string // List of template ids to fetch
+	Datacenter        string   // The datacenter to perform the request within
+	QueryOptions
+}
+
+func (r *ACLPolicyTemplateBatchGetRequest) RequestDatacenter() string {
+	return r.Datacenter
+}
+
+// ACLPolicyTemplateResponse returns a single template + metadata
+type ACLPolicyTemplateResponse struct {
+	PolicyTemplate *ACLPolicyTemplate
+	QueryMeta
+}
+
+type ACLPolicyTemplateListResponse struct {
+	PolicyTemplates ACLPolicyTemplates
+	QueryMeta
+}
+
+type ACLPolicyTemplateBatchResponse struct {
+	PolicyTemplates ACLPolicyTemplates
+	QueryMeta
+}
+
+// ACLPolicyTemplateBatchSetRequest is used at the Raft layer for batching
+// multiple template creations and updates
+type ACLPolicyTemplateBatchSetRequest struct {
+	PolicyTemplates ACLPolicyTemplates
+}
+
+// ACLPolicyTemplateBatchDeleteRequest is used at the Raft layer for batching
+// multiple template deletions
+type ACLPolicyTemplateBatchDeleteRequest struct {
+	PolicyTemplateIDs []string
+}
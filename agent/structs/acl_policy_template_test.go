@@ -0,0 +1,98 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package structs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStructs_ACLPolicyTemplate_Variables(t *testing.T) {
+	template := &ACLPolicyTemplate{
+		Name: "kv-owner",
+		Rules: `key_prefix "${namespace}/${team}/${app}/" { policy = "write" }
+service "${app}" { policy = "read" }`,
+	}
+
+	require.Equal(t, []string{"app", "team"}, template.Variables())
+	require.Empty(t, (&ACLPolicyTemplate{Rules: `key_prefix "" { policy = "read" }`}).Variables())
+}
+
+func TestStructs_ACLPolicyTemplate_Render(t *testing.T) {
+	template := &ACLPolicyTemplate{
+		Name:  "kv-owner",
+		Rules: `key_prefix "${partition}/${namespace}/${app}/" { policy = "write" }`,
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		rules, err := template.Render(map[string]string{"app": "web"}, nil)
+		require.NoError(t, err)
+		require.Equal(t, `key_prefix "default/default/web/" { policy = "write" }`, rules)
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		_, err := template.Render(nil, nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `Missing value for variable "app"`)
+	})
+
+	t.Run("Unknown", func(t *testing.T) {
+		_, err := template.Render(map[string]string{"app": "web", "team": "a"}, nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `has no variable "team"`)
+	})
+
+	t.Run("Injection", func(t *testing.T) {
+		for _, value := range []string{
+			`web" { policy = "write" } key_prefix "`,
+			"${app}",
+			"web\nnode_prefix",
+			"",
+		} {
+			_, err := template.Render(map[string]string{"app": value}, nil)
+			require.Error(t, err, "value %q", value)
+		}
+	})
+}
+
+func TestStructs_ACLPolicyTemplate_SyntheticPolicy(t *testing.T) {
+	template := &ACLPolicyTemplate{
+		Name:        "kv-owner",
+		Rules:       `key_prefix "${app}/" { policy = "write" }`,
+		Datacenters: []string{"dc1", "dc2"},
+	}
+
+	web, err := template.SyntheticPolicy(&ACLTemplatedPolicy{Variables: map[string]string{"app": "web"}}, nil)
+	require.NoError(t, err)
+	require.Equal(t, `key_prefix "web/" { policy = "write" }`, web.Rules)
+	require.Equal(t, []string{"dc1", "dc2"}, web.Datacenters)
+
+	db, err := template.SyntheticPolicy(&ACLTemplatedPolicy{
+		Variables:   map[string]string{"app": "db"},
+		Datacenters: []string{"dc2"},
+	}, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"dc2"}, db.Datacenters)
+	require.NotEqual(t, web.ID, db.ID)
+}
+
+func TestStructs_ACLTemplatedPolicies_Deduplicate(t *testing.T) {
+	links := ACLTemplatedPolicies{
+		{TemplateID: "one", Variables: map[string]string{"app": "web"}, Datacenters: []string{"dc2"}},
+		{TemplateID: "one", Variables: map[string]string{"app": "web"}, Datacenters: []string{"dc1"}},
+		{TemplateID: "one", Variables: map[string]string{"app": "db"}},
+		{TemplateID: "two", Variables: map[string]string{"app": "web"}},
+		{TemplateID: "two", Variables: map[string]string{"app": "web"}, Datacenters: []string{"dc1"}},
+	}
+
+	deduped := links.Deduplicate()
+	require.Len(t, deduped, 3)
+	require.Equal(t, []string{"dc1", "dc2"}, deduped[0].Datacenters)
+	require.Equal(t, "db", deduped[1].Variables["app"])
+	require.Nil(t, deduped[2].Datacenters)
+
+	// the original links are left untouched
+	require.Equal(t, []string{"dc2"}, links[0].Datacenters)
+}
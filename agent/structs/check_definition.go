@@ -35,8 +35,20 @@ type CheckDefinition struct {
 	Method                         string
 	Body                           string
 	DisableRedirects               bool
+	BodyRegex                      string
+	JSONPath                       string
+	JSONPathValue                  string
+	RequiredHeaders                map[string]string
+	LatencyWarning                 time.Duration
 	TCP                            string
 	UDP                            string
+	DNS                            string
+	DNSServer                      string
+	DNSRecordType                  string
+	DNSExpect                      []string
+	TLSCert                        string
+	TLSCertWarningDays             int
+	TLSCertCriticalDays            int
 	Interval                       time.Duration
 	DockerContainerID              string
 	Shell                          string
@@ -47,6 +59,13 @@ type CheckDefinition struct {
 	TLSSkipVerify                  bool
 	AliasNode                      string
 	AliasService                   string
+	CompositeChecks                []string
+	CompositeNode                  string
+	CompositeMembers               []CompositeMember
+	CompositeOperator              string
+	CompositeThreshold             int
+	CompositeStatus                string
+	CompositePartialStatus         string
 	Timeout                        time.Duration
 	TTL                            time.Duration
 	SuccessBeforePassing           int
@@ -65,21 +84,40 @@ func (t *CheckDefinition) UnmarshalJSON(data []byte) (err error) {
 		Interval                       interface{}
 		Timeout                        interface{}
 		TTL                            interface{}
+		LatencyWarning                 interface{}
 		DeregisterCriticalServiceAfter interface{}
 
 		// Translate fields
 
 		// "args" -> ScriptArgs
-		Args                                []string    `json:"args"`
-		ScriptArgsSnake                     []string    `json:"script_args"`
-		DeregisterCriticalServiceAfterSnake interface{} `json:"deregister_critical_service_after"`
-		DockerContainerIDSnake              string      `json:"docker_container_id"`
-		TLSServerNameSnake                  string      `json:"tls_server_name"`
-		TLSSkipVerifySnake                  bool        `json:"tls_skip_verify"`
-		GRPCUseTLSSnake                     bool        `json:"grpc_use_tls"`
-		ServiceIDSnake                      string      `json:"service_id"`
-		H2PingUseTLSSnake                   bool        `json:"h2ping_use_tls"`
-		DisableRedirectsSnake               bool        `json:"disable_redirects"`
+		Args                                []string          `json:"args"`
+		ScriptArgsSnake                     []string          `json:"script_args"`
+		DeregisterCriticalServiceAfterSnake interface{}       `json:"deregister_critical_service_after"`
+		DockerContainerIDSnake              string            `json:"docker_container_id"`
+		TLSServerNameSnake                  string            `json:"tls_server_name"`
+		TLSSkipVerifySnake                  bool              `json:"tls_skip_verify"`
+		GRPCUseTLSSnake                     bool              `json:"grpc_use_tls"`
+		ServiceIDSnake                      string            `json:"service_id"`
+		H2PingUseTLSSnake                   bool              `json:"h2ping_use_tls"`
+		DisableRedirectsSnake               bool              `json:"disable_redirects"`
+		BodyRegexSnake                      string            `json:"body_regex"`
+		JSONPathSnake                       string            `json:"json_path"`
+		JSONPathValueSnake                  string            `json:"json_path_value"`
+		RequiredHeadersSnake                map[string]string `json:"required_headers"`
+		LatencyWarningSnake                 interface{}       `json:"latency_warning"`
+		DNSServerSnake                      string            `json:"dns_server"`
+		DNSRecordTypeSnake                  string            `json:"dns_record_type"`
+		DNSExpectSnake                      []string          `json:"dns_expect"`
+		TLSCertSnake                        string            `json:"tls_cert"`
+		TLSCertWarningDaysSnake             int               `json:"tls_cert_warning_days"`
+		TLSCertCriticalDaysSnake            int               `json:"tls_cert_critical_days"`
+		CompositeChecksSnake                []string          `json:"composite_checks"`
+		CompositeNodeSnake                  string            `json:"composite_node"`
+		CompositeMembersSnake               []CompositeMember `json:"composite_members"`
+		CompositeOperatorSnake              string            `json:"composite_operator"`
+		CompositeThresholdSnake             int               `json:"composite_threshold"`
+		CompositeStatusSnake                string            `json:"composite_status"`
+		CompositePartialStatusSnake         string            `json:"composite_partial_status"`
 
 		*Alias
 	}{
@@ -128,6 +166,60 @@ func (t *CheckDefinition) UnmarshalJSON(data []byte) (err error) {
 	if aux.DisableRedirectsSnake {
 		t.DisableRedirects = aux.DisableRedirectsSnake
 	}
+	if t.BodyRegex == "" {
+		t.BodyRegex = aux.BodyRegexSnake
+	}
+	if t.JSONPath == "" {
+		t.JSONPath = aux.JSONPathSnake
+	}
+	if t.JSONPathValue == "" {
+		t.JSONPathValue = aux.JSONPathValueSnake
+	}
+	if len(t.RequiredHeaders) == 0 {
+		t.RequiredHeaders = aux.RequiredHeadersSnake
+	}
+	if aux.LatencyWarning == nil {
+		aux.LatencyWarning = aux.LatencyWarningSnake
+	}
+	if t.DNSServer == "" {
+		t.DNSServer = aux.DNSServerSnake
+	}
+	if t.DNSRecordType == "" {
+		t.DNSRecordType = aux.DNSRecordTypeSnake
+	}
+	if len(t.DNSExpect) == 0 {
+		t.DNSExpect = aux.DNSExpectSnake
+	}
+	if t.TLSCert == "" {
+		t.TLSCert = aux.TLSCertSnake
+	}
+	if t.TLSCertWarningDays == 0 {
+		t.TLSCertWarningDays = aux.TLSCertWarningDaysSnake
+	}
+	if t.TLSCertCriticalDays == 0 {
+		t.TLSCertCriticalDays = aux.TLSCertCriticalDaysSnake
+	}
+	if len(t.CompositeChecks) == 0 {
+		t.CompositeChecks = aux.CompositeChecksSnake
+	}
+	if t.CompositeNode == "" {
+		t.CompositeNode = aux.CompositeNodeSnake
+	}
+	if len(t.CompositeMembers) == 0 {
+		t.CompositeMembers = aux.CompositeMembersSnake
+	}
+	if t.CompositeOperator == "" {
+		t.CompositeOperator = aux.CompositeOperatorSnake
+	}
+	if t.CompositeThreshold == 0 {
+		t.CompositeThreshold = aux.CompositeThresholdSnake
+	}
+	if t.CompositeStatus == "" {
+		t.CompositeStatus = aux.CompositeStatusSnake
+	}
+	if t.CompositePartialStatus == "" {
+		t.CompositePartialStatus = aux.CompositePartialStatusSnake
+	}
 
 	if (aux.H2PING != "" && !aux.H2PingUseTLSSnake) || (aux.H2PING == "" && aux.H2PingUseTLSSnake) {
 		t.H2PingUseTLS = aux.H2PingUseTLSSnake
@@ -164,6 +256,16 @@ func (t *CheckDefinition) UnmarshalJSON(data []byte) (err error) {
 			t.TTL = time.Duration(v)
 		}
 	}
+	if aux.LatencyWarning != nil {
+		switch v := aux.LatencyWarning.(type) {
+		case string:
+			if t.LatencyWarning, err = time.ParseDuration(v); err != nil {
+				return err
+			}
+		case float64:
+			t.LatencyWarning = time.Duration(v)
+		}
+	}
 	if aux.DeregisterCriticalServiceAfter != nil {
 		switch v := aux.DeregisterCriticalServiceAfter.(type) {
 		case string:
@@ -209,6 +311,13 @@ func (c *CheckDefinition) CheckType() *CheckType {
 		ScriptArgs:                     c.ScriptArgs,
 		AliasNode:                      c.AliasNode,
 		AliasService:                   c.AliasService,
+		CompositeChecks:                c.CompositeChecks,
+		CompositeNode:                  c.CompositeNode,
+		CompositeMembers:               c.CompositeMembers,
+		CompositeOperator:              c.CompositeOperator,
+		CompositeThreshold:             c.CompositeThreshold,
+		CompositeStatus:                c.CompositeStatus,
+		CompositePartialStatus:         c.CompositePartialStatus,
 		HTTP:                           c.HTTP,
 		H2PING:                         c.H2PING,
 		H2PingUseTLS:                   c.H2PingUseTLS,
@@ -218,9 +327,21 @@ func (c *CheckDefinition) CheckType() *CheckType {
 		Method:                         c.Method,
 		Body:                           c.Body,
 		DisableRedirects:               c.DisableRedirects,
+		BodyRegex:                      c.BodyRegex,
+		JSONPath:                       c.JSONPath,
+		JSONPathValue:                  c.JSONPathValue,
+		RequiredHeaders:                c.RequiredHeaders,
+		LatencyWarning:                 c.LatencyWarning,
 		OutputMaxSize:                  c.OutputMaxSize,
 		TCP:                            c.TCP,
 		UDP:                            c.UDP,
+		DNS:                            c.DNS,
+		DNSServer:                      c.DNSServer,
+		DNSRecordType:                  c.DNSRecordType,
+		DNSExpect:                      c.DNSExpect,
+		TLSCert:                        c.TLSCert,
+		TLSCertWarningDays:             c.TLSCertWarningDays,
+		TLSCertCriticalDays:            c.TLSCertCriticalDays,
 		Interval:                       c.Interval,
 		DockerContainerID:              c.DockerContainerID,
 		Shell:                          c.Shell,
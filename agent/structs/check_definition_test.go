@@ -4,6 +4,7 @@
 package structs
 
 import (
+	"encoding/json"
 	"reflect"
 	"testing"
 	"time"
@@ -120,3 +121,78 @@ func TestCheckDefinitionToCheckType(t *testing.T) {
 	}
 	require.Equal(t, want, got.CheckType())
 }
+
+func TestTLSCertCheckDays(t *testing.T) {
+	cases := []struct {
+		warning, critical         int
+		wantWarning, wantCritical int
+	}{
+		{0, 0, DefaultTLSCertWarningDays, DefaultTLSCertCriticalDays},
+		{60, 0, 60, DefaultTLSCertCriticalDays},
+		{0, 14, DefaultTLSCertWarningDays, 14},
+		// The default of the unset threshold would be inverted.
+		{0, 40, 63, 40},
+		{5, 0, 5, 1},
+		{10, 20, 10, 20},
+	}
+	for _, tc := range cases {
+		warning, critical := TLSCertCheckDays(tc.warning, tc.critical)
+		require.Equal(t, tc.wantWarning, warning, "warning=%d critical=%d", tc.warning, tc.critical)
+		require.Equal(t, tc.wantCritical, critical, "warning=%d critical=%d", tc.warning, tc.critical)
+	}
+}
+
+func TestCheckType_Validate_TLSCertDays(t *testing.T) {
+	check := func(warning, critical int) error {
+		return (&CheckType{
+			TLSCert:             "localhost:443",
+			Interval:            time.Second,
+			TLSCertWarningDays:  warning,
+			TLSCertCriticalDays: critical,
+		}).Validate()
+	}
+	require.NoError(t, check(0, 40))
+	require.NoError(t, check(5, 0))
+	require.NoError(t, check(30, 7))
+	require.EqualError(t, check(10, 20), "TLSCertCriticalDays (20) can't be higher than TLSCertWarningDays (10)")
+	require.Error(t, check(-1, 0))
+}
+
+func TestCheckType_Validate_JSONPath(t *testing.T) {
+	check := func(path string) error {
+		return (&CheckType{
+			HTTP:     "http://localhost:8080/health",
+			Interval: time.Second,
+			JSONPath: path,
+		}).Validate()
+	}
+	require.NoError(t, check("$.checks[0].name"))
+	require.NoError(t, check("$['app.version']"))
+	require.EqualError(t, check("$.checks[0"), `JSONPath is invalid: invalid JSONPath "$.checks[0": missing ]`)
+	require.EqualError(t, check("$.checks[-1]"), `JSONPath is invalid: invalid JSONPath "$.checks[-1]": invalid index "-1"`)
+}
+
+func TestCheckType_Validate_CompositeMembers(t *testing.T) {
+	check := func(members ...CompositeMember) error {
+		return (&CheckType{
+			CheckID:            "replicas",
+			CompositeMembers:   members,
+			CompositeThreshold: 2,
+		}).Validate()
+	}
+	require.NoError(t, check(CompositeMember{Node: "a", CheckID: "web"}, CompositeMember{Node: "b", CheckID: "web"}))
+	// The check can combine a check with the same ID on another node.
+	require.NoError(t, check(CompositeMember{Node: "a", CheckID: "replicas"}, CompositeMember{CheckID: "web"}))
+	require.EqualError(t, check(CompositeMember{Node: "a", CheckID: "web"}, CompositeMember{CheckID: "replicas"}),
+		"Composite check can't reference itself")
+	require.EqualError(t, check(CompositeMember{Node: "a", CheckID: "web"}, CompositeMember{Node: "b"}),
+		"CompositeMembers can't contain an empty check ID")
+	require.EqualError(t, check(CompositeMember{Node: "a", CheckID: "web"}),
+		"CompositeThreshold must be between 0 and the number of combined checks")
+}
+
+func TestCheckDefinition_UnmarshalJSON_CompositeMembers(t *testing.T) {
+	var def CheckDefinition
+	require.NoError(t, json.Unmarshal([]byte(`{"Name": "replicas", "composite_members": [{"node": "a", "check_id": "web"}, {"Node": "b", "CheckID": "web"}]}`), &def))
+	require.Equal(t, []CompositeMember{{Node: "a", CheckID: "web"}, {Node: "b", CheckID: "web"}}, def.CompositeMembers)
+}
@@ -6,14 +6,89 @@ package structs
 import (
 	"fmt"
 	"reflect"
+	"regexp"
+	"strings"
 	"time"
 
+	"github.com/miekg/dns"
+
+	"github.com/hernad/consul/api"
 	"github.com/hernad/consul/lib"
+	"github.com/hernad/consul/lib/jsonpath"
 	"github.com/hernad/consul/types"
 )
 
 type CheckTypes []*CheckType
 
+// CompositeMember is a check combined by a composite check along with the
+// node it is registered on, which allows a composite check to combine checks
+// of several nodes. An empty Node is the node of the agent running the
+// composite check.
+type CompositeMember struct {
+	Node    string `json:",omitempty"`
+	CheckID string
+}
+
+func (m *CompositeMember) UnmarshalJSON(data []byte) error {
+	type Alias CompositeMember
+	aux := &struct {
+		CheckIDSnake string `json:"check_id"`
+
+		*Alias
+	}{
+		Alias: (*Alias)(m),
+	}
+	if err := lib.UnmarshalJSON(data, aux); err != nil {
+		return err
+	}
+	if m.CheckID == "" {
+		m.CheckID = aux.CheckIDSnake
+	}
+	return nil
+}
+
+const (
+	// CompositeOperatorAnd makes a composite check fail when all the checks
+	// it combines are failing.
+	CompositeOperatorAnd = "and"
+
+	// CompositeOperatorOr makes a composite check fail when any of the
+	// checks it combines is failing. It is the default.
+	CompositeOperatorOr = "or"
+
+	// DefaultTLSCertWarningDays is the number of days before the expiry of
+	// the certificate that a TLS certificate check is warning by default.
+	DefaultTLSCertWarningDays = 30
+
+	// DefaultTLSCertCriticalDays is the number of days before the expiry of
+	// the certificate that a TLS certificate check is critical by default.
+	DefaultTLSCertCriticalDays = 7
+)
+
+// TLSCertCheckDays returns the warning and critical thresholds of a TLS
+// certificate check once the defaults are applied. When only one of them is
+// set and the default of the other would put the warning threshold below the
+// critical one, the other is derived from it instead: the warning threshold
+// keeps the default gap above the critical one, and the critical threshold
+// keeps the default ratio below the warning one.
+func TLSCertCheckDays(warningDays, criticalDays int) (int, int) {
+	switch {
+	case warningDays <= 0 && criticalDays <= 0:
+		warningDays, criticalDays = DefaultTLSCertWarningDays, DefaultTLSCertCriticalDays
+	case warningDays <= 0:
+		warningDays = DefaultTLSCertWarningDays
+		if criticalDays >= warningDays {
+			warningDays = criticalDays + DefaultTLSCertWarningDays - DefaultTLSCertCriticalDays
+		}
+	case criticalDays <= 0:
+		criticalDays = DefaultTLSCertCriticalDays
+		if criticalDays >= warningDays {
+			criticalDays = warningDays * DefaultTLSCertCriticalDays / DefaultTLSCertWarningDays
+		}
+	}
+	return warningDays, criticalDays
+}
+
 // CheckType is used to create either the CheckMonitor or the CheckTTL.
 // The following types are supported: Script, HTTP, TCP, Docker, TTL, GRPC, Alias, H2PING. Script,
 // HTTP, Docker, TCP, GRPC, and H2PING all require Interval. Only one of the types may
@@ -41,11 +116,30 @@ type CheckType struct {
 	Method                 string
 	Body                   string
 	DisableRedirects       bool
+	BodyRegex              string
+	JSONPath               string
+	JSONPathValue          string
+	RequiredHeaders        map[string]string
+	LatencyWarning         time.Duration
 	TCP                    string
 	UDP                    string
+	DNS                    string
+	DNSServer              string
+	DNSRecordType          string
+	DNSExpect              []string
+	TLSCert                string
+	TLSCertWarningDays     int
+	TLSCertCriticalDays    int
 	Interval               time.Duration
 	AliasNode              string
 	AliasService           string
+	CompositeChecks        []string
+	CompositeNode          string
+	CompositeMembers       []CompositeMember
+	CompositeOperator      string
+	CompositeThreshold     int
+	CompositeStatus        string
+	CompositePartialStatus string
 	DockerContainerID      string
 	Shell                  string
 	GRPC                   string
@@ -76,19 +170,38 @@ func (t *CheckType) UnmarshalJSON(data []byte) (err error) {
 		Interval                       interface{}
 		Timeout                        interface{}
 		TTL                            interface{}
+		LatencyWarning                 interface{}
 		DeregisterCriticalServiceAfter interface{}
 
 		// Translate fields
 
 		// "args" -> ScriptArgs
-		Args                                []string    `json:"args"`
-		ScriptArgsSnake                     []string    `json:"script_args"`
-		DeregisterCriticalServiceAfterSnake interface{} `json:"deregister_critical_service_after"`
-		DockerContainerIDSnake              string      `json:"docker_container_id"`
-		TLSServerNameSnake                  string      `json:"tls_server_name"`
-		TLSSkipVerifySnake                  bool        `json:"tls_skip_verify"`
-		GRPCUseTLSSnake                     bool        `json:"grpc_use_tls"`
-		H2PingUseTLSSnake                   bool        `json:"h2ping_use_tls"`
+		Args                                []string          `json:"args"`
+		ScriptArgsSnake                     []string          `json:"script_args"`
+		DeregisterCriticalServiceAfterSnake interface{}       `json:"deregister_critical_service_after"`
+		DockerContainerIDSnake              string            `json:"docker_container_id"`
+		TLSServerNameSnake                  string            `json:"tls_server_name"`
+		TLSSkipVerifySnake                  bool              `json:"tls_skip_verify"`
+		GRPCUseTLSSnake                     bool              `json:"grpc_use_tls"`
+		H2PingUseTLSSnake                   bool              `json:"h2ping_use_tls"`
+		BodyRegexSnake                      string            `json:"body_regex"`
+		JSONPathSnake                       string            `json:"json_path"`
+		JSONPathValueSnake                  string            `json:"json_path_value"`
+		RequiredHeadersSnake                map[string]string `json:"required_headers"`
+		LatencyWarningSnake                 interface{}       `json:"latency_warning"`
+		DNSServerSnake                      string            `json:"dns_server"`
+		DNSRecordTypeSnake                  string            `json:"dns_record_type"`
+		DNSExpectSnake                      []string          `json:"dns_expect"`
+		TLSCertSnake                        string            `json:"tls_cert"`
+		TLSCertWarningDaysSnake             int               `json:"tls_cert_warning_days"`
+		TLSCertCriticalDaysSnake            int               `json:"tls_cert_critical_days"`
+		CompositeChecksSnake                []string          `json:"composite_checks"`
+		CompositeNodeSnake                  string            `json:"composite_node"`
+		CompositeMembersSnake               []CompositeMember `json:"composite_members"`
+		CompositeOperatorSnake              string            `json:"composite_operator"`
+		CompositeThresholdSnake             int               `json:"composite_threshold"`
+		CompositeStatusSnake                string            `json:"composite_status"`
+		CompositePartialStatusSnake         string            `json:"composite_partial_status"`
 
 		// These are going to be ignored but since we are disallowing unknown fields
 		// during parsing we have to be explicit about parsing but not using these.
@@ -134,6 +247,60 @@ func (t *CheckType) UnmarshalJSON(data []byte) (err error) {
 	if aux.GRPCUseTLSSnake {
 		t.GRPCUseTLS = aux.GRPCUseTLSSnake
 	}
+	if t.BodyRegex == "" {
+		t.BodyRegex = aux.BodyRegexSnake
+	}
+	if t.JSONPath == "" {
+		t.JSONPath = aux.JSONPathSnake
+	}
+	if t.JSONPathValue == "" {
+		t.JSONPathValue = aux.JSONPathValueSnake
+	}
+	if len(t.RequiredHeaders) == 0 {
+		t.RequiredHeaders = aux.RequiredHeadersSnake
+	}
+	if aux.LatencyWarning == nil {
+		aux.LatencyWarning = aux.LatencyWarningSnake
+	}
+	if t.DNSServer == "" {
+		t.DNSServer = aux.DNSServerSnake
+	}
+	if t.DNSRecordType == "" {
+		t.DNSRecordType = aux.DNSRecordTypeSnake
+	}
+	if len(t.DNSExpect) == 0 {
+		t.DNSExpect = aux.DNSExpectSnake
+	}
+	if t.TLSCert == "" {
+		t.TLSCert = aux.TLSCertSnake
+	}
+	if t.TLSCertWarningDays == 0 {
+		t.TLSCertWarningDays = aux.TLSCertWarningDaysSnake
+	}
+	if t.TLSCertCriticalDays == 0 {
+		t.TLSCertCriticalDays = aux.TLSCertCriticalDaysSnake
+	}
+	if len(t.CompositeChecks) == 0 {
+		t.CompositeChecks = aux.CompositeChecksSnake
+	}
+	if t.CompositeNode == "" {
+		t.CompositeNode = aux.CompositeNodeSnake
+	}
+	if len(t.CompositeMembers) == 0 {
+		t.CompositeMembers = aux.CompositeMembersSnake
+	}
+	if t.CompositeOperator == "" {
+		t.CompositeOperator = aux.CompositeOperatorSnake
+	}
+	if t.CompositeThreshold == 0 {
+		t.CompositeThreshold = aux.CompositeThresholdSnake
+	}
+	if t.CompositeStatus == "" {
+		t.CompositeStatus = aux.CompositeStatusSnake
+	}
+	if t.CompositePartialStatus == "" {
+		t.CompositePartialStatus = aux.CompositePartialStatusSnake
+	}
 	if aux.Interval != nil {
 		switch v := aux.Interval.(type) {
 		case string:
@@ -164,6 +331,16 @@ func (t *CheckType) UnmarshalJSON(data []byte) (err error) {
 			t.TTL = time.Duration(v)
 		}
 	}
+	if aux.LatencyWarning != nil {
+		switch v := aux.LatencyWarning.(type) {
+		case string:
+			if t.LatencyWarning, err = time.ParseDuration(v); err != nil {
+				return err
+			}
+		case float64:
+			t.LatencyWarning = time.Duration(v)
+		}
+	}
 	if aux.DeregisterCriticalServiceAfter != nil {
 		switch v := aux.DeregisterCriticalServiceAfter.(type) {
 		case string:
@@ -184,13 +361,13 @@ func (t *CheckType) UnmarshalJSON(data []byte) (err error) {
 
 // Validate returns an error message if the check is invalid
 func (c *CheckType) Validate() error {
-	intervalCheck := c.IsScript() || c.HTTP != "" || c.TCP != "" || c.UDP != "" || c.GRPC != "" || c.H2PING != "" || c.OSService != ""
+	intervalCheck := c.IsScript() || c.HTTP != "" || c.TCP != "" || c.UDP != "" || c.GRPC != "" || c.H2PING != "" || c.OSService != "" || c.DNS != "" || c.TLSCert != ""
 
 	if c.Interval > 0 && c.TTL > 0 {
 		return fmt.Errorf("Interval and TTL cannot both be specified")
 	}
 	if intervalCheck && c.Interval <= 0 {
-		return fmt.Errorf("Interval must be > 0 for Script, HTTP, H2PING, TCP, UDP or OSService checks")
+		return fmt.Errorf("Interval must be > 0 for Script, HTTP, H2PING, TCP, UDP, OSService, DNS or TLSCert checks")
 	}
 	if intervalCheck && c.IsAlias() {
 		return fmt.Errorf("Interval cannot be set for Alias checks")
@@ -198,7 +375,13 @@ func (c *CheckType) Validate() error {
 	if c.IsAlias() && c.TTL > 0 {
 		return fmt.Errorf("TTL must be not be set for Alias checks")
 	}
-	if !intervalCheck && !c.IsAlias() && c.TTL <= 0 {
+	if (intervalCheck || c.IsAlias()) && c.IsComposite() {
+		return fmt.Errorf("Composite checks can't be combined with another check type")
+	}
+	if c.IsComposite() && c.TTL > 0 {
+		return fmt.Errorf("TTL must be not be set for Composite checks")
+	}
+	if !intervalCheck && !c.IsAlias() && !c.IsComposite() && c.TTL <= 0 {
 		return fmt.Errorf("TTL must be > 0 for TTL checks")
 	}
 	if c.OutputMaxSize < 0 {
@@ -207,10 +390,88 @@ func (c *CheckType) Validate() error {
 	if c.FailuresBeforeWarning > c.FailuresBeforeCritical {
 		return fmt.Errorf("FailuresBeforeWarning can't be higher than FailuresBeforeCritical")
 	}
+	if c.HasHTTPAssertions() && c.HTTP == "" {
+		return fmt.Errorf("BodyRegex, JSONPath, RequiredHeaders and LatencyWarning are only supported for HTTP checks")
+	}
+	if c.BodyRegex != "" {
+		if _, err := regexp.Compile(c.BodyRegex); err != nil {
+			return fmt.Errorf("BodyRegex is invalid: %v", err)
+		}
+	}
+	if c.JSONPathValue != "" && c.JSONPath == "" {
+		return fmt.Errorf("JSONPathValue requires JSONPath")
+	}
+	if c.JSONPath != "" {
+		if _, err := jsonpath.Parse(c.JSONPath); err != nil {
+			return fmt.Errorf("JSONPath is invalid: %v", err)
+		}
+	}
+	if c.LatencyWarning < 0 {
+		return fmt.Errorf("LatencyWarning must be positive")
+	}
+	if c.DNSRecordType != "" {
+		if _, ok := dns.StringToType[strings.ToUpper(c.DNSRecordType)]; !ok {
+			return fmt.Errorf("DNSRecordType %q is not a valid DNS record type", c.DNSRecordType)
+		}
+	}
+	if c.TLSCertWarningDays < 0 || c.TLSCertCriticalDays < 0 {
+		return fmt.Errorf("TLSCertWarningDays and TLSCertCriticalDays must be positive")
+	}
+	if warningDays, criticalDays := TLSCertCheckDays(c.TLSCertWarningDays, c.TLSCertCriticalDays); criticalDays > warningDays {
+		return fmt.Errorf("TLSCertCriticalDays (%d) can't be higher than TLSCertWarningDays (%d)", criticalDays, warningDays)
+	}
+	if err := c.validateComposite(); err != nil {
+		return err
+	}
 
 	return nil
 }
 
+func (c *CheckType) validateComposite() error {
+	if !c.IsComposite() {
+		if c.CompositeNode != "" || c.CompositeOperator != "" || c.CompositeThreshold != 0 || c.CompositeStatus != "" || c.CompositePartialStatus != "" {
+			return fmt.Errorf("CompositeChecks or CompositeMembers must be set for Composite checks")
+		}
+		return nil
+	}
+
+	for _, id := range c.CompositeChecks {
+		if id == "" {
+			return fmt.Errorf("CompositeChecks can't contain an empty check ID")
+		}
+		if c.CheckID != "" && types.CheckID(id) == c.CheckID && c.CompositeNode == "" {
+			return fmt.Errorf("Composite check can't reference itself")
+		}
+	}
+	for _, m := range c.CompositeMembers {
+		if m.CheckID == "" {
+			return fmt.Errorf("CompositeMembers can't contain an empty check ID")
+		}
+		if c.CheckID != "" && types.CheckID(m.CheckID) == c.CheckID && m.Node == "" {
+			return fmt.Errorf("Composite check can't reference itself")
+		}
+	}
+	switch c.CompositeOperator {
+	case "", CompositeOperatorAnd, CompositeOperatorOr:
+	default:
+		return fmt.Errorf("CompositeOperator must be %q or %q", CompositeOperatorAnd, CompositeOperatorOr)
+	}
+	if c.CompositeThreshold < 0 || c.CompositeThreshold > len(c.CompositeChecks)+len(c.CompositeMembers) {
+		return fmt.Errorf("CompositeThreshold must be between 0 and the number of combined checks")
+	}
+	if c.CompositeThreshold > 0 && c.CompositeOperator != "" {
+		return fmt.Errorf("CompositeOperator and CompositeThreshold cannot both be specified")
+	}
+	for _, status := range []string{c.CompositeStatus, c.CompositePartialStatus} {
+		switch status {
+		case "", api.HealthPassing, api.HealthWarning, api.HealthCritical:
+		default:
+			return fmt.Errorf("invalid Composite status %q", status)
+		}
+	}
+	return nil
+}
+
 // Empty checks if the CheckType has no fields defined. Empty checks parsed from json configs are filtered out
 func (c *CheckType) Empty() bool {
 	return reflect.DeepEqual(c, &CheckType{})
@@ -221,6 +482,17 @@ func (c *CheckType) IsAlias() bool {
 	return c.AliasNode != "" || c.AliasService != ""
 }
 
+// HasHTTPAssertions checks if assertions on the response of an HTTP check
+// are configured.
+func (c *CheckType) HasHTTPAssertions() bool {
+	return c.BodyRegex != "" || c.JSONPath != "" || c.JSONPathValue != "" || len(c.RequiredHeaders) > 0 || c.LatencyWarning > 0
+}
+
+// IsComposite checks if this is a composite check.
+func (c *CheckType) IsComposite() bool {
+	return len(c.CompositeChecks) > 0 || len(c.CompositeMembers) > 0
+}
+
 // IsScript checks if this is a check that execs some kind of script.
 func (c *CheckType) IsScript() bool {
 	return len(c.ScriptArgs) > 0
@@ -265,6 +537,16 @@ func (c *CheckType) IsH2PING() bool {
 	return c.H2PING != "" && c.Interval > 0
 }
 
+// IsDNS checks if this is a DNS type
+func (c *CheckType) IsDNS() bool {
+	return c.DNS != "" && c.Interval > 0
+}
+
+// IsTLSCert checks if this is a TLS certificate type
+func (c *CheckType) IsTLSCert() bool {
+	return c.TLSCert != "" && c.Interval > 0
+}
+
 // IsOSService checks if this is a WindowsService/systemd type
 func (c *CheckType) IsOSService() bool {
 	return c.OSService != "" && c.Interval > 0
@@ -284,6 +566,8 @@ func (c *CheckType) Type() string {
 		return "udp"
 	case c.IsAlias():
 		return "alias"
+	case c.IsComposite():
+		return "composite"
 	case c.IsDocker():
 		return "docker"
 	case c.IsScript():
@@ -292,6 +576,10 @@ func (c *CheckType) Type() string {
 		return "h2ping"
 	case c.IsOSService():
 		return "os_service"
+	case c.IsDNS():
+		return "dns"
+	case c.IsTLSCert():
+		return "tls_cert"
 	default:
 		return ""
 	}
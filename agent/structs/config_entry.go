@@ -167,6 +167,13 @@ type ServiceConfigEntry struct {
 	BalanceInboundConnections string                 `json:",omitempty" alias:"balance_inbound_connections"`
 	EnvoyExtensions           EnvoyExtensions        `json:",omitempty" alias:"envoy_extensions"`
 
+	// EnableHTTP3 makes the proxies of the service also serve HTTP/3 over
+	// QUIC, and advertise it with an Alt-Svc header. The downstreams calling
+	// it in the local datacenter switch to HTTP/3 once they learned it from
+	// that header, and fall back to TCP otherwise. Only HTTP-like protocols
+	// are supported.
+	EnableHTTP3 bool `json:",omitempty" alias:"enable_http3"`
+
 	Meta               map[string]string `json:",omitempty"`
 	acl.EnterpriseMeta `hcl:",squash" mapstructure:",squash"`
 	RaftIndex
@@ -242,6 +249,10 @@ func (e *ServiceConfigEntry) Validate() error {
 		validationErr = multierror.Append(validationErr, fmt.Errorf("invalid value for balance_inbound_connections: %v", e.BalanceInboundConnections))
 	}
 
+	if e.EnableHTTP3 && e.Protocol != "" && !IsProtocolHTTPLike(e.Protocol) {
+		validationErr = multierror.Append(validationErr, fmt.Errorf("enable_http3 is only supported for http, http2 and grpc protocols, not %q", e.Protocol))
+	}
+
 	// External endpoints are invalid with an existing service's upstream configuration
 	if e.UpstreamConfig != nil && e.Destination != nil {
 		validationErr = multierror.Append(validationErr, errors.New("UpstreamConfig and Destination are mutually exclusive for service defaults"))
@@ -135,6 +135,7 @@ func (e *ServiceIntentionsConfigEntry) ToIntention(src *SourceIntention) *Intent
 		Description:          src.Description,
 		SourcePeer:           src.Peer,
 		SourceSamenessGroup:  src.SamenessGroup,
+		SourceTrustDomain:    src.TrustDomain,
 		SourcePartition:      src.PartitionOrEmpty(),
 		SourceNS:             src.NamespaceOrDefault(),
 		SourceName:           src.Name,
@@ -278,6 +279,11 @@ type SourceIntention struct {
 
 	// SamenessGroup is the name of the sameness group, if applicable.
 	SamenessGroup string `json:",omitempty" alias:"sameness_group"`
+
+	// TrustDomain is the name of the federated SPIFFE trust domain of the
+	// source service, if applicable. Workloads of a federated trust domain
+	// only match intentions that name their trust domain.
+	TrustDomain string `json:",omitempty" alias:"trust_domain"`
 }
 
 type IntentionJWTRequirement struct {
@@ -685,6 +691,7 @@ func (e *ServiceIntentionsConfigEntry) validate(legacyWrite bool) error {
 		ServiceName   ServiceName
 		Peer          string
 		SamenessGroup string
+		TrustDomain   string
 	}
 
 	seenSources := make(map[qualifiedServiceName]struct{})
@@ -717,6 +724,14 @@ func (e *ServiceIntentionsConfigEntry) validate(legacyWrite bool) error {
 			return fmt.Errorf("Sources[%d].SamenessGroup: cannot set SamenessGroup and Peer at the same time", i)
 		}
 
+		if strings.Contains(src.TrustDomain, WildcardSpecifier) {
+			return fmt.Errorf("Sources[%d].TrustDomain: cannot use wildcard '*' in trust domain", i)
+		}
+
+		if src.TrustDomain != "" && (src.Peer != "" || src.SamenessGroup != "") {
+			return fmt.Errorf("Sources[%d].TrustDomain: cannot set TrustDomain with Peer or SamenessGroup", i)
+		}
+
 		// Length of opaque values
 		if len(src.Description) > metaValueMaxLength {
 			return fmt.Errorf(
@@ -732,6 +747,10 @@ func (e *ServiceIntentionsConfigEntry) validate(legacyWrite bool) error {
 				return fmt.Errorf("Sources[%d].SamenessGroup cannot be set by legacy intentions", i)
 			}
 
+			if src.TrustDomain != "" {
+				return fmt.Errorf("Sources[%d].TrustDomain cannot be set by legacy intentions", i)
+			}
+
 			if len(src.LegacyMeta) > metaMaxKeyPairs {
 				return fmt.Errorf(
 					"Sources[%d].Meta exceeds maximum element count %d", i, metaMaxKeyPairs)
@@ -895,12 +914,14 @@ func (e *ServiceIntentionsConfigEntry) validate(legacyWrite bool) error {
 			}
 		}
 
-		qsn := qualifiedServiceName{Peer: src.Peer, SamenessGroup: src.SamenessGroup, ServiceName: src.SourceServiceName()}
+		qsn := qualifiedServiceName{Peer: src.Peer, SamenessGroup: src.SamenessGroup, TrustDomain: src.TrustDomain, ServiceName: src.SourceServiceName()}
 		if _, exists := seenSources[qsn]; exists {
 			if qsn.Peer != "" {
 				return fmt.Errorf("Sources[%d] defines peer(%q) %q more than once", i, qsn.Peer, qsn.ServiceName.String())
 			} else if qsn.SamenessGroup != "" {
 				return fmt.Errorf("Sources[%d] defines sameness-group(%q) %q more than once", i, qsn.SamenessGroup, qsn.ServiceName.String())
+			} else if qsn.TrustDomain != "" {
+				return fmt.Errorf("Sources[%d] defines trust-domain(%q) %q more than once", i, qsn.TrustDomain, qsn.ServiceName.String())
 			} else {
 				return fmt.Errorf("Sources[%d] defines %q more than once", i, qsn.ServiceName.String())
 			}
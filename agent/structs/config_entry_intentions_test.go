@@ -1265,6 +1265,71 @@ func TestServiceIntentionsConfigEntry(t *testing.T) {
 			},
 			validateErr: `Sources[1] defines peer("peer1") "` + fooName.String() + `" more than once`,
 		},
+		"local and federated trust domain intentions are different": {
+			entry: &ServiceIntentionsConfigEntry{
+				Kind: ServiceIntentions,
+				Name: "test",
+				Sources: []*SourceIntention{
+					{
+						Name:   "foo",
+						Action: IntentionActionAllow,
+					},
+					{
+						Name:        "foo",
+						TrustDomain: "example.org",
+						Action:      IntentionActionAllow,
+					},
+				},
+			},
+		},
+		"already have a trust domain intention for source": {
+			entry: &ServiceIntentionsConfigEntry{
+				Kind: ServiceIntentions,
+				Name: "test",
+				Sources: []*SourceIntention{
+					{
+						Name:        "foo",
+						TrustDomain: "example.org",
+						Action:      IntentionActionAllow,
+					},
+					{
+						Name:        "foo",
+						TrustDomain: "example.org",
+						Action:      IntentionActionDeny,
+					},
+				},
+			},
+			validateErr: `Sources[1] defines trust-domain("example.org") "` + fooName.String() + `" more than once`,
+		},
+		"wildcard trust domain": {
+			entry: &ServiceIntentionsConfigEntry{
+				Kind: ServiceIntentions,
+				Name: "test",
+				Sources: []*SourceIntention{
+					{
+						Name:        "foo",
+						TrustDomain: "*",
+						Action:      IntentionActionAllow,
+					},
+				},
+			},
+			validateErr: `Sources[0].TrustDomain: cannot use wildcard '*' in trust domain`,
+		},
+		"trust domain and peer": {
+			entry: &ServiceIntentionsConfigEntry{
+				Kind: ServiceIntentions,
+				Name: "test",
+				Sources: []*SourceIntention{
+					{
+						Name:        "foo",
+						Peer:        "peer1",
+						TrustDomain: "example.org",
+						Action:      IntentionActionAllow,
+					},
+				},
+			},
+			validateErr: `Sources[0].TrustDomain: cannot set TrustDomain with Peer or SamenessGroup`,
+		},
 		"JWT - missing provider name": {
 			entry: &ServiceIntentionsConfigEntry{
 				Kind: ServiceIntentions,
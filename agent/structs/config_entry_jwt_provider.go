@@ -16,6 +16,11 @@ import (
 const (
 	DefaultClockSkewSeconds = 30
 
+	// JWTProviderIssuerConsul is the Issuer of the providers that verify the
+	// JWT-SVIDs issued by Consul. Such providers have no JSONWebKeySet, the
+	// keys of Consul's CA are used instead.
+	JWTProviderIssuerConsul = "consul"
+
 	DiscoveryTypeStrictDNS   ClusterDiscoveryType = "STRICT_DNS"
 	DiscoveryTypeStatic      ClusterDiscoveryType = "STATIC"
 	DiscoveryTypeLogicalDNS  ClusterDiscoveryType = "LOGICAL_DNS"
@@ -36,6 +41,9 @@ type JWTProviderConfigEntry struct {
 
 	// Issuer is the entity that must have issued the JWT.
 	// This value must match the "iss" claim of the token.
+	//
+	// The "consul" issuer refers to the JWT-SVIDs issued by Consul itself,
+	// in which case JSONWebKeySet must not be set.
 	Issuer string `json:",omitempty"`
 
 	// Audiences is the set of audiences the JWT is allowed to access.
@@ -513,12 +521,18 @@ func (e *JWTProviderConfigEntry) Validate() error {
 		return err
 	}
 
-	if e.JSONWebKeySet == nil {
-		return fmt.Errorf("JSONWebKeySet is required")
-	}
+	if e.Issuer == JWTProviderIssuerConsul {
+		if e.JSONWebKeySet != nil {
+			return fmt.Errorf("JSONWebKeySet must not be set when Issuer is %q", JWTProviderIssuerConsul)
+		}
+	} else {
+		if e.JSONWebKeySet == nil {
+			return fmt.Errorf("JSONWebKeySet is required")
+		}
 
-	if err := e.JSONWebKeySet.Validate(); err != nil {
-		return err
+		if err := e.JSONWebKeySet.Validate(); err != nil {
+			return err
+		}
 	}
 
 	if err := validateLocations(e.Locations); err != nil {
@@ -94,6 +94,35 @@ func TestJWTProviderConfigEntry_ValidateAndNormalize(t *testing.T) {
 				EnterpriseMeta:   *defaultMeta,
 			},
 		},
+		"valid jwt-provider - consul issuer": {
+			entry: &JWTProviderConfigEntry{
+				Kind:      JWTProvider,
+				Name:      "consul",
+				Issuer:    JWTProviderIssuerConsul,
+				Audiences: []string{"api"},
+			},
+			expected: &JWTProviderConfigEntry{
+				Kind:             JWTProvider,
+				Name:             "consul",
+				Issuer:           JWTProviderIssuerConsul,
+				Audiences:        []string{"api"},
+				ClockSkewSeconds: DefaultClockSkewSeconds,
+				EnterpriseMeta:   *defaultMeta,
+			},
+		},
+		"invalid jwt-provider - consul issuer with jwks": {
+			entry: &JWTProviderConfigEntry{
+				Kind:   JWTProvider,
+				Name:   "consul",
+				Issuer: JWTProviderIssuerConsul,
+				JSONWebKeySet: &JSONWebKeySet{
+					Local: &LocalJWKS{
+						Filename: "jwks.txt",
+					},
+				},
+			},
+			validateErr: "JSONWebKeySet must not be set when Issuer is \"consul\"",
+		},
 		"invalid jwt-provider - no name": {
 			entry: &JWTProviderConfigEntry{
 				Kind: JWTProvider,
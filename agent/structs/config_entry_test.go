@@ -2891,6 +2891,23 @@ func TestServiceConfigEntry(t *testing.T) {
 				},
 			},
 		},
+		"validate: http3 with http protocol": {
+			entry: &ServiceConfigEntry{
+				Kind:        ServiceDefaults,
+				Name:        "web",
+				Protocol:    "http",
+				EnableHTTP3: true,
+			},
+		},
+		"validate: http3 with tcp protocol": {
+			entry: &ServiceConfigEntry{
+				Kind:        ServiceDefaults,
+				Name:        "web",
+				Protocol:    "tcp",
+				EnableHTTP3: true,
+			},
+			validateErr: `enable_http3 is only supported for http, http2 and grpc protocols, not "tcp"`,
+		},
 		"validate: invalid MutualTLSMode in service-defaults": {
 			entry: &ServiceConfigEntry{
 				Kind:          ServiceDefaults,
@@ -5,7 +5,9 @@ package structs
 
 import (
 	"fmt"
+	"math/big"
 	"reflect"
+	"strings"
 	"time"
 
 	"github.com/hernad/consul/lib/stringslice"
@@ -56,6 +58,31 @@ type IndexedCARoots struct {
 	// Roots is a list of root CA certs to trust.
 	Roots []*CARoot
 
+	// Revocations is the list of leaf certificates and service identities
+	// that must no longer be trusted even though they chain to one of Roots.
+	Revocations []*CARevocation `json:",omitempty"`
+
+	// RevocationLists are the certificate revocation lists of the revoked
+	// serial numbers, one for each key that signs leaf certificates in any
+	// datacenter. They are only set when every datacenter has published its
+	// list, since a proxy checking them rejects every certificate whose issuer
+	// has no list. A datacenter whose CA provider can't sign lists, such as
+	// Vault or AWS, never publishes one.
+	RevocationLists []*CARevocationList `json:",omitempty"`
+
+	// FederatedTrustDomains lists the SPIFFE trust domains not managed by
+	// Consul whose workload identities are accepted as service identities. It
+	// is copied from the CA configuration.
+	FederatedTrustDomains []FederatedTrustDomain `json:",omitempty"`
+
+	// FederatedTrustBundles are the roots of the federated trust domains that
+	// have a bundle endpoint, as last fetched by the leader.
+	FederatedTrustBundles []*FederatedTrustBundle `json:",omitempty"`
+
+	// JWTSigningKeys are the public keys used to verify the JWT-SVIDs issued
+	// by the primary datacenter. Their private keys are never included.
+	JWTSigningKeys []*CAJWTSigningKey `json:",omitempty"`
+
 	// QueryMeta contains the meta sent via a header. We ignore for JSON
 	// so this whole structure can be returned.
 	QueryMeta `json:"-"`
@@ -70,6 +97,33 @@ func (r IndexedCARoots) Active() *CARoot {
 	return nil
 }
 
+// SerialRevoked returns the revocation of the leaf certificate with the given
+// serial number, or nil if it has not been revoked.
+func (r IndexedCARoots) SerialRevoked(serial string) *CARevocation {
+	if serial == "" {
+		return nil
+	}
+	for _, rev := range r.Revocations {
+		if rev.SerialNumber == serial {
+			return rev
+		}
+	}
+	return nil
+}
+
+// ServiceRevoked returns the revocation of the given service identity, or nil
+// if it has not been revoked.
+func (r IndexedCARoots) ServiceRevoked(service string, entMeta *acl.EnterpriseMeta) *CARevocation {
+	for _, rev := range r.Revocations {
+		if rev.Service == service &&
+			rev.NamespaceOrDefault() == entMeta.NamespaceOrDefault() &&
+			rev.PartitionOrDefault() == entMeta.PartitionOrDefault() {
+			return rev
+		}
+	}
+	return nil
+}
+
 // CARoot represents a root CA certificate that is trusted.
 type CARoot struct {
 	// ID is a globally unique ID (UUID) representing this CA chain. It is
@@ -247,6 +301,162 @@ type IssuedCert struct {
 	RaftIndex
 }
 
+// CARevocation revokes either a single leaf certificate, identified by its
+// serial number, or every certificate issued to a service identity.
+//
+// Revoking a service identity also stops the servers from signing new
+// certificates for it until the revocation is deleted.
+type CARevocation struct {
+	// ID is a unique ID (UUID) for the revocation.
+	ID string
+
+	// SerialNumber is the serial number of the revoked leaf certificate. It is
+	// encoded in standard hex separated by :, like IssuedCert.SerialNumber.
+	SerialNumber string `json:",omitempty"`
+
+	// Service is the name of the revoked service identity.
+	Service string `json:",omitempty"`
+
+	// Reason is an optional human readable description of why the
+	// certificate or identity was revoked.
+	Reason string `json:",omitempty"`
+
+	// RevokedAt is the time at which the revocation was created.
+	RevokedAt time.Time
+
+	// ExpiresAt is the latest time at which the certificate revoked by
+	// SerialNumber expires. The revocation is deleted after that time since
+	// the certificate is no longer trusted anyway. It is not set for service
+	// identity revocations.
+	ExpiresAt time.Time
+
+	// EnterpriseMeta is the namespace and partition of Service.
+	acl.EnterpriseMeta
+
+	RaftIndex
+}
+
+// Validate checks that exactly one of SerialNumber and Service is set.
+func (r *CARevocation) Validate() error {
+	switch {
+	case r.SerialNumber == "" && r.Service == "":
+		return fmt.Errorf("a revocation must have either a SerialNumber or a Service")
+	case r.SerialNumber != "" && r.Service != "":
+		return fmt.Errorf("a revocation must not have both a SerialNumber and a Service")
+	case r.Service == WildcardSpecifier:
+		return fmt.Errorf("cannot revoke the wildcard service identity")
+	}
+	if r.SerialNumber != "" {
+		n, ok := new(big.Int).SetString(strings.ReplaceAll(r.SerialNumber, ":", ""), 16)
+		if !ok || n.Sign() <= 0 {
+			return fmt.Errorf("invalid serial number %q: must be hex encoded", r.SerialNumber)
+		}
+	}
+	return nil
+}
+
+// CARevocationList is a certificate revocation list of the revoked serial
+// numbers, signed by a key that signs leaf certificates. Each datacenter signs
+// the lists of its own keys and publishes them in the primary datacenter,
+// which replicates them to every datacenter.
+type CARevocationList struct {
+	// SigningKeyID is the connect.HexString encoded id of the key that signed
+	// the list, like CARoot.SigningKeyID.
+	SigningKeyID string
+
+	// Datacenter is the datacenter signing leaf certificates with the key.
+	Datacenter string
+
+	// PEM is the PEM encoded revocation list.
+	PEM string
+
+	// NotAfter is the expiry of the certificate of the signing key. The list
+	// is deleted after that time since all the leaf certificates it signed
+	// have expired.
+	NotAfter time.Time
+
+	// CreatedAt is the time at which the first list of the key was signed.
+	CreatedAt time.Time
+
+	// Complete is true once every unexpired leaf certificate of Datacenter
+	// was signed by a key that has a list.
+	Complete bool
+
+	RaftIndex
+}
+
+// CAJWTSigningKey is a key used by the primary datacenter to sign the
+// JWT-SVIDs issued to services. Keys are rotated periodically and the keys
+// that were rotated out are kept until the tokens they signed have expired.
+type CAJWTSigningKey struct {
+	// ID is the key ID, used as the "kid" of the tokens and of the key set.
+	ID string
+
+	// PublicKey is the PEM encoded public key.
+	PublicKey string
+
+	// PrivateKey is the PEM encoded private key. It is only stored in the
+	// primary datacenter and is never returned by RPCs.
+	PrivateKey string `json:",omitempty"`
+
+	// Active is true for the single key used to sign new tokens.
+	Active bool
+
+	// CreatedAt is the time at which the key was generated.
+	CreatedAt time.Time
+
+	// RotatedOutAt is the time at which the key stopped being the active key.
+	RotatedOutAt time.Time
+
+	RaftIndex
+}
+
+// CAJWTSVIDRequest is the request to issue a JWT-SVID to a service.
+type CAJWTSVIDRequest struct {
+	// Datacenter is the target for this request.
+	Datacenter string
+
+	// SourceDatacenter is the datacenter of the service, used in its SPIFFE ID.
+	// It is set when a secondary datacenter forwards the request to the
+	// primary datacenter, which holds the signing keys.
+	SourceDatacenter string
+
+	// Service is the name of the service the token is issued to.
+	Service string
+
+	// Audience is the list of audiences of the token, at least one is
+	// required.
+	Audience []string
+
+	// TTL is how long the token is valid for. It defaults to
+	// connect.DefaultJWTSVIDTTL and cannot exceed connect.MaxJWTSVIDTTL.
+	TTL time.Duration
+
+	// EnterpriseMeta is the namespace and partition of Service.
+	acl.EnterpriseMeta
+
+	// WriteRequest is a common struct containing ACL tokens and other
+	// write-related common elements for requests.
+	WriteRequest
+}
+
+// RequestDatacenter returns the datacenter for a given request.
+func (q *CAJWTSVIDRequest) RequestDatacenter() string {
+	return q.Datacenter
+}
+
+// JWTSVID is a JWT-SVID issued to a service.
+type JWTSVID struct {
+	// Token is the signed JWT.
+	Token string
+
+	// SpiffeID is the SPIFFE ID of the service, the "sub" claim of the token.
+	SpiffeID string
+
+	// ExpiresAt is the "exp" claim of the token.
+	ExpiresAt time.Time
+}
+
 // CAOp is the operation for a request related to intentions.
 type CAOp string
 
@@ -257,6 +467,14 @@ const (
 	CAOpDeleteProviderState           CAOp = "delete-provider-state"
 	CAOpSetRootsAndConfig             CAOp = "set-roots-config"
 	CAOpIncrementProviderSerialNumber CAOp = "increment-provider-serial"
+	CAOpSetRevocations                CAOp = "set-revocations"
+	CAOpDeleteRevocations             CAOp = "delete-revocations"
+	CAOpSetFederatedTrustBundles      CAOp = "set-federated-trust-bundles"
+	CAOpDeleteFederatedTrustBundles   CAOp = "delete-federated-trust-bundles"
+	CAOpSetJWTSigningKeys             CAOp = "set-jwt-signing-keys"
+	CAOpDeleteJWTSigningKeys          CAOp = "delete-jwt-signing-keys"
+	CAOpSetRevocationLists            CAOp = "set-revocation-lists"
+	CAOpDeleteRevocationLists         CAOp = "delete-revocation-lists"
 )
 
 // CARequest is used to modify connect CA data. This is used by the
@@ -282,6 +500,25 @@ type CARequest struct {
 	// ProviderState is the state for the builtin CA provider.
 	ProviderState *CAConsulProviderState
 
+	// Revocations is used by CAOpSetRevocations to add or replace
+	// revocations, and by CAOpDeleteRevocations to delete them by ID.
+	Revocations []*CARevocation
+
+	// FederatedTrustBundles is used by CAOpSetFederatedTrustBundles to add or
+	// replace trust bundles, and by CAOpDeleteFederatedTrustBundles to delete
+	// them by trust domain.
+	FederatedTrustBundles []*FederatedTrustBundle
+
+	// JWTSigningKeys is used by CAOpSetJWTSigningKeys to add or replace
+	// JWT-SVID signing keys, and by CAOpDeleteJWTSigningKeys to delete them
+	// by ID.
+	JWTSigningKeys []*CAJWTSigningKey
+
+	// RevocationLists is used by CAOpSetRevocationLists to add or replace
+	// certificate revocation lists, and by CAOpDeleteRevocationLists to delete
+	// them by SigningKeyID.
+	RevocationLists []*CARevocationList
+
 	// WriteRequest is a common struct containing ACL tokens and other
 	// write-related common elements for requests.
 	WriteRequest
@@ -292,10 +529,157 @@ func (q *CARequest) RequestDatacenter() string {
 	return q.Datacenter
 }
 
+// CARevocationRequest is used to revoke a leaf certificate or a service
+// identity, or to delete a revocation.
+type CARevocationRequest struct {
+	// Datacenter is the target for this request. Revocations are always
+	// stored in the primary datacenter.
+	Datacenter string
+
+	// Revocation is the revocation to create. When deleting a revocation only
+	// its ID is used.
+	Revocation *CARevocation
+
+	// WriteRequest is a common struct containing ACL tokens and other
+	// write-related common elements for requests.
+	WriteRequest
+}
+
+// RequestDatacenter returns the datacenter for a given request.
+func (q *CARevocationRequest) RequestDatacenter() string {
+	return q.Datacenter
+}
+
+// CARevocationListRequest is used by the secondary datacenters to publish
+// the revocation lists signed by their keys in the primary datacenter.
+type CARevocationListRequest struct {
+	// Datacenter is the target for this request, always the primary
+	// datacenter.
+	Datacenter string
+
+	// RevocationLists are the current lists of SourceDatacenter. The lists of
+	// that datacenter that are not included are deleted.
+	RevocationLists []*CARevocationList
+
+	// SourceDatacenter is the datacenter that signed the lists.
+	SourceDatacenter string
+
+	// WriteRequest is a common struct containing ACL tokens and other
+	// write-related common elements for requests.
+	WriteRequest
+}
+
+// RequestDatacenter returns the datacenter for a given request.
+func (q *CARevocationListRequest) RequestDatacenter() string {
+	return q.Datacenter
+}
+
+// CADryRunCrossSigning describes how a dry run expects the current root to
+// vouch for the new one.
+type CADryRunCrossSigning string
+
+const (
+	// CADryRunCrossSigningNotRequired is used when the change does not rotate
+	// the root.
+	CADryRunCrossSigningNotRequired CADryRunCrossSigning = "not-required"
+
+	// CADryRunCrossSigningSucceeded is used when a copy of the current
+	// provider, writing its state to a scratch store, successfully
+	// cross-signed the new root.
+	CADryRunCrossSigningSucceeded CADryRunCrossSigning = "cross-signed"
+
+	// CADryRunCrossSigningSupported is used when the current provider supports
+	// cross-signing but the new root could not be generated, or cross-signed
+	// by the current provider, without side effects, so it was not attempted.
+	CADryRunCrossSigningSupported CADryRunCrossSigning = "supported"
+
+	// CADryRunCrossSigningSkipped is used when cross-signing would be skipped
+	// because ForceWithoutCrossSigning is set.
+	CADryRunCrossSigningSkipped CADryRunCrossSigning = "skipped"
+)
+
+// CADryRunReport is the result of validating a new CA configuration without
+// applying it. It describes what applying the configuration would change and
+// which parts of the mesh would be affected.
+type CADryRunReport struct {
+	// Provider is the provider of the new configuration.
+	Provider string
+
+	// NoOp is true when the new configuration is identical to the current one.
+	NoOp bool
+
+	// RootRotation is true when applying the configuration would rotate the
+	// root certificate. It is only set in the primary datacenter.
+	RootRotation bool
+
+	// IntermediateRotation is true when applying the configuration in a
+	// secondary datacenter would require a new intermediate certificate.
+	IntermediateRotation bool
+
+	// CurrentRoot is the root certificate that is active now and NewRoot the
+	// one that would be active after the change. NewRoot is only set when the
+	// new root could be generated without side effects.
+	CurrentRoot *CADryRunRoot `json:",omitempty"`
+	NewRoot     *CADryRunRoot `json:",omitempty"`
+
+	// CrossSigning describes how the current root would vouch for the new one.
+	CrossSigning CADryRunCrossSigning
+
+	// Datacenters, Peers and Gateways list the parts of the mesh that would
+	// have to pick up new certificates, and Proxies is the number of proxy
+	// instances in this datacenter that would need new leaf certificates.
+	Datacenters []CADryRunDatacenter
+	Peers       []CADryRunPeer
+	Gateways    []CADryRunGateway
+	Proxies     int
+
+	// Warnings lists the problems that would not prevent the configuration
+	// from being applied but are likely to cause disruption.
+	Warnings []string
+}
+
+// CADryRunRoot summarizes a root certificate in a CADryRunReport.
+type CADryRunRoot struct {
+	ID             string
+	PrivateKeyType string
+	PrivateKeyBits int
+	NotAfter       time.Time
+}
+
+// CADryRunDatacenter describes a datacenter affected by a CA change.
+type CADryRunDatacenter struct {
+	Datacenter     string
+	Primary        bool
+	Provider       string `json:",omitempty"`
+	PrivateKeyType string `json:",omitempty"`
+	PrivateKeyBits int    `json:",omitempty"`
+
+	// Error is set when the configuration of the datacenter could not be read.
+	Error string `json:",omitempty"`
+}
+
+// CADryRunPeer describes a cluster peer that would receive the new root.
+type CADryRunPeer struct {
+	Name      string
+	Partition string `json:",omitempty"`
+	State     string
+}
+
+// CADryRunGateway describes a gateway service whose instances would need new
+// leaf certificates.
+type CADryRunGateway struct {
+	Kind      ServiceKind
+	Name      string
+	Instances int
+
+	acl.EnterpriseMeta
+}
+
 const (
-	ConsulCAProvider = "consul"
-	VaultCAProvider  = "vault"
-	AWSCAProvider    = "aws-pca"
+	ConsulCAProvider   = "consul"
+	VaultCAProvider    = "vault"
+	AWSCAProvider      = "aws-pca"
+	ExternalCAProvider = "external"
 )
 
 // CAConfiguration is the configuration for the current CA plugin.
@@ -431,6 +815,63 @@ type CommonCAProviderConfig struct {
 	// name. As with PrivateKeyType this is only relevant whan the provier is
 	// generating new CA keys (root or intermediate).
 	PrivateKeyBits int
+
+	// FederatedTrustDomains lists the SPIFFE trust domains not managed by
+	// Consul, for example by a SPIRE server, whose workload identities are
+	// accepted as service identities by intentions and proxies.
+	FederatedTrustDomains []FederatedTrustDomain
+}
+
+// FederatedTrustDomain describes how the SPIFFE IDs of a trust domain not
+// managed by Consul map to service identities.
+type FederatedTrustDomain struct {
+	// Name is the trust domain, for example "example.org".
+	Name string
+
+	// SpiffeIDTemplates are the templates of the paths of the SPIFFE IDs of
+	// services in the trust domain, for example "/ns/{namespace}/sa/{service}".
+	// The {service}, {namespace}, {partition} and {datacenter} placeholders
+	// each match a single path segment and {service} is required.
+	SpiffeIDTemplates []string
+
+	// BundleEndpointURL is the HTTPS URL of the SPIFFE bundle endpoint of the
+	// trust domain. When it is set the roots of the trust domain are fetched
+	// from it, otherwise the certificates of the trust domain must be issued
+	// by an authority chained to Consul's CA.
+	BundleEndpointURL string `json:",omitempty"`
+
+	// BundleEndpointCACert is the PEM encoded CA certificate used to verify
+	// the bundle endpoint. The system roots are used when it is empty.
+	BundleEndpointCACert string `json:",omitempty"`
+}
+
+// FederatedTrustBundle holds the roots of a federated trust domain fetched
+// from its SPIFFE bundle endpoint.
+type FederatedTrustBundle struct {
+	// TrustDomain is the name of the federated trust domain.
+	TrustDomain string
+
+	// RootPEMs are the PEM encoded X.509 authorities of the trust domain.
+	RootPEMs []string
+
+	// Sequence is the spiffe_sequence of the fetched bundle.
+	Sequence uint64 `json:",omitempty"`
+
+	// RefreshHint is how often the bundle endpoint asks to be polled.
+	RefreshHint time.Duration `json:",omitempty"`
+
+	RaftIndex
+}
+
+// FederatedTrustBundle returns the trust bundle fetched for the given trust
+// domain, or nil if there is none.
+func (r IndexedCARoots) FederatedTrustBundle(trustDomain string) *FederatedTrustBundle {
+	for _, b := range r.FederatedTrustBundles {
+		if b.TrustDomain == trustDomain {
+			return b
+		}
+	}
+	return nil
 }
 
 var MinLeafCertTTL = time.Hour
@@ -513,9 +954,37 @@ type ConsulCAProviderConfig struct {
 	// cross sign. We don't document this config field publicly or make any
 	// attempt to parse it from snake case unlike other fields here.
 	DisableCrossSigning bool
+
+	// PKCS11 configures the provider to create its private keys in a PKCS#11
+	// token, such as an HSM, instead of keeping them in the state store.
+	PKCS11 *ConsulCAPKCS11Config
+}
+
+// ConsulCAPKCS11Config holds the settings used by the built-in provider to
+// reach a PKCS#11 token.
+type ConsulCAPKCS11Config struct {
+	// Lib is the path to the PKCS#11 module to load.
+	Lib string
+	// TokenLabel is the label of the token that holds the keys.
+	TokenLabel string
+	// PIN is the user PIN used to log in to the token.
+	PIN string
+	// KeyLabel is the label given to the keys created in the token.
+	KeyLabel string
 }
 
 func (c *ConsulCAProviderConfig) Validate() error {
+	if c.PKCS11 != nil {
+		if c.PKCS11.Lib == "" {
+			return fmt.Errorf("PKCS11.Lib is required when using a PKCS#11 token")
+		}
+		if c.PKCS11.TokenLabel == "" {
+			return fmt.Errorf("PKCS11.TokenLabel is required when using a PKCS#11 token")
+		}
+		if c.PrivateKey != "" {
+			return fmt.Errorf("PrivateKey cannot be set when using a PKCS#11 token")
+		}
+	}
 	return nil
 }
 
@@ -526,6 +995,10 @@ type CAConsulProviderState struct {
 	RootCert         string
 	IntermediateCert string
 
+	// PrivateKeyHandle identifies the private key in the PKCS#11 token when
+	// the provider is configured to use one. PrivateKey is empty in that case.
+	PrivateKeyHandle string `json:",omitempty"`
+
 	RaftIndex
 }
 
@@ -563,6 +1036,29 @@ type AWSCAProviderConfig struct {
 	DeleteOnExit bool
 }
 
+// ExternalCAProviderConfig configures the provider that gets its intermediate
+// certificates from an external issuer exposing a cfssl compatible API.
+type ExternalCAProviderConfig struct {
+	CommonCAProviderConfig `mapstructure:",squash"`
+
+	// Address is the base URL of the issuer's API.
+	Address string
+	// Label selects the signer to use on issuers with several signers.
+	Label string
+	// Profile is the signing profile requested for the intermediates.
+	Profile string
+	// AuthKey is the hex encoded HMAC key used to authenticate the signing
+	// requests. Unauthenticated requests are made when it is empty.
+	AuthKey string `alias:"auth_key"`
+
+	CAFile        string
+	CAPath        string
+	CertFile      string
+	KeyFile       string
+	TLSServerName string
+	TLSSkipVerify bool
+}
+
 // CALeafOp is the operation for a request related to leaf certificates.
 type CALeafOp string
 
@@ -239,6 +239,10 @@ type DiscoveryTarget struct {
 	Name string `json:",omitempty"`
 
 	PrioritizeByLocality *DiscoveryPrioritizeByLocality `json:",omitempty"`
+
+	// EnableHTTP3 is true if the service opted into HTTP/3 and is reached
+	// directly, without going through a mesh gateway.
+	EnableHTTP3 bool `json:",omitempty"`
 }
 
 func (t *DiscoveryTarget) MarshalJSON() ([]byte, error) {
@@ -70,6 +70,11 @@ type Intention struct {
 	// same level of tenancy (sameness group includes both partitions and cluster peers).
 	SourceSamenessGroup string `json:",omitempty"`
 
+	// SourceTrustDomain is the federated SPIFFE trust domain of the source,
+	// see SourceIntention.TrustDomain. It cannot be a wildcard "*" and is
+	// not compatible with legacy intentions, SourcePeer or SourceSamenessGroup.
+	SourceTrustDomain string `json:",omitempty"`
+
 	// SourceType is the type of the value for the source.
 	SourceType IntentionSourceType
 
@@ -327,9 +332,9 @@ func (ixn *Intention) CanRead(authz acl.Authorizer) bool {
 	// complete intention. This is so that both ends can be aware of why
 	// something does or does not work.
 
-	// If SourcePeer is set, tenancy is irrelevant in the context of the local cluster
-	// so we skip authorizing on the Source end.
-	if ixn.SourceName != "" && ixn.SourcePeer == "" {
+	// If SourcePeer or SourceTrustDomain is set, tenancy is irrelevant in the
+	// context of the local cluster so we skip authorizing on the Source end.
+	if ixn.SourceName != "" && ixn.SourcePeer == "" && ixn.SourceTrustDomain == "" {
 		ixn.FillAuthzContext(&authzContext, false)
 		if authz.IntentionRead(ixn.SourceName, &authzContext) == acl.Allow {
 			return true
@@ -423,6 +428,9 @@ func (x *Intention) String() string {
 	if x.SourceSamenessGroup != "" {
 		srcClusterPart = "sameness-group(" + x.SourceSamenessGroup + ")/"
 	}
+	if x.SourceTrustDomain != "" {
+		srcClusterPart = "trust-domain(" + x.SourceTrustDomain + ")/"
+	}
 
 	var dstPartitionPart string
 	if x.DestinationPartition != "" {
@@ -488,6 +496,7 @@ func (x *Intention) ToSourceIntention(legacy bool) *SourceIntention {
 		EnterpriseMeta:   *x.SourceEnterpriseMeta(),
 		Peer:             x.SourcePeer,
 		SamenessGroup:    x.SourceSamenessGroup,
+		TrustDomain:      x.SourceTrustDomain,
 		Action:           x.Action,
 		Permissions:      nil, // explicitly not symmetric with the old APIs
 		Precedence:       0,   // Ignore, let it be computed.
@@ -802,7 +811,7 @@ func (s IntentionPrecedenceSorter) Less(i, j int) bool {
 
 	// Tie break on lexicographic order of the tuple in canonical form:
 	//
-	//   (SrcSamenessGroup, SrcPeer, SrcPxn, SrcNS, Src, DstPxn, DstNS, Dst)
+	//   (SrcSamenessGroup, SrcPeer, SrcTrustDomain, SrcPxn, SrcNS, Src, DstPxn, DstNS, Dst)
 	//
 	// This is arbitrary but it keeps sorting deterministic which is a nice
 	// property for consistency. It is arguably open to abuse if implementations
@@ -815,6 +824,9 @@ func (s IntentionPrecedenceSorter) Less(i, j int) bool {
 	if a.SourcePeer != b.SourcePeer {
 		return a.SourcePeer < b.SourcePeer
 	}
+	if a.SourceTrustDomain != b.SourceTrustDomain {
+		return a.SourceTrustDomain < b.SourceTrustDomain
+	}
 	if a.SourcePartition != b.SourcePartition {
 		return a.SourcePartition < b.SourcePartition
 	}
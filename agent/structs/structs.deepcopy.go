@@ -495,6 +495,60 @@ func (o *IndexedCARoots) DeepCopy() *IndexedCARoots {
 			}
 		}
 	}
+	if o.Revocations != nil {
+		cp.Revocations = make([]*CARevocation, len(o.Revocations))
+		copy(cp.Revocations, o.Revocations)
+		for i2 := range o.Revocations {
+			if o.Revocations[i2] != nil {
+				cp.Revocations[i2] = new(CARevocation)
+				*cp.Revocations[i2] = *o.Revocations[i2]
+			}
+		}
+	}
+	if o.RevocationLists != nil {
+		cp.RevocationLists = make([]*CARevocationList, len(o.RevocationLists))
+		copy(cp.RevocationLists, o.RevocationLists)
+		for i2 := range o.RevocationLists {
+			if o.RevocationLists[i2] != nil {
+				cp.RevocationLists[i2] = new(CARevocationList)
+				*cp.RevocationLists[i2] = *o.RevocationLists[i2]
+			}
+		}
+	}
+	if o.FederatedTrustDomains != nil {
+		cp.FederatedTrustDomains = make([]FederatedTrustDomain, len(o.FederatedTrustDomains))
+		copy(cp.FederatedTrustDomains, o.FederatedTrustDomains)
+		for i2 := range o.FederatedTrustDomains {
+			if o.FederatedTrustDomains[i2].SpiffeIDTemplates != nil {
+				cp.FederatedTrustDomains[i2].SpiffeIDTemplates = make([]string, len(o.FederatedTrustDomains[i2].SpiffeIDTemplates))
+				copy(cp.FederatedTrustDomains[i2].SpiffeIDTemplates, o.FederatedTrustDomains[i2].SpiffeIDTemplates)
+			}
+		}
+	}
+	if o.FederatedTrustBundles != nil {
+		cp.FederatedTrustBundles = make([]*FederatedTrustBundle, len(o.FederatedTrustBundles))
+		copy(cp.FederatedTrustBundles, o.FederatedTrustBundles)
+		for i2 := range o.FederatedTrustBundles {
+			if o.FederatedTrustBundles[i2] != nil {
+				cp.FederatedTrustBundles[i2] = new(FederatedTrustBundle)
+				*cp.FederatedTrustBundles[i2] = *o.FederatedTrustBundles[i2]
+				if o.FederatedTrustBundles[i2].RootPEMs != nil {
+					cp.FederatedTrustBundles[i2].RootPEMs = make([]string, len(o.FederatedTrustBundles[i2].RootPEMs))
+					copy(cp.FederatedTrustBundles[i2].RootPEMs, o.FederatedTrustBundles[i2].RootPEMs)
+				}
+			}
+		}
+	}
+	if o.JWTSigningKeys != nil {
+		cp.JWTSigningKeys = make([]*CAJWTSigningKey, len(o.JWTSigningKeys))
+		copy(cp.JWTSigningKeys, o.JWTSigningKeys)
+		for i2 := range o.JWTSigningKeys {
+			if o.JWTSigningKeys[i2] != nil {
+				cp.JWTSigningKeys[i2] = new(CAJWTSigningKey)
+				*cp.JWTSigningKeys[i2] = *o.JWTSigningKeys[i2]
+			}
+		}
+	}
 	return &cp
 }
 
@@ -45,50 +45,57 @@ type RaftIndex struct {
 // These are serialized between Consul servers and stored in Consul snapshots,
 // so entries must only ever be added.
 const (
-	RegisterRequestType             MessageType = 0
-	DeregisterRequestType                       = 1
-	KVSRequestType                              = 2
-	SessionRequestType                          = 3
-	DeprecatedACLRequestType                    = 4 // Removed with the legacy ACL system
-	TombstoneRequestType                        = 5
-	CoordinateBatchUpdateType                   = 6
-	PreparedQueryRequestType                    = 7
-	TxnRequestType                              = 8
-	AutopilotRequestType                        = 9
-	AreaRequestType                             = 10
-	ACLBootstrapRequestType                     = 11
-	IntentionRequestType                        = 12
-	ConnectCARequestType                        = 13
-	ConnectCAProviderStateType                  = 14
-	ConnectCAConfigType                         = 15 // FSM snapshots only.
-	IndexRequestType                            = 16 // FSM snapshots only.
-	ACLTokenSetRequestType                      = 17
-	ACLTokenDeleteRequestType                   = 18
-	ACLPolicySetRequestType                     = 19
-	ACLPolicyDeleteRequestType                  = 20
-	ConnectCALeafRequestType                    = 21
-	ConfigEntryRequestType                      = 22
-	ACLRoleSetRequestType                       = 23
-	ACLRoleDeleteRequestType                    = 24
-	ACLBindingRuleSetRequestType                = 25
-	ACLBindingRuleDeleteRequestType             = 26
-	ACLAuthMethodSetRequestType                 = 27
-	ACLAuthMethodDeleteRequestType              = 28
-	ChunkingStateType                           = 29
-	FederationStateRequestType                  = 30
-	SystemMetadataRequestType                   = 31
-	ServiceVirtualIPRequestType                 = 32
-	FreeVirtualIPRequestType                    = 33
-	KindServiceNamesType                        = 34
-	PeeringWriteType                            = 35
-	PeeringDeleteType                           = 36
-	PeeringTerminateByIDType                    = 37
-	PeeringTrustBundleWriteType                 = 38
-	PeeringTrustBundleDeleteType                = 39
-	PeeringSecretsWriteType                     = 40
-	RaftLogVerifierCheckpoint                   = 41 // Only used for log verifier, no-op on FSM.
-	ResourceOperationType                       = 42
-	UpdateVirtualIPRequestType                  = 43
+	RegisterRequestType               MessageType = 0
+	DeregisterRequestType                         = 1
+	KVSRequestType                                = 2
+	SessionRequestType                            = 3
+	DeprecatedACLRequestType                      = 4 // Removed with the legacy ACL system
+	TombstoneRequestType                          = 5
+	CoordinateBatchUpdateType                     = 6
+	PreparedQueryRequestType                      = 7
+	TxnRequestType                                = 8
+	AutopilotRequestType                          = 9
+	AreaRequestType                               = 10
+	ACLBootstrapRequestType                       = 11
+	IntentionRequestType                          = 12
+	ConnectCARequestType                          = 13
+	ConnectCAProviderStateType                    = 14
+	ConnectCAConfigType                           = 15 // FSM snapshots only.
+	IndexRequestType                              = 16 // FSM snapshots only.
+	ACLTokenSetRequestType                        = 17
+	ACLTokenDeleteRequestType                     = 18
+	ACLPolicySetRequestType                       = 19
+	ACLPolicyDeleteRequestType                    = 20
+	ConnectCALeafRequestType                      = 21
+	ConfigEntryRequestType                        = 22
+	ACLRoleSetRequestType                         = 23
+	ACLRoleDeleteRequestType                      = 24
+	ACLBindingRuleSetRequestType                  = 25
+	ACLBindingRuleDeleteRequestType               = 26
+	ACLAuthMethodSetRequestType                   = 27
+	ACLAuthMethodDeleteRequestType                = 28
+	ChunkingStateType                             = 29
+	FederationStateRequestType                    = 30
+	SystemMetadataRequestType                     = 31
+	ServiceVirtualIPRequestType                   = 32
+	FreeVirtualIPRequestType                      = 33
+	KindServiceNamesType                          = 34
+	PeeringWriteType                              = 35
+	PeeringDeleteType                             = 36
+	PeeringTerminateByIDType                      = 37
+	PeeringTrustBundleWriteType                   = 38
+	PeeringTrustBundleDeleteType                  = 39
+	PeeringSecretsWriteType                       = 40
+	RaftLogVerifierCheckpoint                     = 41 // Only used for log verifier, no-op on FSM.
+	ResourceOperationType                         = 42
+	UpdateVirtualIPRequestType                    = 43
+	ACLPolicyTemplateSetType                      = 44
+	ACLPolicyTemplateDeleteType                   = 45
+	ACLTokenUsageUpdateType                       = 46
+	ConnectCARevocationType                       = 47 // FSM snapshots only.
+	ConnectCAFederatedTrustBundleType             = 48 // FSM snapshots only.
+	ConnectCAJWTSigningKeyType                    = 49 // FSM snapshots only.
+	ConnectCARevocationListType                   = 50 // FSM snapshots only.
 )
 
 const (
@@ -115,49 +122,56 @@ const (
 // requestTypeStrings is used for snapshot enhance
 // any new request types added must be placed here
 var requestTypeStrings = map[MessageType]string{
-	RegisterRequestType:             "Register",
-	DeregisterRequestType:           "Deregister",
-	KVSRequestType:                  "KVS",
-	SessionRequestType:              "Session",
-	DeprecatedACLRequestType:        "ACL", // DEPRECATED (ACL-Legacy-Compat)
-	TombstoneRequestType:            "Tombstone",
-	CoordinateBatchUpdateType:       "CoordinateBatchUpdate",
-	PreparedQueryRequestType:        "PreparedQuery",
-	TxnRequestType:                  "Txn",
-	AutopilotRequestType:            "Autopilot",
-	AreaRequestType:                 "Area",
-	ACLBootstrapRequestType:         "ACLBootstrap",
-	IntentionRequestType:            "Intention",
-	ConnectCARequestType:            "ConnectCA",
-	ConnectCAProviderStateType:      "ConnectCAProviderState",
-	ConnectCAConfigType:             "ConnectCAConfig", // FSM snapshots only.
-	IndexRequestType:                "Index",           // FSM snapshots only.
-	ACLTokenSetRequestType:          "ACLToken",
-	ACLTokenDeleteRequestType:       "ACLTokenDelete",
-	ACLPolicySetRequestType:         "ACLPolicy",
-	ACLPolicyDeleteRequestType:      "ACLPolicyDelete",
-	ConnectCALeafRequestType:        "ConnectCALeaf",
-	ConfigEntryRequestType:          "ConfigEntry",
-	ACLRoleSetRequestType:           "ACLRole",
-	ACLRoleDeleteRequestType:        "ACLRoleDelete",
-	ACLBindingRuleSetRequestType:    "ACLBindingRule",
-	ACLBindingRuleDeleteRequestType: "ACLBindingRuleDelete",
-	ACLAuthMethodSetRequestType:     "ACLAuthMethod",
-	ACLAuthMethodDeleteRequestType:  "ACLAuthMethodDelete",
-	ChunkingStateType:               "ChunkingState",
-	FederationStateRequestType:      "FederationState",
-	SystemMetadataRequestType:       "SystemMetadata",
-	ServiceVirtualIPRequestType:     "ServiceVirtualIP",
-	FreeVirtualIPRequestType:        "FreeVirtualIP",
-	KindServiceNamesType:            "KindServiceName",
-	PeeringWriteType:                "Peering",
-	PeeringDeleteType:               "PeeringDelete",
-	PeeringTrustBundleWriteType:     "PeeringTrustBundle",
-	PeeringTrustBundleDeleteType:    "PeeringTrustBundleDelete",
-	PeeringSecretsWriteType:         "PeeringSecret",
-	RaftLogVerifierCheckpoint:       "RaftLogVerifierCheckpoint",
-	ResourceOperationType:           "Resource",
-	UpdateVirtualIPRequestType:      "UpdateManualVirtualIPRequestType",
+	RegisterRequestType:               "Register",
+	DeregisterRequestType:             "Deregister",
+	KVSRequestType:                    "KVS",
+	SessionRequestType:                "Session",
+	DeprecatedACLRequestType:          "ACL", // DEPRECATED (ACL-Legacy-Compat)
+	TombstoneRequestType:              "Tombstone",
+	CoordinateBatchUpdateType:         "CoordinateBatchUpdate",
+	PreparedQueryRequestType:          "PreparedQuery",
+	TxnRequestType:                    "Txn",
+	AutopilotRequestType:              "Autopilot",
+	AreaRequestType:                   "Area",
+	ACLBootstrapRequestType:           "ACLBootstrap",
+	IntentionRequestType:              "Intention",
+	ConnectCARequestType:              "ConnectCA",
+	ConnectCAProviderStateType:        "ConnectCAProviderState",
+	ConnectCAConfigType:               "ConnectCAConfig", // FSM snapshots only.
+	IndexRequestType:                  "Index",           // FSM snapshots only.
+	ACLTokenSetRequestType:            "ACLToken",
+	ACLTokenDeleteRequestType:         "ACLTokenDelete",
+	ACLPolicySetRequestType:           "ACLPolicy",
+	ACLPolicyDeleteRequestType:        "ACLPolicyDelete",
+	ConnectCALeafRequestType:          "ConnectCALeaf",
+	ConfigEntryRequestType:            "ConfigEntry",
+	ACLRoleSetRequestType:             "ACLRole",
+	ACLRoleDeleteRequestType:          "ACLRoleDelete",
+	ACLBindingRuleSetRequestType:      "ACLBindingRule",
+	ACLBindingRuleDeleteRequestType:   "ACLBindingRuleDelete",
+	ACLAuthMethodSetRequestType:       "ACLAuthMethod",
+	ACLAuthMethodDeleteRequestType:    "ACLAuthMethodDelete",
+	ChunkingStateType:                 "ChunkingState",
+	FederationStateRequestType:        "FederationState",
+	SystemMetadataRequestType:         "SystemMetadata",
+	ServiceVirtualIPRequestType:       "ServiceVirtualIP",
+	FreeVirtualIPRequestType:          "FreeVirtualIP",
+	KindServiceNamesType:              "KindServiceName",
+	PeeringWriteType:                  "Peering",
+	PeeringDeleteType:                 "PeeringDelete",
+	PeeringTrustBundleWriteType:       "PeeringTrustBundle",
+	PeeringTrustBundleDeleteType:      "PeeringTrustBundleDelete",
+	PeeringSecretsWriteType:           "PeeringSecret",
+	RaftLogVerifierCheckpoint:         "RaftLogVerifierCheckpoint",
+	ResourceOperationType:             "Resource",
+	UpdateVirtualIPRequestType:        "UpdateManualVirtualIPRequestType",
+	ACLPolicyTemplateSetType:          "ACLPolicyTemplate",
+	ACLPolicyTemplateDeleteType:       "ACLPolicyTemplateDelete",
+	ACLTokenUsageUpdateType:           "ACLTokenUsage",
+	ConnectCARevocationType:           "ConnectCARevocation",           // FSM snapshots only.
+	ConnectCAFederatedTrustBundleType: "ConnectCAFederatedTrustBundle", // FSM snapshots only.
+	ConnectCAJWTSigningKeyType:        "ConnectCAJWTSigningKey",        // FSM snapshots only.
+	ConnectCARevocationListType:       "ConnectCARevocationList",       // FSM snapshots only.
 }
 
 const (
@@ -257,6 +271,13 @@ type RPCInfo interface {
 	HasTimedOut(since time.Time, rpcHoldTimeout, maxQueryTime, defaultQueryTime time.Duration) (bool, error)
 }
 
+// AuditedRequest is implemented by the RPC requests carrying the audit log ID
+// of the HTTP request they were made for, so the events of both share it.
+type AuditedRequest interface {
+	AuditRequestID() string
+	SetAuditRequestID(string)
+}
+
 // QueryOptions is used to specify various flags for read queries
 type QueryOptions struct {
 	// Token is the ACL token ID. If not provided, the 'anonymous'
@@ -323,6 +344,10 @@ type QueryOptions struct {
 	// QueryMeta.Index, the response can be left empty and QueryMeta.NotModified
 	// will be set to true to indicate the result of the query has not changed.
 	AllowNotModifiedResponse bool `mapstructure:"allow-not-modified-response,omitempty"`
+
+	// AuditID is the audit log ID of the HTTP request the query was made
+	// for, if any.
+	AuditID string `mapstructure:"audit-id,omitempty"`
 }
 
 // IsRead is always true for QueryOption.
@@ -353,6 +378,14 @@ func (q *QueryOptions) SetTokenSecret(s string) {
 	q.Token = s
 }
 
+func (q QueryOptions) AuditRequestID() string {
+	return q.AuditID
+}
+
+func (q *QueryOptions) SetAuditRequestID(id string) {
+	q.AuditID = id
+}
+
 // BlockingTimeout implements pool.BlockableQuery
 func (q QueryOptions) BlockingTimeout(maxQueryTime, defaultQueryTime time.Duration) time.Duration {
 	// Match logic in Server.blockingQuery.
@@ -380,6 +413,10 @@ type WriteRequest struct {
 	// Token is the ACL token ID. If not provided, the 'anonymous'
 	// token is assumed for backwards compatibility.
 	Token string
+
+	// AuditID is the audit log ID of the HTTP request the write was made
+	// for, if any.
+	AuditID string
 }
 
 // WriteRequest only applies to writes, always false
@@ -399,6 +436,14 @@ func (w *WriteRequest) SetTokenSecret(s string) {
 	w.Token = s
 }
 
+func (w WriteRequest) AuditRequestID() string {
+	return w.AuditID
+}
+
+func (w *WriteRequest) SetAuditRequestID(id string) {
+	w.AuditID = id
+}
+
 func (w WriteRequest) HasTimedOut(start time.Time, rpcHoldTimeout, _, _ time.Duration) (bool, error) {
 	return time.Since(start) > rpcHoldTimeout, nil
 }
@@ -812,6 +857,10 @@ func (r *ServiceSpecificRequest) CacheInfo() cache.RequestInfo {
 		r.Ingress,
 		r.ServiceKind,
 		r.MergeCentralConfig,
+		// The results are sorted by distance from the source node when it is
+		// set, the other fields of the source don't affect the results.
+		r.Source.Node,
+		r.Source.NodePartition,
 	}, nil)
 	if err == nil {
 		// If there is an error, we don't set the key. A blank key forces
@@ -2143,13 +2192,13 @@ func (nodes CheckServiceNodes) Filter(onlyPassing bool) CheckServiceNodes {
 // FilterIgnore removes nodes that are failing health checks just like Filter.
 // It also ignores the status of any check with an ID present in ignoreCheckIDs
 // as if that check didn't exist. Note that this returns the filtered results
-// AND modifies the receiver for performance.
+// AND modifies the receiver for performance. The relative order of the
+// remaining nodes is preserved, so results sorted by distance stay sorted.
 func (nodes CheckServiceNodes) FilterIgnore(onlyPassing bool,
 	ignoreCheckIDs []types.CheckID) CheckServiceNodes {
-	n := len(nodes)
+	n := 0
 OUTER:
-	for i := 0; i < n; i++ {
-		node := nodes[i]
+	for _, node := range nodes {
 	INNER:
 		for _, check := range node.Checks {
 			for _, ignore := range ignoreCheckIDs {
@@ -2160,13 +2209,15 @@ OUTER:
 			}
 			if check.Status == api.HealthCritical ||
 				(onlyPassing && check.Status != api.HealthPassing) {
-				nodes[i], nodes[n-1] = nodes[n-1], CheckServiceNode{}
-				n--
-				i--
-				// Skip this _node_ now we've swapped it off the end of the list.
+				// Skip this _node_, it will be overwritten by the next healthy one.
 				continue OUTER
 			}
 		}
+		nodes[n] = node
+		n++
+	}
+	for i := n; i < len(nodes); i++ {
+		nodes[i] = CheckServiceNode{}
 	}
 	return nodes[:n]
 }
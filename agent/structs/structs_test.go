@@ -1706,6 +1706,19 @@ func TestCheckServiceNodes_Filter(t *testing.T) {
 		}
 	}
 
+	// The order of the remaining nodes is preserved.
+	{
+		twiddle := CheckServiceNodes{nodes[2], nodes[1], nodes[3], nodes[0]}
+		filtered := twiddle.Filter(false)
+		expected := CheckServiceNodes{
+			nodes[1],
+			nodes[0],
+		}
+		if !reflect.DeepEqual(filtered, expected) {
+			t.Fatalf("bad: %v", filtered)
+		}
+	}
+
 	// Allow failing checks to be ignored (note that the test checks have empty
 	// CheckID which is valid).
 	{
@@ -2152,6 +2165,26 @@ func TestSpecificServiceRequest_CacheInfo(t *testing.T) {
 			},
 			wantSame: false,
 		},
+		{
+			name: "source node should be considered",
+			req: ServiceSpecificRequest{
+				ServiceName: "web",
+			},
+			mutate: func(req *ServiceSpecificRequest) {
+				req.Source.Node = "node1"
+			},
+			wantSame: false,
+		},
+		{
+			name: "source address should not be considered",
+			req: ServiceSpecificRequest{
+				ServiceName: "web",
+			},
+			mutate: func(req *ServiceSpecificRequest) {
+				req.Source.Ip = "1.2.3.4"
+			},
+			wantSame: true,
+		},
 		{
 			name: "tags should be different",
 			req: ServiceSpecificRequest{
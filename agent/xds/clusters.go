@@ -477,7 +477,7 @@ func makeMTLSTransportSocket(cfgSnap *proxycfg.ConfigSnapshot, uid proxycfg.Upst
 		cfgSnap.RootPEMs(),
 		makeTLSParametersFromProxyTLSConfig(cfgSnap.MeshConfigTLSOutgoing()),
 	)
-	err := injectSANMatcher(commonTLSContext, spiffeID.URI().String())
+	err := injectSANMatcher(commonTLSContext, makeServiceSpiffeIDs(cfgSnap, spiffeID)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to inject SAN matcher rules for cluster %q: %v", sni, err)
 	}
@@ -1329,13 +1329,13 @@ func (s *ResourceGenerator) makeUpstreamClusterForPreparedQuery(upstream structs
 			name = e.Service.Service
 		}
 
-		spiffeIDs = append(spiffeIDs, connect.SpiffeIDService{
+		spiffeIDs = append(spiffeIDs, makeServiceSpiffeIDs(cfgSnap, connect.SpiffeIDService{
 			Host:       cfgSnap.Roots.TrustDomain,
 			Namespace:  e.Service.NamespaceOrDefault(),
 			Partition:  e.Service.PartitionOrDefault(),
 			Datacenter: e.Node.Datacenter,
 			Service:    name,
-		}.URI().String())
+		})...)
 	}
 
 	// Enable TLS upstream with the configured client certificate.
@@ -1549,7 +1549,24 @@ func (s *ResourceGenerator) makeUpstreamClustersForDiscoveryChain(
 				return nil, fmt.Errorf("cannot have more than one target")
 			}
 
-			if targetInfo := groupedTarget.Targets[0]; targetInfo.TLSContext != nil {
+			targetInfo := groupedTarget.Targets[0]
+
+			// Targets that opted into HTTP/3 are reached over QUIC, which
+			// requires TLS. Terminating gateways only proxy TCP.
+			useHTTP3 := !forMeshGateway && targetInfo.TLSContext != nil &&
+				chain.Targets[targetInfo.TargetID].EnableHTTP3 &&
+				structs.IsProtocolHTTPLike(upstreamConfig.Protocol) &&
+				!servedByTerminatingGateway(upstreamsSnapshot, uid, targetInfo.TargetID)
+			if useHTTP3 {
+				if err := s.setHttp3ProtocolOptions(c, upstreamConfig.Protocol); err != nil {
+					return nil, err
+				}
+				transportSocket, err := makeUpstreamQUICTransportSocket(targetInfo.TLSContext)
+				if err != nil {
+					return nil, err
+				}
+				c.TransportSocket = transportSocket
+			} else if targetInfo.TLSContext != nil {
 				transportSocket, err := makeUpstreamTLSTransportSocket(targetInfo.TLSContext)
 				if err != nil {
 					return nil, err
@@ -1621,6 +1638,17 @@ func (s *ResourceGenerator) makeExportedUpstreamClustersForMeshGateway(cfgSnap *
 	return clusters, nil
 }
 
+// makeServiceSpiffeIDs returns the SPIFFE IDs an upstream service instance may
+// present: its Consul identity followed by its identities in the federated
+// trust domains.
+func makeServiceSpiffeIDs(cfgSnap *proxycfg.ConfigSnapshot, id connect.SpiffeIDService) []string {
+	ids := []string{id.URI().String()}
+	for _, u := range cfgSnap.SpiffeIDTemplates().URIs(id) {
+		ids = append(ids, u.String())
+	}
+	return ids
+}
+
 // injectSANMatcher updates a TLS context so that it verifies the upstream SAN.
 func injectSANMatcher(tlsContext *envoy_tls_v3.CommonTlsContext, matchStrings ...string) error {
 	if tlsContext == nil {
@@ -2021,6 +2049,55 @@ func injectLBToCluster(ec *structs.LoadBalancer, c *envoy_cluster_v3.Cluster) er
 	return nil
 }
 
+// http3AlternateProtocolsCacheName is the name of the cache in which Envoy
+// records the upstreams advertising HTTP/3 with an Alt-Svc header.
+const http3AlternateProtocolsCacheName = "consul_http3"
+
+// setHttp3ProtocolOptions lets Envoy pick the protocol of the cluster: HTTP/3
+// is used once the upstream advertised it with an Alt-Svc header, and
+// connections fall back to HTTP/1.1 or HTTP/2 over TCP otherwise, or when
+// QUIC is blocked on the network.
+func (s *ResourceGenerator) setHttp3ProtocolOptions(c *envoy_cluster_v3.Cluster, protocol string) error {
+	autoConfig := &envoy_upstreams_v3.HttpProtocolOptions_AutoHttpConfig{
+		HttpProtocolOptions:  &envoy_core_v3.Http1ProtocolOptions{},
+		Http3ProtocolOptions: &envoy_core_v3.Http3ProtocolOptions{},
+		AlternateProtocolsCacheOptions: &envoy_core_v3.AlternateProtocolsCacheOptions{
+			Name: http3AlternateProtocolsCacheName,
+		},
+	}
+	if protocol == "http2" || protocol == "grpc" {
+		autoConfig.Http2ProtocolOptions = &envoy_core_v3.Http2ProtocolOptions{}
+	}
+	cfg := &envoy_upstreams_v3.HttpProtocolOptions{
+		UpstreamProtocolOptions: &envoy_upstreams_v3.HttpProtocolOptions_AutoConfig{
+			AutoConfig: autoConfig,
+		},
+	}
+	any, err := anypb.New(cfg)
+	if err != nil {
+		return err
+	}
+	c.TypedExtensionProtocolOptions = map[string]*anypb.Any{
+		"envoy.extensions.upstreams.http.v3.HttpProtocolOptions": any,
+	}
+
+	return nil
+}
+
+// servedByTerminatingGateway returns true when the endpoints of the target
+// are terminating gateways.
+func servedByTerminatingGateway(upstreams *proxycfg.ConfigSnapshotUpstreams, uid proxycfg.UpstreamID, targetID string) bool {
+	if upstreams == nil {
+		return false
+	}
+	for _, ep := range upstreams.WatchedUpstreamEndpoints[uid][targetID] {
+		if ep.Service != nil && ep.Service.Kind == structs.ServiceKindTerminatingGateway {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *ResourceGenerator) setHttp2ProtocolOptions(c *envoy_cluster_v3.Cluster) error {
 	cfg := &envoy_upstreams_v3.HttpProtocolOptions{
 		UpstreamProtocolOptions: &envoy_upstreams_v3.HttpProtocolOptions_ExplicitHttpConfig_{
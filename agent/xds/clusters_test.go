@@ -63,6 +63,49 @@ func makeClusterDiscoChainTests(enterprise bool) []clusterTestCase {
 				return proxycfg.TestConfigSnapshotDiscoveryChain(t, "simple-with-overrides", enterprise, nil, nil)
 			},
 		},
+		{
+			name: "connect-proxy-with-http3-chain",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				return proxycfg.TestConfigSnapshotDiscoveryChain(t, "simple", enterprise, nil, nil,
+					&structs.ServiceConfigEntry{
+						Kind:        structs.ServiceDefaults,
+						Name:        "db",
+						Protocol:    "http",
+						EnableHTTP3: true,
+					},
+				)
+			},
+		},
+		{
+			name: "connect-proxy-with-http3-chain-through-terminating-gateway",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				dbUID := proxycfg.NewUpstreamID(&structs.TestUpstreams(t, enterprise)[0])
+				dbChainID := structs.ChainID(structs.DiscoveryTargetOpts{
+					Service:    dbUID.Name,
+					Namespace:  dbUID.NamespaceOrDefault(),
+					Partition:  dbUID.PartitionOrDefault(),
+					Datacenter: "dc1",
+				})
+				nodes := proxycfg.TestUpstreamNodes(t, "db")
+				for _, n := range nodes {
+					n.Service.Kind = structs.ServiceKindTerminatingGateway
+				}
+				return proxycfg.TestConfigSnapshotDiscoveryChain(t, "simple", enterprise, nil,
+					[]proxycfg.UpdateEvent{{
+						CorrelationID: "upstream-target:" + dbChainID + ":" + dbUID.String(),
+						Result: &structs.IndexedCheckServiceNodes{
+							Nodes: nodes,
+						},
+					}},
+					&structs.ServiceConfigEntry{
+						Kind:        structs.ServiceDefaults,
+						Name:        "db",
+						Protocol:    "http",
+						EnableHTTP3: true,
+					},
+				)
+			},
+		},
 		{
 			name: "connect-proxy-with-chain-and-failover",
 			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
@@ -80,6 +80,20 @@ type ProxyConfig struct {
 	// BalanceInboundConnections indicates how the proxy should attempt to distribute
 	// connections across worker threads. Only used by envoy proxies.
 	BalanceInboundConnections string `json:",omitempty" alias:"balance_inbound_connections"`
+
+	// ExtensionConfigDiscovery delivers the configuration of the HTTP filters
+	// added by Envoy extensions (lua, wasm and ext_authz) through ECDS, so that
+	// updating an extension does not drain the listeners it applies to.
+	ExtensionConfigDiscovery bool `mapstructure:"envoy_extension_config_discovery"`
+
+	// Runtime holds the runtime flags pushed to the proxy through RTDS. They
+	// are only applied when the Envoy bootstrap declares the RTDS layer,
+	// which `consul connect envoy` does when this is set.
+	Runtime map[string]interface{} `mapstructure:"envoy_runtime"`
+
+	// EnableHTTP3 adds a QUIC listener serving HTTP/3 next to the public
+	// listener of HTTP-like services. It is set from service-defaults.
+	EnableHTTP3 bool `mapstructure:"enable_http3"`
 }
 
 // ParseProxyConfig returns the ProxyConfig parsed from the an opaque map. If an
@@ -147,6 +161,10 @@ type GatewayConfig struct {
 	TcpKeepaliveTime     int  `mapstructure:"envoy_gateway_remote_tcp_keepalive_time"`
 	TcpKeepaliveInterval int  `mapstructure:"envoy_gateway_remote_tcp_keepalive_interval"`
 	TcpKeepaliveProbes   int  `mapstructure:"envoy_gateway_remote_tcp_keepalive_probes"`
+
+	// EnableHTTP3 adds QUIC listeners serving HTTP/3 next to the HTTP
+	// listeners of ingress and API gateways. It is set from service-defaults.
+	EnableHTTP3 bool `mapstructure:"enable_http3"`
 }
 
 // ParseGatewayConfig returns the GatewayConfig parsed from an opaque map. If an
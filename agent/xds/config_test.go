@@ -204,6 +204,35 @@ func TestParseProxyConfig(t *testing.T) {
 				BalanceInboundConnections: "exact_balance",
 			},
 		},
+		{
+			name: "enable http3",
+			input: map[string]interface{}{
+				"protocol":     "http",
+				"enable_http3": true,
+			},
+			want: ProxyConfig{
+				LocalConnectTimeoutMs: 5000,
+				Protocol:              "http",
+				EnableHTTP3:           true,
+			},
+		},
+		{
+			name: "extension config discovery and runtime",
+			input: map[string]interface{}{
+				"envoy_extension_config_discovery": true,
+				"envoy_runtime": map[string]interface{}{
+					"overload.global_downstream_max_connections": 1000,
+				},
+			},
+			want: ProxyConfig{
+				LocalConnectTimeoutMs:    5000,
+				Protocol:                 "tcp",
+				ExtensionConfigDiscovery: true,
+				Runtime: map[string]interface{}{
+					"overload.global_downstream_max_connections": 1000,
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -252,6 +281,7 @@ func TestParseGatewayConfig(t *testing.T) {
 				"envoy_gateway_no_default_bind":       true,
 				"envoy_dns_discovery_type":            "StRiCt_DnS",
 				"connect_timeout_ms":                  10,
+				"enable_http3":                        true,
 			},
 			want: GatewayConfig{
 				ConnectTimeoutMs:    10,
@@ -259,6 +289,7 @@ func TestParseGatewayConfig(t *testing.T) {
 				NoDefaultBind:       true,
 				BindAddresses:       map[string]structs.ServiceAddress{"foo": {Address: "127.0.0.1", Port: 80}},
 				DNSDiscoveryType:    "strict_dns",
+				EnableHTTP3:         true,
 			},
 		},
 		{
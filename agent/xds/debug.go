@@ -0,0 +1,262 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package xds
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	rpcstatus "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/status"
+
+	"github.com/hernad/consul/agent/structs"
+)
+
+// deltaPushHistorySize is the number of pushes kept for each stream.
+const deltaPushHistorySize = 20
+
+const (
+	DeltaPushPending = "pending"
+	DeltaPushACKed   = "acked"
+	DeltaPushNACKed  = "nacked"
+)
+
+// DeltaStreamDebug is a point-in-time view of the delta xDS stream of a
+// proxy, used to diagnose rejected config without enabling trace logging.
+type DeltaStreamDebug struct {
+	ProxyID   string
+	NodeName  string
+	StartedAt time.Time
+
+	// Types holds the state of each xDS type, keyed by type URL.
+	Types map[string]*DeltaTypeDebug
+
+	// Pushes are the last responses sent to Envoy, oldest first.
+	Pushes []DeltaPushDebug
+}
+
+// DeltaTypeDebug is the state of one xDS type of a delta stream.
+type DeltaTypeDebug struct {
+	// Registered is true once Envoy has requested the type.
+	Registered bool
+
+	// Wildcard is true when Envoy is subscribed to all the resources of the
+	// type, in which case Subscriptions is empty.
+	Wildcard      bool
+	Subscriptions []string `json:",omitempty"`
+
+	// ResourceVersions are the versions of the resources Consul believes
+	// Envoy has, as ACKed by Envoy. An empty version means the resource will
+	// be sent again.
+	ResourceVersions map[string]string
+
+	// Pending holds the updates sent to Envoy that are not ACKed yet, keyed
+	// by nonce.
+	Pending map[string]map[string]PendingUpdate `json:",omitempty"`
+
+	// LastNACK is the last push of this type rejected by Envoy.
+	LastNACK *DeltaPushDebug `json:",omitempty"`
+}
+
+// DeltaPushDebug describes a response sent to Envoy.
+type DeltaPushDebug struct {
+	Nonce   string
+	TypeURL string
+	SentAt  time.Time
+
+	// Upserted maps the names of the resources sent to their version.
+	Upserted map[string]string `json:",omitempty"`
+	Removed  []string          `json:",omitempty"`
+
+	// Status is one of DeltaPushPending, DeltaPushACKed or DeltaPushNACKed.
+	Status      string
+	RespondedAt time.Time `json:",omitempty"`
+
+	// Error is the error detail Envoy sent along with a NACK.
+	Error string `json:",omitempty"`
+}
+
+// deltaPushHistory records the last pushes of a stream. Like the rest of the
+// stream state it is only used by the goroutine processing the stream.
+type deltaPushHistory struct {
+	pushes []*DeltaPushDebug
+}
+
+func (h *deltaPushHistory) record(typeURL, nonce string, updates map[string]PendingUpdate) {
+	if h == nil {
+		return
+	}
+	push := &DeltaPushDebug{
+		Nonce:   nonce,
+		TypeURL: typeURL,
+		SentAt:  time.Now(),
+		Status:  DeltaPushPending,
+	}
+	for name, u := range updates {
+		if u.Remove {
+			push.Removed = append(push.Removed, name)
+			continue
+		}
+		if push.Upserted == nil {
+			push.Upserted = make(map[string]string)
+		}
+		push.Upserted[name] = u.Version
+	}
+	sort.Strings(push.Removed)
+
+	if len(h.pushes) == deltaPushHistorySize {
+		h.pushes = append(h.pushes[:0], h.pushes[1:]...)
+	}
+	h.pushes = append(h.pushes, push)
+}
+
+// respond records the ACK, or the NACK when errorDetail is set, of the push
+// with the given nonce and returns it. It returns nil if the push is no longer
+// in the history.
+func (h *deltaPushHistory) respond(nonce string, errorDetail *rpcstatus.Status) *DeltaPushDebug {
+	if h == nil {
+		return nil
+	}
+	for _, push := range h.pushes {
+		if push.Nonce != nonce {
+			continue
+		}
+		push.RespondedAt = time.Now()
+		if errorDetail == nil {
+			push.Status = DeltaPushACKed
+		} else {
+			push.Status = DeltaPushNACKed
+			push.Error = nackError(errorDetail)
+		}
+		return push
+	}
+	return nil
+}
+
+// nackError returns the error detail of a NACK as a string. Envoy may send a
+// detail with an OK code, which is not an error.
+func nackError(errorDetail *rpcstatus.Status) string {
+	if err := status.ErrorProto(errorDetail); err != nil {
+		return err.Error()
+	}
+	return errorDetail.GetMessage()
+}
+
+// deltaStreamDebugHandle lets DeltaStreamDebug query a running stream.
+type deltaStreamDebugHandle struct {
+	reqCh  chan chan *DeltaStreamDebug
+	doneCh chan struct{}
+}
+
+// registerDebugStream makes the stream of the given proxy available to
+// DeltaStreamDebug. When a proxy has several streams the last one wins. The
+// returned function must be called once the stream ends.
+func (s *Server) registerDebugStream(proxyID structs.ServiceID) (*deltaStreamDebugHandle, func()) {
+	h := &deltaStreamDebugHandle{
+		reqCh:  make(chan chan *DeltaStreamDebug),
+		doneCh: make(chan struct{}),
+	}
+
+	s.debugStreamsLock.Lock()
+	defer s.debugStreamsLock.Unlock()
+	if s.debugStreams == nil {
+		s.debugStreams = make(map[structs.ServiceID]*deltaStreamDebugHandle)
+	}
+	s.debugStreams[proxyID] = h
+
+	return h, func() {
+		s.debugStreamsLock.Lock()
+		defer s.debugStreamsLock.Unlock()
+		if s.debugStreams[proxyID] == h {
+			delete(s.debugStreams, proxyID)
+		}
+		close(h.doneCh)
+	}
+}
+
+// DeltaStreamDebug returns the state of the delta xDS stream of the given
+// proxy, or nil if the proxy is not connected to this server.
+func (s *Server) DeltaStreamDebug(ctx context.Context, proxyID structs.ServiceID) (*DeltaStreamDebug, error) {
+	s.debugStreamsLock.Lock()
+	h, ok := s.debugStreams[proxyID]
+	s.debugStreamsLock.Unlock()
+	if !ok {
+		return nil, nil
+	}
+
+	// The stream answers from its own goroutine, between two requests or
+	// snapshots, so that its state never needs to be locked.
+	respCh := make(chan *DeltaStreamDebug, 1)
+	select {
+	case h.reqCh <- respCh:
+	case <-h.doneCh:
+		return nil, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	select {
+	case debug := <-respCh:
+		return debug, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// makeDeltaStreamDebug copies the state of a stream for DeltaStreamDebug.
+func makeDeltaStreamDebug(
+	proxyID structs.ServiceID,
+	nodeName string,
+	startedAt time.Time,
+	handlers map[string]*xDSDeltaType,
+	history *deltaPushHistory,
+) *DeltaStreamDebug {
+	debug := &DeltaStreamDebug{
+		ProxyID:   proxyID.String(),
+		NodeName:  nodeName,
+		StartedAt: startedAt,
+		Types:     make(map[string]*DeltaTypeDebug, len(handlers)),
+	}
+
+	for typeURL, t := range handlers {
+		td := &DeltaTypeDebug{
+			Registered:       t.registered,
+			Wildcard:         t.wildcard,
+			ResourceVersions: make(map[string]string, len(t.resourceVersions)),
+		}
+		for name := range t.subscriptions {
+			td.Subscriptions = append(td.Subscriptions, name)
+		}
+		sort.Strings(td.Subscriptions)
+		for name, version := range t.resourceVersions {
+			td.ResourceVersions[name] = version
+		}
+		for nonce, updates := range t.pendingUpdates {
+			if td.Pending == nil {
+				td.Pending = make(map[string]map[string]PendingUpdate)
+			}
+			pending := make(map[string]PendingUpdate, len(updates))
+			for name, u := range updates {
+				pending[name] = u
+			}
+			td.Pending[nonce] = pending
+		}
+		if t.lastNACK != nil {
+			nack := *t.lastNACK
+			td.LastNACK = &nack
+		}
+		debug.Types[typeURL] = td
+	}
+
+	// The maps and slices of a push are never modified once recorded so a
+	// shallow copy is enough.
+	if history != nil {
+		debug.Pushes = make([]DeltaPushDebug, 0, len(history.pushes))
+		for _, push := range history.pushes {
+			debug.Pushes = append(debug.Pushes, *push)
+		}
+	}
+	return debug
+}
@@ -19,6 +19,7 @@ import (
 	envoy_discovery_v3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
 	"github.com/hashicorp/go-hclog"
 	goversion "github.com/hashicorp/go-version"
+	rpcstatus "google.golang.org/genproto/googleapis/rpc/status"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -104,8 +105,17 @@ func (s *Server) processDelta(stream ADSDeltaStream, reqCh <-chan *envoy_discove
 		drainCh     limiter.SessionTerminatedChan
 		watchCancel func()
 		proxyID     structs.ServiceID
+		nodeName    string
 		nonce       uint64 // xDS requires a unique nonce to correlate response/request pairs
 		ready       bool   // set to true after the first snapshot arrives
+		debug       *deltaStreamDebugHandle
+		history     = &deltaPushHistory{}
+
+		// pendingSnap is the last snapshot received while coalescing updates,
+		// it is pushed once coalesceCh fires.
+		pendingSnap *proxycfg.ConfigSnapshot
+		coalesceCh  <-chan time.Time
+		releasePush func()
 
 		streamStartTime = time.Now()
 		streamStartOnce sync.Once
@@ -153,6 +163,11 @@ func (s *Server) processDelta(stream ADSDeltaStream, reqCh <-chan *envoy_discove
 		}),
 		xdscommon.EndpointType: newDeltaType(generator, stream, xdscommon.EndpointType, nil),
 		xdscommon.SecretType:   newDeltaType(generator, stream, xdscommon.SecretType, nil), // TODO allowEmptyFn
+		xdscommon.ExtensionConfigType: newDeltaType(generator, stream, xdscommon.ExtensionConfigType, func(kind structs.ServiceKind) bool {
+			// Extensions are optional.
+			return true
+		}),
+		xdscommon.RuntimeType: newDeltaType(generator, stream, xdscommon.RuntimeType, nil),
 	}
 
 	// Endpoints are stored within a Cluster (and Routes
@@ -173,6 +188,12 @@ func (s *Server) processDelta(stream ADSDeltaStream, reqCh <-chan *envoy_discove
 		childrenNames: make(map[string][]string),
 	}
 
+	// Record the pushes of every type so they can be inspected with
+	// DeltaStreamDebug.
+	for _, handler := range handlers {
+		handler.history = history
+	}
+
 	var authTimer <-chan time.Time
 	extendAuthTimer := func() {
 		authTimer = time.After(s.AuthCheckFrequency)
@@ -182,8 +203,31 @@ func (s *Server) processDelta(stream ADSDeltaStream, reqCh <-chan *envoy_discove
 		return s.authorize(stream.Context(), cfgSnap)
 	}
 
+	// The push concurrency budget is held from the generation of the
+	// resources until they are sent, which is the end of the loop iteration.
+	defer func() {
+		if releasePush != nil {
+			releasePush()
+		}
+	}()
+
 	for {
+		if releasePush != nil {
+			releasePush()
+			releasePush = nil
+		}
+
+		var pushSnap *proxycfg.ConfigSnapshot
+		var debugReqCh chan chan *DeltaStreamDebug
+		if debug != nil {
+			debugReqCh = debug.reqCh
+		}
+
 		select {
+		case respCh := <-debugReqCh:
+			respCh <- makeDeltaStreamDebug(proxyID, nodeName, streamStartTime, handlers, history)
+			continue
+
 		case <-drainCh:
 			generator.Logger.Debug("draining stream to rebalance load")
 			metrics.IncrCounter([]string{"xds", "server", "streamDrained"}, 1)
@@ -244,34 +288,42 @@ func (s *Server) processDelta(stream ADSDeltaStream, reqCh <-chan *envoy_discove
 				// would've already exited this loop.
 				return status.Error(codes.Aborted, "xDS stream terminated due to an irrecoverable error, please try again")
 			}
-			cfgSnap = cs
 
-			newRes, err := generator.AllResourcesFromSnapshot(cfgSnap)
-			if err != nil {
-				return status.Errorf(codes.Unavailable, "failed to generate all xDS resources from the snapshot: %v", err)
+			// The first snapshot is pushed right away, the next ones are
+			// coalesced with the snapshots received during the window.
+			if cfgSnap != nil {
+				if pendingSnap == nil {
+					window := s.pushCoalesceWindow(cfgSnap, cs)
+					if window > 0 {
+						coalesceCh = time.After(window)
+					}
+				}
+				if coalesceCh != nil {
+					pendingSnap = cs
+					continue
+				}
 			}
+			pushSnap = cs
 
-			// index and hash the xDS structures
-			newResourceMap := xdscommon.IndexResources(generator.Logger, newRes)
-
-			if s.ResourceMapMutateFn != nil {
-				s.ResourceMapMutateFn(newResourceMap)
-			}
+		case <-coalesceCh:
+			pushSnap = pendingSnap
+			pendingSnap, coalesceCh = nil, nil
+		}
 
-			if newResourceMap, err = s.applyEnvoyExtensions(newResourceMap, cfgSnap, node); err != nil {
-				// err is already the result of calling status.Errorf
+		if pushSnap != nil {
+			var err error
+			releasePush, err = s.acquirePush(stream.Context(), endpointsOnlyUpdate(cfgSnap, pushSnap))
+			if err != nil {
 				return err
 			}
 
-			if err := populateChildIndexMap(newResourceMap); err != nil {
-				return status.Errorf(codes.Unavailable, "failed to index xDS resource versions: %v", err)
-			}
-
-			newVersions, err := computeResourceVersions(newResourceMap)
+			newResourceMap, newVersions, err := s.generateResources(generator, node, cfgSnap, pushSnap, resourceMap, currentVersions)
 			if err != nil {
-				return status.Errorf(codes.Unavailable, "failed to compute xDS resource versions: %v", err)
+				// err is already the result of calling status.Errorf
+				return err
 			}
 
+			cfgSnap = pushSnap
 			resourceMap = newResourceMap
 			currentVersions = newVersions
 			ready = true
@@ -286,7 +338,7 @@ func (s *Server) processDelta(stream ADSDeltaStream, reqCh <-chan *envoy_discove
 				continue
 			}
 
-			nodeName := node.GetMetadata().GetFields()["node_name"].GetStringValue()
+			nodeName = node.GetMetadata().GetFields()["node_name"].GetStringValue()
 			if nodeName == "" {
 				nodeName = s.NodeName
 			}
@@ -315,6 +367,10 @@ func (s *Server) processDelta(stream ADSDeltaStream, reqCh <-chan *envoy_discove
 			// state machine.
 			defer watchCancel()
 
+			var unregisterDebug func()
+			debug, unregisterDebug = s.registerDebugStream(proxyID)
+			defer unregisterDebug()
+
 			generator.Logger = generator.Logger.With("service_id", proxyID.String()) // enhance future logs
 
 			generator.Logger.Trace("watching proxy, pending initial proxycfg snapshot for xDS")
@@ -403,6 +459,91 @@ func (s *Server) processDelta(stream ADSDeltaStream, reqCh <-chan *envoy_discove
 	}
 }
 
+// generateResources generates the xDS resources of cfgSnap. When it only
+// differs from prevSnap by its endpoints, only the endpoints are generated and
+// the other resources are taken from prevResources.
+func (s *Server) generateResources(
+	generator *ResourceGenerator,
+	node *envoy_config_core_v3.Node,
+	prevSnap, cfgSnap *proxycfg.ConfigSnapshot,
+	prevResources *xdscommon.IndexedResources,
+	prevVersions map[string]map[string]string,
+) (*xdscommon.IndexedResources, map[string]map[string]string, error) {
+	// Extensions and ResourceMapMutateFn may modify any resource based on the
+	// others, so they require all the resources to be generated.
+	if endpointsOnlyUpdate(prevSnap, cfgSnap) && s.ResourceMapMutateFn == nil && !hasEnvoyExtensions(cfgSnap) {
+		endpoints, err := generator.resourcesFromSnapshot(xdscommon.EndpointType, cfgSnap)
+		if err != nil {
+			return nil, nil, status.Errorf(codes.Unavailable, "failed to generate xDS endpoints from the snapshot: %v", err)
+		}
+		indexed := xdscommon.IndexResources(generator.Logger, map[string][]proto.Message{
+			xdscommon.EndpointType: endpoints,
+		})
+		endpointVersions, err := hashResourceMap(indexed.Index[xdscommon.EndpointType])
+		if err != nil {
+			return nil, nil, status.Errorf(codes.Unavailable, "failed to compute xDS resource versions: %v", err)
+		}
+
+		// The maps of the other types are never modified once generated so
+		// they can be shared.
+		newResourceMap := &xdscommon.IndexedResources{
+			Index:      make(map[string]map[string]proto.Message, len(prevResources.Index)),
+			ChildIndex: prevResources.ChildIndex,
+		}
+		for typeURL, resources := range prevResources.Index {
+			newResourceMap.Index[typeURL] = resources
+		}
+		newResourceMap.Index[xdscommon.EndpointType] = indexed.Index[xdscommon.EndpointType]
+
+		newVersions := make(map[string]map[string]string, len(prevVersions))
+		for typeURL, versions := range prevVersions {
+			newVersions[typeURL] = versions
+		}
+		newVersions[xdscommon.EndpointType] = endpointVersions
+		return newResourceMap, newVersions, nil
+	}
+
+	newRes, err := generator.AllResourcesFromSnapshot(cfgSnap)
+	if err != nil {
+		return nil, nil, status.Errorf(codes.Unavailable, "failed to generate all xDS resources from the snapshot: %v", err)
+	}
+
+	// index and hash the xDS structures
+	newResourceMap := xdscommon.IndexResources(generator.Logger, newRes)
+
+	if s.ResourceMapMutateFn != nil {
+		s.ResourceMapMutateFn(newResourceMap)
+	}
+
+	if newResourceMap, err = s.applyEnvoyExtensions(newResourceMap, cfgSnap, node); err != nil {
+		// err is already the result of calling status.Errorf
+		return nil, nil, err
+	}
+
+	cfg, err := ParseProxyConfig(cfgSnap.Proxy.Config)
+	if err != nil {
+		// Don't hard fail on a config typo, just warn. The parse func returns
+		// default config if there is an error so it's safe to continue.
+		generator.Logger.Warn("failed to parse Connect.Proxy.Config", "error", err)
+	}
+	if cfg.ExtensionConfigDiscovery {
+		if err := extractExtensionConfigs(newResourceMap); err != nil {
+			return nil, nil, status.Errorf(codes.Unavailable, "failed to extract Envoy extension configs: %v", err)
+		}
+	}
+	addRuntime(generator.Logger, newResourceMap, cfg.Runtime)
+
+	if err := populateChildIndexMap(newResourceMap); err != nil {
+		return nil, nil, status.Errorf(codes.Unavailable, "failed to index xDS resource versions: %v", err)
+	}
+
+	newVersions, err := computeResourceVersions(newResourceMap)
+	if err != nil {
+		return nil, nil, status.Errorf(codes.Unavailable, "failed to compute xDS resource versions: %v", err)
+	}
+	return newResourceMap, newVersions, nil
+}
+
 func (s *Server) applyEnvoyExtensions(resources *xdscommon.IndexedResources, cfgSnap *proxycfg.ConfigSnapshot, node *envoy_config_core_v3.Node) (*xdscommon.IndexedResources, error) {
 	var err error
 	envoyVersion := xdscommon.DetermineEnvoyVersionFromNode(node)
@@ -567,6 +708,8 @@ func applyEnvoyExtension(extender extensioncommon.EnvoyExtender, resources *xdsc
 
 // https://www.envoyproxy.io/docs/envoy/latest/api-docs/xds_protocol#eventual-consistency-considerations
 var xDSUpdateOrder = []xDSUpdateOperation{
+	// 0. RTDS updates do not depend on any other resource.
+	{TypeUrl: xdscommon.RuntimeType, Upsert: true, Remove: true},
 	// 1. SDS updates (if any) can be pushed here with no harm.
 	{TypeUrl: xdscommon.SecretType, Upsert: true},
 	// 2. CDS updates (if any) must always be pushed before the following types.
@@ -577,6 +720,9 @@ var xDSUpdateOrder = []xDSUpdateOperation{
 	{TypeUrl: xdscommon.ListenerType, Upsert: true, Remove: true},
 	// 5. RDS updates related to the newly added listeners must arrive after CDS/EDS/LDS updates.
 	{TypeUrl: xdscommon.RouteType, Upsert: true, Remove: true},
+	// ECDS resources are requested by Envoy once it gets the listeners
+	// referencing them, and stop being referenced with them.
+	{TypeUrl: xdscommon.ExtensionConfigType, Upsert: true, Remove: true},
 	// 6. (NOT IMPLEMENTED YET IN CONSUL) VHDS updates (if any) related to the newly added RouteConfigurations must arrive after RDS updates.
 	// {},
 	// 7. Stale CDS clusters, related EDS endpoints (ones no longer being referenced) and SDS secrets can then be removed.
@@ -630,6 +776,12 @@ type xDSDeltaType struct {
 	typeURL      string
 	allowEmptyFn func(kind structs.ServiceKind) bool
 
+	// history records the pushes of the stream, shared by all its types.
+	history *deltaPushHistory
+
+	// lastNACK is the last push of this type rejected by envoy.
+	lastNACK *DeltaPushDebug
+
 	// deltaChild contains data for an xDS child type if there is one.
 	// For example, endpoints are a child type of clusters.
 	deltaChild *xDSDeltaChild
@@ -742,7 +894,7 @@ func (t *xDSDeltaType) Recv(req *envoy_discovery_v3.DeltaDiscoveryRequest, sf xd
 		} else {
 			logger.Error("got error response from envoy proxy", "nonce", req.ResponseNonce,
 				"error", status.ErrorProto(req.ErrorDetail))
-			t.nack(req.ResponseNonce)
+			t.nack(req.ResponseNonce, req.ErrorDetail)
 			return deltaRecvResponseNack
 		}
 	}
@@ -830,6 +982,8 @@ func (t *xDSDeltaType) Recv(req *envoy_discovery_v3.DeltaDiscoveryRequest, sf xd
 }
 
 func (t *xDSDeltaType) ack(nonce string) {
+	t.history.respond(nonce, nil)
+
 	pending, ok := t.pendingUpdates[nonce]
 	if !ok {
 		return
@@ -847,7 +1001,19 @@ func (t *xDSDeltaType) ack(nonce string) {
 	delete(t.pendingUpdates, nonce)
 }
 
-func (t *xDSDeltaType) nack(nonce string) {
+func (t *xDSDeltaType) nack(nonce string, errorDetail *rpcstatus.Status) {
+	if push := t.history.respond(nonce, errorDetail); push != nil {
+		t.lastNACK = push
+	} else {
+		// The push is too old to still be in the history.
+		t.lastNACK = &DeltaPushDebug{
+			Nonce:       nonce,
+			TypeURL:     t.typeURL,
+			Status:      DeltaPushNACKed,
+			RespondedAt: time.Now(),
+			Error:       nackError(errorDetail),
+		}
+	}
 	delete(t.pendingUpdates, nonce)
 }
 
@@ -923,6 +1089,7 @@ func (t *xDSDeltaType) SendIfNew(
 		}
 	}
 	t.pendingUpdates[resp.Nonce] = updates
+	t.history.record(t.typeURL, resp.Nonce, updates)
 
 	return nil, true
 }
@@ -4,6 +4,7 @@
 package xds
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strconv"
@@ -388,6 +389,116 @@ func TestServer_DeltaAggregatedResources_v3_NackLoop(t *testing.T) {
 	}
 }
 
+func TestServer_DeltaStreamDebug(t *testing.T) {
+	aclResolve := func(id string) (acl.Authorizer, error) {
+		// Allow all
+		return acl.RootAuthorizer("manage"), nil
+	}
+	scenario := newTestServerDeltaScenario(t, aclResolve, "web-sidecar-proxy", "", 0)
+	mgr, errCh, envoy := scenario.mgr, scenario.errCh, scenario.envoy
+
+	sid := structs.NewServiceID("web-sidecar-proxy", nil)
+
+	// Register the proxy to create state needed to Watch() on
+	mgr.RegisterProxy(t, sid)
+
+	// Nothing is known about a proxy before its stream starts.
+	debug, err := scenario.server.DeltaStreamDebug(context.Background(), sid)
+	require.NoError(t, err)
+	require.Nil(t, debug)
+
+	snap := newTestSnapshot(t, nil, "", nil)
+	clusters := []string{
+		"db.default.dc1.internal.11111111-2222-3333-4444-555555555555.consul",
+		"geo-cache.default.dc1.query.11111111-2222-3333-4444-555555555555.consul",
+		"local_app",
+	}
+
+	testutil.RunStep(t, "NACKed push", func(t *testing.T) {
+		envoy.SendDeltaReq(t, xdscommon.ClusterType, &envoy_discovery_v3.DeltaDiscoveryRequest{})
+		mgr.DeliverConfig(t, sid, snap)
+
+		resp := <-envoy.deltaStream.sendCh
+		require.Equal(t, hexString(1), resp.Nonce)
+
+		envoy.SendDeltaReqNACK(t, xdscommon.ClusterType, 1, &rpcstatus.Status{
+			Code:    int32(codes.InvalidArgument),
+			Message: "invalid cluster",
+		})
+
+		retry.Run(t, func(r *retry.R) {
+			debug, err := scenario.server.DeltaStreamDebug(context.Background(), sid)
+			require.NoError(r, err)
+			require.NotNil(r, debug)
+			require.Equal(r, sid.String(), debug.ProxyID)
+
+			cds := debug.Types[xdscommon.ClusterType]
+			require.True(r, cds.Registered)
+			require.True(r, cds.Wildcard)
+			require.Empty(r, cds.ResourceVersions)
+			require.Empty(r, cds.Pending)
+			require.NotNil(r, cds.LastNACK)
+			require.Equal(r, hexString(1), cds.LastNACK.Nonce)
+			require.Contains(r, cds.LastNACK.Error, "invalid cluster")
+			require.Len(r, cds.LastNACK.Upserted, 3)
+
+			require.False(r, debug.Types[xdscommon.ListenerType].Registered)
+
+			require.Len(r, debug.Pushes, 1)
+			require.Equal(r, DeltaPushNACKed, debug.Pushes[0].Status)
+		})
+	})
+
+	testutil.RunStep(t, "pending and ACKed push", func(t *testing.T) {
+		// A new snapshot sends the clusters again.
+		mgr.DeliverConfig(t, sid, snap)
+		resp := <-envoy.deltaStream.sendCh
+		require.Equal(t, hexString(2), resp.Nonce)
+
+		retry.Run(t, func(r *retry.R) {
+			debug, err := scenario.server.DeltaStreamDebug(context.Background(), sid)
+			require.NoError(r, err)
+			cds := debug.Types[xdscommon.ClusterType]
+			require.Len(r, cds.Pending[hexString(2)], 3)
+			require.Equal(r, DeltaPushPending, debug.Pushes[1].Status)
+		})
+
+		envoy.SendDeltaReqACK(t, xdscommon.ClusterType, 2)
+
+		retry.Run(t, func(r *retry.R) {
+			debug, err := scenario.server.DeltaStreamDebug(context.Background(), sid)
+			require.NoError(r, err)
+			cds := debug.Types[xdscommon.ClusterType]
+			require.Empty(r, cds.Pending)
+			var names []string
+			for name, version := range cds.ResourceVersions {
+				require.NotEmpty(r, version)
+				names = append(names, name)
+			}
+			require.ElementsMatch(r, clusters, names)
+			require.Equal(r, hexString(1), cds.LastNACK.Nonce)
+
+			require.Len(r, debug.Pushes, 2)
+			require.Equal(r, DeltaPushNACKed, debug.Pushes[0].Status)
+			require.Equal(r, DeltaPushACKed, debug.Pushes[1].Status)
+			require.Equal(r, cds.ResourceVersions, debug.Pushes[1].Upserted)
+		})
+	})
+
+	envoy.Close()
+	select {
+	case err := <-errCh:
+		require.NoError(t, err)
+	case <-time.After(50 * time.Millisecond):
+		t.Fatalf("timed out waiting for handler to finish")
+	}
+
+	// The stream is forgotten once it ends.
+	debug, err = scenario.server.DeltaStreamDebug(context.Background(), sid)
+	require.NoError(t, err)
+	require.Nil(t, debug)
+}
+
 func TestServer_DeltaAggregatedResources_v3_BasicProtocol_HTTP2(t *testing.T) {
 	aclResolve := func(id string) (acl.Authorizer, error) {
 		// Allow all
@@ -538,6 +649,121 @@ func TestServer_DeltaAggregatedResources_v3_BasicProtocol_HTTP2(t *testing.T) {
 	}
 }
 
+func TestServer_DeltaAggregatedResources_v3_ExtensionConfigDiscovery(t *testing.T) {
+	aclResolve := func(id string) (acl.Authorizer, error) {
+		// Allow all
+		return acl.RootAuthorizer("manage"), nil
+	}
+	scenario := newTestServerDeltaScenario(t, aclResolve, "web-sidecar-proxy", "", 0)
+	mgr, errCh, envoy := scenario.mgr, scenario.errCh, scenario.envoy
+
+	sid := structs.NewServiceID("web-sidecar-proxy", nil)
+
+	// Register the proxy to create state needed to Watch() on
+	mgr.RegisterProxy(t, sid)
+
+	luaNsFunc := func(script string) func(ns *structs.NodeService) {
+		return func(ns *structs.NodeService) {
+			ns.Proxy.Config["protocol"] = "http"
+			ns.Proxy.Config["envoy_extension_config_discovery"] = true
+			ns.Proxy.EnvoyExtensions = []structs.EnvoyExtension{
+				{
+					Name: api.BuiltinLuaExtension,
+					Arguments: map[string]interface{}{
+						"ProxyType": "connect-proxy",
+						"Listener":  "inbound",
+						"Script":    script,
+					},
+				},
+			}
+		}
+	}
+
+	recv := func(t *testing.T, typeURL string, nonce uint64) *envoy_discovery_v3.DeltaDiscoveryResponse {
+		t.Helper()
+		select {
+		case resp := <-envoy.deltaStream.sendCh:
+			require.Equal(t, typeURL, resp.TypeUrl)
+			require.Equal(t, hexString(nonce), resp.Nonce)
+			return resp
+		case <-time.After(50 * time.Millisecond):
+			t.Fatalf("no response received after 50ms")
+			return nil
+		}
+	}
+
+	snap := newTestSnapshot(t, nil, "", luaNsFunc("x = 0"))
+
+	var extName string
+	testutil.RunStep(t, "extension configs are sent once subscribed", func(t *testing.T) {
+		envoy.SendDeltaReq(t, xdscommon.ClusterType, nil)
+		mgr.DeliverConfig(t, sid, snap)
+		recv(t, xdscommon.ClusterType, 1)
+		envoy.SendDeltaReqACK(t, xdscommon.ClusterType, 1)
+
+		envoy.SendDeltaReq(t, xdscommon.ListenerType, nil)
+		resp := recv(t, xdscommon.ListenerType, 2)
+
+		// The public listener references the lua config instead of embedding
+		// it.
+		for _, res := range resp.Resources {
+			if !strings.HasPrefix(res.Name, "public_listener") {
+				continue
+			}
+			var listener envoy_listener_v3.Listener
+			require.NoError(t, res.Resource.UnmarshalTo(&listener))
+			for _, chain := range listener.FilterChains {
+				hcm, _, err := extensioncommon.GetHTTPConnectionManager(chain.Filters...)
+				require.NoError(t, err)
+				for _, filter := range hcm.HttpFilters {
+					if filter.GetConfigDiscovery() != nil {
+						extName = filter.Name
+					}
+				}
+			}
+		}
+		require.NotEmpty(t, extName)
+
+		// Nothing is sent for the extension configs until Envoy subscribes
+		// to them after receiving the listener.
+		assertDeltaChanBlocked(t, envoy.deltaStream.sendCh)
+
+		envoy.SendDeltaReq(t, xdscommon.ExtensionConfigType, &envoy_discovery_v3.DeltaDiscoveryRequest{
+			ResourceNamesSubscribe: []string{extName},
+		})
+		resp = recv(t, xdscommon.ExtensionConfigType, 3)
+		require.Len(t, resp.Resources, 1)
+		require.Equal(t, extName, resp.Resources[0].Name)
+
+		envoy.SendDeltaReqACK(t, xdscommon.ListenerType, 2)
+		envoy.SendDeltaReqACK(t, xdscommon.ExtensionConfigType, 3)
+		assertDeltaChanBlocked(t, envoy.deltaStream.sendCh)
+	})
+
+	testutil.RunStep(t, "changing an extension only updates its config", func(t *testing.T) {
+		snap = newTestSnapshot(t, snap, "", luaNsFunc("x = 1"))
+		mgr.DeliverConfig(t, sid, snap)
+
+		// The listener version is unchanged so only the extension config is
+		// sent, and the listener is not drained.
+		resp := recv(t, xdscommon.ExtensionConfigType, 4)
+		require.Len(t, resp.Resources, 1)
+		require.Equal(t, extName, resp.Resources[0].Name)
+		require.Empty(t, resp.RemovedResources)
+		envoy.SendDeltaReqACK(t, xdscommon.ExtensionConfigType, 4)
+
+		assertDeltaChanBlocked(t, envoy.deltaStream.sendCh)
+	})
+
+	envoy.Close()
+	select {
+	case err := <-errCh:
+		require.NoError(t, err)
+	case <-time.After(50 * time.Millisecond):
+		t.Fatalf("timed out waiting for handler to finish")
+	}
+}
+
 func TestServer_DeltaAggregatedResources_v3_SlowEndpointPopulation(t *testing.T) {
 	// This illustrates a scenario related to https://github.com/hernad/consul/issues/10563
 
@@ -1540,6 +1766,96 @@ func TestServer_DeltaAggregatedResources_v3_StreamDrained(t *testing.T) {
 	})
 }
 
+func TestServer_DeltaAggregatedResources_v3_PushCoalescing(t *testing.T) {
+	aclResolve := func(id string) (acl.Authorizer, error) {
+		// Allow all
+		return acl.RootAuthorizer("manage"), nil
+	}
+	scenario := newTestServerDeltaScenario(t, aclResolve, "web-sidecar-proxy", "", 0)
+	mgr, envoy := scenario.mgr, scenario.envoy
+	scenario.server.PushCoalesceWindow = time.Hour
+	scenario.server.EndpointPushCoalesceWindow = 20 * time.Millisecond
+
+	sid := structs.NewServiceID("web-sidecar-proxy", nil)
+
+	// Register the proxy to create state needed to Watch() on
+	mgr.RegisterProxy(t, sid)
+
+	snap := newTestSnapshot(t, nil, "", nil)
+	snap.ConfigVersion = 1
+
+	testutil.RunStep(t, "first snapshot is pushed right away", func(t *testing.T) {
+		envoy.SendDeltaReq(t, xdscommon.ClusterType, nil)
+		mgr.DeliverConfig(t, sid, snap)
+
+		assertDeltaResponseSent(t, envoy.deltaStream.sendCh, &envoy_discovery_v3.DeltaDiscoveryResponse{
+			TypeUrl: xdscommon.ClusterType,
+			Nonce:   hexString(1),
+			Resources: makeTestResources(t,
+				makeTestCluster(t, snap, "tcp:local_app"),
+				makeTestCluster(t, snap, "tcp:db"),
+				makeTestCluster(t, snap, "tcp:geo-cache"),
+			),
+		})
+
+		envoy.SendDeltaReq(t, xdscommon.EndpointType, &envoy_discovery_v3.DeltaDiscoveryRequest{
+			ResourceNamesSubscribe: []string{
+				"db.default.dc1.internal.11111111-2222-3333-4444-555555555555.consul",
+			},
+		})
+		assertDeltaResponseSent(t, envoy.deltaStream.sendCh, &envoy_discovery_v3.DeltaDiscoveryResponse{
+			TypeUrl: xdscommon.EndpointType,
+			Nonce:   hexString(2),
+			Resources: makeTestResources(t,
+				makeTestEndpoints(t, snap, "tcp:db"),
+			),
+		})
+
+		envoy.SendDeltaReqACK(t, xdscommon.ClusterType, 1)
+		envoy.SendDeltaReqACK(t, xdscommon.EndpointType, 2)
+		assertDeltaChanBlocked(t, envoy.deltaStream.sendCh)
+	})
+
+	testutil.RunStep(t, "endpoint-only updates are coalesced", func(t *testing.T) {
+		// The connect timeout of the cluster is never pushed since the config
+		// version claims that only the endpoints changed.
+		snap = newTestSnapshot(t, snap, "", nil, &structs.ServiceResolverConfigEntry{
+			Kind:           structs.ServiceResolver,
+			Name:           "db",
+			ConnectTimeout: 1337 * time.Second,
+		})
+		snap.ConfigVersion = 1
+		mgr.DeliverConfig(t, sid, snap)
+
+		snap = newTestSnapshot(t, snap, "", nil)
+		snap.ConfigVersion = 1
+		uid := UID("db")
+		snap.ConnectProxy.WatchedUpstreamEndpoints[uid]["db.default.default.dc1"] =
+			snap.ConnectProxy.WatchedUpstreamEndpoints[uid]["db.default.default.dc1"][0:1]
+		mgr.DeliverConfig(t, sid, snap)
+
+		// Only the last snapshot is pushed once the window is over.
+		assertDeltaResponseSent(t, envoy.deltaStream.sendCh, &envoy_discovery_v3.DeltaDiscoveryResponse{
+			TypeUrl: xdscommon.EndpointType,
+			Nonce:   hexString(3),
+			Resources: makeTestResources(t,
+				makeTestEndpoints(t, snap, "tcp:db[0]"),
+			),
+		})
+		envoy.SendDeltaReqACK(t, xdscommon.EndpointType, 3)
+		assertDeltaChanBlocked(t, envoy.deltaStream.sendCh)
+	})
+
+	testutil.RunStep(t, "full updates wait for their own window", func(t *testing.T) {
+		snap = newTestSnapshot(t, snap, "", nil)
+		snap.ConfigVersion = 2
+		mgr.DeliverConfig(t, sid, snap)
+
+		time.Sleep(100 * time.Millisecond)
+		assertDeltaChanBlocked(t, envoy.deltaStream.sendCh)
+	})
+}
+
 func assertDeltaChanBlocked(t *testing.T, ch chan *envoy_discovery_v3.DeltaDiscoveryResponse) {
 	t.Helper()
 	select {
@@ -0,0 +1,101 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package xds
+
+import (
+	"fmt"
+
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoy_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	envoy_http_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+
+	"github.com/hernad/consul/envoyextensions/extensioncommon"
+	"github.com/hernad/consul/envoyextensions/xdscommon"
+)
+
+// discoverableHTTPFilters are the HTTP filters added by the builtin Envoy
+// extensions whose configuration can be delivered through ECDS.
+var discoverableHTTPFilters = map[string]struct{}{
+	"envoy.filters.http.lua":       {},
+	"envoy.filters.http.wasm":      {},
+	"envoy.filters.http.ext_authz": {},
+}
+
+// extractExtensionConfigs moves the configuration of the discoverable HTTP
+// filters of the listeners to ECDS resources. The filters then only reference
+// their resource, so that changing the configuration of an extension updates
+// the resource instead of the listener, which would be drained.
+//
+// The listeners are modified in place so they must have been generated for
+// this call.
+func extractExtensionConfigs(resources *xdscommon.IndexedResources) error {
+	for name, res := range resources.Index[xdscommon.ListenerType] {
+		listener := res.(*envoy_listener_v3.Listener)
+		for idx, chain := range listener.FilterChains {
+			prefix := fmt.Sprintf("%s/%d", name, idx)
+			if err := extractFilterChainExtensionConfigs(resources, prefix, chain); err != nil {
+				return fmt.Errorf("listener %q: %w", name, err)
+			}
+		}
+		if listener.DefaultFilterChain != nil {
+			prefix := fmt.Sprintf("%s/default", name)
+			if err := extractFilterChainExtensionConfigs(resources, prefix, listener.DefaultFilterChain); err != nil {
+				return fmt.Errorf("listener %q: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func extractFilterChainExtensionConfigs(resources *xdscommon.IndexedResources, prefix string, chain *envoy_listener_v3.FilterChain) error {
+	hcm, hcmIdx, err := extensioncommon.GetHTTPConnectionManager(chain.Filters...)
+	if err != nil {
+		// Not an HTTP filter chain.
+		return nil
+	}
+
+	modified := false
+	for idx, filter := range hcm.HttpFilters {
+		if _, ok := discoverableHTTPFilters[filter.Name]; !ok {
+			continue
+		}
+		typedConfig := filter.GetTypedConfig()
+		if typedConfig == nil {
+			continue
+		}
+
+		// Envoy uses the name of the filter as the name of the resource.
+		resourceName := fmt.Sprintf("%s/%d/%s", prefix, idx, filter.Name)
+		resources.Index[xdscommon.ExtensionConfigType][resourceName] = &envoy_core_v3.TypedExtensionConfig{
+			Name:        resourceName,
+			TypedConfig: typedConfig,
+		}
+		hcm.HttpFilters[idx] = &envoy_http_v3.HttpFilter{
+			Name:       resourceName,
+			IsOptional: filter.IsOptional,
+			ConfigType: &envoy_http_v3.HttpFilter_ConfigDiscovery{
+				ConfigDiscovery: &envoy_core_v3.ExtensionConfigSource{
+					ConfigSource: &envoy_core_v3.ConfigSource{
+						ResourceApiVersion: envoy_core_v3.ApiVersion_V3,
+						ConfigSourceSpecifier: &envoy_core_v3.ConfigSource_Ads{
+							Ads: &envoy_core_v3.AggregatedConfigSource{},
+						},
+					},
+					TypeUrls: []string{typedConfig.TypeUrl},
+				},
+			},
+		}
+		modified = true
+	}
+	if !modified {
+		return nil
+	}
+
+	hcmFilter, err := makeFilter(chain.Filters[hcmIdx].Name, hcm)
+	if err != nil {
+		return err
+	}
+	chain.Filters[hcmIdx] = hcmFilter
+	return nil
+}
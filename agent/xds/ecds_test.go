@@ -0,0 +1,75 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package xds
+
+import (
+	"testing"
+
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoy_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	envoy_lua_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/lua/v3"
+	envoy_http_router_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/router/v3"
+	envoy_http_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	envoy_tcp_proxy_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/tcp_proxy/v3"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/hernad/consul/envoyextensions/extensioncommon"
+	"github.com/hernad/consul/envoyextensions/xdscommon"
+)
+
+func TestExtractExtensionConfigs(t *testing.T) {
+	lua := &envoy_lua_v3.Lua{DefaultSourceCode: &envoy_core_v3.DataSource{
+		Specifier: &envoy_core_v3.DataSource_InlineString{InlineString: "x = 0"},
+	}}
+	luaFilter, err := makeEnvoyHTTPFilter("envoy.filters.http.lua", lua)
+	require.NoError(t, err)
+	router, err := makeEnvoyHTTPFilter("envoy.filters.http.router", &envoy_http_router_v3.Router{})
+	require.NoError(t, err)
+	hcm, err := makeFilter("envoy.filters.network.http_connection_manager", &envoy_http_v3.HttpConnectionManager{
+		StatPrefix:  "public_listener",
+		HttpFilters: []*envoy_http_v3.HttpFilter{luaFilter, router},
+	})
+	require.NoError(t, err)
+	tcpProxy, err := makeFilter("envoy.filters.network.tcp_proxy", &envoy_tcp_proxy_v3.TcpProxy{
+		StatPrefix: "db",
+	})
+	require.NoError(t, err)
+
+	resources := xdscommon.EmptyIndexedResources()
+	resources.Index[xdscommon.ListenerType]["public_listener"] = &envoy_listener_v3.Listener{
+		Name: "public_listener",
+		FilterChains: []*envoy_listener_v3.FilterChain{
+			{Filters: []*envoy_listener_v3.Filter{tcpProxy}},
+			{Filters: []*envoy_listener_v3.Filter{hcm}},
+		},
+	}
+
+	require.NoError(t, extractExtensionConfigs(resources))
+
+	// The lua config is delivered through ECDS.
+	name := "public_listener/1/0/envoy.filters.http.lua"
+	require.Len(t, resources.Index[xdscommon.ExtensionConfigType], 1)
+	ext := resources.Index[xdscommon.ExtensionConfigType][name].(*envoy_core_v3.TypedExtensionConfig)
+	require.Equal(t, name, ext.Name)
+	var gotLua envoy_lua_v3.Lua
+	require.NoError(t, ext.TypedConfig.UnmarshalTo(&gotLua))
+	require.True(t, proto.Equal(lua, &gotLua))
+
+	// The listener references it instead.
+	listener := resources.Index[xdscommon.ListenerType]["public_listener"].(*envoy_listener_v3.Listener)
+	require.Equal(t, "envoy.filters.network.tcp_proxy", listener.FilterChains[0].Filters[0].Name)
+	gotHCM, _, err := extensioncommon.GetHTTPConnectionManager(listener.FilterChains[1].Filters...)
+	require.NoError(t, err)
+	require.Len(t, gotHCM.HttpFilters, 2)
+	require.Equal(t, name, gotHCM.HttpFilters[0].Name)
+	discovery := gotHCM.HttpFilters[0].GetConfigDiscovery()
+	require.NotNil(t, discovery)
+	require.NotNil(t, discovery.ConfigSource.GetAds())
+	require.Equal(t, []string{"type.googleapis.com/envoy.extensions.filters.http.lua.v3.Lua"}, discovery.TypeUrls)
+
+	// Other filters are left alone.
+	require.Equal(t, "envoy.filters.http.router", gotHCM.HttpFilters[1].Name)
+	require.NotNil(t, gotHCM.HttpFilters[1].GetTypedConfig())
+}
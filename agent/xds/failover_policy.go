@@ -116,13 +116,13 @@ func (s *ResourceGenerator) mapDiscoChainTargets(cfgSnap *proxycfg.ConfigSnapsho
 		} else {
 			sni = target.SNI
 			rootPEMs = cfgSnap.RootPEMs()
-			spiffeIDs = []string{connect.SpiffeIDService{
+			spiffeIDs = makeServiceSpiffeIDs(cfgSnap, connect.SpiffeIDService{
 				Host:       cfgSnap.Roots.TrustDomain,
 				Namespace:  target.Namespace,
 				Partition:  target.Partition,
 				Datacenter: target.Datacenter,
 				Service:    target.Service,
-			}.URI().String()}
+			})
 		}
 		commonTLSContext := makeCommonTLSContext(
 			cfgSnap.Leaf(),
@@ -5,12 +5,14 @@ package xds
 
 import (
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 
 	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
 	envoy_http_jwt_authn_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/jwt_authn/v3"
 	envoy_http_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	"github.com/hernad/consul/agent/connect"
 	"github.com/hernad/consul/agent/structs"
 	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/emptypb"
@@ -75,6 +77,50 @@ func makeJWTAuthFilter(providerMap map[string]*structs.JWTProviderConfigEntry, i
 	return makeEnvoyHTTPFilter(jwtEnvoyFilter, cfg)
 }
 
+// resolveJWTProviders returns the providers with the ones that reference Consul
+// itself as their issuer replaced by a provider of the JWT-SVIDs issued in the
+// trust domain of roots, whose keys are inlined since they are distributed
+// with the roots.
+func resolveJWTProviders(providers map[string]*structs.JWTProviderConfigEntry, roots *structs.IndexedCARoots) (map[string]*structs.JWTProviderConfigEntry, error) {
+	var resolved map[string]*structs.JWTProviderConfigEntry
+	for name, p := range providers {
+		if p.Issuer != structs.JWTProviderIssuerConsul {
+			continue
+		}
+		if roots == nil {
+			return nil, fmt.Errorf("cannot resolve jwt provider %s: missing CA roots", name)
+		}
+		if resolved == nil {
+			resolved = make(map[string]*structs.JWTProviderConfigEntry, len(providers))
+			for n, p := range providers {
+				resolved[n] = p
+			}
+		}
+
+		keySet, err := connect.JWTSVIDKeySet(roots.JWTSigningKeys)
+		if err != nil {
+			return nil, err
+		}
+		jwks, err := json.Marshal(keySet)
+		if err != nil {
+			return nil, err
+		}
+
+		// The config entry is shared with the other proxies so it is copied.
+		cp := *p
+		cp.Issuer = connect.JWTSVIDIssuer(roots.TrustDomain)
+		cp.JSONWebKeySet = &structs.JSONWebKeySet{
+			Local: &structs.LocalJWKS{JWKS: base64.StdEncoding.EncodeToString(jwks)},
+		}
+		resolved[name] = &cp
+	}
+
+	if resolved == nil {
+		return providers, nil
+	}
+	return resolved, nil
+}
+
 func makeJWTRequirementRule(r *envoy_http_jwt_authn_v3.JwtRequirement) *envoy_http_jwt_authn_v3.RequirementRule_Requires {
 	return &envoy_http_jwt_authn_v3.RequirementRule_Requires{
 		Requires: r,
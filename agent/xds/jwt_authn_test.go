@@ -5,15 +5,18 @@ package xds
 
 import (
 	"encoding/base64"
+	"encoding/json"
 	"path/filepath"
 	"testing"
 
 	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_http_jwt_authn_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/jwt_authn/v3"
+	"github.com/hernad/consul/agent/connect"
 	"github.com/hernad/consul/agent/structs"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/wrapperspb"
+	"gopkg.in/square/go-jose.v2"
 )
 
 type ixnOpts struct {
@@ -209,6 +212,61 @@ func TestMakeJWTAUTHFilters(t *testing.T) {
 	}
 }
 
+func TestResolveJWTProviders(t *testing.T) {
+	okta := &structs.JWTProviderConfigEntry{
+		Kind:   "jwt-provider",
+		Name:   "okta",
+		Issuer: "test-issuer",
+		JSONWebKeySet: &structs.JSONWebKeySet{
+			Remote: &structs.RemoteJWKS{URI: "https://example-okta.com/.well-known/jwks.json"},
+		},
+	}
+
+	// Providers are returned as is when none references Consul.
+	providers := map[string]*structs.JWTProviderConfigEntry{"okta": okta}
+	resolved, err := resolveJWTProviders(providers, nil)
+	require.NoError(t, err)
+	require.Equal(t, providers, resolved)
+
+	key, err := connect.GenerateJWTSigningKey()
+	require.NoError(t, err)
+	roots := &structs.IndexedCARoots{
+		TrustDomain:    "11111111-2222-3333-4444-555555555555.consul",
+		JWTSigningKeys: []*structs.CAJWTSigningKey{key},
+	}
+	mesh := &structs.JWTProviderConfigEntry{
+		Kind:      "jwt-provider",
+		Name:      "mesh",
+		Issuer:    structs.JWTProviderIssuerConsul,
+		Audiences: []string{"api"},
+	}
+	providers["mesh"] = mesh
+
+	_, err = resolveJWTProviders(providers, nil)
+	require.ErrorContains(t, err, "missing CA roots")
+
+	resolved, err = resolveJWTProviders(providers, roots)
+	require.NoError(t, err)
+	require.Same(t, okta, resolved["okta"])
+	require.Equal(t, structs.JWTProviderIssuerConsul, mesh.Issuer, "the config entry must not be modified")
+
+	p := resolved["mesh"]
+	require.Equal(t, "spiffe://11111111-2222-3333-4444-555555555555.consul", p.Issuer)
+	require.Equal(t, []string{"api"}, p.Audiences)
+	require.NotNil(t, p.JSONWebKeySet.Local)
+
+	jwks, err := base64.StdEncoding.DecodeString(p.JSONWebKeySet.Local.JWKS)
+	require.NoError(t, err)
+	var keySet jose.JSONWebKeySet
+	require.NoError(t, json.Unmarshal(jwks, &keySet))
+	require.Len(t, keySet.Key(key.ID), 1)
+
+	envoyCfg, err := buildJWTProviderConfig(p)
+	require.NoError(t, err)
+	require.Equal(t, p.Issuer, envoyCfg.Issuer)
+	require.NotNil(t, envoyCfg.GetLocalJwks())
+}
+
 func TestCollectJWTProviders(t *testing.T) {
 	tests := map[string]struct {
 		intention *structs.Intention
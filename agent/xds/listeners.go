@@ -124,6 +124,23 @@ func (s *ResourceGenerator) listenersFromSnapshotConnectProxy(cfgSnap *proxycfg.
 		// default config if there is an error so it's safe to continue.
 		s.Logger.Warn("failed to parse Connect.Proxy.Config", "error", err)
 	}
+
+	// Serve HTTP/3 next to the public listener when the service opted into it.
+	if proxyCfg.EnableHTTP3 && structs.IsProtocolHTTPLike(proxyCfg.Protocol) {
+		if publicListener, ok := resources[0].(*envoy_listener_v3.Listener); ok {
+			quicListener, err := makeQUICListener(publicListener, nil)
+			if err != nil {
+				return nil, err
+			}
+			if quicListener != nil {
+				if err := advertiseHTTP3(publicListener); err != nil {
+					return nil, err
+				}
+				resources = append(resources, quicListener)
+			}
+		}
+	}
+
 	var tracing *envoy_http_v3.HttpConnectionManager_Tracing
 	if proxyCfg.ListenerTracingJSON != "" {
 		if tracing, err = makeTracingFromUserConfig(proxyCfg.ListenerTracingJSON); err != nil {
@@ -866,12 +883,26 @@ func (s *ResourceGenerator) listenersFromSnapshotGateway(cfgSnap *proxycfg.Confi
 			}
 
 			resources = append(resources, listeners...)
+			if cfg.EnableHTTP3 {
+				quicListeners, err := makeGatewayQUICListeners(cfgSnap, listeners)
+				if err != nil {
+					return nil, err
+				}
+				resources = append(resources, quicListeners...)
+			}
 		case structs.ServiceKindIngressGateway:
 			listeners, err := s.makeIngressGatewayListeners(a.Address, cfgSnap)
 			if err != nil {
 				return nil, err
 			}
 			resources = append(resources, listeners...)
+			if cfg.EnableHTTP3 {
+				quicListeners, err := makeGatewayQUICListeners(cfgSnap, listeners)
+				if err != nil {
+					return nil, err
+				}
+				resources = append(resources, quicListeners...)
+			}
 		case structs.ServiceKindMeshGateway:
 			l, err = s.makeMeshGatewayListener(a.name, a.Address, a.Port, cfgSnap)
 			if err != nil {
@@ -1004,9 +1035,11 @@ func (s *ResourceGenerator) injectConnectFilters(cfgSnap *proxycfg.ConfigSnapsho
 		cfgSnap.ConnectProxy.Intentions,
 		cfgSnap.IntentionDefaultAllow,
 		rbacLocalInfo{
-			trustDomain: cfgSnap.Roots.TrustDomain,
-			datacenter:  cfgSnap.Datacenter,
-			partition:   cfgSnap.ProxyID.PartitionOrDefault(),
+			trustDomain:       cfgSnap.Roots.TrustDomain,
+			datacenter:        cfgSnap.Datacenter,
+			partition:         cfgSnap.ProxyID.PartitionOrDefault(),
+			revocations:       cfgSnap.Roots.Revocations,
+			spiffeIDTemplates: cfgSnap.SpiffeIDTemplates(),
 		},
 		cfgSnap.ConnectProxy.InboundPeerTrustBundles,
 	)
@@ -1185,12 +1218,20 @@ func createDownstreamTransportSocketForConnectTLS(cfgSnap *proxycfg.ConfigSnapsh
 		tlsContext.AlpnProtocols = getAlpnProtocols(cfg.Protocol)
 	}
 
-	// Inject peering trust bundles if this service is exported to peered clusters.
-	if len(peerBundles) > 0 {
+	var fedBundles []*structs.FederatedTrustBundle
+	if cfgSnap.Roots != nil {
+		fedBundles = cfgSnap.Roots.FederatedTrustBundles
+	}
+
+	// Inject peering trust bundles if this service is exported to peered
+	// clusters, and the bundles fetched for federated trust domains.
+	if len(peerBundles) > 0 || len(fedBundles) > 0 {
 		spiffeConfig, err := makeSpiffeValidatorConfig(
 			cfgSnap.Roots.TrustDomain,
 			cfgSnap.RootPEMs(),
 			peerBundles,
+			cfgSnap.Roots.FederatedTrustDomains,
+			fedBundles,
 		)
 		if err != nil {
 			return nil, err
@@ -1209,6 +1250,8 @@ func createDownstreamTransportSocketForConnectTLS(cfgSnap *proxycfg.ConfigSnapsh
 			Name:        "envoy.tls.cert_validator.spiffe",
 			TypedConfig: spiffeConfig,
 		}
+	} else if cfgSnap.Kind == structs.ServiceKindConnectProxy {
+		injectRevocationLists(tlsContext, cfgSnap.RevocationListPEMs())
 	}
 
 	return makeDownstreamTLSTransportSocket(&envoy_tls_v3.DownstreamTlsContext{
@@ -1221,9 +1264,32 @@ func createDownstreamTransportSocketForConnectTLS(cfgSnap *proxycfg.ConfigSnapsh
 // With cluster peering we expect peered clusters to have independent certificate authorities.
 // This means that we cannot use a single set of root CA certificates to validate client certificates for mTLS,
 // but rather we need to validate against different roots depending on the trust domain of the certificate presented.
-func makeSpiffeValidatorConfig(trustDomain, roots string, peerBundles []*pbpeering.PeeringTrustBundle) (*anypb.Any, error) {
+//
+// Federated trust domains with a SPIFFE bundle endpoint are validated against
+// the bundle fetched from it, and are not trusted until it has been fetched.
+// The other federated trust domains are validated against the local roots
+// since their certificates are expected to be issued by an authority chained
+// to Consul's CA.
+func makeSpiffeValidatorConfig(
+	trustDomain, roots string,
+	peerBundles []*pbpeering.PeeringTrustBundle,
+	federated []structs.FederatedTrustDomain,
+	fedBundles []*structs.FederatedTrustBundle,
+) (*anypb.Any, error) {
 	// Store the trust bundle for the local trust domain.
 	bundles := map[string]string{trustDomain: roots}
+	for _, d := range federated {
+		if d.BundleEndpointURL == "" {
+			bundles[d.Name] = roots
+		}
+	}
+	for _, b := range fedBundles {
+		var pems string
+		for _, pem := range b.RootPEMs {
+			pems += lib.EnsureTrailingNewline(pem)
+		}
+		bundles[b.TrustDomain] = pems
+	}
 
 	// Store the trust bundle for each trust domain of the peers this proxy is exported to.
 	// This allows us to validate traffic from other trust domains.
@@ -1273,6 +1339,11 @@ func (s *ResourceGenerator) makeInboundListener(cfgSnap *proxycfg.ConfigSnapshot
 	// This controls if we do L4 or L7 intention checks.
 	useHTTPFilter := structs.IsProtocolHTTPLike(cfg.Protocol)
 
+	jwtProviders, err := resolveJWTProviders(cfgSnap.JWTProviders, cfgSnap.Roots)
+	if err != nil {
+		return nil, err
+	}
+
 	// Generate and return custom public listener from config if one was provided.
 	if cfg.PublicListenerJSON != "" {
 		l, err = makeListenerFromUserConfig(cfg.PublicListenerJSON)
@@ -1286,12 +1357,14 @@ func (s *ResourceGenerator) makeInboundListener(cfgSnap *proxycfg.ConfigSnapshot
 				cfgSnap.ConnectProxy.Intentions,
 				cfgSnap.IntentionDefaultAllow,
 				rbacLocalInfo{
-					trustDomain: cfgSnap.Roots.TrustDomain,
-					datacenter:  cfgSnap.Datacenter,
-					partition:   cfgSnap.ProxyID.PartitionOrDefault(),
+					trustDomain:       cfgSnap.Roots.TrustDomain,
+					datacenter:        cfgSnap.Datacenter,
+					partition:         cfgSnap.ProxyID.PartitionOrDefault(),
+					revocations:       cfgSnap.Roots.Revocations,
+					spiffeIDTemplates: cfgSnap.SpiffeIDTemplates(),
 				},
 				cfgSnap.ConnectProxy.InboundPeerTrustBundles,
-				cfgSnap.JWTProviders,
+				jwtProviders,
 			)
 			if err != nil {
 				return nil, err
@@ -1365,7 +1438,7 @@ func (s *ResourceGenerator) makeInboundListener(cfgSnap *proxycfg.ConfigSnapshot
 		logger:           s.Logger,
 	}
 	if useHTTPFilter {
-		jwtFilter, err := makeJWTAuthFilter(cfgSnap.JWTProviders, cfgSnap.ConnectProxy.Intentions)
+		jwtFilter, err := makeJWTAuthFilter(jwtProviders, cfgSnap.ConnectProxy.Intentions)
 		if err != nil {
 			return nil, err
 		}
@@ -1373,12 +1446,14 @@ func (s *ResourceGenerator) makeInboundListener(cfgSnap *proxycfg.ConfigSnapshot
 			cfgSnap.ConnectProxy.Intentions,
 			cfgSnap.IntentionDefaultAllow,
 			rbacLocalInfo{
-				trustDomain: cfgSnap.Roots.TrustDomain,
-				datacenter:  cfgSnap.Datacenter,
-				partition:   cfgSnap.ProxyID.PartitionOrDefault(),
+				trustDomain:       cfgSnap.Roots.TrustDomain,
+				datacenter:        cfgSnap.Datacenter,
+				partition:         cfgSnap.ProxyID.PartitionOrDefault(),
+				revocations:       cfgSnap.Roots.Revocations,
+				spiffeIDTemplates: cfgSnap.SpiffeIDTemplates(),
 			},
 			cfgSnap.ConnectProxy.InboundPeerTrustBundles,
-			cfgSnap.JWTProviders,
+			jwtProviders,
 		)
 		if err != nil {
 			return nil, err
@@ -1775,6 +1850,7 @@ func (s *ResourceGenerator) makeFilterChainTerminatingGateway(cfgSnap *proxycfg.
 		),
 		RequireClientCertificate: &wrapperspb.BoolValue{Value: true},
 	}
+	injectRevocationLists(tlsContext.CommonTlsContext, cfgSnap.RevocationListPEMs())
 	transportSocket, err := makeDownstreamTLSTransportSocket(tlsContext)
 	if err != nil {
 		return nil, err
@@ -1795,9 +1871,11 @@ func (s *ResourceGenerator) makeFilterChainTerminatingGateway(cfgSnap *proxycfg.
 			tgtwyOpts.intentions,
 			cfgSnap.IntentionDefaultAllow,
 			rbacLocalInfo{
-				trustDomain: cfgSnap.Roots.TrustDomain,
-				datacenter:  cfgSnap.Datacenter,
-				partition:   cfgSnap.ProxyID.PartitionOrDefault(),
+				trustDomain:       cfgSnap.Roots.TrustDomain,
+				datacenter:        cfgSnap.Datacenter,
+				partition:         cfgSnap.ProxyID.PartitionOrDefault(),
+				revocations:       cfgSnap.Roots.Revocations,
+				spiffeIDTemplates: cfgSnap.SpiffeIDTemplates(),
 			},
 			nil, // TODO(peering): verify intentions w peers don't apply to terminatingGateway
 		)
@@ -1836,17 +1914,22 @@ func (s *ResourceGenerator) makeFilterChainTerminatingGateway(cfgSnap *proxycfg.
 	}
 
 	if useHTTPFilter {
-		var err error
+		jwtProviders, err := resolveJWTProviders(cfgSnap.JWTProviders, cfgSnap.Roots)
+		if err != nil {
+			return nil, err
+		}
 		rbacFilter, err := makeRBACHTTPFilter(
 			tgtwyOpts.intentions,
 			cfgSnap.IntentionDefaultAllow,
 			rbacLocalInfo{
-				trustDomain: cfgSnap.Roots.TrustDomain,
-				datacenter:  cfgSnap.Datacenter,
-				partition:   cfgSnap.ProxyID.PartitionOrDefault(),
+				trustDomain:       cfgSnap.Roots.TrustDomain,
+				datacenter:        cfgSnap.Datacenter,
+				partition:         cfgSnap.ProxyID.PartitionOrDefault(),
+				revocations:       cfgSnap.Roots.Revocations,
+				spiffeIDTemplates: cfgSnap.SpiffeIDTemplates(),
 			},
 			nil, // TODO(peering): verify intentions w peers don't apply to terminatingGateway
-			cfgSnap.JWTProviders,
+			jwtProviders,
 		)
 		if err != nil {
 			return nil, err
@@ -2685,6 +2768,27 @@ func makeCommonTLSContext(
 	}
 }
 
+// injectRevocationLists makes the validation context of tlsContext reject the
+// revoked leaf certificates. It must not be used when the certificates of
+// peers or federated trust domains are accepted: their issuers have no
+// revocation list, and Envoy rejects every certificate whose issuer has none.
+func injectRevocationLists(tlsContext *envoy_tls_v3.CommonTlsContext, crlPEMs string) {
+	if tlsContext == nil || crlPEMs == "" {
+		return
+	}
+	typ, ok := tlsContext.ValidationContextType.(*envoy_tls_v3.CommonTlsContext_ValidationContext)
+	if !ok {
+		return
+	}
+	typ.ValidationContext.Crl = &envoy_core_v3.DataSource{
+		Specifier: &envoy_core_v3.DataSource_InlineString{
+			InlineString: crlPEMs,
+		},
+	}
+	// Only leaf certificates are revoked, intermediates have no list.
+	typ.ValidationContext.OnlyVerifyLeafCertCrl = true
+}
+
 func makeDownstreamTLSTransportSocket(tlsContext *envoy_tls_v3.DownstreamTlsContext) (*envoy_core_v3.TransportSocket, error) {
 	if tlsContext == nil {
 		return nil, nil
@@ -0,0 +1,201 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package xds
+
+import (
+	"fmt"
+	"strings"
+
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoy_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	envoy_http_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	envoy_quic_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/quic/v3"
+	envoy_tls_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/hernad/consul/agent/proxycfg"
+	"github.com/hernad/consul/envoyextensions/extensioncommon"
+)
+
+const quicTransportSocketName = "envoy.transport_sockets.quic"
+
+// http3ALPN is the only application protocol negotiated over QUIC.
+var http3ALPN = []string{"h3"}
+
+// makeQUICListener returns a listener serving HTTP/3 over QUIC on the UDP port
+// matching the TCP listener l. Only the HTTP filter chains of l are kept, with
+// the network filters preceding the HTTP connection manager such as the
+// connection limit, and the ones without TLS use defaultTLS since QUIC always
+// requires it. It returns nil when there is nothing to serve over QUIC.
+//
+// The listener keeps the Envoy ID of l, so Envoy extensions patching l also
+// patch it.
+func makeQUICListener(l *envoy_listener_v3.Listener, defaultTLS *envoy_tls_v3.DownstreamTlsContext) (*envoy_listener_v3.Listener, error) {
+	if l.GetAddress().GetSocketAddress() == nil {
+		return nil, nil
+	}
+
+	var chains []*envoy_listener_v3.FilterChain
+	seen := make(map[string]struct{})
+	for _, chain := range l.FilterChains {
+		hcm, hcmIdx, err := extensioncommon.GetHTTPConnectionManager(chain.Filters...)
+		if err != nil {
+			// Not an HTTP filter chain.
+			continue
+		}
+
+		// QUIC listeners can only match on the server name, the other criteria
+		// used by the TCP listeners are about the TLS handshake over TCP.
+		serverNames := chain.GetFilterChainMatch().GetServerNames()
+		key := strings.Join(serverNames, ",")
+		if _, ok := seen[key]; ok {
+			continue
+		}
+
+		tlsContext := defaultTLS
+		if chain.TransportSocket != nil {
+			tlsContext = &envoy_tls_v3.DownstreamTlsContext{}
+			if err := chain.TransportSocket.GetTypedConfig().UnmarshalTo(tlsContext); err != nil {
+				return nil, err
+			}
+		}
+		if tlsContext == nil {
+			continue
+		}
+		transportSocket, err := makeDownstreamQUICTransportSocket(tlsContext)
+		if err != nil {
+			return nil, err
+		}
+
+		hcm.CodecType = envoy_http_v3.HttpConnectionManager_HTTP3
+		hcm.Http3ProtocolOptions = &envoy_core_v3.Http3ProtocolOptions{}
+		hcmFilter, err := makeFilter(chain.Filters[hcmIdx].Name, hcm)
+		if err != nil {
+			return nil, err
+		}
+
+		filters := make([]*envoy_listener_v3.Filter, 0, hcmIdx+1)
+		for _, f := range chain.Filters[:hcmIdx] {
+			filters = append(filters, proto.Clone(f).(*envoy_listener_v3.Filter))
+		}
+		filters = append(filters, hcmFilter)
+
+		quicChain := &envoy_listener_v3.FilterChain{
+			Name:            chain.Name,
+			Filters:         filters,
+			TransportSocket: transportSocket,
+		}
+		if len(serverNames) > 0 {
+			quicChain.FilterChainMatch = &envoy_listener_v3.FilterChainMatch{
+				ServerNames: serverNames,
+			}
+		}
+		chains = append(chains, quicChain)
+		seen[key] = struct{}{}
+	}
+	if len(chains) == 0 {
+		return nil, nil
+	}
+
+	addr := proto.Clone(l.Address).(*envoy_core_v3.Address)
+	addr.GetSocketAddress().Protocol = envoy_core_v3.SocketAddress_UDP
+
+	return &envoy_listener_v3.Listener{
+		Name:             l.Name + ":quic",
+		Address:          addr,
+		TrafficDirection: l.TrafficDirection,
+		AccessLog:        l.AccessLog,
+		FilterChains:     chains,
+		UdpListenerConfig: &envoy_listener_v3.UdpListenerConfig{
+			QuicOptions: &envoy_listener_v3.QuicProtocolOptions{},
+		},
+	}, nil
+}
+
+// advertiseHTTP3 adds an Alt-Svc header to the responses of the HTTP filter
+// chains of the TCP listener l, telling the clients that HTTP/3 is served on
+// the same port. Upstream clusters with HTTP/3 enabled only use it once they
+// learned it from this header, and use TCP until then.
+func advertiseHTTP3(l *envoy_listener_v3.Listener) error {
+	port := l.GetAddress().GetSocketAddress().GetPortValue()
+	if port == 0 {
+		return nil
+	}
+	altSvc := makeHeadersValueOptions(map[string]string{
+		"alt-svc": fmt.Sprintf("h3=\":%d\"; ma=86400", port),
+	}, false)
+
+	for _, chain := range l.FilterChains {
+		hcm, hcmIdx, err := extensioncommon.GetHTTPConnectionManager(chain.Filters...)
+		if err != nil {
+			// Not an HTTP filter chain.
+			continue
+		}
+		routeConfig := hcm.GetRouteConfig()
+		if routeConfig == nil {
+			continue
+		}
+		routeConfig.ResponseHeadersToAdd = append(routeConfig.ResponseHeadersToAdd, altSvc...)
+		hcmFilter, err := makeFilter(chain.Filters[hcmIdx].Name, hcm)
+		if err != nil {
+			return err
+		}
+		chain.Filters[hcmIdx] = hcmFilter
+	}
+	return nil
+}
+
+// makeGatewayQUICListeners returns the QUIC listeners serving HTTP/3 next to
+// the HTTP listeners of an ingress or API gateway. The listeners without TLS
+// are served over QUIC with the leaf certificate of the gateway.
+func makeGatewayQUICListeners(cfgSnap *proxycfg.ConfigSnapshot, listeners []proto.Message) ([]proto.Message, error) {
+	var defaultTLS *envoy_tls_v3.DownstreamTlsContext
+	if leaf := cfgSnap.Leaf(); leaf != nil {
+		if tlsContext := makeCommonTLSContext(leaf, cfgSnap.RootPEMs(), nil); tlsContext != nil {
+			defaultTLS = &envoy_tls_v3.DownstreamTlsContext{
+				CommonTlsContext:         tlsContext,
+				RequireClientCertificate: &wrapperspb.BoolValue{Value: false},
+			}
+		}
+	}
+
+	var out []proto.Message
+	for _, msg := range listeners {
+		l, ok := msg.(*envoy_listener_v3.Listener)
+		if !ok {
+			continue
+		}
+		quicListener, err := makeQUICListener(l, defaultTLS)
+		if err != nil {
+			return nil, err
+		}
+		if quicListener != nil {
+			out = append(out, quicListener)
+		}
+	}
+	return out, nil
+}
+
+func makeDownstreamQUICTransportSocket(tlsContext *envoy_tls_v3.DownstreamTlsContext) (*envoy_core_v3.TransportSocket, error) {
+	tlsContext = proto.Clone(tlsContext).(*envoy_tls_v3.DownstreamTlsContext)
+	if tlsContext.CommonTlsContext == nil {
+		tlsContext.CommonTlsContext = &envoy_tls_v3.CommonTlsContext{}
+	}
+	tlsContext.CommonTlsContext.AlpnProtocols = http3ALPN
+	return makeTransportSocket(quicTransportSocketName, &envoy_quic_v3.QuicDownstreamTransport{
+		DownstreamTlsContext: tlsContext,
+	})
+}
+
+// makeUpstreamQUICTransportSocket returns the transport socket of the
+// clusters using HTTP/3. Its TLS context is also used by the connections
+// falling back to TCP, so no ALPN is set: Envoy negotiates "h3" over QUIC
+// and the protocols of the auto HTTP config over TCP.
+func makeUpstreamQUICTransportSocket(tlsContext *envoy_tls_v3.UpstreamTlsContext) (*envoy_core_v3.TransportSocket, error) {
+	return makeTransportSocket(quicTransportSocketName, &envoy_quic_v3.QuicUpstreamTransport{
+		UpstreamTlsContext: tlsContext,
+	})
+}
@@ -370,6 +370,25 @@ func TestListenersFromSnapshot(t *testing.T) {
 				}, nil)
 			},
 		},
+		{
+			name: "http-public-listener-http3",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				return proxycfg.TestConfigSnapshot(t, func(ns *structs.NodeService) {
+					ns.Proxy.Config["protocol"] = "http"
+					ns.Proxy.Config["enable_http3"] = true
+				}, nil)
+			},
+		},
+		{
+			name: "http-public-listener-http3-connection-limit",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				return proxycfg.TestConfigSnapshot(t, func(ns *structs.NodeService) {
+					ns.Proxy.Config["protocol"] = "http"
+					ns.Proxy.Config["enable_http3"] = true
+					ns.Proxy.Config["max_inbound_connections"] = 222
+				}, nil)
+			},
+		},
 		{
 			name: "http-public-listener-no-xfcc",
 			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
@@ -805,6 +824,16 @@ func TestListenersFromSnapshot(t *testing.T) {
 				return proxycfg.TestConfigSnapshotIngressGateway(t, true, "tcp", "default", nil, nil, nil)
 			},
 		},
+		{
+			name: "ingress-gateway-http3",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				return proxycfg.TestConfigSnapshotIngressGateway(t, true, "http", "default", func(ns *structs.NodeService) {
+					ns.Proxy.Config = map[string]interface{}{
+						"enable_http3": true,
+					}
+				}, nil, nil)
+			},
+		},
 		{
 			name: "ingress-gateway-nil-config-entry",
 			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
@@ -1107,6 +1136,47 @@ func TestListenersFromSnapshot(t *testing.T) {
 			name:   "transparent-proxy-terminating-gateway",
 			create: proxycfg.TestConfigSnapshotTransparentProxyTerminatingGatewayCatalogDestinationsOnly,
 		},
+		{
+			name: "connect-proxy-with-federated-trust-bundle",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				snap := proxycfg.TestConfigSnapshot(t, nil, nil)
+				snap.Roots.FederatedTrustDomains = []structs.FederatedTrustDomain{
+					{Name: "example.org", SpiffeIDTemplates: []string{"/ns/{namespace}/sa/{service}"}, BundleEndpointURL: "https://example.org/bundle"},
+					{Name: "example.com", SpiffeIDTemplates: []string{"/svc/{service}"}},
+				}
+				snap.Roots.FederatedTrustBundles = []*structs.FederatedTrustBundle{
+					{TrustDomain: "example.org", RootPEMs: []string{"-----BEGIN CERTIFICATE-----\nZXhhbXBsZS5vcmc=\n-----END CERTIFICATE-----"}},
+				}
+				return snap
+			},
+		},
+		{
+			name: "connect-proxy-with-revocation-lists",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				snap := proxycfg.TestConfigSnapshot(t, nil, nil)
+				snap.Roots.RevocationLists = []*structs.CARevocationList{
+					{SigningKeyID: "aa:bb", Datacenter: "dc1", PEM: "-----BEGIN X509 CRL-----\nZGMx\n-----END X509 CRL-----"},
+					{SigningKeyID: "cc:dd", Datacenter: "dc2", PEM: "-----BEGIN X509 CRL-----\nZGMy\n-----END X509 CRL-----"},
+				}
+				return snap
+			},
+		},
+		{
+			name: "connect-proxy-with-revocation-lists-and-federated-trust-bundle",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				snap := proxycfg.TestConfigSnapshot(t, nil, nil)
+				snap.Roots.RevocationLists = []*structs.CARevocationList{
+					{SigningKeyID: "aa:bb", Datacenter: "dc1", PEM: "-----BEGIN X509 CRL-----\nZGMx\n-----END X509 CRL-----"},
+				}
+				snap.Roots.FederatedTrustDomains = []structs.FederatedTrustDomain{
+					{Name: "example.org", SpiffeIDTemplates: []string{"/ns/{namespace}/sa/{service}"}, BundleEndpointURL: "https://example.org/bundle"},
+				}
+				snap.Roots.FederatedTrustBundles = []*structs.FederatedTrustBundle{
+					{TrustDomain: "example.org", RootPEMs: []string{"-----BEGIN CERTIFICATE-----\nZXhhbXBsZS5vcmc=\n-----END CERTIFICATE-----"}},
+				}
+				return snap
+			},
+		},
 		{
 			name: "custom-trace-listener",
 			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
@@ -0,0 +1,137 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package xds
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hernad/consul/agent/proxycfg"
+	"github.com/hernad/consul/agent/xds/extensionruntime"
+)
+
+// pushBudget limits how many streams generate and push resources at the same
+// time. Streams waiting to push endpoint-only updates, which are cheap to
+// generate and usually the most urgent, are served before the ones that need
+// to regenerate all their resources. A nil pushBudget has no limit.
+type pushBudget struct {
+	mu       sync.Mutex
+	limit    int
+	inFlight int
+
+	// endpointsWaiters and fullWaiters are the streams waiting for a slot, in
+	// arrival order. A slot is handed over by closing the channel.
+	endpointsWaiters []chan struct{}
+	fullWaiters      []chan struct{}
+}
+
+func newPushBudget(limit int) *pushBudget {
+	return &pushBudget{limit: limit}
+}
+
+// acquire blocks until a push can start or ctx is done. The returned function
+// must be called once the push is over.
+func (b *pushBudget) acquire(ctx context.Context, endpointsOnly bool) (func(), error) {
+	if b == nil {
+		return func() {}, nil
+	}
+
+	b.mu.Lock()
+	if b.inFlight < b.limit {
+		b.inFlight++
+		b.mu.Unlock()
+		return b.release, nil
+	}
+	ch := make(chan struct{})
+	if endpointsOnly {
+		b.endpointsWaiters = append(b.endpointsWaiters, ch)
+	} else {
+		b.fullWaiters = append(b.fullWaiters, ch)
+	}
+	b.mu.Unlock()
+
+	select {
+	case <-ch:
+		return b.release, nil
+	case <-ctx.Done():
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	select {
+	case <-ch:
+		// The slot was handed over while ctx was being canceled, give it to
+		// the next stream.
+		b.releaseLocked()
+	default:
+		b.endpointsWaiters = removeWaiter(b.endpointsWaiters, ch)
+		b.fullWaiters = removeWaiter(b.fullWaiters, ch)
+	}
+	return nil, ctx.Err()
+}
+
+func (b *pushBudget) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.releaseLocked()
+}
+
+func (b *pushBudget) releaseLocked() {
+	switch {
+	case len(b.endpointsWaiters) > 0:
+		close(b.endpointsWaiters[0])
+		b.endpointsWaiters = b.endpointsWaiters[1:]
+	case len(b.fullWaiters) > 0:
+		close(b.fullWaiters[0])
+		b.fullWaiters = b.fullWaiters[1:]
+	default:
+		b.inFlight--
+	}
+}
+
+func removeWaiter(waiters []chan struct{}, ch chan struct{}) []chan struct{} {
+	for i, w := range waiters {
+		if w == ch {
+			return append(waiters[:i], waiters[i+1:]...)
+		}
+	}
+	return waiters
+}
+
+// acquirePush waits for the push concurrency budget of the server.
+func (s *Server) acquirePush(ctx context.Context, endpointsOnly bool) (func(), error) {
+	s.pushBudgetOnce.Do(func() {
+		if s.MaxConcurrentPushes > 0 {
+			s.pushBudget = newPushBudget(s.MaxConcurrentPushes)
+		}
+	})
+	return s.pushBudget.acquire(ctx, endpointsOnly)
+}
+
+// pushCoalesceWindow returns how long to wait for more snapshots before
+// pushing next, given that the resources were last generated from prev.
+func (s *Server) pushCoalesceWindow(prev, next *proxycfg.ConfigSnapshot) time.Duration {
+	if endpointsOnlyUpdate(prev, next) {
+		return s.EndpointPushCoalesceWindow
+	}
+	return s.PushCoalesceWindow
+}
+
+// endpointsOnlyUpdate returns whether next only differs from prev by the
+// endpoints of its upstreams.
+func endpointsOnlyUpdate(prev, next *proxycfg.ConfigSnapshot) bool {
+	return prev != nil && next.ConfigVersion != 0 && prev.ConfigVersion == next.ConfigVersion
+}
+
+// hasEnvoyExtensions returns whether Envoy extensions apply to the resources
+// generated from the snapshot.
+func hasEnvoyExtensions(cfgSnap *proxycfg.ConfigSnapshot) bool {
+	for _, cfgs := range extensionruntime.GetRuntimeConfigurations(cfgSnap) {
+		if len(cfgs) > 0 {
+			return true
+		}
+	}
+	return false
+}
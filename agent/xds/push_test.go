@@ -0,0 +1,64 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package xds
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPushBudget(t *testing.T) {
+	b := newPushBudget(1)
+
+	release, err := b.acquire(context.Background(), false)
+	require.NoError(t, err)
+
+	// Queue a full push, then an endpoint-only one.
+	acquired := make(chan string, 2)
+	waitFor := func(name string, endpointsOnly bool) {
+		release, err := b.acquire(context.Background(), endpointsOnly)
+		require.NoError(t, err)
+		acquired <- name
+		release()
+	}
+	go waitFor("full", false)
+	require.Eventually(t, func() bool {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		return len(b.fullWaiters) == 1
+	}, time.Second, 10*time.Millisecond)
+	go waitFor("endpoints", true)
+	require.Eventually(t, func() bool {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		return len(b.endpointsWaiters) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// A canceled wait gives up its place.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = b.acquire(ctx, true)
+	require.ErrorIs(t, err, context.Canceled)
+
+	// The endpoint-only push goes first.
+	release()
+	require.Equal(t, "endpoints", <-acquired)
+	require.Equal(t, "full", <-acquired)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	require.Zero(t, b.inFlight)
+	require.Empty(t, b.endpointsWaiters)
+	require.Empty(t, b.fullWaiters)
+}
+
+func TestPushBudget_NoLimit(t *testing.T) {
+	var b *pushBudget
+	release, err := b.acquire(context.Background(), false)
+	require.NoError(t, err)
+	release()
+}
@@ -5,6 +5,7 @@ package xds
 
 import (
 	"fmt"
+	"math"
 	"sort"
 	"strings"
 
@@ -85,6 +86,13 @@ func intentionListToIntermediateRBACForm(
 		if err != nil {
 			return nil, err
 		}
+
+		// An intention naming a federated trust domain matches no workload
+		// when no SPIFFE ID template of that trust domain can represent its
+		// source.
+		if rixn.Source.FederatedTrustDomain != "" && len(makeTemplatedSpiffePatterns(rixn.Source)) == 0 {
+			continue
+		}
 		rbacIxns = append(rbacIxns, rixn)
 	}
 	return rbacIxns, nil
@@ -244,6 +252,13 @@ func intentionToIntermediateRBACForm(
 		rixn.Source.TrustDomain = bundle.TrustDomain
 	}
 
+	// workloads of federated trust domains are identified by their templated
+	// SPIFFE IDs instead of Consul ones
+	if ixn.SourceTrustDomain != "" {
+		rixn.Source.FederatedTrustDomain = ixn.SourceTrustDomain
+		rixn.Source.spiffeIDTemplates = localInfo.spiffeIDTemplates.ForTrustDomain(ixn.SourceTrustDomain)
+	}
+
 	if isHTTP && ixn.JWT != nil {
 		var jwts []*JWTInfo
 		for _, prov := range ixn.JWT.Providers {
@@ -341,6 +356,12 @@ type rbacService struct {
 	Peer              string
 	ExportedPartition string
 	TrustDomain       string
+
+	// FederatedTrustDomain is only set for the workloads of a federated
+	// trust domain, which are matched through spiffeIDTemplates, the
+	// templates of that trust domain, instead of Consul SPIFFE IDs.
+	FederatedTrustDomain string
+	spiffeIDTemplates    connect.SpiffeIDTemplates
 }
 
 type rbacIntention struct {
@@ -528,6 +549,14 @@ type rbacLocalInfo struct {
 	datacenter  string
 	partition   string
 	expectXFCC  bool
+
+	// revocations are the revoked leaf certificates and service identities
+	// of the local cluster.
+	revocations []*structs.CARevocation
+
+	// spiffeIDTemplates map the SPIFFE IDs of the federated trust domains to
+	// the service identities named by intentions with a source trust domain.
+	spiffeIDTemplates connect.SpiffeIDTemplates
 }
 
 // makeRBACRules translates Consul intentions into RBAC Policies for Envoy.
@@ -587,7 +616,8 @@ func makeRBACRules(
 	peerTrustBundles []*pbpeering.PeeringTrustBundle,
 	providerMap map[string]*structs.JWTProviderConfigEntry,
 ) (*envoy_rbac_v3.RBAC, error) {
-	// TODO(banks,rb): Implement revocation list checking?
+	// Revoked service identities are denied regardless of the intentions.
+	intentions = withRevokedIdentities(intentions, localInfo.revocations)
 
 	// TODO(peering): mkeeler asked that these maps come from proxycfg instead of
 	// being constructed in xds to save memory allocation and gc pressure. Low priority.
@@ -673,6 +703,36 @@ func makeRBACRules(
 	return rbac, nil
 }
 
+// revokedIdentityPrecedence is higher than the precedence of any intention so
+// that the deny intentions of revoked identities are never overridden.
+const revokedIdentityPrecedence = math.MaxInt32
+
+// withRevokedIdentities prepends a deny intention for each revoked service
+// identity to the given intentions.
+//
+// Revocations of single certificates by serial number can't be expressed as
+// RBAC principals. The listeners reject them with the revocation lists in
+// their TLS validation context instead, see injectRevocationLists.
+func withRevokedIdentities(intentions structs.SimplifiedIntentions, revocations []*structs.CARevocation) structs.SimplifiedIntentions {
+	var denied structs.SimplifiedIntentions
+	for _, rev := range revocations {
+		if rev.Service == "" {
+			continue
+		}
+		denied = append(denied, &structs.Intention{
+			SourceName:      rev.Service,
+			SourceNS:        rev.NamespaceOrDefault(),
+			SourcePartition: rev.PartitionOrDefault(),
+			Action:          structs.IntentionActionDeny,
+			Precedence:      revokedIdentityPrecedence,
+		})
+	}
+	if len(denied) == 0 {
+		return intentions
+	}
+	return append(denied, intentions...)
+}
+
 // addJWTPrincipal ensure the passed RBAC/Network principal is associated with
 // a JWT principal when JWTs validation is required.
 //
@@ -857,20 +917,23 @@ func removeSameSourceIntentions(intentions structs.SimplifiedIntentions) structs
 	var (
 		out        = make(structs.SimplifiedIntentions, 0, len(intentions))
 		changed    = false
-		seenSource = make(map[structs.PeeredServiceName]struct{})
+		seenSource = make(map[qualifiedSource]struct{})
 	)
 	for _, ixn := range intentions {
-		psn := structs.PeeredServiceName{
-			ServiceName: ixn.SourceServiceName(),
-			Peer:        ixn.SourcePeer,
+		src := qualifiedSource{
+			PeeredServiceName: structs.PeeredServiceName{
+				ServiceName: ixn.SourceServiceName(),
+				Peer:        ixn.SourcePeer,
+			},
+			TrustDomain: ixn.SourceTrustDomain,
 		}
-		if _, ok := seenSource[psn]; ok {
+		if _, ok := seenSource[src]; ok {
 			// A higher precedence intention already used this exact source
 			// definition with a different destination.
 			changed = true
 			continue
 		}
-		seenSource[psn] = struct{}{}
+		seenSource[src] = struct{}{}
 		out = append(out, ixn)
 	}
 
@@ -880,6 +943,13 @@ func removeSameSourceIntentions(intentions structs.SimplifiedIntentions) structs
 	return out
 }
 
+// qualifiedSource is an intention source including the federated trust
+// domain it may name.
+type qualifiedSource struct {
+	structs.PeeredServiceName
+	TrustDomain string
+}
+
 // ixnSourceMatches determines if the 'tester' service name is matched by the
 // 'against' service name via wildcard rules.
 //
@@ -890,7 +960,8 @@ func removeSameSourceIntentions(intentions structs.SimplifiedIntentions) structs
 // - (default/*, */*)         		=> true,  "any service in any NS" includes "all services in the default NS"
 // - (default/default/*, other/*/*) => false, "any service in "other" partition" does NOT include services in the default partition"
 //
-// Peer and partition must be exact names and cannot be compared with wildcards.
+// Peer, partition and federated trust domain must be exact names and cannot be
+// compared with wildcards.
 func ixnSourceMatches(tester, against rbacService) bool {
 	// We assume that we can't have the same intention twice before arriving
 	// here.
@@ -905,9 +976,10 @@ func ixnSourceMatches(tester, against rbacService) bool {
 
 	matchesAP := tester.PartitionOrDefault() == against.PartitionOrDefault()
 	matchesPeer := tester.Peer == against.Peer
+	matchesTrustDomain := tester.FederatedTrustDomain == against.FederatedTrustDomain
 	matchesNS := tester.NamespaceOrDefault() == against.NamespaceOrDefault() || against.NamespaceOrDefault() == structs.WildcardSpecifier
 	matchesName := tester.Name == against.Name || against.Name == structs.WildcardSpecifier
-	return matchesAP && matchesPeer && matchesNS && matchesName
+	return matchesAP && matchesPeer && matchesTrustDomain && matchesNS && matchesName
 }
 
 // countWild counts the number of wildcard values in the given namespace and name.
@@ -974,8 +1046,18 @@ func notPrincipal(id *envoy_rbac_v3.Principal) *envoy_rbac_v3.Principal {
 }
 
 func idPrincipal(src rbacService) *envoy_rbac_v3.Principal {
-	pattern := makeSpiffePattern(src)
-	return authenticatedPatternPrincipal(pattern)
+	if src.FederatedTrustDomain == "" {
+		pattern := makeSpiffePattern(src)
+		return authenticatedPatternPrincipal(pattern)
+	}
+
+	// Workloads of a federated trust domain are identified by the SPIFFE IDs
+	// of its templates only, see intentionListToIntermediateRBACForm.
+	var ids []*envoy_rbac_v3.Principal
+	for _, pattern := range makeTemplatedSpiffePatterns(src) {
+		ids = append(ids, authenticatedPatternPrincipal(pattern))
+	}
+	return orPrincipals(ids)
 }
 
 func authenticatedPatternPrincipal(pattern string) *envoy_rbac_v3.Principal {
@@ -1160,6 +1242,24 @@ func makeSpiffePattern(src rbacService) string {
 	return fmt.Sprintf(`^%s://%s%s$`, id.URI().Scheme, id.Host, id.URI().Path)
 }
 
+// makeTemplatedSpiffePatterns returns the patterns matching the SPIFFE IDs of
+// a source in its federated trust domain.
+func makeTemplatedSpiffePatterns(src rbacService) []string {
+	id := connect.SpiffeIDService{
+		Partition: src.PartitionOrDefault(),
+		Namespace: src.NamespaceOrDefault(),
+		Service:   src.Name,
+	}
+
+	var patterns []string
+	for _, t := range src.spiffeIDTemplates {
+		if pattern, ok := t.Pattern(id); ok {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
 func makeSpiffeMeshGatewayPattern(gwTrustDomain, gwPartition string) string {
 	id := connect.SpiffeIDMeshGateway{
 		Host:      gwTrustDomain,
@@ -16,6 +16,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/hernad/consul/agent/connect"
 	"github.com/hernad/consul/agent/structs"
 	"github.com/hernad/consul/proto/private/pbpeering"
 )
@@ -480,6 +481,11 @@ func TestMakeRBACNetworkAndHTTPFilters(t *testing.T) {
 		ixn.SourcePeer = peer
 		return ixn
 	}
+	testIntentionFederated := func(src string, trustDomain string, action structs.IntentionAction) *structs.Intention {
+		ixn := testIntention(t, src, "api", action)
+		ixn.SourceTrustDomain = trustDomain
+		return ixn
+	}
 	testSourcePermIntention := func(src string, perms ...*structs.IntentionPermission) *structs.Intention {
 		ixn := testIntention(t, src, "api", "")
 		ixn.Permissions = perms
@@ -555,7 +561,53 @@ func TestMakeRBACNetworkAndHTTPFilters(t *testing.T) {
 	tests := map[string]struct {
 		intentionDefaultAllow bool
 		intentions            structs.SimplifiedIntentions
+		revocations           []*structs.CARevocation
+		federatedTrustDomains []structs.FederatedTrustDomain
 	}{
+		// The wildcard only matches local services, and the intention of the
+		// trust domain without templates is dropped.
+		"default-deny-federated-trust-domain": {
+			intentionDefaultAllow: false,
+			intentions: sorted(
+				testIntentionFederated("web", "example.org", structs.IntentionActionAllow),
+				testIntentionFederated("web", "unknown.org", structs.IntentionActionAllow),
+				testSourceIntention("*", structs.IntentionActionAllow),
+			),
+			federatedTrustDomains: []structs.FederatedTrustDomain{
+				{Name: "example.org", SpiffeIDTemplates: []string{"/ns/{namespace}/sa/{service}"}},
+			},
+		},
+		"default-allow-federated-trust-domain": {
+			intentionDefaultAllow: true,
+			intentions: sorted(
+				testIntentionFederated("web", "example.org", structs.IntentionActionAllow),
+				testIntentionFederated("*", "example.org", structs.IntentionActionDeny),
+				testSourceIntention("*", structs.IntentionActionDeny),
+			),
+			federatedTrustDomains: []structs.FederatedTrustDomain{
+				{Name: "example.org", SpiffeIDTemplates: []string{"/ns/{namespace}/sa/{service}"}},
+			},
+		},
+		"default-deny-revoked-identity": {
+			intentionDefaultAllow: false,
+			intentions: sorted(
+				testSourceIntention("web", structs.IntentionActionAllow),
+				testSourceIntention("*", structs.IntentionActionAllow),
+			),
+			revocations: []*structs.CARevocation{
+				{ID: "rev1", Service: "web"},
+				{ID: "rev2", SerialNumber: "0a:1b"},
+			},
+		},
+		"default-allow-revoked-identity": {
+			intentionDefaultAllow: true,
+			intentions: sorted(
+				testSourceIntention("cron", structs.IntentionActionDeny),
+			),
+			revocations: []*structs.CARevocation{
+				{ID: "rev1", Service: "web"},
+			},
+		},
 		"default-deny-mixed-precedence": {
 			intentionDefaultAllow: false,
 			intentions: sorted(
@@ -916,6 +968,12 @@ func TestMakeRBACNetworkAndHTTPFilters(t *testing.T) {
 	for name, tt := range tests {
 		tt := tt
 		t.Run(name, func(t *testing.T) {
+			testLocalInfo := testLocalInfo
+			testLocalInfo.revocations = tt.revocations
+			templates, err := connect.NewSpiffeIDTemplates(tt.federatedTrustDomains)
+			require.NoError(t, err)
+			testLocalInfo.spiffeIDTemplates = templates
+
 			t.Run("network filter", func(t *testing.T) {
 				filter, err := makeRBACNetworkFilter(tt.intentions, tt.intentionDefaultAllow, testLocalInfo, testPeerTrustBundle)
 				require.NoError(t, err)
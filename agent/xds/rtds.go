@@ -0,0 +1,106 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package xds
+
+import (
+	"reflect"
+
+	envoy_runtime_v3 "github.com/envoyproxy/go-control-plane/envoy/service/runtime/v3"
+	"github.com/hashicorp/go-hclog"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/hernad/consul/envoyextensions/xdscommon"
+)
+
+// RuntimeResourceName is the name of the RTDS resource holding the runtime
+// flags of a proxy. The Envoy bootstrap must declare a runtime layer with this
+// name for the flags to be applied.
+const RuntimeResourceName = "consul"
+
+// addRuntime adds the RTDS resource of the proxy with the given runtime flags.
+// The resource is always served, even without flags, since Envoy waits for the
+// layers declared in its bootstrap before starting.
+func addRuntime(logger hclog.Logger, resources *xdscommon.IndexedResources, flags map[string]interface{}) {
+	layer, err := structpb.NewStruct(runtimeFlags(flags))
+	if err != nil {
+		// Don't hard fail on a config typo, just warn and serve an empty layer
+		// so that the proxy does not block waiting for it.
+		logger.Warn("failed to convert envoy_runtime to runtime flags", "error", err)
+		layer = &structpb.Struct{}
+	}
+	resources.Index[xdscommon.RuntimeType][RuntimeResourceName] = &envoy_runtime_v3.Runtime{
+		Name:  RuntimeResourceName,
+		Layer: layer,
+	}
+}
+
+// runtimeFlags converts the flags decoded from the proxy config to the types
+// supported by structpb. HCL decodes nested blocks to lists of maps, which
+// are merged into a single map since runtime layers are trees of keys: the
+// block form of
+//
+//	envoy_runtime { re2 { max_program_size { error_level = 1000 } } }
+//
+// is equivalent to nested JSON objects.
+func runtimeFlags(flags map[string]interface{}) map[string]interface{} {
+	if flags == nil {
+		return nil
+	}
+	out := make(map[string]interface{}, len(flags))
+	for k, v := range flags {
+		out[k] = runtimeValue(v)
+	}
+	return out
+}
+
+func runtimeValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		return runtimeFlags(val)
+	case []map[string]interface{}:
+		list := make([]interface{}, len(val))
+		for i, m := range val {
+			list[i] = m
+		}
+		return runtimeValue(list)
+	case []interface{}:
+		if merged, ok := mergeRuntimeBlocks(val); ok {
+			return merged
+		}
+		out := make([]interface{}, len(val))
+		for i, e := range val {
+			out[i] = runtimeValue(e)
+		}
+		return out
+	}
+
+	// Other slices, e.g. []string, are lists of values.
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() != reflect.Uint8 {
+		out := make([]interface{}, rv.Len())
+		for i := range out {
+			out[i] = runtimeValue(rv.Index(i).Interface())
+		}
+		return out
+	}
+	return v
+}
+
+// mergeRuntimeBlocks merges a non-empty list of maps into a single map.
+func mergeRuntimeBlocks(list []interface{}) (map[string]interface{}, bool) {
+	if len(list) == 0 {
+		return nil, false
+	}
+	merged := make(map[string]interface{})
+	for _, e := range list {
+		m, ok := e.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		for k, v := range m {
+			merged[k] = runtimeValue(v)
+		}
+	}
+	return merged, true
+}
@@ -0,0 +1,72 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package xds
+
+import (
+	"testing"
+
+	envoy_runtime_v3 "github.com/envoyproxy/go-control-plane/envoy/service/runtime/v3"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hernad/consul/envoyextensions/xdscommon"
+)
+
+func TestAddRuntime(t *testing.T) {
+	cases := map[string]struct {
+		flags  map[string]interface{}
+		expect map[string]interface{}
+	}{
+		"empty": {
+			expect: map[string]interface{}{},
+		},
+		"json": {
+			flags: map[string]interface{}{
+				"re2": map[string]interface{}{
+					"max_program_size": map[string]interface{}{"error_level": 1000},
+				},
+				"tracing.random_sampling": 50.0,
+			},
+			expect: map[string]interface{}{
+				"re2": map[string]interface{}{
+					"max_program_size": map[string]interface{}{"error_level": 1000.0},
+				},
+				"tracing.random_sampling": 50.0,
+			},
+		},
+		"hcl blocks": {
+			flags: map[string]interface{}{
+				"re2": []map[string]interface{}{
+					{"max_program_size": []map[string]interface{}{{"error_level": 1000}}},
+				},
+				"overload": []interface{}{
+					map[string]interface{}{"global_downstream_max_connections": 5000},
+					map[string]interface{}{"premature_reset_min_stream_lifetime_seconds": 1},
+				},
+				"names": []string{"a", "b"},
+			},
+			expect: map[string]interface{}{
+				"re2": map[string]interface{}{
+					"max_program_size": map[string]interface{}{"error_level": 1000.0},
+				},
+				"overload": map[string]interface{}{
+					"global_downstream_max_connections":           5000.0,
+					"premature_reset_min_stream_lifetime_seconds": 1.0,
+				},
+				"names": []interface{}{"a", "b"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			resources := xdscommon.EmptyIndexedResources()
+			addRuntime(hclog.NewNullLogger(), resources, tc.flags)
+
+			runtime := resources.Index[xdscommon.RuntimeType][RuntimeResourceName].(*envoy_runtime_v3.Runtime)
+			require.Equal(t, RuntimeResourceName, runtime.Name)
+			require.Equal(t, tc.expect, runtime.Layer.AsMap())
+		})
+	}
+}
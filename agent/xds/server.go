@@ -6,6 +6,7 @@ package xds
 import (
 	"context"
 	"errors"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -118,10 +119,31 @@ type Server struct {
 	// there has been no recent DiscoveryRequest).
 	AuthCheckFrequency time.Duration
 
+	// PushCoalesceWindow is how long a stream waits for more proxy config
+	// snapshots before generating and pushing resources to Envoy, so that a
+	// burst of catalog changes results in a single push.
+	// EndpointPushCoalesceWindow is used instead when the snapshots only
+	// change the endpoints of upstreams. Zero disables coalescing.
+	PushCoalesceWindow         time.Duration
+	EndpointPushCoalesceWindow time.Duration
+
+	// MaxConcurrentPushes limits how many streams generate and push resources
+	// at the same time. Endpoint-only updates are served first. Zero means no
+	// limit.
+	MaxConcurrentPushes int
+
 	// ResourceMapMutateFn exclusively exists for testing purposes.
 	ResourceMapMutateFn func(resourceMap *xdscommon.IndexedResources)
 
 	activeStreams *activeStreamCounters
+
+	pushBudgetOnce sync.Once
+	pushBudget     *pushBudget
+
+	// debugStreams holds the delta streams that can be inspected with
+	// DeltaStreamDebug, keyed by proxy ID.
+	debugStreamsLock sync.Mutex
+	debugStreams     map[structs.ServiceID]*deltaStreamDebugHandle
 }
 
 // activeStreamCounters tracks various stream-related metrics.
@@ -40,11 +40,14 @@ type ACLToken struct {
 	Roles             []*ACLTokenRoleLink   `json:",omitempty"`
 	ServiceIdentities []*ACLServiceIdentity `json:",omitempty"`
 	NodeIdentities    []*ACLNodeIdentity    `json:",omitempty"`
+	TemplatedPolicies []*ACLTemplatedPolicy `json:",omitempty"`
 	Local             bool
 	AuthMethod        string        `json:",omitempty"`
+	ParentAccessorID  string        `json:",omitempty"`
 	ExpirationTTL     time.Duration `json:",omitempty"`
 	ExpirationTime    *time.Time    `json:",omitempty"`
 	CreateTime        time.Time     `json:",omitempty"`
+	LastUsedTime      *time.Time    `json:",omitempty"`
 	Hash              []byte        `json:",omitempty"`
 
 	// DEPRECATED (ACL-Legacy-Compat)
@@ -64,6 +67,20 @@ type ACLToken struct {
 	AuthMethodNamespace string `json:",omitempty"`
 }
 
+// ACLTokenFilterOptions is used to filter the tokens returned by
+// TokenListFiltered.
+type ACLTokenFilterOptions struct {
+	AuthMethod string
+	Policy     string
+	Role       string
+
+	// UnusedFor only includes the tokens that were not used within the given
+	// duration. Tokens that were never used are included once they are older
+	// than the duration. The usage of global tokens is aggregated in the
+	// primary datacenter, so secondary datacenters only return local tokens.
+	UnusedFor time.Duration
+}
+
 type ACLTokenExpanded struct {
 	ExpandedPolicies []ACLPolicy
 	ExpandedRoles    []ACLRole
@@ -88,10 +105,13 @@ type ACLTokenListEntry struct {
 	Roles             []*ACLTokenRoleLink   `json:",omitempty"`
 	ServiceIdentities []*ACLServiceIdentity `json:",omitempty"`
 	NodeIdentities    []*ACLNodeIdentity    `json:",omitempty"`
+	TemplatedPolicies []*ACLTemplatedPolicy `json:",omitempty"`
 	Local             bool
 	AuthMethod        string     `json:",omitempty"`
+	ParentAccessorID  string     `json:",omitempty"`
 	ExpirationTime    *time.Time `json:",omitempty"`
 	CreateTime        time.Time
+	LastUsedTime      *time.Time `json:",omitempty"`
 	Hash              []byte
 	Legacy            bool `json:"-"` // DEPRECATED
 
@@ -121,16 +141,17 @@ type ACLEntry struct {
 
 // ACLReplicationStatus is used to represent the status of ACL replication.
 type ACLReplicationStatus struct {
-	Enabled              bool
-	Running              bool
-	SourceDatacenter     string
-	ReplicationType      string
-	ReplicatedIndex      uint64
-	ReplicatedRoleIndex  uint64
-	ReplicatedTokenIndex uint64
-	LastSuccess          time.Time
-	LastError            time.Time
-	LastErrorMessage     string
+	Enabled                       bool
+	Running                       bool
+	SourceDatacenter              string
+	ReplicationType               string
+	ReplicatedIndex               uint64
+	ReplicatedRoleIndex           uint64
+	ReplicatedPolicyTemplateIndex uint64
+	ReplicatedTokenIndex          uint64
+	LastSuccess                   time.Time
+	LastError                     time.Time
+	LastErrorMessage              string
 }
 
 // ACLServiceIdentity represents a high-level grant of all necessary privileges
@@ -148,6 +169,15 @@ type ACLNodeIdentity struct {
 	Datacenter string
 }
 
+// ACLTemplatedPolicy links an ACL Policy Template and binds values to the
+// variables used in its rules.
+type ACLTemplatedPolicy struct {
+	TemplateID   string
+	TemplateName string
+	Variables    map[string]string `json:",omitempty"`
+	Datacenters  []string          `json:",omitempty"`
+}
+
 // ACLPolicy represents an ACL Policy.
 type ACLPolicy struct {
 	ID          string
@@ -186,6 +216,27 @@ type ACLPolicyListEntry struct {
 	Partition string `json:",omitempty"`
 }
 
+// ACLPolicyTemplate represents an ACL Policy Template. Its rules may refer to
+// variables as ${name} which are bound by the tokens and roles linking it.
+type ACLPolicyTemplate struct {
+	ID          string
+	Name        string
+	Description string
+	Rules       string
+	Datacenters []string
+	Hash        []byte
+	CreateIndex uint64
+	ModifyIndex uint64
+
+	// Namespace is the namespace the ACLPolicyTemplate is associated with.
+	// Namespacing is a Consul Enterprise feature.
+	Namespace string `json:",omitempty"`
+
+	// Partition is the partition the ACLPolicyTemplate is associated with.
+	// Partitions are a Consul Enterprise feature.
+	Partition string `json:",omitempty"`
+}
+
 type ACLRolePolicyLink = ACLLink
 
 // ACLRole represents an ACL Role.
@@ -196,6 +247,7 @@ type ACLRole struct {
 	Policies          []*ACLRolePolicyLink  `json:",omitempty"`
 	ServiceIdentities []*ACLServiceIdentity `json:",omitempty"`
 	NodeIdentities    []*ACLNodeIdentity    `json:",omitempty"`
+	TemplatedPolicies []*ACLTemplatedPolicy `json:",omitempty"`
 	Hash              []byte
 	CreateIndex       uint64
 	ModifyIndex       uint64
@@ -218,6 +270,10 @@ const (
 
 	// BindingRuleBindTypeRole binds to pre-existing roles with the given name.
 	BindingRuleBindTypeRole BindingRuleBindType = "role"
+
+	// BindingRuleBindTypeTemplatedPolicy binds to the policy template with the
+	// given name, using BindVars as the values of its variables.
+	BindingRuleBindTypeTemplatedPolicy BindingRuleBindType = "templated-policy"
 )
 
 type ACLBindingRule struct {
@@ -227,6 +283,7 @@ type ACLBindingRule struct {
 	Selector    string
 	BindType    BindingRuleBindType
 	BindName    string
+	BindVars    map[string]string `json:",omitempty"`
 
 	CreateIndex uint64
 	ModifyIndex uint64
@@ -442,6 +499,8 @@ type OIDCAuthMethodConfig struct {
 	// just for type=jwt
 	JWKSURL              string        `json:",omitempty"`
 	JWKSCACert           string        `json:",omitempty"`
+	JWKS                 string        `json:",omitempty"`
+	JWKSFile             string        `json:",omitempty"`
 	JWTValidationPubKeys []string      `json:",omitempty"`
 	BoundIssuer          string        `json:",omitempty"`
 	ExpirationLeeway     time.Duration `json:",omitempty"`
@@ -470,6 +529,8 @@ func (c *OIDCAuthMethodConfig) RenderToConfig() map[string]interface{} {
 		// just for type=jwt
 		"JWKSURL":              c.JWKSURL,
 		"JWKSCACert":           c.JWKSCACert,
+		"JWKS":                 c.JWKS,
+		"JWKSFile":             c.JWKSFile,
 		"JWTValidationPubKeys": c.JWTValidationPubKeys,
 		"BoundIssuer":          c.BoundIssuer,
 		"ExpirationLeeway":     c.ExpirationLeeway,
@@ -484,6 +545,59 @@ type ACLLoginParams struct {
 	Meta        map[string]string `json:",omitempty"`
 }
 
+// ACLAuthorizationRequest is an authorization to check, such as a "write"
+// Access on the "key" Resource with the Segment "foo/bar".
+type ACLAuthorizationRequest struct {
+	Resource  string
+	Segment   string `json:",omitempty"`
+	Access    string
+	Namespace string `json:",omitempty"`
+	Partition string `json:",omitempty"`
+}
+
+// ACLAuthorizeExplainParams are the authorizations to explain, for the token
+// of the request or the token with the AccessorID.
+type ACLAuthorizeExplainParams struct {
+	AccessorID string `json:",omitempty"`
+	Requests   []ACLAuthorizationRequest
+}
+
+// ACLAuthorizationExplanation is the decision of an authorization along with
+// what produced it.
+type ACLAuthorizationExplanation struct {
+	ACLAuthorizationRequest
+	Allow bool
+
+	// Reason describes what produced the decision.
+	Reason string
+
+	// PolicyID, PolicyName and Rule identify the rule that produced the
+	// decision, when it was produced by a policy of the token.
+	PolicyID   string
+	PolicyName string
+	Rule       *ACLRuleMatch
+
+	// Matches are all the policies of the token with a rule applying to
+	// the authorization.
+	Matches []ACLAuthorizationPolicyMatch
+}
+
+// ACLRuleMatch is a rule of a policy, such as key_prefix "foo/".
+type ACLRuleMatch struct {
+	Type   string
+	Name   string
+	Policy string
+}
+
+// ACLAuthorizationPolicyMatch is a policy with a rule applying to an
+// authorization, and whether the policy alone allows it.
+type ACLAuthorizationPolicyMatch struct {
+	PolicyID   string
+	PolicyName string
+	Rule       ACLRuleMatch
+	Allow      bool
+}
+
 type ACLOIDCAuthURLParams struct {
 	AuthMethod  string
 	RedirectURI string
@@ -776,6 +890,38 @@ func (a *ACL) TokenClone(accessorID string, description string, q *WriteOptions)
 	return &out, wm, nil
 }
 
+// TokenCreateChild creates a child of the token used to make the request. The
+// child may only be linked to a subset of the policies, roles and identities of
+// its parent, must set an ExpirationTTL or ExpirationTime no later than the
+// expiration of its parent, and is deleted along with its parent. Creating a
+// child token does not require acl:write.
+func (a *ACL) TokenCreateChild(token *ACLToken, q *WriteOptions) (*ACLToken, *WriteMeta, error) {
+	if token.AccessorID != "" {
+		return nil, nil, fmt.Errorf("Cannot specify an AccessorID in Child Token Creation")
+	}
+	if token.SecretID != "" {
+		return nil, nil, fmt.Errorf("Cannot specify a SecretID in Child Token Creation")
+	}
+	r := a.c.newRequest("PUT", "/v1/acl/token/self/child")
+	r.setWriteOptions(q)
+	r.obj = token
+	rtt, resp, err := a.c.doRequest(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeResponseBody(resp)
+	if err := requireOK(resp); err != nil {
+		return nil, nil, err
+	}
+	wm := &WriteMeta{RequestTime: rtt}
+	var out ACLToken
+	if err := decodeBody(resp, &out); err != nil {
+		return nil, nil, err
+	}
+
+	return &out, wm, nil
+}
+
 // TokenDelete removes a single ACL token. The accessorID parameter must be a valid
 // Accessor ID of an existing token.
 func (a *ACL) TokenDelete(accessorID string, q *WriteOptions) (*WriteMeta, error) {
@@ -874,8 +1020,29 @@ func (a *ACL) TokenReadSelf(q *QueryOptions) (*ACLToken, *QueryMeta, error) {
 // TokenList lists all tokens. The listing does not contain any SecretIDs as those
 // may only be retrieved by a call to TokenRead.
 func (a *ACL) TokenList(q *QueryOptions) ([]*ACLTokenListEntry, *QueryMeta, error) {
+	return a.TokenListFiltered(ACLTokenFilterOptions{}, q)
+}
+
+// TokenListFiltered lists all tokens that match the given filter options.
+// The listing does not contain any SecretIDs as those may only be retrieved by
+// a call to TokenRead.
+func (a *ACL) TokenListFiltered(t ACLTokenFilterOptions, q *QueryOptions) ([]*ACLTokenListEntry, *QueryMeta, error) {
 	r := a.c.newRequest("GET", "/v1/acl/tokens")
 	r.setQueryOptions(q)
+
+	if t.AuthMethod != "" {
+		r.params.Set("authmethod", t.AuthMethod)
+	}
+	if t.Policy != "" {
+		r.params.Set("policy", t.Policy)
+	}
+	if t.Role != "" {
+		r.params.Set("role", t.Role)
+	}
+	if t.UnusedFor > 0 {
+		r.params.Set("unused-for", t.UnusedFor.String())
+	}
+
 	rtt, resp, err := a.c.doRequest(r)
 	if err != nil {
 		return nil, nil, err
@@ -1043,6 +1210,155 @@ func (a *ACL) PolicyList(q *QueryOptions) ([]*ACLPolicyListEntry, *QueryMeta, er
 	return entries, qm, nil
 }
 
+// PolicyTemplateCreate will create a new policy template. It is not allowed for
+// the template parameters ID field to be set as this will be generated by Consul
+// while processing the request.
+func (a *ACL) PolicyTemplateCreate(template *ACLPolicyTemplate, q *WriteOptions) (*ACLPolicyTemplate, *WriteMeta, error) {
+	if template.ID != "" {
+		return nil, nil, fmt.Errorf("Cannot specify an ID in Policy Template Creation")
+	}
+	r := a.c.newRequest("PUT", "/v1/acl/policy-template")
+	r.setWriteOptions(q)
+	r.obj = template
+	rtt, resp, err := a.c.doRequest(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeResponseBody(resp)
+	if err := requireOK(resp); err != nil {
+		return nil, nil, err
+	}
+	wm := &WriteMeta{RequestTime: rtt}
+	var out ACLPolicyTemplate
+	if err := decodeBody(resp, &out); err != nil {
+		return nil, nil, err
+	}
+
+	return &out, wm, nil
+}
+
+// PolicyTemplateUpdate updates a policy template. The ID field of the template
+// parameter must be set to an existing policy template ID
+func (a *ACL) PolicyTemplateUpdate(template *ACLPolicyTemplate, q *WriteOptions) (*ACLPolicyTemplate, *WriteMeta, error) {
+	if template.ID == "" {
+		return nil, nil, fmt.Errorf("Must specify an ID in Policy Template Update")
+	}
+
+	r := a.c.newRequest("PUT", "/v1/acl/policy-template/"+template.ID)
+	r.setWriteOptions(q)
+	r.obj = template
+	rtt, resp, err := a.c.doRequest(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeResponseBody(resp)
+	if err := requireOK(resp); err != nil {
+		return nil, nil, err
+	}
+	wm := &WriteMeta{RequestTime: rtt}
+	var out ACLPolicyTemplate
+	if err := decodeBody(resp, &out); err != nil {
+		return nil, nil, err
+	}
+
+	return &out, wm, nil
+}
+
+// PolicyTemplateDelete deletes a policy template given its ID.
+func (a *ACL) PolicyTemplateDelete(templateID string, q *WriteOptions) (*WriteMeta, error) {
+	r := a.c.newRequest("DELETE", "/v1/acl/policy-template/"+templateID)
+	r.setWriteOptions(q)
+	rtt, resp, err := a.c.doRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	closeResponseBody(resp)
+	if err := requireOK(resp); err != nil {
+		return nil, err
+	}
+
+	wm := &WriteMeta{RequestTime: rtt}
+	return wm, nil
+}
+
+// PolicyTemplateRead retrieves the policy template details including the rules.
+func (a *ACL) PolicyTemplateRead(templateID string, q *QueryOptions) (*ACLPolicyTemplate, *QueryMeta, error) {
+	r := a.c.newRequest("GET", "/v1/acl/policy-template/"+templateID)
+	r.setQueryOptions(q)
+	rtt, resp, err := a.c.doRequest(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeResponseBody(resp)
+	if err := requireOK(resp); err != nil {
+		return nil, nil, err
+	}
+	qm := &QueryMeta{}
+	parseQueryMeta(resp, qm)
+	qm.RequestTime = rtt
+
+	var out ACLPolicyTemplate
+	if err := decodeBody(resp, &out); err != nil {
+		return nil, nil, err
+	}
+
+	return &out, qm, nil
+}
+
+// PolicyTemplateReadByName retrieves the policy template details including the
+// rules with name.
+func (a *ACL) PolicyTemplateReadByName(templateName string, q *QueryOptions) (*ACLPolicyTemplate, *QueryMeta, error) {
+	r := a.c.newRequest("GET", "/v1/acl/policy-template/name/"+url.QueryEscape(templateName))
+	r.setQueryOptions(q)
+	rtt, resp, err := a.c.doRequest(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeResponseBody(resp)
+	found, resp, err := requireNotFoundOrOK(resp)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	qm := &QueryMeta{}
+	parseQueryMeta(resp, qm)
+	qm.RequestTime = rtt
+
+	if !found {
+		return nil, qm, nil
+	}
+
+	var out ACLPolicyTemplate
+	if err := decodeBody(resp, &out); err != nil {
+		return nil, nil, err
+	}
+
+	return &out, qm, nil
+}
+
+// PolicyTemplateList retrieves a listing of all policy templates.
+func (a *ACL) PolicyTemplateList(q *QueryOptions) ([]*ACLPolicyTemplate, *QueryMeta, error) {
+	r := a.c.newRequest("GET", "/v1/acl/policy-templates")
+	r.setQueryOptions(q)
+	rtt, resp, err := a.c.doRequest(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeResponseBody(resp)
+	if err := requireOK(resp); err != nil {
+		return nil, nil, err
+	}
+	qm := &QueryMeta{}
+	parseQueryMeta(resp, qm)
+	qm.RequestTime = rtt
+
+	var entries []*ACLPolicyTemplate
+	if err := decodeBody(resp, &entries); err != nil {
+		return nil, nil, err
+	}
+	return entries, qm, nil
+}
+
 // RulesTranslate translates the legacy rule syntax into the current syntax.
 //
 // Deprecated: Support for the legacy syntax translation has been removed.
@@ -1515,6 +1831,29 @@ func (a *ACL) Logout(q *WriteOptions) (*WriteMeta, error) {
 	return wm, nil
 }
 
+// AuthorizeExplain returns whether the token is allowed the authorizations,
+// along with the policy and rule of the token that produced each decision.
+func (a *ACL) AuthorizeExplain(params *ACLAuthorizeExplainParams, q *WriteOptions) ([]*ACLAuthorizationExplanation, *WriteMeta, error) {
+	r := a.c.newRequest("POST", "/v1/acl/authorize-explain")
+	r.setWriteOptions(q)
+	r.obj = params
+
+	rtt, resp, err := a.c.doRequest(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeResponseBody(resp)
+	if err := requireOK(resp); err != nil {
+		return nil, nil, err
+	}
+	wm := &WriteMeta{RequestTime: rtt}
+	var out []*ACLAuthorizationExplanation
+	if err := decodeBody(resp, &out); err != nil {
+		return nil, nil, err
+	}
+	return out, wm, nil
+}
+
 // OIDCAuthURL requests an authorization URL to start an OIDC login flow.
 func (a *ACL) OIDCAuthURL(auth *ACLOIDCAuthURLParams, q *WriteOptions) (string, *WriteMeta, error) {
 	if auth.AuthMethod == "" {
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 )
 
 // ServiceKind is the kind of service being registered.
@@ -76,6 +77,21 @@ type AgentCheck struct {
 	Partition   string `json:",omitempty"`
 }
 
+// AgentCheckHistory is the recent history of a check known to the agent
+type AgentCheckHistory struct {
+	CheckID  string
+	Status   string
+	Flapping bool
+	History  []AgentCheckTransition
+}
+
+// AgentCheckTransition is a status change of a check, see AgentCheckHistory
+type AgentCheckTransition struct {
+	Status string
+	Output string
+	Time   time.Time
+}
+
 // AgentWeights represent optional weights for a service
 type AgentWeights struct {
 	Passing int
@@ -344,8 +360,20 @@ type AgentServiceCheck struct {
 	Header                 map[string][]string `json:",omitempty"`
 	Method                 string              `json:",omitempty"`
 	Body                   string              `json:",omitempty"`
+	BodyRegex              string              `json:",omitempty"`
+	JSONPath               string              `json:",omitempty"`
+	JSONPathValue          string              `json:",omitempty"`
+	RequiredHeaders        map[string]string   `json:",omitempty"`
+	LatencyWarning         string              `json:",omitempty"`
 	TCP                    string              `json:",omitempty"`
 	UDP                    string              `json:",omitempty"`
+	DNS                    string              `json:",omitempty"`
+	DNSServer              string              `json:",omitempty"`
+	DNSRecordType          string              `json:",omitempty"`
+	DNSExpect              []string            `json:",omitempty"`
+	TLSCert                string              `json:",omitempty"`
+	TLSCertWarningDays     int                 `json:",omitempty"`
+	TLSCertCriticalDays    int                 `json:",omitempty"`
 	Status                 string              `json:",omitempty"`
 	Notes                  string              `json:",omitempty"`
 	TLSServerName          string              `json:",omitempty"`
@@ -356,6 +384,13 @@ type AgentServiceCheck struct {
 	H2PingUseTLS           bool                `json:",omitempty"`
 	AliasNode              string              `json:",omitempty"`
 	AliasService           string              `json:",omitempty"`
+	CompositeChecks        []string            `json:",omitempty"`
+	CompositeNode          string              `json:",omitempty"`
+	CompositeMembers       []CompositeMember   `json:",omitempty"`
+	CompositeOperator      string              `json:",omitempty"`
+	CompositeThreshold     int                 `json:",omitempty"`
+	CompositeStatus        string              `json:",omitempty"`
+	CompositePartialStatus string              `json:",omitempty"`
 	SuccessBeforePassing   int                 `json:",omitempty"`
 	FailuresBeforeWarning  int                 `json:",omitempty"`
 	FailuresBeforeCritical int                 `json:",omitempty"`
@@ -370,6 +405,14 @@ type AgentServiceCheck struct {
 }
 type AgentServiceChecks []*AgentServiceCheck
 
+// CompositeMember is a check combined by a composite check along with the
+// node it is registered on. An empty Node is the node of the agent running
+// the composite check.
+type CompositeMember struct {
+	Node    string `json:",omitempty"`
+	CheckID string
+}
+
 // AgentToken is used when updating ACL tokens for an agent.
 type AgentToken struct {
 	Token string
@@ -618,6 +661,26 @@ func (a *Agent) ChecksWithFilterOpts(filter string, q *QueryOptions) (map[string
 	return out, nil
 }
 
+// CheckHistory returns the recent status transitions of a locally registered
+// check and whether it is flapping
+func (a *Agent) CheckHistory(checkID string, q *QueryOptions) (*AgentCheckHistory, error) {
+	r := a.c.newRequest("GET", "/v1/agent/check/"+checkID+"/history")
+	r.setQueryOptions(q)
+	_, resp, err := a.c.doRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	defer closeResponseBody(resp)
+	if err := requireOK(resp); err != nil {
+		return nil, err
+	}
+	var out *AgentCheckHistory
+	if err := decodeBody(resp, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Services returns the locally registered services
 func (a *Agent) Services() (map[string]*AgentService, error) {
 	return a.ServicesWithFilter("")
@@ -1174,6 +1237,49 @@ func (a *Agent) ConnectCALeaf(serviceID string, q *QueryOptions) (*LeafCert, *Qu
 	return &out, qm, nil
 }
 
+// JWTSVIDRequest is the request to issue a JWT-SVID to a service.
+type JWTSVIDRequest struct {
+	// Audience is the list of audiences of the token, at least one is
+	// required.
+	Audience []string
+
+	// TTL is how long the token is valid for, as a duration string like
+	// "10m". It defaults to 5 minutes and cannot exceed one hour.
+	TTL string `json:",omitempty"`
+}
+
+// JWTSVID is a JWT-SVID issued to a service.
+type JWTSVID struct {
+	Token     string
+	SpiffeID  string
+	ExpiresAt time.Time
+}
+
+// ConnectCAJWTSVID issues a short-lived JWT-SVID to the given service. Note
+// that this takes the service name, not the service ID.
+func (a *Agent) ConnectCAJWTSVID(serviceName string, req *JWTSVIDRequest, q *WriteOptions) (*JWTSVID, *WriteMeta, error) {
+	r := a.c.newRequest("PUT", "/v1/agent/connect/ca/jwt-svid/"+serviceName)
+	r.setWriteOptions(q)
+	r.obj = req
+	rtt, resp, err := a.c.doRequest(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeResponseBody(resp)
+	if err := requireOK(resp); err != nil {
+		return nil, nil, err
+	}
+
+	wm := &WriteMeta{}
+	wm.RequestTime = rtt
+
+	var out JWTSVID
+	if err := decodeBody(resp, &out); err != nil {
+		return nil, nil, err
+	}
+	return &out, wm, nil
+}
+
 // EnableServiceMaintenance toggles service maintenance mode on
 // for the given service ID.
 func (a *Agent) EnableServiceMaintenance(serviceID, reason string) error {
@@ -1742,6 +1742,31 @@ func TestAPI_AgentConnectCARoots_list(t *testing.T) {
 	require.Len(t, list.Roots, 1)
 }
 
+func TestAPI_AgentConnectCAJWTSVID(t *testing.T) {
+	t.Parallel()
+
+	c, s := makeClient(t)
+	defer s.Stop()
+
+	s.WaitForActiveCARoot(t)
+
+	agent := c.Agent()
+	retry.Run(t, func(r *retry.R) {
+		list, _, err := c.Connect().CARoots(nil)
+		require.NoError(r, err)
+		require.Len(r, list.JWTSigningKeys, 1)
+	})
+
+	_, _, err := agent.ConnectCAJWTSVID("foo", &JWTSVIDRequest{}, nil)
+	require.ErrorContains(t, err, "At least one audience is required")
+
+	svid, _, err := agent.ConnectCAJWTSVID("foo", &JWTSVIDRequest{Audience: []string{"api"}, TTL: "10m"}, nil)
+	require.NoError(t, err)
+	require.NotEmpty(t, svid.Token)
+	require.Contains(t, svid.SpiffeID, "/svc/foo")
+	require.True(t, svid.ExpiresAt.After(time.Now()))
+}
+
 func TestAPI_AgentConnectCALeaf(t *testing.T) {
 	t.Parallel()
 
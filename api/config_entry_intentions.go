@@ -26,6 +26,7 @@ type SourceIntention struct {
 	Partition     string                 `json:",omitempty"`
 	Namespace     string                 `json:",omitempty"`
 	SamenessGroup string                 `json:",omitempty" alias:"sameness_group"`
+	TrustDomain   string                 `json:",omitempty" alias:"trust_domain"`
 	Action        IntentionAction        `json:",omitempty"`
 	Permissions   []*IntentionPermission `json:",omitempty"`
 	Precedence    int
@@ -78,11 +78,140 @@ func ParseConsulCAConfig(raw map[string]interface{}) (*ConsulCAProviderConfig, e
 	return &config, nil
 }
 
+// CADryRunReport describes what applying a CA configuration would change, as
+// returned by CASetConfigDryRun.
+type CADryRunReport struct {
+	// Provider is the provider of the new configuration.
+	Provider string
+
+	// NoOp is true when the new configuration is identical to the current one.
+	NoOp bool
+
+	// RootRotation is true when applying the configuration would rotate the
+	// root certificate. It is only set in the primary datacenter.
+	RootRotation bool
+
+	// IntermediateRotation is true when applying the configuration in a
+	// secondary datacenter would require a new intermediate certificate.
+	IntermediateRotation bool
+
+	// CurrentRoot is the root certificate that is active now and NewRoot the
+	// one that would be active after the change. NewRoot is only set when the
+	// new root could be generated without side effects.
+	CurrentRoot *CADryRunRoot `json:",omitempty"`
+	NewRoot     *CADryRunRoot `json:",omitempty"`
+
+	// CrossSigning is one of "not-required", "cross-signed", "supported" or
+	// "skipped" and describes how the current root would vouch for the new
+	// one.
+	CrossSigning string
+
+	// Datacenters, Peers and Gateways list the parts of the mesh that would
+	// have to pick up new certificates, and Proxies is the number of proxy
+	// instances in the datacenter that would need new leaf certificates.
+	Datacenters []CADryRunDatacenter
+	Peers       []CADryRunPeer
+	Gateways    []CADryRunGateway
+	Proxies     int
+
+	// Warnings lists the problems that would not prevent the configuration
+	// from being applied but are likely to cause disruption.
+	Warnings []string
+}
+
+// CADryRunRoot summarizes a root certificate in a CADryRunReport.
+type CADryRunRoot struct {
+	ID             string
+	PrivateKeyType string
+	PrivateKeyBits int
+	NotAfter       time.Time
+}
+
+// CADryRunDatacenter describes a datacenter affected by a CA change.
+type CADryRunDatacenter struct {
+	Datacenter     string
+	Primary        bool
+	Provider       string `json:",omitempty"`
+	PrivateKeyType string `json:",omitempty"`
+	PrivateKeyBits int    `json:",omitempty"`
+
+	// Error is set when the configuration of the datacenter could not be read.
+	Error string `json:",omitempty"`
+}
+
+// CADryRunPeer describes a cluster peer that would receive the new root.
+type CADryRunPeer struct {
+	Name      string
+	Partition string `json:",omitempty"`
+	State     string
+}
+
+// CADryRunGateway describes a gateway service whose instances would need new
+// leaf certificates.
+type CADryRunGateway struct {
+	Kind      ServiceKind
+	Name      string
+	Instances int
+	Namespace string `json:",omitempty"`
+	Partition string `json:",omitempty"`
+}
+
 // CARootList is the structure for the results of listing roots.
 type CARootList struct {
 	ActiveRootID string
 	TrustDomain  string
 	Roots        []*CARoot
+
+	// Revocations lists the leaf certificates and service identities that
+	// are no longer trusted even though they chain to one of Roots.
+	Revocations []*CARevocation `json:",omitempty"`
+
+	// FederatedTrustDomains lists the SPIFFE trust domains not managed by
+	// Consul whose workload identities are accepted as service identities.
+	FederatedTrustDomains []CAFederatedTrustDomain `json:",omitempty"`
+
+	// FederatedTrustBundles are the roots fetched from the SPIFFE bundle
+	// endpoints of the federated trust domains.
+	FederatedTrustBundles []CAFederatedTrustBundle `json:",omitempty"`
+
+	// JWTSigningKeys are the public keys used to verify the JWT-SVIDs issued
+	// by Consul.
+	JWTSigningKeys []*CAJWTSigningKey `json:",omitempty"`
+}
+
+// CAFederatedTrustDomain describes how the SPIFFE IDs of a trust domain not
+// managed by Consul map to service identities.
+type CAFederatedTrustDomain struct {
+	Name              string
+	SpiffeIDTemplates []string `json:",omitempty"`
+
+	// BundleEndpointURL is the SPIFFE bundle endpoint the roots of the trust
+	// domain are fetched from, if any.
+	BundleEndpointURL    string `json:",omitempty"`
+	BundleEndpointCACert string `json:",omitempty"`
+}
+
+// CAFederatedTrustBundle is the trust bundle of a federated trust domain as
+// last fetched from its SPIFFE bundle endpoint.
+type CAFederatedTrustBundle struct {
+	TrustDomain string
+	RootPEMs    []string
+	Sequence    uint64        `json:",omitempty"`
+	RefreshHint time.Duration `json:",omitempty"`
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// CAJWTSigningKey is a public key used to verify the JWT-SVIDs issued by
+// Consul. Only the Active key signs new tokens.
+type CAJWTSigningKey struct {
+	ID           string
+	PublicKey    string
+	Active       bool
+	CreatedAt    time.Time
+	RotatedOutAt time.Time
+	CreateIndex  uint64
+	ModifyIndex  uint64
 }
 
 // CARoot represents a root CA certificate that is trusted.
@@ -107,6 +236,38 @@ type CARoot struct {
 	ModifyIndex uint64
 }
 
+// CARevocation revokes either a single leaf certificate, identified by its
+// serial number, or every certificate issued to a service identity.
+type CARevocation struct {
+	// ID is a unique ID (UUID) for the revocation.
+	ID string `json:",omitempty"`
+
+	// SerialNumber is the serial number of the revoked leaf certificate.
+	// Proxies only enforce it when the CA provider of every datacenter can
+	// sign revocation lists, which the Vault and AWS providers can't.
+	SerialNumber string `json:",omitempty"`
+
+	// Service is the name of the revoked service identity.
+	Service string `json:",omitempty"`
+
+	// Namespace and Partition of Service.
+	Namespace string `json:",omitempty"`
+	Partition string `json:",omitempty"`
+
+	// Reason is an optional description of why it was revoked.
+	Reason string `json:",omitempty"`
+
+	// RevokedAt is the time at which the revocation was created.
+	RevokedAt time.Time
+
+	// ExpiresAt is the latest time at which the certificate revoked by
+	// SerialNumber expires. The revocation is deleted after that time.
+	ExpiresAt time.Time
+
+	CreateIndex uint64 `json:",omitempty"`
+	ModifyIndex uint64 `json:",omitempty"`
+}
+
 // LeafCert is a certificate that has been issued by a Connect CA.
 type LeafCert struct {
 	// SerialNumber is the unique serial number for this certificate.
@@ -199,3 +360,72 @@ func (h *Connect) CASetConfig(conf *CAConfig, q *WriteOptions) (*WriteMeta, erro
 	wm.RequestTime = rtt
 	return wm, nil
 }
+
+// CASetConfigDryRun validates the given CA configuration and reports what
+// applying it would change, without applying it.
+func (h *Connect) CASetConfigDryRun(conf *CAConfig, q *WriteOptions) (*CADryRunReport, *WriteMeta, error) {
+	r := h.c.newRequest("PUT", "/v1/connect/ca/configuration")
+	r.setWriteOptions(q)
+	r.params.Set("dry-run", "")
+	r.obj = conf
+	rtt, resp, err := h.c.doRequest(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeResponseBody(resp)
+	if err := requireOK(resp); err != nil {
+		return nil, nil, err
+	}
+
+	wm := &WriteMeta{}
+	wm.RequestTime = rtt
+
+	var out CADryRunReport
+	if err := decodeBody(resp, &out); err != nil {
+		return nil, nil, err
+	}
+	return &out, wm, nil
+}
+
+// CARevoke revokes a leaf certificate or a service identity in every
+// datacenter. It returns the created revocation.
+func (h *Connect) CARevoke(rev *CARevocation, q *WriteOptions) (*CARevocation, *WriteMeta, error) {
+	r := h.c.newRequest("PUT", "/v1/connect/ca/revoke")
+	r.setWriteOptions(q)
+	r.obj = rev
+	rtt, resp, err := h.c.doRequest(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeResponseBody(resp)
+	if err := requireOK(resp); err != nil {
+		return nil, nil, err
+	}
+
+	wm := &WriteMeta{}
+	wm.RequestTime = rtt
+
+	var out CARevocation
+	if err := decodeBody(resp, &out); err != nil {
+		return nil, nil, err
+	}
+	return &out, wm, nil
+}
+
+// CARevocationDelete deletes the revocation with the given ID.
+func (h *Connect) CARevocationDelete(id string, q *WriteOptions) (*WriteMeta, error) {
+	r := h.c.newRequest("DELETE", "/v1/connect/ca/revocation/"+id)
+	r.setWriteOptions(q)
+	rtt, resp, err := h.c.doRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	defer closeResponseBody(resp)
+	if err := requireOK(resp); err != nil {
+		return nil, err
+	}
+
+	wm := &WriteMeta{}
+	wm.RequestTime = rtt
+	return wm, nil
+}
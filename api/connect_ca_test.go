@@ -108,3 +108,65 @@ func TestAPI_ConnectCAConfig_get_set(t *testing.T) {
 		require.Equal(r, "bar", updated.State["foo"])
 	})
 }
+
+func TestAPI_ConnectCAConfig_DryRun(t *testing.T) {
+	t.Parallel()
+
+	c, s := makeClient(t)
+	defer s.Stop()
+
+	s.WaitForSerfCheck(t)
+
+	conf := &CAConfig{
+		Provider: "consul",
+		Config: map[string]interface{}{
+			"PrivateKeyType": "rsa",
+			"PrivateKeyBits": 2048,
+		},
+	}
+
+	// This fails occasionally if server doesn't have time to bootstrap CA so
+	// retry
+	retry.Run(t, func(r *retry.R) {
+		report, _, err := c.Connect().CASetConfigDryRun(conf, nil)
+		r.Check(err)
+		require.True(r, report.RootRotation)
+		require.Equal(r, "cross-signed", report.CrossSigning)
+		require.NotNil(r, report.NewRoot)
+		require.Equal(r, "rsa", report.NewRoot.PrivateKeyType)
+	})
+
+	roots, _, err := c.Connect().CARoots(nil)
+	require.NoError(t, err)
+	require.Len(t, roots.Roots, 1)
+}
+
+func TestAPI_ConnectCARevoke(t *testing.T) {
+	t.Parallel()
+
+	c, s := makeClient(t)
+	defer s.Stop()
+
+	s.WaitForActiveCARoot(t)
+	connect := c.Connect()
+
+	_, _, err := connect.CARevoke(&CARevocation{Reason: "compromised"}, nil)
+	require.ErrorContains(t, err, "either a SerialNumber or a Service")
+
+	rev, _, err := connect.CARevoke(&CARevocation{SerialNumber: "0A1B", Reason: "compromised"}, nil)
+	require.NoError(t, err)
+	require.NotEmpty(t, rev.ID)
+	require.Equal(t, "0a:1b", rev.SerialNumber)
+
+	list, _, err := connect.CARoots(nil)
+	require.NoError(t, err)
+	require.Len(t, list.Revocations, 1)
+	require.Equal(t, rev.ID, list.Revocations[0].ID)
+
+	_, err = connect.CARevocationDelete(rev.ID, nil)
+	require.NoError(t, err)
+
+	list, _, err = connect.CARoots(nil)
+	require.NoError(t, err)
+	require.Empty(t, list.Revocations)
+}
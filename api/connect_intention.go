@@ -47,6 +47,10 @@ type Intention struct {
 	// is not compatible with legacy intentions.
 	SourceSamenessGroup string `json:",omitempty"`
 
+	// SourceTrustDomain is the federated SPIFFE trust domain of the source.
+	// It cannot be a wildcard "*" and is not compatible with legacy intentions.
+	SourceTrustDomain string `json:",omitempty"`
+
 	// SourceType is the type of the value for the source.
 	SourceType IntentionSourceType
 
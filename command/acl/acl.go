@@ -50,6 +50,11 @@ Usage: consul acl <subcommand> [options] [args]
                                  -datacenter "dc2" \
                                  -rules @rules.hcl
 
+  Export the ACL configuration and apply it to another cluster:
+
+      $ consul acl export > acl.json
+      $ consul acl apply -f acl.json -http-addr=https://other-cluster:8501
+
   Set the default agent token:
 
       $ consul acl set-agent-token default 0bc6bc46-f25e-4262-b2d9-ffbe1d96be6f
@@ -0,0 +1,986 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package acl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hernad/consul/agent/structs"
+	"github.com/hernad/consul/api"
+)
+
+// Config is the portable form of the ACL configuration used by the
+// "consul acl export" and "consul acl apply" commands. Objects are identified
+// by name instead of ID so a configuration can be applied to any cluster, and
+// tokens are deliberately not part of it.
+type Config struct {
+	AuthMethods     []*ConfigAuthMethod     `json:",omitempty"`
+	Policies        []*ConfigPolicy         `json:",omitempty"`
+	PolicyTemplates []*ConfigPolicyTemplate `json:",omitempty"`
+	Roles           []*ConfigRole           `json:",omitempty"`
+	BindingRules    []*ConfigBindingRule    `json:",omitempty"`
+}
+
+type ConfigAuthMethod struct {
+	Name           string
+	Type           string
+	DisplayName    string                            `json:",omitempty"`
+	Description    string                            `json:",omitempty"`
+	MaxTokenTTL    string                            `json:",omitempty"`
+	TokenLocality  string                            `json:",omitempty"`
+	Config         map[string]interface{}            `json:",omitempty"`
+	NamespaceRules []*api.ACLAuthMethodNamespaceRule `json:",omitempty"`
+}
+
+type ConfigPolicy struct {
+	Name        string
+	Description string   `json:",omitempty"`
+	Rules       string   `json:",omitempty"`
+	Datacenters []string `json:",omitempty"`
+}
+
+type ConfigPolicyTemplate struct {
+	Name        string
+	Description string   `json:",omitempty"`
+	Rules       string   `json:",omitempty"`
+	Datacenters []string `json:",omitempty"`
+}
+
+type ConfigRole struct {
+	Name        string
+	Description string `json:",omitempty"`
+	// Policies holds the names of the policies linked to the role.
+	Policies          []string                  `json:",omitempty"`
+	ServiceIdentities []*api.ACLServiceIdentity `json:",omitempty"`
+	NodeIdentities    []*api.ACLNodeIdentity    `json:",omitempty"`
+	TemplatedPolicies []*ConfigTemplatedPolicy  `json:",omitempty"`
+}
+
+type ConfigTemplatedPolicy struct {
+	TemplateName string
+	Variables    map[string]string `json:",omitempty"`
+	Datacenters  []string          `json:",omitempty"`
+}
+
+// ConfigBindingRule is a binding rule. Binding rules have no name, so they
+// are identified by their auth method, bind type, bind name and selector.
+type ConfigBindingRule struct {
+	AuthMethod  string
+	Description string `json:",omitempty"`
+	Selector    string `json:",omitempty"`
+	BindType    api.BindingRuleBindType
+	BindName    string
+	BindVars    map[string]string `json:",omitempty"`
+}
+
+func (r *ConfigBindingRule) key() string {
+	return strings.Join([]string{r.AuthMethod, string(r.BindType), r.BindName, r.Selector}, "\x00")
+}
+
+func (r *ConfigBindingRule) displayName() string {
+	name := fmt.Sprintf("%s/%s/%s", r.AuthMethod, r.BindType, r.BindName)
+	if r.Selector != "" {
+		name += fmt.Sprintf(" (%s)", r.Selector)
+	}
+	return name
+}
+
+// LoadConfig reads an ACL configuration from the given file, or from all of
+// the JSON files in the given directory.
+func LoadConfig(path string) (*Config, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		files, err = filepath.Glob(filepath.Join(path, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("No JSON files found in %q", path)
+		}
+		sort.Strings(files)
+	}
+
+	config := &Config{}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		var part Config
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&part); err != nil {
+			return nil, fmt.Errorf("Failed to decode %q: %v", file, err)
+		}
+
+		config.AuthMethods = append(config.AuthMethods, part.AuthMethods...)
+		config.Policies = append(config.Policies, part.Policies...)
+		config.PolicyTemplates = append(config.PolicyTemplates, part.PolicyTemplates...)
+		config.Roles = append(config.Roles, part.Roles...)
+		config.BindingRules = append(config.BindingRules, part.BindingRules...)
+	}
+
+	if err := config.normalize(); err != nil {
+		return nil, err
+	}
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// normalize puts the configuration in a canonical form so that equivalent
+// configurations compare equal.
+func (c *Config) normalize() error {
+	for _, method := range c.AuthMethods {
+		if method.MaxTokenTTL != "" {
+			ttl, err := time.ParseDuration(method.MaxTokenTTL)
+			if err != nil {
+				return fmt.Errorf("Invalid MaxTokenTTL for auth method %q: %v", method.Name, err)
+			}
+			method.MaxTokenTTL = formatTTL(ttl)
+		}
+	}
+	sort.Slice(c.AuthMethods, func(i, j int) bool {
+		return c.AuthMethods[i].Name < c.AuthMethods[j].Name
+	})
+
+	for _, policy := range c.Policies {
+		sort.Strings(policy.Datacenters)
+	}
+	sort.Slice(c.Policies, func(i, j int) bool {
+		return c.Policies[i].Name < c.Policies[j].Name
+	})
+
+	for _, template := range c.PolicyTemplates {
+		sort.Strings(template.Datacenters)
+	}
+	sort.Slice(c.PolicyTemplates, func(i, j int) bool {
+		return c.PolicyTemplates[i].Name < c.PolicyTemplates[j].Name
+	})
+
+	for _, role := range c.Roles {
+		sort.Strings(role.Policies)
+		sort.Slice(role.ServiceIdentities, func(i, j int) bool {
+			return role.ServiceIdentities[i].ServiceName < role.ServiceIdentities[j].ServiceName
+		})
+		sort.Slice(role.NodeIdentities, func(i, j int) bool {
+			a, b := role.NodeIdentities[i], role.NodeIdentities[j]
+			if a.NodeName != b.NodeName {
+				return a.NodeName < b.NodeName
+			}
+			return a.Datacenter < b.Datacenter
+		})
+		sort.SliceStable(role.TemplatedPolicies, func(i, j int) bool {
+			return role.TemplatedPolicies[i].TemplateName < role.TemplatedPolicies[j].TemplateName
+		})
+	}
+	sort.Slice(c.Roles, func(i, j int) bool {
+		return c.Roles[i].Name < c.Roles[j].Name
+	})
+
+	sort.Slice(c.BindingRules, func(i, j int) bool {
+		return c.BindingRules[i].key() < c.BindingRules[j].key()
+	})
+	return nil
+}
+
+func (c *Config) validate() error {
+	methods := make(map[string]struct{})
+	for _, method := range c.AuthMethods {
+		if method.Name == "" {
+			return fmt.Errorf("Auth methods must have a name")
+		}
+		if _, ok := methods[method.Name]; ok {
+			return fmt.Errorf("Auth method %q is defined more than once", method.Name)
+		}
+		methods[method.Name] = struct{}{}
+	}
+
+	policies := make(map[string]struct{})
+	for _, policy := range structs.ACLBuiltinPolicies {
+		policies[policy.Name] = struct{}{}
+	}
+	for _, policy := range c.Policies {
+		if policy.Name == "" {
+			return fmt.Errorf("Policies must have a name")
+		}
+		if _, ok := policies[policy.Name]; ok {
+			return fmt.Errorf("Policy %q is defined more than once or is a builtin policy", policy.Name)
+		}
+		policies[policy.Name] = struct{}{}
+	}
+
+	templates := make(map[string]struct{})
+	for _, template := range c.PolicyTemplates {
+		if template.Name == "" {
+			return fmt.Errorf("Policy templates must have a name")
+		}
+		if _, ok := templates[template.Name]; ok {
+			return fmt.Errorf("Policy template %q is defined more than once", template.Name)
+		}
+		templates[template.Name] = struct{}{}
+	}
+
+	roles := make(map[string]struct{})
+	for _, role := range c.Roles {
+		if role.Name == "" {
+			return fmt.Errorf("Roles must have a name")
+		}
+		if _, ok := roles[role.Name]; ok {
+			return fmt.Errorf("Role %q is defined more than once", role.Name)
+		}
+		roles[role.Name] = struct{}{}
+
+		for _, name := range role.Policies {
+			if _, ok := policies[name]; !ok {
+				return fmt.Errorf("Role %q links to policy %q which is not defined", role.Name, name)
+			}
+		}
+		for _, link := range role.TemplatedPolicies {
+			if _, ok := templates[link.TemplateName]; !ok {
+				return fmt.Errorf("Role %q links to policy template %q which is not defined", role.Name, link.TemplateName)
+			}
+		}
+	}
+
+	rules := make(map[string]struct{})
+	for _, rule := range c.BindingRules {
+		if _, ok := methods[rule.AuthMethod]; !ok {
+			return fmt.Errorf("Binding rule %q uses auth method %q which is not defined", rule.displayName(), rule.AuthMethod)
+		}
+		if _, ok := rules[rule.key()]; ok {
+			return fmt.Errorf("Binding rule %q is defined more than once", rule.displayName())
+		}
+		rules[rule.key()] = struct{}{}
+	}
+	return nil
+}
+
+func formatTTL(ttl time.Duration) string {
+	if ttl <= 0 {
+		return ""
+	}
+	return ttl.String()
+}
+
+// clusterConfig is the ACL configuration read from a cluster along with the
+// IDs needed to update or delete its objects.
+type clusterConfig struct {
+	config         *Config
+	policyIDs      map[string]string
+	templateIDs    map[string]string
+	roleIDs        map[string]string
+	bindingRuleIDs []string
+}
+
+// ExportConfig reads the ACL configuration of the cluster. Builtin policies are
+// left out since they cannot be modified. The credentials held in the config
+// of the auth methods are replaced by RedactedSecret unless includeSecrets is
+// set.
+func ExportConfig(client *api.Client, includeSecrets bool) (*Config, error) {
+	current, err := readClusterConfig(client)
+	if err != nil {
+		return nil, err
+	}
+	if !includeSecrets {
+		for i, method := range current.config.AuthMethods {
+			current.config.AuthMethods[i] = method.redactSecrets(nil)
+		}
+	}
+	return current.config, nil
+}
+
+func readClusterConfig(client *api.Client) (*clusterConfig, error) {
+	acl := client.ACL()
+	current := &clusterConfig{
+		config:      &Config{},
+		policyIDs:   make(map[string]string),
+		templateIDs: make(map[string]string),
+		roleIDs:     make(map[string]string),
+	}
+
+	methods, _, err := acl.AuthMethodList(nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to retrieve the auth method list: %v", err)
+	}
+	for _, entry := range methods {
+		method, _, err := acl.AuthMethodRead(entry.Name, nil)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read auth method %q: %v", entry.Name, err)
+		}
+		if method == nil {
+			continue
+		}
+		current.config.AuthMethods = append(current.config.AuthMethods, &ConfigAuthMethod{
+			Name:           method.Name,
+			Type:           method.Type,
+			DisplayName:    method.DisplayName,
+			Description:    method.Description,
+			MaxTokenTTL:    formatTTL(method.MaxTokenTTL),
+			TokenLocality:  method.TokenLocality,
+			Config:         method.Config,
+			NamespaceRules: method.NamespaceRules,
+		})
+	}
+
+	policies, _, err := acl.PolicyList(nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to retrieve the policy list: %v", err)
+	}
+	for _, entry := range policies {
+		if _, ok := structs.ACLBuiltinPolicies[entry.ID]; ok {
+			continue
+		}
+		policy, _, err := acl.PolicyRead(entry.ID, nil)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read policy %q: %v", entry.Name, err)
+		}
+		if policy == nil {
+			continue
+		}
+		current.policyIDs[policy.Name] = policy.ID
+		current.config.Policies = append(current.config.Policies, &ConfigPolicy{
+			Name:        policy.Name,
+			Description: policy.Description,
+			Rules:       policy.Rules,
+			Datacenters: policy.Datacenters,
+		})
+	}
+
+	templates, _, err := acl.PolicyTemplateList(nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to retrieve the policy template list: %v", err)
+	}
+	templateNames := make(map[string]string)
+	for _, template := range templates {
+		templateNames[template.ID] = template.Name
+		current.templateIDs[template.Name] = template.ID
+		current.config.PolicyTemplates = append(current.config.PolicyTemplates, &ConfigPolicyTemplate{
+			Name:        template.Name,
+			Description: template.Description,
+			Rules:       template.Rules,
+			Datacenters: template.Datacenters,
+		})
+	}
+
+	roles, _, err := acl.RoleList(nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to retrieve the role list: %v", err)
+	}
+	for _, role := range roles {
+		current.roleIDs[role.Name] = role.ID
+
+		out := &ConfigRole{
+			Name:              role.Name,
+			Description:       role.Description,
+			ServiceIdentities: role.ServiceIdentities,
+			NodeIdentities:    role.NodeIdentities,
+		}
+		for _, link := range role.Policies {
+			out.Policies = append(out.Policies, link.Name)
+		}
+		for _, link := range role.TemplatedPolicies {
+			name := link.TemplateName
+			if name == "" {
+				name = templateNames[link.TemplateID]
+			}
+			out.TemplatedPolicies = append(out.TemplatedPolicies, &ConfigTemplatedPolicy{
+				TemplateName: name,
+				Variables:    link.Variables,
+				Datacenters:  link.Datacenters,
+			})
+		}
+		current.config.Roles = append(current.config.Roles, out)
+	}
+
+	rules, _, err := acl.BindingRuleList("", nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to retrieve the binding rule list: %v", err)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].ID < rules[j].ID
+	})
+	for _, rule := range rules {
+		current.config.BindingRules = append(current.config.BindingRules, &ConfigBindingRule{
+			AuthMethod:  rule.AuthMethod,
+			Description: rule.Description,
+			Selector:    rule.Selector,
+			BindType:    rule.BindType,
+			BindName:    rule.BindName,
+			BindVars:    rule.BindVars,
+		})
+		current.bindingRuleIDs = append(current.bindingRuleIDs, rule.ID)
+	}
+
+	// The binding rule IDs follow the rules through the sort.
+	byRule := make(map[*ConfigBindingRule]string, len(rules))
+	for i, rule := range current.config.BindingRules {
+		byRule[rule] = current.bindingRuleIDs[i]
+	}
+	if err := current.config.normalize(); err != nil {
+		return nil, err
+	}
+	for i, rule := range current.config.BindingRules {
+		current.bindingRuleIDs[i] = byRule[rule]
+	}
+
+	return current, nil
+}
+
+type ConfigChangeOp string
+
+const (
+	ConfigChangeCreate ConfigChangeOp = "create"
+	ConfigChangeUpdate ConfigChangeOp = "update"
+	ConfigChangeDelete ConfigChangeOp = "delete"
+)
+
+// ConfigChange is a single change needed to bring the cluster in line with
+// the desired ACL configuration.
+type ConfigChange struct {
+	Op   ConfigChangeOp
+	Kind string
+	Name string
+	// Diff holds the lines of the object that are added or removed by an
+	// update.
+	Diff []string
+
+	apply func(acl *api.ACL) error
+}
+
+func (c *ConfigChange) String() string {
+	var prefix string
+	switch c.Op {
+	case ConfigChangeCreate:
+		prefix = "+"
+	case ConfigChangeUpdate:
+		prefix = "~"
+	case ConfigChangeDelete:
+		prefix = "-"
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%s %s %q", prefix, c.Kind, c.Name)
+	for _, line := range c.Diff {
+		buf.WriteString("\n    ")
+		buf.WriteString(line)
+	}
+	return buf.String()
+}
+
+// ConfigPlan is the ordered list of changes that apply a configuration.
+type ConfigPlan struct {
+	Changes []*ConfigChange
+}
+
+func (p *ConfigPlan) String() string {
+	lines := make([]string, 0, len(p.Changes))
+	for _, change := range p.Changes {
+		lines = append(lines, change.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Deletes returns the number of objects deleted by the plan.
+func (p *ConfigPlan) Deletes() int {
+	var n int
+	for _, change := range p.Changes {
+		if change.Op == ConfigChangeDelete {
+			n++
+		}
+	}
+	return n
+}
+
+// Apply makes the changes of the plan in order, stopping at the first error.
+func (p *ConfigPlan) Apply(client *api.Client) error {
+	acl := client.ACL()
+	for _, change := range p.Changes {
+		if err := change.apply(acl); err != nil {
+			return fmt.Errorf("Failed to %s %s %q: %v", change.Op, change.Kind, change.Name, err)
+		}
+	}
+	return nil
+}
+
+// PlanConfig computes the changes needed to make the ACL configuration of the
+// cluster match the desired one. Objects that are missing from the desired
+// configuration are deleted. Objects are created and updated before the
+// deletions, in dependency order, so that every intermediate state is valid.
+func PlanConfig(client *api.Client, desired *Config) (*ConfigPlan, error) {
+	current, err := readClusterConfig(client)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &ConfigPlan{}
+	var deletes []*ConfigChange
+
+	// Auth methods
+	currentMethods := make(map[string]*ConfigAuthMethod)
+	for _, method := range current.config.AuthMethods {
+		currentMethods[method.Name] = method
+	}
+	for _, method := range desired.AuthMethods {
+		method := method
+		existing, ok := currentMethods[method.Name]
+		delete(currentMethods, method.Name)
+		method, err := method.restoreSecrets(existing)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			// Plans are printed, so secrets are compared without showing
+			// them.
+			diff, err := configDiff(existing.redactSecrets(nil), method.redactSecrets(existing))
+			if err != nil {
+				return nil, err
+			}
+			if len(diff) == 0 {
+				continue
+			}
+			plan.Changes = append(plan.Changes, &ConfigChange{
+				Op: ConfigChangeUpdate, Kind: "auth-method", Name: method.Name, Diff: diff,
+				apply: func(acl *api.ACL) error {
+					_, _, err := acl.AuthMethodUpdate(method.toAPI(), nil)
+					return err
+				},
+			})
+			continue
+		}
+		plan.Changes = append(plan.Changes, &ConfigChange{
+			Op: ConfigChangeCreate, Kind: "auth-method", Name: method.Name,
+			apply: func(acl *api.ACL) error {
+				_, _, err := acl.AuthMethodCreate(method.toAPI(), nil)
+				return err
+			},
+		})
+	}
+	var methodDeletes []*ConfigChange
+	for _, method := range current.config.AuthMethods {
+		name := method.Name
+		if _, ok := currentMethods[name]; !ok {
+			continue
+		}
+		methodDeletes = append(methodDeletes, &ConfigChange{
+			Op: ConfigChangeDelete, Kind: "auth-method", Name: name,
+			apply: func(acl *api.ACL) error {
+				_, err := acl.AuthMethodDelete(name, nil)
+				return err
+			},
+		})
+	}
+
+	// Policies
+	currentPolicies := make(map[string]*ConfigPolicy)
+	for _, policy := range current.config.Policies {
+		currentPolicies[policy.Name] = policy
+	}
+	for _, policy := range desired.Policies {
+		policy := policy
+		existing, ok := currentPolicies[policy.Name]
+		delete(currentPolicies, policy.Name)
+		if ok {
+			diff, err := configDiff(existing, policy)
+			if err != nil {
+				return nil, err
+			}
+			if len(diff) == 0 {
+				continue
+			}
+			id := current.policyIDs[policy.Name]
+			plan.Changes = append(plan.Changes, &ConfigChange{
+				Op: ConfigChangeUpdate, Kind: "policy", Name: policy.Name, Diff: diff,
+				apply: func(acl *api.ACL) error {
+					_, _, err := acl.PolicyUpdate(policy.toAPI(id), nil)
+					return err
+				},
+			})
+			continue
+		}
+		plan.Changes = append(plan.Changes, &ConfigChange{
+			Op: ConfigChangeCreate, Kind: "policy", Name: policy.Name,
+			apply: func(acl *api.ACL) error {
+				_, _, err := acl.PolicyCreate(policy.toAPI(""), nil)
+				return err
+			},
+		})
+	}
+	var policyDeletes []*ConfigChange
+	for _, policy := range current.config.Policies {
+		if _, ok := currentPolicies[policy.Name]; !ok {
+			continue
+		}
+		id := current.policyIDs[policy.Name]
+		policyDeletes = append(policyDeletes, &ConfigChange{
+			Op: ConfigChangeDelete, Kind: "policy", Name: policy.Name,
+			apply: func(acl *api.ACL) error {
+				_, err := acl.PolicyDelete(id, nil)
+				return err
+			},
+		})
+	}
+
+	// Policy templates
+	currentTemplates := make(map[string]*ConfigPolicyTemplate)
+	for _, template := range current.config.PolicyTemplates {
+		currentTemplates[template.Name] = template
+	}
+	for _, template := range desired.PolicyTemplates {
+		template := template
+		existing, ok := currentTemplates[template.Name]
+		delete(currentTemplates, template.Name)
+		if ok {
+			diff, err := configDiff(existing, template)
+			if err != nil {
+				return nil, err
+			}
+			if len(diff) == 0 {
+				continue
+			}
+			id := current.templateIDs[template.Name]
+			plan.Changes = append(plan.Changes, &ConfigChange{
+				Op: ConfigChangeUpdate, Kind: "policy-template", Name: template.Name, Diff: diff,
+				apply: func(acl *api.ACL) error {
+					_, _, err := acl.PolicyTemplateUpdate(template.toAPI(id), nil)
+					return err
+				},
+			})
+			continue
+		}
+		plan.Changes = append(plan.Changes, &ConfigChange{
+			Op: ConfigChangeCreate, Kind: "policy-template", Name: template.Name,
+			apply: func(acl *api.ACL) error {
+				_, _, err := acl.PolicyTemplateCreate(template.toAPI(""), nil)
+				return err
+			},
+		})
+	}
+	var templateDeletes []*ConfigChange
+	for _, template := range current.config.PolicyTemplates {
+		if _, ok := currentTemplates[template.Name]; !ok {
+			continue
+		}
+		id := current.templateIDs[template.Name]
+		templateDeletes = append(templateDeletes, &ConfigChange{
+			Op: ConfigChangeDelete, Kind: "policy-template", Name: template.Name,
+			apply: func(acl *api.ACL) error {
+				_, err := acl.PolicyTemplateDelete(id, nil)
+				return err
+			},
+		})
+	}
+
+	// Roles
+	currentRoles := make(map[string]*ConfigRole)
+	for _, role := range current.config.Roles {
+		currentRoles[role.Name] = role
+	}
+	for _, role := range desired.Roles {
+		role := role
+		existing, ok := currentRoles[role.Name]
+		delete(currentRoles, role.Name)
+		if ok {
+			diff, err := configDiff(existing, role)
+			if err != nil {
+				return nil, err
+			}
+			if len(diff) == 0 {
+				continue
+			}
+			id := current.roleIDs[role.Name]
+			plan.Changes = append(plan.Changes, &ConfigChange{
+				Op: ConfigChangeUpdate, Kind: "role", Name: role.Name, Diff: diff,
+				apply: func(acl *api.ACL) error {
+					_, _, err := acl.RoleUpdate(role.toAPI(id), nil)
+					return err
+				},
+			})
+			continue
+		}
+		plan.Changes = append(plan.Changes, &ConfigChange{
+			Op: ConfigChangeCreate, Kind: "role", Name: role.Name,
+			apply: func(acl *api.ACL) error {
+				_, _, err := acl.RoleCreate(role.toAPI(""), nil)
+				return err
+			},
+		})
+	}
+	var roleDeletes []*ConfigChange
+	for _, role := range current.config.Roles {
+		if _, ok := currentRoles[role.Name]; !ok {
+			continue
+		}
+		id := current.roleIDs[role.Name]
+		roleDeletes = append(roleDeletes, &ConfigChange{
+			Op: ConfigChangeDelete, Kind: "role", Name: role.Name,
+			apply: func(acl *api.ACL) error {
+				_, err := acl.RoleDelete(id, nil)
+				return err
+			},
+		})
+	}
+
+	// Binding rules. Duplicates of a rule in the cluster are deleted.
+	currentRules := make(map[string]int)
+	for i, rule := range current.config.BindingRules {
+		if _, ok := currentRules[rule.key()]; !ok {
+			currentRules[rule.key()] = i
+		}
+	}
+	matchedRules := make(map[int]struct{})
+	for _, rule := range desired.BindingRules {
+		rule := rule
+		i, ok := currentRules[rule.key()]
+		if ok {
+			matchedRules[i] = struct{}{}
+			diff, err := configDiff(current.config.BindingRules[i], rule)
+			if err != nil {
+				return nil, err
+			}
+			if len(diff) == 0 {
+				continue
+			}
+			id := current.bindingRuleIDs[i]
+			plan.Changes = append(plan.Changes, &ConfigChange{
+				Op: ConfigChangeUpdate, Kind: "binding-rule", Name: rule.displayName(), Diff: diff,
+				apply: func(acl *api.ACL) error {
+					_, _, err := acl.BindingRuleUpdate(rule.toAPI(id), nil)
+					return err
+				},
+			})
+			continue
+		}
+		plan.Changes = append(plan.Changes, &ConfigChange{
+			Op: ConfigChangeCreate, Kind: "binding-rule", Name: rule.displayName(),
+			apply: func(acl *api.ACL) error {
+				_, _, err := acl.BindingRuleCreate(rule.toAPI(""), nil)
+				return err
+			},
+		})
+	}
+	for i, rule := range current.config.BindingRules {
+		if _, ok := matchedRules[i]; ok {
+			continue
+		}
+		id := current.bindingRuleIDs[i]
+		deletes = append(deletes, &ConfigChange{
+			Op: ConfigChangeDelete, Kind: "binding-rule", Name: rule.displayName(),
+			apply: func(acl *api.ACL) error {
+				_, err := acl.BindingRuleDelete(id, nil)
+				return err
+			},
+		})
+	}
+
+	deletes = append(deletes, roleDeletes...)
+	deletes = append(deletes, templateDeletes...)
+	deletes = append(deletes, policyDeletes...)
+	deletes = append(deletes, methodDeletes...)
+	plan.Changes = append(plan.Changes, deletes...)
+	return plan, nil
+}
+
+// RedactedSecret replaces the credentials held in the config of the auth
+// methods of exported configurations.
+const RedactedSecret = "[redacted]"
+
+// authMethodSecretFields lists the config fields holding credentials, by auth
+// method type.
+var authMethodSecretFields = map[string][]string{
+	"kubernetes": {"ServiceAccountJWT"},
+	"oidc":       {"OIDCClientSecret"},
+}
+
+// redactSecrets returns the auth method with the secret fields of its config
+// replaced by RedactedSecret. When previous is given, the secrets that differ
+// from its own are marked as changed.
+func (m *ConfigAuthMethod) redactSecrets(previous *ConfigAuthMethod) *ConfigAuthMethod {
+	redacted := *m
+	redacted.Config = copyConfig(m.Config)
+	for _, field := range authMethodSecretFields[m.Type] {
+		v, ok := m.Config[field]
+		if !ok || v == "" {
+			continue
+		}
+		redacted.Config[field] = RedactedSecret
+		if previous != nil && !reflect.DeepEqual(previous.Config[field], v) {
+			redacted.Config[field] = RedactedSecret + " (changed)"
+		}
+	}
+	return &redacted
+}
+
+// restoreSecrets returns the auth method with the secret fields that were
+// redacted on export taken from current, the same auth method in the cluster.
+func (m *ConfigAuthMethod) restoreSecrets(current *ConfigAuthMethod) (*ConfigAuthMethod, error) {
+	restored := *m
+	restored.Config = copyConfig(m.Config)
+	for _, field := range authMethodSecretFields[m.Type] {
+		if m.Config[field] != RedactedSecret {
+			continue
+		}
+		if current == nil || current.Type != m.Type || current.Config[field] == nil {
+			return nil, fmt.Errorf("Auth method %q has a redacted %s, set it or export the configuration with -include-secrets", m.Name, field)
+		}
+		restored.Config[field] = current.Config[field]
+	}
+	return &restored, nil
+}
+
+func copyConfig(config map[string]interface{}) map[string]interface{} {
+	if config == nil {
+		return nil
+	}
+	c := make(map[string]interface{}, len(config))
+	for k, v := range config {
+		c[k] = v
+	}
+	return c
+}
+
+func (m *ConfigAuthMethod) toAPI() *api.ACLAuthMethod {
+	// The TTL was validated when the configuration was loaded, and an empty
+	// TTL parses to zero.
+	ttl, _ := time.ParseDuration(m.MaxTokenTTL)
+	return &api.ACLAuthMethod{
+		Name:           m.Name,
+		Type:           m.Type,
+		DisplayName:    m.DisplayName,
+		Description:    m.Description,
+		MaxTokenTTL:    ttl,
+		TokenLocality:  m.TokenLocality,
+		Config:         m.Config,
+		NamespaceRules: m.NamespaceRules,
+	}
+}
+
+func (p *ConfigPolicy) toAPI(id string) *api.ACLPolicy {
+	return &api.ACLPolicy{
+		ID:          id,
+		Name:        p.Name,
+		Description: p.Description,
+		Rules:       p.Rules,
+		Datacenters: p.Datacenters,
+	}
+}
+
+func (t *ConfigPolicyTemplate) toAPI(id string) *api.ACLPolicyTemplate {
+	return &api.ACLPolicyTemplate{
+		ID:          id,
+		Name:        t.Name,
+		Description: t.Description,
+		Rules:       t.Rules,
+		Datacenters: t.Datacenters,
+	}
+}
+
+func (r *ConfigRole) toAPI(id string) *api.ACLRole {
+	role := &api.ACLRole{
+		ID:                id,
+		Name:              r.Name,
+		Description:       r.Description,
+		ServiceIdentities: r.ServiceIdentities,
+		NodeIdentities:    r.NodeIdentities,
+	}
+	for _, name := range r.Policies {
+		role.Policies = append(role.Policies, &api.ACLRolePolicyLink{Name: name})
+	}
+	for _, link := range r.TemplatedPolicies {
+		role.TemplatedPolicies = append(role.TemplatedPolicies, &api.ACLTemplatedPolicy{
+			TemplateName: link.TemplateName,
+			Variables:    link.Variables,
+			Datacenters:  link.Datacenters,
+		})
+	}
+	return role
+}
+
+func (r *ConfigBindingRule) toAPI(id string) *api.ACLBindingRule {
+	return &api.ACLBindingRule{
+		ID:          id,
+		AuthMethod:  r.AuthMethod,
+		Description: r.Description,
+		Selector:    r.Selector,
+		BindType:    r.BindType,
+		BindName:    r.BindName,
+		BindVars:    r.BindVars,
+	}
+}
+
+// configDiff returns the lines of the JSON encoding of current that are
+// removed, prefixed with "-", and the lines of desired that are added,
+// prefixed with "+". It returns nothing when both are equivalent.
+func configDiff(current, desired interface{}) ([]string, error) {
+	a, err := json.MarshalIndent(current, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	b, err := json.MarshalIndent(desired, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(a, b) {
+		return nil, nil
+	}
+	return diffLines(strings.Split(string(a), "\n"), strings.Split(string(b), "\n")), nil
+}
+
+// diffLines computes a line diff of a and b from their longest common
+// subsequence and returns only the lines that differ.
+func diffLines(a, b []string) []string {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var out []string
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			out = append(out, "- "+strings.TrimSpace(a[i]))
+			i++
+		default:
+			out = append(out, "+ "+strings.TrimSpace(b[j]))
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		out = append(out, "- "+strings.TrimSpace(a[i]))
+	}
+	for ; j < len(b); j++ {
+		out = append(out, "+ "+strings.TrimSpace(b[j]))
+	}
+	return out
+}
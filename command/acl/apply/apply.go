@@ -0,0 +1,146 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package apply
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/hernad/consul/command/acl"
+	"github.com/hernad/consul/command/flags"
+	"github.com/mitchellh/cli"
+)
+
+func New(ui cli.Ui) *cmd {
+	c := &cmd{UI: ui}
+	c.init()
+	return c
+}
+
+type cmd struct {
+	UI    cli.Ui
+	flags *flag.FlagSet
+	http  *flags.HTTPFlags
+	help  string
+
+	file        string
+	dryRun      bool
+	autoApprove bool
+}
+
+func (c *cmd) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.StringVar(&c.file, "f", "", "The file, or directory of JSON files, "+
+		"holding the ACL configuration to apply.")
+	c.flags.BoolVar(&c.dryRun, "dry-run", false, "Print the changes that would be "+
+		"made without applying them.")
+	c.flags.BoolVar(&c.autoApprove, "auto-approve", false, "Apply deletions "+
+		"without asking for confirmation.")
+
+	c.http = &flags.HTTPFlags{}
+	flags.Merge(c.flags, c.http.ClientFlags())
+	flags.Merge(c.flags, c.http.ServerFlags())
+	flags.Merge(c.flags, c.http.MultiTenancyFlags())
+	c.help = flags.Usage(help, c.flags)
+}
+
+func (c *cmd) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	if c.file == "" {
+		c.UI.Error("Missing required '-f' flag")
+		c.UI.Error(c.Help())
+		return 1
+	}
+
+	config, err := acl.LoadConfig(c.file)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Failed to load the ACL configuration: %v", err))
+		return 1
+	}
+
+	client, err := c.http.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error connecting to Consul agent: %s", err))
+		return 1
+	}
+
+	plan, err := acl.PlanConfig(client, config)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	if len(plan.Changes) == 0 {
+		c.UI.Info("No changes to apply")
+		return 0
+	}
+	c.UI.Output(plan.String())
+
+	if c.dryRun {
+		c.UI.Info(fmt.Sprintf("\n%d change(s) would be applied", len(plan.Changes)))
+		return 0
+	}
+
+	if deletes := plan.Deletes(); deletes > 0 && !c.autoApprove {
+		answer, err := c.UI.Ask(fmt.Sprintf("\n%d object(s) will be deleted. Only 'yes' will be accepted to apply the changes:", deletes))
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Failed to read the confirmation, use -auto-approve to apply deletions non-interactively: %v", err))
+			return 1
+		}
+		if answer != "yes" {
+			c.UI.Error("Apply cancelled, no changes were made")
+			return 1
+		}
+	}
+
+	if err := plan.Apply(client); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+	c.UI.Info(fmt.Sprintf("\nApplied %d change(s)", len(plan.Changes)))
+	return 0
+}
+
+func (c *cmd) Synopsis() string {
+	return synopsis
+}
+
+func (c *cmd) Help() string {
+	return flags.Usage(c.help, nil)
+}
+
+const (
+	synopsis = "Apply an ACL configuration"
+	help     = `
+Usage: consul acl apply -f <file or directory> [options]
+
+    Makes the auth methods, policies, policy templates, roles and binding
+    rules match the given configuration, as written by "consul acl export".
+    Objects are created or updated as needed, and objects that are not part
+    of the configuration are deleted. The changes are printed before they are
+    applied, and applying the same configuration again makes no changes.
+
+    Builtin policies are never modified, and tokens are not part of the
+    configuration. Deletions still affect tokens though: deleting an auth
+    method deletes the tokens created by logging in with it, and deleting a
+    policy, policy template or role removes it from the tokens linking it.
+    Deletions must be confirmed interactively, or with -auto-approve.
+    Review the changes with -dry-run first.
+
+    Preview the changes:
+
+        $ consul acl apply -f acl/ -dry-run
+
+    Apply the changes:
+
+        $ consul acl apply -f acl/
+
+    Apply the changes, including deletions, without confirmation:
+
+        $ consul acl apply -f acl/ -auto-approve
+`
+)
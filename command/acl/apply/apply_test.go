@@ -0,0 +1,248 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package apply
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hernad/consul/agent"
+	"github.com/hernad/consul/agent/connect"
+	"github.com/hernad/consul/api"
+	"github.com/hernad/consul/command/acl"
+	"github.com/hernad/consul/testrpc"
+
+	// activate testing auth method
+	_ "github.com/hernad/consul/agent/consul/authmethod/testauth"
+)
+
+func TestApplyCommand_noTabs(t *testing.T) {
+	t.Parallel()
+
+	if strings.ContainsRune(New(cli.NewMockUi()).Help(), '\t') {
+		t.Fatal("help has tabs")
+	}
+}
+
+func TestApplyCommand(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	a := agent.NewTestAgent(t, `
+	primary_datacenter = "dc1"
+	acl {
+		enabled = true
+		tokens {
+			initial_management = "root"
+		}
+	}`)
+
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	client := a.Client()
+
+	// This policy is not part of the configuration and is deleted.
+	_, _, err := client.ACL().PolicyCreate(
+		&api.ACLPolicy{Name: "stale", Rules: `node_prefix "" { policy = "read" }`},
+		&api.WriteOptions{Token: "root"},
+	)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	writeFile := func(t *testing.T, name, contents string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(contents), 0600))
+	}
+	writeFile(t, "auth.json", `{
+		"AuthMethods": [
+			{"Name": "test", "Type": "testing", "MaxTokenTTL": "60m", "Config": {"SessionID": "abc"}}
+		],
+		"BindingRules": [
+			{"AuthMethod": "test", "BindType": "role", "BindName": "web", "Selector": "serviceaccount.name==web"}
+		]
+	}`)
+	writeFile(t, "policies.json", `{
+		"Policies": [
+			{"Name": "web", "Description": "web", "Rules": "service \"web\" { policy = \"write\" }"}
+		],
+		"PolicyTemplates": [
+			{"Name": "kv-owner", "Rules": "key_prefix \"${app}/\" { policy = \"write\" }"}
+		],
+		"Roles": [
+			{
+				"Name": "web",
+				"Policies": ["web", "global-management"],
+				"TemplatedPolicies": [{"TemplateName": "kv-owner", "Variables": {"app": "web"}}]
+			}
+		]
+	}`)
+
+	runWithInput := func(t *testing.T, input string, args ...string) string {
+		ui := cli.NewMockUi()
+		ui.InputReader = strings.NewReader(input)
+		code := New(ui).Run(append([]string{
+			"-http-addr=" + a.HTTPAddr(),
+			"-token=root",
+			"-f=" + dir,
+		}, args...))
+		require.Equal(t, 0, code, ui.ErrorWriter.String())
+		require.Empty(t, ui.ErrorWriter.String())
+		return ui.OutputWriter.String()
+	}
+	run := func(t *testing.T, args ...string) string {
+		return runWithInput(t, "", args...)
+	}
+	runFails := func(t *testing.T, input string, args ...string) string {
+		ui := cli.NewMockUi()
+		ui.InputReader = strings.NewReader(input)
+		code := New(ui).Run(append([]string{
+			"-http-addr=" + a.HTTPAddr(),
+			"-token=root",
+			"-f=" + dir,
+		}, args...))
+		require.Equal(t, 1, code)
+		return ui.ErrorWriter.String()
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		out := run(t, "-dry-run")
+		require.Contains(t, out, `+ auth-method "test"`)
+		require.Contains(t, out, `- policy "stale"`)
+		require.Contains(t, out, "6 change(s) would be applied")
+
+		policy, _, err := client.ACL().PolicyReadByName("stale", &api.QueryOptions{Token: "root"})
+		require.NoError(t, err)
+		require.NotNil(t, policy)
+	})
+
+	t.Run("deletions need confirmation", func(t *testing.T) {
+		require.Contains(t, runFails(t, ""), "use -auto-approve")
+		require.Contains(t, runFails(t, "no\n"), "Apply cancelled")
+
+		policy, _, err := client.ACL().PolicyReadByName("stale", &api.QueryOptions{Token: "root"})
+		require.NoError(t, err)
+		require.NotNil(t, policy)
+		method, _, err := client.ACL().AuthMethodRead("test", &api.QueryOptions{Token: "root"})
+		require.NoError(t, err)
+		require.Nil(t, method)
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		out := runWithInput(t, "yes\n")
+		require.Equal(t, `+ auth-method "test"
++ policy "web"
++ policy-template "kv-owner"
++ role "web"
++ binding-rule "test/role/web (serviceaccount.name==web)"
+- policy "stale"
+
+1 object(s) will be deleted. Only 'yes' will be accepted to apply the changes:
+Applied 6 change(s)
+`, out)
+
+		policy, _, err := client.ACL().PolicyReadByName("stale", &api.QueryOptions{Token: "root"})
+		require.NoError(t, err)
+		require.Nil(t, policy)
+
+		role, _, err := client.ACL().RoleReadByName("web", &api.QueryOptions{Token: "root"})
+		require.NoError(t, err)
+		require.NotNil(t, role)
+		require.Len(t, role.Policies, 2)
+		require.Len(t, role.TemplatedPolicies, 1)
+	})
+
+	t.Run("idempotent", func(t *testing.T) {
+		require.Equal(t, "No changes to apply\n", run(t))
+	})
+
+	t.Run("update and delete", func(t *testing.T) {
+		writeFile(t, "policies.json", `{
+			"Policies": [
+				{"Name": "web", "Description": "web service", "Rules": "service \"web\" { policy = \"write\" }"}
+			]
+		}`)
+
+		out := run(t, "-auto-approve")
+		require.Equal(t, `~ policy "web"
+    - "Description": "web",
+    + "Description": "web service",
+- role "web"
+- policy-template "kv-owner"
+
+Applied 3 change(s)
+`, out)
+
+		require.Equal(t, "No changes to apply\n", run(t))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		writeFile(t, "roles.json", `{"Roles": [{"Name": "db", "Policies": ["db"]}]}`)
+		defer os.Remove(filepath.Join(dir, "roles.json"))
+
+		ui := cli.NewMockUi()
+		code := New(ui).Run([]string{
+			"-http-addr=" + a.HTTPAddr(),
+			"-token=root",
+			"-f=" + dir,
+		})
+		require.Equal(t, 1, code)
+		require.Contains(t, ui.ErrorWriter.String(), `Role "db" links to policy "db" which is not defined`)
+	})
+
+	t.Run("redacted secrets", func(t *testing.T) {
+		ca := connect.TestCA(t, nil)
+		method := map[string]interface{}{
+			"Name": "k8s",
+			"Type": "kubernetes",
+			"Config": map[string]interface{}{
+				"Host":              "https://foo.internal:8443",
+				"CACert":            ca.RootCert,
+				"ServiceAccountJWT": acl.RedactedSecret,
+			},
+		}
+		config, err := json.Marshal(map[string]interface{}{
+			"AuthMethods": []interface{}{method},
+		})
+		require.NoError(t, err)
+		writeFile(t, "k8s.json", string(config))
+
+		// A redacted secret can't be used to create an auth method.
+		require.Contains(t, runFails(t, ""), `Auth method "k8s" has a redacted ServiceAccountJWT`)
+
+		_, _, err = client.ACL().AuthMethodCreate(&api.ACLAuthMethod{
+			Name: "k8s",
+			Type: "kubernetes",
+			Config: map[string]interface{}{
+				"Host":              "https://foo.internal:8443",
+				"CACert":            ca.RootCert,
+				"ServiceAccountJWT": acl.TestKubernetesJWT_A,
+			},
+		}, &api.WriteOptions{Token: "root"})
+		require.NoError(t, err)
+
+		// The redacted secret keeps the current one.
+		require.Equal(t, "No changes to apply\n", run(t))
+
+		// A changed secret is shown as such.
+		method["Config"].(map[string]interface{})["ServiceAccountJWT"] = acl.TestKubernetesJWT_B
+		config, err = json.Marshal(map[string]interface{}{
+			"AuthMethods": []interface{}{method},
+		})
+		require.NoError(t, err)
+		writeFile(t, "k8s.json", string(config))
+
+		out := run(t, "-dry-run")
+		require.Contains(t, out, `+ "ServiceAccountJWT": "[redacted] (changed)"`)
+		require.NotContains(t, out, acl.TestKubernetesJWT_B)
+	})
+}
@@ -0,0 +1,97 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package export
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+
+	"github.com/hernad/consul/command/acl"
+	"github.com/hernad/consul/command/flags"
+	"github.com/mitchellh/cli"
+)
+
+func New(ui cli.Ui) *cmd {
+	c := &cmd{UI: ui}
+	c.init()
+	return c
+}
+
+type cmd struct {
+	UI    cli.Ui
+	flags *flag.FlagSet
+	http  *flags.HTTPFlags
+	help  string
+
+	includeSecrets bool
+}
+
+func (c *cmd) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.BoolVar(&c.includeSecrets, "include-secrets", false, "Include the "+
+		"credentials held in the config of the auth methods, such as the "+
+		"Kubernetes service account JWT, instead of redacting them.")
+
+	c.http = &flags.HTTPFlags{}
+	flags.Merge(c.flags, c.http.ClientFlags())
+	flags.Merge(c.flags, c.http.ServerFlags())
+	flags.Merge(c.flags, c.http.MultiTenancyFlags())
+	c.help = flags.Usage(help, c.flags)
+}
+
+func (c *cmd) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	client, err := c.http.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error connecting to Consul agent: %s", err))
+		return 1
+	}
+
+	config, err := acl.ExportConfig(client, c.includeSecrets)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	out, err := json.MarshalIndent(config, "", "    ")
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Failed to encode the ACL configuration: %v", err))
+		return 1
+	}
+	c.UI.Output(string(out))
+	return 0
+}
+
+func (c *cmd) Synopsis() string {
+	return synopsis
+}
+
+func (c *cmd) Help() string {
+	return flags.Usage(c.help, nil)
+}
+
+const (
+	synopsis = "Export the ACL configuration"
+	help     = `
+Usage: consul acl export [options]
+
+    Exports the auth methods, policies, policy templates, roles and binding
+    rules as JSON that can be applied again with "consul acl apply". Objects
+    are referenced by name, so the output can be applied to another cluster.
+    Builtin policies and tokens are not exported.
+
+    The credentials held in the config of the auth methods are redacted
+    unless -include-secrets is set. "consul acl apply" keeps the current
+    value of redacted fields, so the output can still be applied to the same
+    cluster.
+
+    Example:
+
+        $ consul acl export > acl.json
+`
+)
@@ -0,0 +1,177 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hernad/consul/agent"
+	"github.com/hernad/consul/agent/connect"
+	"github.com/hernad/consul/api"
+	"github.com/hernad/consul/command/acl"
+	"github.com/hernad/consul/testrpc"
+
+	// activate testing auth method
+	_ "github.com/hernad/consul/agent/consul/authmethod/testauth"
+)
+
+func TestExportCommand_noTabs(t *testing.T) {
+	t.Parallel()
+
+	if strings.ContainsRune(New(cli.NewMockUi()).Help(), '\t') {
+		t.Fatal("help has tabs")
+	}
+}
+
+func TestExportCommand(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	a := agent.NewTestAgent(t, `
+	primary_datacenter = "dc1"
+	acl {
+		enabled = true
+		tokens {
+			initial_management = "root"
+		}
+	}`)
+
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	client := a.Client()
+	opts := &api.WriteOptions{Token: "root"}
+
+	_, _, err := client.ACL().AuthMethodCreate(&api.ACLAuthMethod{
+		Name:        "test",
+		Type:        "testing",
+		MaxTokenTTL: time.Hour,
+	}, opts)
+	require.NoError(t, err)
+
+	policy, _, err := client.ACL().PolicyCreate(&api.ACLPolicy{
+		Name:        "web",
+		Rules:       `service "web" { policy = "write" }`,
+		Datacenters: []string{"dc2", "dc1"},
+	}, opts)
+	require.NoError(t, err)
+
+	_, _, err = client.ACL().PolicyTemplateCreate(&api.ACLPolicyTemplate{
+		Name:  "kv-owner",
+		Rules: `key_prefix "${app}/" { policy = "write" }`,
+	}, opts)
+	require.NoError(t, err)
+
+	_, _, err = client.ACL().RoleCreate(&api.ACLRole{
+		Name:     "web",
+		Policies: []*api.ACLRolePolicyLink{{ID: policy.ID}},
+		ServiceIdentities: []*api.ACLServiceIdentity{
+			{ServiceName: "web"},
+		},
+		TemplatedPolicies: []*api.ACLTemplatedPolicy{
+			{TemplateName: "kv-owner", Variables: map[string]string{"app": "web"}},
+		},
+	}, opts)
+	require.NoError(t, err)
+
+	_, _, err = client.ACL().BindingRuleCreate(&api.ACLBindingRule{
+		AuthMethod: "test",
+		BindType:   api.BindingRuleBindTypeRole,
+		BindName:   "web",
+	}, opts)
+	require.NoError(t, err)
+
+	ui := cli.NewMockUi()
+	code := New(ui).Run([]string{
+		"-http-addr=" + a.HTTPAddr(),
+		"-token=root",
+	})
+	require.Equal(t, 0, code, ui.ErrorWriter.String())
+
+	var config acl.Config
+	require.NoError(t, json.Unmarshal(ui.OutputWriter.Bytes(), &config))
+	require.Equal(t, acl.Config{
+		AuthMethods: []*acl.ConfigAuthMethod{
+			{Name: "test", Type: "testing", MaxTokenTTL: "1h0m0s"},
+		},
+		Policies: []*acl.ConfigPolicy{
+			{Name: "web", Rules: `service "web" { policy = "write" }`, Datacenters: []string{"dc1", "dc2"}},
+		},
+		PolicyTemplates: []*acl.ConfigPolicyTemplate{
+			{Name: "kv-owner", Rules: `key_prefix "${app}/" { policy = "write" }`},
+		},
+		Roles: []*acl.ConfigRole{
+			{
+				Name:              "web",
+				Policies:          []string{"web"},
+				ServiceIdentities: []*api.ACLServiceIdentity{{ServiceName: "web"}},
+				TemplatedPolicies: []*acl.ConfigTemplatedPolicy{
+					{TemplateName: "kv-owner", Variables: map[string]string{"app": "web"}},
+				},
+			},
+		},
+		BindingRules: []*acl.ConfigBindingRule{
+			{AuthMethod: "test", BindType: api.BindingRuleBindTypeRole, BindName: "web"},
+		},
+	}, config)
+}
+
+func TestExportCommand_secrets(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	a := agent.NewTestAgent(t, `
+	primary_datacenter = "dc1"
+	acl {
+		enabled = true
+		tokens {
+			initial_management = "root"
+		}
+	}`)
+
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	ca := connect.TestCA(t, nil)
+	_, _, err := a.Client().ACL().AuthMethodCreate(&api.ACLAuthMethod{
+		Name: "k8s",
+		Type: "kubernetes",
+		Config: map[string]interface{}{
+			"Host":              "https://foo.internal:8443",
+			"CACert":            ca.RootCert,
+			"ServiceAccountJWT": acl.TestKubernetesJWT_A,
+		},
+	}, &api.WriteOptions{Token: "root"})
+	require.NoError(t, err)
+
+	export := func(t *testing.T, args ...string) interface{} {
+		ui := cli.NewMockUi()
+		code := New(ui).Run(append([]string{
+			"-http-addr=" + a.HTTPAddr(),
+			"-token=root",
+		}, args...))
+		require.Equal(t, 0, code, ui.ErrorWriter.String())
+
+		var config acl.Config
+		require.NoError(t, json.Unmarshal(ui.OutputWriter.Bytes(), &config))
+		require.Len(t, config.AuthMethods, 1)
+		require.Equal(t, ca.RootCert, config.AuthMethods[0].Config["CACert"])
+		return config.AuthMethods[0].Config["ServiceAccountJWT"]
+	}
+
+	require.Equal(t, acl.RedactedSecret, export(t))
+	require.Equal(t, acl.TestKubernetesJWT_A, export(t, "-include-secrets"))
+}
@@ -0,0 +1,190 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tokencani
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/hernad/consul/api"
+	"github.com/hernad/consul/command/acl"
+	"github.com/hernad/consul/command/acl/token"
+	"github.com/hernad/consul/command/flags"
+)
+
+func New(ui cli.Ui) *cmd {
+	c := &cmd{UI: ui}
+	c.init()
+	return c
+}
+
+type cmd struct {
+	UI    cli.Ui
+	flags *flag.FlagSet
+	http  *flags.HTTPFlags
+	help  string
+
+	tokenAccessorID string
+	format          string
+}
+
+func (c *cmd) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.StringVar(&c.tokenAccessorID, "accessor-id", "", "The Accessor ID of the token to "+
+		"check the authorizations of. It may be specified as a unique ID prefix but will error "+
+		"if the prefix matches multiple token Accessor IDs. Defaults to the token of the request.")
+	c.flags.StringVar(
+		&c.format,
+		"format",
+		token.PrettyFormat,
+		fmt.Sprintf("Output format {%s}", strings.Join(token.GetSupportedFormats(), "|")),
+	)
+	c.http = &flags.HTTPFlags{}
+	flags.Merge(c.flags, c.http.ClientFlags())
+	flags.Merge(c.flags, c.http.ServerFlags())
+	flags.Merge(c.flags, c.http.MultiTenancyFlags())
+	c.help = flags.Usage(help, c.flags)
+}
+
+func (c *cmd) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	if c.format != token.PrettyFormat && c.format != token.JSONFormat {
+		c.UI.Error(fmt.Sprintf("Invalid format: %s", c.format))
+		return 1
+	}
+
+	var params api.ACLAuthorizeExplainParams
+	for _, arg := range c.flags.Args() {
+		req, err := parseAuthorization(arg)
+		if err != nil {
+			c.UI.Error(err.Error())
+			return 1
+		}
+		req.Namespace = c.http.Namespace()
+		req.Partition = c.http.Partition()
+		params.Requests = append(params.Requests, req)
+	}
+	if len(params.Requests) == 0 {
+		c.UI.Error("Must specify at least one RESOURCE:ACCESS[:SEGMENT] authorization")
+		return 1
+	}
+
+	client, err := c.http.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error connecting to Consul agent: %s", err))
+		return 1
+	}
+
+	if c.tokenAccessorID != "" {
+		params.AccessorID, err = acl.GetTokenAccessorIDFromPartial(client, c.tokenAccessorID)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error determining token ID: %v", err))
+			return 1
+		}
+	}
+
+	explanations, _, err := client.ACL().AuthorizeExplain(&params, nil)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error checking authorizations: %v", err))
+		return 1
+	}
+
+	if c.format == token.JSONFormat {
+		b, err := json.MarshalIndent(explanations, "", "    ")
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Failed to marshal authorizations: %v", err))
+			return 1
+		}
+		c.UI.Output(string(b))
+	} else {
+		c.UI.Output(formatExplanations(explanations))
+	}
+
+	for _, e := range explanations {
+		if !e.Allow {
+			return 2
+		}
+	}
+	return 0
+}
+
+// parseAuthorization parses an authorization written RESOURCE:ACCESS[:SEGMENT].
+// The segment is last so it may contain colons.
+func parseAuthorization(arg string) (api.ACLAuthorizationRequest, error) {
+	parts := strings.SplitN(arg, ":", 3)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return api.ACLAuthorizationRequest{}, fmt.Errorf("Invalid authorization %q, must be RESOURCE:ACCESS[:SEGMENT]", arg)
+	}
+	req := api.ACLAuthorizationRequest{Resource: parts[0], Access: parts[1]}
+	if len(parts) == 3 {
+		req.Segment = parts[2]
+	}
+	return req, nil
+}
+
+func formatExplanations(explanations []*api.ACLAuthorizationExplanation) string {
+	var b strings.Builder
+	for i, e := range explanations {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		decision := "deny"
+		if e.Allow {
+			decision = "allow"
+		}
+		target := e.Resource
+		if e.Segment != "" {
+			target = fmt.Sprintf("%s %q", e.Resource, e.Segment)
+		}
+		fmt.Fprintf(&b, "%s %s: %s\n", e.Access, target, decision)
+		fmt.Fprintf(&b, "   Reason: %s\n", e.Reason)
+		for _, m := range e.Matches {
+			decision := "deny"
+			if m.Allow {
+				decision = "allow"
+			}
+			name := ""
+			if m.Rule.Name != "" || strings.HasSuffix(m.Rule.Type, "_prefix") {
+				name = fmt.Sprintf(" %q", m.Rule.Name)
+			}
+			fmt.Fprintf(&b, "   Policy %s (%s): %s%s = %q (%s)\n", m.PolicyName, m.PolicyID, m.Rule.Type, name, m.Rule.Policy, decision)
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func (c *cmd) Synopsis() string {
+	return synopsis
+}
+
+func (c *cmd) Help() string {
+	return flags.Usage(c.help, nil)
+}
+
+const (
+	synopsis = "Check the authorizations of an ACL token"
+	help     = `
+Usage: consul acl token can-i [options] RESOURCE:ACCESS[:SEGMENT]...
+
+  This command checks whether a token is allowed the given authorizations
+  and prints, for each of them, the policy and rule of the token that
+  produced the decision. It exits with code 2 if any authorization is
+  denied.
+
+  Check the token of the request:
+
+          $ consul acl token can-i key:write:app/config service:read:web
+
+  Check another token, which requires acl:read:
+
+          $ consul acl token can-i -accessor-id 4be56c77-82 operator:write
+`
+)
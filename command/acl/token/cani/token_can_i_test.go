@@ -0,0 +1,103 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tokencani
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hernad/consul/agent"
+	"github.com/hernad/consul/api"
+	"github.com/hernad/consul/testrpc"
+)
+
+func TestTokenCanICommand_noTabs(t *testing.T) {
+	t.Parallel()
+
+	if strings.ContainsRune(New(cli.NewMockUi()).Help(), '\t') {
+		t.Fatal("help has tabs")
+	}
+}
+
+func TestTokenCanICommand(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	a := agent.NewTestAgent(t, `
+	primary_datacenter = "dc1"
+	acl {
+		enabled = true
+		default_policy = "deny"
+		tokens {
+			initial_management = "root"
+		}
+	}`)
+
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	client := a.Client()
+
+	policy, _, err := client.ACL().PolicyCreate(
+		&api.ACLPolicy{Name: "app", Rules: `key_prefix "app/" { policy = "write" }`},
+		&api.WriteOptions{Token: "root"},
+	)
+	require.NoError(t, err)
+
+	token, _, err := client.ACL().TokenCreate(
+		&api.ACLToken{Policies: []*api.ACLTokenPolicyLink{{ID: policy.ID}}},
+		&api.WriteOptions{Token: "root"},
+	)
+	require.NoError(t, err)
+
+	t.Run("allowed", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		code := New(ui).Run([]string{
+			"-http-addr=" + a.HTTPAddr(),
+			"-token=" + token.SecretID,
+			"key:write:app/config",
+		})
+		require.Equal(t, 0, code, ui.ErrorWriter.String())
+		require.Contains(t, ui.OutputWriter.String(), `write key "app/config": allow`)
+		require.Contains(t, ui.OutputWriter.String(), `rule key_prefix "app/" { policy = "write" } of policy "app"`)
+	})
+
+	t.Run("denied by accessor", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		code := New(ui).Run([]string{
+			"-http-addr=" + a.HTTPAddr(),
+			"-token=root",
+			"-accessor-id=" + token.AccessorID,
+			"-format=json",
+			"key:write:app/config",
+			"operator:read",
+		})
+		require.Equal(t, 2, code, ui.ErrorWriter.String())
+
+		var out []api.ACLAuthorizationExplanation
+		require.NoError(t, json.Unmarshal(ui.OutputWriter.Bytes(), &out))
+		require.Len(t, out, 2)
+		require.True(t, out[0].Allow)
+		require.Equal(t, policy.ID, out[0].PolicyID)
+		require.False(t, out[1].Allow)
+		require.Nil(t, out[1].Rule)
+	})
+
+	t.Run("invalid authorization", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		code := New(ui).Run([]string{
+			"-http-addr=" + a.HTTPAddr(),
+			"key",
+		})
+		require.Equal(t, 1, code)
+		require.Contains(t, ui.ErrorWriter.String(), "must be RESOURCE:ACCESS[:SEGMENT]")
+	})
+}
@@ -40,6 +40,7 @@ type cmd struct {
 	nodeIdents    []string
 	expirationTTL time.Duration
 	local         bool
+	child         bool
 	showMeta      bool
 	format        string
 }
@@ -53,6 +54,10 @@ func (c *cmd) init() {
 	c.flags.BoolVar(&c.showMeta, "meta", false, "Indicates that token metadata such "+
 		"as the content hash and raft indices should be shown for each entry")
 	c.flags.BoolVar(&c.local, "local", false, "Create this as a datacenter local token")
+	c.flags.BoolVar(&c.child, "child", false, "Create this as a child of the token used "+
+		"to make the request. The child may only be granted policies, roles and identities "+
+		"of its parent, requires -expires-ttl, and is deleted along with its parent. This "+
+		"does not require acl:write")
 	c.flags.StringVar(&c.description, "description", "", "A description of the token")
 	c.flags.Var((*flags.AppendSliceValue)(&c.policyIDs), "policy-id", "ID of a "+
 		"policy to use for this token. May be specified multiple times")
@@ -95,6 +100,17 @@ func (c *cmd) Run(args []string) int {
 		return 1
 	}
 
+	if c.child {
+		if c.expirationTTL <= 0 {
+			c.UI.Error("Cannot create a child token without specifying -expires-ttl")
+			return 1
+		}
+		if c.accessor != "" || c.secret != "" {
+			c.UI.Error("Cannot specify -accessor or -secret when creating a child token")
+			return 1
+		}
+	}
+
 	client, err := c.http.APIClient()
 	if err != nil {
 		c.UI.Error(fmt.Sprintf("Error connecting to Consul agent: %s", err))
@@ -155,7 +171,12 @@ func (c *cmd) Run(args []string) int {
 		newToken.Roles = append(newToken.Roles, &api.ACLTokenRoleLink{ID: roleID})
 	}
 
-	t, _, err := client.ACL().TokenCreate(newToken, nil)
+	var t *api.ACLToken
+	if c.child {
+		t, _, err = client.ACL().TokenCreateChild(newToken, nil)
+	} else {
+		t, _, err = client.ACL().TokenCreate(newToken, nil)
+	}
 	if err != nil {
 		c.UI.Error(fmt.Sprintf("Failed to create new token: %v", err))
 		return 1
@@ -204,5 +225,11 @@ Usage: consul acl token create [options]
                                     -role-name "db-updater" \
                                     -service-identity "web" \
                                     -service-identity "db:east,west"
+
+  Create a short-lived child of the token in use, limited to one of its policies:
+
+          $ consul acl token create -child -expires-ttl 15m \
+                                    -description "CI job" \
+                                    -policy-name "deploy"
 `
 )
@@ -87,6 +87,28 @@ func TestTokenCreateCommand_Pretty(t *testing.T) {
 		})
 	})
 
+	// create a child of a token
+	t.Run("child", func(t *testing.T) {
+		parent := run(t, []string{
+			"-http-addr=" + a.HTTPAddr(),
+			"-token=root",
+			"-policy-name=" + policy.Name,
+		})
+
+		child := run(t, []string{
+			"-http-addr=" + a.HTTPAddr(),
+			"-token=" + parent.SecretID,
+			"-child",
+			"-expires-ttl=10m",
+			"-policy-id=" + policy.ID,
+			"-description=child token",
+		})
+		require.Equal(t, parent.AccessorID, child.ParentAccessorID)
+		require.NotNil(t, child.ExpirationTime)
+		require.Len(t, child.Policies, 1)
+		require.Equal(t, policy.ID, child.Policies[0].ID)
+	})
+
 	// create with a node identity
 	t.Run("node-identity", func(t *testing.T) {
 		token := run(t, []string{
@@ -72,10 +72,16 @@ func (f *prettyFormatter) FormatToken(token *api.ACLToken) (string, error) {
 	if token.AuthMethod != "" {
 		buffer.WriteString(fmt.Sprintf("Auth Method:      %s (Namespace: %s)\n", token.AuthMethod, token.AuthMethodNamespace))
 	}
+	if token.ParentAccessorID != "" {
+		buffer.WriteString(fmt.Sprintf("Parent Token:     %s\n", token.ParentAccessorID))
+	}
 	buffer.WriteString(fmt.Sprintf("Create Time:      %v\n", token.CreateTime))
 	if token.ExpirationTime != nil && !token.ExpirationTime.IsZero() {
 		buffer.WriteString(fmt.Sprintf("Expiration Time:  %v\n", *token.ExpirationTime))
 	}
+	if token.LastUsedTime != nil && !token.LastUsedTime.IsZero() {
+		buffer.WriteString(fmt.Sprintf("Last Used Time:   %v\n", *token.LastUsedTime))
+	}
 	if f.showMeta {
 		buffer.WriteString(fmt.Sprintf("Hash:             %x\n", token.Hash))
 		buffer.WriteString(fmt.Sprintf("Create Index:     %d\n", token.CreateIndex))
@@ -129,10 +135,16 @@ func (f *prettyFormatter) FormatTokenExpanded(token *api.ACLTokenExpanded) (stri
 	if token.AuthMethod != "" {
 		buffer.WriteString(fmt.Sprintf("Auth Method:      %s (Namespace: %s)\n", token.AuthMethod, token.AuthMethodNamespace))
 	}
+	if token.ParentAccessorID != "" {
+		buffer.WriteString(fmt.Sprintf("Parent Token:     %s\n", token.ParentAccessorID))
+	}
 	buffer.WriteString(fmt.Sprintf("Create Time:      %v\n", token.CreateTime))
 	if token.ExpirationTime != nil && !token.ExpirationTime.IsZero() {
 		buffer.WriteString(fmt.Sprintf("Expiration Time:  %v\n", *token.ExpirationTime))
 	}
+	if token.LastUsedTime != nil && !token.LastUsedTime.IsZero() {
+		buffer.WriteString(fmt.Sprintf("Last Used Time:   %v\n", *token.LastUsedTime))
+	}
 	if f.showMeta {
 		buffer.WriteString(fmt.Sprintf("Hash:             %x\n", token.Hash))
 		buffer.WriteString(fmt.Sprintf("Create Index:     %d\n", token.CreateIndex))
@@ -298,10 +310,16 @@ func (f *prettyFormatter) formatTokenListEntry(token *api.ACLTokenListEntry) str
 	if token.AuthMethod != "" {
 		buffer.WriteString(fmt.Sprintf("Auth Method:      %s (Namespace: %s)\n", token.AuthMethod, token.AuthMethodNamespace))
 	}
+	if token.ParentAccessorID != "" {
+		buffer.WriteString(fmt.Sprintf("Parent Token:     %s\n", token.ParentAccessorID))
+	}
 	buffer.WriteString(fmt.Sprintf("Create Time:      %v\n", token.CreateTime))
 	if token.ExpirationTime != nil && !token.ExpirationTime.IsZero() {
 		buffer.WriteString(fmt.Sprintf("Expiration Time:  %v\n", *token.ExpirationTime))
 	}
+	if token.LastUsedTime != nil && !token.LastUsedTime.IsZero() {
+		buffer.WriteString(fmt.Sprintf("Last Used Time:   %v\n", *token.LastUsedTime))
+	}
 	if f.showMeta {
 		buffer.WriteString(fmt.Sprintf("Hash:             %x\n", token.Hash))
 		buffer.WriteString(fmt.Sprintf("Create Index:     %d\n", token.CreateIndex))
@@ -60,6 +60,33 @@ func TestFormatToken(t *testing.T) {
 				ModifyIndex: 100,
 			},
 		},
+		"last-used": {
+			token: api.ACLToken{
+				AccessorID:   "fbd2447f-7479-4329-ad13-b021d74f86ba",
+				SecretID:     "869c6e91-4de9-4dab-b56e-87548435f9c6",
+				Description:  "test token",
+				Local:        false,
+				CreateTime:   time.Date(2020, 5, 22, 18, 52, 31, 0, time.UTC),
+				LastUsedTime: timeRef(time.Date(2020, 6, 1, 9, 0, 0, 0, time.UTC)),
+				Hash:         []byte{'a', 'b', 'c', 'd', 'e', 'f', 'g', 'h'},
+				CreateIndex:  42,
+				ModifyIndex:  100,
+			},
+		},
+		"child": {
+			token: api.ACLToken{
+				AccessorID:       "fbd2447f-7479-4329-ad13-b021d74f86ba",
+				SecretID:         "869c6e91-4de9-4dab-b56e-87548435f9c6",
+				Description:      "test token",
+				Local:            false,
+				ParentAccessorID: "a8c1f1a6-1c1d-4c3a-9c1b-5b2e0f8d2c11",
+				CreateTime:       time.Date(2020, 5, 22, 18, 52, 31, 0, time.UTC),
+				ExpirationTime:   timeRef(time.Date(2020, 5, 22, 19, 52, 31, 0, time.UTC)),
+				Hash:             []byte{'a', 'b', 'c', 'd', 'e', 'f', 'g', 'h'},
+				CreateIndex:      42,
+				ModifyIndex:      100,
+			},
+		},
 		"complex": {
 			token: api.ACLToken{
 				AccessorID:          "fbd2447f-7479-4329-ad13-b021d74f86ba",
@@ -162,6 +189,37 @@ func TestFormatTokenList(t *testing.T) {
 				},
 			},
 		},
+		"last-used": {
+			tokens: []*api.ACLTokenListEntry{
+				{
+					AccessorID:   "fbd2447f-7479-4329-ad13-b021d74f86ba",
+					SecretID:     "257ade69-748c-4022-bafd-76d27d9143f8",
+					Description:  "test token",
+					Local:        false,
+					CreateTime:   time.Date(2020, 5, 22, 18, 52, 31, 0, time.UTC),
+					LastUsedTime: timeRef(time.Date(2020, 6, 1, 9, 0, 0, 0, time.UTC)),
+					Hash:         []byte{'a', 'b', 'c', 'd', 'e', 'f', 'g', 'h'},
+					CreateIndex:  42,
+					ModifyIndex:  100,
+				},
+			},
+		},
+		"child": {
+			tokens: []*api.ACLTokenListEntry{
+				{
+					AccessorID:       "fbd2447f-7479-4329-ad13-b021d74f86ba",
+					SecretID:         "257ade69-748c-4022-bafd-76d27d9143f8",
+					Description:      "test token",
+					Local:            false,
+					ParentAccessorID: "a8c1f1a6-1c1d-4c3a-9c1b-5b2e0f8d2c11",
+					CreateTime:       time.Date(2020, 5, 22, 18, 52, 31, 0, time.UTC),
+					ExpirationTime:   timeRef(time.Date(2020, 5, 22, 19, 52, 31, 0, time.UTC)),
+					Hash:             []byte{'a', 'b', 'c', 'd', 'e', 'f', 'g', 'h'},
+					CreateIndex:      42,
+					ModifyIndex:      100,
+				},
+			},
+		},
 		"complex": {
 			tokens: []*api.ACLTokenListEntry{
 				{
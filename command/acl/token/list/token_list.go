@@ -7,7 +7,9 @@ import (
 	"flag"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/hernad/consul/api"
 	"github.com/hernad/consul/command/acl/token"
 	"github.com/hernad/consul/command/flags"
 	"github.com/mitchellh/cli"
@@ -25,8 +27,9 @@ type cmd struct {
 	http  *flags.HTTPFlags
 	help  string
 
-	showMeta bool
-	format   string
+	showMeta      bool
+	format        string
+	unusedForDays int
 }
 
 func (c *cmd) init() {
@@ -39,6 +42,10 @@ func (c *cmd) init() {
 		token.PrettyFormat,
 		fmt.Sprintf("Output format {%s}", strings.Join(token.GetSupportedFormats(), "|")),
 	)
+	c.flags.IntVar(&c.unusedForDays, "unused-for-days", 0, "Only list the tokens that "+
+		"were not used within this many days. Tokens that were never used are listed "+
+		"once they are older than this. Global tokens are only listed in the primary "+
+		"datacenter, which tracks their usage in every datacenter.")
 	c.http = &flags.HTTPFlags{}
 	flags.Merge(c.flags, c.http.ClientFlags())
 	flags.Merge(c.flags, c.http.ServerFlags())
@@ -51,13 +58,21 @@ func (c *cmd) Run(args []string) int {
 		return 1
 	}
 
+	if c.unusedForDays < 0 {
+		c.UI.Error("The -unused-for-days value must not be negative")
+		return 1
+	}
+
 	client, err := c.http.APIClient()
 	if err != nil {
 		c.UI.Error(fmt.Sprintf("Error connecting to Consul agent: %s", err))
 		return 1
 	}
 
-	tokens, _, err := client.ACL().TokenList(nil)
+	filter := api.ACLTokenFilterOptions{
+		UnusedFor: time.Duration(c.unusedForDays) * 24 * time.Hour,
+	}
+	tokens, _, err := client.ACL().TokenListFiltered(filter, nil)
 	if err != nil {
 		c.UI.Error(fmt.Sprintf("Failed to retrieve the token list: %v", err))
 		return 1
@@ -96,5 +111,9 @@ Usage: consul acl token list [options]
   List all the ACL tokens
 
           $ consul acl token list
+
+  List the ACL tokens that were not used in the last 90 days
+
+          $ consul acl token list -unused-for-days=90
 `
 )
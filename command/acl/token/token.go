@@ -56,5 +56,9 @@ Usage: consul acl token <subcommand> [options] [args]
 
     $ consul acl token delete -accessor-id 986193
 
+  Check whether a token may write a key
+
+    $ consul acl token can-i -accessor-id 986193 key:write:app/config
+
   For more examples, ask for subcommand help or view the documentation.
 `
@@ -8,6 +8,8 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/hernad/consul/api"
 	"github.com/hernad/consul/command/flags"
@@ -29,6 +31,7 @@ type cmd struct {
 	// flags
 	configFile               flags.StringValue
 	forceWithoutCrossSigning bool
+	dryRun                   bool
 }
 
 func (c *cmd) init() {
@@ -41,6 +44,9 @@ func (c *cmd) init() {
 			"failures during the rollout as new leafs will be rejected by proxies that "+
 			"have not yet observed the new root cert but is the only option if a CA that "+
 			"doesn't support cross signing needs to be reconfigured or mirated away from.")
+	c.flags.BoolVar(&c.dryRun, "dry-run", false,
+		"Validate the configuration and report which datacenters, peers and gateways "+
+			"would be affected by the change without applying it.")
 
 	c.http = &flags.HTTPFlags{}
 	flags.Merge(c.flags, c.http.ClientFlags())
@@ -82,6 +88,16 @@ func (c *cmd) Run(args []string) int {
 	}
 	config.ForceWithoutCrossSigning = c.forceWithoutCrossSigning
 
+	if c.dryRun {
+		report, _, err := client.Connect().CASetConfigDryRun(&config, nil)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error validating CA configuration: %s", err))
+			return 1
+		}
+		c.UI.Output(formatDryRunReport(report))
+		return 0
+	}
+
 	// Set the new configuration.
 	if _, err := client.Connect().CASetConfig(&config, nil); err != nil {
 		c.UI.Error(fmt.Sprintf("Error setting CA configuration: %s", err))
@@ -91,6 +107,68 @@ func (c *cmd) Run(args []string) int {
 	return 0
 }
 
+func formatDryRunReport(report *api.CADryRunReport) string {
+	var b strings.Builder
+
+	if report.NoOp {
+		b.WriteString("The configuration is identical to the current one, no changes would be made.")
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "Provider:               %s\n", report.Provider)
+	if report.CurrentRoot != nil {
+		fmt.Fprintf(&b, "Current root:           %s\n", formatDryRunRoot(report.CurrentRoot))
+	}
+	if report.NewRoot != nil {
+		fmt.Fprintf(&b, "New root:               %s\n", formatDryRunRoot(report.NewRoot))
+	}
+	fmt.Fprintf(&b, "Root rotation:          %t\n", report.RootRotation)
+	fmt.Fprintf(&b, "Intermediate rotation:  %t\n", report.IntermediateRotation)
+	fmt.Fprintf(&b, "Cross-signing:          %s\n", report.CrossSigning)
+
+	if len(report.Datacenters) > 0 {
+		b.WriteString("\nAffected datacenters:\n")
+		for _, dc := range report.Datacenters {
+			switch {
+			case dc.Error != "":
+				fmt.Fprintf(&b, "  %s (error: %s)\n", dc.Datacenter, dc.Error)
+			case dc.Primary:
+				fmt.Fprintf(&b, "  %s (primary, %s, %s-%d)\n", dc.Datacenter, dc.Provider, dc.PrivateKeyType, dc.PrivateKeyBits)
+			default:
+				fmt.Fprintf(&b, "  %s (%s, %s-%d)\n", dc.Datacenter, dc.Provider, dc.PrivateKeyType, dc.PrivateKeyBits)
+			}
+		}
+	}
+	if len(report.Peers) > 0 {
+		b.WriteString("\nAffected peers:\n")
+		for _, peer := range report.Peers {
+			fmt.Fprintf(&b, "  %s (%s)\n", peer.Name, peer.State)
+		}
+	}
+	if len(report.Gateways) > 0 {
+		b.WriteString("\nAffected gateways:\n")
+		for _, gw := range report.Gateways {
+			fmt.Fprintf(&b, "  %s %s (%d instances)\n", gw.Kind, gw.Name, gw.Instances)
+		}
+	}
+	if report.RootRotation || report.IntermediateRotation {
+		fmt.Fprintf(&b, "\nAffected proxies:       %d\n", report.Proxies)
+	}
+	if len(report.Warnings) > 0 {
+		b.WriteString("\nWarnings:\n")
+		for _, warning := range report.Warnings {
+			fmt.Fprintf(&b, "  - %s\n", warning)
+		}
+	}
+
+	b.WriteString("\nThe configuration is valid, no changes have been made.")
+	return b.String()
+}
+
+func formatDryRunRoot(root *api.CADryRunRoot) string {
+	return fmt.Sprintf("%s (%s-%d, expires %s)", root.ID, root.PrivateKeyType, root.PrivateKeyBits, root.NotAfter.Format(time.RFC3339))
+}
+
 func (c *cmd) Synopsis() string {
 	return synopsis
 }
@@ -104,4 +182,8 @@ const help = `
 Usage: consul connect ca set-config [options]
 
   Modifies the current Connect Certificate Authority (CA) configuration.
+
+  To check the impact of a new configuration before applying it:
+
+      $ consul connect ca set-config -config-file ca.json -dry-run
 `
@@ -59,3 +59,38 @@ func TestConnectCASetConfigCommand(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, 288*time.Hour, parsed.IntermediateCertTTL)
 }
+
+func TestConnectCASetConfigCommand_DryRun(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := agent.NewTestAgent(t, ``)
+	defer a.Shutdown()
+
+	testrpc.WaitForTestAgent(t, a.RPC, "dc1")
+	ui := cli.NewMockUi()
+	c := New(ui)
+	args := []string{
+		"-http-addr=" + a.HTTPAddr(),
+		"-config-file=test-fixtures/ca_config_rsa.json",
+		"-dry-run",
+	}
+
+	code := c.Run(args)
+	require.Equal(t, 0, code, ui.ErrorWriter.String())
+
+	output := ui.OutputWriter.String()
+	require.Contains(t, output, "Root rotation:          true")
+	require.Contains(t, output, "Cross-signing:          cross-signed")
+	require.Contains(t, output, "dc1 (primary, consul, rsa-2048)")
+	require.Contains(t, output, "no changes have been made")
+
+	req := structs.DCSpecificRequest{
+		Datacenter: "dc1",
+	}
+	var reply structs.CAConfiguration
+	require.NoError(t, a.RPC(context.Background(), "ConnectCA.ConfigurationGet", &req, &reply))
+	require.NotContains(t, reply.Config, "PrivateKeyType")
+}
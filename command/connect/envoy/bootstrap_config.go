@@ -16,6 +16,7 @@ import (
 	"strings"
 	"text/template"
 
+	"github.com/hernad/consul/agent/xds"
 	"github.com/hernad/consul/api"
 )
 
@@ -160,6 +161,11 @@ type BootstrapConfig struct {
 	// the bootstrap config. It's format may vary based on Envoy version used.
 	// See https://www.envoyproxy.io/docs/envoy/v1.9.0/api-v2/config/trace/v2/trace.proto.
 	TracingConfigJSON string `mapstructure:"envoy_tracing_json"`
+
+	// Runtime holds the runtime flags the agent delivers through RTDS. When it
+	// is set, even empty, the RTDS layer is declared so that the flags can then
+	// be changed without restarting Envoy.
+	Runtime map[string]interface{} `mapstructure:"envoy_runtime"`
 }
 
 // Template returns the bootstrap template to use as a base.
@@ -249,6 +255,10 @@ func (c *BootstrapConfig) ConfigureArgs(args *BootstrapTplArgs, omitDeprecatedTa
 		args.StatsFlushInterval = c.StatsFlushInterval
 	}
 
+	if c.Runtime != nil {
+		args.RuntimeLayerName = xds.RuntimeResourceName
+	}
+
 	// Setup telemetry collector if needed. This MUST happen after the Static*JSON is set above
 	if c.TelemetryCollectorBindSocketDir != "" {
 		appendTelemetryCollectorConfig(args, c.TelemetryCollectorBindSocketDir)
@@ -135,6 +135,11 @@ type BootstrapTplArgs struct {
 	// PrometheusKeyFile is the path to a private key file Envoy to use when serving TLS on the Prometheus metrics
 	// endpoint. Only applicable when envoy_prometheus_bind_addr is set in the proxy config.
 	PrometheusKeyFile string
+
+	// RuntimeLayerName is the name of the runtime layer, and of its RTDS
+	// resource, through which the agent delivers the runtime flags of the
+	// proxy. No RTDS layer is declared when empty.
+	RuntimeLayerName string
 }
 
 // GRPC settings used in the bootstrap template.
@@ -196,6 +201,19 @@ const bootstrapTemplate = `{
           "re2.max_program_size.error_level": 1048576
         }
       }
+      {{- if .RuntimeLayerName -}}
+      ,
+      {
+        "name": "{{ .RuntimeLayerName }}",
+        "rtds_layer": {
+          "name": "{{ .RuntimeLayerName }}",
+          "rtds_config": {
+            "ads": {},
+            "resource_api_version": "V3"
+          }
+        }
+      }
+      {{- end }}
     ]
   },
   "static_resources": {
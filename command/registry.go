@@ -11,6 +11,7 @@ import (
 
 	"github.com/hernad/consul/command/acl"
 	aclagent "github.com/hernad/consul/command/acl/agenttokens"
+	aclapply "github.com/hernad/consul/command/acl/apply"
 	aclam "github.com/hernad/consul/command/acl/authmethod"
 	aclamcreate "github.com/hernad/consul/command/acl/authmethod/create"
 	aclamdelete "github.com/hernad/consul/command/acl/authmethod/delete"
@@ -24,6 +25,7 @@ import (
 	aclbrread "github.com/hernad/consul/command/acl/bindingrule/read"
 	aclbrupdate "github.com/hernad/consul/command/acl/bindingrule/update"
 	aclbootstrap "github.com/hernad/consul/command/acl/bootstrap"
+	aclexport "github.com/hernad/consul/command/acl/export"
 	aclpolicy "github.com/hernad/consul/command/acl/policy"
 	aclpcreate "github.com/hernad/consul/command/acl/policy/create"
 	aclpdelete "github.com/hernad/consul/command/acl/policy/delete"
@@ -37,6 +39,7 @@ import (
 	aclrread "github.com/hernad/consul/command/acl/role/read"
 	aclrupdate "github.com/hernad/consul/command/acl/role/update"
 	acltoken "github.com/hernad/consul/command/acl/token"
+	acltcani "github.com/hernad/consul/command/acl/token/cani"
 	acltclone "github.com/hernad/consul/command/acl/token/clone"
 	acltcreate "github.com/hernad/consul/command/acl/token/create"
 	acltdelete "github.com/hernad/consul/command/acl/token/delete"
@@ -141,6 +144,8 @@ func RegisteredCommands(ui cli.Ui) map[string]mcli.CommandFactory {
 	registerCommands(ui, registry,
 		entry{"acl", func(cli.Ui) (cli.Command, error) { return acl.New(), nil }},
 		entry{"acl bootstrap", func(ui cli.Ui) (cli.Command, error) { return aclbootstrap.New(ui), nil }},
+		entry{"acl export", func(ui cli.Ui) (cli.Command, error) { return aclexport.New(ui), nil }},
+		entry{"acl apply", func(ui cli.Ui) (cli.Command, error) { return aclapply.New(ui), nil }},
 		entry{"acl policy", func(cli.Ui) (cli.Command, error) { return aclpolicy.New(), nil }},
 		entry{"acl policy create", func(ui cli.Ui) (cli.Command, error) { return aclpcreate.New(ui), nil }},
 		entry{"acl policy list", func(ui cli.Ui) (cli.Command, error) { return aclplist.New(ui), nil }},
@@ -155,6 +160,7 @@ func RegisteredCommands(ui cli.Ui) map[string]mcli.CommandFactory {
 		entry{"acl token read", func(ui cli.Ui) (cli.Command, error) { return acltread.New(ui), nil }},
 		entry{"acl token update", func(ui cli.Ui) (cli.Command, error) { return acltupdate.New(ui), nil }},
 		entry{"acl token delete", func(ui cli.Ui) (cli.Command, error) { return acltdelete.New(ui), nil }},
+		entry{"acl token can-i", func(ui cli.Ui) (cli.Command, error) { return acltcani.New(ui), nil }},
 		entry{"acl role", func(cli.Ui) (cli.Command, error) { return aclrole.New(), nil }},
 		entry{"acl role create", func(ui cli.Ui) (cli.Command, error) { return aclrcreate.New(ui), nil }},
 		entry{"acl role list", func(ui cli.Ui) (cli.Command, error) { return aclrlist.New(ui), nil }},
@@ -5,9 +5,11 @@ package xdscommon
 
 import (
 	envoy_cluster_v3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_endpoint_v3 "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
 	envoy_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
 	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	envoy_runtime_v3 "github.com/envoyproxy/go-control-plane/envoy/service/runtime/v3"
 	"github.com/hashicorp/go-hclog"
 	"google.golang.org/protobuf/proto"
 )
@@ -53,6 +55,13 @@ const (
 	// SecretType is the TypeURL for Secret discovery responses.
 	SecretType = apiTypePrefix + "envoy.extensions.transport_sockets.tls.v3.Secret"
 
+	// ExtensionConfigType is the TypeURL for Extension Config discovery
+	// responses.
+	ExtensionConfigType = apiTypePrefix + "envoy.config.core.v3.TypedExtensionConfig"
+
+	// RuntimeType is the TypeURL for Runtime discovery responses.
+	RuntimeType = apiTypePrefix + "envoy.service.runtime.v3.Runtime"
+
 	FailoverClusterNamePrefix = "failover-target~"
 )
 
@@ -107,7 +116,7 @@ func IndexResources(logger hclog.Logger, resources map[string][]proto.Message) *
 }
 
 func GetResourceName(res proto.Message) string {
-	// NOTE: this only covers types that we currently care about for LDS/RDS/CDS/EDS
+	// NOTE: this only covers types that we currently care about for LDS/RDS/CDS/EDS/ECDS/RTDS
 	switch x := res.(type) {
 	case *envoy_listener_v3.Listener: // LDS
 		return x.Name
@@ -117,6 +126,10 @@ func GetResourceName(res proto.Message) string {
 		return x.Name
 	case *envoy_endpoint_v3.ClusterLoadAssignment: // EDS
 		return x.ClusterName
+	case *envoy_core_v3.TypedExtensionConfig: // ECDS
+		return x.Name
+	case *envoy_runtime_v3.Runtime: // RTDS
+		return x.Name
 	default:
 		return ""
 	}
@@ -129,6 +142,9 @@ func EmptyIndexedResources() *IndexedResources {
 			RouteType:    make(map[string]proto.Message),
 			ClusterType:  make(map[string]proto.Message),
 			EndpointType: make(map[string]proto.Message),
+
+			ExtensionConfigType: make(map[string]proto.Message),
+			RuntimeType:         make(map[string]proto.Message),
 		},
 		ChildIndex: map[string]map[string][]string{
 			ListenerType: make(map[string][]string),
@@ -3,7 +3,7 @@
 
 // package oidcauth bundles up an opinionated approach to authentication using
 // both the OIDC authorization code workflow and simple JWT decoding (via
-// static keys, JWKS, and OIDC discovery).
+// static keys, remote or local JWKS, and OIDC discovery).
 //
 // NOTE: This was roughly forked from hashicorp/vault-plugin-auth-jwt
 // originally at commit 825c85535e3832d254a74253a8e9ae105357778b with later
@@ -116,6 +116,11 @@ func New(c *Config, logger hclog.Logger) (*Authenticator, error) {
 			contextWithHttpClient(a.backgroundCtx, a.httpClient),
 			a.config.JWKSURL,
 		)
+	case authLocalJWKS:
+		a.keySet, err = newLocalKeySet(a.config)
+		if err != nil {
+			return nil, fmt.Errorf("error loading JWKS: %v", err)
+		}
 	}
 
 	return a, nil
@@ -127,6 +127,21 @@ type Config struct {
 	// Valid only if Type=jwt
 	JWKSCACert string
 
+	// JWKS is an inline JWKS document with the public keys to use to
+	// authenticate signatures locally. Cannot be used with "JWKSURL",
+	// "JWKSFile", "OIDCDiscoveryURL" or "JWTValidationPubKeys".
+	//
+	// Valid only if Type=jwt
+	JWKS string
+
+	// JWKSFile is the path to a local file containing a JWKS document with
+	// the public keys to use to authenticate signatures. The file is read
+	// again when it changes. Cannot be used with "JWKSURL", "JWKS",
+	// "OIDCDiscoveryURL" or "JWTValidationPubKeys".
+	//
+	// Valid only if Type=jwt
+	JWKSFile string
+
 	// JWTValidationPubKeys is a list of PEM-encoded public keys to use to
 	// authenticate signatures locally. Cannot be used with "JWKSURL" or
 	// "OIDCDiscoveryURL".
@@ -192,6 +207,10 @@ func (c *Config) Validate() error {
 			return fmt.Errorf("'JWKSURL' must not be set for type %q", c.Type)
 		case c.JWKSCACert != "":
 			return fmt.Errorf("'JWKSCACert' must not be set for type %q", c.Type)
+		case c.JWKS != "":
+			return fmt.Errorf("'JWKS' must not be set for type %q", c.Type)
+		case c.JWKSFile != "":
+			return fmt.Errorf("'JWKSFile' must not be set for type %q", c.Type)
 		case len(c.JWTValidationPubKeys) != 0:
 			return fmt.Errorf("'JWTValidationPubKeys' must not be set for type %q", c.Type)
 		case c.BoundIssuer != "":
@@ -241,9 +260,15 @@ func (c *Config) Validate() error {
 		if c.JWKSURL != "" {
 			methodCount++
 		}
+		if c.JWKS != "" {
+			methodCount++
+		}
+		if c.JWKSFile != "" {
+			methodCount++
+		}
 
 		if methodCount != 1 {
-			return fmt.Errorf("exactly one of 'JWTValidationPubKeys', 'JWKSURL', or 'OIDCDiscoveryURL' must be set for type %q", c.Type)
+			return fmt.Errorf("exactly one of 'JWTValidationPubKeys', 'JWKSURL', 'JWKS', 'JWKSFile', or 'OIDCDiscoveryURL' must be set for type %q", c.Type)
 		}
 
 		if c.JWKSURL != "" {
@@ -270,6 +295,12 @@ func (c *Config) Validate() error {
 			return fmt.Errorf("'JWKSCACert' should not be set unless 'JWKSURL' is set")
 		}
 
+		if c.JWKS != "" || c.JWKSFile != "" {
+			if _, err := newLocalKeySet(c); err != nil {
+				return fmt.Errorf("error checking JWKS: %v", err)
+			}
+		}
+
 		if len(c.JWTValidationPubKeys) != 0 {
 			for i, v := range c.JWTValidationPubKeys {
 				if _, err := parsePublicKeyPEM([]byte(v)); err != nil {
@@ -333,6 +364,7 @@ const (
 	authUnconfigured = iota
 	authStaticKeys
 	authJWKS
+	authLocalJWKS
 	authOIDCDiscovery
 	authOIDCFlow
 )
@@ -345,6 +377,8 @@ func (c *Config) authType() int {
 		return authStaticKeys
 	case c.JWKSURL != "":
 		return authJWKS
+	case c.JWKS != "" || c.JWKSFile != "":
+		return authLocalJWKS
 	case c.OIDCDiscoveryURL != "":
 		if c.OIDCClientID != "" && c.OIDCClientSecret != "" {
 			return authOIDCFlow
@@ -22,6 +22,9 @@ func TestConfigValidate(t *testing.T) {
 
 	srv := oidcauthtest.Start(t)
 
+	testJWKS, err := oidcauthtest.JWKS(testJWTPubKey)
+	require.NoError(t, err)
+
 	oidcCases := map[string]testcase{
 		"all required": {
 			config: Config{
@@ -343,6 +346,36 @@ func TestConfigValidate(t *testing.T) {
 			},
 			expectAuthType: authJWKS,
 		},
+		"all required for inline jwks": {
+			config: Config{
+				Type: TypeJWT,
+				JWKS: testJWKS,
+			},
+			expectAuthType: authLocalJWKS,
+		},
+		"invalid inline jwks": {
+			config: Config{
+				Type: TypeJWT,
+				JWKS: `{"keys":[]}`,
+			},
+			expectErr: "no keys found in JWKS",
+		},
+		"missing jwks file": {
+			config: Config{
+				Type:     TypeJWT,
+				JWKSFile: "/does/not/exist/jwks.json",
+			},
+			expectErr: "error reading JWKSFile",
+		},
+		"incompatible JWKS with JWKSURL": {
+			config: Config{
+				Type:       TypeJWT,
+				JWKS:       testJWKS,
+				JWKSURL:    srv.Addr() + "/certs",
+				JWKSCACert: srv.CACert(),
+			},
+			expectErr: "exactly one of",
+		},
 		"all required for public keys": {
 			config: Config{
 				Type:                 TypeJWT,
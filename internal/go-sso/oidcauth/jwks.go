@@ -0,0 +1,128 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package oidcauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"gopkg.in/square/go-jose.v2"
+)
+
+// localKeySet is an oidc.KeySet backed by a JWKS document that is either
+// configured inline or read from a local file. The file is read again when its
+// modification time changes so that keys can be rotated by replacing the file
+// without reconfiguring the auth method.
+type localKeySet struct {
+	path string
+
+	l       sync.Mutex
+	modTime time.Time
+	keys    []jose.JSONWebKey
+}
+
+func newLocalKeySet(c *Config) (*localKeySet, error) {
+	if c.JWKSFile == "" {
+		keys, err := parseJWKS([]byte(c.JWKS))
+		if err != nil {
+			return nil, err
+		}
+		return &localKeySet{keys: keys}, nil
+	}
+
+	ks := &localKeySet{path: c.JWKSFile}
+	if _, err := ks.currentKeys(); err != nil {
+		return nil, err
+	}
+	return ks, nil
+}
+
+// VerifySignature implements oidc.KeySet.
+func (ks *localKeySet) VerifySignature(_ context.Context, token string) ([]byte, error) {
+	jws, err := jose.ParseSigned(token)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: malformed jwt: %v", err)
+	}
+	if len(jws.Signatures) == 0 {
+		return nil, errors.New("oidc: jwt has no signature")
+	}
+	keyID := jws.Signatures[0].Header.KeyID
+
+	keys, err := ks.currentKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range keys {
+		key := &keys[i]
+		if keyID != "" && key.KeyID != "" && keyID != key.KeyID {
+			continue
+		}
+		if key.Use == "enc" {
+			continue
+		}
+		if payload, err := jws.Verify(key); err == nil {
+			return payload, nil
+		}
+	}
+	return nil, errors.New("failed to verify id token signature")
+}
+
+// currentKeys returns the keys of the key set, reloading the JWKS file if it
+// changed since it was last read.
+func (ks *localKeySet) currentKeys() ([]jose.JSONWebKey, error) {
+	ks.l.Lock()
+	defer ks.l.Unlock()
+
+	if ks.path == "" {
+		return ks.keys, nil
+	}
+
+	info, err := os.Stat(ks.path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading JWKSFile: %v", err)
+	}
+	if ks.keys != nil && info.ModTime().Equal(ks.modTime) {
+		return ks.keys, nil
+	}
+
+	data, err := os.ReadFile(ks.path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading JWKSFile: %v", err)
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing JWKSFile: %v", err)
+	}
+
+	ks.keys = keys
+	ks.modTime = info.ModTime()
+	return ks.keys, nil
+}
+
+// parseJWKS parses a JWKS document, which must contain at least one key and
+// only public keys.
+func parseJWKS(data []byte) ([]jose.JSONWebKey, error) {
+	var jwks jose.JSONWebKeySet
+	if err := json.Unmarshal(data, &jwks); err != nil {
+		return nil, err
+	}
+	if len(jwks.Keys) == 0 {
+		return nil, errors.New("no keys found in JWKS")
+	}
+	for i, key := range jwks.Keys {
+		if !key.Valid() {
+			return nil, fmt.Errorf("invalid key %d in JWKS", i)
+		}
+		if !key.IsPublic() {
+			return nil, fmt.Errorf("key %d in JWKS is not a public key", i)
+		}
+	}
+	return jwks.Keys, nil
+}
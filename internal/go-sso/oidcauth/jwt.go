@@ -40,7 +40,7 @@ func (a *Authenticator) ClaimsFromJWT(ctx context.Context, jwt string) (*Claims,
 		err       error
 	)
 	switch a.config.authType() {
-	case authStaticKeys, authJWKS:
+	case authStaticKeys, authJWKS, authLocalJWKS:
 		allClaims, err = a.verifyVanillaJWT(ctx, jwt)
 		if err != nil {
 			return nil, err
@@ -75,7 +75,7 @@ func (a *Authenticator) verifyVanillaJWT(ctx context.Context, loginToken string)
 	)
 	// TODO(sso): handle JWTSupportedAlgs
 	switch a.config.authType() {
-	case authJWKS:
+	case authJWKS, authLocalJWKS:
 		// Verify signature (and only signature... other elements are checked later)
 		payload, err := a.keySet.VerifySignature(ctx, loginToken)
 		if err != nil {
@@ -12,6 +12,8 @@ import (
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -67,6 +69,15 @@ func setupForJWT(t *testing.T, authType int, f func(c *Config)) (*Authenticator,
 
 		// TODO(sso): is this a bug in vault?
 		// config.BoundIssuer = issuer
+	case authLocalJWKS:
+		pubKey, _ := oidcauthtest.SigningKeys()
+		jwks, err := oidcauthtest.JWKS(pubKey)
+		require.NoError(t, err)
+
+		config.JWKSFile = filepath.Join(t.TempDir(), "jwks.json")
+		require.NoError(t, os.WriteFile(config.JWKSFile, []byte(jwks), 0600))
+		config.BoundIssuer = "https://legit.issuer.internal/"
+		issuer = config.BoundIssuer
 	default:
 		require.Fail(t, "inappropriate authType: %d", authType)
 	}
@@ -128,6 +139,9 @@ func TestJWT_ClaimsFromJWT(t *testing.T) {
 	t.Run("JWKS", func(t *testing.T) {
 		testJWT_ClaimsFromJWT(t, authJWKS)
 	})
+	t.Run("local JWKS", func(t *testing.T) {
+		testJWT_ClaimsFromJWT(t, authLocalJWKS)
+	})
 	t.Run("oidc discovery", func(t *testing.T) {
 		// TODO(sso): the vault versions of these tests did not run oidc-discovery
 		testJWT_ClaimsFromJWT(t, authOIDCDiscovery)
@@ -329,7 +343,7 @@ func testJWT_ClaimsFromJWT(t *testing.T, authType int) {
 		_, err = oa.ClaimsFromJWT(context.Background(), jwtData)
 
 		switch authType {
-		case authOIDCDiscovery, authJWKS:
+		case authOIDCDiscovery, authJWKS, authLocalJWKS:
 			requireErrorContains(t, err, "failed to verify id token signature")
 		case authStaticKeys:
 			requireErrorContains(t, err, "no known key successfully validated the token signature")
@@ -369,7 +383,7 @@ func testJWT_ClaimsFromJWT(t *testing.T, authType int) {
 		switch authType {
 		case authOIDCDiscovery:
 			requireErrorContains(t, err, "error validating signature: oidc: id token issued by a different provider")
-		case authStaticKeys:
+		case authStaticKeys, authLocalJWKS:
 			requireErrorContains(t, err, "validation failed, invalid issuer claim (iss)")
 		case authJWKS:
 			// requireErrorContains(t, err, "validation failed, invalid issuer claim (iss)")
@@ -642,6 +656,39 @@ func setupLogin(t *testing.T, iat, exp, nbf time.Time, issuer string) string {
 	return jwtData
 }
 
+func TestJWT_ClaimsFromJWT_JWKSFileRotation(t *testing.T) {
+	oa, issuer := setupForJWT(t, authLocalJWKS, func(c *Config) {
+		c.BoundAudiences = []string{"https://go-sso.test"}
+	})
+
+	cl := jwt.Claims{
+		Subject:   "r3qXcK2bix9eFECzsU3Sbmh0K16fatW6@clients",
+		Issuer:    issuer,
+		Audience:  jwt.Audience{"https://go-sso.test"},
+		NotBefore: jwt.NewNumericDate(time.Now().Add(-5 * time.Second)),
+		Expiry:    jwt.NewNumericDate(time.Now().Add(5 * time.Second)),
+	}
+
+	newPubKey, newPrivKey, err := oidcauthtest.GenerateKey()
+	require.NoError(t, err)
+
+	jwtData, err := oidcauthtest.SignJWT(newPrivKey, cl, struct{}{})
+	require.NoError(t, err)
+
+	_, err = oa.ClaimsFromJWT(context.Background(), jwtData)
+	requireErrorContains(t, err, "failed to verify id token signature")
+
+	// Replace the keys in the file, which is read again once it changed.
+	jwks, err := oidcauthtest.JWKS(newPubKey)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(oa.config.JWKSFile, []byte(jwks), 0600))
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(oa.config.JWKSFile, later, later))
+
+	_, err = oa.ClaimsFromJWT(context.Background(), jwtData)
+	require.NoError(t, err)
+}
+
 func TestParsePublicKeyPEM(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
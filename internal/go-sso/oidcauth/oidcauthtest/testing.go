@@ -394,6 +394,20 @@ func newJWKS(pubKey string) (*jose.JSONWebKeySet, error) {
 	}, nil
 }
 
+// JWKS converts a pem-encoded public key into an encoded JWKS document
+// suitable for the JWKS and JWKSFile auth method settings.
+func JWKS(pubKey string) (string, error) {
+	jwks, err := newJWKS(pubKey)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(jwks)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
 func writeJSON(w http.ResponseWriter, out interface{}) error {
 	enc := json.NewEncoder(w)
 	return enc.Encode(out)
@@ -0,0 +1,97 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package jsonpath implements the subset of JSONPath supported by HTTP checks.
+package jsonpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// segment is either a key of an object or an index of an array.
+type segment struct {
+	key   string
+	index int
+	isKey bool
+}
+
+// Path is a parsed JSONPath expression.
+type Path []segment
+
+// Parse parses a root `$` followed by any number of `.key`, `['key']` or
+// `[index]` selectors, e.g. `$.status`, `$.checks[0].name` or
+// `$['app.version']`. The leading `$` may be omitted.
+func Parse(path string) (Path, error) {
+	p := strings.TrimPrefix(strings.TrimSpace(path), "$")
+	if p != "" && p[0] != '.' && p[0] != '[' {
+		p = "." + p
+	}
+
+	var segments Path
+	for len(p) > 0 {
+		switch p[0] {
+		case '.':
+			p = p[1:]
+			end := strings.IndexAny(p, ".[")
+			if end < 0 {
+				end = len(p)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("invalid JSONPath %q: empty key", path)
+			}
+			segments = append(segments, segment{key: p[:end], isKey: true})
+			p = p[end:]
+
+		case '[':
+			end := strings.IndexByte(p, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid JSONPath %q: missing ]", path)
+			}
+			selector := p[1:end]
+			p = p[end+1:]
+
+			if len(selector) >= 2 && (selector[0] == '\'' || selector[0] == '"') && selector[len(selector)-1] == selector[0] {
+				segments = append(segments, segment{key: selector[1 : len(selector)-1], isKey: true})
+				continue
+			}
+			index, err := strconv.Atoi(selector)
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid JSONPath %q: invalid index %q", path, selector)
+			}
+			segments = append(segments, segment{index: index})
+
+		default:
+			return nil, fmt.Errorf("invalid JSONPath %q: unexpected %q", path, p[0])
+		}
+	}
+	return segments, nil
+}
+
+// Eval returns the value selected by the path in doc, a JSON document decoded
+// with encoding/json. The boolean is false when the path does not select
+// anything.
+func (p Path) Eval(doc interface{}) (interface{}, bool) {
+	for _, s := range p {
+		switch v := doc.(type) {
+		case map[string]interface{}:
+			if !s.isKey {
+				return nil, false
+			}
+			val, ok := v[s.key]
+			if !ok {
+				return nil, false
+			}
+			doc = val
+		case []interface{}:
+			if s.isKey || s.index >= len(v) {
+				return nil, false
+			}
+			doc = v[s.index]
+		default:
+			return nil, false
+		}
+	}
+	return doc, true
+}
@@ -0,0 +1,46 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package jsonpath
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPath_Eval(t *testing.T) {
+	var doc interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{"status":"ok","checks":[{"name":"db"}],"app.version":3}`), &doc))
+
+	cases := []struct {
+		path  string
+		value interface{}
+		found bool
+	}{
+		{"$", doc, true},
+		{"$.status", "ok", true},
+		{"status", "ok", true},
+		{"$.checks[0].name", "db", true},
+		{`$.checks[0]["name"]`, "db", true},
+		{"$['app.version']", float64(3), true},
+		{"$.checks[1].name", nil, false},
+		{"$.status.name", nil, false},
+		{"$.checks.name", nil, false},
+	}
+	for _, tc := range cases {
+		p, err := Parse(tc.path)
+		require.NoError(t, err, tc.path)
+		v, ok := p.Eval(doc)
+		require.Equal(t, tc.found, ok, tc.path)
+		require.Equal(t, tc.value, v, tc.path)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, path := range []string{"$.", "$..status", "$.checks[0", "$.checks[a]", "$.checks[-1]", "$.checks[0]name"} {
+		_, err := Parse(path)
+		require.Error(t, err, path)
+	}
+}
@@ -48,6 +48,34 @@ type LogFile struct {
 	acquire sync.Mutex
 }
 
+// NewLogFile creates a LogFile writing to path and opens its first file. When
+// path is a directory the file is named defaultName. The file is rotated every
+// duration, 24 hours if zero, and after maxBytes if positive. maxFiles rotated
+// files are kept, or all of them if zero.
+func NewLogFile(path, defaultName string, duration time.Duration, maxBytes, maxFiles int) (*LogFile, error) {
+	dir, fileName := filepath.Split(path)
+	if fileName == "" {
+		fileName = defaultName
+	}
+	if duration == 0 {
+		duration = defaultRotateDuration
+	}
+	logFile := &LogFile{
+		fileName: fileName,
+		logPath:  dir,
+		duration: duration,
+		MaxBytes: maxBytes,
+		MaxFiles: maxFiles,
+	}
+	if err := logFile.pruneFiles(); err != nil {
+		return nil, fmt.Errorf("Failed to prune log files: %w", err)
+	}
+	if err := logFile.openNew(); err != nil {
+		return nil, fmt.Errorf("Failed to setup logging: %w", err)
+	}
+	return logFile, nil
+}
+
 func (l *LogFile) fileNamePattern() string {
 	// Extract the file extension
 	fileExt := filepath.Ext(l.fileName)
@@ -125,6 +153,18 @@ func removeFiles(files []string) error {
 	return nil
 }
 
+// Close closes the current file. A later Write opens a new one.
+func (l *LogFile) Close() error {
+	l.acquire.Lock()
+	defer l.acquire.Unlock()
+	if l.FileInfo == nil {
+		return nil
+	}
+	err := l.FileInfo.Close()
+	l.FileInfo = nil
+	return err
+}
+
 // Write is used to implement io.Writer
 func (l *LogFile) Write(b []byte) (n int, err error) {
 	l.acquire.Lock()
@@ -1762,6 +1762,7 @@ func ServiceDefaultsToStructs(s *ServiceDefaults, t *structs.ServiceConfigEntry)
 	t.LocalRequestTimeoutMs = int(s.LocalRequestTimeoutMs)
 	t.BalanceInboundConnections = s.BalanceInboundConnections
 	t.EnvoyExtensions = EnvoyExtensionsToStructs(s.EnvoyExtensions)
+	t.EnableHTTP3 = s.EnableHTTP3
 	t.Meta = s.Meta
 }
 func ServiceDefaultsFromStructs(t *structs.ServiceConfigEntry, s *ServiceDefaults) {
@@ -1802,6 +1803,7 @@ func ServiceDefaultsFromStructs(t *structs.ServiceConfigEntry, s *ServiceDefault
 	s.LocalRequestTimeoutMs = int32(t.LocalRequestTimeoutMs)
 	s.BalanceInboundConnections = t.BalanceInboundConnections
 	s.EnvoyExtensions = EnvoyExtensionsFromStructs(t.EnvoyExtensions)
+	s.EnableHTTP3 = t.EnableHTTP3
 	s.Meta = t.Meta
 }
 func ServiceIntentionsToStructs(s *ServiceIntentions, t *structs.ServiceIntentionsConfigEntry) {
@@ -2098,6 +2100,7 @@ func SourceIntentionToStructs(s *SourceIntention, t *structs.SourceIntention) {
 	t.EnterpriseMeta = enterpriseMetaToStructs(s.EnterpriseMeta)
 	t.Peer = s.Peer
 	t.SamenessGroup = s.SamenessGroup
+	t.TrustDomain = s.TrustDomain
 }
 func SourceIntentionFromStructs(t *structs.SourceIntention, s *SourceIntention) {
 	if s == nil {
@@ -2125,6 +2128,7 @@ func SourceIntentionFromStructs(t *structs.SourceIntention, s *SourceIntention)
 	s.EnterpriseMeta = enterpriseMetaFromStructs(t.EnterpriseMeta)
 	s.Peer = t.Peer
 	s.SamenessGroup = t.SamenessGroup
+	s.TrustDomain = t.TrustDomain
 }
 func StatusToStructs(s *Status, t *structs.Status) {
 	if s == nil {
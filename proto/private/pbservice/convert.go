@@ -170,6 +170,32 @@ func NewExposePathSliceFromStructs(t []structs.ExposePath) []*ExposePath {
 	return s
 }
 
+// TODO: handle this with mog
+func CompositeMembersToStructs(s []*CompositeMember) []structs.CompositeMember {
+	if len(s) == 0 {
+		return nil
+	}
+	t := make([]structs.CompositeMember, len(s))
+	for i, v := range s {
+		CompositeMemberToStructs(v, &t[i])
+	}
+	return t
+}
+
+// TODO: handle this with mog
+func NewCompositeMembersFromStructs(t []structs.CompositeMember) []*CompositeMember {
+	if len(t) == 0 {
+		return nil
+	}
+	s := make([]*CompositeMember, len(t))
+	for i, v := range t {
+		m := new(CompositeMember)
+		CompositeMemberFromStructs(&v, m)
+		s[i] = m
+	}
+	return s
+}
+
 // TODO: handle this with mog
 func UpstreamsToStructs(s []*Upstream) structs.Upstreams {
 	t := make(structs.Upstreams, len(s))
@@ -20,11 +20,30 @@ func CheckTypeToStructs(s *CheckType, t *structs.CheckType) {
 	t.Method = s.Method
 	t.Body = s.Body
 	t.DisableRedirects = s.DisableRedirects
+	t.BodyRegex = s.BodyRegex
+	t.JSONPath = s.JSONPath
+	t.JSONPathValue = s.JSONPathValue
+	t.RequiredHeaders = s.RequiredHeaders
+	t.LatencyWarning = structs.DurationFromProto(s.LatencyWarning)
 	t.TCP = s.TCP
 	t.UDP = s.UDP
+	t.DNS = s.DNS
+	t.DNSServer = s.DNSServer
+	t.DNSRecordType = s.DNSRecordType
+	t.DNSExpect = s.DNSExpect
+	t.TLSCert = s.TLSCert
+	t.TLSCertWarningDays = int(s.TLSCertWarningDays)
+	t.TLSCertCriticalDays = int(s.TLSCertCriticalDays)
 	t.Interval = structs.DurationFromProto(s.Interval)
 	t.AliasNode = s.AliasNode
 	t.AliasService = s.AliasService
+	t.CompositeChecks = s.CompositeChecks
+	t.CompositeNode = s.CompositeNode
+	t.CompositeMembers = CompositeMembersToStructs(s.CompositeMembers)
+	t.CompositeOperator = s.CompositeOperator
+	t.CompositeThreshold = int(s.CompositeThreshold)
+	t.CompositeStatus = s.CompositeStatus
+	t.CompositePartialStatus = s.CompositePartialStatus
 	t.DockerContainerID = s.DockerContainerID
 	t.Shell = s.Shell
 	t.GRPC = s.GRPC
@@ -58,11 +77,30 @@ func CheckTypeFromStructs(t *structs.CheckType, s *CheckType) {
 	s.Method = t.Method
 	s.Body = t.Body
 	s.DisableRedirects = t.DisableRedirects
+	s.BodyRegex = t.BodyRegex
+	s.JSONPath = t.JSONPath
+	s.JSONPathValue = t.JSONPathValue
+	s.RequiredHeaders = t.RequiredHeaders
+	s.LatencyWarning = structs.DurationToProto(t.LatencyWarning)
 	s.TCP = t.TCP
 	s.UDP = t.UDP
+	s.DNS = t.DNS
+	s.DNSServer = t.DNSServer
+	s.DNSRecordType = t.DNSRecordType
+	s.DNSExpect = t.DNSExpect
+	s.TLSCert = t.TLSCert
+	s.TLSCertWarningDays = int32(t.TLSCertWarningDays)
+	s.TLSCertCriticalDays = int32(t.TLSCertCriticalDays)
 	s.Interval = structs.DurationToProto(t.Interval)
 	s.AliasNode = t.AliasNode
 	s.AliasService = t.AliasService
+	s.CompositeChecks = t.CompositeChecks
+	s.CompositeNode = t.CompositeNode
+	s.CompositeMembers = NewCompositeMembersFromStructs(t.CompositeMembers)
+	s.CompositeOperator = t.CompositeOperator
+	s.CompositeThreshold = int32(t.CompositeThreshold)
+	s.CompositeStatus = t.CompositeStatus
+	s.CompositePartialStatus = t.CompositePartialStatus
 	s.DockerContainerID = t.DockerContainerID
 	s.Shell = t.Shell
 	s.GRPC = t.GRPC
@@ -80,6 +118,20 @@ func CheckTypeFromStructs(t *structs.CheckType, s *CheckType) {
 	s.DeregisterCriticalServiceAfter = structs.DurationToProto(t.DeregisterCriticalServiceAfter)
 	s.OutputMaxSize = int32(t.OutputMaxSize)
 }
+func CompositeMemberToStructs(s *CompositeMember, t *structs.CompositeMember) {
+	if s == nil {
+		return
+	}
+	t.Node = s.Node
+	t.CheckID = s.CheckID
+}
+func CompositeMemberFromStructs(t *structs.CompositeMember, s *CompositeMember) {
+	if s == nil {
+		return
+	}
+	s.Node = t.Node
+	s.CheckID = t.CheckID
+}
 func HealthCheckToStructs(s *HealthCheck, t *structs.HealthCheck) {
 	if s == nil {
 		return